@@ -2,28 +2,47 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"runtime"
 	"runtime/debug"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/jessevdk/go-flags"
+	"golang.org/x/term"
 
+	"github.com/umputun/ralphex/pkg/analyzer"
+	"github.com/umputun/ralphex/pkg/artifacts"
+	"github.com/umputun/ralphex/pkg/baseline"
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/debuglog"
+	"github.com/umputun/ralphex/pkg/demo"
+	"github.com/umputun/ralphex/pkg/estimate"
 	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/history"
 	"github.com/umputun/ralphex/pkg/input"
+	"github.com/umputun/ralphex/pkg/keychain"
+	"github.com/umputun/ralphex/pkg/logship"
 	"github.com/umputun/ralphex/pkg/notify"
 	"github.com/umputun/ralphex/pkg/plan"
+	"github.com/umputun/ralphex/pkg/plugin"
 	"github.com/umputun/ralphex/pkg/processor"
 	"github.com/umputun/ralphex/pkg/progress"
+	"github.com/umputun/ralphex/pkg/seal"
 	"github.com/umputun/ralphex/pkg/status"
+	"github.com/umputun/ralphex/pkg/telemetry"
 	"github.com/umputun/ralphex/pkg/web"
 )
 
@@ -36,8 +55,16 @@ type opts struct {
 	TasksOnly       bool     `short:"t" long:"tasks-only" description:"run only task phase, skip all reviews"`
 	BaseRef         string   `short:"b" long:"base-ref" description:"override default branch for review diffs (branch name or commit hash)"`
 	SkipFinalize    bool     `long:"skip-finalize" description:"skip finalize step even if enabled in config"`
+	SkipFirstReview bool     `long:"skip-first-review" description:"skip the claude review before the external review"`
+	SkipCodex       bool     `long:"skip-codex" description:"skip the external review (codex or custom tool)"`
+	SkipSecond      bool     `long:"skip-second-review" description:"skip the claude review after the external review"`
+	EmitPatch       string   `long:"emit-patch" value-name:"FILE" description:"write review fixes to a patch file and restore the worktree (review modes)"`
+	Report          string   `long:"report" value-name:"FILE" description:"write a JSON report of the run to a file: steps, iterations, durations, signals, findings count, changed files"`
 	PlanDescription string   `long:"plan" description:"create plan interactively (enter plan description)"`
-	Debug           bool     `short:"d" long:"debug" description:"enable debug logging"`
+	PlanSpec        string   `long:"plan-spec" value-name:"FILE" description:"draft a plan from a short spec file without questions, write it to the plans dir and stop"`
+	DryRun          bool     `long:"dry-run" description:"print prompts the pipeline would send, without running agents or creating a branch"`
+	Resume          bool     `long:"resume" description:"continue an interrupted run from its checkpoint (.ralphex/state.json)"`
+	Debug           []string `short:"d" long:"debug" optional:"yes" optional-value:"all" env:"RALPHEX_DEBUG" env-delim:"," value-name:"SUBSYSTEMS" description:"enable debug output, all or comma-separated: executor-io, prompts, signals, git, processor (use --debug=signals)"`
 	NoColor         bool     `long:"no-color" description:"disable color output"`
 	Version         bool     `short:"v" long:"version" description:"print version and exit"`
 	Serve           bool     `short:"s" long:"serve" description:"start web dashboard for real-time streaming"`
@@ -47,11 +74,93 @@ type opts struct {
 	DumpDefaults    string   `long:"dump-defaults" description:"extract raw embedded defaults to specified directory"`
 	ConfigDir       string   `long:"config-dir" env:"RALPHEX_CONFIG_DIR" description:"custom config directory"`
 
+	StartTask string   `long:"start-task" value-name:"TASK" description:"start the task phase at this task: its number or a regex on the task text, earlier tasks are skipped"`
+	OnlyTasks []string `long:"only-tasks" value-name:"TASK" description:"run only tasks matching this number or regex on the task text (repeatable)"`
+
+	MaxDuration time.Duration `long:"max-duration" value-name:"DURATION" description:"stop the run with its state saved for --resume once it runs this long (e.g. 8h), overrides max_run_duration_ms"`
+	RunWindow   string        `long:"run-window" value-name:"HH:MM-HH:MM" description:"run only in this time of day, stop with the state saved for --resume once it closes (e.g. 22:00-06:00), overrides run_window"`
+
+	UpdateBaseline bool `long:"update-baseline" description:"add findings dismissed as invalid in several runs to .ralphex/baseline without asking"`
+
 	PlanFile string `positional-arg-name:"plan-file" description:"path to plan file (optional, uses fzf if omitted)"`
+
+	PlanCmd      planCommand      `command:"plan" description:"plan file tools"`
+	TelemetryCmd telemetryCommand `command:"telemetry" subcommands-optional:"yes" description:"show or change opt-in anonymous usage stats"`
+	DemoCmd      demoCommand      `command:"demo" description:"simulate a full run with scripted agents, no claude, codex, git or network needed"`
+	StatsCmd     statsCommand     `command:"stats" description:"show success rate, iterations and stalls of past runs in this repository, per week"`
+	DecryptCmd   decryptCommand   `command:"decrypt" description:"print a run artifact encrypted with artifact_key: checkpoint, transcript or run history"`
+	ApplyCmd     applyCommand     `command:"apply" description:"interactively select fixes from a patch file written by --emit-patch and apply them"`
+	AuthCmd      authCommand      `command:"auth" description:"store secrets referenced from config as keychain:<name> in the OS keychain"`
+
+	subcommand string           // active subcommand path, e.g. "plan lint", empty for a regular run
+	debug      debuglog.Flags   // parsed --debug subsystems
+	pause      *processor.Pause // pause requests from SIGUSR1 and SIGUSR2, nil when not watched
+}
+
+// planCommand groups plan file subcommands.
+type planCommand struct {
+	Lint     planLintCommand     `command:"lint" description:"check plan quality before a run"`
+	Estimate planEstimateCommand `command:"estimate" description:"predict iterations, duration and cost of a plan run"`
+}
+
+// telemetryCommand groups telemetry subcommands, "ralphex telemetry" alone shows the status.
+type telemetryCommand struct {
+	Status struct{} `command:"status" description:"show whether telemetry is enabled and the collected stats"`
+	On     struct{} `command:"on" description:"enable anonymous aggregate usage stats"`
+	Off    struct{} `command:"off" description:"disable usage stats and drop unreported ones"`
+}
+
+// demoCommand holds options of "ralphex demo".
+type demoCommand struct {
+	Dir   string        `long:"dir" description:"directory for the demo project (default: new temp directory)"`
+	Delay time.Duration `long:"delay" default:"150ms" description:"pause between streamed lines of scripted agent output"`
+}
+
+// statsCommand holds options of "ralphex stats".
+type statsCommand struct {
+	Weeks int `long:"weeks" default:"8" description:"number of recent weeks to show"`
+}
+
+// decryptCommand holds options of "ralphex decrypt".
+type decryptCommand struct {
+	Args struct {
+		File string `positional-arg-name:"file" required:"yes" description:"encrypted artifact file"`
+	} `positional-args:"yes"`
+}
+
+// applyCommand holds options of "ralphex apply".
+type applyCommand struct {
+	Args struct {
+		PatchFile string `positional-arg-name:"patch-file" required:"yes" description:"patch file written by --emit-patch"`
+	} `positional-args:"yes"`
+}
+
+// authCommand groups OS keychain subcommands.
+type authCommand struct {
+	Set    authNameCommand `command:"set" description:"store a secret in the OS keychain, read from stdin"`
+	Delete authNameCommand `command:"delete" description:"remove a secret from the OS keychain"`
+}
+
+// authNameCommand holds options of "ralphex auth set" and "ralphex auth delete".
+type authNameCommand struct {
+	Args struct {
+		Name string `positional-arg-name:"name" required:"yes" description:"secret name, referenced from config as keychain:<name>"`
+	} `positional-args:"yes"`
+}
+
+// planLintCommand holds options of "ralphex plan lint".
+type planLintCommand struct {
+	Static bool `long:"static" description:"run static checks only, skip the model pass"`
+	Args   struct {
+		PlanFile string `positional-arg-name:"plan-file" description:"plan file to lint (optional, uses fzf if omitted)"`
+	} `positional-args:"yes"`
 }
 
 var revision = "unknown"
 
+// baselinePath is the project baseline of accepted findings, relative to the repository root
+var baselinePath = filepath.Join(config.RepoArtifactDir, baseline.File)
+
 // resolveVersion returns the best available version string.
 // priority: ldflags revision → module version from go install → VCS commit hash → "unknown".
 func resolveVersion() string {
@@ -87,6 +196,7 @@ func (stderrLog) Print(format string, args ...any) {
 type startupInfo struct {
 	PlanFile        string
 	PlanDescription string // used for plan mode instead of PlanFile
+	PlanSpec        string // spec file of a plan drafted without questions, plan mode only
 	Branch          string
 	Mode            processor.Mode
 	MaxIterations   int
@@ -103,8 +213,39 @@ type executePlanRequest struct {
 	Selector      *plan.Selector
 	DefaultBranch string
 	NotifySvc     *notify.Service
+	Artifacts     *artifacts.Publisher
+	Telemetry     *telemetry.Telemetry
+	Resume        *processor.Checkpoint // checkpoint of the interrupted run to continue, set by --resume
+	ArtifactDir   string                // directory for run artifacts, .ralphex if empty
+	Plugins       []*plugin.Client      // running plugins, see startPlugins
+}
+
+// artifactPath returns the path of a run artifact, e.g. "progress" or "state.json".
+func (req executePlanRequest) artifactPath(name string) string {
+	dir := req.ArtifactDir
+	if dir == "" {
+		dir = config.RepoArtifactDir
+	}
+	return filepath.Join(dir, name)
+}
+
+// sealer returns the sealer run artifacts are encrypted with, nil without an artifact key.
+func (req executePlanRequest) sealer() *seal.Sealer {
+	if req.Config == nil {
+		return nil
+	}
+	return req.Config.ArtifactSealer
 }
 
+// exitStopped is the exit code of a run stopped on request or by the run window closing, see processor.StopError
+const exitStopped = 3
+
+// exitCrashed is the exit code of a run ended by a panic in ralphex, see processor.PanicError
+const exitCrashed = 4
+
+// exitNothingToDo is the exit code of a run with all plan tasks completed before it started, see processor.ErrNothingToDo
+const exitNothingToDo = 5
+
 func main() {
 	if os.Getenv("GO_FLAGS_COMPLETION") == "" {
 		fmt.Printf("ralphex %s\n", resolveVersion())
@@ -113,6 +254,7 @@ func main() {
 	var o opts
 	parser := flags.NewParser(&o, flags.Default)
 	parser.Usage = "[OPTIONS] [plan-file]"
+	parser.SubcommandsOptional = true
 
 	args, err := parser.Parse()
 	if err != nil {
@@ -131,12 +273,27 @@ func main() {
 	if len(args) > 0 {
 		o.PlanFile = args[0]
 	}
+	o.subcommand = commandPath(parser.Active)
 
 	// setup context with signal handling
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
 	if err := run(ctx, o); err != nil {
+		var stopErr *processor.StopError
+		if errors.As(err, &stopErr) {
+			fmt.Fprintf(os.Stderr, "%v\n", stopErr)
+			os.Exit(exitStopped)
+		}
+		var panicErr *processor.PanicError
+		if errors.As(err, &panicErr) {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(exitCrashed)
+		}
+		if errors.Is(err, processor.ErrNothingToDo) {
+			fmt.Fprintf(os.Stderr, "%v\n", processor.ErrNothingToDo)
+			os.Exit(exitNothingToDo)
+		}
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
@@ -151,8 +308,11 @@ func run(ctx context.Context, o opts) error {
 	// returned cleanup ensures goroutine exits when run() returns, avoiding leaks in tests.
 	defer startInterruptWatcher(ctx, restoreTerminal)()
 
-	// validate conflicting flags
-	if err := validateFlags(o); err != nil {
+	// SIGUSR1 pauses the run after the current iteration, SIGUSR2 continues it
+	o.pause = processor.NewPause()
+	defer watchPauseSignals(o.pause)()
+
+	if err := prepareOpts(&o); err != nil {
 		return err
 	}
 
@@ -170,11 +330,16 @@ func run(ctx context.Context, o opts) error {
 	// create colors from config (all colors guaranteed populated via fallback)
 	colors := progress.NewColors(cfg.Colors)
 
-	// create notification service (nil if no channels configured)
-	notifySvc, err := notify.New(cfg.NotifyParams, stderrLog{})
+	// subcommands run their own flow, no notifications or artifacts
+	if o.subcommand != "" {
+		return runSubcommand(ctx, o, cfg, colors)
+	}
+
+	req, stopServices, err := newRunRequest(cfg, colors)
 	if err != nil {
-		return fmt.Errorf("create notification service: %w", err)
+		return err
 	}
+	defer stopServices()
 
 	// watch-only mode: --serve with watch dirs (CLI or config) and no plan file
 	// runs web dashboard without plan execution, can run from any directory
@@ -182,90 +347,280 @@ func run(ctx context.Context, o opts) error {
 		return runWatchOnly(ctx, o, cfg, colors)
 	}
 
-	// check dependencies using configured command (or default "codex")
-	if depErr := checkPrimaryCommandDep(cfg); depErr != nil {
-		return depErr
+	if err := openRunRepo(ctx, o, &req); err != nil {
+		return err
+	}
+	if o.SkipFinalize {
+		cfg.FinalizeEnabled = false
+	}
+
+	req.Mode = determineMode(o)
+	var resume *processor.Checkpoint
+	if o.Resume {
+		if resume, err = loadResumeCheckpoint(o, req.artifactPath("state.json"), cfg.ArtifactSealer); err != nil {
+			return err
+		}
+		req.Mode, o.PlanFile = resume.Mode, resume.PlanFile
+	}
+
+	// plan mode has different flow - doesn't require plan file selection
+	if req.Mode == processor.ModePlan {
+		return runPlanMode(ctx, o, req)
+	}
+
+	// a plan directory runs each of its plans in turn
+	if info, statErr := os.Stat(o.PlanFile); statErr == nil && info.IsDir() {
+		return runPlanDir(ctx, o, o.PlanFile, req, os.Stdout)
+	}
+
+	req.Resume = resume
+	return selectAndExecutePlan(ctx, o, req)
+}
+
+// prepareOpts validates the flags, parses --debug and reads the --plan-spec file into the plan description.
+func prepareOpts(o *opts) error {
+	// validate conflicting flags
+	if err := validateFlags(*o); err != nil {
+		return err
+	}
+	debugFlags, err := debuglog.Parse(o.Debug)
+	if err != nil {
+		return fmt.Errorf("invalid --debug: %w", err)
+	}
+	o.debug = debugFlags
+
+	// a spec file is the plan description of a plan drafted without questions
+	if o.PlanSpec != "" {
+		if o.PlanDescription, err = readPlanSpec(o.PlanSpec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newRunRequest starts the services a run reports to: plugins, notifications, the artifacts publisher and
+// telemetry. returns the request with them set and a function stopping the plugins.
+func newRunRequest(cfg *config.Config, colors *progress.Colors) (executePlanRequest, func(), error) {
+	// start out-of-process plugins, their notifiers become "plugin:<name>" notification channels
+	plugins, err := startPlugins(cfg)
+	if err != nil {
+		return executePlanRequest{}, nil, err
+	}
+	stop := func() { stopPlugins(plugins) }
+	cfg.NotifyParams.Plugins = pluginNotifiers(plugins)
+
+	// create notification service (nil if no channels configured)
+	notifySvc, err := notify.New(cfg.NotifyParams, stderrLog{})
+	if err != nil {
+		stop()
+		return executePlanRequest{}, nil, fmt.Errorf("create notification service: %w", err)
+	}
+
+	// create artifacts publisher (nil if publishing is not configured)
+	publisher, err := artifacts.New(cfg.ArtifactsParams)
+	if err != nil {
+		stop()
+		return executePlanRequest{}, nil, fmt.Errorf("create artifacts publisher: %w", err)
+	}
+
+	return executePlanRequest{
+		Config:    cfg,
+		Colors:    colors,
+		NotifySvc: notifySvc,
+		Artifacts: publisher,
+		Telemetry: newTelemetry(cfg), // usage stats, recorded only if enabled with "ralphex telemetry on"
+		Plugins:   plugins,
+	}, stop, nil
+}
+
+// openRunRepo checks the agent dependency and opens the repository of the run in the current directory,
+// setting the git service, artifact directory, default branch and plan selector of req.
+func openRunRepo(ctx context.Context, o opts, req *executePlanRequest) error {
+	// check dependencies using configured command (or default "codex"), dry run calls no agents
+	if !o.DryRun {
+		if depErr := checkPrimaryCommandDep(req.Config); depErr != nil {
+			return depErr
+		}
 	}
 
 	// require running from repo root
 	if _, statErr := os.Stat(".git"); statErr != nil {
 		return errors.New("must run from repository root (no .git directory found)")
 	}
+	req.ArtifactDir = prepareArtifactDir(req.Config, req.Colors)
 
 	// open git repository via Service
-	gitSvc, err := openGitService(colors)
+	gitSvc, err := openGitService(req.Config.GitCommand, req.Colors)
 	if err != nil {
 		return fmt.Errorf("open git repo: %w", err)
 	}
+	gitSvc.SetDebug(o.debug)
 
 	// ensure repository has commits (prompts to create initial commit if empty)
 	if ensureErr := ensureRepoHasCommits(ctx, gitSvc, os.Stdin, os.Stdout); ensureErr != nil {
 		return ensureErr
 	}
 
-	defaultBranch := resolveDefaultBranch(o.BaseRef, cfg.DefaultBranch, gitSvc.GetDefaultBranch())
-	if o.SkipFinalize {
-		cfg.FinalizeEnabled = false
-	}
-
-	mode := determineMode(o)
-
+	req.GitSvc = gitSvc
+	req.DefaultBranch = resolveDefaultBranch(o.BaseRef, req.Config.DefaultBranch, gitSvc.GetDefaultBranch())
 	// create plan selector for use by plan selection and plan mode
-	selector := plan.NewSelector(cfg.PlansDir, colors)
-
-	// plan mode has different flow - doesn't require plan file selection
-	if mode == processor.ModePlan {
-		return runPlanMode(ctx, o, executePlanRequest{
-			Mode:          processor.ModePlan,
-			GitSvc:        gitSvc,
-			Config:        cfg,
-			Colors:        colors,
-			Selector:      selector,
-			DefaultBranch: defaultBranch,
-			NotifySvc:     notifySvc,
-		})
-	}
+	req.Selector = plan.NewSelector(req.Config.PlansDir, req.Colors)
+	return nil
+}
 
+// selectAndExecutePlan selects the plan file, prepares the branch for it and executes it.
+// with no plans on the main branch it offers to create one in plan mode.
+func selectAndExecutePlan(ctx context.Context, o opts, req executePlanRequest) error {
 	// select and prepare plan file (not needed for plan mode)
 	// plan is optional only for review modes (ModeReview, ModeCodexOnly)
-	planOptional := mode == processor.ModeReview || mode == processor.ModeCodexOnly
-	planFile, err := selector.Select(ctx, o.PlanFile, planOptional)
+	planOptional := req.Mode == processor.ModeReview || req.Mode == processor.ModeCodexOnly
+	planFile, err := req.Selector.Select(ctx, o.PlanFile, planOptional)
 	if err != nil {
 		// check for auto-plan-mode: no plans found on main/master branch
-		handled, autoPlanErr := tryAutoPlanMode(ctx, err, o, executePlanRequest{
-			GitSvc:        gitSvc,
-			Config:        cfg,
-			Colors:        colors,
-			Selector:      selector,
-			DefaultBranch: defaultBranch,
-			NotifySvc:     notifySvc,
-		})
-		if handled {
+		autoReq := req
+		autoReq.Mode, autoReq.Resume = "", nil
+		if handled, autoPlanErr := tryAutoPlanMode(ctx, err, o, autoReq); handled {
 			return autoPlanErr
 		}
 		return fmt.Errorf("select plan: %w", err)
 	}
 
-	// setup git for execution (branch, gitignore)
-	if planFile != "" && modeRequiresBranch(mode) {
-		if err := gitSvc.CreateBranchForPlan(planFile); err != nil {
+	// setup git for execution (branch, gitignore), dry run leaves the branch alone
+	if planFile != "" && modeRequiresBranch(req.Mode) && !o.DryRun {
+		if err := req.GitSvc.CreateBranchForPlan(planFile); err != nil {
 			return fmt.Errorf("create branch for plan: %w", err)
 		}
 	}
-	if err := gitSvc.EnsureIgnored(".ralphex/progress/", ".ralphex/progress/progress-test.txt"); err != nil {
+	if err := ensureArtifactsIgnored(req.GitSvc, req.Config); err != nil {
+		return err
+	}
+
+	req.PlanFile = planFile
+	return executePlan(ctx, o, req)
+}
+
+// prepareArtifactDir returns the run artifact directory of the current repository, see config.ArtifactDir.
+// artifacts left in the other layout after artifact_location changed are moved there first.
+func prepareArtifactDir(cfg *config.Config, colors *progress.Colors) string {
+	dir := cfg.ArtifactDir(".")
+	moved, err := cfg.MigrateArtifacts(".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to migrate run artifacts: %v\n", err)
+	}
+	if len(moved) > 0 {
+		colors.Info().Printf("moved run artifacts (%s) to %s\n", strings.Join(moved, ", "), dir)
+	}
+	return dir
+}
+
+// ensureArtifactsIgnored keeps run artifacts stored in the repository out of git with a .gitignore in .ralphex,
+// leaving shared local config, prompts and agents trackable. nothing is needed when artifacts live in the
+// user state directory.
+func ensureArtifactsIgnored(gitSvc *git.Service, cfg *config.Config) error {
+	if cfg.ArtifactLocation == config.ArtifactsUser {
+		return nil
+	}
+	probes := []string{config.RepoArtifactDir + "/progress/progress-test.txt",
+		processor.TranscriptDir + "/transcript-test.txt", processor.CheckpointFile, processor.StopFile, processor.InboxFile}
+	if err := gitSvc.EnsureNestedIgnored(config.RepoArtifactDir, config.RepoArtifactGitignore, probes...); err != nil {
 		return fmt.Errorf("ensure gitignore: %w", err)
 	}
+	return nil
+}
 
-	return executePlan(ctx, o, executePlanRequest{
-		PlanFile:      planFile,
-		Mode:          mode,
-		GitSvc:        gitSvc,
-		Config:        cfg,
-		Colors:        colors,
-		Selector:      selector,
-		DefaultBranch: defaultBranch,
-		NotifySvc:     notifySvc,
-	})
+// planDirResult is the outcome of a plan of a plan directory run.
+type planDirResult struct {
+	PlanFile string
+	Status   string // done, failed or skipped
+	Duration time.Duration
+	Err      error
+}
+
+// runPlanDir executes the plans of dir one after another, each on its own branch started from the current one
+// and with the full pipeline, then prints a status summary. a failed plan doesn't stop the next one,
+// an interrupt or a branch which can't be prepared does.
+func runPlanDir(ctx context.Context, o opts, dir string, req executePlanRequest, stdout io.Writer) error {
+	if o.Serve || o.EmitPatch != "" || o.Report != "" {
+		return errors.New("--serve, --emit-patch and --report need a single plan file, not a plan directory")
+	}
+	plans, err := plan.ListPlans(dir)
+	if err != nil {
+		return fmt.Errorf("select plans: %w", err)
+	}
+	if err := ensureArtifactsIgnored(req.GitSvc, req.Config); err != nil {
+		return err
+	}
+	startBranch := getCurrentBranch(req.GitSvc)
+	withBranch := modeRequiresBranch(req.Mode) && !o.DryRun
+
+	results := make([]planDirResult, len(plans))
+	for i, p := range plans {
+		results[i] = planDirResult{PlanFile: p, Status: "skipped"}
+	}
+	for i, p := range plans {
+		if ctx.Err() != nil {
+			break
+		}
+		req.Colors.Info().Printf("\nplan %d of %d: %s\n", i+1, len(plans), toRelPath(p))
+		start := time.Now()
+		if withBranch {
+			if err := preparePlanBranch(req.GitSvc, startBranch, p); err != nil {
+				results[i] = planDirResult{PlanFile: p, Status: "failed", Err: err}
+				break
+			}
+		}
+		req.PlanFile = p
+		runErr := executePlan(ctx, o, req)
+		results[i] = planDirResult{PlanFile: p, Status: "done", Duration: time.Since(start).Round(time.Second), Err: runErr}
+		if runErr != nil {
+			results[i].Status = "failed"
+		}
+	}
+	if withBranch && getCurrentBranch(req.GitSvc) != startBranch {
+		if err := req.GitSvc.Checkout(startBranch); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+
+	failed := printPlanDirSummary(results, req.Colors, stdout)
+	if ctx.Err() != nil {
+		return fmt.Errorf("plan directory: %w", ctx.Err())
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d plans failed", failed, len(plans))
+	}
+	return nil
+}
+
+// preparePlanBranch switches back to the starting branch after an earlier plan and creates the branch of planFile.
+func preparePlanBranch(gitSvc *git.Service, startBranch, planFile string) error {
+	if getCurrentBranch(gitSvc) != startBranch {
+		if err := gitSvc.Checkout(startBranch); err != nil {
+			return err
+		}
+	}
+	if err := gitSvc.CreateBranchForPlan(planFile); err != nil {
+		return fmt.Errorf("create branch for plan: %w", err)
+	}
+	return nil
+}
+
+// printPlanDirSummary prints the status of each plan of a plan directory run and returns the number of failed plans.
+func printPlanDirSummary(results []planDirResult, colors *progress.Colors, stdout io.Writer) int {
+	failed := 0
+	colors.Info().Fprintf(stdout, "\nplans summary:\n")
+	for _, res := range results {
+		line := fmt.Sprintf("  %-7s %s", res.Status, toRelPath(res.PlanFile))
+		if res.Status != "skipped" {
+			line += fmt.Sprintf(" (%s)", res.Duration)
+		}
+		if res.Err != nil {
+			failed++
+			line += ": " + res.Err.Error()
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	return failed
 }
 
 // getCurrentBranch returns the current git branch name or "unknown" if unavailable.
@@ -302,6 +657,7 @@ func tryAutoPlanMode(ctx context.Context, err error, o opts, req executePlanRequ
 // executePlan runs the main execution loop for a plan file.
 // handles progress logging, web dashboard, runner execution, and post-execution tasks.
 func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
+	start := time.Now()
 	branch := getCurrentBranch(req.GitSvc)
 
 	// create shared phase holder (single source of truth for current phase)
@@ -309,10 +665,12 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 
 	// create progress logger
 	baseLog, err := progress.NewLogger(progress.Config{
-		PlanFile: req.PlanFile,
-		Mode:     string(req.Mode),
-		Branch:   branch,
-		NoColor:  o.NoColor,
+		PlanFile:   req.PlanFile,
+		Mode:       string(req.Mode),
+		Branch:     branch,
+		Dir:        req.artifactPath("progress"),
+		NoColor:    o.NoColor,
+		Accessible: req.Config.AccessibleOutput,
 	}, req.Colors, holder)
 	if err != nil {
 		return fmt.Errorf("create progress logger: %w", err)
@@ -327,7 +685,86 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 		}
 	}()
 
-	// wrap logger with broadcast logger if --serve is enabled
+	runnerLog, closeRunnerLog, err := newRunnerLog(ctx, o, req, baseLog, branch, holder)
+	if err != nil {
+		return err
+	}
+	defer closeRunnerLog()
+
+	// print startup info
+	printStartupInfo(startupInfo{
+		PlanFile:      req.PlanFile,
+		Branch:        branch,
+		Mode:          req.Mode,
+		MaxIterations: o.MaxIterations,
+		ProgressPath:  baseLog.Path(),
+	}, req.Colors)
+
+	warnPromptChange(req)
+
+	// remember where fixes start, so they can be extracted into a patch after the run
+	var patchBase string
+	if o.EmitPatch != "" {
+		if patchBase, err = req.GitSvc.StartCapture(); err != nil {
+			return fmt.Errorf("emit patch: %w", err)
+		}
+	}
+
+	// create and run the runner
+	r := createRunner(req, o, runnerLog, holder)
+	report, runErr := r.Run(ctx)
+	if o.DryRun {
+		// nothing ran, so there is nothing to record, notify about or archive
+		if runErr != nil {
+			return fmt.Errorf("dry run: %w", runErr)
+		}
+		return nil
+	}
+	if patchBase != "" {
+		// extract on failure too, fixes made before the failure are still useful
+		if patchErr := emitPatch(req, o.EmitPatch, patchBase); patchErr != nil {
+			if runErr == nil {
+				return patchErr
+			}
+			fmt.Fprintf(os.Stderr, "warning: %v\n", patchErr)
+		}
+	}
+	recordRun(ctx, o, req, r, report, start, runErr)
+	run := finishedRun{runID: artifacts.RunID(branch, start), start: start, branch: branch, iterations: r.TaskIterations(),
+		log: baseLog, transcripts: r.TranscriptPath()}
+	if runErr != nil {
+		return failRun(o, req, run, runErr)
+	}
+	completeRun(o, req, run, report)
+
+	// keep web dashboard running after execution completes
+	if o.Serve {
+		if err := baseLog.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to close progress log: %v\n", err)
+		}
+		baseLogClosed = true
+		req.Colors.Info().Printf("web dashboard still running at http://localhost:%d (press Ctrl+C to exit)\n", o.Port)
+		<-ctx.Done()
+	}
+
+	return nil
+}
+
+// finishedRun holds what the steps after a run need to know about it.
+type finishedRun struct {
+	runID       string
+	start       time.Time
+	branch      string
+	iterations  int
+	log         *progress.Logger
+	transcripts string // directory of the prompt transcripts, empty without prompts debugging
+}
+
+// newRunnerLog wraps the progress logger with the web dashboard broadcast if --serve is enabled, and with
+// the log shipper if log shipping is configured. returns the logger of the runner and a function closing
+// the shipper.
+func newRunnerLog(ctx context.Context, o opts, req executePlanRequest, baseLog *progress.Logger, branch string,
+	holder *status.PhaseHolder) (processor.Logger, func(), error) {
 	var runnerLog processor.Logger = baseLog
 	if o.Serve {
 		dashboard := web.NewDashboard(web.DashboardConfig{
@@ -337,42 +774,98 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 			Branch:          branch,
 			WatchDirs:       o.Watch,
 			ConfigWatchDirs: req.Config.WatchDirs,
+			InboxFile:       inboxPath(req, o),
 			Colors:          req.Colors,
 		}, holder)
 		var dashErr error
 		runnerLog, dashErr = dashboard.Start(ctx)
 		if dashErr != nil {
-			return fmt.Errorf("start dashboard: %w", dashErr)
+			return nil, nil, fmt.Errorf("start dashboard: %w", dashErr)
 		}
 	}
 
-	// print startup info
-	printStartupInfo(startupInfo{
-		PlanFile:      req.PlanFile,
-		Branch:        branch,
-		Mode:          req.Mode,
-		MaxIterations: o.MaxIterations,
-		ProgressPath:  baseLog.Path(),
-	}, req.Colors)
+	// ship run events to a log collector, if configured
+	shipper, err := newLogShipper(req, branch)
+	if err != nil {
+		return nil, nil, err
+	}
+	if shipper == nil {
+		return runnerLog, func() {}, nil
+	}
+	closeShipper := func() {
+		if closeErr := shipper.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", closeErr)
+		}
+	}
+	return logship.NewLogger(runnerLog, shipper, holder), closeShipper, nil
+}
 
-	// create and run the runner
-	r := createRunner(req, o, runnerLog, holder)
-	if runErr := r.Run(ctx); runErr != nil {
-		// send failure notification before returning error.
-		// use context.Background() because the parent ctx may be canceled (e.g. SIGINT),
-		// and the notification timeout is applied inside Send() independently.
-		req.NotifySvc.Send(context.Background(), notify.Result{
-			Status:   "failure",
-			Mode:     string(req.Mode),
-			PlanFile: req.PlanFile,
-			Branch:   branch,
-			Duration: baseLog.Elapsed(),
-			Error:    runErr.Error(),
-		})
+// recordRun writes the run report requested with --report and records the run in telemetry, the run
+// history and the baseline suggestions, whatever its outcome.
+func recordRun(ctx context.Context, o opts, req executePlanRequest, r *processor.Runner, report processor.RunReport,
+	start time.Time, runErr error) {
+	if o.Report != "" {
+		if err := writeRunReport(o.Report, report); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+	recordTelemetry(req, r.TaskIterations(), runErr)
+	recordHistory(req, history.Entry{Time: start, Mode: string(req.Mode), Iterations: r.TaskIterations(),
+		Duration: time.Since(start).Round(time.Second), Findings: historyFindings(r.Findings()),
+		Dismissed: historyFindings(r.Dismissed())}, runErr)
+	suggestBaseline(ctx, req, historyFindings(r.Dismissed()), o.UpdateBaseline, os.Stdin, os.Stdout)
+}
+
+// failRun reports a run ended by runErr: annotates the plan, writes the crash report of a panic and sends
+// the failure notification. a requested stop and a run with nothing to do are not failures.
+// returns runErr wrapped.
+func failRun(o opts, req executePlanRequest, run finishedRun, runErr error) error {
+	var stopErr *processor.StopError
+	if errors.As(runErr, &stopErr) {
+		// a requested stop is not a failure, nobody needs to be notified
+		annotatePlan(req, o, plan.Outcome{RunID: run.runID, Date: run.start, Status: "stopped", Mode: string(req.Mode),
+			Duration: run.log.Elapsed(), Iterations: run.iterations})
+		if cp := req.artifactPath("state.json"); checkpointExists(cp) {
+			req.Colors.Info().Printf("run stopped, state saved to %s, continue with: ralphex --resume\n", cp)
+		}
+		return fmt.Errorf("runner: %w", runErr)
+	}
+	if errors.Is(runErr, processor.ErrNothingToDo) {
+		// no agent was called, there is nothing to notify about
 		return fmt.Errorf("runner: %w", runErr)
 	}
+	var panicErr *processor.PanicError
+	if errors.As(runErr, &panicErr) {
+		path := req.artifactPath("crash-" + run.start.Format("20060102-150405") + ".txt")
+		if err := writeCrashReport(path, panicErr); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		} else {
+			req.Colors.Warn().Printf("ralphex crashed, crash report written to %s, please include it in a bug report\n", path)
+		}
+	}
+	annotatePlan(req, o, plan.Outcome{RunID: run.runID, Date: run.start, Status: "failure", Mode: string(req.Mode),
+		Duration: run.log.Elapsed(), Iterations: run.iterations, Error: runErr.Error()})
+	// send failure notification before returning error.
+	// use context.Background() because the parent ctx may be canceled (e.g. SIGINT),
+	// and the notification timeout is applied inside Send() independently.
+	req.NotifySvc.Send(context.Background(), notify.Result{
+		Status:   "failure",
+		Mode:     string(req.Mode),
+		PlanFile: req.PlanFile,
+		Branch:   run.branch,
+		Duration: run.log.Elapsed(),
+		Error:    runErr.Error(),
+	})
+	if cp := req.artifactPath("state.json"); checkpointExists(cp) {
+		req.Colors.Info().Printf("run state saved to %s, continue with: ralphex --resume\n", cp)
+	}
+	return fmt.Errorf("runner: %w", runErr)
+}
 
-	elapsed := baseLog.Elapsed()
+// completeRun finishes a successful run: publishes the artifacts, sends the success notification, annotates
+// and moves the plan, and prints the completion summary.
+func completeRun(o opts, req executePlanRequest, run finishedRun, report processor.RunReport) {
+	elapsed := run.log.Elapsed()
 
 	// get diff stats for completion message (optional - errors logged but don't block)
 	stats, statsErr := req.GitSvc.DiffStats(req.DefaultBranch)
@@ -380,6 +873,9 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 		fmt.Fprintf(os.Stderr, "warning: failed to get diff stats: %v\n", statsErr)
 	}
 
+	// publish run artifacts before notifying, so links can be included
+	links := publishArtifacts(req, run, report)
+
 	// send success notification.
 	// use context.Background() because the parent ctx may be canceled (e.g. SIGINT),
 	// and the notification timeout is applied inside Send() independently.
@@ -387,67 +883,516 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 		Status:    "success",
 		Mode:      string(req.Mode),
 		PlanFile:  req.PlanFile,
-		Branch:    branch,
+		Branch:    run.branch,
 		Duration:  elapsed,
 		Files:     stats.Files,
 		Additions: stats.Additions,
 		Deletions: stats.Deletions,
+		Artifacts: links,
 	})
 
-	// move completed plan to completed/ directory
-	if req.PlanFile != "" && modeRequiresBranch(req.Mode) {
-		if moveErr := req.GitSvc.MovePlanToCompleted(req.PlanFile); moveErr != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to move plan to completed: %v\n", moveErr)
-		}
-	}
+	// record the outcome before the plan is moved, so the annotation is committed with it
+	annotatePlan(req, o, plan.Outcome{RunID: run.runID, Date: run.start, Status: "success", Mode: string(req.Mode),
+		Duration: elapsed, Iterations: run.iterations})
+	completePlanFile(req)
 
 	// display completion with stats
 	if stats.Files > 0 {
-		baseLog.LogDiffStats(stats.Files, stats.Additions, stats.Deletions)
+		run.log.LogDiffStats(stats.Files, stats.Additions, stats.Deletions)
 		req.Colors.Info().Printf("\ncompleted in %s (%d files, +%d/-%d lines)\n",
 			elapsed, stats.Files, stats.Additions, stats.Deletions)
 	} else {
 		req.Colors.Info().Printf("\ncompleted in %s\n", elapsed)
 	}
+	printFinalVerification(req.Colors, report.FinalVerification)
+}
 
-	// keep web dashboard running after execution completes
-	if o.Serve {
-		if err := baseLog.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to close progress log: %v\n", err)
+// completePlanFile moves the completed plan to the completed/ directory, or to the archive directory if
+// configured. review modes leave the plan in place.
+func completePlanFile(req executePlanRequest) {
+	if req.PlanFile == "" || !modeRequiresBranch(req.Mode) {
+		return
+	}
+	if req.Config != nil && req.Config.ArchivePlans {
+		if archiveErr := req.GitSvc.ArchivePlan(req.PlanFile, req.Config.ArchiveDir, time.Now()); archiveErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to archive plan: %v\n", archiveErr)
 		}
-		baseLogClosed = true
-		req.Colors.Info().Printf("web dashboard still running at http://localhost:%d (press Ctrl+C to exit)\n", o.Port)
-		<-ctx.Done()
+		return
+	}
+	if moveErr := req.GitSvc.MovePlanToCompleted(req.PlanFile); moveErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to move plan to completed: %v\n", moveErr)
 	}
-
-	return nil
 }
 
-// openGitService creates a git.Service for the current directory.
-func openGitService(colors *progress.Colors) (*git.Service, error) {
-	svc, err := git.NewService(".", colors.Info())
+// emitPatch writes changes made since base to path as a patch series and restores the worktree to base.
+// nothing is written if there are no changes.
+func emitPatch(req executePlanRequest, path, base string) error {
+	patch, err := req.GitSvc.ExtractChanges(base)
 	if err != nil {
-		return nil, fmt.Errorf("new git service: %w", err)
+		return fmt.Errorf("emit patch: %w", err)
 	}
-	return svc, nil
-}
-
-// checkPrimaryCommandDep checks that the primary tool command is available in PATH.
-func checkPrimaryCommandDep(cfg *config.Config) error {
-	primaryCmd := cfg.ClaudeCommand
-	if primaryCmd == "" {
-		primaryCmd = "codex"
+	if len(patch) == 0 {
+		req.Colors.Info().Printf("no changes made, %s not written\n", path)
+		return nil
 	}
-	if _, err := exec.LookPath(primaryCmd); err != nil {
-		return fmt.Errorf("%s not found in PATH", primaryCmd)
+	if err := os.WriteFile(path, patch, 0o600); err != nil {
+		return fmt.Errorf("emit patch: write %s: %w", path, err)
 	}
+	req.Colors.Info().Printf("changes written to %s and removed from the worktree, apply with: git am %s\n", path, path)
 	return nil
 }
 
-// isWatchOnlyMode returns true if running in watch-only mode.
-// watch-only mode runs the web dashboard without executing any plan.
-func isWatchOnlyMode(o opts, configWatchDirs []string) bool {
-	return o.Serve && o.PlanFile == "" && o.PlanDescription == "" && (len(o.Watch) > 0 || len(configWatchDirs) > 0)
+// needsHumanNotifier returns a function sending a needs_human notification for the run, called when
+// the run pauses for a problem only a human can fix.
+func needsHumanNotifier(req executePlanRequest) func(reason string) {
+	return func(reason string) {
+		req.NotifySvc.Send(context.Background(), notify.Result{Status: "needs_human", Mode: string(req.Mode),
+			PlanFile: req.PlanFile, Error: reason})
+	}
+}
+
+// needsInputAnswerer returns the reader of answers to NEEDS_INPUT questions of the agent from stdin.
+// without a terminal, e.g. an answer piped in by a wrapper, it waits needs_input_timeout_ms and fails after it.
+func needsInputAnswerer(cfg *config.Config, stdin *os.File, stdout io.Writer) func(context.Context, string) (string, error) {
+	return func(ctx context.Context, question string) (string, error) {
+		if !term.IsTerminal(int(stdin.Fd())) {
+			if cfg.NeedsInputTimeoutMs <= 0 {
+				return "", errors.New("no terminal to ask the question, needs_input_timeout_ms is 0")
+			}
+			timeout := time.Duration(cfg.NeedsInputTimeoutMs) * time.Millisecond
+			tctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			answer, err := input.ReadAnswer(tctx, question, stdin, stdout)
+			if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+				return "", fmt.Errorf("no answer within %s", timeout)
+			}
+			return answer, err
+		}
+		return input.ReadAnswer(ctx, question, stdin, stdout)
+	}
+}
+
+// printFinalVerification shows the findings the final verification left unfixed, nothing if it didn't run.
+func printFinalVerification(colors *progress.Colors, fv *processor.VerificationReport) {
+	if fv == nil {
+		return
+	}
+	if len(fv.Residual) == 0 {
+		colors.Info().Printf("final verification (%s): no issues left\n", fv.Tool)
+		return
+	}
+	colors.Warn().Printf("final verification (%s): %d findings left unfixed\n", fv.Tool, len(fv.Residual))
+	for _, f := range fv.Residual {
+		colors.Warn().Printf("  %s: %s\n", f.File, f.Message)
+	}
+}
+
+// writeRunReport writes the report of a run as JSON for wrappers and CI.
+func writeRunReport(path string, report processor.RunReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("run report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("run report: write %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeCrashReport writes the crash report of a run ended by a panic to path, with the ralphex version
+func writeCrashReport(path string, panicErr *processor.PanicError) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("crash report: %w", err)
+	}
+	data := fmt.Sprintf("ralphex %s, %s/%s, %s\n\n%s", resolveVersion(), runtime.GOOS, runtime.GOARCH,
+		time.Now().Format(time.RFC3339), panicErr.Report())
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		return fmt.Errorf("crash report: write %s: %w", path, err)
+	}
+	return nil
+}
+
+// newLogShipper creates the run event shipper labeled with the plan, mode and branch of the run,
+// nil if log shipping is not configured.
+func newLogShipper(req executePlanRequest, branch string) (*logship.Shipper, error) {
+	params := req.Config.LogShipParams
+	params.Labels = map[string]string{"mode": string(req.Mode), "branch": branch}
+	if req.PlanFile != "" {
+		params.Labels["plan"] = filepath.Base(req.PlanFile)
+	}
+	shipper, err := logship.New(params)
+	if err != nil {
+		return nil, fmt.Errorf("create log shipper: %w", err)
+	}
+	return shipper, nil
+}
+
+// publishArtifacts uploads the progress log, the run report, the prompt transcripts and branch patches
+// if publishing is configured, returning links to them. errors are logged as warnings and don't fail the run.
+func publishArtifacts(req executePlanRequest, run finishedRun, report processor.RunReport) []string {
+	if req.Artifacts == nil {
+		return nil
+	}
+	files := []string{run.log.Path()}
+	if run.transcripts != "" {
+		// the directory is created with the first transcript, a run may have written none
+		if _, err := os.Stat(run.transcripts); err == nil {
+			files = append(files, run.transcripts)
+		}
+	}
+	dir, err := artifacts.Stage(files, func(dir string) error {
+		_, patchErr := req.GitSvc.FormatPatches(req.DefaultBranch, dir)
+		return patchErr
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to stage artifacts: %v\n", err)
+		return nil
+	}
+	defer os.RemoveAll(dir)
+	if err := writeRunReport(filepath.Join(dir, "report.json"), report); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	// background context, the run is complete and the upload timeout is applied inside Publish
+	links, err := req.Artifacts.Publish(context.Background(), dir, run.runID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to publish artifacts: %v\n", err)
+		return nil
+	}
+	for _, link := range links {
+		req.Colors.Info().Printf("artifacts: %s\n", link)
+	}
+	return links
+}
+
+// annotatePlan appends the run outcome to the plan file if annotate_plan is enabled.
+// on a feature branch the annotation is committed, otherwise it is left in the worktree.
+// failures are logged as warnings and never fail the run.
+func annotatePlan(req executePlanRequest, o opts, outcome plan.Outcome) {
+	if req.PlanFile == "" || req.Config == nil || !req.Config.AnnotatePlan {
+		return
+	}
+	if err := plan.AppendOutcome(req.PlanFile, outcome); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to annotate plan: %v\n", err)
+		return
+	}
+	// --emit-patch leaves no commits behind, the annotation stays uncommitted there as well
+	if !modeRequiresBranch(req.Mode) || o.EmitPatch != "" {
+		return
+	}
+	msg := "record run outcome: " + filepath.Base(req.PlanFile)
+	if err := req.GitSvc.CommitPlanUpdate(req.PlanFile, msg); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to commit plan annotation: %v\n", err)
+	}
+}
+
+// newTelemetry creates telemetry keeping its state in the state directory.
+// stats kept in the global config directory by earlier versions are moved there first.
+func newTelemetry(cfg *config.Config) *telemetry.Telemetry {
+	if err := cfg.MigrateStateFile(telemetry.StateFile); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telemetry: %v\n", err)
+	}
+	return telemetry.New(telemetry.Params{Dir: cfg.StateDir(), Endpoint: cfg.TelemetryEndpoint, Version: resolveVersion()})
+}
+
+// recordTelemetry adds the run to the usage stats if telemetry is enabled.
+// failures are logged as warnings and never fail the run.
+func recordTelemetry(req executePlanRequest, iterations int, runErr error) {
+	if req.Telemetry == nil {
+		return
+	}
+	// background context, the run context may be canceled and the report timeout is applied inside Record
+	run := telemetry.Run{Mode: string(req.Mode), Iterations: iterations, Err: runErr}
+	if err := req.Telemetry.Record(context.Background(), run); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record usage stats: %v\n", err)
+	}
+}
+
+// recordHistory appends the run outcome to the repository's run history, shown by "ralphex stats",
+// and reports findings of the run recurring across runs. failures are logged as warnings and never fail the run.
+func recordHistory(req executePlanRequest, e history.Entry, runErr error) {
+	e.Success = runErr == nil
+	if runErr != nil {
+		e.Failure = telemetry.Classify(runErr)
+		e.Stalled = e.Failure == telemetry.FailureMaxIterations || e.Failure == telemetry.FailureTimeout
+	}
+	dir := req.artifactPath("")
+	entries, err := history.Load(dir, req.sealer())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to load run history: %v\n", err)
+	}
+	if req.Config != nil {
+		e.Prompts, e.PromptVersions = req.Config.PromptsHash(), req.Config.PromptVersions()
+		e.Canary = history.Canary(entries, e.Prompts)
+	}
+	if err := history.Append(dir, e, req.sealer()); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record run history: %v\n", err)
+	}
+	reportChronicFindings(req.Colors, history.ChronicFindings(append(entries, e)), e.Findings, os.Stdout)
+}
+
+// historyFindings converts the external review findings of a run for the run history.
+func historyFindings(findings []processor.Finding) []history.Finding {
+	res := make([]history.Finding, 0, len(findings))
+	for _, f := range findings {
+		res = append(res, history.Finding{File: f.File, Message: f.Message})
+	}
+	return res
+}
+
+// suggestBaseline offers the findings the run dismissed as invalid, and earlier runs did too, for the
+// project baseline, so the external review stops reporting them. with update set they are added without asking.
+// failures are logged as warnings and never fail the run.
+func suggestBaseline(ctx context.Context, req executePlanRequest, dismissed []history.Finding, update bool,
+	stdin io.Reader, stdout io.Writer) {
+	if len(dismissed) == 0 {
+		return
+	}
+	entries, err := history.Load(req.artifactPath(""), req.sealer())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to load run history: %v\n", err)
+		return
+	}
+	accepted, err := baseline.Load(baselinePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return
+	}
+	var candidates []history.Finding
+	for _, c := range history.Recurring(history.DismissedFindings(entries), dismissed) {
+		if !baseline.Contains(accepted, c.Finding) {
+			candidates = append(candidates, c.Finding)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	req.Colors.Info().Fprintf(stdout, "findings dismissed as invalid in %d or more runs:\n", history.DismissedRuns)
+	for _, f := range candidates {
+		fmt.Fprintf(stdout, "  %s: %s\n", f.File, f.Message)
+	}
+	if !update && !input.AskYesNo(ctx, "add them to "+baselinePath+", so the external review stops reporting them?", stdin, stdout) {
+		fmt.Fprintln(stdout, "not added, run with --update-baseline to add them without asking")
+		return
+	}
+	added, err := baseline.Add(baselinePath, candidates)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return
+	}
+	fmt.Fprintf(stdout, "added %d findings to %s\n", added, baselinePath)
+}
+
+// loadBaseline returns the accepted findings of the project baseline for the external review.
+// a baseline that can't be read is logged as a warning and ignored.
+func loadBaseline() []processor.Finding {
+	accepted, err := baseline.Load(baselinePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return nil
+	}
+	res := make([]processor.Finding, 0, len(accepted))
+	for _, f := range accepted {
+		res = append(res, processor.Finding{File: f.File, Message: f.Message})
+	}
+	return res
+}
+
+// reportChronicFindings lists the findings of the run that were reported by earlier runs too,
+// with what can be done about them instead of having them re-reported and re-triaged every run.
+func reportChronicFindings(colors *progress.Colors, chronic []history.Chronic, findings []history.Finding, stdout io.Writer) {
+	recurring := history.Recurring(chronic, findings)
+	if len(recurring) == 0 {
+		return
+	}
+	colors.Warn().Fprintf(stdout, "chronic findings, reported by external review in %d or more runs:\n", history.ChronicRuns)
+	printChronic(recurring, stdout)
+	fmt.Fprintln(stdout, "fix them for real, baseline them, or record them as accepted in the project memory (CLAUDE.md)")
+}
+
+// printChronic prints chronic findings, one per line.
+func printChronic(chronic []history.Chronic, stdout io.Writer) {
+	for _, c := range chronic {
+		fmt.Fprintf(stdout, "  %s: %s (%d runs, last %s)\n", c.File, c.Message, c.Runs, c.Last.Format("2006-01-02"))
+	}
+}
+
+// warnPromptChange warns before the first run with prompt templates differing from the last recorded run,
+// if enabled by warn_prompt_change.
+func warnPromptChange(req executePlanRequest) {
+	if req.Config == nil || !req.Config.WarnPromptChange {
+		return
+	}
+	entries, err := history.Load(req.artifactPath(""), req.sealer())
+	if err != nil || !history.PromptsChanged(entries, req.Config.PromptsHash()) {
+		return
+	}
+	changed := ""
+	if names := history.ChangedPrompts(history.LastPromptVersions(entries), req.Config.PromptVersions()); len(names) > 0 {
+		changed = " (" + strings.Join(names, ", ") + ")"
+	}
+	req.Colors.Warn().Printf("prompt templates changed since the last run%s, this and the next %d runs "+
+		"are marked as canary runs in \"ralphex stats\"\n", changed, history.CanaryRuns-1)
+}
+
+// promptChangesShown is the number of the latest prompt changes listed by "ralphex stats"
+const promptChangesShown = 5
+
+// runStats handles "ralphex stats": overall success rate, mean iterations and stall frequency of the
+// repository's runs, the latest prompt changes, then the same per week for the last weeks.
+func runStats(dir string, s *seal.Sealer, weeks int, now time.Time, colors *progress.Colors, stdout io.Writer) error {
+	entries, err := history.Load(dir, s)
+	if err != nil {
+		return fmt.Errorf("load run history: %w", err)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(stdout, "no run history in %s yet\n", dir)
+		return nil
+	}
+	total := history.Summarize(entries)
+	colors.Info().Fprintf(stdout, "%d runs since %s: %s\n", total.Runs, total.Start.Format("2006-01-02"), formatSummary(total))
+	for _, f := range slices.Sorted(maps.Keys(total.Failures)) {
+		fmt.Fprintf(stdout, "  failure %s: %d\n", f, total.Failures[f])
+	}
+	if chronic := history.ChronicFindings(entries); len(chronic) > 0 {
+		fmt.Fprintf(stdout, "chronic findings, reported in %d or more runs:\n", history.ChronicRuns)
+		printChronic(chronic, stdout)
+	}
+	if since, before, ok := history.LastCanary(entries); ok {
+		fmt.Fprintf(stdout, "since prompt change on %s: %d runs, %s\n", since.Start.Format("2006-01-02"), since.Runs,
+			formatSummary(since))
+		if before.Runs > 0 {
+			fmt.Fprintf(stdout, "  before: %d runs, %s\n", before.Runs, formatSummary(before))
+		}
+	}
+	if changes := history.PromptChanges(entries); len(changes) > 0 {
+		fmt.Fprintln(stdout, "prompt changes:")
+		for _, c := range changes[max(0, len(changes)-promptChangesShown):] {
+			changed := "templates not recorded"
+			if len(c.Changed) > 0 {
+				changed = strings.Join(c.Changed, ", ")
+			}
+			colors.Warn().Fprintf(stdout, "  %s: %s -> %s, %s\n", c.Time.Format("2006-01-02"), c.From, c.To, changed)
+		}
+	}
+	if weeks <= 0 {
+		return nil
+	}
+	fmt.Fprintln(stdout, "per week:")
+	for _, w := range history.Weekly(entries, weeks, now) {
+		if w.Runs == 0 {
+			fmt.Fprintf(stdout, "  %s: no runs\n", w.Start.Format("2006-01-02"))
+			continue
+		}
+		canaries := ""
+		if w.Canaries > 0 {
+			canaries = fmt.Sprintf(" (%d canary)", w.Canaries)
+		}
+		fmt.Fprintf(stdout, "  %s: %d runs%s, %s\n", w.Start.Format("2006-01-02"), w.Runs, canaries, formatSummary(w))
+	}
+	return nil
+}
+
+// formatSummary formats the rates of a history summary.
+func formatSummary(s history.Summary) string {
+	return fmt.Sprintf("%.0f%% succeeded, %.1f task iterations on average, %.0f%% stalled",
+		100*s.SuccessRate(), s.MeanIterations(), 100*s.StallRate())
+}
+
+// runDecrypt handles "ralphex decrypt": prints an artifact encrypted with artifact_key. each line is decrypted
+// on its own, so run history with entries written before and after encryption was turned on reads whole.
+func runDecrypt(path string, s *seal.Sealer, stdout io.Writer) error {
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided artifact file
+	if err != nil {
+		return fmt.Errorf("read artifact: %w", err)
+	}
+	for line := range strings.SplitAfterSeq(string(data), "\n") {
+		plain, err := s.Open([]byte(line))
+		if err != nil {
+			return fmt.Errorf("decrypt %s: %w", path, err)
+		}
+		if seal.IsSealed([]byte(line)) && !strings.HasSuffix(string(plain), "\n") {
+			plain = append(plain, '\n')
+		}
+		if _, err := stdout.Write(plain); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+	}
+	return nil
+}
+
+// runTelemetry handles "ralphex telemetry [status|on|off]".
+func runTelemetry(cmd string, tel *telemetry.Telemetry, colors *progress.Colors, stdout io.Writer) error {
+	switch cmd {
+	case "telemetry on":
+		if err := tel.SetEnabled(true); err != nil {
+			return fmt.Errorf("enable telemetry: %w", err)
+		}
+	case "telemetry off":
+		if err := tel.SetEnabled(false); err != nil {
+			return fmt.Errorf("disable telemetry: %w", err)
+		}
+	}
+
+	st, err := tel.Load()
+	if err != nil {
+		return fmt.Errorf("load telemetry: %w", err)
+	}
+	if !st.Enabled {
+		colors.Info().Fprintf(stdout, "telemetry is off\n")
+		return nil
+	}
+	colors.Info().Fprintf(stdout, "telemetry is on\n")
+	if tel.Endpoint() == "" {
+		fmt.Fprintln(stdout, "no telemetry_endpoint configured, stats are kept locally only")
+	} else {
+		fmt.Fprintf(stdout, "reporting to %s\n", tel.Endpoint())
+	}
+	if telemetry.DoNotTrack() {
+		colors.Warn().Fprintf(stdout, "DO_NOT_TRACK is set, nothing is recorded\n")
+	}
+	printStats := func(title string, s telemetry.Stats) {
+		fmt.Fprintf(stdout, "%s: %d runs, %d succeeded, %d task iterations\n", title, s.Runs, s.Successes, s.Iterations)
+		for _, m := range slices.Sorted(maps.Keys(s.Modes)) {
+			fmt.Fprintf(stdout, "  mode %s: %d\n", m, s.Modes[m])
+		}
+		for _, f := range slices.Sorted(maps.Keys(s.Failures)) {
+			fmt.Fprintf(stdout, "  failure %s: %d\n", f, s.Failures[f])
+		}
+	}
+	printStats("total", st.Total)
+	if tel.Endpoint() != "" {
+		printStats("not reported yet", st.Pending)
+	}
+	return nil
+}
+
+// openGitService creates a git.Service for the current directory using the configured git binary.
+func openGitService(gitCommand string, colors *progress.Colors) (*git.Service, error) {
+	svc, err := git.NewServiceWithCommand(".", gitCommand, colors.Info())
+	if err != nil {
+		return nil, fmt.Errorf("new git service: %w", err)
+	}
+	return svc, nil
+}
+
+// checkPrimaryCommandDep checks that the primary tool command is available in PATH.
+func checkPrimaryCommandDep(cfg *config.Config) error {
+	primaryCmd := cfg.ClaudeCommand
+	if primaryCmd == "" {
+		primaryCmd = "codex"
+	}
+	if _, err := exec.LookPath(primaryCmd); err != nil {
+		return fmt.Errorf("%s not found in PATH", primaryCmd)
+	}
+	return nil
+}
+
+// isWatchOnlyMode returns true if running in watch-only mode.
+// watch-only mode runs the web dashboard without executing any plan.
+func isWatchOnlyMode(o opts, configWatchDirs []string) bool {
+	return o.Serve && o.PlanFile == "" && o.PlanDescription == "" && (len(o.Watch) > 0 || len(configWatchDirs) > 0)
 }
 
 // runWatchOnly starts the web dashboard in watch-only mode without plan execution.
@@ -487,12 +1432,221 @@ func modeRequiresBranch(mode processor.Mode) bool {
 
 // validateFlags checks for conflicting CLI flags.
 func validateFlags(o opts) error {
-	if o.PlanDescription != "" && o.PlanFile != "" {
+	if err := validatePlanFlags(o); err != nil {
+		return err
+	}
+	if o.PlanSpec != "" {
+		o.PlanDescription = o.PlanSpec // the checks below treat both as plan mode
+	}
+	validators := []func(opts) error{validatePatchFlags, validateSubcommandFlags, validateResumeFlags, validateLimitFlags,
+		validatePhaseFlags, validateTaskFlags}
+	for _, validate := range validators {
+		if err := validate(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePlanFlags checks the plan file argument, --plan and --plan-spec, only one of them can be given.
+func validatePlanFlags(o opts) error {
+	if o.PlanSpec != "" && o.PlanDescription != "" {
+		return errors.New("--plan-spec conflicts with --plan; use one or the other")
+	}
+	if (o.PlanDescription != "" || o.PlanSpec != "") && o.PlanFile != "" {
 		return errors.New("--plan flag conflicts with plan file argument; use one or the other")
 	}
 	return nil
 }
 
+// validatePatchFlags checks --emit-patch, it needs a review mode and changes the worktree.
+func validatePatchFlags(o opts) error {
+	if o.EmitPatch != "" && !o.Review && !o.ExternalOnly && !o.CodexOnly {
+		return errors.New("--emit-patch requires --review or --external-only")
+	}
+	if o.DryRun && o.EmitPatch != "" {
+		return errors.New("--dry-run makes no changes, it can't be combined with --emit-patch")
+	}
+	return nil
+}
+
+// validateSubcommandFlags checks the run flags given with a subcommand running on its own.
+func validateSubcommandFlags(o opts) error {
+	if o.subcommand == "apply" && (o.PlanFile != "" || o.PlanDescription != "" || o.Review || o.ExternalOnly || o.CodexOnly ||
+		o.TasksOnly || o.EmitPatch != "" || o.Serve || o.DryRun || o.Resume) {
+		return errors.New("ralphex apply runs on its own, without plan, mode, --serve, --dry-run or --resume flags")
+	}
+	return nil
+}
+
+// validateResumeFlags checks --resume, the resumed run keeps the mode of the interrupted one.
+func validateResumeFlags(o opts) error {
+	if o.Resume && (o.PlanDescription != "" || o.Review || o.ExternalOnly || o.CodexOnly || o.TasksOnly || o.DryRun ||
+		o.EmitPatch != "") {
+		return errors.New("--resume continues the interrupted run in its mode, it can't be combined with mode flags, " +
+			"--dry-run or --emit-patch")
+	}
+	return nil
+}
+
+// validateLimitFlags checks the run budget flags, --max-duration and --run-window.
+func validateLimitFlags(o opts) error {
+	if o.MaxDuration < 0 {
+		return errors.New("--max-duration must be positive")
+	}
+	if o.RunWindow != "" {
+		if _, err := config.ParseRunWindow(o.RunWindow); err != nil {
+			return fmt.Errorf("--run-window: %w", err)
+		}
+	}
+	return nil
+}
+
+// validatePhaseFlags checks the flags skipping review phases, they need a mode running the phases.
+func validatePhaseFlags(o opts) error {
+	if (o.SkipFirstReview || o.SkipCodex || o.SkipSecond) && (o.PlanDescription != "" || o.TasksOnly) {
+		return errors.New("--skip-first-review, --skip-codex and --skip-second-review leave out review phases, " +
+			"they need a mode running them")
+	}
+	if o.SkipCodex && (o.ExternalOnly || o.CodexOnly) {
+		return errors.New("--skip-codex conflicts with --external-only")
+	}
+	return nil
+}
+
+// validateTaskFlags checks the task selection flags, --start-task and --only-tasks.
+func validateTaskFlags(o opts) error {
+	if o.StartTask == "" && len(o.OnlyTasks) == 0 {
+		return nil
+	}
+	if o.PlanDescription != "" || o.Review || o.ExternalOnly || o.CodexOnly || o.subcommand == "apply" {
+		return errors.New("--start-task and --only-tasks select plan tasks, they need a mode running the task phase")
+	}
+	for _, sel := range append([]string{o.StartTask}, o.OnlyTasks...) {
+		if err := plan.ValidTaskSelector(sel); sel != "" && err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inboxPath returns the file of user notes for the agent, empty for a dry run sending no prompts.
+func inboxPath(req executePlanRequest, o opts) string {
+	if o.DryRun {
+		return ""
+	}
+	return req.artifactPath("inbox")
+}
+
+// maxRunDuration returns the run budget, --max-duration or max_run_duration_ms from config.
+func maxRunDuration(o opts, cfg *config.Config) time.Duration {
+	if o.MaxDuration > 0 {
+		return o.MaxDuration
+	}
+	return time.Duration(cfg.MaxRunDurationMs) * time.Millisecond
+}
+
+// runWindow returns the time of day the run is allowed in, --run-window or run_window from config.
+// the flag is validated by validateFlags.
+func runWindow(o opts, cfg *config.Config) config.RunWindow {
+	if o.RunWindow != "" {
+		if w, err := config.ParseRunWindow(o.RunWindow); err == nil {
+			return w
+		}
+	}
+	return cfg.RunWindow
+}
+
+// startPlugins starts the plugins configured with plugin_<name> keys, in name order.
+// a plugin failing to start fails the run, plugins started before it are stopped.
+func startPlugins(cfg *config.Config) ([]*plugin.Client, error) {
+	var res []*plugin.Client
+	for _, name := range slices.Sorted(maps.Keys(cfg.Plugins)) {
+		c, err := plugin.Start(name, cfg.Plugins[name])
+		if err != nil {
+			stopPlugins(res)
+			return nil, fmt.Errorf("start plugin: %w", err)
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+// stopPlugins stops running plugins.
+func stopPlugins(plugins []*plugin.Client) {
+	for _, p := range plugins {
+		p.Close()
+	}
+}
+
+// pluginExecutors returns the executors of plugins providing one, by plugin name.
+func pluginExecutors(plugins []*plugin.Client) map[string]processor.Executor {
+	res := map[string]processor.Executor{}
+	for _, p := range plugins {
+		if p.Provides(plugin.KindExecutor) {
+			res[p.Name()] = p
+		}
+	}
+	return res
+}
+
+// pluginAnalyzers returns the findings providers of plugins providing one, by plugin name.
+func pluginAnalyzers(plugins []*plugin.Client) map[string]analyzer.FindingsProvider {
+	res := map[string]analyzer.FindingsProvider{}
+	for _, p := range plugins {
+		if p.Provides(plugin.KindFindings) {
+			res[p.Name()] = p.FindingsProvider()
+		}
+	}
+	return res
+}
+
+// pluginNotifiers returns the notifiers of plugins providing one, by plugin name.
+func pluginNotifiers(plugins []*plugin.Client) map[string]notify.Sender {
+	res := map[string]notify.Sender{}
+	for _, p := range plugins {
+		if p.Provides(plugin.KindNotifier) {
+			res[p.Name()] = p
+		}
+	}
+	return res
+}
+
+// retryPolicy returns the retry policy of failed executor calls from config.
+func retryPolicy(cfg *config.Config) processor.RetryPolicy {
+	return processor.RetryPolicy{
+		Count:    cfg.ExecutorRetryCount,
+		Delay:    time.Duration(cfg.ExecutorRetryDelayMs) * time.Millisecond,
+		MaxDelay: time.Duration(cfg.ExecutorRetryMaxDelayMs) * time.Millisecond,
+		Jitter:   cfg.ExecutorRetryJitter,
+	}
+}
+
+// checkpointExists reports whether a failed run left a checkpoint to resume from.
+func checkpointExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// loadResumeCheckpoint loads the checkpoint of an interrupted run at path for --resume.
+// a plan file given on the command line must be the one the checkpoint is for.
+func loadResumeCheckpoint(o opts, path string, s *seal.Sealer) (*processor.Checkpoint, error) {
+	cp, err := processor.LoadCheckpoint(path, s)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("nothing to resume, no checkpoint at %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resume: %w", err)
+	}
+	if o.PlanFile != "" {
+		abs, absErr := filepath.Abs(o.PlanFile)
+		if absErr != nil || abs != cp.PlanFile {
+			return nil, fmt.Errorf("checkpoint is for plan %q, not %s", cp.PlanFile, o.PlanFile)
+		}
+	}
+	return cp, nil
+}
+
 // createRunner creates a processor.Runner with the given configuration.
 func createRunner(req executePlanRequest, o opts, log processor.Logger, holder *status.PhaseHolder) *processor.Runner {
 	// --codex-only mode forces codex enabled regardless of config
@@ -500,19 +1654,51 @@ func createRunner(req executePlanRequest, o opts, log processor.Logger, holder *
 	if req.Mode == processor.ModeCodexOnly {
 		codexEnabled = true
 	}
+	// a dry run has nothing to resume or stop
+	checkpointPath, stopFile := req.artifactPath("state.json"), req.artifactPath("stop")
+	if o.DryRun {
+		checkpointPath, stopFile = "", ""
+	}
 	r := processor.New(processor.Config{
 		PlanFile:         req.PlanFile,
 		ProgressPath:     log.Path(),
 		Mode:             req.Mode,
 		MaxIterations:    o.MaxIterations,
-		Debug:            o.Debug,
+		Debug:            o.debug,
+		DryRun:           o.DryRun,
+		CheckpointPath:   checkpointPath,
+		StopFile:         stopFile,
+		InboxFile:        inboxPath(req, o),
+		Resume:           req.Resume,
+		TranscriptDir:    req.artifactPath("transcripts"),
 		NoColor:          o.NoColor,
 		IterationDelayMs: req.Config.IterationDelayMs,
 		TaskRetryCount:   req.Config.TaskRetryCount,
 		CodexEnabled:     codexEnabled,
+		RepeatUntilClean: req.Config.RepeatUntilClean,
+		TaskTimeout:      time.Duration(req.Config.TaskPhaseTimeoutMs) * time.Millisecond,
+		ReviewTimeout:    time.Duration(req.Config.ReviewPhaseTimeoutMs) * time.Millisecond,
+		CodexTimeout:     time.Duration(req.Config.CodexPhaseTimeoutMs) * time.Millisecond,
+		MaxRunDuration:   maxRunDuration(o, req.Config),
+		RunWindow:        runWindow(o, req.Config),
+		Phases:           req.Config.Phases,
+		Baseline:         loadBaseline(),
+		Retry:            retryPolicy(req.Config),
+		ResolveFindings:  req.Config.RequireFindingResolution,
+		VerifyReviewDone: req.Config.VerifyReviewDone,
+		PluginExecutors:  pluginExecutors(req.Plugins),
+		PluginAnalyzers:  pluginAnalyzers(req.Plugins),
+		Pause:            o.pause,
+		NeedsHuman:       needsHumanNotifier(req),
+		AnswerInput:      needsInputAnswerer(req.Config, os.Stdin, os.Stdout),
 		FinalizeEnabled:  req.Config.FinalizeEnabled,
 		DefaultBranch:    req.DefaultBranch,
 		AppConfig:        req.Config,
+		StartTask:        o.StartTask,
+		OnlyTasks:        o.OnlyTasks,
+		SkipFirstReview:  o.SkipFirstReview,
+		SkipCodex:        o.SkipCodex,
+		SkipSecondReview: o.SkipSecond,
 	}, log, holder)
 	if req.GitSvc != nil {
 		r.SetGitChecker(req.GitSvc)
@@ -521,6 +1707,13 @@ func createRunner(req executePlanRequest, o opts, log processor.Logger, holder *
 }
 
 func printStartupInfo(info startupInfo, colors *progress.Colors) {
+	if info.Mode == processor.ModePlan && info.PlanSpec != "" {
+		colors.Info().Printf("drafting plan from spec file\n")
+		colors.Info().Printf("spec: %s\n", toRelPath(info.PlanSpec))
+		colors.Info().Printf("branch: %s (max %d iterations)\n", info.Branch, info.MaxIterations)
+		colors.Info().Printf("progress log: %s\n\n", info.ProgressPath)
+		return
+	}
 	if info.Mode == processor.ModePlan {
 		colors.Info().Printf("starting interactive plan creation\n")
 		colors.Info().Printf("request: %s\n", info.PlanDescription)
@@ -541,13 +1734,14 @@ func printStartupInfo(info startupInfo, colors *progress.Colors) {
 	colors.Info().Printf("progress log: %s\n\n", info.ProgressPath)
 }
 
-// runPlanMode executes interactive plan creation mode.
+// runPlanMode executes plan creation mode.
 // creates input collector, progress logger, and runs the plan creation loop.
-// after plan creation, prompts user to continue with implementation or exit.
+// after interactive plan creation, prompts user to continue with implementation or exit.
+// a plan drafted from --plan-spec stops once the plan is written.
 func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 	// ensure gitignore has progress files
-	if err := req.GitSvc.EnsureIgnored(".ralphex/progress/", ".ralphex/progress/progress-test.txt"); err != nil {
-		return fmt.Errorf("ensure gitignore: %w", err)
+	if err := ensureArtifactsIgnored(req.GitSvc, req.Config); err != nil {
+		return err
 	}
 
 	branch := getCurrentBranch(req.GitSvc)
@@ -555,12 +1749,18 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 	// create shared phase holder (single source of truth for current phase)
 	holder := &status.PhaseHolder{}
 
-	// create progress logger for plan mode
+	// create progress logger for plan mode, named after the spec file for a plan drafted from one
+	progressName := o.PlanDescription
+	if o.PlanSpec != "" {
+		progressName = strings.TrimSuffix(filepath.Base(o.PlanSpec), filepath.Ext(o.PlanSpec))
+	}
 	baseLog, err := progress.NewLogger(progress.Config{
-		PlanDescription: o.PlanDescription,
+		PlanDescription: progressName,
 		Mode:            string(processor.ModePlan),
 		Branch:          branch,
+		Dir:             req.artifactPath("progress"),
 		NoColor:         o.NoColor,
+		Accessible:      req.Config.AccessibleOutput,
 	}, req.Colors, holder)
 	if err != nil {
 		return fmt.Errorf("create progress logger: %w", err)
@@ -574,6 +1774,7 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 	// print startup info for plan mode
 	printStartupInfo(startupInfo{
 		PlanDescription: o.PlanDescription,
+		PlanSpec:        o.PlanSpec,
 		Branch:          branch,
 		Mode:            processor.ModePlan,
 		MaxIterations:   o.MaxIterations,
@@ -589,19 +1790,31 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 	// create and configure runner
 	r := processor.New(processor.Config{
 		PlanDescription:  o.PlanDescription,
+		PlanSpec:         o.PlanSpec,
 		ProgressPath:     baseLog.Path(),
 		Mode:             processor.ModePlan,
 		MaxIterations:    o.MaxIterations,
-		Debug:            o.Debug,
+		Debug:            o.debug,
+		DryRun:           o.DryRun,
+		TranscriptDir:    req.artifactPath("transcripts"),
 		NoColor:          o.NoColor,
 		IterationDelayMs: req.Config.IterationDelayMs,
+		Retry:            retryPolicy(req.Config),
 		DefaultBranch:    req.DefaultBranch,
 		AppConfig:        req.Config,
 	}, baseLog, holder)
 	r.SetInputCollector(collector)
 
 	// run the plan creation loop
-	if runErr := r.Run(ctx); runErr != nil {
+	_, runErr := r.Run(ctx)
+	if o.DryRun {
+		if runErr != nil {
+			return fmt.Errorf("dry run: %w", runErr)
+		}
+		return nil
+	}
+	recordTelemetry(req, 0, runErr)
+	if runErr != nil {
 		return fmt.Errorf("plan creation: %w", runErr)
 	}
 
@@ -616,8 +1829,8 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 		req.Colors.Info().Printf("\nplan creation completed in %s\n", elapsed)
 	}
 
-	// if no plan file found, can't continue to implementation
-	if planFile == "" {
+	// if no plan file found, can't continue to implementation; a plan drafted from a spec stops here
+	if planFile == "" || o.PlanSpec != "" {
 		return nil
 	}
 
@@ -642,9 +1855,322 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 		Colors:        req.Colors,
 		DefaultBranch: req.DefaultBranch,
 		NotifySvc:     req.NotifySvc,
+		Artifacts:     req.Artifacts,
+		ArtifactDir:   req.ArtifactDir,
+		Plugins:       req.Plugins,
 	})
 }
 
+// readPlanSpec returns the content of a spec file a plan is drafted from.
+func readPlanSpec(path string) (string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided spec file
+	if err != nil {
+		return "", fmt.Errorf("read plan spec: %w", err)
+	}
+	spec := strings.TrimSpace(string(data))
+	if spec == "" {
+		return "", fmt.Errorf("plan spec %s is empty", path)
+	}
+	return spec, nil
+}
+
+// runApply lets the user pick fixes from a patch file written by --emit-patch and commits the accepted ones.
+func runApply(ctx context.Context, path string, cfg *config.Config, colors *progress.Colors) error {
+	series, err := os.ReadFile(path) //nolint:gosec // user-provided patch file
+	if err != nil {
+		return fmt.Errorf("read patch file: %w", err)
+	}
+	patches := git.SplitPatches(series)
+	if len(patches) == 0 {
+		return fmt.Errorf("no patches found in %s", path)
+	}
+	if _, statErr := os.Stat(".git"); statErr != nil {
+		return errors.New("must run from repository root (no .git directory found)")
+	}
+	gitSvc, err := openGitService(cfg.GitCommand, colors)
+	if err != nil {
+		return fmt.Errorf("open git repo: %w", err)
+	}
+	return applyPatches(ctx, gitSvc, patches, os.Stdin, os.Stdout)
+}
+
+// commandPath returns the names of the active command chain, e.g. "plan lint", or empty if no command is active.
+func commandPath(c *flags.Command) string {
+	var names []string
+	for ; c != nil; c = c.Active {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+// planEstimateCommand holds options of "ralphex plan estimate".
+type planEstimateCommand struct {
+	Args struct {
+		PlanFile string `positional-arg-name:"plan-file" description:"plan file to estimate (optional, uses fzf if omitted)"`
+	} `positional-args:"yes"`
+}
+
+// runSubcommand dispatches the active subcommand.
+func runSubcommand(ctx context.Context, o opts, cfg *config.Config, colors *progress.Colors) error {
+	switch o.subcommand {
+	case "plan lint":
+		return runPlanLint(ctx, o.PlanCmd.Lint, cfg, colors)
+	case "telemetry", "telemetry status", "telemetry on", "telemetry off":
+		return runTelemetry(o.subcommand, newTelemetry(cfg), colors, os.Stdout)
+	case "demo":
+		return runDemo(ctx, o, cfg, colors)
+	case "apply":
+		return runApply(ctx, o.ApplyCmd.Args.PatchFile, cfg, colors)
+	case "decrypt":
+		return runDecrypt(o.DecryptCmd.Args.File, cfg.ArtifactSealer, os.Stdout)
+	case "stats":
+		return runStats(cfg.ArtifactDir("."), cfg.ArtifactSealer, o.StatsCmd.Weeks, time.Now(), colors, os.Stdout)
+	case "plan estimate":
+		planFile, err := plan.NewSelector(cfg.PlansDir, colors).Select(ctx, o.PlanCmd.Estimate.Args.PlanFile, false)
+		if err != nil {
+			return fmt.Errorf("select plan: %w", err)
+		}
+		return estimatePlan(planFile, ".", o.MaxIterations, cfg, colors, os.Stdout)
+	default:
+		return fmt.Errorf("unknown command %q", o.subcommand)
+	}
+}
+
+// runDemo runs the full pipeline on a scratch project with scripted claude and codex.
+// git is simulated in memory, so the demo works without any external tools or network access.
+func runDemo(ctx context.Context, o opts, cfg *config.Config, colors *progress.Colors) error {
+	dir := o.DemoCmd.Dir
+	if dir == "" {
+		var err error
+		if dir, err = os.MkdirTemp("", "ralphex-demo-"); err != nil {
+			return fmt.Errorf("create demo dir: %w", err)
+		}
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("resolve demo dir: %w", err)
+	}
+	proj, err := demo.NewProject(dir)
+	if err != nil {
+		return fmt.Errorf("create demo project: %w", err)
+	}
+	// the plan and progress files are resolved relative to the project root, as in a regular run
+	if err = os.Chdir(dir); err != nil {
+		return fmt.Errorf("enter demo dir: %w", err)
+	}
+
+	gitSvc := git.NewMemoryService(proj.Repo, colors.Info())
+	if err = gitSvc.CreateBranchForPlan(demo.PlanFile); err != nil {
+		return fmt.Errorf("create branch for plan: %w", err)
+	}
+	branch := getCurrentBranch(gitSvc)
+
+	holder := &status.PhaseHolder{}
+	log, err := progress.NewLogger(progress.Config{PlanFile: demo.PlanFile, Mode: string(processor.ModeFull),
+		Branch: branch, NoColor: o.NoColor, Accessible: cfg.AccessibleOutput}, colors, holder)
+	if err != nil {
+		return fmt.Errorf("create progress logger: %w", err)
+	}
+	defer func() {
+		if closeErr := log.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to close progress log: %v\n", closeErr)
+		}
+	}()
+
+	// scripted agents don't need finalize, verification or a custom review script
+	appCfg := *cfg
+	appCfg.FinalizeEnabled = false
+	appCfg.ExternalReviewTool = "codex"
+	claude, codex := proj.Executors(log.PrintAligned, o.DemoCmd.Delay)
+	r := processor.NewWithExecutors(processor.Config{
+		PlanFile:         demo.PlanFile,
+		ProgressPath:     log.Path(),
+		Mode:             processor.ModeFull,
+		MaxIterations:    o.MaxIterations,
+		Debug:            o.debug,
+		NoColor:          o.NoColor,
+		IterationDelayMs: max(1, int(o.DemoCmd.Delay.Milliseconds())),
+		CodexEnabled:     true,
+		DefaultBranch:    proj.Repo.GetDefaultBranch(),
+		AppConfig:        &appCfg,
+	}, log, claude, codex, nil, holder)
+	r.SetGitChecker(gitSvc)
+
+	printStartupInfo(startupInfo{PlanFile: demo.PlanFile, Branch: branch, Mode: processor.ModeFull,
+		MaxIterations: o.MaxIterations, ProgressPath: log.Path()}, colors)
+	if _, err := r.Run(ctx); err != nil {
+		return fmt.Errorf("demo run: %w", err)
+	}
+
+	colors.Info().Printf("\ndemo completed in %s\n", log.Elapsed())
+	colors.Info().Printf("project: %s\n", dir)
+	colors.Info().Printf("progress log: %s\n", filepath.Join(dir, log.Path()))
+	colors.Info().Printf("commits on %s (simulated git):\n", branch)
+	for _, c := range proj.Repo.Commits(branch) {
+		colors.Info().Printf("  %s %s (%s)\n", c.Hash[:7], c.Message, strings.Join(c.Files, ", "))
+	}
+	colors.Info().Printf("\nto run for real, install claude (and optionally codex), then run ralphex <plan> in your repository\n")
+	return nil
+}
+
+// runPlanLint selects the plan to lint and runs static checks plus, unless --static, the model pass.
+func runPlanLint(ctx context.Context, cmd planLintCommand, cfg *config.Config, colors *progress.Colors) error {
+	planFile, err := plan.NewSelector(cfg.PlansDir, colors).Select(ctx, cmd.Args.PlanFile, false)
+	if err != nil {
+		return fmt.Errorf("select plan: %w", err)
+	}
+	var lintExec processor.Executor
+	if !cmd.Static {
+		if depErr := checkPrimaryCommandDep(cfg); depErr != nil {
+			return fmt.Errorf("%w (use --static to skip the model pass)", depErr)
+		}
+		lintExec = processor.NewPlanLintExecutor(cfg)
+	}
+	return lintPlan(ctx, planFile, cfg, colors, lintExec, os.Stdout)
+}
+
+// lintPlan prints static lint issues of planFile and, if lintExec is set, the model's suggestions.
+// returns an error if the plan has error-level issues.
+func lintPlan(ctx context.Context, planFile string, cfg *config.Config, colors *progress.Colors,
+	lintExec processor.Executor, stdout io.Writer) error {
+	data, err := os.ReadFile(planFile) //nolint:gosec // plan file selected by the user
+	if err != nil {
+		return fmt.Errorf("read plan: %w", err)
+	}
+
+	issues := plan.Lint(string(data))
+	found := make([]string, 0, len(issues))
+	errCount := 0
+	for _, issue := range issues {
+		found = append(found, issue.String())
+		if issue.Severity == plan.SeverityError {
+			errCount++
+		}
+	}
+	if len(issues) == 0 {
+		colors.Info().Fprintf(stdout, "static checks: no issues\n")
+	} else {
+		colors.Info().Fprintf(stdout, "static checks: %d issues\n", len(issues))
+	}
+	for _, issue := range issues {
+		c := colors.Warn()
+		if issue.Severity == plan.SeverityError {
+			c = colors.Error()
+		}
+		c.Fprintf(stdout, "  %s\n", issue)
+	}
+
+	if lintExec != nil {
+		colors.Info().Fprintf(stdout, "\nmodel review:\n")
+		suggestions, lintErr := processor.LintPlan(ctx, lintExec, cfg, planFile, cfg.DefaultBranch, found)
+		if lintErr != nil {
+			return lintErr
+		}
+		for line := range strings.SplitSeq(suggestions, "\n") {
+			fmt.Fprintf(stdout, "  %s\n", line)
+		}
+	}
+
+	if errCount > 0 {
+		return fmt.Errorf("plan %s has %d lint errors", planFile, errCount)
+	}
+	return nil
+}
+
+// estimatePlan prints the predicted iterations, duration and cost of running planFile,
+// based on the plan's tasks and past runs recorded under root.
+func estimatePlan(planFile, root string, maxIterations int, cfg *config.Config, colors *progress.Colors, stdout io.Writer) error {
+	data, err := os.ReadFile(planFile) //nolint:gosec // plan file selected by the user
+	if err != nil {
+		return fmt.Errorf("read plan: %w", err)
+	}
+	historyDir := filepath.Join(cfg.ArtifactDir(root), "progress")
+	history, err := estimate.LoadHistory(root, historyDir)
+	if err != nil {
+		return fmt.Errorf("load run history: %w", err)
+	}
+	est := estimate.New(string(data), history, estimate.Params{Root: root, MaxIterations: maxIterations, IterationCost: cfg.IterationCost})
+
+	items := 0
+	for _, t := range est.Tasks {
+		items += t.Items
+	}
+	colors.Info().Fprintf(stdout, "plan: %s (%d tasks, %d items)\n", planFile, len(est.Tasks), items)
+	if len(est.Areas) > 0 {
+		fmt.Fprintf(stdout, "affected areas: %s\n", strings.Join(est.Areas, ", "))
+	}
+	if len(est.Tasks) > 0 {
+		if est.HistoryRuns > 0 {
+			fmt.Fprintf(stdout, "based on %d past runs in %s\n", est.HistoryRuns, historyDir)
+		} else {
+			fmt.Fprintf(stdout, "not enough run history in %s, using default rates\n", historyDir)
+		}
+		fmt.Fprintf(stdout, "iterations: %d-%d (likely %d)\n", est.Iterations.Low, est.Iterations.High, est.Iterations.Likely)
+		fmt.Fprintf(stdout, "duration: %s-%s\n", est.Duration[0], est.Duration[1])
+		if est.Cost[1] > 0 {
+			fmt.Fprintf(stdout, "cost: %.2f-%.2f\n", est.Cost[0], est.Cost[1])
+		}
+		fmt.Fprintln(stdout, "tasks:")
+		for _, t := range est.Tasks {
+			fmt.Fprintf(stdout, "  %s: %d items, weight %.2f\n", t.Header, t.Items, t.Weight)
+		}
+	}
+	if len(est.Suggestions) == 0 {
+		colors.Info().Fprintf(stdout, "plan size looks fine to run now\n")
+	}
+	for _, sg := range est.Suggestions {
+		colors.Warn().Fprintf(stdout, "suggestion: %s\n", sg)
+	}
+	return nil
+}
+
+// applyPatches walks through patches, showing each summary and asking whether to apply it.
+// "d" shows the full diff, "q" or end of input stops. patches that fail to apply are skipped.
+func applyPatches(ctx context.Context, gitSvc *git.Service, patches []git.Patch, stdin io.Reader, stdout io.Writer) error {
+	reader := bufio.NewReader(stdin)
+	applied, failed := 0, 0
+loop:
+	for i, p := range patches {
+		fmt.Fprintf(stdout, "\n[%d/%d] %s\n\n%s\n", i+1, len(patches), p.Subject, p.Summary())
+		for {
+			fmt.Fprint(stdout, "apply this fix? [y]es/[n]o/[d]iff/[q]uit: ")
+			line, err := input.ReadLineWithContext(ctx, reader)
+			if err != nil {
+				fmt.Fprintln(stdout)
+				if !errors.Is(err, io.EOF) {
+					return fmt.Errorf("read answer: %w", err)
+				}
+				break loop
+			}
+			answer := strings.ToLower(strings.TrimSpace(line))
+			switch answer {
+			case "y", "yes":
+				if applyErr := gitSvc.ApplyPatch(p); applyErr != nil {
+					fmt.Fprintf(stdout, "failed, skipped: %v\n", applyErr)
+					failed++
+				} else {
+					applied++
+				}
+			case "n", "no":
+			case "d", "diff":
+				fmt.Fprintln(stdout, p.Text)
+				continue
+			case "q", "quit":
+				break loop
+			default:
+				continue
+			}
+			break
+		}
+	}
+	fmt.Fprintf(stdout, "applied %d of %d fixes", applied, len(patches))
+	if failed > 0 {
+		fmt.Fprintf(stdout, ", %d failed to apply", failed)
+	}
+	fmt.Fprintln(stdout)
+	return nil
+}
+
 // runReset runs the interactive config reset flow.
 func runReset(configDir string, stdin io.Reader, stdout io.Writer) error {
 	_, err := config.Reset(configDir, stdin, stdout)
@@ -654,9 +2180,14 @@ func runReset(configDir string, stdin io.Reader, stdout io.Writer) error {
 	return nil
 }
 
-// handleEarlyFlags processes flags that should run before full config load (--reset, --dump-defaults).
+// handleEarlyFlags processes flags that should run before full config load (--reset, --dump-defaults) and
+// "ralphex auth", which stores the secrets the config may reference.
 // returns (true, nil) if an early exit occurred, (true, err) on error, or (false, nil) to continue.
 func handleEarlyFlags(o opts) (bool, error) {
+	if o.subcommand == "auth set" || o.subcommand == "auth delete" {
+		return true, runAuth(o, keychain.New(), os.Stdin, os.Stdout)
+	}
+
 	if o.Reset {
 		if err := runReset(o.ConfigDir, os.Stdin, os.Stdout); err != nil {
 			return true, err
@@ -673,6 +2204,55 @@ func handleEarlyFlags(o opts) (bool, error) {
 	return false, nil
 }
 
+// secretStore abstracts keychain access for "ralphex auth".
+type secretStore interface {
+	Set(name, secret string) error
+	Delete(name string) error
+}
+
+// runAuth stores or removes a secret in the OS keychain.
+// stored secrets are referenced from config as "keychain:<name>", e.g. notify_slack_token = keychain:slack.
+func runAuth(o opts, store secretStore, stdin io.Reader, stdout io.Writer) error {
+	if o.subcommand == "auth delete" {
+		name := o.AuthCmd.Delete.Args.Name
+		if err := store.Delete(name); err != nil {
+			return fmt.Errorf("keychain: %w", err)
+		}
+		fmt.Fprintf(stdout, "removed %q from keychain\n", name)
+		return nil
+	}
+
+	name := o.AuthCmd.Set.Args.Name
+	secret, err := readSecret(stdin, stdout)
+	if err != nil {
+		return err
+	}
+	if err := store.Set(name, secret); err != nil {
+		return fmt.Errorf("keychain: %w", err)
+	}
+	fmt.Fprintf(stdout, "stored %q in keychain, reference it in config as %s%s\n", name, keychain.RefPrefix, name)
+	return nil
+}
+
+// readSecret reads a single secret line, without echo when stdin is a terminal.
+func readSecret(stdin io.Reader, stdout io.Writer) (string, error) {
+	if f, ok := stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(stdout, "secret: ")
+		b, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(stdout)
+		if err != nil {
+			return "", fmt.Errorf("read secret: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read secret: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
 // dumpDefaults extracts raw embedded defaults to the specified directory.
 func dumpDefaults(dir string) error {
 	if err := config.DumpDefaults(dir); err != nil {
@@ -699,7 +2279,7 @@ func toRelPath(p string) string {
 // this allows reset to work standalone (exit after reset) while also supporting
 // combined usage like "ralphex --reset docs/plans/feature.md".
 func isResetOnly(o opts) bool {
-	return o.PlanFile == "" && !o.Review && !o.ExternalOnly && !o.CodexOnly && !o.TasksOnly && !o.Serve && o.PlanDescription == "" && len(o.Watch) == 0 && o.DumpDefaults == ""
+	return o.PlanFile == "" && !o.Review && !o.ExternalOnly && !o.CodexOnly && !o.TasksOnly && !o.Serve && o.PlanDescription == "" && len(o.Watch) == 0 && o.DumpDefaults == "" && o.subcommand == ""
 }
 
 // startInterruptWatcher prints immediate feedback when context is canceled.
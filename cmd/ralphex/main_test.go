@@ -3,24 +3,37 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/jessevdk/go-flags"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/umputun/ralphex/pkg/artifacts"
+	"github.com/umputun/ralphex/pkg/baseline"
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/debuglog"
+	"github.com/umputun/ralphex/pkg/demo"
+	"github.com/umputun/ralphex/pkg/executor"
 	"github.com/umputun/ralphex/pkg/git"
 	gitmocks "github.com/umputun/ralphex/pkg/git/mocks"
+	"github.com/umputun/ralphex/pkg/history"
 	"github.com/umputun/ralphex/pkg/notify"
 	"github.com/umputun/ralphex/pkg/plan"
 	"github.com/umputun/ralphex/pkg/processor"
+	procmocks "github.com/umputun/ralphex/pkg/processor/mocks"
 	"github.com/umputun/ralphex/pkg/progress"
+	"github.com/umputun/ralphex/pkg/seal"
 	"github.com/umputun/ralphex/pkg/status"
+	"github.com/umputun/ralphex/pkg/telemetry"
 )
 
 // testColors returns a Colors instance for testing.
@@ -401,7 +414,7 @@ func TestCreateRunner(t *testing.T) {
 		t.Cleanup(func() { _ = os.Chdir(oldWd) })
 
 		cfg := &config.Config{IterationDelayMs: 5000, TaskRetryCount: 3, CodexEnabled: false}
-		o := opts{MaxIterations: 100, Debug: true, NoColor: true}
+		o := opts{MaxIterations: 100, debug: debuglog.Flags{Prompts: true, Processor: true}, NoColor: true}
 
 		colors := testColors()
 		holder := &status.PhaseHolder{}
@@ -524,6 +537,88 @@ func TestGetCurrentBranch(t *testing.T) {
 	})
 }
 
+func TestMaxRunDuration(t *testing.T) {
+	cfg := &config.Config{MaxRunDurationMs: 3600000}
+	assert.Equal(t, time.Hour, maxRunDuration(opts{}, cfg))
+	assert.Equal(t, 8*time.Hour, maxRunDuration(opts{MaxDuration: 8 * time.Hour}, cfg), "flag overrides config")
+	assert.Zero(t, maxRunDuration(opts{}, &config.Config{}))
+}
+
+func TestRunWindow(t *testing.T) {
+	night, err := config.ParseRunWindow("22:00-06:00")
+	require.NoError(t, err)
+	cfg := &config.Config{RunWindow: night}
+	assert.Equal(t, night, runWindow(opts{}, cfg))
+	assert.Equal(t, "01:00-05:30", runWindow(opts{RunWindow: "01:00-05:30"}, cfg).String(), "flag overrides config")
+	assert.True(t, runWindow(opts{}, &config.Config{}).IsZero())
+}
+
+func TestNewLogShipper(t *testing.T) {
+	req := executePlanRequest{PlanFile: "docs/plans/feature.md", Mode: processor.ModeFull, Config: &config.Config{}}
+	shipper, err := newLogShipper(req, "feature")
+	require.NoError(t, err)
+	assert.Nil(t, shipper, "not configured")
+
+	req.Config.LogShipParams.Destination = "kafka://broker:9092"
+	_, err = newLogShipper(req, "feature")
+	require.ErrorContains(t, err, "create log shipper: invalid log shipping destination")
+
+	req.Config.LogShipParams.Destination = "syslog://127.0.0.1:514"
+	shipper, err = newLogShipper(req, "feature")
+	require.NoError(t, err)
+	require.NotNil(t, shipper)
+	assert.Empty(t, req.Config.LogShipParams.Labels, "labels are set on a copy")
+	require.NoError(t, shipper.Close())
+}
+
+func TestEnsureArtifactsIgnored(t *testing.T) {
+	dir := setupTestRepo(t)
+	for _, f := range []string{".ralphex/config", ".ralphex/prompts/task.txt", ".ralphex/agents/qa.txt",
+		".ralphex/progress/progress-x.txt", ".ralphex/transcripts/run/001.txt", ".ralphex/state.json"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, f), []byte("x"), 0o600))
+	}
+	gitSvc, err := git.NewService(dir, testColors().Info())
+	require.NoError(t, err)
+
+	require.NoError(t, ensureArtifactsIgnored(gitSvc, &config.Config{}))
+
+	cmd := exec.Command("git", "status", "--porcelain", "--untracked-files=all")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"?? .ralphex/.gitignore", "?? .ralphex/agents/qa.txt", "?? .ralphex/config",
+		"?? .ralphex/prompts/task.txt"}, strings.Split(strings.TrimSpace(string(out)), "\n"))
+
+	t.Run("user location", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		gitSvc, err := git.NewService(dir, testColors().Info())
+		require.NoError(t, err)
+		require.NoError(t, ensureArtifactsIgnored(gitSvc, &config.Config{ArtifactLocation: config.ArtifactsUser}))
+		assert.NoDirExists(t, filepath.Join(dir, ".ralphex"))
+	})
+}
+
+func TestLoadResumeCheckpoint(t *testing.T) {
+	t.Chdir(t.TempDir())
+	_, err := loadResumeCheckpoint(opts{}, processor.CheckpointFile, nil)
+	require.EqualError(t, err, "nothing to resume, no checkpoint at .ralphex/state.json")
+
+	plan, err := filepath.Abs("plan.md")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(".ralphex", 0o750))
+	data := `{"plan_file":` + strconv.Quote(plan) + `,"mode":"full","step":"external-review","iteration":2}`
+	require.NoError(t, os.WriteFile(processor.CheckpointFile, []byte(data), 0o600))
+
+	cp, err := loadResumeCheckpoint(opts{PlanFile: "plan.md"}, processor.CheckpointFile, nil)
+	require.NoError(t, err)
+	assert.Equal(t, processor.StepExternal, cp.Step)
+	assert.Equal(t, 2, cp.Iteration)
+
+	_, err = loadResumeCheckpoint(opts{PlanFile: "other.md"}, processor.CheckpointFile, nil)
+	require.ErrorContains(t, err, "checkpoint is for plan")
+}
+
 func TestValidateFlags(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -535,6 +630,36 @@ func TestValidateFlags(t *testing.T) {
 		{name: "plan_flag_only_is_valid", opts: opts{PlanDescription: "add feature"}, wantErr: false},
 		{name: "plan_file_only_is_valid", opts: opts{PlanFile: "docs/plans/test.md"}, wantErr: false},
 		{name: "both_plan_and_planfile_conflicts", opts: opts{PlanDescription: "add feature", PlanFile: "docs/plans/test.md"}, wantErr: true, errMsg: "conflicts"},
+		{name: "plan_spec_only_is_valid", opts: opts{PlanSpec: "spec.md"}, wantErr: false},
+		{name: "plan_spec_and_plan_conflict", opts: opts{PlanSpec: "spec.md", PlanDescription: "add feature"}, wantErr: true,
+			errMsg: "--plan-spec conflicts with --plan"},
+		{name: "plan_spec_with_resume_conflicts", opts: opts{PlanSpec: "spec.md", Resume: true}, wantErr: true, errMsg: "--resume continues"},
+		{name: "emit_patch_with_review_is_valid", opts: opts{Review: true, EmitPatch: "out.patch"}, wantErr: false},
+		{name: "emit_patch_with_external_only_is_valid", opts: opts{ExternalOnly: true, EmitPatch: "out.patch"}, wantErr: false},
+		{name: "emit_patch_without_review_mode", opts: opts{EmitPatch: "out.patch"}, wantErr: true, errMsg: "--emit-patch requires"},
+		{name: "apply_only_is_valid", opts: opts{subcommand: "apply"}, wantErr: false},
+		{name: "apply_with_review_conflicts", opts: opts{subcommand: "apply", Review: true}, wantErr: true,
+			errMsg: "ralphex apply runs on its own"},
+		{name: "dry_run_with_emit_patch_conflicts", opts: opts{DryRun: true, Review: true, EmitPatch: "out.patch"}, wantErr: true,
+			errMsg: "--dry-run makes no changes"},
+		{name: "dry_run_with_apply_conflicts", opts: opts{DryRun: true, subcommand: "apply"}, wantErr: true,
+			errMsg: "ralphex apply runs on its own"},
+		{name: "dry_run_with_review", opts: opts{DryRun: true, Review: true}, wantErr: false},
+		{name: "resume_with_plan_file_is_valid", opts: opts{Resume: true, PlanFile: "docs/plans/test.md"}, wantErr: false},
+		{name: "resume_with_mode_conflicts", opts: opts{Resume: true, Review: true}, wantErr: true, errMsg: "--resume continues"},
+		{name: "max_duration_is_valid", opts: opts{MaxDuration: 8 * time.Hour}, wantErr: false},
+		{name: "run_window_is_valid", opts: opts{RunWindow: "22:00-06:00"}, wantErr: false},
+		{name: "invalid_run_window", opts: opts{RunWindow: "22:00"}, wantErr: true, errMsg: "--run-window"},
+		{name: "negative_max_duration", opts: opts{MaxDuration: -time.Minute}, wantErr: true, errMsg: "--max-duration must be positive"},
+		{name: "skip_phases_is_valid", opts: opts{SkipFirstReview: true, SkipSecond: true}, wantErr: false},
+		{name: "skip_phases_with_tasks_only_conflicts", opts: opts{TasksOnly: true, SkipCodex: true}, wantErr: true,
+			errMsg: "leave out review phases"},
+		{name: "skip_codex_with_external_only_conflicts", opts: opts{ExternalOnly: true, SkipCodex: true}, wantErr: true,
+			errMsg: "--skip-codex conflicts with --external-only"},
+		{name: "task_selection_is_valid", opts: opts{StartTask: "3", OnlyTasks: []string{"(?i)docs"}}, wantErr: false},
+		{name: "task_selection_with_review_conflicts", opts: opts{Review: true, OnlyTasks: []string{"2"}}, wantErr: true,
+			errMsg: "--start-task and --only-tasks select plan tasks"},
+		{name: "bad_task_selector", opts: opts{StartTask: "[a"}, wantErr: true, errMsg: `bad task selector "[a"`},
 	}
 
 	for _, tc := range tests {
@@ -550,6 +675,111 @@ func TestValidateFlags(t *testing.T) {
 	}
 }
 
+func TestNeedsInputAnswerer(t *testing.T) {
+	pipe := func(t *testing.T, content string) *os.File {
+		t.Helper()
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = r.Close(); _ = w.Close() })
+		if content != "" {
+			_, err = w.WriteString(content)
+			require.NoError(t, err)
+		}
+		return r
+	}
+
+	t.Run("piped answer", func(t *testing.T) {
+		var stdout bytes.Buffer
+		answer := needsInputAnswerer(&config.Config{NeedsInputTimeoutMs: 1000}, pipe(t, "remove them\n"), &stdout)
+		got, err := answer(context.Background(), "Keep v1?")
+		require.NoError(t, err)
+		assert.Equal(t, "remove them", got)
+		assert.Contains(t, stdout.String(), "QUESTION: Keep v1?")
+	})
+
+	t.Run("timeout without a terminal", func(t *testing.T) {
+		answer := needsInputAnswerer(&config.Config{NeedsInputTimeoutMs: 20}, pipe(t, ""), io.Discard)
+		_, err := answer(context.Background(), "Keep v1?")
+		require.EqualError(t, err, "no answer within 20ms")
+	})
+
+	t.Run("no wait configured", func(t *testing.T) {
+		answer := needsInputAnswerer(&config.Config{}, pipe(t, "late\n"), io.Discard)
+		_, err := answer(context.Background(), "Keep v1?")
+		require.ErrorContains(t, err, "needs_input_timeout_ms is 0")
+	})
+}
+
+func TestReadPlanSpec(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "spec.md")
+	require.NoError(t, os.WriteFile(path, []byte("\nadd rate limiting to the api\n\n"), 0o600))
+	spec, err := readPlanSpec(path)
+	require.NoError(t, err)
+	assert.Equal(t, "add rate limiting to the api", spec)
+
+	require.NoError(t, os.WriteFile(path, []byte(" \n"), 0o600))
+	_, err = readPlanSpec(path)
+	require.ErrorContains(t, err, "is empty")
+
+	_, err = readPlanSpec(filepath.Join(dir, "missing.md"))
+	require.ErrorContains(t, err, "read plan spec")
+}
+
+func TestWriteCrashReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "artifacts", "crash-20260101-120000.txt")
+	panicErr := &processor.PanicError{Value: "boom", Stack: []byte("goroutine 1 [running]:"), Phase: "task",
+		Step: processor.StepTask, Iteration: 2, LastOutput: "working on task 2"}
+	require.NoError(t, writeCrashReport(path, panicErr))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "ralphex "), "starts with the version")
+	assert.Contains(t, string(data), "crashed in task phase, task step iteration 2: panic: boom")
+	assert.Contains(t, string(data), "goroutine 1 [running]:")
+	assert.Contains(t, string(data), "working on task 2")
+}
+
+func TestPublishArtifacts(t *testing.T) {
+	dir := setupTestRepo(t)
+	t.Chdir(dir)
+	gitSvc, err := git.NewService(".", testColors().Info())
+	require.NoError(t, err)
+
+	out := t.TempDir()
+	publisher, err := artifacts.New(artifacts.Params{
+		Command: `cp -R "$RALPHEX_ARTIFACTS_DIR"/. ` + out + ` && echo https://example.com/$RALPHEX_RUN_ID`})
+	require.NoError(t, err)
+
+	log, err := progress.NewLogger(progress.Config{Mode: "full", Branch: "master", NoColor: true, Dir: t.TempDir()},
+		testColors(), &status.PhaseHolder{})
+	require.NoError(t, err)
+	defer log.Close()
+
+	transcripts := filepath.Join(t.TempDir(), "progress-transcripts")
+	require.NoError(t, os.Mkdir(transcripts, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(transcripts, "001-task.txt"), []byte("prompt"), 0o600))
+
+	req := executePlanRequest{GitSvc: gitSvc, DefaultBranch: "master", Colors: testColors(), Artifacts: publisher}
+	links := publishArtifacts(req, finishedRun{runID: "run-1", log: log, transcripts: transcripts},
+		processor.RunReport{Mode: "full"})
+	assert.Equal(t, []string{"https://example.com/run-1"}, links)
+
+	assert.FileExists(t, filepath.Join(out, filepath.Base(log.Path())))
+	data, err := os.ReadFile(filepath.Join(out, "progress-transcripts", "001-task.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "prompt", string(data))
+	report, err := os.ReadFile(filepath.Join(out, "report.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(report), `"mode": "full"`)
+
+	t.Run("missing transcripts dir", func(t *testing.T) {
+		links := publishArtifacts(req, finishedRun{runID: "run-2", log: log, transcripts: filepath.Join(dir, "missing")},
+			processor.RunReport{Mode: "full"})
+		assert.Equal(t, []string{"https://example.com/run-2"}, links)
+	})
+}
+
 func TestPrintStartupInfo(t *testing.T) {
 	colors := testColors()
 
@@ -624,6 +854,61 @@ func noopLogger() *gitmocks.LoggerMock {
 	}
 }
 
+func TestApplyPatches(t *testing.T) {
+	// memory repo with two fixes extracted into a patch series
+	setup := func(t *testing.T) (*git.MemoryRepo, *git.Service, []git.Patch) {
+		t.Helper()
+		repo := git.NewMemoryRepo("/repo", "master")
+		repo.Touch("README.md")
+		require.NoError(t, repo.CreateInitialCommit("initial commit"))
+		svc := git.NewMemoryService(repo, noopLogger())
+		base, err := svc.StartCapture()
+		require.NoError(t, err)
+		for _, f := range []string{"a.go", "b.go"} {
+			repo.Touch(f)
+			require.NoError(t, repo.Add(f))
+			require.NoError(t, repo.Commit("fix "+f))
+		}
+		series, err := svc.ExtractChanges(base)
+		require.NoError(t, err)
+		patches := git.SplitPatches(series)
+		require.Len(t, patches, 2)
+		return repo, svc, patches
+	}
+
+	t.Run("accept and reject individually", func(t *testing.T) {
+		repo, svc, patches := setup(t)
+		var stdout bytes.Buffer
+		err := applyPatches(context.Background(), svc, patches, strings.NewReader("d\nmaybe\nn\ny\n"), &stdout)
+		require.NoError(t, err)
+
+		commits := repo.Commits("master")
+		require.Len(t, commits, 2)
+		assert.Equal(t, "fix b.go", commits[1].Message)
+		assert.Equal(t, []string{"b.go"}, commits[1].Files)
+		assert.Contains(t, stdout.String(), "[1/2] fix a.go")
+		assert.Equal(t, 4, strings.Count(stdout.String(), "apply this fix?"), "diff and unknown answers prompt again")
+		assert.Contains(t, stdout.String(), "applied 1 of 2 fixes")
+	})
+
+	t.Run("quit stops", func(t *testing.T) {
+		repo, svc, patches := setup(t)
+		var stdout bytes.Buffer
+		require.NoError(t, applyPatches(context.Background(), svc, patches, strings.NewReader("q\n"), &stdout))
+		assert.Len(t, repo.Commits("master"), 1)
+		assert.NotContains(t, stdout.String(), "[2/2]")
+		assert.Contains(t, stdout.String(), "applied 0 of 2 fixes")
+	})
+
+	t.Run("end of input stops", func(t *testing.T) {
+		repo, svc, patches := setup(t)
+		var stdout bytes.Buffer
+		require.NoError(t, applyPatches(context.Background(), svc, patches, strings.NewReader("y\n"), &stdout))
+		assert.Len(t, repo.Commits("master"), 2)
+		assert.Contains(t, stdout.String(), "applied 1 of 2 fixes")
+	})
+}
+
 func TestEnsureRepoHasCommits(t *testing.T) {
 	t.Run("returns nil for repo with commits", func(t *testing.T) {
 		dir := setupTestRepo(t)
@@ -943,6 +1228,371 @@ func TestNotificationServiceCreation(t *testing.T) {
 	})
 }
 
+func TestAnnotatePlan(t *testing.T) {
+	outcome := plan.Outcome{RunID: "20260102-030405-plan", Date: time.Now(), Status: "success", Iterations: 2}
+	setup := func(t *testing.T) (executePlanRequest, string) {
+		t.Helper()
+		dir := setupTestRepo(t)
+		planFile := filepath.Join(dir, "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n\n### Task 1: x\n- [x] done\n"), 0o600))
+		runGit(t, dir, "add", "plan.md")
+		runGit(t, dir, "commit", "-m", "add plan")
+		gitSvc, err := git.NewService(dir, testColors().Info())
+		require.NoError(t, err)
+		req := executePlanRequest{PlanFile: planFile, Mode: processor.ModeFull, GitSvc: gitSvc,
+			Config: &config.Config{AnnotatePlan: true}}
+		return req, dir
+	}
+	gitOutput := func(t *testing.T, dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		req, _ := setup(t)
+		req.Config.AnnotatePlan = false
+		annotatePlan(req, opts{}, outcome)
+		data, err := os.ReadFile(req.PlanFile)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), plan.RunHistoryHeader)
+	})
+
+	t.Run("committed on feature branch modes", func(t *testing.T) {
+		req, dir := setup(t)
+		annotatePlan(req, opts{}, outcome)
+		data, err := os.ReadFile(req.PlanFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "### Run 20260102-030405-plan")
+		assert.Equal(t, "record run outcome: plan.md", gitOutput(t, dir, "log", "-1", "--format=%s"))
+	})
+
+	t.Run("left uncommitted in review mode", func(t *testing.T) {
+		req, dir := setup(t)
+		req.Mode = processor.ModeReview
+		annotatePlan(req, opts{}, outcome)
+		assert.Equal(t, "add plan", gitOutput(t, dir, "log", "-1", "--format=%s"))
+		assert.Equal(t, "M plan.md", gitOutput(t, dir, "status", "--porcelain"))
+	})
+}
+
+func TestCommandPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantArg string
+	}{
+		{name: "plan file", args: []string{"docs/plans/x.md"}, wantArg: "docs/plans/x.md"},
+		{name: "plan lint", args: []string{"plan", "lint", "--static", "x.md"}, want: "plan lint"},
+		{name: "flags before command", args: []string{"--no-color", "plan", "lint"}, want: "plan lint"},
+		{name: "plan estimate", args: []string{"plan", "estimate", "x.md"}, want: "plan estimate"},
+		{name: "telemetry without subcommand", args: []string{"telemetry"}, want: "telemetry"},
+		{name: "telemetry on", args: []string{"telemetry", "on"}, want: "telemetry on"},
+		{name: "demo", args: []string{"demo", "--delay", "0s"}, want: "demo"},
+		{name: "auth set", args: []string{"auth", "set", "slack"}, want: "auth set"},
+		{name: "auth delete", args: []string{"auth", "delete", "slack"}, want: "auth delete"},
+		{name: "apply", args: []string{"apply", "review.patch"}, want: "apply"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var o opts
+			parser := flags.NewParser(&o, flags.Default&^flags.PrintErrors)
+			parser.SubcommandsOptional = true
+			args, err := parser.ParseArgs(tc.args)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, commandPath(parser.Active))
+			if tc.wantArg != "" {
+				assert.Equal(t, []string{tc.wantArg}, args)
+			}
+		})
+	}
+
+	t.Run("lint options", func(t *testing.T) {
+		var o opts
+		parser := flags.NewParser(&o, flags.Default&^flags.PrintErrors)
+		parser.SubcommandsOptional = true
+		_, err := parser.ParseArgs([]string{"plan", "lint", "--static", "x.md"})
+		require.NoError(t, err)
+		assert.True(t, o.PlanCmd.Lint.Static)
+		assert.Equal(t, "x.md", o.PlanCmd.Lint.Args.PlanFile)
+	})
+
+	t.Run("auth name", func(t *testing.T) {
+		var o opts
+		parser := flags.NewParser(&o, flags.Default&^flags.PrintErrors)
+		parser.SubcommandsOptional = true
+		_, err := parser.ParseArgs([]string{"auth", "set", "smtp"})
+		require.NoError(t, err)
+		assert.Equal(t, "smtp", o.AuthCmd.Set.Args.Name)
+		_, err = parser.ParseArgs([]string{"auth", "delete"})
+		require.ErrorContains(t, err, "`name` was not provided")
+	})
+}
+
+func TestLintPlan(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	cfg := &config.Config{PlanLintPrompt: "lint {{PLAN_FILE}}: {{LINT_ISSUES}}"}
+
+	t.Run("static only, warnings pass", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(planFile, []byte("### Task 1: add x\n- [ ] add x to pkg/x.go\n"), 0o600))
+		var stdout bytes.Buffer
+		require.NoError(t, lintPlan(context.Background(), planFile, cfg, testColors(), nil, &stdout))
+		assert.Contains(t, stdout.String(), "static checks: 1 issues")
+		assert.Contains(t, stdout.String(), "task has no verification step")
+		assert.NotContains(t, stdout.String(), "model review")
+	})
+
+	t.Run("errors fail, model pass included", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n"), 0o600))
+		lintExec := &procmocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			return executor.Result{Output: "- Task 1: missing -> add tasks"}
+		}}
+		var stdout bytes.Buffer
+		err := lintPlan(context.Background(), planFile, cfg, testColors(), lintExec, &stdout)
+		require.ErrorContains(t, err, "has 1 lint errors")
+		assert.Contains(t, stdout.String(), "error: no tasks found")
+		assert.Contains(t, stdout.String(), "model review:\n  - Task 1: missing -> add tasks")
+		assert.Contains(t, lintExec.RunCalls()[0].Prompt, "error: no tasks found")
+	})
+
+	t.Run("missing plan", func(t *testing.T) {
+		err := lintPlan(context.Background(), filepath.Join(t.TempDir(), "x.md"), cfg, testColors(), nil, io.Discard)
+		require.ErrorContains(t, err, "read plan")
+	})
+}
+
+func TestEstimatePlan(t *testing.T) {
+	root := t.TempDir()
+	planFile := filepath.Join(root, "plan.md")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "pkg"), 0o750))
+
+	t.Run("fits the limit", func(t *testing.T) {
+		content := "### Task 1: add x\n- [ ] add x to pkg/x.go\n- [ ] write tests\n"
+		require.NoError(t, os.WriteFile(planFile, []byte(content), 0o600))
+		var stdout bytes.Buffer
+		require.NoError(t, estimatePlan(planFile, root, 50, &config.Config{IterationCost: 0.5}, testColors(), &stdout))
+		out := stdout.String()
+		assert.Contains(t, out, "(1 tasks, 2 items)")
+		assert.Contains(t, out, "affected areas: pkg")
+		assert.Contains(t, out, "using default rates")
+		assert.Contains(t, out, "iterations: 1-2 (likely 1)")
+		assert.Contains(t, out, "cost: 0.50-1.00")
+		assert.Contains(t, out, "plan size looks fine to run now")
+	})
+
+	t.Run("over the limit", func(t *testing.T) {
+		content := "### Task 1: a\n- [ ] do a\n\n### Task 2: b\n- [ ] do b\n\n### Task 3: c\n- [ ] do c\n"
+		require.NoError(t, os.WriteFile(planFile, []byte(content), 0o600))
+		var stdout bytes.Buffer
+		require.NoError(t, estimatePlan(planFile, root, 3, &config.Config{}, testColors(), &stdout))
+		out := stdout.String()
+		assert.NotContains(t, out, "cost:")
+		assert.Contains(t, out, "suggestion: up to 5 iterations predicted, over the limit of 3")
+		assert.NotContains(t, out, "looks fine")
+	})
+
+	t.Run("missing plan", func(t *testing.T) {
+		err := estimatePlan(filepath.Join(root, "x.md"), root, 50, &config.Config{}, testColors(), io.Discard)
+		require.ErrorContains(t, err, "read plan")
+	})
+}
+
+func TestDebugOption(t *testing.T) {
+	t.Setenv("RALPHEX_DEBUG", "")
+	tests := []struct {
+		name string
+		args []string
+		env  string
+		want debuglog.Flags
+	}{
+		{name: "off", args: []string{"plan.md"}},
+		{name: "short flag enables all", args: []string{"-d", "plan.md"},
+			want: debuglog.Flags{ExecutorIO: true, Prompts: true, Signals: true, Git: true, Processor: true}},
+		{name: "subsystems", args: []string{"--debug=signals,git", "plan.md"}, want: debuglog.Flags{Signals: true, Git: true}},
+		{name: "env", args: []string{"plan.md"}, env: "prompts,processor", want: debuglog.Flags{Prompts: true, Processor: true}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("RALPHEX_DEBUG", tc.env)
+			var o opts
+			parser := flags.NewParser(&o, flags.Default&^flags.PrintErrors)
+			parser.SubcommandsOptional = true
+			args, err := parser.ParseArgs(tc.args)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"plan.md"}, args, "plan file stays positional")
+			f, err := debuglog.Parse(o.Debug)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, f)
+		})
+	}
+}
+
+func TestRunTelemetry(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+	dir := t.TempDir()
+
+	run := func(cmd, endpoint string) string {
+		var stdout bytes.Buffer
+		tel := telemetry.New(telemetry.Params{Dir: dir, Endpoint: endpoint})
+		require.NoError(t, runTelemetry(cmd, tel, testColors(), &stdout))
+		return stdout.String()
+	}
+
+	assert.Contains(t, run("telemetry", ""), "telemetry is off")
+	out := run("telemetry on", "")
+	assert.Contains(t, out, "telemetry is on")
+	assert.Contains(t, out, "stats are kept locally only")
+
+	req := executePlanRequest{Mode: processor.ModeFull, Telemetry: telemetry.New(telemetry.Params{Dir: dir})}
+	recordTelemetry(req, 4, nil)
+	recordTelemetry(req, 1, errors.New("max iterations (1) reached without completion"))
+	out = run("telemetry status", "http://127.0.0.1:1/stats")
+	assert.Contains(t, out, "reporting to http://127.0.0.1:1/stats")
+	assert.Contains(t, out, "total: 2 runs, 1 succeeded, 5 task iterations\n  mode full: 2\n  failure max_iterations: 1")
+	assert.Contains(t, out, "not reported yet: 2 runs")
+
+	assert.Contains(t, run("telemetry off", ""), "telemetry is off")
+}
+
+func TestRunDecrypt(t *testing.T) {
+	s, err := seal.New("decrypt passphrase")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	content := "{\"mode\":\"full\"}\n" + string(s.Seal([]byte(`{"mode":"review"}`))) + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	var stdout bytes.Buffer
+	require.NoError(t, runDecrypt(path, s, &stdout))
+	assert.Equal(t, "{\"mode\":\"full\"}\n{\"mode\":\"review\"}\n", stdout.String())
+
+	err = runDecrypt(path, nil, &bytes.Buffer{})
+	require.ErrorIs(t, err, seal.ErrNoKey)
+	require.ErrorContains(t, runDecrypt(filepath.Join(t.TempDir(), "missing"), s, &bytes.Buffer{}), "read artifact")
+}
+
+func TestRunStats(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	var stdout bytes.Buffer
+	require.NoError(t, runStats(dir, nil, 2, now, testColors(), &stdout))
+	assert.Contains(t, stdout.String(), "no run history in "+dir)
+
+	req := executePlanRequest{Mode: processor.ModeFull, ArtifactDir: dir}
+	recordHistory(req, history.Entry{Time: now.AddDate(0, 0, -7), Mode: "full", Iterations: 4}, nil)
+	recordHistory(req, history.Entry{Time: now.Add(-time.Hour), Mode: "full", Iterations: 10},
+		errors.New("max iterations (10) reached without completion"))
+
+	stdout.Reset()
+	require.NoError(t, runStats(dir, nil, 2, now, testColors(), &stdout))
+	assert.Equal(t, "2 runs since 2026-10-09: 50% succeeded, 7.0 task iterations on average, 50% stalled\n"+
+		"  failure max_iterations: 1\n"+
+		"per week:\n"+
+		"  2026-10-05: 1 runs, 100% succeeded, 4.0 task iterations on average, 0% stalled\n"+
+		"  2026-10-12: 1 runs, 0% succeeded, 10.0 task iterations on average, 100% stalled\n", stdout.String())
+}
+
+func TestRunStats_Canary(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	v1 := &config.Config{TaskPrompt: "v1"}
+	req := executePlanRequest{Mode: processor.ModeFull, ArtifactDir: dir, Config: v1}
+	recordHistory(req, history.Entry{Time: now.AddDate(0, 0, -7), Mode: "full", Iterations: 4}, nil)
+	req.Config = &config.Config{TaskPrompt: "v2"}
+	recordHistory(req, history.Entry{Time: now.Add(-2 * time.Hour), Mode: "full", Iterations: 6}, nil)
+	recordHistory(req, history.Entry{Time: now.Add(-time.Hour), Mode: "full", Iterations: 10},
+		errors.New("max iterations (10) reached without completion"))
+
+	entries, err := history.Load(dir, nil)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.False(t, entries[0].Canary, "first run is the baseline")
+	assert.True(t, entries[1].Canary)
+	assert.True(t, entries[2].Canary)
+	assert.Equal(t, req.Config.PromptsHash(), entries[2].Prompts)
+	assert.Equal(t, req.Config.PromptVersions(), entries[2].PromptVersions)
+
+	var stdout bytes.Buffer
+	require.NoError(t, runStats(dir, nil, 2, now, testColors(), &stdout))
+	assert.Equal(t, "3 runs since 2026-10-09: 67% succeeded, 6.7 task iterations on average, 33% stalled\n"+
+		"  failure max_iterations: 1\n"+
+		"since prompt change on 2026-10-16: 2 runs, 50% succeeded, 8.0 task iterations on average, 50% stalled\n"+
+		"  before: 1 runs, 100% succeeded, 4.0 task iterations on average, 0% stalled\n"+
+		"prompt changes:\n"+
+		"  2026-10-16: "+v1.PromptsHash()+" -> "+req.Config.PromptsHash()+", task\n"+
+		"per week:\n"+
+		"  2026-10-05: 1 runs, 100% succeeded, 4.0 task iterations on average, 0% stalled\n"+
+		"  2026-10-12: 2 runs (2 canary), 50% succeeded, 8.0 task iterations on average, 50% stalled\n", stdout.String())
+}
+
+func TestChronicFindings(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	req := executePlanRequest{Mode: processor.ModeFull, ArtifactDir: dir, Colors: testColors()}
+	findings := historyFindings([]processor.Finding{{File: "a.go", Message: "possible nil dereference"}})
+	for i := range history.ChronicRuns {
+		recordHistory(req, history.Entry{Time: now.Add(time.Duration(i-3) * time.Hour), Mode: "full", Iterations: 1,
+			Findings: findings}, nil)
+	}
+
+	var stdout bytes.Buffer
+	reportChronicFindings(testColors(), history.ChronicFindings([]history.Entry{}), findings, &stdout)
+	assert.Empty(t, stdout.String())
+
+	entries, err := history.Load(dir, nil)
+	require.NoError(t, err)
+	reportChronicFindings(testColors(), history.ChronicFindings(entries), findings, &stdout)
+	assert.Equal(t, "chronic findings, reported by external review in 3 or more runs:\n"+
+		"  a.go: possible nil dereference (3 runs, last 2026-10-16)\n"+
+		"fix them for real, baseline them, or record them as accepted in the project memory (CLAUDE.md)\n", stdout.String())
+
+	stdout.Reset()
+	require.NoError(t, runStats(dir, nil, 0, now, testColors(), &stdout))
+	assert.Equal(t, "3 runs since 2026-10-16: 100% succeeded, 1.0 task iterations on average, 0% stalled\n"+
+		"chronic findings, reported in 3 or more runs:\n"+
+		"  a.go: possible nil dereference (3 runs, last 2026-10-16)\n", stdout.String())
+}
+
+func TestSuggestBaseline(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir) // the baseline is relative to the repository root
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	req := executePlanRequest{Mode: processor.ModeFull, ArtifactDir: filepath.Join(dir, ".ralphex"), Colors: testColors()}
+	dismissed := []history.Finding{{File: "a.go", Message: "error not checked"}}
+	once := []history.Finding{{File: "b.go", Message: "dismissed once"}}
+
+	record := func(dismissed []history.Finding) {
+		recordHistory(req, history.Entry{Time: now, Mode: "full", Dismissed: dismissed}, nil)
+	}
+	record(dismissed)
+	var stdout bytes.Buffer
+	suggestBaseline(context.Background(), req, dismissed, false, strings.NewReader("y\n"), &stdout)
+	assert.Empty(t, stdout.String(), "dismissed in a single run only")
+
+	record(append(dismissed, once...))
+	suggestBaseline(context.Background(), req, append(dismissed, once...), false, strings.NewReader("n\n"), &stdout)
+	assert.Contains(t, stdout.String(), "findings dismissed as invalid in 2 or more runs:\n  a.go: error not checked\n")
+	assert.NotContains(t, stdout.String(), "b.go")
+	assert.Contains(t, stdout.String(), "not added, run with --update-baseline")
+	_, err := os.Stat(filepath.Join(".ralphex", "baseline"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	stdout.Reset()
+	suggestBaseline(context.Background(), req, dismissed, true, strings.NewReader(""), &stdout)
+	assert.Contains(t, stdout.String(), "added 1 findings to .ralphex/baseline")
+	accepted, err := baseline.Load(filepath.Join(".ralphex", "baseline"))
+	require.NoError(t, err)
+	assert.Equal(t, dismissed, accepted)
+	assert.Equal(t, []processor.Finding{{File: "a.go", Message: "error not checked"}}, loadBaseline())
+
+	stdout.Reset()
+	suggestBaseline(context.Background(), req, dismissed, false, strings.NewReader("y\n"), &stdout)
+	assert.Empty(t, stdout.String(), "already in the baseline")
+}
+
 func TestExecutePlanRequestHasNotifySvc(t *testing.T) {
 	// verify the struct has NotifySvc field and it works with nil
 	req := executePlanRequest{
@@ -1158,3 +1808,137 @@ func TestResolveVersion(t *testing.T) {
 		assert.NotEmpty(t, v)
 	})
 }
+
+// fakeSecretStore records keychain calls for runAuth tests.
+type fakeSecretStore struct {
+	set     map[string]string
+	deleted []string
+	err     error
+}
+
+func (f *fakeSecretStore) Set(name, secret string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.set[name] = secret
+	return nil
+}
+
+func (f *fakeSecretStore) Delete(name string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+func TestRunAuth(t *testing.T) {
+	authOpts := func(subcommand, name string) opts {
+		o := opts{subcommand: subcommand}
+		o.AuthCmd.Set.Args.Name, o.AuthCmd.Delete.Args.Name = name, name
+		return o
+	}
+
+	t.Run("set_reads_stdin", func(t *testing.T) {
+		store := &fakeSecretStore{set: map[string]string{}}
+		var out bytes.Buffer
+		err := runAuth(authOpts("auth set", "slack"), store, strings.NewReader("xoxb-123\n"), &out)
+		require.NoError(t, err)
+		assert.Equal(t, "xoxb-123", store.set["slack"])
+		assert.Contains(t, out.String(), "keychain:slack")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := &fakeSecretStore{set: map[string]string{}}
+		var out bytes.Buffer
+		require.NoError(t, runAuth(authOpts("auth delete", "slack"), store, strings.NewReader(""), &out))
+		assert.Equal(t, []string{"slack"}, store.deleted)
+		assert.Empty(t, store.set)
+	})
+
+	t.Run("store_error", func(t *testing.T) {
+		store := &fakeSecretStore{set: map[string]string{}, err: errors.New("no secret service")}
+		err := runAuth(authOpts("auth set", "slack"), store, strings.NewReader("tok\n"), io.Discard)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "keychain: no secret service")
+	})
+}
+
+func TestRunPlanDir(t *testing.T) {
+	dir := setupTestRepo(t)
+	t.Chdir(dir)
+	cfg, err := config.Load(filepath.Join(t.TempDir(), "config"))
+	require.NoError(t, err)
+	gitSvc, err := git.NewService(dir, testColors().Info())
+	require.NoError(t, err)
+	plansDir := filepath.Join(dir, "plans")
+	require.NoError(t, os.MkdirAll(plansDir, 0o750))
+	for _, name := range []string{"b-second.md", "a-first.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(plansDir, name), []byte("# Plan\n\n### Task 1: do it\n- [ ] item\n"), 0o600))
+	}
+	req := executePlanRequest{Mode: processor.ModeFull, GitSvc: gitSvc, Config: cfg, Colors: testColors(),
+		DefaultBranch: "master", ArtifactDir: filepath.Join(dir, ".ralphex")}
+
+	t.Run("single plan flags", func(t *testing.T) {
+		err := runPlanDir(context.Background(), opts{Serve: true}, plansDir, req, io.Discard)
+		require.ErrorContains(t, err, "need a single plan file")
+	})
+
+	t.Run("runs each plan", func(t *testing.T) {
+		var out bytes.Buffer
+		o := opts{DryRun: true, MaxIterations: 1, NoColor: true}
+		require.NoError(t, runPlanDir(context.Background(), o, plansDir, req, &out))
+		assert.Regexp(t, `plans summary:\n  done    plans/a-first.md \(\d+s\)\n  done    plans/b-second.md \(\d+s\)\n`, out.String())
+		branch, err := gitSvc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "master", branch, "dry run creates no branches")
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var out bytes.Buffer
+		require.ErrorIs(t, runPlanDir(ctx, opts{DryRun: true}, plansDir, req, &out), context.Canceled)
+		assert.Contains(t, out.String(), "  skipped plans/a-first.md\n  skipped plans/b-second.md\n")
+	})
+}
+
+func TestPrintPlanDirSummary(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	results := []planDirResult{
+		{PlanFile: filepath.Join(cwd, "plans", "a.md"), Status: "done", Duration: 90 * time.Second},
+		{PlanFile: filepath.Join(cwd, "plans", "b.md"), Status: "failed", Duration: time.Second, Err: errors.New("runner: boom")},
+		{PlanFile: filepath.Join(cwd, "plans", "c.md"), Status: "skipped"},
+	}
+	var out bytes.Buffer
+	assert.Equal(t, 1, printPlanDirSummary(results, testColors(), &out))
+	assert.Equal(t, "\nplans summary:\n  done    plans/a.md (1m30s)\n  failed  plans/b.md (1s): runner: boom\n"+
+		"  skipped plans/c.md\n", out.String())
+}
+
+func TestRunDemo(t *testing.T) {
+	t.Chdir(t.TempDir()) // runDemo enters the project dir, restored on cleanup
+	cfg, err := config.Load(filepath.Join(t.TempDir(), "config"))
+	require.NoError(t, err)
+	dir := filepath.Join(t.TempDir(), "demo")
+
+	o := opts{MaxIterations: 50, NoColor: true}
+	o.DemoCmd.Dir = dir
+	require.NoError(t, runDemo(context.Background(), o, cfg, testColors()))
+
+	// all plan items are checked off and the agent's files are written
+	planData, err := os.ReadFile(filepath.Join(dir, demo.PlanFile))
+	require.NoError(t, err)
+	assert.NotContains(t, string(planData), "- [ ]")
+	greet, err := os.ReadFile(filepath.Join(dir, "greet", "greet.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(greet), `"stranger"`)
+
+	progressData, err := os.ReadFile(filepath.Join(dir, ".ralphex", "progress", "progress-greeting.txt"))
+	require.NoError(t, err)
+	for _, want := range []string{"task iteration 2", "claude review 0", "codex iteration 2", "all phases completed successfully"} {
+		assert.Contains(t, string(progressData), want)
+	}
+}
@@ -0,0 +1,40 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/umputun/ralphex/pkg/processor"
+)
+
+// watchPauseSignals pauses the run on SIGUSR1 once the current iteration completes and continues it on SIGUSR2.
+// returns a function stopping the watch.
+func watchPauseSignals(pause *processor.Pause) func() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR1, syscall.SIGUSR2)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-sigs:
+				if sig == syscall.SIGUSR1 {
+					fmt.Fprintf(os.Stderr, "\npause requested, pausing after the current iteration (SIGUSR2 to continue)\n")
+					pause.Request()
+					continue
+				}
+				fmt.Fprintf(os.Stderr, "\ncontinue requested\n")
+				pause.Continue()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
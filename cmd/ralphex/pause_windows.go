@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+import "github.com/umputun/ralphex/pkg/processor"
+
+// watchPauseSignals is a no-op on windows, which has no SIGUSR1 and SIGUSR2.
+func watchPauseSignals(*processor.Pause) func() {
+	return func() {}
+}
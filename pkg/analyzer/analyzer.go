@@ -0,0 +1,93 @@
+// Package analyzer runs third-party analyzers, e.g. semgrep wrappers, org scanners or proprietary linters,
+// next to the external review. an analyzer gets the diff under review and reports findings, which are
+// evaluated and fixed together with the external review findings.
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/verify"
+)
+
+// Finding is an issue reported by an analyzer. Line and Severity are optional.
+type Finding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// String formats the finding as a review line, "[severity] file:line: message".
+func (f Finding) String() string {
+	var sb strings.Builder
+	if f.Severity != "" {
+		sb.WriteString("[" + f.Severity + "] ")
+	}
+	sb.WriteString(f.File)
+	if f.Line > 0 {
+		fmt.Fprintf(&sb, ":%d", f.Line)
+	}
+	sb.WriteString(": " + f.Message)
+	return sb.String()
+}
+
+// FindingsProvider reports findings on the changes under review.
+type FindingsProvider interface {
+	Run(ctx context.Context, diff string) ([]Finding, error)
+}
+
+// Command is a FindingsProvider running a shell command. the command gets the diff on stdin and prints
+// findings as JSON to stdout, either a list of findings or an object with the list in "findings".
+type Command struct {
+	Command string // command line to run
+	Shell   string // shell to run the command with, platform default if empty
+	Dir     string // working directory, current directory if empty
+}
+
+// Run runs the command with the diff and parses the findings it printed.
+func (c *Command) Run(ctx context.Context, diff string) ([]Finding, error) {
+	out, err := verify.Output(ctx, c.Shell, c.Dir, c.Command, strings.NewReader(diff))
+	if err != nil {
+		return nil, fmt.Errorf("analyzer: %w", err)
+	}
+	res, err := Parse(out)
+	if err != nil {
+		return nil, fmt.Errorf("analyzer %q: %w", c.Command, err)
+	}
+	return res, nil
+}
+
+// Parse parses analyzer JSON output, a list of findings or an object with the list in "findings".
+// empty output means no findings, findings without a file or message are rejected.
+func Parse(data []byte) ([]Finding, error) {
+	data = []byte(strings.TrimSpace(string(data)))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var res []Finding
+	if data[0] == '{' {
+		var wrapped struct {
+			Findings []Finding `json:"findings"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("parse findings: %w", err)
+		}
+		res = wrapped.Findings
+	} else if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("parse findings: %w", err)
+	}
+
+	for i, f := range res {
+		res[i].File = strings.TrimPrefix(strings.TrimSpace(f.File), "./")
+		res[i].Severity = strings.ToLower(strings.TrimSpace(f.Severity))
+		res[i].Message = strings.Join(strings.Fields(f.Message), " ")
+		if res[i].File == "" || res[i].Message == "" {
+			return nil, errors.New("parse findings: finding without file or message")
+		}
+	}
+	return res, nil
+}
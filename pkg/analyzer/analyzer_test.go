@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []Finding
+		wantErr string
+	}{
+		{name: "empty", data: " \n"},
+		{name: "empty list", data: "[]", want: []Finding{}},
+		{name: "list", data: `[{"file":"./a.go","line":3,"severity":"HIGH","message":"nil  dereference\n of x"}]`,
+			want: []Finding{{File: "a.go", Line: 3, Severity: "high", Message: "nil dereference of x"}}},
+		{name: "wrapped", data: `{"tool":"scan","findings":[{"file":"b.go","message":"unused"}]}`,
+			want: []Finding{{File: "b.go", Message: "unused"}}},
+		{name: "invalid json", data: "found 2 issues", wantErr: "parse findings"},
+		{name: "no message", data: `[{"file":"a.go","line":1}]`, wantErr: "finding without file or message"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse([]byte(tc.data))
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFinding_String(t *testing.T) {
+	assert.Equal(t, "[major] a.go:12: nil dereference", Finding{File: "a.go", Line: 12, Severity: "major", Message: "nil dereference"}.String())
+	assert.Equal(t, "b.go: unused import", Finding{File: "b.go", Message: "unused import"}.String())
+}
+
+func TestCommand_Run(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh commands")
+	}
+	c := &Command{Command: `grep -q '+bad' && echo '[{"file":"a.go","line":2,"message":"bad line"}]' || echo '[]'`}
+	got, err := c.Run(context.Background(), "--- a/a.go\n+++ b/a.go\n+bad\n")
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{{File: "a.go", Line: 2, Message: "bad line"}}, got)
+
+	got, err = c.Run(context.Background(), "--- a/a.go\n+++ b/a.go\n+good\n")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = (&Command{Command: "echo scanner crashed >&2; exit 1"}).Run(context.Background(), "")
+	require.ErrorContains(t, err, "scanner crashed")
+	_, err = (&Command{Command: "echo not json"}).Run(context.Background(), "")
+	require.ErrorContains(t, err, "parse findings")
+}
@@ -0,0 +1,280 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Spelling is a FindingsProvider reporting common misspellings in lines added by the diff under review:
+// comments and string literals of code, whole lines of docs. identifiers are left alone, renaming them is
+// not a cheap fix. words are checked against a built-in list of common misspellings, like the misspell tool.
+type Spelling struct {
+	Ignore []string // words never reported, case-insensitive
+}
+
+// docExts are extensions of files checked as prose, every added line of them is checked
+var docExts = []string{".md", ".markdown", ".txt", ".rst", ".adoc"}
+
+// hashCommentExts are extensions of files with "#" line comments
+var hashCommentExts = []string{".py", ".sh", ".bash", ".rb", ".pl", ".yml", ".yaml", ".toml", ".ini", ".conf", ".cfg", ".mk"}
+
+// hunkHeaderRe matches a unified diff hunk header, capturing the first line of the new file
+var hunkHeaderRe = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// stringLiteralRe matches double-quoted and backquoted string literals on a line
+var stringLiteralRe = regexp.MustCompile("\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`")
+
+// wordRe matches words, apostrophes included
+var wordRe = regexp.MustCompile(`[A-Za-z]+(?:'[A-Za-z]+)*`)
+
+// Run checks the lines the diff adds and reports each misspelled word once per line.
+func (s *Spelling) Run(_ context.Context, diff string) ([]Finding, error) {
+	var res []Finding
+	file, line := "", 0
+	for l := range strings.SplitSeq(diff, "\n") {
+		switch {
+		case strings.HasPrefix(l, "+++ "):
+			file = strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(l, "+++ ")), "b/")
+			if file == "/dev/null" {
+				file = ""
+			}
+		case strings.HasPrefix(l, "@@"):
+			line = 0
+			if m := hunkHeaderRe.FindStringSubmatch(l); m != nil {
+				line, _ = strconv.Atoi(m[1])
+			}
+		case strings.HasPrefix(l, "+") && file != "" && line > 0:
+			res = append(res, s.checkLine(file, line, strings.TrimPrefix(l, "+"))...)
+			line++
+		case strings.HasPrefix(l, " ") && line > 0:
+			line++
+		}
+	}
+	return res, nil
+}
+
+// checkLine reports the misspellings of the prose of an added line.
+func (s *Spelling) checkLine(file string, line int, text string) []Finding {
+	var res []Finding
+	var seen []string
+	for _, w := range wordRe.FindAllString(proseOf(file, text), -1) {
+		lw := strings.ToLower(w)
+		fix, ok := misspellings[lw]
+		if !ok || slices.Contains(seen, lw) || slices.ContainsFunc(s.Ignore, func(i string) bool { return strings.EqualFold(i, lw) }) {
+			continue
+		}
+		seen = append(seen, lw)
+		res = append(res, Finding{File: file, Line: line, Severity: "minor",
+			Message: fmt.Sprintf("%q is a misspelling of %q", w, fix)})
+	}
+	return res
+}
+
+// proseOf returns the parts of a line of file written for humans: the whole line of docs, comments
+// and string literals of code.
+func proseOf(file, text string) string {
+	ext := strings.ToLower(path.Ext(file))
+	if slices.Contains(docExts, ext) {
+		return text
+	}
+	var parts []string
+	trimmed := strings.TrimSpace(text)
+	switch {
+	case strings.HasPrefix(trimmed, "/*"), strings.HasPrefix(trimmed, "* "), trimmed == "*":
+		return trimmed // block comment line
+	case slices.Contains(hashCommentExts, ext) || path.Base(file) == "Makefile":
+		if i := strings.Index(text, "#"); i >= 0 {
+			parts = append(parts, text[i+1:])
+			text = text[:i]
+		}
+	default:
+		if i := strings.Index(text, "//"); i >= 0 && !strings.Contains(text[:i], `"`) && !strings.Contains(text[:i], "`") {
+			parts = append(parts, text[i+2:])
+			text = text[:i]
+		}
+	}
+	for _, lit := range stringLiteralRe.FindAllString(text, -1) {
+		parts = append(parts, lit[1:len(lit)-1])
+	}
+	return strings.Join(parts, " ")
+}
+
+// misspellings maps common misspellings, lowercase, to their corrections
+var misspellings = map[string]string{
+	"abscence":      "absence",
+	"accidentaly":   "accidentally",
+	"accomodate":    "accommodate",
+	"acheive":       "achieve",
+	"adress":        "address",
+	"agressive":     "aggressive",
+	"alot":          "a lot",
+	"alredy":        "already",
+	"amoung":        "among",
+	"ammount":       "amount",
+	"apparantly":    "apparently",
+	"appearence":    "appearance",
+	"arguement":     "argument",
+	"arguements":    "arguments",
+	"asynchronus":   "asynchronous",
+	"atleast":       "at least",
+	"attribtue":     "attribute",
+	"availabe":      "available",
+	"availible":     "available",
+	"begining":      "beginning",
+	"beleive":       "believe",
+	"beacuse":       "because",
+	"becuase":       "because",
+	"buisness":      "business",
+	"charachter":    "character",
+	"commited":      "committed",
+	"commiting":     "committing",
+	"comparision":   "comparison",
+	"compatability": "compatibility",
+	"compatable":    "compatible",
+	"completly":     "completely",
+	"conditon":      "condition",
+	"configuraiton": "configuration",
+	"connnection":   "connection",
+	"consistant":    "consistent",
+	"containg":      "containing",
+	"contian":       "contain",
+	"contians":      "contains",
+	"convertion":    "conversion",
+	"curent":        "current",
+	"currenly":      "currently",
+	"defualt":       "default",
+	"definately":    "definitely",
+	"definitly":     "definitely",
+	"dependancy":    "dependency",
+	"dependancies":  "dependencies",
+	"desciption":    "description",
+	"descripton":    "description",
+	"destory":       "destroy",
+	"diffrent":      "different",
+	"directoy":      "directory",
+	"dissapear":     "disappear",
+	"doesnt":        "doesn't",
+	"embarass":      "embarrass",
+	"enviroment":    "environment",
+	"enviroments":   "environments",
+	"equivalant":    "equivalent",
+	"excecute":      "execute",
+	"exection":      "execution",
+	"existance":     "existence",
+	"existant":      "existent",
+	"experiance":    "experience",
+	"explicitely":   "explicitly",
+	"extention":     "extension",
+	"failiure":      "failure",
+	"familar":       "familiar",
+	"finaly":        "finally",
+	"fucntion":      "function",
+	"funciton":      "function",
+	"futher":        "further",
+	"garantee":      "guarantee",
+	"gaurantee":     "guarantee",
+	"happend":       "happened",
+	"hierarcy":      "hierarchy",
+	"identifer":     "identifier",
+	"immediatly":    "immediately",
+	"implemenation": "implementation",
+	"implmentation": "implementation",
+	"incomming":     "incoming",
+	"incorect":      "incorrect",
+	"independant":   "independent",
+	"infomation":    "information",
+	"initalize":     "initialize",
+	"initialze":     "initialize",
+	"intial":        "initial",
+	"interupt":      "interrupt",
+	"invalide":      "invalid",
+	"lenght":        "length",
+	"libary":        "library",
+	"maintainance":  "maintenance",
+	"maintenence":   "maintenance",
+	"managment":     "management",
+	"mesage":        "message",
+	"messsage":      "message",
+	"millenium":     "millennium",
+	"mispell":       "misspell",
+	"neccessary":    "necessary",
+	"necesary":      "necessary",
+	"noticable":     "noticeable",
+	"occassion":     "occasion",
+	"occured":       "occurred",
+	"occurence":     "occurrence",
+	"occuring":      "occurring",
+	"ommit":         "omit",
+	"optionnal":     "optional",
+	"orignal":       "original",
+	"overriden":     "overridden",
+	"paramater":     "parameter",
+	"paramaters":    "parameters",
+	"paramter":      "parameter",
+	"paramters":     "parameters",
+	"persistant":    "persistent",
+	"posible":       "possible",
+	"preceeding":    "preceding",
+	"prefered":      "preferred",
+	"presense":      "presence",
+	"previos":       "previous",
+	"priviledge":    "privilege",
+	"probaly":       "probably",
+	"proccess":      "process",
+	"programatic":   "programmatic",
+	"propery":       "property",
+	"recieve":       "receive",
+	"recieved":      "received",
+	"reciever":      "receiver",
+	"recomend":      "recommend",
+	"recommand":     "recommend",
+	"recursivly":    "recursively",
+	"refered":       "referred",
+	"refering":      "referring",
+	"relevent":      "relevant",
+	"remeber":       "remember",
+	"repositry":     "repository",
+	"reponse":       "response",
+	"requirment":    "requirement",
+	"resouce":       "resource",
+	"responce":      "response",
+	"retreive":      "retrieve",
+	"retrive":       "retrieve",
+	"returing":      "returning",
+	"seperate":      "separate",
+	"seperated":     "separated",
+	"seperator":     "separator",
+	"shoud":         "should",
+	"similiar":      "similar",
+	"specifed":      "specified",
+	"speficied":     "specified",
+	"succesful":     "successful",
+	"successfull":   "successful",
+	"sucess":        "success",
+	"sucessful":     "successful",
+	"supercede":     "supersede",
+	"suport":        "support",
+	"supress":       "suppress",
+	"suprise":       "surprise",
+	"synchronus":    "synchronous",
+	"teh":           "the",
+	"tempory":       "temporary",
+	"thier":         "their",
+	"threshhold":    "threshold",
+	"tommorow":      "tomorrow",
+	"truely":        "truly",
+	"unecessary":    "unnecessary",
+	"unneccessary":  "unnecessary",
+	"untill":        "until",
+	"usefull":       "useful",
+	"valiation":     "validation",
+	"verion":        "version",
+	"wich":          "which",
+	"withing":       "within",
+	"writting":      "writing",
+}
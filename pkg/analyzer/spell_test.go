@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpelling_Run(t *testing.T) {
+	tests := []struct {
+		name   string
+		diff   string
+		ignore []string
+		want   []Finding
+	}{
+		{name: "empty diff"},
+		{name: "go comment and string", diff: "--- a/a.go\n+++ b/a.go\n@@ -10,2 +10,4 @@ func f() {\n x := 1\n-// old\n+// recieve the value, teh end\n+return errors.New(\"seperate Seperate failure\")\n",
+			want: []Finding{
+				{File: "a.go", Line: 11, Severity: "minor", Message: `"recieve" is a misspelling of "receive"`},
+				{File: "a.go", Line: 11, Severity: "minor", Message: `"teh" is a misspelling of "the"`},
+				{File: "a.go", Line: 12, Severity: "minor", Message: `"seperate" is a misspelling of "separate"`},
+			}},
+		{name: "identifiers not checked", diff: "+++ b/a.go\n@@ -0,0 +1,2 @@\n+func recieve(lenght int) {}\n+*p = seperate\n"},
+		{name: "url in string is not a comment", diff: "+++ b/a.go\n@@ -0,0 +1 @@\n+u := \"http://x.io/occured\" // fine\n",
+			want: []Finding{{File: "a.go", Line: 1, Severity: "minor", Message: `"occured" is a misspelling of "occurred"`}}},
+		{name: "block comment", diff: "+++ b/a.go\n@@ -0,0 +1,2 @@\n+/* untill\n+ * wich\n",
+			want: []Finding{
+				{File: "a.go", Line: 1, Severity: "minor", Message: `"untill" is a misspelling of "until"`},
+				{File: "a.go", Line: 2, Severity: "minor", Message: `"wich" is a misspelling of "which"`},
+			}},
+		{name: "docs whole line", diff: "+++ b/README.md\n@@ -1 +1,2 @@\n # title\n+Definately `recieve`\n",
+			want: []Finding{
+				{File: "README.md", Line: 2, Severity: "minor", Message: `"Definately" is a misspelling of "definitely"`},
+				{File: "README.md", Line: 2, Severity: "minor", Message: `"recieve" is a misspelling of "receive"`},
+			}},
+		{name: "hash comment", diff: "+++ b/run.sh\n@@ -0,0 +1 @@\n+echo $adress # enviroment\n",
+			want: []Finding{{File: "run.sh", Line: 1, Severity: "minor", Message: `"enviroment" is a misspelling of "environment"`}}},
+		{name: "removed lines and deleted files skipped", diff: "+++ b/a.md\n@@ -1 +1 @@\n-teh\n+the\n--- a/b.md\n+++ /dev/null\n@@ -1 +0,0 @@\n-teh\n"},
+		{name: "ignored words", diff: "+++ b/a.md\n@@ -0,0 +1 @@\n+Teh wich\n", ignore: []string{"teh"},
+			want: []Finding{{File: "a.md", Line: 1, Severity: "minor", Message: `"wich" is a misspelling of "which"`}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := (&Spelling{Ignore: tc.ignore}).Run(context.Background(), tc.diff)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
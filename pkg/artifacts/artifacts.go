@@ -0,0 +1,264 @@
+// Package artifacts publishes run artifacts (progress log, run report, transcripts, patches) to remote storage after a run,
+// so results survive ephemeral CI machines. storage is reached through its CLI (aws, gcloud)
+// or a custom command, keeping cloud SDKs out of the binary.
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a single upload.
+const DefaultTimeout = 5 * time.Minute
+
+// waitDelay bounds waiting for output pipes after a timeout, children of a killed shell may hold them
+const waitDelay = time.Second
+
+// Params configures a Publisher.
+type Params struct {
+	Destination string        // s3://bucket/prefix or gs://bucket/prefix
+	Command     string        // custom upload command, used instead of Destination if set
+	Timeout     time.Duration // DefaultTimeout if 0
+}
+
+// Publisher uploads a directory of artifacts to the configured backend.
+type Publisher struct {
+	backend backend
+	timeout time.Duration
+}
+
+// backend uploads dir under the run id and returns links to the uploaded artifacts.
+type backend interface {
+	upload(ctx context.Context, dir, runID string) ([]string, error)
+}
+
+// New creates a Publisher from params. returns nil, nil if publishing is not configured,
+// the nil Publisher is safe to use.
+func New(p Params) (*Publisher, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if p.Command != "" {
+		return &Publisher{backend: &commandBackend{command: p.Command}, timeout: timeout}, nil
+	}
+	if p.Destination == "" {
+		return nil, nil //nolint:nilnil // nil publisher means "not configured", Publish is nil-safe
+	}
+
+	u, err := url.Parse(p.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifacts destination %q: %w", p.Destination, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid artifacts destination %q: bucket is missing", p.Destination)
+	}
+	prefix := strings.Trim(u.Path, "/")
+	switch u.Scheme {
+	case "s3":
+		return &Publisher{backend: &s3Backend{bucket: u.Host, prefix: prefix}, timeout: timeout}, nil
+	case "gs":
+		return &Publisher{backend: &gcsBackend{bucket: u.Host, prefix: prefix}, timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("invalid artifacts destination %q: unsupported scheme, use s3:// or gs://", p.Destination)
+	}
+}
+
+// Publish uploads files in dir under runID and returns links to them. nil-safe, returns no links
+// if the publisher is not configured.
+func (p *Publisher) Publish(ctx context.Context, dir, runID string) ([]string, error) {
+	if p == nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	links, err := p.backend.upload(ctx, dir, runID)
+	if err != nil {
+		return nil, fmt.Errorf("publish artifacts: %w", err)
+	}
+	return links, nil
+}
+
+// Stage copies files into a new temporary directory, keeping their base names,
+// and returns it. a directory in files is copied with the files in it.
+// patches go into its "patches" subdirectory, written by writePatches.
+// the caller removes the directory.
+func Stage(files []string, writePatches func(dir string) error) (string, error) {
+	dir, err := os.MkdirTemp("", "ralphex-artifacts-")
+	if err != nil {
+		return "", fmt.Errorf("create staging dir: %w", err)
+	}
+	for _, f := range files {
+		if err := stage(f, filepath.Join(dir, filepath.Base(f))); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	if writePatches != nil {
+		patchDir := filepath.Join(dir, "patches")
+		if err := os.Mkdir(patchDir, 0o750); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", fmt.Errorf("create patches dir: %w", err)
+		}
+		if err := writePatches(patchDir); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", fmt.Errorf("write patches: %w", err)
+		}
+	}
+	return dir, nil
+}
+
+// RunID builds a run id from the branch and start time, safe as an object key part.
+func RunID(branch string, start time.Time) string {
+	slug := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, branch)
+	id := start.UTC().Format("20060102-150405")
+	if slug = strings.Trim(slug, "-."); slug != "" {
+		id += "-" + slug
+	}
+	return id
+}
+
+// stage copies src to dst, a directory with the files in it.
+func stage(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("open artifact: %w", err)
+	}
+	if !info.IsDir() {
+		return copyFile(src, dst)
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("read artifact dir: %w", err)
+	}
+	if err := os.Mkdir(dst, 0o750); err != nil {
+		return fmt.Errorf("create artifact dir copy: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies src to dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) //nolint:gosec // artifact paths come from the runner
+	if err != nil {
+		return fmt.Errorf("open artifact: %w", err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) //nolint:gosec // staging dir path
+	if err != nil {
+		return fmt.Errorf("create artifact copy: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("copy artifact: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("close artifact copy: %w", err)
+	}
+	return nil
+}
+
+// s3Backend uploads with the aws CLI, which picks up credentials from the environment.
+type s3Backend struct {
+	bucket, prefix string
+}
+
+func (b *s3Backend) upload(ctx context.Context, dir, runID string) ([]string, error) {
+	key := joinKey(b.prefix, runID)
+	if err := run(ctx, "aws", "s3", "cp", "--recursive", "--only-show-errors", dir, "s3://"+b.bucket+"/"+key+"/"); err != nil {
+		return nil, err
+	}
+	return []string{fmt.Sprintf("https://s3.console.aws.amazon.com/s3/buckets/%s?prefix=%s/",
+		b.bucket, url.QueryEscape(key))}, nil
+}
+
+// gcsBackend uploads with the gcloud CLI, which picks up credentials from the environment.
+type gcsBackend struct {
+	bucket, prefix string
+}
+
+func (b *gcsBackend) upload(ctx context.Context, dir, runID string) ([]string, error) {
+	key := joinKey(b.prefix, runID)
+	// trailing separator copies the directory contents, not the directory itself
+	if err := run(ctx, "gcloud", "storage", "cp", "--recursive", dir+string(filepath.Separator)+"*",
+		"gs://"+b.bucket+"/"+key+"/"); err != nil {
+		return nil, err
+	}
+	return []string{fmt.Sprintf("https://console.cloud.google.com/storage/browser/%s/%s", b.bucket, key)}, nil
+}
+
+// commandBackend runs a custom upload command through the shell. the command gets the artifacts
+// directory and run id in RALPHEX_ARTIFACTS_DIR and RALPHEX_RUN_ID, and prints links, one per line.
+type commandBackend struct {
+	command string
+}
+
+func (b *commandBackend) upload(ctx context.Context, dir, runID string) ([]string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", b.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", b.command)
+	}
+	cmd.Env = append(os.Environ(), "RALPHEX_ARTIFACTS_DIR="+dir, "RALPHEX_RUN_ID="+runID)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = waitDelay
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("artifacts command: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var links []string
+	for line := range strings.SplitSeq(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			links = append(links, line)
+		}
+	}
+	return links, nil
+}
+
+// run executes an upload CLI, including its stderr in the error.
+func run(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = waitDelay
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("%s CLI is required for this destination: %w", name, err)
+		}
+		return fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// joinKey joins an optional prefix and the run id into an object key.
+func joinKey(prefix, runID string) string {
+	if prefix == "" {
+		return runID
+	}
+	return prefix + "/" + runID
+}
@@ -0,0 +1,155 @@
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  Params
+		backend backend
+		wantErr string
+	}{
+		{name: "not configured", params: Params{}},
+		{name: "s3", params: Params{Destination: "s3://bucket/runs/ci/"},
+			backend: &s3Backend{bucket: "bucket", prefix: "runs/ci"}},
+		{name: "gcs without prefix", params: Params{Destination: "gs://bucket"},
+			backend: &gcsBackend{bucket: "bucket"}},
+		{name: "command wins", params: Params{Destination: "s3://bucket", Command: "upload.sh"},
+			backend: &commandBackend{command: "upload.sh"}},
+		{name: "unsupported scheme", params: Params{Destination: "https://example.com/x"}, wantErr: "unsupported scheme"},
+		{name: "missing bucket", params: Params{Destination: "s3:///prefix"}, wantErr: "bucket is missing"},
+		{name: "invalid url", params: Params{Destination: "s3://bu cket/%zz"}, wantErr: "invalid artifacts destination"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := New(tc.params)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tc.backend == nil {
+				assert.Nil(t, p)
+				return
+			}
+			require.NotNil(t, p)
+			assert.Equal(t, tc.backend, p.backend)
+			assert.Equal(t, DefaultTimeout, p.timeout)
+		})
+	}
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	t.Run("nil publisher", func(t *testing.T) {
+		var p *Publisher
+		links, err := p.Publish(context.Background(), t.TempDir(), "run")
+		require.NoError(t, err)
+		assert.Empty(t, links)
+	})
+
+	t.Run("command backend", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("uses sh")
+		}
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "progress.txt"), []byte("log"), 0o600))
+		p, err := New(Params{Command: `ls "$RALPHEX_ARTIFACTS_DIR"; echo; echo "https://store.example.com/$RALPHEX_RUN_ID"`})
+		require.NoError(t, err)
+
+		links, err := p.Publish(context.Background(), dir, "20260102-030405-feature")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"progress.txt", "https://store.example.com/20260102-030405-feature"}, links)
+	})
+
+	t.Run("command failure includes stderr", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("uses sh")
+		}
+		p, err := New(Params{Command: "echo denied >&2; exit 3"})
+		require.NoError(t, err)
+		_, err = p.Publish(context.Background(), t.TempDir(), "run")
+		require.ErrorContains(t, err, "publish artifacts: artifacts command")
+		require.ErrorContains(t, err, "denied")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("uses sh")
+		}
+		p, err := New(Params{Command: "sleep 5", Timeout: 50 * time.Millisecond})
+		require.NoError(t, err)
+		start := time.Now()
+		_, err = p.Publish(context.Background(), t.TempDir(), "run")
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 4*time.Second)
+	})
+
+	t.Run("missing cli", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		p, err := New(Params{Destination: "s3://bucket/prefix"})
+		require.NoError(t, err)
+		_, err = p.Publish(context.Background(), t.TempDir(), "run")
+		require.ErrorContains(t, err, "aws CLI is required")
+	})
+}
+
+func TestStage(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "progress-plan.txt")
+	require.NoError(t, os.WriteFile(src, []byte("progress"), 0o600))
+
+	t.Run("copies files and writes patches", func(t *testing.T) {
+		dir, err := Stage([]string{src}, func(dir string) error {
+			return os.WriteFile(filepath.Join(dir, "0001-x.patch"), []byte("patch"), 0o600)
+		})
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		data, err := os.ReadFile(filepath.Join(dir, "progress-plan.txt")) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Equal(t, "progress", string(data))
+		assert.FileExists(t, filepath.Join(dir, "patches", "0001-x.patch"))
+	})
+
+	t.Run("copies directories", func(t *testing.T) {
+		transcripts := filepath.Join(t.TempDir(), "run-20260102-030405")
+		require.NoError(t, os.MkdirAll(filepath.Join(transcripts, "nested"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(transcripts, "001-task.txt"), []byte("prompt"), 0o600))
+		dir, err := Stage([]string{src, transcripts}, nil)
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		data, err := os.ReadFile(filepath.Join(dir, "run-20260102-030405", "001-task.txt")) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Equal(t, "prompt", string(data))
+		assert.NoDirExists(t, filepath.Join(dir, "run-20260102-030405", "nested"))
+		assert.NoDirExists(t, filepath.Join(dir, "patches"))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := Stage([]string{filepath.Join(t.TempDir(), "missing.txt")}, nil)
+		require.ErrorContains(t, err, "open artifact")
+	})
+
+	t.Run("patches error", func(t *testing.T) {
+		_, err := Stage([]string{src}, func(string) error { return errors.New("boom") })
+		require.ErrorContains(t, err, "write patches: boom")
+	})
+}
+
+func TestRunID(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, "20260102-030405-feat-add-x", RunID("feat/add x", start))
+	assert.Equal(t, "20260102-030405", RunID("", start))
+	assert.Equal(t, "20260102-030405", RunID("///", start))
+}
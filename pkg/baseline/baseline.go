@@ -0,0 +1,102 @@
+// Package baseline keeps the project's accepted external review findings: findings triaged as false
+// positives, which the external review is asked not to report again. the baseline lives in the repository's
+// .ralphex directory and is meant to be committed, one "file: message" entry per line, # starts a comment.
+package baseline
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/history"
+)
+
+// File is the name of the baseline file in the repository's .ralphex directory.
+const File = "baseline"
+
+// header starts a new baseline file
+const header = `# accepted external review findings, not reported again by the external review.
+# one "file: message" entry per line, maintained by ralphex and editable by hand.
+`
+
+// Load reads the baseline at path. a missing file is an empty baseline, lines without a file are skipped.
+func Load(path string) ([]history.Finding, error) {
+	f, err := os.Open(path) //nolint:gosec // baseline in the repository
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open baseline: %w", err)
+	}
+	defer f.Close()
+
+	var res []history.Finding
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		file, msg, ok := strings.Cut(line, ": ")
+		if !ok || strings.TrimSpace(msg) == "" {
+			continue
+		}
+		res = append(res, history.Finding{File: strings.TrimSpace(file), Message: strings.TrimSpace(msg)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+	return res, nil
+}
+
+// Contains reports whether the baseline has an entry matching f.
+func Contains(entries []history.Finding, f history.Finding) bool {
+	key := f.Key()
+	return slices.ContainsFunc(entries, func(e history.Finding) bool { return e.Key() == key })
+}
+
+// Add appends findings missing from the baseline at path, creating the file if needed.
+// returns the number of findings added.
+func Add(path string, findings []history.Finding) (int, error) {
+	existing, err := Load(path)
+	if err != nil {
+		return 0, err
+	}
+	var sb strings.Builder
+	added := 0
+	for _, f := range findings {
+		if Contains(existing, f) {
+			continue
+		}
+		existing = append(existing, f)
+		fmt.Fprintf(&sb, "%s: %s\n", f.File, strings.Join(strings.Fields(f.Message), " "))
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, fmt.Errorf("create baseline dir: %w", err)
+	}
+	fh, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644) //nolint:gosec // committed with the repository
+	if err != nil {
+		return 0, fmt.Errorf("open baseline: %w", err)
+	}
+	data := sb.String()
+	if info, statErr := fh.Stat(); statErr == nil && info.Size() == 0 {
+		data = header + data
+	}
+	if _, err = fh.WriteString(data); err != nil {
+		_ = fh.Close()
+		return 0, fmt.Errorf("write baseline: %w", err)
+	}
+	if err = fh.Close(); err != nil {
+		return 0, fmt.Errorf("close baseline: %w", err)
+	}
+	return added, nil
+}
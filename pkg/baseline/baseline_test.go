@@ -0,0 +1,41 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/history"
+)
+
+func TestLoadAdd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ralphex", File)
+	entries, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "missing file is an empty baseline")
+
+	nilDeref := history.Finding{File: "a.go", Message: "possible nil  dereference"}
+	added, err := Add(path, []history.Finding{nilDeref, {File: "b.go", Message: "unused parameter ctx"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+
+	added, err = Add(path, []history.Finding{{File: "a.go", Message: "Possible nil dereference"}, {File: "c.go", Message: "x: y"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, added, "findings already in the baseline are skipped")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, header+"a.go: possible nil dereference\nb.go: unused parameter ctx\nc.go: x: y\n", string(data))
+
+	// hand edits: comments, blank lines and entries without a file are skipped
+	require.NoError(t, os.WriteFile(path, append(data, []byte("\n# note\nno separator\n")...), 0o600))
+	entries, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []history.Finding{{File: "a.go", Message: "possible nil dereference"},
+		{File: "b.go", Message: "unused parameter ctx"}, {File: "c.go", Message: "x: y"}}, entries)
+	assert.True(t, Contains(entries, nilDeref))
+	assert.False(t, Contains(entries, history.Finding{File: "a.go", Message: "other"}))
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// run artifact locations, see Config.ArtifactDir
+const (
+	ArtifactsRepo = "repo" // .ralphex in the repository, gitignored
+	ArtifactsUser = "user" // per-repository directory under the state dir, keeping the repository clean
+)
+
+// ArtifactKeyEnv is the environment variable with the passphrase run artifacts are encrypted with,
+// taking precedence over artifact_key.
+const ArtifactKeyEnv = "RALPHEX_ARTIFACT_KEY"
+
+// RepoArtifactDir is the directory for run artifacts inside a repository, relative to its root.
+const RepoArtifactDir = ".ralphex"
+
+// RepoArtifactGitignore is kept as .gitignore in RepoArtifactDir: everything there is a run artifact,
+// except the local config, baseline, prompts and agents meant to be shared through the repository.
+const RepoArtifactGitignore = `# managed by ralphex: run artifacts stay out of git, shared config, baseline, prompts and agents are kept
+*
+!.gitignore
+!config
+!baseline
+!prompts/
+!prompts/**
+!agents/
+!agents/**
+`
+
+// artifactEntries are the run artifacts moved between layouts. the rest of .ralphex,
+// local config, baseline, prompts and agents, is never touched.
+var artifactEntries = []string{"progress", "transcripts", "state.json", "history.jsonl"}
+
+// ArtifactDir returns the directory for run artifacts (progress logs, transcripts, checkpoint, history) of the repository at root:
+// RepoArtifactDir under root, or with artifact_location = user a per-repository directory under StateDir.
+func (c *Config) ArtifactDir(root string) string {
+	if c.ArtifactLocation == ArtifactsUser {
+		return c.userArtifactDir(root)
+	}
+	return filepath.Join(root, RepoArtifactDir)
+}
+
+// userArtifactDir returns the per-repository artifact directory under StateDir, named after the repository
+// directory and a hash of its absolute path, so repositories sharing a name don't collide.
+func (c *Config) userArtifactDir(root string) string {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		abs = root
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(c.StateDir(), "repos", filepath.Base(abs)+"-"+hex.EncodeToString(sum[:4]))
+}
+
+// MigrateArtifacts moves run artifacts of the repository at root left in the other layout after
+// artifact_location changed, so run history, transcripts and checkpoints follow the setting.
+// entries already present in the current location are left alone. returns the moved entries.
+func (c *Config) MigrateArtifacts(root string) ([]string, error) {
+	from, to := c.userArtifactDir(root), filepath.Join(root, RepoArtifactDir)
+	if c.ArtifactLocation == ArtifactsUser {
+		from, to = to, from
+	}
+
+	var moved []string
+	for _, name := range artifactEntries {
+		src, dst := filepath.Join(from, name), filepath.Join(to, name)
+		if _, err := os.Lstat(src); err != nil {
+			continue // nothing to migrate
+		}
+		if _, err := os.Lstat(dst); err == nil || !errors.Is(err, os.ErrNotExist) {
+			continue // already in place, or unknown state left alone
+		}
+		if err := os.MkdirAll(to, 0o750); err != nil {
+			return moved, fmt.Errorf("create artifact dir: %w", err)
+		}
+		if err := moveArtifact(src, dst); err != nil {
+			return moved, fmt.Errorf("move %s to %s: %w", name, to, err)
+		}
+		moved = append(moved, name)
+	}
+	return moved, nil
+}
+
+// moveArtifact moves a file or directory, copying it when a rename isn't possible,
+// e.g. with the repository and the home directory on different filesystems.
+func moveArtifact(src, dst string) error {
+	if os.Rename(src, dst) == nil {
+		return nil
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("stat: %w", err)
+	}
+	if info.IsDir() {
+		if err = os.CopyFS(dst, os.DirFS(src)); err != nil {
+			return fmt.Errorf("copy: %w", err)
+		}
+	} else {
+		data, readErr := os.ReadFile(src) //nolint:gosec // artifact path built from known entries
+		if readErr != nil {
+			return fmt.Errorf("read: %w", readErr)
+		}
+		if err = os.WriteFile(dst, data, 0o600); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+	}
+	if err = os.RemoveAll(src); err != nil {
+		return fmt.Errorf("remove source: %w", err)
+	}
+	return nil
+}
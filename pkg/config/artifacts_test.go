@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_ArtifactDir(t *testing.T) {
+	state := t.TempDir()
+	root := filepath.Join(t.TempDir(), "myrepo")
+
+	cfg := &Config{dataDir: state}
+	assert.Equal(t, filepath.Join(root, ".ralphex"), cfg.ArtifactDir(root))
+	assert.Equal(t, ".ralphex", cfg.ArtifactDir("."))
+
+	cfg.ArtifactLocation = ArtifactsUser
+	dir := cfg.ArtifactDir(root)
+	assert.Equal(t, filepath.Join(state, "state", "repos"), filepath.Dir(dir))
+	assert.True(t, strings.HasPrefix(filepath.Base(dir), "myrepo-"), dir)
+	assert.NotEqual(t, dir, cfg.ArtifactDir(filepath.Join(t.TempDir(), "myrepo")), "same name, different repository")
+}
+
+func TestConfig_MigrateArtifacts(t *testing.T) {
+	root := t.TempDir()
+	cfg := &Config{dataDir: t.TempDir()}
+	repoDir := filepath.Join(root, ".ralphex")
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "progress"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "progress", "progress-plan.txt"), []byte("log"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "state.json"), []byte("{}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "config"), []byte("x = 1"), 0o600))
+
+	// repo layout, nothing in the user dir to bring back
+	moved, err := cfg.MigrateArtifacts(root)
+	require.NoError(t, err)
+	assert.Empty(t, moved)
+
+	// switching to the user layout moves artifacts out of the repository, local config stays
+	cfg.ArtifactLocation = ArtifactsUser
+	moved, err = cfg.MigrateArtifacts(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"progress", "state.json"}, moved)
+	userDir := cfg.ArtifactDir(root)
+	assert.FileExists(t, filepath.Join(userDir, "progress", "progress-plan.txt"))
+	assert.FileExists(t, filepath.Join(userDir, "state.json"))
+	assert.NoDirExists(t, filepath.Join(repoDir, "progress"))
+	assert.FileExists(t, filepath.Join(repoDir, "config"))
+
+	// switching back moves them into the repository, an existing entry is not overwritten
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "state.json"), []byte(`{"new":true}`), 0o600))
+	cfg.ArtifactLocation = ArtifactsRepo
+	moved, err = cfg.MigrateArtifacts(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"progress"}, moved)
+	assert.FileExists(t, filepath.Join(repoDir, "progress", "progress-plan.txt"))
+	data, err := os.ReadFile(filepath.Join(repoDir, "state.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"new":true}`, string(data))
+}
+
+func TestMoveArtifact(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "progress")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "sub"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "sub", "a.txt"), []byte("a"), 0o600))
+	dst := filepath.Join(t.TempDir(), "progress")
+
+	require.NoError(t, moveArtifact(src, dst))
+	assert.NoDirExists(t, src)
+	data, err := os.ReadFile(filepath.Join(dst, "sub", "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(data))
+}
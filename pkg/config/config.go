@@ -4,10 +4,15 @@ package config
 import (
 	"embed"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 
+	"github.com/umputun/ralphex/pkg/artifacts"
+	"github.com/umputun/ralphex/pkg/keychain"
+	"github.com/umputun/ralphex/pkg/logship"
 	"github.com/umputun/ralphex/pkg/notify"
+	"github.com/umputun/ralphex/pkg/seal"
 )
 
 //go:embed defaults/config defaults/prompts/* defaults/agents/*
@@ -23,8 +28,127 @@ const (
 	finalizePromptFile     = "finalize.txt"
 	customReviewPromptFile = "custom_review.txt"
 	customEvalPromptFile   = "custom_eval.txt"
+	planLintPromptFile     = "plan_lint.txt"
 )
 
+// show_diff values
+const (
+	ShowDiffNone      = "none"      // don't print diffs
+	ShowDiffIteration = "iteration" // print changes after each iteration
+	ShowDiffPhase     = "phase"     // print changes after each phase (tasks, review, external review)
+)
+
+// gomod_gate values, what to do when the module files are left inconsistent after the task phase
+const (
+	GoModGateOff      = "off"      // don't check
+	GoModGateFeedback = "feedback" // continue the task phase with the problems as feedback
+	GoModGateFail     = "fail"     // fail the run
+)
+
+// generate_gate values, what to do when regenerating code changes generated files after the task phase
+const (
+	GenerateGateOff      = "off"      // don't check
+	GenerateGateFeedback = "feedback" // continue the task phase with the drifted files as feedback
+	GenerateGateFail     = "fail"     // fail the run
+)
+
+// format_gate values, what to do when files changed by a task iteration are not formatted
+const (
+	FormatGateOff      = "off"      // don't check
+	FormatGateFix      = "fix"      // format the files and commit the result
+	FormatGateFeedback = "feedback" // leave the files as they are and give the agent the unformatted files as feedback
+)
+
+// artifact_gate values, what to do when a task iteration adds binary or oversized files
+const (
+	ArtifactGateOff      = "off"      // don't check
+	ArtifactGateWarn     = "warn"     // log the files
+	ArtifactGateFeedback = "feedback" // continue the task phase with the files to remove as feedback
+	ArtifactGateFail     = "fail"     // fail the run
+)
+
+// rollback_on_failure values, what to reset the worktree to when the task phase fails with a FAILED signal
+const (
+	RollbackOff       = "off"       // leave the worktree as the failed task left it
+	RollbackRun       = "run"       // back to the state before the run, commits of the run dropped
+	RollbackIteration = "iteration" // back to the state before the failed task iteration, earlier iterations kept
+)
+
+// completed_plan values, what to do when every task of the plan is checked before the task phase starts
+const (
+	CompletedPlanRun    = "run"    // run the task phase anyway
+	CompletedPlanReview = "review" // skip the task phase, go on with the review phases
+	CompletedPlanExit   = "exit"   // end the run with processor.ErrNothingToDo
+)
+
+// license_check values, what to do when a module added by the run has a license not allowed
+const (
+	LicenseCheckOff  = "off"  // don't check
+	LicenseCheckWarn = "warn" // log the modules and list them in the run report
+	LicenseCheckFail = "fail" // fail the run as well
+)
+
+// on_codex_error and on_review_error values, the failure policy of a phase
+const (
+	OnErrorAbort = "abort" // stop the run
+	OnErrorSkip  = "skip"  // log a warning and continue with the next phase
+	OnErrorRetry = "retry" // run the phase once more, stop the run if it fails again
+)
+
+// finding severities, lowest first
+const (
+	SeverityInfo     = "info"
+	SeverityMinor    = "minor"
+	SeverityMajor    = "major"
+	SeverityCritical = "critical"
+)
+
+// Severities lists finding severities, lowest first.
+var Severities = []string{SeverityInfo, SeverityMinor, SeverityMajor, SeverityCritical}
+
+// pipeline phases, see PhaseSpec
+const (
+	PhaseTask     = "task"     // task iterations until the plan is done
+	PhaseReview   = "review"   // claude review loop, the first review of a pipeline starts with a pass addressing all findings
+	PhaseCodex    = "codex"    // external review loop, codex or custom
+	PhaseFinalize = "finalize" // finalize step
+)
+
+// PhaseSpec is a phase of a custom pipeline, configured as a comma-separated list of phase names,
+// e.g. "task, review, codex?, review". a name ending with "?" marks an optional phase.
+type PhaseSpec struct {
+	Name     string `json:"name"`               // PhaseTask, PhaseReview, PhaseCodex or PhaseFinalize
+	Optional bool   `json:"optional,omitempty"` // a failure of the phase is logged and the pipeline continues
+}
+
+// String returns the phase as configured.
+func (p PhaseSpec) String() string {
+	if p.Optional {
+		return p.Name + "?"
+	}
+	return p.Name
+}
+
+// hook points, see Hook
+const (
+	HookPreTask    = "pre_task"
+	HookPostTask   = "post_task"
+	HookPreReview  = "pre_review"
+	HookPostReview = "post_review"
+	HookPreCodex   = "pre_codex"
+	HookPostCodex  = "post_codex"
+)
+
+// HookPoints lists the hook points, configured as hook_<point> keys.
+var HookPoints = []string{HookPreTask, HookPostTask, HookPreReview, HookPostReview, HookPreCodex, HookPostCodex}
+
+// Hook is a shell command run before or after a phase: task, each claude review and each external review.
+// post hooks run only after the phase succeeded.
+type Hook struct {
+	Command  string `json:"command"`
+	Required bool   `json:"required,omitempty"` // a failure aborts the run, otherwise it is logged and the run continues
+}
+
 // Config holds all configuration settings for ralphex.
 // Fields ending in *Set track whether that field was explicitly set in config.
 // This allows distinguishing explicit false/0 from "not set", enabling proper
@@ -35,10 +159,22 @@ const (
 //   - CodexTimeoutMsSet: tracks if codex_timeout_ms was explicitly set
 //   - IterationDelayMsSet: tracks if iteration_delay_ms was explicitly set
 //   - TaskRetryCountSet: tracks if task_retry_count was explicitly set
+//   - MaxOutputBytesSet: tracks if max_output_bytes was explicitly set
 //   - FinalizeEnabledSet: tracks if finalize_enabled was explicitly set
+//   - PartialCloneFetchSet: tracks if partial_clone_fetch was explicitly set
+//   - VerifyEnabledSet: tracks if verify_enabled was explicitly set
+//   - VerifyTimeoutMsSet: tracks if verify_timeout_ms was explicitly set
+//   - ShowDiffMaxLinesSet: tracks if show_diff_max_lines was explicitly set
+//   - AnnotatePlanSet: tracks if annotate_plan was explicitly set
+//   - IterationCostSet: tracks if iteration_cost was explicitly set
+//   - WarnPromptChangeSet: tracks if warn_prompt_change was explicitly set
+//   - AccessibleOutputSet: tracks if accessible_output was explicitly set
+//   - NeedsInputTimeoutMsSet: tracks if needs_input_timeout_ms was explicitly set
+//   - ArchivePlansSet: tracks if archive_plans was explicitly set
 type Config struct {
 	ClaudeCommand string `json:"claude_command"`
 	ClaudeArgs    string `json:"claude_args"`
+	PlanLintArgs  string `json:"plan_lint_args"` // primary command args for "plan lint", empty uses claude_args
 
 	CodexEnabled         bool   `json:"codex_enabled"`
 	CodexEnabledSet      bool   `json:"-"` // tracks if codex_enabled was explicitly set in config
@@ -51,18 +187,189 @@ type Config struct {
 
 	ExternalReviewTool string `json:"external_review_tool"` // "codex", "custom", or "none"
 	CustomReviewScript string `json:"custom_review_script"` // path to custom review script
+	RepeatUntilClean   int    `json:"repeat_until_clean"`   // extra external review + review rounds until clean, 0 disables
+
+	Phases []PhaseSpec `json:"phases"` // custom pipeline replacing the full mode, empty for task → review → codex → review
+
+	Hooks map[string]Hook `json:"hooks"` // shell commands by hook point, see HookPoints
+
+	// third-party analyzers by name, commands getting the diff under review on stdin and printing
+	// JSON findings, run next to the external review
+	Analyzers map[string]string `json:"analyzers"`
+
+	// built-in spelling check of comments, docs and strings added on the branch, run next to the external review
+	SpellCheck  bool     `json:"spell_check"`
+	SpellIgnore []string `json:"spell_ignore"` // words never reported
+
+	// out-of-process plugins by name, executables speaking the plugin protocol, see the plugin package
+	Plugins map[string]string `json:"plugins"`
+
+	// run the claude first review, reporting only, and the first external review at the same time,
+	// followed by a single pass fixing the findings of both
+	ParallelFirstReview bool `json:"parallel_first_review"`
+
+	// run the first review like ParallelFirstReview, fixing only the findings both reviews report
+	ConsensusReview bool `json:"consensus_review"`
+
+	// split the first codex review into one run per changed directory, e.g. pkg/processor, once the diff
+	// changes at least CodexSplitDirs of them (0 = never), running CodexSplitConcurrency at a time
+	CodexSplitDirs        int `json:"codex_split_dirs"`
+	CodexSplitConcurrency int `json:"codex_split_concurrency"`
+
+	// in full mode, run the review and external review after the tasks of each "## " section of the plan
+	// holding tasks, reviewing the changes of that section, instead of once after all tasks
+	MilestoneReviews bool `json:"milestone_reviews"`
+
+	// after the external review rounds, run a verification pass of the external review and, if it still
+	// reports findings, an escalation review fixing them with the executor overrides below
+	EscalationReview               bool   `json:"escalation_review"`
+	EscalationClaudeArgs           string `json:"escalation_claude_args"`            // empty uses claude_args
+	EscalationCodexModel           string `json:"escalation_codex_model"`            // empty uses codex_model
+	EscalationCodexReasoningEffort string `json:"escalation_codex_reasoning_effort"` // empty uses codex_reasoning_effort
+
+	// run the external review once more on the final diff before finalize, only to report the findings left
+	FinalVerification bool `json:"final_verification"`
+
+	// skip of the claude review after an external review: when all its findings are below the severity
+	// (one of Severities, "none" or empty never skips), or when it reported fewer findings, 0 = never skip
+	PostReviewSkipSeverity string `json:"post_review_skip_severity"`
+	PostReviewSkipFindings int    `json:"post_review_skip_findings"`
+
+	// failure policies of external review and claude review phases: OnErrorAbort, OnErrorSkip or OnErrorRetry
+	OnCodexError  string `json:"on_codex_error"`
+	OnReviewError string `json:"on_review_error"`
+
+	MaxRunDurationMs     int `json:"max_run_duration_ms"`     // wall-clock budget of the whole run, 0 = no limit
+	TaskPhaseTimeoutMs   int `json:"task_phase_timeout_ms"`   // limit for the whole task phase, 0 = no limit
+	ReviewPhaseTimeoutMs int `json:"review_phase_timeout_ms"` // limit for each claude review phase, 0 = no limit
+	CodexPhaseTimeoutMs  int `json:"codex_phase_timeout_ms"`  // limit for each external review loop, 0 = no limit
+
+	// time of day runs are allowed in, the zero value allows any time
+	RunWindow RunWindow `json:"run_window"`
 
 	IterationDelayMs    int  `json:"iteration_delay_ms"`
 	IterationDelayMsSet bool `json:"-"` // tracks if iteration_delay_ms was explicitly set in config
 	TaskRetryCount      int  `json:"task_retry_count"`
-	TaskRetryCountSet   bool `json:"-"` // tracks if task_retry_count was explicitly set in config
+	TaskRetryCountSet   bool `json:"-"`                // tracks if task_retry_count was explicitly set in config
+	MaxOutputBytes      int  `json:"max_output_bytes"` // executor output retained per iteration, 0 = unlimited
+	MaxOutputBytesSet   bool `json:"-"`                // tracks if max_output_bytes was explicitly set in config
+
+	// iterations one plan task may take before the task phase fails, 0 = derived from the plan and max iterations
+	TaskMaxIterations int `json:"task_max_iterations"`
+
+	// worktree reset when the task phase fails with a FAILED signal, see Rollback* values
+	RollbackOnFailure string `json:"rollback_on_failure"`
+
+	// task iterations after the first continue the agent session of the previous iteration
+	ContinueSession bool `json:"continue_session"`
+
+	// what to do with a plan having all its tasks checked, see CompletedPlan* values
+	CompletedPlan string `json:"completed_plan"`
+
+	// retry of failed executor calls, the delay doubles with each attempt up to the max delay
+	ExecutorRetryCount      int     `json:"executor_retry_count"`        // retries of a failed call, 0 = fail at once
+	ExecutorRetryDelayMs    int     `json:"executor_retry_delay_ms"`     // delay before the first retry
+	ExecutorRetryMaxDelayMs int     `json:"executor_retry_max_delay_ms"` // upper limit of the delay, 0 = no limit
+	ExecutorRetryJitter     float64 `json:"executor_retry_jitter"`       // random share of the delay added or removed, 0-1
+
+	// no-progress detection of the task loop: the run fails after this many task iterations in a row
+	// leaving the plan and the worktree unchanged with output as similar as StallSimilarity, 0 disables
+	StallIterations int     `json:"stall_iterations"`
+	StallSimilarity float64 `json:"stall_similarity"` // minimal similarity of consecutive outputs, 0-1
+
+	// iteration budget extension of the task loop: when max iterations are used up while the plan keeps
+	// advancing, the limit is multiplied by BudgetExtensionFactor, at most BudgetMaxExtensions times. 0 disables
+	BudgetExtensionFactor float64 `json:"budget_extension_factor"`
+	BudgetMaxExtensions   int     `json:"budget_max_extensions"`
+
+	// disk usage guard, checked before each iteration: the run pauses with a notification when free space
+	// of the worktree or temp filesystem drops below DiskMinFreeMB or the worktree grew by more than
+	// DiskMaxGrowthMB since the run started, 0 disables a check
+	DiskMinFreeMB   int `json:"disk_min_free_mb"`
+	DiskMaxGrowthMB int `json:"disk_max_growth_mb"`
+
+	// go.mod gate run once all tasks are completed: go mod tidy and go mod verify, see GoModGate* values
+	GoModGate         string `json:"gomod_gate"`
+	GoModBlockNewDeps bool   `json:"gomod_block_new_deps"` // report dependencies added without the plan allowing them
+
+	// dependency policy for modules added to go.mod by the agents: module paths, globs or "path/..." patterns
+	DepsAllow []string `json:"deps_allow"` // only these may be added, any if empty
+	DepsDeny  []string `json:"deps_deny"`  // these may not be added, wins over DepsAllow
+
+	// generated code gate run once all tasks are completed: the generators must leave generated files
+	// unchanged, see GenerateGate* values
+	GenerateGate     string   `json:"generate_gate"`
+	GenerateCommands []string `json:"generate_commands"` // generator commands, go generate ./... if empty
+
+	// formatting gate run after each task iteration on the files changed on the branch, see FormatGate* values
+	FormatGate string            `json:"format_gate"`
+	Formatters map[string]string `json:"formatters"` // formatter commands by file extension from formatter_<ext> keys
+
+	// license check of modules added to go.mod by the run, see LicenseCheck* values
+	LicenseCheck string   `json:"license_check"`
+	LicenseAllow []string `json:"license_allow"` // SPDX identifiers of allowed licenses
+
+	// artifact gate run after each task iteration on binary and oversized files added on the branch,
+	// see ArtifactGate* values
+	ArtifactGate      string   `json:"artifact_gate"`
+	ArtifactMaxSizeKB int      `json:"artifact_max_size_kb"` // files above are flagged, 0 flags binary files only
+	ArtifactAllow     []string `json:"artifact_allow"`       // path.Match patterns of files never flagged
 
 	FinalizeEnabled    bool `json:"finalize_enabled"`
 	FinalizeEnabledSet bool `json:"-"` // tracks if finalize_enabled was explicitly set in config
 
-	PlansDir      string   `json:"plans_dir"`
-	WatchDirs     []string `json:"watch_dirs"`     // directories to watch for progress files
-	DefaultBranch string   `json:"default_branch"` // override auto-detected default branch
+	// diff viewer, prints changes through the progress logger per iteration or per phase
+	ShowDiff            string `json:"show_diff"` // ShowDiffNone, ShowDiffIteration or ShowDiffPhase
+	ShowDiffMaxLines    int    `json:"show_diff_max_lines"`
+	ShowDiffMaxLinesSet bool   `json:"-"` // tracks if show_diff_max_lines was explicitly set in config
+
+	AnnotatePlan    bool `json:"annotate_plan"` // append run outcomes to the plan file
+	AnnotatePlanSet bool `json:"-"`             // tracks if annotate_plan was explicitly set in config
+
+	IterationCost    float64 `json:"iteration_cost"` // average cost of an iteration for "plan estimate", 0 = unknown
+	IterationCostSet bool    `json:"-"`              // tracks if iteration_cost was explicitly set in config
+
+	WarnPromptChange    bool `json:"warn_prompt_change"` // warn before the first run with changed prompt templates
+	WarnPromptChangeSet bool `json:"-"`                  // tracks if warn_prompt_change was explicitly set in config
+
+	// screen reader friendly progress output: no colors or decorations on stdout, periodic status lines
+	AccessibleOutput    bool `json:"accessible_output"`
+	AccessibleOutputSet bool `json:"-"` // tracks if accessible_output was explicitly set in config
+
+	// how long to wait for the answer to a NEEDS_INPUT question of the agent when stdin is not a terminal,
+	// 0 fails the run at once. with a terminal the run waits for the answer
+	NeedsInputTimeoutMs    int  `json:"needs_input_timeout_ms"`
+	NeedsInputTimeoutMsSet bool `json:"-"` // tracks if needs_input_timeout_ms was explicitly set in config
+
+	// fail the external review when a finding is neither fixed nor explained with a DISMISSED or SKIPPED line
+	RequireFindingResolution bool `json:"require_finding_resolution"`
+	// reject a review done signal when the response reports fixes without changes or the verification gate fails
+	VerifyReviewDone bool `json:"verify_review_done"`
+
+	PlansDir        string   `json:"plans_dir"`
+	ArchivePlans    bool     `json:"archive_plans"`  // move completed plans to ArchiveDir instead of completed/
+	ArchivePlansSet bool     `json:"-"`              // tracks if archive_plans was explicitly set in config
+	ArchiveDir      string   `json:"archive_dir"`    // archive directory, relative paths are resolved from the repo root
+	WatchDirs       []string `json:"watch_dirs"`     // directories to watch for progress files
+	DefaultBranch   string   `json:"default_branch"` // override auto-detected default branch
+	GitCommand      string   `json:"git_command"`    // git binary used for repository operations
+
+	PartialCloneFetch    bool `json:"partial_clone_fetch"` // fetch blobs missing from a partial clone before reviews
+	PartialCloneFetchSet bool `json:"-"`                   // tracks if partial_clone_fetch was explicitly set in config
+
+	// verification gate run after each task iteration
+	VerifyEnabled          bool     `json:"verify_enabled"`
+	VerifyEnabledSet       bool     `json:"-"`              // tracks if verify_enabled was explicitly set in config
+	VerifyProfile          string   `json:"verify_profile"` // built-in profile name or "auto"
+	VerifyTargets          []string `json:"verify_targets"`
+	VerifyCommands         []string `json:"verify_commands"`
+	VerifyTimeoutMs        int      `json:"verify_timeout_ms"`
+	VerifyTimeoutMsSet     bool     `json:"-"`                        // tracks if verify_timeout_ms was explicitly set in config
+	VerifyImage            string   `json:"verify_image"`             // run verification in this container image
+	VerifyContainerCommand string   `json:"verify_container_command"` // container CLI, docker if empty
+	VerifyServices         string   `json:"verify_services"`          // compose file with services for integration tests
+	VerifyGoVersions       []string `json:"verify_go_versions"`       // run verification once per go version
+	VerifyShell            []string `json:"verify_shell"`             // shells for verification commands, per os
 
 	// error patterns to detect in executor output (e.g., rate limit messages)
 	ClaudeErrorPatterns []string `json:"claude_error_patterns"`
@@ -71,9 +378,35 @@ type Config struct {
 	// notification parameters
 	NotifyParams notify.Params `json:"-"`
 
+	// artifacts publishing parameters, publishing is disabled if neither destination nor command is set
+	ArtifactsParams artifacts.Params `json:"-"`
+
+	// run event shipping, labels are set per run
+	LogShipParams logship.Params `json:"-"`
+
+	// endpoint anonymous telemetry aggregates are reported to, stats stay local if empty
+	TelemetryEndpoint string `json:"telemetry_endpoint"`
+
+	// where run artifacts live, ArtifactsRepo (default) or ArtifactsUser, see ArtifactDir
+	ArtifactLocation string `json:"artifact_location"`
+
+	// passphrase the checkpoint, transcripts and run history are encrypted with, from artifact_key or
+	// ArtifactKeyEnv, and the sealer made from it. both are empty without a key, artifacts stay in plaintext
+	ArtifactKey    string       `json:"-"`
+	ArtifactSealer *seal.Sealer `json:"-"`
+
 	// output colors (RGB values as comma-separated strings)
 	Colors ColorConfig `json:"-"`
 
+	// project instructions appended to the built-in or custom prompts of a phase, for simple "always do X" rules
+	TaskInstructions   string `json:"task_instructions"`   // task iterations
+	ReviewInstructions string `json:"review_instructions"` // claude reviews
+	CodexInstructions  string `json:"codex_instructions"`  // external reviews, codex or custom
+
+	// language the agents write reports, plan text and commit messages in, e.g. "German" or "pt-BR".
+	// signals and the formats ralphex parses stay as they are, empty leaves the language to the prompts
+	Language string `json:"language"`
+
 	// prompts (loaded separately from files)
 	TaskPrompt         string `json:"-"`
 	ReviewFirstPrompt  string `json:"-"`
@@ -83,12 +416,14 @@ type Config struct {
 	FinalizePrompt     string `json:"-"`
 	CustomReviewPrompt string `json:"-"`
 	CustomEvalPrompt   string `json:"-"`
+	PlanLintPrompt     string `json:"-"`
 
 	// custom agents (loaded separately from files)
 	CustomAgents []CustomAgent `json:"-"`
 
 	configDir string // private, global config directory set by Load()
 	localDir  string // private, local project config directory (.ralphex/) if found
+	dataDir   string // private, parent of state and cache dirs if set, platform locations otherwise
 }
 
 // CustomAgent represents a user-defined review agent.
@@ -113,7 +448,8 @@ type ColorConfig struct {
 }
 
 // Load loads all configuration from the specified directory.
-// If configDir is empty, uses the default location (~/.config/ralphex/).
+// If configDir is empty, uses the default location, see DefaultConfigDir.
+// an explicit configDir also holds state and cache, keeping custom setups self-contained.
 // It also auto-detects .ralphex/ in the current working directory for local overrides.
 // It installs defaults if needed, parses config file, loads prompts and agents.
 func Load(configDir string) (*Config, error) {
@@ -133,11 +469,16 @@ func Load(configDir string) (*Config, error) {
 		}
 	}
 
-	return loadWithLocal(globalDir, localDir)
+	cfg, err := loadWithLocal(globalDir, localDir)
+	if err != nil {
+		return nil, err
+	}
+	cfg.dataDir = configDir
+	return cfg, nil
 }
 
 // loadWithLocal loads configuration with explicit global and local directories.
-// local config (.ralphex/) overrides global config (~/.config/ralphex/) per-field.
+// local config (.ralphex/) overrides global config (DefaultConfigDir) per-field.
 // if localDir is empty, only global config is used.
 func loadWithLocal(globalDir, localDir string) (*Config, error) {
 	// install defaults
@@ -167,7 +508,12 @@ func LoadReadOnly(configDir string) (*Config, error) {
 		}
 	}
 
-	return loadConfigFromDirs(globalDir, localDir)
+	cfg, err := loadConfigFromDirs(globalDir, localDir)
+	if err != nil {
+		return nil, err
+	}
+	cfg.dataDir = configDir
+	return cfg, nil
 }
 
 // loadConfigFromDirs loads configuration from specified directories without installing defaults.
@@ -220,31 +566,83 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 		return nil, fmt.Errorf("load agents: %w", err)
 	}
 
+	// validate prompt templates once at startup, so typos are reported with file positions
+	// instead of silently leaving {{VARIABLES}} unexpanded on every iteration
+	for _, issue := range validatePromptTemplates(prompts, pl.sources, agents) {
+		log.Printf("[WARN] prompt template %s", issue)
+	}
+
 	// assemble config
 	c := &Config{
-		ClaudeCommand:        values.ClaudeCommand,
-		ClaudeArgs:           values.ClaudeArgs,
-		CodexEnabled:         values.CodexEnabled,
-		CodexEnabledSet:      values.CodexEnabledSet,
-		CodexCommand:         values.CodexCommand,
-		CodexModel:           values.CodexModel,
-		CodexReasoningEffort: values.CodexReasoningEffort,
-		CodexTimeoutMs:       values.CodexTimeoutMs,
-		CodexTimeoutMsSet:    values.CodexTimeoutMsSet,
-		CodexSandbox:         values.CodexSandbox,
-		ExternalReviewTool:   values.ExternalReviewTool,
-		CustomReviewScript:   values.CustomReviewScript,
-		IterationDelayMs:     values.IterationDelayMs,
-		IterationDelayMsSet:  values.IterationDelayMsSet,
-		TaskRetryCount:       values.TaskRetryCount,
-		TaskRetryCountSet:    values.TaskRetryCountSet,
-		FinalizeEnabled:      values.FinalizeEnabled,
-		FinalizeEnabledSet:   values.FinalizeEnabledSet,
-		PlansDir:             values.PlansDir,
-		DefaultBranch:        values.DefaultBranch,
-		WatchDirs:            values.WatchDirs,
-		ClaudeErrorPatterns:  values.ClaudeErrorPatterns,
-		CodexErrorPatterns:   values.CodexErrorPatterns,
+		ClaudeCommand:          values.ClaudeCommand,
+		ClaudeArgs:             values.ClaudeArgs,
+		PlanLintArgs:           values.PlanLintArgs,
+		CodexEnabled:           values.CodexEnabled,
+		CodexEnabledSet:        values.CodexEnabledSet,
+		CodexCommand:           values.CodexCommand,
+		CodexModel:             values.CodexModel,
+		CodexReasoningEffort:   values.CodexReasoningEffort,
+		CodexTimeoutMs:         values.CodexTimeoutMs,
+		CodexTimeoutMsSet:      values.CodexTimeoutMsSet,
+		CodexSandbox:           values.CodexSandbox,
+		ExternalReviewTool:     values.ExternalReviewTool,
+		CustomReviewScript:     values.CustomReviewScript,
+		RepeatUntilClean:       values.RepeatUntilClean,
+		Phases:                 values.Phases,
+		Hooks:                  buildHooks(values),
+		MaxRunDurationMs:       values.MaxRunDurationMs,
+		RunWindow:              values.RunWindow,
+		TaskPhaseTimeoutMs:     values.TaskPhaseTimeoutMs,
+		ReviewPhaseTimeoutMs:   values.ReviewPhaseTimeoutMs,
+		CodexPhaseTimeoutMs:    values.CodexPhaseTimeoutMs,
+		IterationDelayMs:       values.IterationDelayMs,
+		IterationDelayMsSet:    values.IterationDelayMsSet,
+		TaskRetryCount:         values.TaskRetryCount,
+		TaskRetryCountSet:      values.TaskRetryCountSet,
+		TaskMaxIterations:      values.TaskMaxIterations,
+		RollbackOnFailure:      values.RollbackOnFailure,
+		ContinueSession:        values.ContinueSession,
+		CompletedPlan:          values.CompletedPlan,
+		MaxOutputBytes:         values.MaxOutputBytes,
+		MaxOutputBytesSet:      values.MaxOutputBytesSet,
+		FinalizeEnabled:        values.FinalizeEnabled,
+		FinalizeEnabledSet:     values.FinalizeEnabledSet,
+		PlansDir:               values.PlansDir,
+		ArchivePlans:           values.ArchivePlans,
+		ArchivePlansSet:        values.ArchivePlansSet,
+		ArchiveDir:             values.ArchiveDir,
+		DefaultBranch:          values.DefaultBranch,
+		GitCommand:             values.GitCommand,
+		PartialCloneFetch:      values.PartialCloneFetch,
+		PartialCloneFetchSet:   values.PartialCloneFetchSet,
+		VerifyEnabled:          values.VerifyEnabled,
+		VerifyEnabledSet:       values.VerifyEnabledSet,
+		VerifyProfile:          values.VerifyProfile,
+		VerifyTargets:          values.VerifyTargets,
+		VerifyImage:            values.VerifyImage,
+		VerifyContainerCommand: values.VerifyContainerCommand,
+		VerifyServices:         values.VerifyServices,
+		VerifyGoVersions:       values.VerifyGoVersions,
+		VerifyShell:            values.VerifyShell,
+		VerifyCommands:         values.VerifyCommands,
+		VerifyTimeoutMs:        values.VerifyTimeoutMs,
+		VerifyTimeoutMsSet:     values.VerifyTimeoutMsSet,
+		WatchDirs:              values.WatchDirs,
+		ShowDiff:               values.ShowDiff,
+		ShowDiffMaxLines:       values.ShowDiffMaxLines,
+		ShowDiffMaxLinesSet:    values.ShowDiffMaxLinesSet,
+		AnnotatePlan:           values.AnnotatePlan,
+		AnnotatePlanSet:        values.AnnotatePlanSet,
+		IterationCost:          values.IterationCost,
+		IterationCostSet:       values.IterationCostSet,
+		WarnPromptChange:       values.WarnPromptChange,
+		WarnPromptChangeSet:    values.WarnPromptChangeSet,
+		AccessibleOutput:       values.AccessibleOutput,
+		AccessibleOutputSet:    values.AccessibleOutputSet,
+		NeedsInputTimeoutMs:    values.NeedsInputTimeoutMs,
+		NeedsInputTimeoutMsSet: values.NeedsInputTimeoutMsSet,
+		ClaudeErrorPatterns:    values.ClaudeErrorPatterns,
+		CodexErrorPatterns:     values.CodexErrorPatterns,
 		NotifyParams: notify.Params{
 			Channels:      values.NotifyChannels,
 			OnError:       values.NotifyOnError,
@@ -264,7 +662,82 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 			WebhookURLs:   values.NotifyWebhookURLs,
 			CustomScript:  values.NotifyCustomScript,
 		},
+		ArtifactsParams: artifacts.Params{
+			Destination: values.ArtifactsDestination,
+			Command:     values.ArtifactsCommand,
+		},
+		ExecutorRetryCount:      values.ExecutorRetryCount,
+		ExecutorRetryDelayMs:    values.ExecutorRetryDelayMs,
+		ExecutorRetryMaxDelayMs: values.ExecutorRetryMaxDelayMs,
+		ExecutorRetryJitter:     values.ExecutorRetryJitter,
+
+		RequireFindingResolution: values.RequireFindingResolution,
+		VerifyReviewDone:         values.VerifyReviewDone,
+
+		OnCodexError:  values.OnCodexError,
+		OnReviewError: values.OnReviewError,
+
+		ParallelFirstReview: values.ParallelFirstReview,
+		ConsensusReview:     values.ConsensusReview,
+
+		CodexSplitDirs:        values.CodexSplitDirs,
+		CodexSplitConcurrency: values.CodexSplitConcurrency,
+
+		MilestoneReviews: values.MilestoneReviews,
+
+		EscalationReview:               values.EscalationReview,
+		EscalationClaudeArgs:           values.EscalationClaudeArgs,
+		EscalationCodexModel:           values.EscalationCodexModel,
+		EscalationCodexReasoningEffort: values.EscalationCodexReasoningEffort,
+
+		FinalVerification: values.FinalVerification,
+
+		StallIterations: values.StallIterations,
+		StallSimilarity: values.StallSimilarity,
+
+		BudgetExtensionFactor: values.BudgetExtensionFactor,
+		BudgetMaxExtensions:   values.BudgetMaxExtensions,
+
+		DiskMinFreeMB:   values.DiskMinFreeMB,
+		DiskMaxGrowthMB: values.DiskMaxGrowthMB,
+
+		GoModGate:         values.GoModGate,
+		GoModBlockNewDeps: values.GoModBlockNewDeps,
+
+		DepsAllow: values.DepsAllow,
+		DepsDeny:  values.DepsDeny,
+
+		GenerateGate:     values.GenerateGate,
+		GenerateCommands: values.GenerateCommands,
+
+		FormatGate: values.FormatGate,
+		Formatters: buildCommands(values.FormatterCommands),
+
+		LicenseCheck: values.LicenseCheck,
+		LicenseAllow: values.LicenseAllow,
+
+		ArtifactGate:      values.ArtifactGate,
+		ArtifactMaxSizeKB: values.ArtifactMaxSizeKB,
+		ArtifactAllow:     values.ArtifactAllow,
+
+		Analyzers: buildCommands(values.AnalyzerCommands),
+		Plugins:   buildCommands(values.PluginCommands),
+
+		SpellCheck:  values.SpellCheck,
+		SpellIgnore: values.SpellIgnore,
+
+		PostReviewSkipSeverity: values.PostReviewSkipSeverity,
+		PostReviewSkipFindings: values.PostReviewSkipFindings,
+
+		LogShipParams:      logship.Params{Destination: values.LogShipDestination},
+		TelemetryEndpoint:  values.TelemetryEndpoint,
+		ArtifactLocation:   values.ArtifactLocation,
+		ArtifactKey:        values.ArtifactKey,
 		Colors:             colors,
+		TaskInstructions:   values.TaskInstructions,
+		ReviewInstructions: values.ReviewInstructions,
+		CodexInstructions:  values.CodexInstructions,
+		Language:           values.Language,
 		TaskPrompt:         prompts.Task,
 		ReviewFirstPrompt:  prompts.ReviewFirst,
 		ReviewSecondPrompt: prompts.ReviewSecond,
@@ -273,6 +746,7 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 		FinalizePrompt:     prompts.Finalize,
 		CustomReviewPrompt: prompts.CustomReview,
 		CustomEvalPrompt:   prompts.CustomEval,
+		PlanLintPrompt:     prompts.PlanLint,
 		CustomAgents:       agents,
 		configDir:          globalDir,
 		localDir:           localDir,
@@ -286,19 +760,54 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 		c.NotifyParams.OnComplete = true
 	}
 
+	if key := os.Getenv(ArtifactKeyEnv); key != "" {
+		c.ArtifactKey = key
+	}
+	if err := resolveSecrets(c); err != nil {
+		return nil, err
+	}
+	if c.ArtifactKey != "" {
+		if c.ArtifactSealer, err = seal.New(c.ArtifactKey); err != nil {
+			return nil, fmt.Errorf("artifact_key: %w", err)
+		}
+	}
+
 	return c, nil
 }
 
-// DefaultConfigDir returns the default configuration directory path.
-// returns ~/.config/ralphex/ on all platforms.
-// if os.UserHomeDir() fails, falls back to ./.config/ralphex/ silently -
-// this allows the tool to work even in unusual environments.
-func DefaultConfigDir() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return filepath.Join(".", ".config", "ralphex")
+// secretResolver looks up a "keychain:<name>" reference; replaceable in tests.
+var secretResolver = func(value string) (string, error) {
+	return keychain.New().Resolve(value) //nolint:wrapcheck // wrapped by resolveSecrets
+}
+
+// resolveSecrets replaces keychain references in secret notification fields and the artifact key with stored values.
+// plain values are left untouched, so the keychain is only consulted when a reference is used.
+func resolveSecrets(c *Config) error {
+	fields := []struct {
+		key string
+		val *string
+	}{
+		{key: "notify_telegram_token", val: &c.NotifyParams.TelegramToken},
+		{key: "notify_slack_token", val: &c.NotifyParams.SlackToken},
+		{key: "notify_smtp_password", val: &c.NotifyParams.SMTPPassword},
+		{key: "artifact_key", val: &c.ArtifactKey},
+	}
+	for _, f := range fields {
+		if _, ok := keychain.IsRef(*f.val); !ok {
+			continue
+		}
+		resolved, err := secretResolver(*f.val)
+		if err != nil {
+			return fmt.Errorf("resolve %s: %w", f.key, err)
+		}
+		*f.val = resolved
 	}
-	return filepath.Join(home, ".config", "ralphex")
+	return nil
+}
+
+// GlobalDir returns the global config directory the configuration was loaded from.
+func (c *Config) GlobalDir() string {
+	return c.configDir
 }
 
 // LocalDir returns the local project config directory if one was detected.
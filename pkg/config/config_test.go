@@ -1,12 +1,15 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/seal"
 )
 
 // --- embedded filesystem tests ---
@@ -905,3 +908,87 @@ claude_command = local-symlinked-claude
 	// verify localDir is the symlink path
 	assert.Equal(t, symlinkLocalDir, cfg.LocalDir())
 }
+
+func TestLoad_NotifySecretsFromKeychain(t *testing.T) {
+	orig := secretResolver
+	t.Cleanup(func() { secretResolver = orig })
+
+	var resolved []string
+	secretResolver = func(value string) (string, error) {
+		resolved = append(resolved, value)
+		switch value {
+		case "keychain:telegram":
+			return "bot123:FROM-KEYCHAIN", nil
+		case "keychain:smtp":
+			return "smtp-secret", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	configDir := filepath.Join(t.TempDir(), "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+	configContent := `
+notify_telegram_token = keychain:telegram
+notify_smtp_password = keychain:smtp
+notify_slack_token = xoxb-plain
+`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(configContent), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+	assert.Equal(t, "bot123:FROM-KEYCHAIN", cfg.NotifyParams.TelegramToken)
+	assert.Equal(t, "smtp-secret", cfg.NotifyParams.SMTPPassword)
+	assert.Equal(t, "xoxb-plain", cfg.NotifyParams.SlackToken)
+	assert.ElementsMatch(t, []string{"keychain:telegram", "keychain:smtp"}, resolved, "plain values must not hit keychain")
+}
+
+func TestLoad_ArtifactKey(t *testing.T) {
+	orig := secretResolver
+	t.Cleanup(func() { secretResolver = orig })
+	secretResolver = func(value string) (string, error) {
+		if value == "keychain:artifacts" {
+			return "passphrase-from-keychain", nil
+		}
+		return "", errors.New("not found")
+	}
+	configDir := filepath.Join(t.TempDir(), "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("artifact_key = keychain:artifacts\n"), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+	assert.Equal(t, "passphrase-from-keychain", cfg.ArtifactKey)
+	require.NotNil(t, cfg.ArtifactSealer)
+	sealed := cfg.ArtifactSealer.Seal([]byte("state"))
+	assert.True(t, seal.IsSealed(sealed))
+
+	t.Run("environment takes precedence", func(t *testing.T) {
+		t.Setenv(ArtifactKeyEnv, "passphrase-from-env")
+		cfg, err := Load(configDir)
+		require.NoError(t, err)
+		assert.Equal(t, "passphrase-from-env", cfg.ArtifactKey)
+		_, err = cfg.ArtifactSealer.Open(sealed)
+		require.Error(t, err, "a different passphrase makes a different key")
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		cfg, err := Load(t.TempDir())
+		require.NoError(t, err)
+		assert.Empty(t, cfg.ArtifactKey)
+		assert.Nil(t, cfg.ArtifactSealer)
+	})
+}
+
+func TestLoad_NotifySecretsFromKeychain_Missing(t *testing.T) {
+	orig := secretResolver
+	t.Cleanup(func() { secretResolver = orig })
+	secretResolver = func(string) (string, error) { return "", errors.New("secret not found in keychain") }
+
+	configDir := filepath.Join(t.TempDir(), "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("notify_slack_token = keychain:slack\n"), 0o600))
+
+	_, err := Load(configDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "resolve notify_slack_token")
+}
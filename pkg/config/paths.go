@@ -0,0 +1,134 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appDir is the directory name used under platform config, state and cache locations.
+const appDir = "ralphex"
+
+// platformDirs holds global directories resolved for a platform.
+type platformDirs struct {
+	config string // config file, prompts, agents
+	state  string // persisted data worth keeping: telemetry, history
+	cache  string // data that can be recreated, safe to delete
+}
+
+// resolveDirs resolves global directories following platform conventions:
+//   - XDG_CONFIG_HOME, XDG_STATE_HOME and XDG_CACHE_HOME are used when set to absolute paths, on any platform
+//   - windows: %AppData%\ralphex for config, %LocalAppData%\ralphex\state and \cache
+//   - macOS: ~/.config/ralphex for config, as usual for CLI tools, ~/Library/Application Support/ralphex
+//     and ~/Library/Caches/ralphex for state and cache
+//   - others: ~/.config/ralphex, ~/.local/state/ralphex and ~/.cache/ralphex
+//
+// an existing ~/.config/ralphex is kept as config dir on every platform, so configs of earlier versions keep working.
+func resolveDirs(goos, home string, getenv func(string) string, exists func(string) bool) platformDirs {
+	xdg := func(name string) string {
+		if v := getenv(name); filepath.IsAbs(v) {
+			return filepath.Join(v, appDir)
+		}
+		return ""
+	}
+	legacy := filepath.Join(home, ".config", appDir)
+
+	var d platformDirs
+	switch goos {
+	case "windows":
+		local := getenv("LOCALAPPDATA")
+		if local == "" {
+			local = filepath.Join(home, "AppData", "Local")
+		}
+		d.state, d.cache = filepath.Join(local, appDir, "state"), filepath.Join(local, appDir, "cache")
+		d.config = legacy
+		if roaming := getenv("APPDATA"); roaming != "" && !exists(legacy) {
+			d.config = filepath.Join(roaming, appDir)
+		}
+	case "darwin":
+		d.config = legacy
+		d.state = filepath.Join(home, "Library", "Application Support", appDir)
+		d.cache = filepath.Join(home, "Library", "Caches", appDir)
+	default:
+		d.config = legacy
+		d.state = filepath.Join(home, ".local", "state", appDir)
+		d.cache = filepath.Join(home, ".cache", appDir)
+	}
+
+	if v := xdg("XDG_CONFIG_HOME"); v != "" && !exists(legacy) {
+		d.config = v
+	}
+	if v := xdg("XDG_STATE_HOME"); v != "" {
+		d.state = v
+	}
+	if v := xdg("XDG_CACHE_HOME"); v != "" {
+		d.cache = v
+	}
+	return d
+}
+
+// defaultDirs resolves global directories for the current platform.
+// if the home directory is unknown, paths are relative to the current directory,
+// allowing the tool to work even in unusual environments.
+func defaultDirs() platformDirs {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	exists := func(path string) bool {
+		info, statErr := os.Stat(path)
+		return statErr == nil && info.IsDir()
+	}
+	return resolveDirs(runtime.GOOS, home, os.Getenv, exists)
+}
+
+// DefaultConfigDir returns the default global configuration directory,
+// ~/.config/ralphex on unix-like systems, see resolveDirs for platform specifics.
+func DefaultConfigDir() string {
+	return defaultDirs().config
+}
+
+// StateDir returns the directory for persisted state, e.g. telemetry stats.
+// it is the "state" subdirectory of an explicit config dir, or the platform state location.
+func (c *Config) StateDir() string {
+	if c.dataDir != "" {
+		return filepath.Join(c.dataDir, "state")
+	}
+	return defaultDirs().state
+}
+
+// CacheDir returns the directory for data that can be recreated.
+// it is the "cache" subdirectory of an explicit config dir, or the platform cache location.
+func (c *Config) CacheDir() string {
+	if c.dataDir != "" {
+		return filepath.Join(c.dataDir, "cache")
+	}
+	return defaultDirs().cache
+}
+
+// MigrateStateFile moves a state file kept in the global config directory by earlier versions to StateDir.
+// nothing is done if the old file is missing or the new one already exists.
+func (c *Config) MigrateStateFile(name string) error {
+	if c.configDir == "" {
+		return nil
+	}
+	src, dst := filepath.Join(c.configDir, name), filepath.Join(c.StateDir(), name)
+	if src == dst {
+		return nil
+	}
+	if _, err := os.Stat(src); err != nil {
+		return nil //nolint:nilerr // no old file, nothing to migrate
+	}
+	if _, err := os.Stat(dst); err == nil || !errors.Is(err, os.ErrNotExist) {
+		return nil //nolint:nilerr // already migrated, or unknown state left alone
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return fmt.Errorf("create state dir: %w", err)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("move %s to state dir: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDirs(t *testing.T) {
+	home := filepath.FromSlash("/home/u")
+	p := func(parts ...string) string { return filepath.Join(append([]string{home}, parts...)...) }
+
+	tests := []struct {
+		name   string
+		goos   string
+		env    map[string]string
+		legacy bool // ~/.config/ralphex exists
+		want   platformDirs
+	}{
+		{name: "linux", goos: "linux",
+			want: platformDirs{config: p(".config", "ralphex"), state: p(".local", "state", "ralphex"), cache: p(".cache", "ralphex")}},
+		{name: "linux xdg", goos: "linux",
+			env: map[string]string{"XDG_CONFIG_HOME": "/xdg/config", "XDG_STATE_HOME": "/xdg/state", "XDG_CACHE_HOME": "/xdg/cache"},
+			want: platformDirs{config: filepath.Join("/xdg/config", "ralphex"), state: filepath.Join("/xdg/state", "ralphex"),
+				cache: filepath.Join("/xdg/cache", "ralphex")}},
+		{name: "relative xdg ignored", goos: "linux", env: map[string]string{"XDG_STATE_HOME": "state"},
+			want: platformDirs{config: p(".config", "ralphex"), state: p(".local", "state", "ralphex"), cache: p(".cache", "ralphex")}},
+		{name: "xdg config with legacy dir", goos: "linux", legacy: true, env: map[string]string{"XDG_CONFIG_HOME": "/xdg/config"},
+			want: platformDirs{config: p(".config", "ralphex"), state: p(".local", "state", "ralphex"), cache: p(".cache", "ralphex")}},
+		{name: "darwin", goos: "darwin",
+			want: platformDirs{config: p(".config", "ralphex"), state: p("Library", "Application Support", "ralphex"),
+				cache: p("Library", "Caches", "ralphex")}},
+		{name: "windows", goos: "windows", env: map[string]string{"APPDATA": "/appdata/roaming", "LOCALAPPDATA": "/appdata/local"},
+			want: platformDirs{config: filepath.Join("/appdata/roaming", "ralphex"), state: filepath.Join("/appdata/local", "ralphex", "state"),
+				cache: filepath.Join("/appdata/local", "ralphex", "cache")}},
+		{name: "windows with legacy dir", goos: "windows", legacy: true, env: map[string]string{"APPDATA": "/appdata/roaming"},
+			want: platformDirs{config: p(".config", "ralphex"), state: p("AppData", "Local", "ralphex", "state"),
+				cache: p("AppData", "Local", "ralphex", "cache")}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			getenv := func(k string) string { return tc.env[k] }
+			exists := func(path string) bool { return tc.legacy && path == p(".config", "ralphex") }
+			assert.Equal(t, tc.want, resolveDirs(tc.goos, home, getenv, exists))
+		})
+	}
+}
+
+func TestConfig_DataDirs(t *testing.T) {
+	t.Run("explicit config dir", func(t *testing.T) {
+		dir := t.TempDir()
+		cfg, err := Load(dir)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "state"), cfg.StateDir())
+		assert.Equal(t, filepath.Join(dir, "cache"), cfg.CacheDir())
+	})
+
+	t.Run("platform dirs", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+		t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+		cfg := &Config{}
+		assert.Equal(t, filepath.Join(home, "state", "ralphex"), cfg.StateDir())
+		assert.Equal(t, filepath.Join(home, "cache", "ralphex"), cfg.CacheDir())
+	})
+}
+
+func TestConfig_MigrateStateFile(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{configDir: dir, dataDir: dir}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stats.json"), []byte("old"), 0o600))
+
+	require.NoError(t, cfg.MigrateStateFile("stats.json"))
+	assert.NoFileExists(t, filepath.Join(dir, "stats.json"))
+	data, err := os.ReadFile(filepath.Join(cfg.StateDir(), "stats.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(data))
+
+	// an existing new file is never overwritten
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stats.json"), []byte("stale"), 0o600))
+	require.NoError(t, cfg.MigrateStateFile("stats.json"))
+	data, err = os.ReadFile(filepath.Join(cfg.StateDir(), "stats.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(data))
+	assert.FileExists(t, filepath.Join(dir, "stats.json"))
+
+	require.NoError(t, cfg.MigrateStateFile("missing.json"), "nothing to migrate")
+}
@@ -1,12 +1,15 @@
 package config
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -21,16 +24,57 @@ type Prompts struct {
 	Finalize     string
 	CustomReview string
 	CustomEval   string
+	PlanLint     string
+}
+
+// PromptsHash returns a short hash of the loaded prompt templates and custom agents,
+// identifying the prompt set a run used, so runs after a prompt change can be told apart.
+func (c *Config) PromptsHash() string {
+	h := sha256.New()
+	for _, p := range []string{c.TaskPrompt, c.ReviewFirstPrompt, c.ReviewSecondPrompt, c.CodexPrompt, c.MakePlanPrompt,
+		c.FinalizePrompt, c.CustomReviewPrompt, c.CustomEvalPrompt, c.PlanLintPrompt} {
+		_, _ = fmt.Fprintf(h, "%d:%s", len(p), p)
+	}
+	agents := slices.SortedFunc(slices.Values(c.CustomAgents), func(a, b CustomAgent) int { return strings.Compare(a.Name, b.Name) })
+	for _, a := range agents {
+		_, _ = fmt.Fprintf(h, "%d:%s%d:%s", len(a.Name), a.Name, len(a.Prompt), a.Prompt)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:6])
+}
+
+// PromptVersions returns a short hash of each loaded prompt template, by prompt file name without the
+// extension, and of each custom agent as "agent:<name>". two prompt sets differing in PromptsHash can be
+// compared with it to tell which templates changed, see history.PromptChanges.
+func (c *Config) PromptVersions() map[string]string {
+	res := map[string]string{}
+	for name, p := range map[string]string{
+		taskPromptFile: c.TaskPrompt, reviewFirstPromptFile: c.ReviewFirstPrompt, reviewSecondPromptFile: c.ReviewSecondPrompt,
+		codexPromptFile: c.CodexPrompt, makePlanPromptFile: c.MakePlanPrompt, finalizePromptFile: c.FinalizePrompt,
+		customReviewPromptFile: c.CustomReviewPrompt, customEvalPromptFile: c.CustomEvalPrompt, planLintPromptFile: c.PlanLintPrompt,
+	} {
+		res[strings.TrimSuffix(name, ".txt")] = promptVersion(p)
+	}
+	for _, a := range c.CustomAgents {
+		res["agent:"+a.Name] = promptVersion(a.Prompt)
+	}
+	return res
+}
+
+// promptVersion returns a short hash of a single prompt
+func promptVersion(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:4])
 }
 
 // promptLoader implements PromptLoader with embedded filesystem fallback.
 type promptLoader struct {
 	embedFS embed.FS
+	sources map[string]promptSource // prompt file name -> where the loaded content came from
 }
 
 // newPromptLoader creates a new promptLoader with the given embedded filesystem.
 func newPromptLoader(embedFS embed.FS) *promptLoader {
-	return &promptLoader{embedFS: embedFS}
+	return &promptLoader{embedFS: embedFS, sources: map[string]promptSource{}}
 }
 
 // Load loads all prompt files with fallback chain: local → global → embedded.
@@ -78,6 +122,11 @@ func (p *promptLoader) Load(localDir, globalDir string) (Prompts, error) {
 		return Prompts{}, fmt.Errorf("load custom_eval prompt: %w", err)
 	}
 
+	prompts.PlanLint, err = p.loadPromptWithLocalFallback(localDir, globalDir, planLintPromptFile)
+	if err != nil {
+		return Prompts{}, fmt.Errorf("load plan_lint prompt: %w", err)
+	}
+
 	return prompts, nil
 }
 
@@ -128,6 +177,7 @@ func (p *promptLoader) loadPromptFile(path string) (string, error) {
 	if strings.TrimSpace(stripComments(content)) == "" {
 		return "", nil // all-commented file, trigger fallback to embedded
 	}
+	p.recordSource(path, content)
 	return strings.TrimSpace(stripLeadingComments(content)), nil
 }
 
@@ -141,7 +191,18 @@ func (p *promptLoader) loadPromptFromEmbedFS(path string) (string, error) {
 		}
 		return "", fmt.Errorf("read embedded prompt %s: %w", path, err)
 	}
-	return strings.TrimSpace(stripLeadingComments(normalizeCRLF(string(data)))), nil
+	content := normalizeCRLF(string(data))
+	p.recordSource("embedded:"+path, content)
+	return strings.TrimSpace(stripLeadingComments(content)), nil
+}
+
+// recordSource remembers the last loaded source for a prompt file name.
+// the fallback chain stops at the first non-empty source, so the last one recorded wins.
+func (p *promptLoader) recordSource(path, raw string) {
+	if p.sources == nil {
+		p.sources = map[string]promptSource{}
+	}
+	p.sources[filepath.Base(path)] = promptSource{path: path, raw: raw}
 }
 
 // normalizeCRLF converts Windows line endings (CRLF) to Unix (LF).
@@ -776,3 +776,41 @@ func TestPromptLoader_Load_CustomEvalPrompt_LocalOverridesGlobal(t *testing.T) {
 
 	assert.Equal(t, "local custom eval", prompts.CustomEval)
 }
+
+func TestConfig_PromptsHash(t *testing.T) {
+	cfg := &Config{TaskPrompt: "task", ReviewFirstPrompt: "review",
+		CustomAgents: []CustomAgent{{Name: "b", Prompt: "two"}, {Name: "a", Prompt: "one"}}}
+	hash := cfg.PromptsHash()
+	assert.Len(t, hash, 12)
+
+	reordered := &Config{TaskPrompt: "task", ReviewFirstPrompt: "review",
+		CustomAgents: []CustomAgent{{Name: "a", Prompt: "one"}, {Name: "b", Prompt: "two"}}}
+	assert.Equal(t, hash, reordered.PromptsHash(), "agent order doesn't matter")
+
+	changed := *reordered
+	changed.TaskPrompt = "task v2"
+	assert.NotEqual(t, hash, changed.PromptsHash())
+
+	changed = *reordered
+	changed.CustomAgents = []CustomAgent{{Name: "a", Prompt: "one v2"}, {Name: "b", Prompt: "two"}}
+	assert.NotEqual(t, hash, changed.PromptsHash())
+
+	// content moving between prompts changes the hash
+	moved := &Config{TaskPrompt: "taskreview"}
+	assert.NotEqual(t, hash, moved.PromptsHash())
+}
+
+func TestConfig_PromptVersions(t *testing.T) {
+	cfg := &Config{TaskPrompt: "task", ReviewFirstPrompt: "review", CustomAgents: []CustomAgent{{Name: "a", Prompt: "one"}}}
+	versions := cfg.PromptVersions()
+	assert.Len(t, versions, 10, "nine templates and the agent")
+	assert.Len(t, versions["task"], 8)
+	assert.Contains(t, versions, "agent:a")
+	assert.Equal(t, versions["codex"], versions["finalize"], "empty prompts are the same version")
+
+	changed := *cfg
+	changed.ReviewFirstPrompt = "review v2"
+	changedVersions := changed.PromptVersions()
+	assert.NotEqual(t, versions["review_first"], changedVersions["review_first"])
+	assert.Equal(t, versions["task"], changedVersions["task"])
+}
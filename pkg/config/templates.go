@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// baseTemplateVars are expanded in every prompt by the processor.
+var baseTemplateVars = []string{"PLAN_FILE", "PROGRESS_FILE", "GOAL", "DEFAULT_BRANCH", "PLANS_DIR"}
+
+// promptTemplateSpec describes which template features a prompt file supports.
+type promptTemplateSpec struct {
+	extraVars []string // variables expanded for this prompt in addition to baseTemplateVars
+	agentRefs bool     // whether {{agent:name}} references are expanded
+}
+
+// promptTemplateSpecs maps prompt file names to supported features, mirroring the
+// prompt builders in pkg/processor/prompts.go.
+var promptTemplateSpecs = map[string]promptTemplateSpec{
+	taskPromptFile:         {agentRefs: true},
+	reviewFirstPromptFile:  {agentRefs: true},
+	reviewSecondPromptFile: {agentRefs: true},
+	codexPromptFile:        {extraVars: []string{"CODEX_OUTPUT"}, agentRefs: true},
+	makePlanPromptFile:     {extraVars: []string{"PLAN_DESCRIPTION"}},
+	finalizePromptFile:     {agentRefs: true},
+	customReviewPromptFile: {extraVars: []string{"DIFF_INSTRUCTION"}, agentRefs: true},
+	customEvalPromptFile:   {extraVars: []string{"CUSTOM_OUTPUT"}, agentRefs: true},
+	planLintPromptFile:     {extraVars: []string{"LINT_ISSUES"}},
+}
+
+// templateRefPattern matches things that look like ralphex template references: {{UPPER_CASE}} or {{agent:...}}.
+// other {{...}} content (e.g. Go or Helm template examples inside a prompt) is left alone.
+var templateRefPattern = regexp.MustCompile(`\{\{\s*(agent:[^}\s]*|[A-Z][A-Z0-9_]*)\s*\}\}`)
+
+// agentNamePattern matches valid agent names, same as the processor's agent reference syntax.
+var agentNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// TemplateIssue describes a problem found in a prompt template, with its position in the source file.
+type TemplateIssue struct {
+	File string // source file path, or embedded path for defaults
+	Line int    // 1-based line number
+	Col  int    // 1-based column (byte offset)
+	Msg  string
+}
+
+// String formats the issue as file:line:col: message.
+func (t TemplateIssue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", t.File, t.Line, t.Col, t.Msg)
+}
+
+// checkTemplate validates template references in content against spec and known agents.
+// lineOffset is the number of source lines stripped before content (leading comments),
+// so reported positions point into the original file.
+func checkTemplate(file, content string, lineOffset int, spec promptTemplateSpec, agents map[string]bool) []TemplateIssue {
+	allowed := make(map[string]bool, len(baseTemplateVars)+len(spec.extraVars))
+	for _, v := range baseTemplateVars {
+		allowed[v] = true
+	}
+	for _, v := range spec.extraVars {
+		allowed[v] = true
+	}
+
+	var issues []TemplateIssue
+	for i, line := range strings.Split(content, "\n") {
+		for _, m := range templateRefPattern.FindAllStringSubmatchIndex(line, -1) {
+			ref := line[m[2]:m[3]]
+			var msg string
+			switch {
+			case strings.HasPrefix(ref, "agent:"):
+				name := strings.TrimPrefix(ref, "agent:")
+				switch {
+				case !spec.agentRefs:
+					msg = fmt.Sprintf("agent references are not expanded in this prompt: {{%s}}", ref)
+				case !agentNamePattern.MatchString(name):
+					msg = fmt.Sprintf("invalid agent name %q in {{%s}}", name, ref)
+				case !agents[name]:
+					msg = fmt.Sprintf("unknown agent %q", name)
+				}
+			case !allowed[ref]:
+				msg = fmt.Sprintf("unknown variable {{%s}}, available: %s", ref, strings.Join(sortedVars(allowed), ", "))
+			}
+			if msg != "" {
+				issues = append(issues, TemplateIssue{File: file, Line: i + 1 + lineOffset, Col: m[0] + 1, Msg: msg})
+			}
+		}
+	}
+	return issues
+}
+
+// validatePromptTemplates checks all loaded prompts once at startup. sources maps prompt file names
+// to where each prompt was loaded from; agents are the loaded custom agents.
+func validatePromptTemplates(prompts Prompts, sources map[string]promptSource, agents []CustomAgent) []TemplateIssue {
+	agentNames := make(map[string]bool, len(agents))
+	for _, a := range agents {
+		agentNames[a.Name] = true
+	}
+
+	byFile := map[string]string{
+		taskPromptFile:         prompts.Task,
+		reviewFirstPromptFile:  prompts.ReviewFirst,
+		reviewSecondPromptFile: prompts.ReviewSecond,
+		codexPromptFile:        prompts.Codex,
+		makePlanPromptFile:     prompts.MakePlan,
+		finalizePromptFile:     prompts.Finalize,
+		customReviewPromptFile: prompts.CustomReview,
+		customEvalPromptFile:   prompts.CustomEval,
+		planLintPromptFile:     prompts.PlanLint,
+	}
+
+	var issues []TemplateIssue
+	for _, name := range slices.Sorted(maps.Keys(byFile)) {
+		content := byFile[name]
+		src, ok := sources[name]
+		if !ok {
+			src = promptSource{path: name}
+		}
+		issues = append(issues, checkTemplate(src.path, content, src.lineOffset(content), promptTemplateSpecs[name], agentNames)...)
+	}
+	return issues
+}
+
+// promptSource records where a prompt was loaded from, for positions in validation messages.
+type promptSource struct {
+	path string // file path, or embedded path for defaults
+	raw  string // file content before comment stripping
+}
+
+// lineOffset returns how many lines of the raw source precede content.
+func (s promptSource) lineOffset(content string) int {
+	if s.raw == "" || content == "" {
+		return 0
+	}
+	idx := strings.Index(s.raw, content)
+	if idx < 0 {
+		return 0
+	}
+	return strings.Count(s.raw[:idx], "\n")
+}
+
+func sortedVars(m map[string]bool) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, "{{"+k+"}}")
+	}
+	slices.Sort(res)
+	return res
+}
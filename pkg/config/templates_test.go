@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTemplate(t *testing.T) {
+	agents := map[string]bool{"quality": true}
+	tests := []struct {
+		name    string
+		content string
+		spec    promptTemplateSpec
+		want    []string
+	}{
+		{name: "known variables", content: "plan {{PLAN_FILE}} goal {{GOAL}}\nbranch {{DEFAULT_BRANCH}}", want: nil},
+		{name: "unknown variable", content: "line one\nplan {{PLAN_FIEL}}",
+			want: []string{"x.txt:2:6: unknown variable {{PLAN_FIEL}}, available: {{DEFAULT_BRANCH}}, {{GOAL}}, {{PLANS_DIR}}, {{PLAN_FILE}}, {{PROGRESS_FILE}}"}},
+		{name: "variable from another prompt", content: "{{CODEX_OUTPUT}}", spec: promptTemplateSpec{extraVars: []string{"PLAN_DESCRIPTION"}},
+			want: []string{"x.txt:1:1: unknown variable {{CODEX_OUTPUT}}, available: {{DEFAULT_BRANCH}}, {{GOAL}}, {{PLANS_DIR}}, {{PLAN_DESCRIPTION}}, {{PLAN_FILE}}, {{PROGRESS_FILE}}"}},
+		{name: "extra variable allowed", content: "{{CODEX_OUTPUT}}", spec: promptTemplateSpec{extraVars: []string{"CODEX_OUTPUT"}}, want: nil},
+		{name: "known agent", content: "{{agent:quality}}", spec: promptTemplateSpec{agentRefs: true}, want: nil},
+		{name: "unknown agent", content: "  {{agent:qualty}}", spec: promptTemplateSpec{agentRefs: true},
+			want: []string{`x.txt:1:3: unknown agent "qualty"`}},
+		{name: "invalid agent name", content: "{{agent:a.b}}", spec: promptTemplateSpec{agentRefs: true},
+			want: []string{`x.txt:1:1: invalid agent name "a.b" in {{agent:a.b}}`}},
+		{name: "agent refs not supported", content: "{{agent:quality}}",
+			want: []string{"x.txt:1:1: agent references are not expanded in this prompt: {{agent:quality}}"}},
+		{name: "non-variable braces ignored", content: "example: {{.Name}} and {{ range .Items }}", want: nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := checkTemplate("x.txt", tc.content, 0, tc.spec, agents)
+			var got []string
+			for _, issue := range issues {
+				got = append(got, issue.String())
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestCheckTemplate_LineOffset(t *testing.T) {
+	issues := checkTemplate("x.txt", "first\n{{NOPE}}", 3, promptTemplateSpec{}, nil)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 5, issues[0].Line)
+}
+
+func TestPromptSource_lineOffset(t *testing.T) {
+	raw := "# comment one\n# comment two\n\nbody line\n{{NOPE}}\n"
+	src := promptSource{path: "task.txt", raw: raw}
+	assert.Equal(t, 3, src.lineOffset("body line\n{{NOPE}}"))
+	assert.Equal(t, 0, src.lineOffset("not in raw"))
+	assert.Equal(t, 0, promptSource{}.lineOffset("body"))
+}
+
+func TestValidatePromptTemplates_EmbeddedDefaultsAreClean(t *testing.T) {
+	pl := newPromptLoader(defaultsFS)
+	prompts, err := pl.Load("", t.TempDir())
+	require.NoError(t, err)
+	agents, err := newAgentLoader(defaultsFS).Load("", filepath.Join(t.TempDir(), "agents")) // missing dir loads embedded agents
+	require.NoError(t, err)
+
+	issues := validatePromptTemplates(prompts, pl.sources, agents)
+	assert.Empty(t, issues, "embedded prompts must only use supported variables and agents")
+}
+
+func TestValidatePromptTemplates_UserPromptPositions(t *testing.T) {
+	globalDir := t.TempDir()
+	content := "# custom task prompt\n# uses plan file\n\nImplement {{PLAN_FILE}}\nthen {{PROGRES_FILE}}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "task.txt"), []byte(content), 0o600))
+
+	pl := newPromptLoader(defaultsFS)
+	prompts, err := pl.Load("", globalDir)
+	require.NoError(t, err)
+
+	agents, err := newAgentLoader(defaultsFS).Load("", filepath.Join(globalDir, "agents"))
+	require.NoError(t, err)
+
+	issues := validatePromptTemplates(prompts, pl.sources, agents)
+	require.Len(t, issues, 1)
+	assert.Equal(t, filepath.Join(globalDir, "task.txt"), issues[0].File)
+	assert.Equal(t, 5, issues[0].Line, "line must point into the original file, including stripped comments")
+	assert.Equal(t, 6, issues[0].Col)
+	assert.Contains(t, issues[0].Msg, "unknown variable {{PROGRES_FILE}}")
+}
@@ -4,9 +4,14 @@ import (
 	"embed"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"unicode"
 
 	"gopkg.in/ini.v1"
+
+	"github.com/umputun/ralphex/pkg/verify"
 )
 
 // Values holds scalar configuration values.
@@ -16,6 +21,7 @@ import (
 type Values struct {
 	ClaudeCommand        string
 	ClaudeArgs           string
+	PlanLintArgs         string   // primary command args for the plan lint pass, empty uses claude_args
 	ClaudeErrorPatterns  []string // patterns to detect in claude output (e.g., rate limit messages)
 	CodexEnabled         bool
 	CodexEnabledSet      bool // tracks if codex_enabled was explicitly set
@@ -28,15 +34,172 @@ type Values struct {
 	CodexErrorPatterns   []string // patterns to detect in codex output (e.g., rate limit messages)
 	ExternalReviewTool   string   // "codex", "custom", or "none"
 	CustomReviewScript   string   // path to custom review script (when ExternalReviewTool = "custom")
+	RepeatUntilClean     int      // extra external review + review rounds while external review keeps finding issues
+	RepeatUntilCleanSet  bool     // tracks if repeat_until_clean was explicitly set
 	IterationDelayMs     int
 	IterationDelayMsSet  bool // tracks if iteration_delay_ms was explicitly set
 	TaskRetryCount       int
 	TaskRetryCountSet    bool // tracks if task_retry_count was explicitly set
+	TaskMaxIterations    int
+	TaskMaxIterationsSet bool   // tracks if task_max_iterations was explicitly set
+	RollbackOnFailure    string // off, run or iteration
+	ContinueSession      bool   // task iterations continue the agent session of the previous one
+	ContinueSessionSet   bool   // tracks if continue_session was explicitly set
+	CompletedPlan        string // run, review or exit
+
+	// executor retry on failed claude, codex and custom review calls
+	ExecutorRetryCount       int
+	ExecutorRetryCountSet    bool // tracks if executor_retry_count was explicitly set
+	ExecutorRetryDelayMs     int
+	ExecutorRetryDelayMsSet  bool // tracks if executor_retry_delay_ms was explicitly set
+	ExecutorRetryMaxDelayMs  int
+	ExecutorRetryMaxDelaySet bool // tracks if executor_retry_max_delay_ms was explicitly set
+	ExecutorRetryJitter      float64
+	ExecutorRetryJitterSet   bool // tracks if executor_retry_jitter was explicitly set
+	StallIterations          int
+	StallIterationsSet       bool // tracks if stall_iterations was explicitly set
+	StallSimilarity          float64
+	StallSimilaritySet       bool // tracks if stall_similarity was explicitly set
+	BudgetExtensionFactor    float64
+	BudgetExtensionFactorSet bool // tracks if budget_extension_factor was explicitly set
+	BudgetMaxExtensions      int
+	BudgetMaxExtensionsSet   bool // tracks if budget_max_extensions was explicitly set
+
+	DiskMinFreeMB      int
+	DiskMinFreeMBSet   bool // tracks if disk_min_free_mb was explicitly set
+	DiskMaxGrowthMB    int
+	DiskMaxGrowthMBSet bool // tracks if disk_max_growth_mb was explicitly set
+
+	GoModGate            string // off, feedback or fail
+	GoModBlockNewDeps    bool
+	GoModBlockNewDepsSet bool // tracks if gomod_block_new_deps was explicitly set
+
+	DepsAllow []string // module patterns the agents may add, any if empty
+	DepsDeny  []string // module patterns the agents may not add
+
+	GenerateGate     string   // off, feedback or fail
+	GenerateCommands []string // code generator commands
+
+	FormatGate        string            // off, fix or feedback
+	FormatterCommands map[string]string // formatter commands by file extension from formatter_<ext> keys
+
+	LicenseCheck string   // off, warn or fail
+	LicenseAllow []string // SPDX identifiers of licenses allowed for added modules
+
+	ArtifactGate         string   // off, warn, feedback or fail
+	ArtifactMaxSizeKB    int      // files above are flagged, 0 flags binary files only
+	ArtifactMaxSizeKBSet bool     // tracks if artifact_max_size_kb was explicitly set
+	ArtifactAllow        []string // path.Match patterns of files never flagged
+
+	MaxOutputBytes       int
+	MaxOutputBytesSet    bool // tracks if max_output_bytes was explicitly set
 	FinalizeEnabled      bool
 	FinalizeEnabledSet   bool // tracks if finalize_enabled was explicitly set
 	PlansDir             string
-	DefaultBranch        string   // override auto-detected default branch
-	WatchDirs            []string // directories to watch for progress files
+	ArchivePlans         bool   // move completed plans to ArchiveDir with a timestamp
+	ArchivePlansSet      bool   // tracks if archive_plans was explicitly set
+	ArchiveDir           string // archive directory for completed plans
+	DefaultBranch        string // override auto-detected default branch
+	GitCommand           string // git binary used for repository operations
+	PartialCloneFetch    bool   // fetch blobs missing from a partial clone before reviews
+	PartialCloneFetchSet bool   // tracks if partial_clone_fetch was explicitly set
+
+	// run and phase time limits, 0 = no limit
+	MaxRunDurationMs        int
+	MaxRunDurationMsSet     bool // tracks if max_run_duration_ms was explicitly set
+	RunWindow               RunWindow
+	RunWindowSet            bool // tracks if run_window was explicitly set, an empty value allows any time
+	TaskPhaseTimeoutMs      int
+	TaskPhaseTimeoutMsSet   bool // tracks if task_phase_timeout_ms was explicitly set
+	ReviewPhaseTimeoutMs    int
+	ReviewPhaseTimeoutMsSet bool // tracks if review_phase_timeout_ms was explicitly set
+	CodexPhaseTimeoutMs     int
+	CodexPhaseTimeoutMsSet  bool // tracks if codex_phase_timeout_ms was explicitly set
+
+	// verification gate settings
+	VerifyEnabled          bool
+	VerifyEnabledSet       bool     // tracks if verify_enabled was explicitly set
+	VerifyProfile          string   // built-in profile name or "auto"
+	VerifyTargets          []string // make/task targets for target runner profiles
+	VerifyCommands         []string // explicit commands, override the profile
+	VerifyTimeoutMs        int
+	VerifyTimeoutMsSet     bool     // tracks if verify_timeout_ms was explicitly set
+	VerifyImage            string   // container image for verification commands
+	VerifyContainerCommand string   // container CLI (docker, podman)
+	VerifyServices         string   // compose file with services started for the task phase
+	VerifyGoVersions       []string // go versions to verify with, matrix disabled if empty
+	VerifyShell            []string // shells for verification commands, "shell" or "os:shell" entries
+	WatchDirs              []string // directories to watch for progress files
+	ShowDiff               string   // print changes per "iteration" or "phase", "none" disables
+	ShowDiffMaxLines       int      // lines of a printed diff, longer diffs are cut
+	ShowDiffMaxLinesSet    bool     // tracks if show_diff_max_lines was explicitly set
+	AnnotatePlan           bool     // append run outcomes to the plan file
+	AnnotatePlanSet        bool     // tracks if annotate_plan was explicitly set
+	IterationCost          float64  // average cost of an iteration, used by plan estimate
+	IterationCostSet       bool     // tracks if iteration_cost was explicitly set
+	WarnPromptChange       bool     // warn before the first run with changed prompt templates
+	WarnPromptChangeSet    bool     // tracks if warn_prompt_change was explicitly set
+	AccessibleOutput       bool     // plain progress output with periodic status lines for screen readers
+	AccessibleOutputSet    bool     // tracks if accessible_output was explicitly set
+	NeedsInputTimeoutMs    int      // wait for an answer to a NEEDS_INPUT question without a terminal
+	NeedsInputTimeoutMsSet bool     // tracks if needs_input_timeout_ms was explicitly set
+
+	RequireFindingResolution    bool // fail the external review when a finding is neither fixed nor explained
+	RequireFindingResolutionSet bool // tracks if require_finding_resolution was explicitly set
+	VerifyReviewDone            bool // reject review done signals contradicted by the diff or the verification gate
+	VerifyReviewDoneSet         bool // tracks if verify_review_done was explicitly set
+
+	ParallelFirstReview    bool // run the claude first review and the external review at the same time
+	ParallelFirstReviewSet bool // tracks if parallel_first_review was explicitly set
+	ConsensusReview        bool // fix only findings both the claude and the external first review report
+	ConsensusReviewSet     bool // tracks if consensus_review was explicitly set
+	MilestoneReviews       bool // run the review pipeline after each "## " section of the plan
+	MilestoneReviewsSet    bool // tracks if milestone_reviews was explicitly set
+
+	CodexSplitDirs           int  // split the first codex review by directory from this many changed directories, 0 disables
+	CodexSplitDirsSet        bool // tracks if codex_split_dirs was explicitly set
+	CodexSplitConcurrency    int  // codex runs of a split review at the same time
+	CodexSplitConcurrencySet bool // tracks if codex_split_concurrency was explicitly set
+
+	EscalationReview               bool // run an escalation review when a verification pass still reports findings
+	EscalationReviewSet            bool // tracks if escalation_review was explicitly set
+	EscalationClaudeArgs           string
+	EscalationCodexModel           string
+	EscalationCodexReasoningEffort string
+
+	FinalVerification    bool // run a report-only external review pass before finalize
+	FinalVerificationSet bool // tracks if final_verification was explicitly set
+
+	PostReviewSkipSeverity    string // skip the post-codex review when all external review findings are below it
+	PostReviewSkipFindings    int    // skip the post-codex review when the external review reported fewer findings
+	PostReviewSkipFindingsSet bool   // tracks if post_review_skip_findings was explicitly set
+
+	OnCodexError  string // failure policy of external review phases: "abort", "skip" or "retry"
+	OnReviewError string // failure policy of claude review phases: "abort", "skip" or "retry"
+
+	// custom pipeline replacing the full mode, empty for the default
+	Phases []PhaseSpec
+
+	// project instructions appended to the prompts of a phase
+	TaskInstructions   string
+	ReviewInstructions string
+	CodexInstructions  string
+	Language           string // language of agent output, see Config.Language
+
+	// shell commands run before and after phases
+	HookCommands     map[string]string // commands by hook point, see HookPoints
+	HooksRequired    []string          // hook points whose failure aborts the run
+	HooksRequiredSet bool              // tracks if hooks_required was explicitly set (allows empty to reset)
+
+	// third-party analyzers run next to the external review, commands by name from analyzer_<name> keys
+	AnalyzerCommands map[string]string
+	// out-of-process plugins, commands by name from plugin_<name> keys
+	PluginCommands map[string]string
+
+	// spelling check of changed comments, docs and strings
+	SpellCheck    bool     // run the spelling check next to the external review
+	SpellCheckSet bool     // tracks if spell_check was explicitly set
+	SpellIgnore   []string // words never reported
 
 	// notification settings
 	NotifyChannels        []string // channels to use: telegram, email, webhook, slack, custom
@@ -64,6 +227,20 @@ type Values struct {
 	NotifyWebhookURLs     []string // comma-separated in config
 	NotifyWebhookURLsSet  bool     // tracks if notify_webhook_urls was explicitly set (allows empty to disable)
 	NotifyCustomScript    string   // path to custom notification script (tilde-expanded)
+
+	// artifacts publishing
+	ArtifactsDestination string // s3://bucket/prefix or gs://bucket/prefix
+	ArtifactsCommand     string // custom upload command, used instead of ArtifactsDestination
+
+	// run event shipping to a log collector
+	LogShipDestination string // syslog://, syslog+tcp://, loki://, loki+https:// or http(s):// URL
+
+	// telemetry, enabled separately with "ralphex telemetry on"
+	TelemetryEndpoint string // URL anonymous aggregate stats are reported to
+
+	// run artifacts (progress logs, transcripts, checkpoint) location: "repo" or "user"
+	ArtifactLocation string
+	ArtifactKey      string // passphrase encrypting checkpoint, transcripts and run history, or a keychain reference
 }
 
 // valuesLoader implements ValuesLoader with embedded filesystem fallback.
@@ -162,6 +339,9 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 	if key, err := section.GetKey("claude_args"); err == nil {
 		values.ClaudeArgs = key.String()
 	}
+	if key, err := section.GetKey("plan_lint_args"); err == nil {
+		values.PlanLintArgs = strings.TrimSpace(key.String())
+	}
 
 	// codex settings
 	if key, err := section.GetKey("codex_enabled"); err == nil {
@@ -203,6 +383,59 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 	if key, err := section.GetKey("custom_review_script"); err == nil {
 		values.CustomReviewScript = expandTilde(key.String())
 	}
+	if key, err := section.GetKey("repeat_until_clean"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid repeat_until_clean: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid repeat_until_clean: must be non-negative, got %d", val)
+		}
+		values.RepeatUntilClean = val
+		values.RepeatUntilCleanSet = true
+	}
+	if key, err := section.GetKey("phases"); err == nil {
+		phases, phasesErr := parsePhases(key.String())
+		if phasesErr != nil {
+			return Values{}, fmt.Errorf("invalid phases: %w", phasesErr)
+		}
+		values.Phases = phases
+	}
+
+	if key, err := section.GetKey("run_window"); err == nil {
+		window, windowErr := ParseRunWindow(key.String())
+		if windowErr != nil {
+			return Values{}, fmt.Errorf("invalid run_window: %w", windowErr)
+		}
+		values.RunWindow = window
+		values.RunWindowSet = true
+	}
+
+	// run and phase time limits
+	timeLimits := []struct {
+		key string
+		val *int
+		set *bool
+	}{
+		{"max_run_duration_ms", &values.MaxRunDurationMs, &values.MaxRunDurationMsSet},
+		{"task_phase_timeout_ms", &values.TaskPhaseTimeoutMs, &values.TaskPhaseTimeoutMsSet},
+		{"review_phase_timeout_ms", &values.ReviewPhaseTimeoutMs, &values.ReviewPhaseTimeoutMsSet},
+		{"codex_phase_timeout_ms", &values.CodexPhaseTimeoutMs, &values.CodexPhaseTimeoutMsSet},
+	}
+	for _, pt := range timeLimits {
+		key, err := section.GetKey(pt.key)
+		if err != nil {
+			continue
+		}
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid %s: %w", pt.key, intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid %s: must be non-negative, got %d", pt.key, val)
+		}
+		*pt.val, *pt.set = val, true
+	}
 
 	// timing settings
 	if key, err := section.GetKey("iteration_delay_ms"); err == nil {
@@ -227,6 +460,55 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		values.TaskRetryCount = val
 		values.TaskRetryCountSet = true
 	}
+	if key, err := section.GetKey("task_max_iterations"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid task_max_iterations: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid task_max_iterations: must be non-negative, got %d", val)
+		}
+		values.TaskMaxIterations = val
+		values.TaskMaxIterationsSet = true
+	}
+	if key, err := section.GetKey("rollback_on_failure"); err == nil {
+		val := strings.ToLower(strings.TrimSpace(key.String()))
+		switch val {
+		case "", RollbackOff, RollbackRun, RollbackIteration:
+			values.RollbackOnFailure = val
+		default:
+			return Values{}, fmt.Errorf("invalid rollback_on_failure: %q, use %s, %s or %s", val, RollbackOff, RollbackRun, RollbackIteration)
+		}
+	}
+	if key, err := section.GetKey("continue_session"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid continue_session: %w", boolErr)
+		}
+		values.ContinueSession = val
+		values.ContinueSessionSet = true
+	}
+	if key, err := section.GetKey("completed_plan"); err == nil {
+		val := strings.ToLower(strings.TrimSpace(key.String()))
+		switch val {
+		case "", CompletedPlanRun, CompletedPlanReview, CompletedPlanExit:
+			values.CompletedPlan = val
+		default:
+			return Values{}, fmt.Errorf("invalid completed_plan: %q, use %s, %s or %s", val,
+				CompletedPlanRun, CompletedPlanReview, CompletedPlanExit)
+		}
+	}
+	if key, err := section.GetKey("max_output_bytes"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid max_output_bytes: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid max_output_bytes: must be non-negative, got %d", val)
+		}
+		values.MaxOutputBytes = val
+		values.MaxOutputBytesSet = true
+	}
 
 	// finalize settings
 	if key, err := section.GetKey("finalize_enabled"); err == nil {
@@ -242,9 +524,94 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 	if key, err := section.GetKey("plans_dir"); err == nil {
 		values.PlansDir = key.String()
 	}
+	if key, err := section.GetKey("archive_plans"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid archive_plans: %w", boolErr)
+		}
+		values.ArchivePlans = val
+		values.ArchivePlansSet = true
+	}
+	if key, err := section.GetKey("archive_dir"); err == nil {
+		values.ArchiveDir = strings.TrimSpace(key.String())
+	}
 	if key, err := section.GetKey("default_branch"); err == nil {
 		values.DefaultBranch = strings.TrimSpace(key.String())
 	}
+	if key, err := section.GetKey("git_command"); err == nil {
+		values.GitCommand = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("partial_clone_fetch"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid partial_clone_fetch: %w", boolErr)
+		}
+		values.PartialCloneFetch = val
+		values.PartialCloneFetchSet = true
+	}
+
+	// verification gate
+	if key, err := section.GetKey("verify_enabled"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid verify_enabled: %w", boolErr)
+		}
+		values.VerifyEnabled = val
+		values.VerifyEnabledSet = true
+	}
+	if key, err := section.GetKey("verify_profile"); err == nil {
+		val := strings.TrimSpace(key.String())
+		if _, ok := verify.Lookup(val); val != "" && val != verify.ProfileAuto && !ok {
+			return Values{}, fmt.Errorf("invalid verify_profile: unknown profile %q", val)
+		}
+		values.VerifyProfile = val
+	}
+	if key, err := section.GetKey("verify_image"); err == nil {
+		values.VerifyImage = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("verify_container_command"); err == nil {
+		values.VerifyContainerCommand = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("verify_services"); err == nil {
+		values.VerifyServices = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("verify_go_versions"); err == nil {
+		for v := range strings.FieldsFuncSeq(key.String(), func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			if !verify.ValidGoVersion(v) {
+				return Values{}, fmt.Errorf("invalid verify_go_versions: %q is not a go version like 1.23 or 1.23.4", v)
+			}
+			values.VerifyGoVersions = append(values.VerifyGoVersions, v)
+		}
+	}
+	if key, err := section.GetKey("verify_shell"); err == nil {
+		for v := range strings.FieldsFuncSeq(key.String(), func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			if _, _, shellErr := verify.ParseShell(v); shellErr != nil {
+				return Values{}, fmt.Errorf("invalid verify_shell: %w", shellErr)
+			}
+			values.VerifyShell = append(values.VerifyShell, v)
+		}
+	}
+	if key, err := section.GetKey("verify_targets"); err == nil {
+		values.VerifyTargets = strings.FieldsFunc(key.String(), func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	}
+	if key, err := section.GetKey("verify_commands"); err == nil {
+		for c := range strings.SplitSeq(key.String(), ",") {
+			if t := strings.TrimSpace(c); t != "" {
+				values.VerifyCommands = append(values.VerifyCommands, t)
+			}
+		}
+	}
+	if key, err := section.GetKey("verify_timeout_ms"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid verify_timeout_ms: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid verify_timeout_ms: must be non-negative, got %d", val)
+		}
+		values.VerifyTimeoutMs = val
+		values.VerifyTimeoutMsSet = true
+	}
 
 	// watch directories (comma-separated)
 	if key, err := section.GetKey("watch_dirs"); err == nil {
@@ -258,10 +625,253 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		}
 	}
 
+	// diff viewer in progress output
+	if key, err := section.GetKey("show_diff"); err == nil {
+		val := strings.ToLower(strings.TrimSpace(key.String()))
+		switch val {
+		case "", ShowDiffNone, ShowDiffIteration, ShowDiffPhase:
+			values.ShowDiff = val
+		default:
+			return Values{}, fmt.Errorf("invalid show_diff: %q, use %s, %s or %s", val, ShowDiffNone, ShowDiffIteration, ShowDiffPhase)
+		}
+	}
+	if key, err := section.GetKey("parallel_first_review"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid parallel_first_review: %w", boolErr)
+		}
+		values.ParallelFirstReview = val
+		values.ParallelFirstReviewSet = true
+	}
+	if key, err := section.GetKey("consensus_review"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid consensus_review: %w", boolErr)
+		}
+		values.ConsensusReview = val
+		values.ConsensusReviewSet = true
+	}
+	if key, err := section.GetKey("codex_split_dirs"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid codex_split_dirs: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid codex_split_dirs: must be non-negative, got %d", val)
+		}
+		values.CodexSplitDirs = val
+		values.CodexSplitDirsSet = true
+	}
+	if key, err := section.GetKey("codex_split_concurrency"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid codex_split_concurrency: %w", intErr)
+		}
+		if val < 1 {
+			return Values{}, fmt.Errorf("invalid codex_split_concurrency: must be at least 1, got %d", val)
+		}
+		values.CodexSplitConcurrency = val
+		values.CodexSplitConcurrencySet = true
+	}
+	if key, err := section.GetKey("milestone_reviews"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid milestone_reviews: %w", boolErr)
+		}
+		values.MilestoneReviews = val
+		values.MilestoneReviewsSet = true
+	}
+
+	// post-codex review skip
+	if key, err := section.GetKey("post_review_skip_severity"); err == nil {
+		val := strings.ToLower(strings.TrimSpace(key.String()))
+		if val != "" && val != "none" && !slices.Contains(Severities, val) {
+			return Values{}, fmt.Errorf("invalid post_review_skip_severity: %q, use none or %s", val, strings.Join(Severities, ", "))
+		}
+		values.PostReviewSkipSeverity = val
+	}
+	if key, err := section.GetKey("post_review_skip_findings"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid post_review_skip_findings: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid post_review_skip_findings: must be non-negative, got %d", val)
+		}
+		values.PostReviewSkipFindings = val
+		values.PostReviewSkipFindingsSet = true
+	}
+
+	// phase failure policies
+	for _, p := range []struct {
+		key string
+		val *string
+	}{{"on_codex_error", &values.OnCodexError}, {"on_review_error", &values.OnReviewError}} {
+		key, err := section.GetKey(p.key)
+		if err != nil {
+			continue
+		}
+		val := strings.ToLower(strings.TrimSpace(key.String()))
+		switch val {
+		case "", OnErrorAbort, OnErrorSkip, OnErrorRetry:
+			*p.val = val
+		default:
+			return Values{}, fmt.Errorf("invalid %s: %q, use %s, %s or %s", p.key, val, OnErrorAbort, OnErrorSkip, OnErrorRetry)
+		}
+	}
+	if key, err := section.GetKey("show_diff_max_lines"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid show_diff_max_lines: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid show_diff_max_lines: must be non-negative, got %d", val)
+		}
+		values.ShowDiffMaxLines = val
+		values.ShowDiffMaxLinesSet = true
+	}
+
+	// plan annotation
+	if key, err := section.GetKey("annotate_plan"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid annotate_plan: %w", boolErr)
+		}
+		values.AnnotatePlan = val
+		values.AnnotatePlanSet = true
+	}
+
+	// plan estimate
+	if key, err := section.GetKey("iteration_cost"); err == nil {
+		val, floatErr := key.Float64()
+		if floatErr != nil {
+			return Values{}, fmt.Errorf("invalid iteration_cost: %w", floatErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid iteration_cost: must be non-negative, got %g", val)
+		}
+		values.IterationCost = val
+		values.IterationCostSet = true
+	}
+
+	if key, err := section.GetKey("require_finding_resolution"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid require_finding_resolution: %w", boolErr)
+		}
+		values.RequireFindingResolution = val
+		values.RequireFindingResolutionSet = true
+	}
+	if key, err := section.GetKey("verify_review_done"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid verify_review_done: %w", boolErr)
+		}
+		values.VerifyReviewDone = val
+		values.VerifyReviewDoneSet = true
+	}
+
+	// run history
+	if key, err := section.GetKey("warn_prompt_change"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid warn_prompt_change: %w", boolErr)
+		}
+		values.WarnPromptChange = val
+		values.WarnPromptChangeSet = true
+	}
+
+	// progress output
+	if key, err := section.GetKey("accessible_output"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid accessible_output: %w", boolErr)
+		}
+		values.AccessibleOutput = val
+		values.AccessibleOutputSet = true
+	}
+
+	// agent questions
+	if key, err := section.GetKey("needs_input_timeout_ms"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid needs_input_timeout_ms: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid needs_input_timeout_ms: must be non-negative, got %d", val)
+		}
+		values.NeedsInputTimeoutMs = val
+		values.NeedsInputTimeoutMsSet = true
+	}
+
+	// artifacts publishing, the command may be a script path (tilde-expanded)
+	if key, err := section.GetKey("artifacts_destination"); err == nil {
+		values.ArtifactsDestination = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("artifacts_command"); err == nil {
+		values.ArtifactsCommand = expandTilde(strings.TrimSpace(key.String()))
+	}
+	if key, err := section.GetKey("log_ship_destination"); err == nil {
+		values.LogShipDestination = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("telemetry_endpoint"); err == nil {
+		values.TelemetryEndpoint = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("artifact_location"); err == nil {
+		v := strings.ToLower(strings.TrimSpace(key.String()))
+		if v != "" && v != ArtifactsRepo && v != ArtifactsUser {
+			return Values{}, fmt.Errorf("invalid artifact_location: %q, use %s or %s", v, ArtifactsRepo, ArtifactsUser)
+		}
+		values.ArtifactLocation = v
+	}
+	if key, err := section.GetKey("artifact_key"); err == nil {
+		values.ArtifactKey = strings.TrimSpace(key.String())
+	}
+
 	// notification settings
 	if err := parseNotifyValues(section, &values); err != nil {
 		return Values{}, err
 	}
+	if err := parseHookValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	if err := parseRetryValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	if err := parseStallValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	if err := parseBudgetValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	if err := parseDiskValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	if err := parseGoModValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	if err := parseGenerateValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	if err := parseFormatValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	if err := parseEscalationValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	if err := parseLicenseValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	if err := parseArtifactValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	if err := parseAnalyzerValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	if err := parseSpellValues(section, &values); err != nil {
+		return Values{}, err
+	}
+	parseInstructionValues(section, &values)
 
 	// error patterns (comma-separated)
 	if key, err := section.GetKey("claude_error_patterns"); err == nil {
@@ -290,131 +900,610 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 
 // mergeFrom merges non-empty values from src into dst.
 func (dst *Values) mergeFrom(src *Values) {
-	if src.ClaudeCommand != "" {
-		dst.ClaudeCommand = src.ClaudeCommand
+	dst.mergeAgentFrom(src)
+	dst.mergeRunFrom(src)
+	dst.mergeGateFrom(src)
+	dst.mergeGitFrom(src)
+	dst.mergeVerifyFrom(src)
+	dst.mergeReviewFrom(src)
+	dst.mergeOutputFrom(src)
+	dst.mergeNotifyFrom(src)
+	dst.mergePublishFrom(src)
+	dst.mergeExtensionFrom(src)
+}
+
+// mergeAgentFrom merges the claude, codex and custom review settings, the instructions and the language from src into dst.
+func (dst *Values) mergeAgentFrom(src *Values) {
+	mergeString(&dst.ClaudeCommand, src.ClaudeCommand)
+	mergeString(&dst.ClaudeArgs, src.ClaudeArgs)
+	mergeString(&dst.PlanLintArgs, src.PlanLintArgs)
+	mergeSet(&dst.CodexEnabled, &dst.CodexEnabledSet, src.CodexEnabled, src.CodexEnabledSet)
+	mergeString(&dst.CodexCommand, src.CodexCommand)
+	mergeString(&dst.CodexModel, src.CodexModel)
+	mergeString(&dst.CodexReasoningEffort, src.CodexReasoningEffort)
+	mergeSet(&dst.CodexTimeoutMs, &dst.CodexTimeoutMsSet, src.CodexTimeoutMs, src.CodexTimeoutMsSet)
+	mergeString(&dst.CodexSandbox, src.CodexSandbox)
+	mergeString(&dst.ExternalReviewTool, src.ExternalReviewTool)
+	mergeString(&dst.CustomReviewScript, src.CustomReviewScript)
+	mergeString(&dst.TaskInstructions, src.TaskInstructions)
+	mergeString(&dst.ReviewInstructions, src.ReviewInstructions)
+	mergeString(&dst.CodexInstructions, src.CodexInstructions)
+	mergeString(&dst.Language, src.Language)
+}
+
+// mergeRunFrom merges the run limits, timeouts, retries and resource guards from src into dst.
+func (dst *Values) mergeRunFrom(src *Values) {
+	mergeSet(&dst.RepeatUntilClean, &dst.RepeatUntilCleanSet, src.RepeatUntilClean, src.RepeatUntilCleanSet)
+	mergeSlice(&dst.Phases, src.Phases)
+	mergeSet(&dst.MaxRunDurationMs, &dst.MaxRunDurationMsSet, src.MaxRunDurationMs, src.MaxRunDurationMsSet)
+	mergeSet(&dst.RunWindow, &dst.RunWindowSet, src.RunWindow, src.RunWindowSet)
+	mergeSet(&dst.TaskPhaseTimeoutMs, &dst.TaskPhaseTimeoutMsSet, src.TaskPhaseTimeoutMs, src.TaskPhaseTimeoutMsSet)
+	mergeSet(&dst.ReviewPhaseTimeoutMs, &dst.ReviewPhaseTimeoutMsSet, src.ReviewPhaseTimeoutMs, src.ReviewPhaseTimeoutMsSet)
+	mergeSet(&dst.CodexPhaseTimeoutMs, &dst.CodexPhaseTimeoutMsSet, src.CodexPhaseTimeoutMs, src.CodexPhaseTimeoutMsSet)
+	mergeSet(&dst.IterationDelayMs, &dst.IterationDelayMsSet, src.IterationDelayMs, src.IterationDelayMsSet)
+	mergeSet(&dst.TaskRetryCount, &dst.TaskRetryCountSet, src.TaskRetryCount, src.TaskRetryCountSet)
+	mergeSet(&dst.TaskMaxIterations, &dst.TaskMaxIterationsSet, src.TaskMaxIterations, src.TaskMaxIterationsSet)
+	mergeString(&dst.RollbackOnFailure, src.RollbackOnFailure)
+	mergeSet(&dst.ContinueSession, &dst.ContinueSessionSet, src.ContinueSession, src.ContinueSessionSet)
+	mergeString(&dst.CompletedPlan, src.CompletedPlan)
+	mergeSet(&dst.ExecutorRetryCount, &dst.ExecutorRetryCountSet, src.ExecutorRetryCount, src.ExecutorRetryCountSet)
+	mergeSet(&dst.ExecutorRetryDelayMs, &dst.ExecutorRetryDelayMsSet, src.ExecutorRetryDelayMs, src.ExecutorRetryDelayMsSet)
+	mergeSet(&dst.ExecutorRetryMaxDelayMs, &dst.ExecutorRetryMaxDelaySet, src.ExecutorRetryMaxDelayMs, src.ExecutorRetryMaxDelaySet)
+	mergeSet(&dst.ExecutorRetryJitter, &dst.ExecutorRetryJitterSet, src.ExecutorRetryJitter, src.ExecutorRetryJitterSet)
+	mergeSet(&dst.StallIterations, &dst.StallIterationsSet, src.StallIterations, src.StallIterationsSet)
+	mergeSet(&dst.StallSimilarity, &dst.StallSimilaritySet, src.StallSimilarity, src.StallSimilaritySet)
+	mergeSet(&dst.BudgetExtensionFactor, &dst.BudgetExtensionFactorSet, src.BudgetExtensionFactor, src.BudgetExtensionFactorSet)
+	mergeSet(&dst.BudgetMaxExtensions, &dst.BudgetMaxExtensionsSet, src.BudgetMaxExtensions, src.BudgetMaxExtensionsSet)
+	mergeSet(&dst.DiskMinFreeMB, &dst.DiskMinFreeMBSet, src.DiskMinFreeMB, src.DiskMinFreeMBSet)
+	mergeSet(&dst.DiskMaxGrowthMB, &dst.DiskMaxGrowthMBSet, src.DiskMaxGrowthMB, src.DiskMaxGrowthMBSet)
+}
+
+// mergeGateFrom merges the go.mod, generated code, formatting, license and artifact gates from src into dst.
+func (dst *Values) mergeGateFrom(src *Values) {
+	mergeString(&dst.GoModGate, src.GoModGate)
+	mergeSet(&dst.GoModBlockNewDeps, &dst.GoModBlockNewDepsSet, src.GoModBlockNewDeps, src.GoModBlockNewDepsSet)
+	mergeSlice(&dst.DepsAllow, src.DepsAllow)
+	mergeSlice(&dst.DepsDeny, src.DepsDeny)
+	mergeString(&dst.GenerateGate, src.GenerateGate)
+	mergeSlice(&dst.GenerateCommands, src.GenerateCommands)
+	mergeString(&dst.FormatGate, src.FormatGate)
+	mergeMap(&dst.FormatterCommands, src.FormatterCommands)
+	mergeString(&dst.LicenseCheck, src.LicenseCheck)
+	mergeSlice(&dst.LicenseAllow, src.LicenseAllow)
+	mergeString(&dst.ArtifactGate, src.ArtifactGate)
+	mergeSet(&dst.ArtifactMaxSizeKB, &dst.ArtifactMaxSizeKBSet, src.ArtifactMaxSizeKB, src.ArtifactMaxSizeKBSet)
+	mergeSlice(&dst.ArtifactAllow, src.ArtifactAllow)
+	mergeSet(&dst.MaxOutputBytes, &dst.MaxOutputBytesSet, src.MaxOutputBytes, src.MaxOutputBytesSet)
+}
+
+// mergeGitFrom merges the finalize, plan archive and git settings from src into dst.
+func (dst *Values) mergeGitFrom(src *Values) {
+	mergeSet(&dst.FinalizeEnabled, &dst.FinalizeEnabledSet, src.FinalizeEnabled, src.FinalizeEnabledSet)
+	mergeString(&dst.PlansDir, src.PlansDir)
+	mergeSet(&dst.ArchivePlans, &dst.ArchivePlansSet, src.ArchivePlans, src.ArchivePlansSet)
+	mergeString(&dst.ArchiveDir, src.ArchiveDir)
+	mergeString(&dst.DefaultBranch, src.DefaultBranch)
+	mergeString(&dst.GitCommand, src.GitCommand)
+	mergeSet(&dst.PartialCloneFetch, &dst.PartialCloneFetchSet, src.PartialCloneFetch, src.PartialCloneFetchSet)
+}
+
+// mergeVerifyFrom merges the verification settings from src into dst.
+func (dst *Values) mergeVerifyFrom(src *Values) {
+	mergeSet(&dst.VerifyEnabled, &dst.VerifyEnabledSet, src.VerifyEnabled, src.VerifyEnabledSet)
+	mergeString(&dst.VerifyProfile, src.VerifyProfile)
+	mergeString(&dst.VerifyImage, src.VerifyImage)
+	mergeString(&dst.VerifyContainerCommand, src.VerifyContainerCommand)
+	mergeString(&dst.VerifyServices, src.VerifyServices)
+	mergeSlice(&dst.VerifyGoVersions, src.VerifyGoVersions)
+	mergeSlice(&dst.VerifyShell, src.VerifyShell)
+	mergeSlice(&dst.VerifyTargets, src.VerifyTargets)
+	mergeSlice(&dst.VerifyCommands, src.VerifyCommands)
+	mergeSet(&dst.VerifyTimeoutMs, &dst.VerifyTimeoutMsSet, src.VerifyTimeoutMs, src.VerifyTimeoutMsSet)
+	mergeSlice(&dst.WatchDirs, src.WatchDirs)
+}
+
+// mergeReviewFrom merges the review settings and the error patterns of the agents from src into dst.
+func (dst *Values) mergeReviewFrom(src *Values) {
+	mergeSet(&dst.ParallelFirstReview, &dst.ParallelFirstReviewSet, src.ParallelFirstReview, src.ParallelFirstReviewSet)
+	mergeSet(&dst.ConsensusReview, &dst.ConsensusReviewSet, src.ConsensusReview, src.ConsensusReviewSet)
+	mergeSet(&dst.CodexSplitDirs, &dst.CodexSplitDirsSet, src.CodexSplitDirs, src.CodexSplitDirsSet)
+	mergeSet(&dst.CodexSplitConcurrency, &dst.CodexSplitConcurrencySet, src.CodexSplitConcurrency, src.CodexSplitConcurrencySet)
+	mergeSet(&dst.MilestoneReviews, &dst.MilestoneReviewsSet, src.MilestoneReviews, src.MilestoneReviewsSet)
+	mergeSet(&dst.EscalationReview, &dst.EscalationReviewSet, src.EscalationReview, src.EscalationReviewSet)
+	mergeSet(&dst.FinalVerification, &dst.FinalVerificationSet, src.FinalVerification, src.FinalVerificationSet)
+	mergeString(&dst.EscalationClaudeArgs, src.EscalationClaudeArgs)
+	mergeString(&dst.EscalationCodexModel, src.EscalationCodexModel)
+	mergeString(&dst.EscalationCodexReasoningEffort, src.EscalationCodexReasoningEffort)
+	mergeString(&dst.PostReviewSkipSeverity, src.PostReviewSkipSeverity)
+	mergeSet(&dst.PostReviewSkipFindings, &dst.PostReviewSkipFindingsSet, src.PostReviewSkipFindings, src.PostReviewSkipFindingsSet)
+	mergeString(&dst.OnCodexError, src.OnCodexError)
+	mergeString(&dst.OnReviewError, src.OnReviewError)
+	mergeSet(&dst.RequireFindingResolution, &dst.RequireFindingResolutionSet, src.RequireFindingResolution, src.RequireFindingResolutionSet)
+	mergeSet(&dst.VerifyReviewDone, &dst.VerifyReviewDoneSet, src.VerifyReviewDone, src.VerifyReviewDoneSet)
+	mergeSlice(&dst.ClaudeErrorPatterns, src.ClaudeErrorPatterns)
+	mergeSlice(&dst.CodexErrorPatterns, src.CodexErrorPatterns)
+}
+
+// mergeOutputFrom merges the diff, plan annotation and interaction settings from src into dst.
+func (dst *Values) mergeOutputFrom(src *Values) {
+	mergeString(&dst.ShowDiff, src.ShowDiff)
+	mergeSet(&dst.ShowDiffMaxLines, &dst.ShowDiffMaxLinesSet, src.ShowDiffMaxLines, src.ShowDiffMaxLinesSet)
+	mergeSet(&dst.AnnotatePlan, &dst.AnnotatePlanSet, src.AnnotatePlan, src.AnnotatePlanSet)
+	mergeSet(&dst.IterationCost, &dst.IterationCostSet, src.IterationCost, src.IterationCostSet)
+	mergeSet(&dst.WarnPromptChange, &dst.WarnPromptChangeSet, src.WarnPromptChange, src.WarnPromptChangeSet)
+	mergeSet(&dst.AccessibleOutput, &dst.AccessibleOutputSet, src.AccessibleOutput, src.AccessibleOutputSet)
+	mergeSet(&dst.NeedsInputTimeoutMs, &dst.NeedsInputTimeoutMsSet, src.NeedsInputTimeoutMs, src.NeedsInputTimeoutMsSet)
+}
+
+// mergeExtensionFrom merges the hooks, analyzers, spell checking and plugins from src into dst.
+func (dst *Values) mergeExtensionFrom(src *Values) {
+	mergeMap(&dst.HookCommands, src.HookCommands)
+	mergeSet(&dst.HooksRequired, &dst.HooksRequiredSet, src.HooksRequired, src.HooksRequiredSet)
+	mergeMap(&dst.AnalyzerCommands, src.AnalyzerCommands)
+	mergeSet(&dst.SpellCheck, &dst.SpellCheckSet, src.SpellCheck, src.SpellCheckSet)
+	mergeSlice(&dst.SpellIgnore, src.SpellIgnore)
+	mergeMap(&dst.PluginCommands, src.PluginCommands)
+}
+
+// mergeNotifyFrom merges notification-related fields from src into dst.
+// called from mergeFrom to manage function length.
+func (dst *Values) mergeNotifyFrom(src *Values) {
+	mergeSet(&dst.NotifyChannels, &dst.NotifyChannelsSet, src.NotifyChannels, src.NotifyChannelsSet)
+	mergeSet(&dst.NotifyOnError, &dst.NotifyOnErrorSet, src.NotifyOnError, src.NotifyOnErrorSet)
+	mergeSet(&dst.NotifyOnComplete, &dst.NotifyOnCompleteSet, src.NotifyOnComplete, src.NotifyOnCompleteSet)
+	mergeSet(&dst.NotifyTimeoutMs, &dst.NotifyTimeoutMsSet, src.NotifyTimeoutMs, src.NotifyTimeoutMsSet)
+	mergeString(&dst.NotifyTelegramToken, src.NotifyTelegramToken)
+	mergeString(&dst.NotifyTelegramChat, src.NotifyTelegramChat)
+	mergeString(&dst.NotifySlackToken, src.NotifySlackToken)
+	mergeString(&dst.NotifySlackChannel, src.NotifySlackChannel)
+	mergeString(&dst.NotifySMTPHost, src.NotifySMTPHost)
+	mergeSet(&dst.NotifySMTPPort, &dst.NotifySMTPPortSet, src.NotifySMTPPort, src.NotifySMTPPortSet)
+	mergeString(&dst.NotifySMTPUsername, src.NotifySMTPUsername)
+	mergeString(&dst.NotifySMTPPassword, src.NotifySMTPPassword)
+	mergeSet(&dst.NotifySMTPStartTLS, &dst.NotifySMTPStartTLSSet, src.NotifySMTPStartTLS, src.NotifySMTPStartTLSSet)
+	mergeString(&dst.NotifyEmailFrom, src.NotifyEmailFrom)
+	mergeSet(&dst.NotifyEmailTo, &dst.NotifyEmailToSet, src.NotifyEmailTo, src.NotifyEmailToSet)
+	mergeSet(&dst.NotifyWebhookURLs, &dst.NotifyWebhookURLsSet, src.NotifyWebhookURLs, src.NotifyWebhookURLsSet)
+	mergeString(&dst.NotifyCustomScript, src.NotifyCustomScript)
+}
+
+// mergePublishFrom merges the artifact upload, log shipping, telemetry and artifact storage settings
+// from src into dst.
+func (dst *Values) mergePublishFrom(src *Values) {
+	mergeString(&dst.ArtifactsDestination, src.ArtifactsDestination)
+	mergeString(&dst.ArtifactsCommand, src.ArtifactsCommand)
+	mergeString(&dst.LogShipDestination, src.LogShipDestination)
+	mergeString(&dst.TelemetryEndpoint, src.TelemetryEndpoint)
+	mergeString(&dst.ArtifactLocation, src.ArtifactLocation)
+	mergeString(&dst.ArtifactKey, src.ArtifactKey)
+}
+
+// mergeString sets dst to src unless src is empty.
+func mergeString(dst *string, src string) {
+	if src != "" {
+		*dst = src
 	}
-	if src.ClaudeArgs != "" {
-		dst.ClaudeArgs = src.ClaudeArgs
+}
+
+// mergeSet sets dst to src and marks it as set if src was explicitly set, see the *Set fields of Values.
+func mergeSet[T any](dst *T, dstSet *bool, src T, srcSet bool) {
+	if srcSet {
+		*dst, *dstSet = src, true
 	}
-	if src.CodexEnabledSet {
-		dst.CodexEnabled = src.CodexEnabled
-		dst.CodexEnabledSet = true
+}
+
+// mergeSlice sets dst to src unless src is empty.
+func mergeSlice[T any](dst *[]T, src []T) {
+	if len(src) > 0 {
+		*dst = src
 	}
-	if src.CodexCommand != "" {
-		dst.CodexCommand = src.CodexCommand
+}
+
+// mergeMap adds the entries of src to dst, an entry of src replaces the entry of dst with the same key.
+func mergeMap(dst *map[string]string, src map[string]string) {
+	for k, v := range src {
+		if *dst == nil {
+			*dst = map[string]string{}
+		}
+		(*dst)[k] = v
 	}
-	if src.CodexModel != "" {
-		dst.CodexModel = src.CodexModel
+}
+
+// parseHookValues extracts hook commands (hook_<point> keys) and hooks_required from an INI section into Values.
+// an empty command disables a hook set in the global config.
+func parseHookValues(section *ini.Section, values *Values) error {
+	for _, point := range HookPoints {
+		if key, err := section.GetKey("hook_" + point); err == nil {
+			if values.HookCommands == nil {
+				values.HookCommands = map[string]string{}
+			}
+			values.HookCommands[point] = strings.TrimSpace(key.String())
+		}
 	}
-	if src.CodexReasoningEffort != "" {
-		dst.CodexReasoningEffort = src.CodexReasoningEffort
+	if key, err := section.GetKey("hooks_required"); err == nil {
+		values.HooksRequiredSet = true
+		values.HooksRequired = nil
+		for p := range strings.SplitSeq(key.String(), ",") {
+			point := strings.ToLower(strings.TrimSpace(p))
+			if point == "" {
+				continue
+			}
+			if !slices.Contains(HookPoints, point) {
+				return fmt.Errorf("invalid hooks_required: unknown hook %q, use %s", point, strings.Join(HookPoints, ", "))
+			}
+			values.HooksRequired = append(values.HooksRequired, point)
+		}
 	}
-	if src.CodexTimeoutMsSet {
-		dst.CodexTimeoutMs = src.CodexTimeoutMs
-		dst.CodexTimeoutMsSet = true
+	return nil
+}
+
+// parseRetryValues extracts executor retry settings from an INI section into Values.
+func parseRetryValues(section *ini.Section, values *Values) error {
+	ints := []struct {
+		key string
+		val *int
+		set *bool
+	}{
+		{"executor_retry_count", &values.ExecutorRetryCount, &values.ExecutorRetryCountSet},
+		{"executor_retry_delay_ms", &values.ExecutorRetryDelayMs, &values.ExecutorRetryDelayMsSet},
+		{"executor_retry_max_delay_ms", &values.ExecutorRetryMaxDelayMs, &values.ExecutorRetryMaxDelaySet},
+	}
+	for _, v := range ints {
+		key, err := section.GetKey(v.key)
+		if err != nil {
+			continue
+		}
+		val, intErr := key.Int()
+		if intErr != nil {
+			return fmt.Errorf("invalid %s: %w", v.key, intErr)
+		}
+		if val < 0 {
+			return fmt.Errorf("invalid %s: must be non-negative, got %d", v.key, val)
+		}
+		*v.val, *v.set = val, true
 	}
-	if src.CodexSandbox != "" {
-		dst.CodexSandbox = src.CodexSandbox
+
+	if key, err := section.GetKey("executor_retry_jitter"); err == nil {
+		val, floatErr := key.Float64()
+		if floatErr != nil {
+			return fmt.Errorf("invalid executor_retry_jitter: %w", floatErr)
+		}
+		if val < 0 || val > 1 {
+			return fmt.Errorf("invalid executor_retry_jitter: must be between 0 and 1, got %g", val)
+		}
+		values.ExecutorRetryJitter = val
+		values.ExecutorRetryJitterSet = true
 	}
-	if src.ExternalReviewTool != "" {
-		dst.ExternalReviewTool = src.ExternalReviewTool
+	return nil
+}
+
+// parseStallValues extracts no-progress detection settings of the task loop from an INI section into Values.
+func parseStallValues(section *ini.Section, values *Values) error {
+	if key, err := section.GetKey("stall_iterations"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return fmt.Errorf("invalid stall_iterations: %w", intErr)
+		}
+		if val < 0 {
+			return fmt.Errorf("invalid stall_iterations: must be non-negative, got %d", val)
+		}
+		values.StallIterations = val
+		values.StallIterationsSet = true
 	}
-	if src.CustomReviewScript != "" {
-		dst.CustomReviewScript = src.CustomReviewScript
+
+	if key, err := section.GetKey("stall_similarity"); err == nil {
+		val, floatErr := key.Float64()
+		if floatErr != nil {
+			return fmt.Errorf("invalid stall_similarity: %w", floatErr)
+		}
+		if val < 0 || val > 1 {
+			return fmt.Errorf("invalid stall_similarity: must be between 0 and 1, got %g", val)
+		}
+		values.StallSimilarity = val
+		values.StallSimilaritySet = true
 	}
-	if src.IterationDelayMsSet {
-		dst.IterationDelayMs = src.IterationDelayMs
-		dst.IterationDelayMsSet = true
+	return nil
+}
+
+// parseBudgetValues extracts the iteration budget extension settings of the task loop from an INI section into Values.
+func parseBudgetValues(section *ini.Section, values *Values) error {
+	if key, err := section.GetKey("budget_extension_factor"); err == nil {
+		val, floatErr := key.Float64()
+		if floatErr != nil {
+			return fmt.Errorf("invalid budget_extension_factor: %w", floatErr)
+		}
+		if val != 0 && val <= 1 {
+			return fmt.Errorf("invalid budget_extension_factor: must be 0 or greater than 1, got %g", val)
+		}
+		values.BudgetExtensionFactor = val
+		values.BudgetExtensionFactorSet = true
 	}
-	if src.TaskRetryCountSet {
-		dst.TaskRetryCount = src.TaskRetryCount
-		dst.TaskRetryCountSet = true
+
+	if key, err := section.GetKey("budget_max_extensions"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return fmt.Errorf("invalid budget_max_extensions: %w", intErr)
+		}
+		if val < 0 {
+			return fmt.Errorf("invalid budget_max_extensions: must be non-negative, got %d", val)
+		}
+		values.BudgetMaxExtensions = val
+		values.BudgetMaxExtensionsSet = true
 	}
-	if src.FinalizeEnabledSet {
-		dst.FinalizeEnabled = src.FinalizeEnabled
-		dst.FinalizeEnabledSet = true
+	return nil
+}
+
+// parseDiskValues extracts the disk usage guard thresholds from an INI section into Values.
+func parseDiskValues(section *ini.Section, values *Values) error {
+	for _, opt := range []struct {
+		name string
+		val  *int
+		set  *bool
+	}{
+		{"disk_min_free_mb", &values.DiskMinFreeMB, &values.DiskMinFreeMBSet},
+		{"disk_max_growth_mb", &values.DiskMaxGrowthMB, &values.DiskMaxGrowthMBSet},
+	} {
+		key, err := section.GetKey(opt.name)
+		if err != nil {
+			continue
+		}
+		val, intErr := key.Int()
+		if intErr != nil {
+			return fmt.Errorf("invalid %s: %w", opt.name, intErr)
+		}
+		if val < 0 {
+			return fmt.Errorf("invalid %s: must be non-negative, got %d", opt.name, val)
+		}
+		*opt.val, *opt.set = val, true
 	}
-	if src.PlansDir != "" {
-		dst.PlansDir = src.PlansDir
+	return nil
+}
+
+// parseGoModValues extracts the go.mod gate and dependency policy settings from an INI section into Values.
+func parseGoModValues(section *ini.Section, values *Values) error {
+	if key, err := section.GetKey("gomod_gate"); err == nil {
+		val := strings.ToLower(strings.TrimSpace(key.String()))
+		switch val {
+		case "", GoModGateOff, GoModGateFeedback, GoModGateFail:
+			values.GoModGate = val
+		default:
+			return fmt.Errorf("invalid gomod_gate: %q, use %s, %s or %s", val, GoModGateOff, GoModGateFeedback, GoModGateFail)
+		}
 	}
-	if src.DefaultBranch != "" {
-		dst.DefaultBranch = src.DefaultBranch
+	if key, err := section.GetKey("gomod_block_new_deps"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return fmt.Errorf("invalid gomod_block_new_deps: %w", boolErr)
+		}
+		values.GoModBlockNewDeps = val
+		values.GoModBlockNewDepsSet = true
 	}
-	if len(src.WatchDirs) > 0 {
-		dst.WatchDirs = src.WatchDirs
+	for _, name := range []string{"deps_allow", "deps_deny"} {
+		key, err := section.GetKey(name)
+		if err != nil {
+			continue
+		}
+		var patterns []string
+		for p := range strings.FieldsFuncSeq(key.String(), func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			if patErr := verify.ValidModulePattern(p); patErr != nil {
+				return fmt.Errorf("invalid %s: %w", name, patErr)
+			}
+			patterns = append(patterns, p)
+		}
+		if name == "deps_allow" {
+			values.DepsAllow = patterns
+		} else {
+			values.DepsDeny = patterns
+		}
 	}
-	if len(src.ClaudeErrorPatterns) > 0 {
-		dst.ClaudeErrorPatterns = src.ClaudeErrorPatterns
+	return nil
+}
+
+// parseGenerateValues extracts the generated code gate settings from an INI section into Values.
+func parseGenerateValues(section *ini.Section, values *Values) error {
+	if key, err := section.GetKey("generate_gate"); err == nil {
+		val := strings.ToLower(strings.TrimSpace(key.String()))
+		switch val {
+		case "", GenerateGateOff, GenerateGateFeedback, GenerateGateFail:
+			values.GenerateGate = val
+		default:
+			return fmt.Errorf("invalid generate_gate: %q, use %s, %s or %s", val, GenerateGateOff, GenerateGateFeedback, GenerateGateFail)
+		}
 	}
-	if len(src.CodexErrorPatterns) > 0 {
-		dst.CodexErrorPatterns = src.CodexErrorPatterns
+	if key, err := section.GetKey("generate_commands"); err == nil {
+		for c := range strings.SplitSeq(key.String(), ",") {
+			if t := strings.TrimSpace(c); t != "" {
+				values.GenerateCommands = append(values.GenerateCommands, t)
+			}
+		}
 	}
-
-	dst.mergeNotifyFrom(src)
+	return nil
 }
 
-// mergeNotifyFrom merges notification-related fields from src into dst.
-// called from mergeFrom to manage function length.
-func (dst *Values) mergeNotifyFrom(src *Values) {
-	if src.NotifyChannelsSet {
-		dst.NotifyChannels = src.NotifyChannels
-		dst.NotifyChannelsSet = true
+// parseFormatValues extracts the formatting gate settings and the formatter commands from formatter_<ext> keys
+// of an INI section into Values. an empty command disables a formatter defined by a lower-priority config.
+func parseFormatValues(section *ini.Section, values *Values) error {
+	if key, err := section.GetKey("format_gate"); err == nil {
+		val := strings.ToLower(strings.TrimSpace(key.String()))
+		switch val {
+		case "", FormatGateOff, FormatGateFix, FormatGateFeedback:
+			values.FormatGate = val
+		default:
+			return fmt.Errorf("invalid format_gate: %q, use %s, %s or %s", val, FormatGateOff, FormatGateFix, FormatGateFeedback)
+		}
 	}
-	if src.NotifyOnErrorSet {
-		dst.NotifyOnError = src.NotifyOnError
-		dst.NotifyOnErrorSet = true
+	for _, key := range section.Keys() {
+		ext, ok := strings.CutPrefix(key.Name(), "formatter_")
+		if !ok {
+			continue
+		}
+		if !commandNameRe.MatchString(ext) {
+			return fmt.Errorf("invalid %s: file extension must be lowercase letters, digits, - or _", key.Name())
+		}
+		if values.FormatterCommands == nil {
+			values.FormatterCommands = map[string]string{}
+		}
+		values.FormatterCommands[ext] = strings.TrimSpace(key.String())
 	}
-	if src.NotifyOnCompleteSet {
-		dst.NotifyOnComplete = src.NotifyOnComplete
-		dst.NotifyOnCompleteSet = true
+	return nil
+}
+
+// parseEscalationValues extracts the escalation review and final verification settings from an INI section into Values.
+func parseEscalationValues(section *ini.Section, values *Values) error {
+	if key, err := section.GetKey("escalation_review"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return fmt.Errorf("invalid escalation_review: %w", boolErr)
+		}
+		values.EscalationReview = val
+		values.EscalationReviewSet = true
 	}
-	if src.NotifyTimeoutMsSet {
-		dst.NotifyTimeoutMs = src.NotifyTimeoutMs
-		dst.NotifyTimeoutMsSet = true
+	if key, err := section.GetKey("escalation_claude_args"); err == nil {
+		values.EscalationClaudeArgs = strings.TrimSpace(key.String())
 	}
-	if src.NotifyTelegramToken != "" {
-		dst.NotifyTelegramToken = src.NotifyTelegramToken
+	if key, err := section.GetKey("escalation_codex_model"); err == nil {
+		values.EscalationCodexModel = strings.TrimSpace(key.String())
 	}
-	if src.NotifyTelegramChat != "" {
-		dst.NotifyTelegramChat = src.NotifyTelegramChat
+	if key, err := section.GetKey("escalation_codex_reasoning_effort"); err == nil {
+		values.EscalationCodexReasoningEffort = strings.TrimSpace(key.String())
 	}
-	if src.NotifySlackToken != "" {
-		dst.NotifySlackToken = src.NotifySlackToken
+	if key, err := section.GetKey("final_verification"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return fmt.Errorf("invalid final_verification: %w", boolErr)
+		}
+		values.FinalVerification = val
+		values.FinalVerificationSet = true
 	}
-	if src.NotifySlackChannel != "" {
-		dst.NotifySlackChannel = src.NotifySlackChannel
+	return nil
+}
+
+// parseLicenseValues extracts the license check settings from an INI section into Values.
+func parseLicenseValues(section *ini.Section, values *Values) error {
+	if key, err := section.GetKey("license_check"); err == nil {
+		val := strings.ToLower(strings.TrimSpace(key.String()))
+		switch val {
+		case "", LicenseCheckOff, LicenseCheckWarn, LicenseCheckFail:
+			values.LicenseCheck = val
+		default:
+			return fmt.Errorf("invalid license_check: %q, use %s, %s or %s", val, LicenseCheckOff, LicenseCheckWarn, LicenseCheckFail)
+		}
 	}
-	if src.NotifySMTPHost != "" {
-		dst.NotifySMTPHost = src.NotifySMTPHost
+	if key, err := section.GetKey("license_allow"); err == nil {
+		values.LicenseAllow = slices.Collect(strings.FieldsFuncSeq(key.String(), func(r rune) bool { return r == ',' || unicode.IsSpace(r) }))
 	}
-	if src.NotifySMTPPortSet {
-		dst.NotifySMTPPort = src.NotifySMTPPort
-		dst.NotifySMTPPortSet = true
+	return nil
+}
+
+// parseArtifactValues extracts the artifact gate settings from an INI section into Values.
+func parseArtifactValues(section *ini.Section, values *Values) error {
+	if key, err := section.GetKey("artifact_gate"); err == nil {
+		val := strings.ToLower(strings.TrimSpace(key.String()))
+		switch val {
+		case "", ArtifactGateOff, ArtifactGateWarn, ArtifactGateFeedback, ArtifactGateFail:
+			values.ArtifactGate = val
+		default:
+			return fmt.Errorf("invalid artifact_gate: %q, use %s, %s, %s or %s", val,
+				ArtifactGateOff, ArtifactGateWarn, ArtifactGateFeedback, ArtifactGateFail)
+		}
 	}
-	if src.NotifySMTPUsername != "" {
-		dst.NotifySMTPUsername = src.NotifySMTPUsername
+	if key, err := section.GetKey("artifact_max_size_kb"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return fmt.Errorf("invalid artifact_max_size_kb: %w", intErr)
+		}
+		if val < 0 {
+			return fmt.Errorf("invalid artifact_max_size_kb: must be non-negative, got %d", val)
+		}
+		values.ArtifactMaxSizeKB = val
+		values.ArtifactMaxSizeKBSet = true
 	}
-	if src.NotifySMTPPassword != "" {
-		dst.NotifySMTPPassword = src.NotifySMTPPassword
+	if key, err := section.GetKey("artifact_allow"); err == nil {
+		values.ArtifactAllow = slices.Collect(strings.FieldsFuncSeq(key.String(), func(r rune) bool { return r == ',' || unicode.IsSpace(r) }))
 	}
-	if src.NotifySMTPStartTLSSet {
-		dst.NotifySMTPStartTLS = src.NotifySMTPStartTLS
-		dst.NotifySMTPStartTLSSet = true
+	return nil
+}
+
+// commandNameRe matches valid analyzer and plugin names, the part of an analyzer_<name> or plugin_<name> key
+var commandNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// parseAnalyzerValues extracts analyzer and plugin commands from analyzer_<name> and plugin_<name> keys
+// of an INI section into Values. an empty command disables one defined by a lower-priority config.
+func parseAnalyzerValues(section *ini.Section, values *Values) error {
+	for _, key := range section.Keys() {
+		kind, name, ok := strings.Cut(key.Name(), "_")
+		if !ok || (kind != "analyzer" && kind != "plugin") {
+			continue
+		}
+		if !commandNameRe.MatchString(name) {
+			return fmt.Errorf("invalid %s: %s name must be lowercase letters, digits, - or _", key.Name(), kind)
+		}
+		commands := &values.AnalyzerCommands
+		if kind == "plugin" {
+			commands = &values.PluginCommands
+		}
+		if *commands == nil {
+			*commands = map[string]string{}
+		}
+		(*commands)[name] = strings.TrimSpace(key.String())
 	}
-	if src.NotifyEmailFrom != "" {
-		dst.NotifyEmailFrom = src.NotifyEmailFrom
+	return nil
+}
+
+// parseInstructionValues extracts the project instructions appended to the prompts of each phase
+// and the language of agent output from an INI section into Values.
+func parseInstructionValues(section *ini.Section, values *Values) {
+	for _, opt := range []struct {
+		key string
+		dst *string
+	}{
+		{"task_instructions", &values.TaskInstructions},
+		{"review_instructions", &values.ReviewInstructions},
+		{"codex_instructions", &values.CodexInstructions},
+		{"language", &values.Language},
+	} {
+		if key, err := section.GetKey(opt.key); err == nil {
+			*opt.dst = strings.TrimSpace(key.String())
+		}
 	}
-	if src.NotifyEmailToSet {
-		dst.NotifyEmailTo = src.NotifyEmailTo
-		dst.NotifyEmailToSet = true
+}
+
+// parseSpellValues extracts spell_check and spell_ignore from an INI section into Values.
+func parseSpellValues(section *ini.Section, values *Values) error {
+	if key, err := section.GetKey("spell_check"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return fmt.Errorf("invalid spell_check: %w", boolErr)
+		}
+		values.SpellCheck = val
+		values.SpellCheckSet = true
 	}
-	if src.NotifyWebhookURLsSet {
-		dst.NotifyWebhookURLs = src.NotifyWebhookURLs
-		dst.NotifyWebhookURLsSet = true
+	if key, err := section.GetKey("spell_ignore"); err == nil {
+		values.SpellIgnore = slices.Collect(strings.FieldsFuncSeq(key.String(), func(r rune) bool { return r == ',' || unicode.IsSpace(r) }))
 	}
-	if src.NotifyCustomScript != "" {
-		dst.NotifyCustomScript = src.NotifyCustomScript
+	return nil
+}
+
+// buildHooks combines hook commands and hooks_required into hooks by point, hooks without a command are left out
+func buildHooks(values Values) map[string]Hook {
+	res := map[string]Hook{}
+	for point, command := range values.HookCommands {
+		if command != "" {
+			res[point] = Hook{Command: command, Required: slices.Contains(values.HooksRequired, point)}
+		}
+	}
+	return res
+}
+
+// buildCommands returns analyzer, plugin or formatter commands by name, ones without a command are left out
+func buildCommands(commands map[string]string) map[string]string {
+	res := map[string]string{}
+	for name, command := range commands {
+		if command != "" {
+			res[name] = command
+		}
 	}
+	return res
 }
 
 // parseNotifyValues extracts notification-related settings from an INI section into Values.
@@ -548,6 +1637,25 @@ func parseNotifyDestValues(section *ini.Section, values *Values) error {
 	return nil
 }
 
+// parsePhases parses a comma-separated pipeline definition
+func parsePhases(val string) ([]PhaseSpec, error) {
+	var res []PhaseSpec
+	for p := range strings.SplitSeq(val, ",") {
+		name := strings.ToLower(strings.TrimSpace(p))
+		if name == "" {
+			continue
+		}
+		spec := PhaseSpec{Name: strings.TrimSuffix(name, "?"), Optional: strings.HasSuffix(name, "?")}
+		switch spec.Name {
+		case PhaseTask, PhaseReview, PhaseCodex, PhaseFinalize:
+			res = append(res, spec)
+		default:
+			return nil, fmt.Errorf("unknown phase %q, use %s, %s, %s or %s", spec.Name, PhaseTask, PhaseReview, PhaseCodex, PhaseFinalize)
+		}
+	}
+	return res, nil
+}
+
 // expandTilde expands a leading ~ in a path to the user's home directory.
 // returns the original path if it doesn't start with ~/ or if home dir is unavailable.
 func expandTilde(path string) string {
@@ -133,6 +133,8 @@ func TestValuesLoader_Load_InvalidConfig(t *testing.T) {
 		{name: "negative task_retry_count", config: "task_retry_count = -1", errPart: "task_retry_count"},
 		{name: "negative codex_timeout_ms", config: "codex_timeout_ms = -100", errPart: "codex_timeout_ms"},
 		{name: "negative iteration_delay_ms", config: "iteration_delay_ms = -50", errPart: "iteration_delay_ms"},
+		{name: "invalid max_output_bytes", config: "max_output_bytes = lots", errPart: "max_output_bytes"},
+		{name: "negative max_output_bytes", config: "max_output_bytes = -1", errPart: "max_output_bytes"},
 	}
 
 	for _, tc := range tests {
@@ -191,6 +193,26 @@ func TestValuesLoader_Load_ExplicitZeroTaskRetryCount(t *testing.T) {
 	assert.True(t, values.TaskRetryCountSet)
 }
 
+func TestValuesLoader_Load_MaxOutputBytes(t *testing.T) {
+	t.Run("embedded default", func(t *testing.T) {
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", "")
+		require.NoError(t, err)
+		assert.Equal(t, 1048576, values.MaxOutputBytes)
+	})
+
+	t.Run("explicit zero disables limit", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(configPath, []byte(`max_output_bytes = 0`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", configPath)
+		require.NoError(t, err)
+		assert.Equal(t, 0, values.MaxOutputBytes)
+		assert.True(t, values.MaxOutputBytesSet)
+	})
+}
+
 func TestValuesLoader_Load_ExplicitZeroCodexTimeoutMs(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "config")
@@ -906,6 +928,22 @@ notify_custom_script = /usr/local/bin/notify.sh
 		require.NoError(t, homeErr)
 		assert.Equal(t, home+"/.config/ralphex/scripts/notify.sh", values.NotifyCustomScript)
 	})
+
+	t.Run("artifacts publishing", func(t *testing.T) {
+		data := []byte("artifacts_destination = s3://bucket/runs\nartifacts_command = ~/upload.sh\n")
+		values, err := vl.parseValuesFromBytes(data)
+		require.NoError(t, err)
+
+		home, homeErr := os.UserHomeDir()
+		require.NoError(t, homeErr)
+		assert.Equal(t, "s3://bucket/runs", values.ArtifactsDestination)
+		assert.Equal(t, home+"/upload.sh", values.ArtifactsCommand)
+
+		merged := Values{ArtifactsDestination: "gs://old"}
+		merged.mergeFrom(&values)
+		assert.Equal(t, "s3://bucket/runs", merged.ArtifactsDestination)
+		assert.Equal(t, home+"/upload.sh", merged.ArtifactsCommand)
+	})
 }
 
 func TestValuesLoader_Load_InvalidNotifyConfig(t *testing.T) {
@@ -1181,6 +1219,429 @@ func TestValuesLoader_Load_LocalOverridesGlobalDefaultBranch(t *testing.T) {
 	assert.Equal(t, "dev", values.DefaultBranch)
 }
 
+func TestValuesLoader_Load_GitCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte(`git_command =  /opt/git/bin/git  `), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/git/bin/git", values.GitCommand)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte(`git_command = git-wrapper`), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "git-wrapper", values.GitCommand, "local config overrides global")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.GitCommand, "not set by default, git from PATH is used")
+}
+
+func TestValuesLoader_Load_PartialCloneFetch(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.True(t, values.PartialCloneFetch, "enabled by embedded defaults")
+	assert.True(t, values.PartialCloneFetchSet)
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("partial_clone_fetch = true"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("partial_clone_fetch = false"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.PartialCloneFetch, "local false overrides global true")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("partial_clone_fetch = maybe"), 0o600))
+	_, err = loader.Load(localConfig, globalConfig)
+	require.ErrorContains(t, err, "invalid partial_clone_fetch")
+}
+
+func TestValuesLoader_Load_Verify(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.VerifyEnabled, "disabled by default")
+	assert.Equal(t, "auto", values.VerifyProfile)
+	assert.Empty(t, values.VerifyCommands)
+	assert.Empty(t, values.VerifyTargets)
+	assert.Empty(t, values.VerifyImage, "runs on the host by default")
+	assert.Equal(t, 600000, values.VerifyTimeoutMs)
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("verify_enabled = true\nverify_profile = rust\nverify_targets = lint,  test unit\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig,
+		[]byte("verify_commands = make lint, make test ,\nverify_timeout_ms = 30000\n"+
+			"verify_image = golang:1.24 \nverify_container_command = podman\nverify_services = compose.test.yml\nverify_go_versions = 1.23, go1.24.1\n"+
+			"verify_shell = bash, windows:pwsh\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.VerifyEnabled)
+	assert.Equal(t, "rust", values.VerifyProfile)
+	assert.Equal(t, []string{"make lint", "make test"}, values.VerifyCommands)
+	assert.Equal(t, []string{"lint", "test", "unit"}, values.VerifyTargets)
+	assert.Equal(t, 30000, values.VerifyTimeoutMs)
+	assert.Equal(t, "golang:1.24", values.VerifyImage)
+	assert.Equal(t, "podman", values.VerifyContainerCommand)
+	assert.Equal(t, "compose.test.yml", values.VerifyServices)
+	assert.Equal(t, []string{"1.23", "go1.24.1"}, values.VerifyGoVersions)
+	assert.Equal(t, []string{"bash", "windows:pwsh"}, values.VerifyShell)
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{name: "unknown profile", config: "verify_profile = cobol", wantErr: `invalid verify_profile: unknown profile "cobol"`},
+		{name: "negative timeout", config: "verify_timeout_ms = -1", wantErr: "invalid verify_timeout_ms: must be non-negative"},
+		{name: "bad enabled", config: "verify_enabled = sometimes", wantErr: "invalid verify_enabled"},
+		{name: "bad go version", config: "verify_go_versions = 1.23, latest", wantErr: `invalid verify_go_versions: "latest" is not a go version`},
+		{name: "unknown shell", config: "verify_shell = windows:fish", wantErr: `invalid verify_shell: unknown shell "fish"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(localConfig, []byte(tc.config), 0o600))
+			_, err := loader.Load(localConfig, "")
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestValuesLoader_Load_ShowDiff(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Equal(t, ShowDiffNone, values.ShowDiff, "disabled by default")
+	assert.Equal(t, 200, values.ShowDiffMaxLines)
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("show_diff = Phase\nshow_diff_max_lines = 50\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("show_diff_max_lines = 0\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, ShowDiffPhase, values.ShowDiff)
+	assert.Equal(t, 0, values.ShowDiffMaxLines, "local zero overrides global")
+	assert.True(t, values.ShowDiffMaxLinesSet)
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{name: "unknown mode", config: "show_diff = always", wantErr: `invalid show_diff: "always"`},
+		{name: "negative max lines", config: "show_diff_max_lines = -1", wantErr: "invalid show_diff_max_lines: must be non-negative"},
+		{name: "bad max lines", config: "show_diff_max_lines = many", wantErr: "invalid show_diff_max_lines"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(localConfig, []byte(tc.config), 0o600))
+			_, err := loader.Load(localConfig, "")
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestValuesLoader_Load_AnnotatePlan(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.AnnotatePlan, "disabled by default")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("annotate_plan = true\n"), 0o600))
+	values, err = loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.AnnotatePlan)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("annotate_plan = false\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.AnnotatePlan, "local false overrides global true")
+	assert.True(t, values.AnnotatePlanSet)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("annotate_plan = maybe\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid annotate_plan")
+}
+
+func TestValuesLoader_Load_ArchivePlans(t *testing.T) {
+	tmpDir := t.TempDir()
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.ArchivePlans, "disabled by default")
+	assert.Equal(t, ".ralphex/done", values.ArchiveDir)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("archive_plans = true\narchive_dir = plans/archive\n"), 0o600))
+	values, err = loader.Load(localConfig, "")
+	require.NoError(t, err)
+	assert.True(t, values.ArchivePlans)
+	assert.Equal(t, "plans/archive", values.ArchiveDir)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("archive_plans = sometimes\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid archive_plans")
+}
+
+func TestValuesLoader_Load_IterationCost(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Zero(t, values.IterationCost, "no cost by default")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("iteration_cost = 0.35\n"), 0o600))
+	values, err = loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.35, values.IterationCost, 0.0001)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("iteration_cost = 0\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Zero(t, values.IterationCost, "local zero overrides global")
+	assert.True(t, values.IterationCostSet)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("iteration_cost = -1\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid iteration_cost: must be non-negative")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("iteration_cost = cheap\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid iteration_cost")
+}
+
+func TestValuesLoader_Load_Phases(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.Phases, "default pipeline")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("phases = task, Review, codex?, review,\n"), 0o600))
+	values, err = loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []PhaseSpec{{Name: PhaseTask}, {Name: PhaseReview}, {Name: PhaseCodex, Optional: true}, {Name: PhaseReview}},
+		values.Phases)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("phases = codex, finalize\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []PhaseSpec{{Name: PhaseCodex}, {Name: PhaseFinalize}}, values.Phases, "local overrides global")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("phases = task, lint\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, `invalid phases: unknown phase "lint"`)
+}
+
+func TestValuesLoader_Load_WarnPromptChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.WarnPromptChange, "disabled by default")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("warn_prompt_change = true\n"), 0o600))
+	values, err = loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.WarnPromptChange)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("warn_prompt_change = false\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.WarnPromptChange, "local false overrides global")
+	assert.True(t, values.WarnPromptChangeSet)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("warn_prompt_change = maybe\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid warn_prompt_change")
+}
+
+func TestValuesLoader_Load_NeedsInputTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Equal(t, 600000, values.NeedsInputTimeoutMs, "embedded default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("needs_input_timeout_ms = 0\n"), 0o600))
+	values, err = loader.Load(localConfig, "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, values.NeedsInputTimeoutMs)
+	assert.True(t, values.NeedsInputTimeoutMsSet)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("needs_input_timeout_ms = -1\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid needs_input_timeout_ms: must be non-negative")
+}
+
+func TestValuesLoader_Load_AccessibleOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.AccessibleOutput, "disabled by default")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("accessible_output = true\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("max_iterations = 5\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.AccessibleOutput, "global kept when local doesn't set it")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("accessible_output = false\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.AccessibleOutput, "local false overrides global")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("accessible_output = loud\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid accessible_output")
+}
+
+func TestValuesLoader_Load_TelemetryEndpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.TelemetryEndpoint, "no endpoint by default")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("telemetry_endpoint = https://stats.example.com/ralphex\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("telemetry_endpoint =\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "https://stats.example.com/ralphex", values.TelemetryEndpoint, "empty local value keeps global")
+}
+
+func TestValuesLoader_Load_LogShipDestination(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.LogShipDestination, "shipping off by default")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("log_ship_destination = loki://loki:3100\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("log_ship_destination = syslog://logs.local:514\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "syslog://logs.local:514", values.LogShipDestination, "local overrides global")
+}
+
+func TestValuesLoader_Load_RepeatUntilClean(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Zero(t, values.RepeatUntilClean)
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("repeat_until_clean = 3\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("repeat_until_clean = 0\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Zero(t, values.RepeatUntilClean, "explicit local 0 overrides global")
+	assert.True(t, values.RepeatUntilCleanSet)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("repeat_until_clean = -1\n"), 0o600))
+	_, err = loader.Load(localConfig, globalConfig)
+	require.ErrorContains(t, err, "invalid repeat_until_clean: must be non-negative")
+}
+
+func TestValuesLoader_Load_TimeLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Zero(t, values.TaskPhaseTimeoutMs)
+	assert.Zero(t, values.ReviewPhaseTimeoutMs)
+	assert.Zero(t, values.CodexPhaseTimeoutMs)
+
+	require.NoError(t, os.WriteFile(globalConfig,
+		[]byte("task_phase_timeout_ms = 3600000\nreview_phase_timeout_ms = 600000\ncodex_phase_timeout_ms = 900000\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("review_phase_timeout_ms = 0\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 3600000, values.TaskPhaseTimeoutMs)
+	assert.Zero(t, values.ReviewPhaseTimeoutMs, "explicit local 0 overrides global")
+	assert.True(t, values.ReviewPhaseTimeoutMsSet)
+	assert.Equal(t, 900000, values.CodexPhaseTimeoutMs)
+	assert.Zero(t, values.MaxRunDurationMs)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("max_run_duration_ms = 28800000\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 28800000, values.MaxRunDurationMs)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("codex_phase_timeout_ms = -5\n"), 0o600))
+	_, err = loader.Load(localConfig, globalConfig)
+	require.ErrorContains(t, err, "invalid codex_phase_timeout_ms: must be non-negative, got -5")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("task_phase_timeout_ms = 1h\n"), 0o600))
+	_, err = loader.Load(localConfig, globalConfig)
+	require.ErrorContains(t, err, "invalid task_phase_timeout_ms")
+}
+
+func TestValuesLoader_Load_ArtifactLocation(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.ArtifactLocation, "repository layout by default")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("artifact_location = User\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("artifact_location =\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, ArtifactsUser, values.ArtifactLocation, "empty local value keeps global")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("artifact_location = home\n"), 0o600))
+	_, err = loader.Load(localConfig, globalConfig)
+	require.ErrorContains(t, err, `invalid artifact_location: "home"`)
+}
+
 func TestValues_mergeFrom_DefaultBranch(t *testing.T) {
 	t.Run("merge default branch", func(t *testing.T) {
 		dst := Values{DefaultBranch: "main"}
@@ -1226,3 +1687,685 @@ func TestValues_mergeFrom_ExternalReviewFields(t *testing.T) {
 		assert.Equal(t, "/old/script.sh", dst.CustomReviewScript)
 	})
 }
+
+func TestValuesLoader_Load_Hooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, buildHooks(values), "no hooks by default")
+
+	require.NoError(t, os.WriteFile(globalConfig,
+		[]byte("hook_pre_review = go generate ./...\nhook_post_task = gofmt -w .\nhooks_required = pre_review\n"), 0o600))
+	values, err = loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Hook{
+		HookPreReview: {Command: "go generate ./...", Required: true},
+		HookPostTask:  {Command: "gofmt -w ."},
+	}, buildHooks(values))
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("hook_post_task =\nhooks_required =\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Hook{HookPreReview: {Command: "go generate ./..."}}, buildHooks(values),
+		"local disables a hook and clears required")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("hooks_required = pre_lint\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, `invalid hooks_required: unknown hook "pre_lint"`)
+}
+
+func TestValuesLoader_Load_ExecutorRetry(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, values.ExecutorRetryCount)
+	assert.Equal(t, 10000, values.ExecutorRetryDelayMs)
+	assert.Equal(t, 120000, values.ExecutorRetryMaxDelayMs)
+	assert.InDelta(t, 0.2, values.ExecutorRetryJitter, 1e-9)
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("executor_retry_count = 5\nexecutor_retry_jitter = 0\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("executor_retry_count = 0\nexecutor_retry_delay_ms = 500\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 0, values.ExecutorRetryCount, "local disables retries")
+	assert.Equal(t, 500, values.ExecutorRetryDelayMs)
+	assert.Equal(t, 120000, values.ExecutorRetryMaxDelayMs)
+	assert.Zero(t, values.ExecutorRetryJitter)
+
+	for _, tc := range []struct{ content, wantErr string }{
+		{"executor_retry_count = -1\n", "invalid executor_retry_count: must be non-negative"},
+		{"executor_retry_delay_ms = soon\n", "invalid executor_retry_delay_ms"},
+		{"executor_retry_jitter = 1.5\n", "invalid executor_retry_jitter: must be between 0 and 1"},
+	} {
+		require.NoError(t, os.WriteFile(localConfig, []byte(tc.content), 0o600))
+		_, err = loader.Load(localConfig, "")
+		require.ErrorContains(t, err, tc.wantErr)
+	}
+}
+
+func TestValuesLoader_Load_RequireFindingResolution(t *testing.T) {
+	localConfig := filepath.Join(t.TempDir(), "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.True(t, values.RequireFindingResolution, "enabled by default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("require_finding_resolution = false\n"), 0o600))
+	values, err = loader.Load(localConfig, "")
+	require.NoError(t, err)
+	assert.False(t, values.RequireFindingResolution)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("require_finding_resolution = maybe\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid require_finding_resolution")
+}
+
+func TestValuesLoader_Load_PhaseErrorPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.OnCodexError, "abort by default")
+	assert.Empty(t, values.OnReviewError)
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("on_codex_error = Skip\non_review_error = retry\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("on_review_error = abort\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, OnErrorSkip, values.OnCodexError)
+	assert.Equal(t, OnErrorAbort, values.OnReviewError, "local overrides global")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("on_codex_error = ignore\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, `invalid on_codex_error: "ignore", use abort, skip or retry`)
+}
+
+func TestValuesLoader_Load_VerifyReviewDone(t *testing.T) {
+	localConfig := filepath.Join(t.TempDir(), "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.True(t, values.VerifyReviewDone, "enabled by default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("verify_review_done = false\n"), 0o600))
+	values, err = loader.Load(localConfig, "")
+	require.NoError(t, err)
+	assert.False(t, values.VerifyReviewDone)
+}
+
+func TestValuesLoader_Load_PostReviewSkip(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.PostReviewSkipSeverity)
+	assert.Zero(t, values.PostReviewSkipFindings)
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("post_review_skip_severity = Major\npost_review_skip_findings = 3\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("post_review_skip_severity = none\npost_review_skip_findings = 0\n"), 0o600))
+	values, err = loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, SeverityMajor, values.PostReviewSkipSeverity)
+	assert.Equal(t, 3, values.PostReviewSkipFindings)
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "none", values.PostReviewSkipSeverity, "local disables the skip")
+	assert.Zero(t, values.PostReviewSkipFindings)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("post_review_skip_severity = trivial\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, `invalid post_review_skip_severity: "trivial", use none or info, minor, major, critical`)
+}
+
+func TestValuesLoader_Load_ParallelFirstReview(t *testing.T) {
+	localConfig := filepath.Join(t.TempDir(), "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.ParallelFirstReview, "disabled by default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("parallel_first_review = true\n"), 0o600))
+	values, err = loader.Load(localConfig, "")
+	require.NoError(t, err)
+	assert.True(t, values.ParallelFirstReview)
+}
+
+func TestValuesLoader_Load_ConsensusReview(t *testing.T) {
+	localConfig := filepath.Join(t.TempDir(), "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.ConsensusReview, "disabled by default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("consensus_review = true\n"), 0o600))
+	values, err = loader.Load(localConfig, "")
+	require.NoError(t, err)
+	assert.True(t, values.ConsensusReview)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("consensus_review = maybe\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid consensus_review")
+}
+
+func TestValuesLoader_Load_CodexSplit(t *testing.T) {
+	localConfig := filepath.Join(t.TempDir(), "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, values.CodexSplitDirs, "disabled by default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("codex_split_dirs = 4\ncodex_split_concurrency = 2\n"), 0o600))
+	values, err = loader.Load(localConfig, "")
+	require.NoError(t, err)
+	assert.Equal(t, 4, values.CodexSplitDirs)
+	assert.Equal(t, 2, values.CodexSplitConcurrency)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("codex_split_dirs = -1\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid codex_split_dirs: must be non-negative")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("codex_split_concurrency = 0\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid codex_split_concurrency: must be at least 1")
+}
+
+func TestValuesLoader_Load_MilestoneReviews(t *testing.T) {
+	localConfig := filepath.Join(t.TempDir(), "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.MilestoneReviews, "disabled by default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("milestone_reviews = true\n"), 0o600))
+	values, err = loader.Load(localConfig, "")
+	require.NoError(t, err)
+	assert.True(t, values.MilestoneReviews)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("milestone_reviews = often\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid milestone_reviews")
+}
+
+func TestValuesLoader_Load_Stall(t *testing.T) {
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, values.StallIterations)
+	assert.InDelta(t, 0.9, values.StallSimilarity, 1e-9)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "disabled", content: "stall_iterations = 0\nstall_similarity = 1\n"},
+		{name: "negative iterations", content: "stall_iterations = -1\n", wantErr: "must be non-negative"},
+		{name: "similarity out of range", content: "stall_similarity = 1.5\n", wantErr: "must be between 0 and 1"},
+		{name: "bad similarity", content: "stall_similarity = high\n", wantErr: "invalid stall_similarity"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			localConfig := filepath.Join(t.TempDir(), "local")
+			require.NoError(t, os.WriteFile(localConfig, []byte(tc.content), 0o600))
+			values, err := loader.Load(localConfig, "")
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 0, values.StallIterations)
+			assert.InDelta(t, 1.0, values.StallSimilarity, 1e-9)
+		})
+	}
+}
+
+func TestValuesLoader_Load_Disk(t *testing.T) {
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Equal(t, 1024, values.DiskMinFreeMB)
+	assert.Equal(t, 0, values.DiskMaxGrowthMB)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "set", content: "disk_min_free_mb = 0\ndisk_max_growth_mb = 500\n"},
+		{name: "negative free", content: "disk_min_free_mb = -1\n", wantErr: "invalid disk_min_free_mb: must be non-negative"},
+		{name: "bad growth", content: "disk_max_growth_mb = lots\n", wantErr: "invalid disk_max_growth_mb"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			localConfig := filepath.Join(t.TempDir(), "local")
+			require.NoError(t, os.WriteFile(localConfig, []byte(tc.content), 0o600))
+			values, err := loader.Load(localConfig, "")
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 0, values.DiskMinFreeMB)
+			assert.Equal(t, 500, values.DiskMaxGrowthMB)
+		})
+	}
+}
+
+func TestValuesLoader_Load_GoModGate(t *testing.T) {
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.GoModGate)
+	assert.False(t, values.GoModBlockNewDeps)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "set", content: "gomod_gate = Feedback\ngomod_block_new_deps = true\n"},
+		{name: "bad gate", content: "gomod_gate = strict\n", wantErr: `invalid gomod_gate: "strict", use off, feedback or fail`},
+		{name: "bad block", content: "gomod_block_new_deps = maybe\n", wantErr: "invalid gomod_block_new_deps"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			localConfig := filepath.Join(t.TempDir(), "local")
+			require.NoError(t, os.WriteFile(localConfig, []byte(tc.content), 0o600))
+			values, err := loader.Load(localConfig, "")
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, GoModGateFeedback, values.GoModGate)
+			assert.True(t, values.GoModBlockNewDeps)
+		})
+	}
+}
+
+func TestValuesLoader_Load_DepPolicy(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("deps_allow = golang.org/x/..., github.com/acme/*\ndeps_deny = github.com/bad/lib\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("deps_deny = github.com/acme/legacy github.com/bad/...\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"golang.org/x/...", "github.com/acme/*"}, values.DepsAllow, "global allowlist kept")
+	assert.Equal(t, []string{"github.com/acme/legacy", "github.com/bad/..."}, values.DepsDeny, "local denylist wins")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("deps_allow = github.com/[bad\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, `invalid deps_allow: bad module pattern "github.com/[bad"`)
+}
+
+func TestValuesLoader_Load_RollbackOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("rollback_on_failure = run\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("rollback_on_failure = Iteration\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "iteration", values.RollbackOnFailure, "local wins")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.RollbackOnFailure, "off by default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("rollback_on_failure = all\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, `invalid rollback_on_failure: "all", use off, run or iteration`)
+}
+
+func TestValuesLoader_Load_CompletedPlan(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("completed_plan = run\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("completed_plan = Exit\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "exit", values.CompletedPlan, "local wins")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.CompletedPlan, "review by default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("completed_plan = skip\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, `invalid completed_plan: "skip", use run, review or exit`)
+}
+
+func TestValuesLoader_Load_GenerateGate(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("generate_gate = feedback\ngenerate_commands = go generate ./..., buf generate\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("generate_gate = Fail\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "fail", values.GenerateGate, "local wins")
+	assert.Equal(t, []string{"go generate ./...", "buf generate"}, values.GenerateCommands, "global commands kept")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("generate_gate = always\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, `invalid generate_gate: "always", use off, feedback or fail`)
+}
+
+func TestValuesLoader_Load_License(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("license_check = warn\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("license_check = FAIL\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "fail", values.LicenseCheck, "local wins")
+	assert.Equal(t, []string{"MIT", "Apache-2.0", "BSD-2-Clause", "BSD-3-Clause", "ISC", "Unlicense"}, values.LicenseAllow, "embedded defaults")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("license_allow = MIT MPL-2.0\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "warn", values.LicenseCheck)
+	assert.Equal(t, []string{"MIT", "MPL-2.0"}, values.LicenseAllow)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("license_check = strict\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, `invalid license_check: "strict", use off, warn or fail`)
+}
+
+func TestValuesLoader_Load_BudgetExtension(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("budget_extension_factor = 1.5\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.5, values.BudgetExtensionFactor, 1e-9)
+	assert.Equal(t, 2, values.BudgetMaxExtensions, "embedded default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("budget_extension_factor = 0\nbudget_max_extensions = 5\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Zero(t, values.BudgetExtensionFactor, "local disables")
+	assert.Equal(t, 5, values.BudgetMaxExtensions)
+
+	for content, wantErr := range map[string]string{
+		"budget_extension_factor = 0.5\n": "invalid budget_extension_factor: must be 0 or greater than 1, got 0.5",
+		"budget_extension_factor = x\n":   "invalid budget_extension_factor",
+		"budget_max_extensions = -1\n":    "invalid budget_max_extensions: must be non-negative, got -1",
+	} {
+		require.NoError(t, os.WriteFile(localConfig, []byte(content), 0o600))
+		_, err = loader.Load(localConfig, "")
+		require.ErrorContains(t, err, wantErr, content)
+	}
+}
+
+func TestValuesLoader_Load_EscalationReview(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("escalation_review = true\nescalation_codex_model = gpt-5.3-codex\n"+
+		"escalation_codex_reasoning_effort = high\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("escalation_claude_args = exec -c model_reasoning_effort=xhigh\n"+
+		"escalation_codex_reasoning_effort = xhigh\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.EscalationReview, "global kept")
+	assert.Equal(t, "exec -c model_reasoning_effort=xhigh", values.EscalationClaudeArgs)
+	assert.Equal(t, "gpt-5.3-codex", values.EscalationCodexModel)
+	assert.Equal(t, "xhigh", values.EscalationCodexReasoningEffort, "local wins")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("escalation_review = false\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.EscalationReview, "local disables")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("escalation_review = maybe\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid escalation_review")
+}
+
+func TestValuesLoader_Load_FinalVerification(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("final_verification = true\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("escalation_review = true\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.FinalVerification, "global kept")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("final_verification = false\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.FinalVerification, "local disables")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("final_verification = maybe\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid final_verification")
+}
+
+func TestValuesLoader_Load_FormatGate(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("format_gate = feedback\nformatter_py = black -q\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("format_gate = Fix\nformatter_go = gofumpt -w\nformatter_py =\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "fix", values.FormatGate, "local wins")
+	assert.Equal(t, map[string]string{"go": "gofumpt -w", "py": ""}, values.FormatterCommands)
+	assert.Equal(t, map[string]string{"go": "gofumpt -w"}, buildCommands(values.FormatterCommands), "disabled formatter dropped")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.FormatGate)
+	assert.Equal(t, map[string]string{"go": "gofmt -w"}, values.FormatterCommands, "embedded defaults")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("format_gate = always\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, `invalid format_gate: "always", use off, fix or feedback`)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("formatter_.Go = gofmt -w\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid formatter_.Go: file extension must be lowercase")
+}
+
+func TestValuesLoader_Load_Analyzers(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("analyzer_semgrep = semgrep-json.sh\nanalyzer_org-scan = scan --json\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("analyzer_org-scan =\nanalyzer_lint_2 = lint.sh\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"semgrep": "semgrep-json.sh", "org-scan": "", "lint_2": "lint.sh"}, values.AnalyzerCommands)
+	assert.Equal(t, map[string]string{"semgrep": "semgrep-json.sh", "lint_2": "lint.sh"}, buildCommands(values.AnalyzerCommands),
+		"empty command disables the global analyzer")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("plugin_pager = /opt/ralphex/pager-plugin --team core\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"pager": "/opt/ralphex/pager-plugin --team core"}, values.PluginCommands)
+	assert.Len(t, values.AnalyzerCommands, 2, "plugins and analyzers are kept apart")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("analyzer_Bad.Name = x\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid analyzer_Bad.Name: analyzer name")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("plugin_ = x\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid plugin_: plugin name")
+}
+
+func TestValuesLoader_Load_TaskMaxIterations(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("task_max_iterations = 5\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("task_max_iterations = 0\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 5, values.TaskMaxIterations)
+
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 0, values.TaskMaxIterations, "local config resets to the derived budget")
+	assert.True(t, values.TaskMaxIterationsSet)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("task_max_iterations = -2\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid task_max_iterations: must be non-negative")
+}
+
+func TestValuesLoader_Load_SpellCheck(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("spell_check = true\nspell_ignore = teh, wich\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.SpellCheck, "off by default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("spell_check = false\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.SpellCheck, "local disables")
+	assert.Equal(t, []string{"teh", "wich"}, values.SpellIgnore)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("spell_check = maybe\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid spell_check")
+}
+
+func TestValuesLoader_Load_ArtifactGate(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("artifact_gate = warn\nartifact_allow = *.png, testdata/*\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("artifact_gate = Feedback\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "feedback", values.ArtifactGate, "local wins")
+	assert.Equal(t, 1024, values.ArtifactMaxSizeKB, "embedded default")
+	assert.Equal(t, []string{"*.png", "testdata/*"}, values.ArtifactAllow)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("artifact_max_size_kb = 0\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "warn", values.ArtifactGate)
+	assert.Zero(t, values.ArtifactMaxSizeKB, "binary files only")
+
+	for content, wantErr := range map[string]string{
+		"artifact_gate = block\n":       `invalid artifact_gate: "block", use off, warn, feedback or fail`,
+		"artifact_max_size_kb = -1\n":   "invalid artifact_max_size_kb: must be non-negative, got -1",
+		"artifact_max_size_kb = many\n": "invalid artifact_max_size_kb",
+	} {
+		require.NoError(t, os.WriteFile(localConfig, []byte(content), 0o600))
+		_, err = loader.Load(localConfig, "")
+		require.ErrorContains(t, err, wantErr)
+	}
+}
+
+func TestValuesLoader_Load_Instructions(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig, localConfig := filepath.Join(dir, "global"), filepath.Join(dir, "local")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("task_instructions = use testify\nreview_instructions = check docs\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("task_instructions = \"\"\"always run make lint\nnever edit vendor/\"\"\"\n"+
+		"codex_instructions = ignore generated files\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "always run make lint\nnever edit vendor/", values.TaskInstructions, "local wins, multi-line")
+	assert.Equal(t, "check docs", values.ReviewInstructions)
+	assert.Equal(t, "ignore generated files", values.CodexInstructions)
+	assert.Empty(t, values.Language)
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("language = German\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("language =  pt-BR \n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "pt-BR", values.Language, "local wins, trimmed")
+}
+
+func TestValuesLoader_Load_ContinueSession(t *testing.T) {
+	localConfig := filepath.Join(t.TempDir(), "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.ContinueSession, "disabled by default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("continue_session = true\n"), 0o600))
+	values, err = loader.Load(localConfig, "")
+	require.NoError(t, err)
+	assert.True(t, values.ContinueSession)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("continue_session = sometimes\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid continue_session")
+}
+
+func TestValuesLoader_Load_RunWindow(t *testing.T) {
+	dir := t.TempDir()
+	globalConfig := filepath.Join(dir, "global")
+	localConfig := filepath.Join(dir, "local")
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.True(t, values.RunWindow.IsZero(), "any time by default")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("run_window = 22:00-06:00\n"), 0o600))
+	values, err = loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "22:00-06:00", values.RunWindow.String())
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("run_window =\n"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.RunWindow.IsZero(), "local config clears the global window")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("run_window = nightly\n"), 0o600))
+	_, err = loader.Load(localConfig, "")
+	require.ErrorContains(t, err, "invalid run_window")
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RunWindow is the time of day runs are allowed in, configured as "HH:MM-HH:MM" in local time, e.g.
+// "22:00-06:00" for overnight runs. an end before the start spans midnight. the zero value allows any time.
+type RunWindow struct {
+	Start time.Duration `json:"start"` // time of day the window opens, from midnight
+	End   time.Duration `json:"end"`   // time of day the window closes, from midnight
+}
+
+// ParseRunWindow parses a run window, "HH:MM-HH:MM". an empty string is the zero window.
+func ParseRunWindow(s string) (RunWindow, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return RunWindow{}, nil
+	}
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return RunWindow{}, fmt.Errorf("%q is not a HH:MM-HH:MM range", s)
+	}
+	var w RunWindow
+	var err error
+	if w.Start, err = parseTimeOfDay(start); err != nil {
+		return RunWindow{}, err
+	}
+	if w.End, err = parseTimeOfDay(end); err != nil {
+		return RunWindow{}, err
+	}
+	if w.Start == w.End {
+		return RunWindow{}, fmt.Errorf("%q opens and closes at the same time", s)
+	}
+	return w, nil
+}
+
+// parseTimeOfDay parses "HH:MM" as the time from midnight
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, use HH:MM", strings.TrimSpace(s))
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// IsZero reports whether the window is unset, allowing any time.
+func (w RunWindow) IsZero() bool {
+	return w.Start == w.End
+}
+
+// String returns the window as configured, empty for the zero window.
+func (w RunWindow) String() string {
+	if w.IsZero() {
+		return ""
+	}
+	format := func(d time.Duration) string { return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60) }
+	return format(w.Start) + "-" + format(w.End)
+}
+
+// Contains reports whether t is within the window, always true for the zero window.
+func (w RunWindow) Contains(t time.Time) bool {
+	if w.IsZero() {
+		return true
+	}
+	tod := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.Start < w.End {
+		return tod >= w.Start && tod < w.End
+	}
+	return tod >= w.Start || tod < w.End
+}
+
+// NextOpen returns when the window opens next, t itself if t is within the window.
+func (w RunWindow) NextOpen(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+	y, m, d := t.Date()
+	open := time.Date(y, m, d, int(w.Start.Hours()), int(w.Start.Minutes())%60, 0, 0, t.Location())
+	if open.Before(t) {
+		open = time.Date(y, m, d+1, int(w.Start.Hours()), int(w.Start.Minutes())%60, 0, 0, t.Location())
+	}
+	return open
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRunWindow(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    RunWindow
+		wantErr string
+	}{
+		{in: "", want: RunWindow{}},
+		{in: "22:00-06:00", want: RunWindow{Start: 22 * time.Hour, End: 6 * time.Hour}},
+		{in: " 9:30 - 17:45 ", want: RunWindow{Start: 9*time.Hour + 30*time.Minute, End: 17*time.Hour + 45*time.Minute}},
+		{in: "22:00", wantErr: "not a HH:MM-HH:MM range"},
+		{in: "22:00-25:00", wantErr: `invalid time of day "25:00"`},
+		{in: "night-06:00", wantErr: `invalid time of day "night"`},
+		{in: "06:00-06:00", wantErr: "opens and closes at the same time"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			w, err := ParseRunWindow(tc.in)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, w)
+		})
+	}
+}
+
+func TestRunWindow(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 10, 16, h, m, 0, 0, time.UTC) }
+	overnight := RunWindow{Start: 22 * time.Hour, End: 6 * time.Hour}
+	workday := RunWindow{Start: 9 * time.Hour, End: 17*time.Hour + 30*time.Minute}
+
+	assert.Equal(t, "22:00-06:00", overnight.String())
+	assert.Equal(t, "09:00-17:30", workday.String())
+	assert.Empty(t, RunWindow{}.String())
+
+	assert.True(t, overnight.Contains(at(23, 0)))
+	assert.True(t, overnight.Contains(at(5, 59)))
+	assert.True(t, overnight.Contains(at(22, 0)), "start is inside")
+	assert.False(t, overnight.Contains(at(6, 0)), "end is outside")
+	assert.False(t, overnight.Contains(at(12, 0)))
+	assert.True(t, workday.Contains(at(12, 0)))
+	assert.False(t, workday.Contains(at(17, 30)))
+	assert.True(t, RunWindow{}.Contains(at(12, 0)), "zero window allows any time")
+
+	assert.Equal(t, at(23, 0), overnight.NextOpen(at(23, 0)), "open now")
+	assert.Equal(t, at(22, 0), overnight.NextOpen(at(12, 0)), "later today")
+	assert.Equal(t, at(9, 0).AddDate(0, 0, 1), workday.NextOpen(at(18, 0)), "tomorrow")
+}
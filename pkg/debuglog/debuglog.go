@@ -0,0 +1,112 @@
+// Package debuglog provides per-subsystem debug toggles, so one area can be debugged
+// without the output of all others, e.g. signal detection without full stream dumps.
+package debuglog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Subsystem names a debuggable area.
+type Subsystem string
+
+// debuggable subsystems
+const (
+	ExecutorIO Subsystem = "executor-io" // raw output lines of the agent CLIs
+	Prompts    Subsystem = "prompts"     // prompts sent to the agents
+	Signals    Subsystem = "signals"     // signals detected in agent output
+	Git        Subsystem = "git"         // git commands run
+	Processor  Subsystem = "processor"   // runner decisions: iterations, phases, retries
+)
+
+// All lists the subsystems in display order.
+var All = []Subsystem{ExecutorIO, Prompts, Signals, Git, Processor}
+
+// out is where debug lines are written, replaced in tests
+var out io.Writer = os.Stdout
+
+// Flags holds the enabled subsystems. the zero value has debugging off.
+type Flags struct {
+	ExecutorIO bool
+	Prompts    bool
+	Signals    bool
+	Git        bool
+	Processor  bool
+}
+
+// Parse builds flags from subsystem names. each value may hold comma-separated names,
+// "all" enables every subsystem.
+func Parse(values []string) (Flags, error) {
+	var f Flags
+	for _, v := range values {
+		for name := range strings.SplitSeq(v, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			switch Subsystem(name) {
+			case "":
+			case "all":
+				f = Flags{ExecutorIO: true, Prompts: true, Signals: true, Git: true, Processor: true}
+			case ExecutorIO:
+				f.ExecutorIO = true
+			case Prompts:
+				f.Prompts = true
+			case Signals:
+				f.Signals = true
+			case Git:
+				f.Git = true
+			case Processor:
+				f.Processor = true
+			default:
+				return Flags{}, fmt.Errorf("unknown debug subsystem %q, use all or one of: %s", name, names())
+			}
+		}
+	}
+	return f, nil
+}
+
+// Enabled reports whether debugging of subsystem s is on.
+func (f Flags) Enabled(s Subsystem) bool {
+	switch s {
+	case ExecutorIO:
+		return f.ExecutorIO
+	case Prompts:
+		return f.Prompts
+	case Signals:
+		return f.Signals
+	case Git:
+		return f.Git
+	case Processor:
+		return f.Processor
+	default:
+		return false
+	}
+}
+
+// String returns the enabled subsystems comma-separated, empty if none.
+func (f Flags) String() string {
+	var res []string
+	for _, s := range All {
+		if f.Enabled(s) {
+			res = append(res, string(s))
+		}
+	}
+	return strings.Join(res, ",")
+}
+
+// Printf writes a debug line prefixed with the subsystem name if debugging of s is on.
+func (f Flags) Printf(s Subsystem, format string, args ...any) {
+	if !f.Enabled(s) {
+		return
+	}
+	fmt.Fprintf(out, "[debug:%s] %s\n", s, fmt.Sprintf(format, args...))
+}
+
+// names returns all subsystem names comma-separated
+func names() string {
+	res := make([]string, 0, len(All))
+	for _, s := range All {
+		res = append(res, string(s))
+	}
+	return strings.Join(res, ", ")
+}
@@ -0,0 +1,54 @@
+package debuglog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    Flags
+		wantErr string
+	}{
+		{name: "none", values: nil, want: Flags{}},
+		{name: "all", values: []string{"all"}, want: Flags{ExecutorIO: true, Prompts: true, Signals: true, Git: true, Processor: true}},
+		{name: "comma separated", values: []string{"signals, GIT"}, want: Flags{Signals: true, Git: true}},
+		{name: "repeated", values: []string{"prompts", "executor-io", ""}, want: Flags{Prompts: true, ExecutorIO: true}},
+		{name: "unknown", values: []string{"signals,network"},
+			wantErr: `unknown debug subsystem "network", use all or one of: executor-io, prompts, signals, git, processor`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Parse(tc.values)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, f)
+		})
+	}
+}
+
+func TestFlags_String(t *testing.T) {
+	assert.Empty(t, Flags{}.String())
+	assert.Equal(t, "prompts,processor", Flags{Processor: true, Prompts: true}.String())
+}
+
+func TestFlags_Printf(t *testing.T) {
+	var buf bytes.Buffer
+	orig := out
+	out = &buf
+	defer func() { out = orig }()
+
+	f := Flags{Signals: true}
+	f.Printf(Signals, "detected %s", "COMPLETED")
+	f.Printf(Git, "git status")
+	assert.Equal(t, "[debug:signals] detected COMPLETED\n", buf.String())
+	assert.False(t, f.Enabled(Subsystem("other")))
+}
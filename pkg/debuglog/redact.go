@@ -0,0 +1,82 @@
+package debuglog
+
+import (
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// redacted replaces secrets in redacted text
+const redacted = "[REDACTED]"
+
+// minSecretLen is the length below which literal secrets are not masked, short values would mask common words
+const minSecretLen = 6
+
+var (
+	// secretPatterns match well-known credential formats
+	secretPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+		regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),                // anthropic, openai
+		regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}`),           // github
+		regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}`),         // github fine-grained
+		regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),                   // aws access key id
+		regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),         // slack
+		regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{8,}=*`), // authorization headers
+	}
+	// assignmentRe matches secret-looking assignments, the value is masked and the key kept.
+	// the key may be quoted, as in JSON. values can't start with "=", so code like "token := x" and "token == y"
+	// stays intact
+	assignmentRe = regexp.MustCompile(`(?i)\b([\w-]*(?:password|passwd|secret|token|api[_-]?key)[\w-]*["']?)(\s*[:=]\s*)("[^"\n]*"|'[^'\n]*'|[^=\s"',;][^\s"',;]*)`)
+	// secretEnvRe matches names of environment variables holding secrets
+	secretEnvRe = regexp.MustCompile(`(?i)(key|token|secret|password|passwd|credential)`)
+)
+
+// Redactor masks secrets in text before it is written to debug dumps: well-known credential formats,
+// secret-looking assignments and literal secret values it was created with.
+type Redactor struct {
+	secrets []string
+}
+
+// NewRedactor creates a Redactor masking the given literal secrets in addition to the built-in patterns.
+// empty and very short values are ignored.
+func NewRedactor(secrets ...string) *Redactor {
+	r := &Redactor{}
+	for _, s := range secrets {
+		if s = strings.TrimSpace(s); len(s) >= minSecretLen && !slices.Contains(r.secrets, s) {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+	// longest first, so a secret containing another one is masked whole
+	slices.SortFunc(r.secrets, func(a, b string) int { return len(b) - len(a) })
+	return r
+}
+
+// Redact returns s with secrets replaced by [REDACTED].
+func (r *Redactor) Redact(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllString(s, redacted)
+	}
+	return assignmentRe.ReplaceAllStringFunc(s, func(m string) string {
+		sub := assignmentRe.FindStringSubmatch(m)
+		if sub[3] == redacted {
+			return m
+		}
+		return sub[1] + sub[2] + redacted
+	})
+}
+
+// EnvSecrets returns values of environment variables whose names suggest secrets, e.g. ANTHROPIC_API_KEY.
+func EnvSecrets() []string {
+	var res []string
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if ok && value != "" && secretEnvRe.MatchString(name) {
+			res = append(res, value)
+		}
+	}
+	return res
+}
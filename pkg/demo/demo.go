@@ -0,0 +1,199 @@
+// Package demo provides the scripted project behind "ralphex demo", a full run
+// (task → review → codex → review) that needs no agent CLIs, git or network access.
+package demo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// PlanFile is the demo plan, relative to the project root.
+const PlanFile = "docs/plans/greeting.md"
+
+const planContent = `# Greeting helper
+
+Add a small greeting package.
+
+## Validation Commands
+- ` + "`go test ./...`" + `
+
+### Task 1: Add greeting function
+- [ ] create greet/greet.go with Greet(name string) string
+- [ ] return "hello, <name>"
+
+### Task 2: Add tests
+- [ ] create greet/greet_test.go covering regular and padded names
+`
+
+const greetV1 = `package greet
+
+// Greet returns a greeting for name.
+func Greet(name string) string {
+	return "hello, " + name
+}
+`
+
+const greetV2 = `package greet
+
+import "strings"
+
+// Greet returns a greeting for name, surrounding spaces are ignored.
+func Greet(name string) string {
+	return "hello, " + strings.TrimSpace(name)
+}
+`
+
+const greetV3 = `package greet
+
+import "strings"
+
+// Greet returns a greeting for name, surrounding spaces are ignored.
+// an empty name greets a stranger.
+func Greet(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "stranger"
+	}
+	return "hello, " + name
+}
+`
+
+const greetTest = `package greet
+
+import "testing"
+
+func TestGreet(t *testing.T) {
+	for in, want := range map[string]string{"bob": "hello, bob", " ann ": "hello, ann"} {
+		if got := Greet(in); got != want {
+			t.Errorf("Greet(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+`
+
+// Project is a scratch project the demo run works on, tracked by an in-memory git repository.
+type Project struct {
+	Dir  string
+	Repo *git.MemoryRepo
+}
+
+// NewProject writes the demo plan and go.mod into dir and commits them to a fresh in-memory repository.
+func NewProject(dir string) (*Project, error) {
+	p := &Project{Dir: dir, Repo: git.NewMemoryRepo(dir, "master")}
+	if err := p.write("go.mod", "module example.com/greeting\n\ngo 1.22\n"); err != nil {
+		return nil, err
+	}
+	if err := p.write(PlanFile, planContent); err != nil {
+		return nil, err
+	}
+	if err := p.Repo.CreateInitialCommit("initial commit"); err != nil {
+		return nil, fmt.Errorf("initial commit: %w", err)
+	}
+	return p, nil
+}
+
+// Executors returns scripted claude and codex executors playing the agents of the demo run.
+// scripted claude edits project files, checks off plan items and commits, as a real agent would.
+func (p *Project) Executors(out func(string), lineDelay time.Duration) (claude, codex *executor.ScriptedExecutor) {
+	claude = &executor.ScriptedExecutor{OutputHandler: out, LineDelay: lineDelay, Steps: []executor.ScriptStep{
+		{
+			Output: "reading plan " + PlanFile + "\nworking on Task 1: Add greeting function\n" +
+				"created greet/greet.go\nmarked Task 1 done in the plan\ncommitted: add greeting function",
+			Action: p.change("add greeting function", 1, "greet/greet.go", greetV1),
+		},
+		{
+			Output: "working on Task 2: Add tests\ncreated greet/greet_test.go\nran go test ./... - ok\n" +
+				"marked Task 2 done in the plan\ncommitted: add greeting tests\nall tasks are complete",
+			Signal: status.Completed,
+			Action: p.change("add greeting tests", 2, "greet/greet_test.go", greetTest),
+		},
+		{
+			Output: "reviewed 2 changed files with 5 review agents\n" +
+				"found 1 issue: greet/greet.go:5 padded names are greeted with spaces, the test expects them trimmed\n" +
+				"fixed, committed: trim greeted name",
+			Signal: status.ReviewDone,
+			Action: p.change("trim greeted name", 0, "greet/greet.go", greetV2),
+		},
+		{Output: "reviewed changes for critical and major issues\nno issues found", Signal: status.ReviewDone},
+		{
+			Output: "codex finding is valid: an empty name produces \"hello, \"\n" +
+				"fixed, Greet falls back to \"stranger\"\ncommitted: greet stranger on empty name",
+			Action: p.change("greet stranger on empty name", 0, "greet/greet.go", greetV3),
+		},
+		{Output: "codex found no remaining issues", Signal: status.CodexDone},
+		{Output: "reviewed codex fixes for critical and major issues\nno issues found", Signal: status.ReviewDone},
+	}}
+	codex = &executor.ScriptedExecutor{OutputHandler: out, LineDelay: lineDelay, Steps: []executor.ScriptStep{
+		{Output: "greet/greet.go:7: Greet(\"\") returns \"hello, \" with a dangling separator, consider a default name"},
+		{Output: "NO ISSUES FOUND"},
+	}}
+	return claude, codex
+}
+
+// change returns an action writing content to file, checking off the task (0 for none) and committing both
+func (p *Project) change(msg string, task int, file, content string) func() error {
+	return func() error {
+		if err := p.write(file, content); err != nil {
+			return err
+		}
+		if err := p.Repo.Add(file); err != nil {
+			return fmt.Errorf("stage %s: %w", file, err)
+		}
+		if task > 0 {
+			if err := p.completeTask(task); err != nil {
+				return err
+			}
+		}
+		if err := p.Repo.Commit(msg); err != nil {
+			return fmt.Errorf("commit: %w", err)
+		}
+		return nil
+	}
+}
+
+// completeTask checks off all items of the task section and stages the plan
+func (p *Project) completeTask(task int) error {
+	path := filepath.Join(p.Dir, PlanFile)
+	data, err := os.ReadFile(path) //nolint:gosec // path is within the demo project
+	if err != nil {
+		return fmt.Errorf("read plan: %w", err)
+	}
+	lines := strings.Split(string(data), "\n")
+	inTask := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "### ") {
+			inTask = strings.HasPrefix(line, fmt.Sprintf("### Task %d:", task))
+			continue
+		}
+		if inTask {
+			lines[i] = strings.Replace(line, "- [ ]", "- [x]", 1)
+		}
+	}
+	if err := p.write(PlanFile, strings.Join(lines, "\n")); err != nil {
+		return err
+	}
+	if err := p.Repo.Add(PlanFile); err != nil {
+		return fmt.Errorf("stage plan: %w", err)
+	}
+	return nil
+}
+
+// write creates or replaces a project file and records it as changed in the repository
+func (p *Project) write(file, content string) error {
+	path := filepath.Join(p.Dir, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create dir for %s: %w", file, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", file, err)
+	}
+	p.Repo.Touch(file)
+	return nil
+}
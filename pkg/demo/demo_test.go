@@ -0,0 +1,59 @@
+package demo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestNewProject(t *testing.T) {
+	dir := t.TempDir()
+	p, err := NewProject(dir)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, PlanFile))
+	assert.FileExists(t, filepath.Join(dir, "go.mod"))
+	commits := p.Repo.Commits("master")
+	require.Len(t, commits, 1)
+	assert.Equal(t, []string{PlanFile, "go.mod"}, commits[0].Files)
+}
+
+func TestProject_Executors(t *testing.T) {
+	dir := t.TempDir()
+	p, err := NewProject(dir)
+	require.NoError(t, err)
+
+	var out []string
+	claude, codex := p.Executors(func(s string) { out = append(out, s) }, 0)
+
+	// task iterations check off the plan one task at a time
+	res := claude.Run(context.Background(), "task")
+	require.NoError(t, res.Error)
+	assert.Empty(t, res.Signal)
+	plan, err := os.ReadFile(filepath.Join(dir, PlanFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(plan), "- [x] create greet/greet.go")
+	assert.Contains(t, string(plan), "- [ ] create greet/greet_test.go")
+
+	res = claude.Run(context.Background(), "task")
+	require.NoError(t, res.Error)
+	assert.Equal(t, status.Completed, res.Signal)
+	plan, err = os.ReadFile(filepath.Join(dir, PlanFile))
+	require.NoError(t, err)
+	assert.NotContains(t, string(plan), "- [ ]")
+
+	res = codex.Run(context.Background(), "review")
+	require.NoError(t, res.Error)
+	assert.Contains(t, res.Output, "greet/greet.go:7")
+	assert.NotEmpty(t, out)
+
+	commits := p.Repo.Commits("master")
+	require.Len(t, commits, 3)
+	assert.Equal(t, "add greeting tests", commits[2].Message)
+	assert.Equal(t, []string{PlanFile, "greet/greet_test.go"}, commits[2].Files)
+}
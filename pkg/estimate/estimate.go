@@ -0,0 +1,297 @@
+// Package estimate predicts iterations, duration and cost of a plan run from the plan's tasks,
+// the files they touch and the history of past runs recorded in progress files.
+package estimate
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// minHistoryRuns is the number of past runs needed before their rates replace the defaults
+const minHistoryRuns = 3
+
+// default rates used without enough run history: iterations per unit of task weight, and time per iteration
+var (
+	defaultIterationRates = [3]float64{1.0, 1.2, 1.6} // low, likely, high
+	defaultPace           = [2]time.Duration{3 * time.Minute, 8 * time.Minute}
+)
+
+// task weight factors, a task of up to baseItems items in a single area weighs 1
+const (
+	baseItems       = 5
+	itemWeight      = 0.15 // per item above baseItems
+	areaWeight      = 0.25 // per affected area above the first
+	linesWeight     = 0.25 // per linesBlock lines of existing files the task refers to
+	linesBlock      = 1000
+	maxLinesWeight  = 1.0  // cap of the lines weight, huge files don't make a task endless
+	heavyTaskWeight = 1.75 // tasks from this weight on are suggested for splitting
+)
+
+// pathRe matches file and directory references in plan text: paths with a slash or file names with a known extension
+var pathRe = regexp.MustCompile(`(?:[\w.-]+/)+[\w.*-]*|\b[\w-]+\.(?:go|md|txt|ya?ml|json|toml|ts|tsx|js|py|sh|html|css|sql)\b`)
+
+// Run is a past run read from a progress file.
+type Run struct {
+	Path       string // progress file
+	PlanFile   string
+	Mode       string
+	Tasks      int     // tasks of the plan, 0 if the plan can't be found anymore
+	Weight     float64 // total task weight of the plan
+	Iterations int     // task iterations
+	Duration   time.Duration
+}
+
+// Params configures the estimate.
+type Params struct {
+	Root          string  // repository root, plan paths and task file references are resolved from it
+	MaxIterations int     // iteration limit of the run, exceeding it is reported
+	IterationCost float64 // cost of an iteration, 0 to skip the cost estimate
+}
+
+// Range is a low, likely and high prediction.
+type Range struct {
+	Low, Likely, High int
+}
+
+// TaskSize describes how big a task is, as used for the estimate.
+type TaskSize struct {
+	Header string
+	Items  int
+	Areas  []string // top-level directories of the files the task refers to, "." for the root
+	Lines  int      // lines of existing files the task refers to
+	Weight float64  // relative size, a plain small task weighs 1
+}
+
+// Estimate is the prediction for a plan run.
+type Estimate struct {
+	Tasks       []TaskSize
+	Areas       []string // all affected areas
+	HistoryRuns int      // past runs the rates are based on, 0 if defaults were used
+	Iterations  Range
+	Duration    [2]time.Duration // low and high
+	Cost        [2]float64       // low and high, zero if no iteration cost is set
+	Suggestions []string
+}
+
+// New estimates a run of the plan content from its tasks and past runs.
+func New(content string, history []Run, p Params) Estimate {
+	var est Estimate
+	totalWeight := 0.0
+	areas := map[string]bool{}
+	for _, t := range plan.ParseTasks(content) {
+		size := sizeTask(t, p.Root)
+		est.Tasks = append(est.Tasks, size)
+		totalWeight += size.Weight
+		for _, a := range size.Areas {
+			areas[a] = true
+		}
+	}
+	for a := range areas {
+		est.Areas = append(est.Areas, a)
+	}
+	slices.Sort(est.Areas)
+	if len(est.Tasks) == 0 {
+		est.Suggestions = append(est.Suggestions, "no tasks found, nothing to estimate")
+		return est
+	}
+
+	rates, pace, used := historyRates(history)
+	est.HistoryRuns = used
+	// each task takes at least one iteration
+	iterations := func(rate float64) int { return max(len(est.Tasks), int(math.Round(totalWeight*rate))) }
+	est.Iterations = Range{Low: iterations(rates[0]), Likely: iterations(rates[1]), High: iterations(rates[2])}
+	est.Duration = [2]time.Duration{
+		(time.Duration(est.Iterations.Low) * pace[0]).Round(time.Minute),
+		(time.Duration(est.Iterations.High) * pace[1]).Round(time.Minute),
+	}
+	if p.IterationCost > 0 {
+		est.Cost = [2]float64{float64(est.Iterations.Low) * p.IterationCost, float64(est.Iterations.High) * p.IterationCost}
+	}
+
+	if p.MaxIterations > 0 && est.Iterations.High > p.MaxIterations {
+		est.Suggestions = append(est.Suggestions, fmt.Sprintf(
+			"up to %d iterations predicted, over the limit of %d: split the plan or raise --max-iterations",
+			est.Iterations.High, p.MaxIterations))
+	}
+	for _, t := range est.Tasks {
+		if t.Weight >= heavyTaskWeight {
+			est.Suggestions = append(est.Suggestions, fmt.Sprintf("split %q: %d items across %d areas",
+				t.Header, t.Items, max(len(t.Areas), 1)))
+		}
+	}
+	return est
+}
+
+// sizeTask weighs a task by its items, the areas it touches and the size of existing files it refers to
+func sizeTask(t plan.Task, root string) TaskSize {
+	size := TaskSize{Header: t.Header, Items: len(t.Items)}
+	texts := []string{t.Header}
+	for _, it := range t.Items {
+		texts = append(texts, it.Text)
+	}
+	seen := map[string]bool{}
+	areas := map[string]bool{}
+	for _, ref := range pathRe.FindAllString(strings.Join(texts, "\n"), -1) {
+		ref = strings.TrimSuffix(ref, "/")
+		if ref == "" || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		area, _, found := strings.Cut(ref, "/")
+		if !found {
+			area = "."
+		} else if fi, err := os.Stat(filepath.Join(root, area)); err != nil || !fi.IsDir() {
+			continue // not a path in the repository, e.g. "and/or" or "pub/sub"
+		}
+		areas[area] = true
+		size.Lines += countLines(filepath.Join(root, filepath.FromSlash(ref)))
+	}
+	for a := range areas {
+		size.Areas = append(size.Areas, a)
+	}
+	slices.Sort(size.Areas)
+
+	weight := 1.0 + itemWeight*float64(max(0, size.Items-baseItems)) + areaWeight*float64(max(0, len(size.Areas)-1))
+	weight += min(maxLinesWeight, linesWeight*float64(size.Lines/linesBlock))
+	size.Weight = math.Round(weight*100) / 100
+	return size
+}
+
+// countLines returns the line count of a regular file, 0 if it doesn't exist or isn't a file
+func countLines(path string) int {
+	fi, err := os.Stat(path)
+	if err != nil || !fi.Mode().IsRegular() {
+		return 0
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path referenced by the plan, read-only
+	if err != nil {
+		return 0
+	}
+	return strings.Count(string(data), "\n")
+}
+
+// historyRates returns iterations per task weight (low, likely, high) and time per iteration (low, high)
+// from past runs, or the defaults if there are fewer than minHistoryRuns usable runs.
+func historyRates(history []Run) (rates [3]float64, pace [2]time.Duration, used int) {
+	var perWeight, perIteration []float64
+	for _, r := range history {
+		if r.Iterations == 0 || r.Weight == 0 {
+			continue
+		}
+		perWeight = append(perWeight, float64(r.Iterations)/r.Weight)
+		perIteration = append(perIteration, float64(r.Duration)/float64(r.Iterations))
+	}
+	if len(perWeight) < minHistoryRuns {
+		return defaultIterationRates, defaultPace, 0
+	}
+	slices.Sort(perWeight)
+	slices.Sort(perIteration)
+	rates = [3]float64{percentile(perWeight, 0.25), percentile(perWeight, 0.5), percentile(perWeight, 0.75)}
+	pace = [2]time.Duration{time.Duration(percentile(perIteration, 0.25)), time.Duration(percentile(perIteration, 0.75))}
+	return rates, pace, len(perWeight)
+}
+
+// percentile returns the p-th percentile of sorted values, interpolating between neighbors
+func percentile(sorted []float64, p float64) float64 {
+	pos := p * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := min(lo+1, len(sorted)-1)
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// LoadHistory reads past runs from progress files in dir, plan files they name are resolved against root.
+// plan creation runs and runs without a task phase or completion footer are skipped.
+// a missing progress directory is no history.
+func LoadHistory(root, dir string) ([]Run, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "progress-*.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("list progress files: %w", err)
+	}
+	var res []Run
+	for _, f := range files {
+		r, parseErr := parseRun(f)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		if r.Iterations == 0 || r.Duration == 0 || r.Mode == "plan" {
+			continue
+		}
+		r.Tasks, r.Weight = planSize(root, r.PlanFile)
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// completedRe matches the completion footer written when a run ends, with its elapsed time
+var completedRe = regexp.MustCompile(`^Completed: .* \(([0-9hms.]+)\)$`)
+
+// parseRun reads the header, task iteration sections and completion footers of a progress file.
+// restarted runs append to the same file, their iterations and durations are summed.
+func parseRun(path string) (Run, error) {
+	f, err := os.Open(path) //nolint:gosec // progress file in the project's progress directory
+	if err != nil {
+		return Run{}, fmt.Errorf("open progress file: %w", err)
+	}
+	defer f.Close()
+
+	r := Run{Path: path}
+	reader := bufio.NewReader(f)
+	for {
+		line, readErr := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "--- task iteration "):
+			r.Iterations++
+		case strings.HasPrefix(line, "Plan: ") && r.PlanFile == "":
+			r.PlanFile = strings.TrimPrefix(line, "Plan: ")
+		case strings.HasPrefix(line, "Mode: ") && r.Mode == "":
+			r.Mode = strings.TrimPrefix(line, "Mode: ")
+		default:
+			if m := completedRe.FindStringSubmatch(line); m != nil {
+				if d, durErr := time.ParseDuration(m[1]); durErr == nil {
+					r.Duration += d
+				}
+			}
+		}
+		if readErr != nil {
+			if !errors.Is(readErr, io.EOF) {
+				return Run{}, fmt.Errorf("read progress file: %w", readErr)
+			}
+			return r, nil
+		}
+	}
+}
+
+// planSize returns the task count and total task weight of a past run's plan,
+// looking in completed/ if it was moved there
+func planSize(root, planFile string) (tasks int, weight float64) {
+	if planFile == "" || strings.HasPrefix(planFile, "(") {
+		return 0, 0 // review-only runs have no plan
+	}
+	if !filepath.IsAbs(planFile) {
+		planFile = filepath.Join(root, planFile)
+	}
+	for _, p := range []string{planFile, filepath.Join(filepath.Dir(planFile), "completed", filepath.Base(planFile))} {
+		data, err := os.ReadFile(p) //nolint:gosec // plan path recorded by a past run
+		if err != nil {
+			continue
+		}
+		for _, t := range plan.ParseTasks(string(data)) {
+			tasks++
+			weight += sizeTask(t, root).Weight
+		}
+		return tasks, weight
+	}
+	return 0, 0
+}
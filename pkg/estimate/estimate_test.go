@@ -0,0 +1,168 @@
+package estimate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+func TestNew(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "pkg", "x"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pkg", "x", "big.go"), []byte(strings.Repeat("line\n", 2500)), 0o600))
+
+	const content = `# Plan
+
+### Task 1: small
+- [ ] add field to pkg/x/small.go
+- [ ] write tests
+
+### Task 2: big
+- [ ] rework pkg/x/big.go
+- [ ] update cmd/app/main.go
+- [ ] update docs/usage.md
+- [ ] item 4
+- [ ] item 5
+- [ ] item 6
+- [ ] write tests
+`
+
+	t.Run("default rates", func(t *testing.T) {
+		est := New(content, nil, Params{Root: root, MaxIterations: 3, IterationCost: 0.5})
+		require.Len(t, est.Tasks, 2)
+		assert.InDelta(t, 1.0, est.Tasks[0].Weight, 0.001)
+		assert.Equal(t, []string{"pkg"}, est.Tasks[0].Areas)
+		// 2 extra items, 3 areas but only pkg exists, 2500 lines of big.go
+		assert.Equal(t, []string{"pkg"}, est.Tasks[1].Areas)
+		assert.Equal(t, 2500, est.Tasks[1].Lines)
+		assert.InDelta(t, 1.0+0.3+0.5, est.Tasks[1].Weight, 0.001)
+		assert.Equal(t, []string{"pkg"}, est.Areas)
+		assert.Equal(t, 0, est.HistoryRuns)
+		assert.Equal(t, Range{Low: 3, Likely: 3, High: 4}, est.Iterations)
+		assert.Equal(t, [2]time.Duration{9 * time.Minute, 32 * time.Minute}, est.Duration)
+		assert.Equal(t, [2]float64{1.5, 2}, est.Cost)
+		require.Len(t, est.Suggestions, 2)
+		assert.Contains(t, est.Suggestions[0], "up to 4 iterations predicted, over the limit of 3")
+		assert.Equal(t, `split "Task 2: big": 7 items across 1 areas`, est.Suggestions[1])
+	})
+
+	t.Run("history rates", func(t *testing.T) {
+		history := []Run{
+			{Iterations: 2, Weight: 1, Duration: 20 * time.Minute},
+			{Iterations: 4, Weight: 2, Duration: 40 * time.Minute},
+			{Iterations: 6, Weight: 2, Duration: 60 * time.Minute},
+			{Iterations: 0, Weight: 2, Duration: time.Minute}, // skipped, no task iterations
+		}
+		est := New(content, history, Params{Root: root})
+		assert.Equal(t, 3, est.HistoryRuns)
+		assert.Equal(t, Range{Low: 6, Likely: 6, High: 7}, est.Iterations)
+		assert.Equal(t, [2]time.Duration{time.Hour, 70 * time.Minute}, est.Duration)
+		assert.Equal(t, [2]float64{}, est.Cost)
+	})
+
+	t.Run("no tasks", func(t *testing.T) {
+		est := New("# Plan\n\nprose only\n", nil, Params{Root: root})
+		assert.Empty(t, est.Tasks)
+		assert.Equal(t, []string{"no tasks found, nothing to estimate"}, est.Suggestions)
+	})
+}
+
+func TestSizeTask(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "pkg"), 0o750))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "docs"), 0o750))
+
+	tests := []struct {
+		name   string
+		items  []string
+		areas  []string
+		weight float64
+	}{
+		{name: "no references", items: []string{"add a thing"}, weight: 1},
+		{name: "root file", items: []string{"update README.md"}, areas: []string{"."}, weight: 1},
+		{name: "two areas", items: []string{"edit pkg/a.go", "edit docs/a.md"}, areas: []string{"docs", "pkg"}, weight: 1.25},
+		{name: "non-path slashes ignored", items: []string{"handle and/or in pub/sub"}, weight: 1},
+		{name: "many items", items: strings.Split("a b c d e f g h", " "), weight: 1.45},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			task := plan.Task{Header: "Task 1: x"}
+			for _, it := range tc.items {
+				task.Items = append(task.Items, plan.Item{Text: it})
+			}
+			size := sizeTask(task, root)
+			assert.Equal(t, tc.areas, size.Areas)
+			assert.InDelta(t, tc.weight, size.Weight, 0.001)
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	vals := []float64{1, 2, 3, 4, 5}
+	assert.InDelta(t, 1.0, percentile(vals, 0), 0.001)
+	assert.InDelta(t, 2.0, percentile(vals, 0.25), 0.001)
+	assert.InDelta(t, 3.0, percentile(vals, 0.5), 0.001)
+	assert.InDelta(t, 5.0, percentile(vals, 1), 0.001)
+	assert.InDelta(t, 1.5, percentile([]float64{1, 2}, 0.5), 0.001)
+	assert.InDelta(t, 7.0, percentile([]float64{7}, 0.75), 0.001)
+}
+
+func TestLoadHistory(t *testing.T) {
+	root := t.TempDir()
+	progressDir := filepath.Join(root, ".ralphex", "progress")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "docs", "plans", "completed"), 0o750))
+	require.NoError(t, os.MkdirAll(progressDir, 0o750))
+	planContent := "### Task 1: a\n- [x] do a\n\n### Task 2: b\n- [x] do b\n"
+	require.NoError(t, os.WriteFile(filepath.Join(root, "docs", "plans", "completed", "feature.md"), []byte(planContent), 0o600))
+
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(progressDir, name), []byte(content), 0o600))
+	}
+	// restarted run, appended to the same file
+	write("progress-feature.txt", `Plan: docs/plans/feature.md
+Branch: feature
+Mode: full
+Started: 2026-01-15 10:00:00
+
+--- task iteration 1 ---
+work
+--- task iteration 2 ---
+------------------------------------------------------------
+Completed: 2026-01-15 10:10:00 (10m0s)
+
+Plan: docs/plans/feature.md
+Mode: full
+
+--- task iteration 1 ---
+--- review ---
+------------------------------------------------------------
+Completed: 2026-01-15 11:05:00 (1h5m)
+`)
+	write("progress-plan-idea.txt", "Plan: (plan mode)\nMode: plan\n\n--- task iteration 1 ---\nCompleted: 2026-01-15 10:10:00 (1m0s)\n")
+	write("progress-review.txt", "Plan: (no plan - review only)\nMode: review\n\n--- review ---\nCompleted: 2026-01-15 10:10:00 (1m0s)\n")
+	write("progress-unfinished.txt", "Plan: docs/plans/x.md\nMode: full\n\n--- task iteration 1 ---\n")
+	write("other.txt", "--- task iteration 1 ---\nCompleted: x (1m0s)\n")
+
+	runs, err := LoadHistory(root, progressDir)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "docs/plans/feature.md", runs[0].PlanFile)
+	assert.Equal(t, "full", runs[0].Mode)
+	assert.Equal(t, 3, runs[0].Iterations)
+	assert.Equal(t, 75*time.Minute, runs[0].Duration)
+	assert.Equal(t, 2, runs[0].Tasks)
+	assert.InDelta(t, 2.0, runs[0].Weight, 0.001)
+
+	t.Run("no progress dir", func(t *testing.T) {
+		runs, err := LoadHistory(root, t.TempDir())
+		require.NoError(t, err)
+		assert.Empty(t, runs)
+	})
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+
+	"github.com/umputun/ralphex/pkg/debuglog"
 )
 
 // CodexStreams holds both stderr and stdout from codex command.
@@ -68,8 +70,10 @@ type CodexExecutor struct {
 	Sandbox         string            // sandbox mode, defaults to "read-only"
 	ProjectDoc      string            // path to project documentation file
 	OutputHandler   func(text string) // called for each filtered output line in real-time
-	Debug           bool              // enable debug output
+	Debug           debuglog.Flags    // executor-io dumps stderr lines and stdout, signals logs detected signals
 	ErrorPatterns   []string          // patterns to detect in output (e.g., rate limit messages)
+	MaxOutputBytes  int               // max stdout retained in Result.Output (head+tail), 0 keeps everything
+	SpillOutput     bool              // stream truncated stdout in full to Result.OutputFile
 	runner          CodexRunner       // for testing, nil uses default
 }
 
@@ -139,14 +143,26 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 	// process stderr for progress display (header block + bold summaries)
 	stderrDone := make(chan stderrResult, 1)
 	go func() {
-		stderrDone <- e.processStderr(ctx, streams.Stderr)
+		var res stderrResult
+		defer func() {
+			if res.panicked != nil {
+				_, _ = io.Copy(io.Discard, streams.Stderr) // keep codex from blocking on a full pipe
+			}
+			stderrDone <- res
+		}()
+		defer CatchPanic(&res.panicked)
+		res = e.processStderr(ctx, streams.Stderr)
 	}()
 
 	// read stdout entirely as final response
-	stdoutContent, stdoutErr := e.readStdout(streams.Stdout)
+	stdoutContent, stdoutFile, stdoutErr := e.readStdout(streams.Stdout)
 
 	// wait for stderr processing to complete
 	stderrRes := <-stderrDone
+	if stderrRes.panicked != nil {
+		_ = wait() // don't leave the process behind
+		panic(stderrRes.panicked)
+	}
 
 	// wait for command completion
 	waitErr := wait()
@@ -172,26 +188,33 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 		}
 	}
 
+	e.Debug.Printf(debuglog.ExecutorIO, "codex stdout:\n%s", stdoutContent)
+
 	// detect signal in stdout (the actual response)
-	signal := detectSignal(stdoutContent)
+	signal := DetectSignal(stdoutContent)
+	if signal != "" {
+		e.Debug.Printf(debuglog.Signals, "codex output signal %s", signal)
+	}
 
 	// check for error patterns in output
 	if pattern := checkErrorPatterns(stdoutContent, e.ErrorPatterns); pattern != "" {
 		return Result{
-			Output: stdoutContent,
-			Signal: signal,
-			Error:  &PatternMatchError{Pattern: pattern, HelpCmd: "codex /status"},
+			Output:     stdoutContent,
+			OutputFile: stdoutFile,
+			Signal:     signal,
+			Error:      &PatternMatchError{Pattern: pattern, HelpCmd: "codex /status"},
 		}
 	}
 
 	// return stdout content as the result (the actual answer from codex)
-	return Result{Output: stdoutContent, Signal: signal, Error: finalErr}
+	return Result{Output: stdoutContent, OutputFile: stdoutFile, Signal: signal, Error: finalErr}
 }
 
 // stderrResult holds processed stderr output and any error from reading.
 type stderrResult struct {
 	lastLines []string // last few lines of stderr for error context
 	err       error
+	panicked  *Panic // set if processing stderr panicked
 }
 
 // processStderr reads stderr line-by-line, filters for progress display.
@@ -205,6 +228,7 @@ func (e *CodexExecutor) processStderr(ctx context.Context, r io.Reader) stderrRe
 	var tail []string
 
 	err := readLines(ctx, r, func(line string) {
+		e.Debug.Printf(debuglog.ExecutorIO, "codex stderr: %s", line)
 		// capture non-empty lines for error context, preserving original formatting
 		if strings.TrimSpace(line) != "" {
 			stored := line
@@ -232,12 +256,25 @@ func (e *CodexExecutor) processStderr(ctx context.Context, r io.Reader) stderrRe
 }
 
 // readStdout reads the entire stdout content as the final response.
-func (e *CodexExecutor) readStdout(r io.Reader) (string, error) {
-	data, err := io.ReadAll(r)
-	if err != nil {
-		return "", fmt.Errorf("read stdout: %w", err)
+// retained content is capped by MaxOutputBytes, the same way as the output of the other executors.
+// with SpillOutput the dropped part is streamed to a file as it is read, returned with the full stdout.
+func (e *CodexExecutor) readStdout(r io.Reader) (content, file string, err error) {
+	out := newOutputBuffer(e.MaxOutputBytes, e.SpillOutput)
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			out.WriteString(string(buf[:n]))
+		}
+		if errors.Is(readErr, io.EOF) {
+			content = out.String()
+			return content, out.Spilled(), nil
+		}
+		if readErr != nil {
+			out.discard()
+			return "", "", fmt.Errorf("read stdout: %w", readErr)
+		}
 	}
-	return string(data), nil
 }
 
 // shouldDisplay implements a simple filter for codex stderr output.
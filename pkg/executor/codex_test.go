@@ -498,7 +498,7 @@ func TestCodexExecutor_readStdout(t *testing.T) {
 	e := &CodexExecutor{}
 
 	content := "This is the stdout content\nWith multiple lines\n"
-	result, err := e.readStdout(strings.NewReader(content))
+	result, _, err := e.readStdout(strings.NewReader(content))
 
 	require.NoError(t, err)
 	assert.Equal(t, content, result)
@@ -555,7 +555,7 @@ func TestCodexExecutor_readStdout_error(t *testing.T) {
 	e := &CodexExecutor{}
 	errReader := &failingReader{err: errors.New("read failed")}
 
-	_, err := e.readStdout(errReader)
+	_, _, err := e.readStdout(errReader)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "read stdout")
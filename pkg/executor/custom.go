@@ -50,10 +50,12 @@ func (r *execCustomRunner) Run(ctx context.Context, script, promptFile string) (
 
 // CustomExecutor runs custom review scripts and streams output.
 type CustomExecutor struct {
-	Script        string            // path to the custom review script
-	OutputHandler func(text string) // called for each output line, can be nil
-	ErrorPatterns []string          // patterns to detect in output (e.g., rate limit messages)
-	runner        CustomRunner      // for testing, nil uses default
+	Script         string            // path to the custom review script
+	OutputHandler  func(text string) // called for each output line, can be nil
+	ErrorPatterns  []string          // patterns to detect in output (e.g., rate limit messages)
+	MaxOutputBytes int               // max output retained in Result.Output (head+tail), 0 keeps everything
+	SpillOutput    bool              // stream truncated output in full to Result.OutputFile
+	runner         CustomRunner      // for testing, nil uses default
 }
 
 // SetRunner sets the custom runner for testing purposes.
@@ -96,7 +98,7 @@ func (e *CustomExecutor) Run(ctx context.Context, promptContent string) Result {
 	}
 
 	// process stdout for output and signal detection
-	output, signal, streamErr := e.processOutput(ctx, stdout)
+	output, outputFile, signal, streamErr := e.processOutput(ctx, stdout)
 
 	// wait for command completion
 	waitErr := wait()
@@ -117,36 +119,39 @@ func (e *CustomExecutor) Run(ctx context.Context, promptContent string) Result {
 	// check for error patterns in output
 	if pattern := checkErrorPatterns(output, e.ErrorPatterns); pattern != "" {
 		return Result{
-			Output: output,
-			Signal: signal,
-			Error:  &PatternMatchError{Pattern: pattern, HelpCmd: e.Script + " --help"},
+			Output:     output,
+			OutputFile: outputFile,
+			Signal:     signal,
+			Error:      &PatternMatchError{Pattern: pattern, HelpCmd: e.Script + " --help"},
 		}
 	}
 
-	return Result{Output: output, Signal: signal, Error: finalErr}
+	return Result{Output: output, OutputFile: outputFile, Signal: signal, Error: finalErr}
 }
 
 // processOutput reads stdout line-by-line, streams to OutputHandler, and detects signals.
-func (e *CustomExecutor) processOutput(ctx context.Context, r io.Reader) (output, signal string, err error) {
-	var outputBuf []byte
+// retained output is capped by MaxOutputBytes, the full text still goes to OutputHandler
+// and, with SpillOutput, to the returned output file.
+func (e *CustomExecutor) processOutput(ctx context.Context, r io.Reader) (output, outputFile, signal string, err error) {
+	outputBuf := newOutputBuffer(e.MaxOutputBytes, e.SpillOutput)
 	var sig string
 
 	readErr := readLines(ctx, r, func(line string) {
-		outputBuf = append(outputBuf, line...)
-		outputBuf = append(outputBuf, '\n')
+		outputBuf.WriteString(line + "\n")
 
 		if e.OutputHandler != nil {
 			e.OutputHandler(line + "\n")
 		}
 
 		// check for signals in each line
-		if s := detectSignal(line); s != "" {
+		if s := DetectSignal(line); s != "" {
 			sig = s
 		}
 	})
 
+	output = outputBuf.String()
 	if readErr != nil {
-		return string(outputBuf), sig, fmt.Errorf("read output: %w", readErr)
+		return output, outputBuf.Spilled(), sig, fmt.Errorf("read output: %w", readErr)
 	}
-	return string(outputBuf), sig, nil
+	return output, outputBuf.Spilled(), sig, nil
 }
@@ -136,7 +136,7 @@ func TestCustomExecutor_Run_NoOutputHandler(t *testing.T) {
 }
 
 func TestCustomExecutor_Run_AllSignals(t *testing.T) {
-	// tests all recognized signals (from detectSignal function)
+	// tests all recognized signals (from DetectSignal function)
 	tests := []struct {
 		name       string
 		output     string
@@ -274,7 +274,7 @@ func TestCustomExecutor_processOutput_readError(t *testing.T) {
 	e := &CustomExecutor{Script: "/path/to/script.sh"}
 	errReader := &failingReader{err: errors.New("read failed")}
 
-	output, signal, err := e.processOutput(context.Background(), errReader)
+	output, _, signal, err := e.processOutput(context.Background(), errReader)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "read output")
@@ -296,7 +296,7 @@ func TestCustomExecutor_processOutput_contextCancellation(t *testing.T) {
 	}()
 
 	e := &CustomExecutor{Script: "/path/to/script.sh"}
-	_, _, err := e.processOutput(ctx, pr)
+	_, _, _, err := e.processOutput(ctx, pr)
 
 	// should return context.Canceled or nil (depending on timing)
 	if err != nil {
@@ -2,6 +2,7 @@
 package executor
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -9,8 +10,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/umputun/ralphex/pkg/debuglog"
 	"github.com/umputun/ralphex/pkg/status"
 )
 
@@ -20,9 +24,39 @@ const defaultPrimaryCommand = "codex"
 
 // Result holds execution result with output and detected signal.
 type Result struct {
-	Output string // accumulated text output
-	Signal string // detected signal (COMPLETED, FAILED, etc.) or empty
-	Error  error  // execution error if any
+	Output     string // accumulated text output
+	OutputFile string // full output when Output was truncated and the executor spills output, the caller removes it
+	Signal     string // detected signal (COMPLETED, FAILED, etc.) or empty
+	SessionID  string // agent session reported by the CLI, continued by a later run with WithSession, empty if not reported
+	Usage      Usage  // tokens and provider time reported by the CLI, zero if not reported
+	Error      error  // execution error if any
+}
+
+// Usage is the token use and provider time of an agent call as reported by the CLI.
+type Usage struct {
+	InputTokens  int           // prompt tokens, cached ones included
+	OutputTokens int           // generated tokens
+	APIDuration  time.Duration // time spent waiting for the model provider, 0 if not reported
+}
+
+// IsZero reports if the CLI reported no usage.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// sessionKey is the context key of the agent session a run continues, see WithSession.
+type sessionKey struct{}
+
+// WithSession returns a context making ClaudeExecutor.Run continue the agent session with the given id
+// (claude --resume, codex exec resume) instead of starting a new one. an empty id starts a new session.
+func WithSession(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, id)
+}
+
+// SessionFromContext returns the agent session set by WithSession, empty for a new session.
+func SessionFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
 }
 
 // PatternMatchError is returned when a configured error pattern is detected in output.
@@ -161,19 +195,43 @@ type streamEvent struct {
 		Type string `json:"type"`
 		Text string `json:"text"`
 	} `json:"delta"`
-	Result json.RawMessage `json:"result"` // can be string or object with "output" field
+	Result    json.RawMessage `json:"result"`     // can be string or object with "output" field
+	SessionID string          `json:"session_id"` // claude session, reported by the init and result events
+	ThreadID  string          `json:"thread_id"`  // codex session, reported by the thread.started event of --json output
+
+	// token use of the claude result event and of each codex turn.completed event
+	Usage struct {
+		InputTokens              int `json:"input_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+	} `json:"usage"`
+	DurationAPIMs int64 `json:"duration_api_ms"` // provider time of the claude result event
 }
 
 // ClaudeExecutor runs CLI commands with streaming JSON parsing.
 type ClaudeExecutor struct {
-	Command       string            // command to execute, defaults to "codex"
-	Args          string            // additional arguments (space-separated), defaults to standard args
-	OutputHandler func(text string) // called for each text chunk, can be nil
-	Debug         bool              // enable debug output
-	ErrorPatterns []string          // patterns to detect in output (e.g., rate limit messages)
-	cmdRunner     CommandRunner     // for testing, nil uses default
+	Command        string            // command to execute, defaults to "codex"
+	Args           string            // additional arguments (space-separated), defaults to standard args
+	OutputHandler  func(text string) // called for each text chunk, can be nil
+	Debug          debuglog.Flags    // executor-io dumps stream lines, signals logs detected signals
+	ErrorPatterns  []string          // patterns to detect in output (e.g., rate limit messages)
+	MaxOutputBytes int               // max output retained in Result.Output (head+tail), 0 keeps everything
+	SpillOutput    bool              // stream truncated output in full to Result.OutputFile
+	MaxEventBytes  int               // max size of a single stream-json event held in memory, 0 = no limit
+	cmdRunner      CommandRunner     // for testing, nil uses default
 }
 
+// DefaultMaxEventBytes is the recommended cap for a single stream-json line. larger events are
+// mostly tool results (e.g. the agent reading a huge file) which carry no text ralphex needs.
+const DefaultMaxEventBytes = 16 * 1024 * 1024
+
+// eventTypePattern extracts the event type from the start of a (possibly cut) stream-json line.
+var eventTypePattern = regexp.MustCompile(`^\s*\{\s*"type"\s*:\s*"([^"]*)"`)
+
+// codexSessionPattern extracts the session id from the header codex prints in plain text output.
+var codexSessionPattern = regexp.MustCompile(`^session id:\s*(\S+)\s*$`)
+
 // Run executes CLI with the given prompt and parses streaming JSON output.
 func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 	cmd := e.Command
@@ -192,11 +250,17 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 			"--verbose",
 		}
 	}
-	// codex expects the prompt as a positional argument (not -p).
-	// all other tools keep Claude-compatible "-p <prompt>" mode.
-	if isCodexCommand(cmd) {
+	// codex expects the prompt as a positional argument (not -p), a session is continued by its
+	// "resume" subcommand. all other tools keep Claude-compatible "--resume <id> -p <prompt>" mode.
+	session := SessionFromContext(ctx)
+	switch {
+	case isCodexCommand(cmd) && session != "":
+		args = append(args, "resume", session, prompt)
+	case isCodexCommand(cmd):
 		args = append(args, prompt)
-	} else {
+	case session != "":
+		args = append(args, "--resume", session, "-p", prompt)
+	default:
 		args = append(args, "-p", prompt)
 	}
 
@@ -215,7 +279,8 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 	if err := wait(); err != nil {
 		// check if it was context cancellation
 		if ctx.Err() != nil {
-			return Result{Output: result.Output, Signal: result.Signal, Error: ctx.Err()}
+			return Result{Output: result.Output, Signal: result.Signal, SessionID: result.SessionID, Usage: result.Usage,
+				Error: ctx.Err()}
 		}
 		// non-zero exit might still have useful output
 		if result.Output == "" {
@@ -226,9 +291,11 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 	// check for error patterns in output
 	if pattern := checkErrorPatterns(result.Output, e.ErrorPatterns); pattern != "" {
 		return Result{
-			Output: result.Output,
-			Signal: result.Signal,
-			Error:  &PatternMatchError{Pattern: pattern, HelpCmd: commandBase(cmd) + " /usage"},
+			Output:    result.Output,
+			Signal:    result.Signal,
+			SessionID: result.SessionID,
+			Usage:     result.Usage,
+			Error:     &PatternMatchError{Pattern: pattern, HelpCmd: commandBase(cmd) + " /usage"},
 		}
 	}
 
@@ -249,30 +316,46 @@ func commandBase(cmd string) string {
 }
 
 // parseStream reads and parses the JSON stream from claude CLI.
-// uses readLines internally, so there is no line length limit.
+// events are processed one line at a time and not retained, so memory stays flat for long runs:
+// a single line is capped by MaxEventBytes and retained text by MaxOutputBytes.
+// the full text still goes to OutputHandler.
 // checks ctx.Done() between reads so cancellation is not blocked by slow pipe reads.
 func (e *ClaudeExecutor) parseStream(ctx context.Context, r io.Reader) Result {
-	var output strings.Builder
-	var signal string
+	output := newOutputBuffer(e.MaxOutputBytes, e.SpillOutput)
+	var signal, session string
+	var usage Usage
 
-	err := readLines(ctx, r, func(line string) {
+	err := readLinesLimit(ctx, r, e.MaxEventBytes, func(line string, truncated bool) {
 		if line == "" {
 			return
 		}
+		e.Debug.Printf(debuglog.ExecutorIO, "%s", line)
+		if truncated {
+			e.skipOversizedEvent(line, e.MaxEventBytes, output)
+			return
+		}
 
 		var event streamEvent
 		if jsonErr := json.Unmarshal([]byte(line), &event); jsonErr != nil {
-			// print non-JSON lines as-is
-			if e.Debug {
-				fmt.Printf("[debug] non-JSON line: %s\n", line)
+			if m := codexSessionPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+				session = m[1]
 			}
-			output.WriteString(line)
-			output.WriteString("\n")
+			// print non-JSON lines as-is
+			output.WriteString(line + "\n")
 			if e.OutputHandler != nil {
 				e.OutputHandler(line + "\n")
 			}
 			return
 		}
+		if id := cmp.Or(event.SessionID, event.ThreadID); id != "" {
+			session = id
+		}
+		if event.Type == "result" || event.Type == "turn.completed" {
+			u := event.Usage
+			usage.InputTokens += u.InputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens
+			usage.OutputTokens += u.OutputTokens
+			usage.APIDuration += time.Duration(event.DurationAPIMs) * time.Millisecond
+		}
 
 		text := e.extractText(&event)
 		if text != "" {
@@ -282,17 +365,38 @@ func (e *ClaudeExecutor) parseStream(ctx context.Context, r io.Reader) Result {
 			}
 
 			// check for signals in text
-			if sig := detectSignal(text); sig != "" {
+			if sig := DetectSignal(text); sig != "" {
+				e.Debug.Printf(debuglog.Signals, "claude output signal %s", sig)
 				signal = sig
 			}
 		}
 	})
 
+	text := output.String()
 	if err != nil {
-		return Result{Output: output.String(), Signal: signal, Error: fmt.Errorf("stream read: %w", err)}
+		return Result{Output: text, OutputFile: output.Spilled(), Signal: signal, SessionID: session, Usage: usage,
+			Error: fmt.Errorf("stream read: %w", err)}
 	}
 
-	return Result{Output: output.String(), Signal: signal}
+	return Result{Output: text, OutputFile: output.Spilled(), Signal: signal, SessionID: session, Usage: usage}
+}
+
+// skipOversizedEvent handles a stream-json line cut at maxEvent bytes. the event can't be parsed,
+// so it is dropped. tool traffic is dropped silently, text-bearing events leave a marker in the output.
+func (e *ClaudeExecutor) skipOversizedEvent(prefix string, maxEvent int, output *outputBuffer) {
+	var eventType string
+	if m := eventTypePattern.FindStringSubmatch(prefix); m != nil {
+		eventType = m[1]
+	}
+	e.Debug.Printf(debuglog.ExecutorIO, "skipped stream event %q over %d bytes", eventType, maxEvent)
+	switch eventType {
+	case "assistant", "content_block_delta", "message_stop", "result", "":
+		marker := fmt.Sprintf("\n[stream event %q over %d bytes skipped]\n", eventType, maxEvent)
+		output.WriteString(marker)
+		if e.OutputHandler != nil {
+			e.OutputHandler(marker)
+		}
+	}
 }
 
 // extractText extracts text content from various event types.
@@ -339,15 +443,16 @@ func (e *ClaudeExecutor) extractText(event *streamEvent) string {
 	return ""
 }
 
-// detectSignal checks text for completion status.
+// DetectSignal returns the first known signal in text, empty if none.
 // looks for <<<RALPHEX:...>>> format status.
-func detectSignal(text string) string {
+func DetectSignal(text string) string {
 	knownSignals := []string{
 		status.Completed,
 		status.Failed,
 		status.ReviewDone,
 		status.CodexDone,
 		status.PlanReady,
+		status.NeedsInput,
 	}
 	for _, sig := range knownSignals {
 		if strings.Contains(text, sig) {
@@ -6,10 +6,12 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/umputun/ralphex/pkg/debuglog"
 	"github.com/umputun/ralphex/pkg/executor/mocks"
 	"github.com/umputun/ralphex/pkg/status"
 )
@@ -228,7 +230,7 @@ func TestClaudeExecutor_parseStream_withDebug(t *testing.T) {
 	// non-json lines should be printed as-is (with debug message)
 	input := "not json\n" + `{"type":"content_block_delta","delta":{"type":"text_delta","text":"valid"}}`
 
-	e := &ClaudeExecutor{Debug: true}
+	e := &ClaudeExecutor{Debug: debuglog.Flags{ExecutorIO: true, Signals: true}}
 	result := e.parseStream(context.Background(), strings.NewReader(input))
 
 	assert.Equal(t, "not json\nvalid", result.Output)
@@ -336,7 +338,7 @@ func TestDetectSignal(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.text, func(t *testing.T) {
-			got := detectSignal(tc.text)
+			got := DetectSignal(tc.text)
 			assert.Equal(t, tc.want, got)
 		})
 	}
@@ -406,6 +408,88 @@ func TestClaudeExecutor_Run_WithCodexCommand_UsesPositionalPrompt(t *testing.T)
 	assert.Equal(t, []string{"exec", "--dangerously-bypass-approvals-and-sandbox", "-c", "model=gpt-5.3-codex", "test prompt"}, capturedArgs)
 }
 
+func TestClaudeExecutor_Run_Session(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		args     string
+		stream   string
+		session  string
+		wantArgs []string
+		wantID   string
+	}{
+		{name: "claude new session", command: "claude", args: "--verbose",
+			stream:   `{"type":"system","subtype":"init","session_id":"s-1"}` + "\n" + `{"type":"result","result":"done","session_id":"s-1"}`,
+			wantArgs: []string{"--verbose", "-p", "prompt"}, wantID: "s-1"},
+		{name: "claude resumed session", command: "claude", args: "--verbose", session: "s-1",
+			stream:   `{"type":"result","result":"done","session_id":"s-2"}`,
+			wantArgs: []string{"--verbose", "--resume", "s-1", "-p", "prompt"}, wantID: "s-2"},
+		{name: "codex header", command: "codex", args: "exec --full-auto",
+			stream:   "--------\nmodel: gpt-5.3-codex\nsession id: 0199a2b3-c4d5\n--------\ndone",
+			wantArgs: []string{"exec", "--full-auto", "prompt"}, wantID: "0199a2b3-c4d5"},
+		{name: "codex resumed session", command: "codex", args: "exec --json", session: "0199a2b3-c4d5",
+			stream:   `{"type":"thread.started","thread_id":"0199a2b3-c4d5"}`,
+			wantArgs: []string{"exec", "--json", "resume", "0199a2b3-c4d5", "prompt"}, wantID: "0199a2b3-c4d5"},
+		{name: "no session reported", command: "claude", args: "--verbose",
+			stream:   `{"type":"content_block_delta","delta":{"type":"text_delta","text":"ok"}}`,
+			wantArgs: []string{"--verbose", "-p", "prompt"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var capturedArgs []string
+			mock := &mocks.CommandRunnerMock{
+				RunFunc: func(_ context.Context, _ string, args ...string) (io.Reader, func() error, error) {
+					capturedArgs = args
+					return strings.NewReader(tc.stream), func() error { return nil }, nil
+				},
+			}
+			e := &ClaudeExecutor{cmdRunner: mock, Command: tc.command, Args: tc.args}
+
+			ctx := context.Background()
+			if tc.session != "" {
+				ctx = WithSession(ctx, tc.session)
+			}
+			result := e.Run(ctx, "prompt")
+
+			require.NoError(t, result.Error)
+			assert.Equal(t, tc.wantArgs, capturedArgs)
+			assert.Equal(t, tc.wantID, result.SessionID)
+		})
+	}
+}
+
+func TestClaudeExecutor_Run_Usage(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   Usage
+	}{
+		{name: "claude result", stream: `{"type":"content_block_delta","delta":{"type":"text_delta","text":"ok"}}` + "\n" +
+			`{"type":"result","result":"ok","duration_ms":9000,"duration_api_ms":7500,"usage":{"input_tokens":120,` +
+			`"cache_read_input_tokens":9000,"cache_creation_input_tokens":880,"output_tokens":450}}`,
+			want: Usage{InputTokens: 10000, OutputTokens: 450, APIDuration: 7500 * time.Millisecond}},
+		{name: "codex turns", stream: `{"type":"turn.completed","usage":{"input_tokens":2000,"cached_input_tokens":1500,"output_tokens":100}}` +
+			"\n" + `{"type":"turn.completed","usage":{"input_tokens":3000,"output_tokens":200}}`,
+			want: Usage{InputTokens: 5000, OutputTokens: 300}},
+		{name: "not reported", stream: `{"type":"content_block_delta","delta":{"type":"text_delta","text":"ok"}}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mocks.CommandRunnerMock{
+				RunFunc: func(context.Context, string, ...string) (io.Reader, func() error, error) {
+					return strings.NewReader(tc.stream), func() error { return nil }, nil
+				},
+			}
+			e := &ClaudeExecutor{cmdRunner: mock, Command: "claude"}
+			result := e.Run(context.Background(), "prompt")
+			require.NoError(t, result.Error)
+			assert.Equal(t, tc.want, result.Usage)
+			assert.Equal(t, tc.want == Usage{}, result.Usage.IsZero())
+		})
+	}
+}
+
 func TestClaudeExecutor_Run_WithCustomCommandAndArgs(t *testing.T) {
 	var capturedCmd string
 	var capturedArgs []string
@@ -705,3 +789,39 @@ func TestClaudeExecutor_Run_ErrorPattern_WithSignal(t *testing.T) {
 	assert.Contains(t, result.Output, "You've hit your limit")
 	assert.Equal(t, "<<<RALPHEX:ALL_TASKS_DONE>>>", result.Signal)
 }
+
+func TestClaudeExecutor_parseStream_MaxEventBytes(t *testing.T) {
+	bigText := strings.Repeat("x", 4096)
+	input := strings.Join([]string{
+		`{"type":"content_block_delta","delta":{"type":"text_delta","text":"before "}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","content":"` + bigText + `"}]}}`,
+		`{"type":"content_block_delta","delta":{"type":"text_delta","text":"` + bigText + `"}}`,
+		`{"type":"content_block_delta","delta":{"type":"text_delta","text":"after <<<RALPHEX:ALL_TASKS_DONE>>>"}}`,
+	}, "\n")
+
+	var handled strings.Builder
+	e := &ClaudeExecutor{MaxEventBytes: 1024, OutputHandler: func(text string) { handled.WriteString(text) }}
+	result := e.parseStream(context.Background(), strings.NewReader(input))
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, "before \n[stream event \"content_block_delta\" over 1024 bytes skipped]\nafter <<<RALPHEX:ALL_TASKS_DONE>>>",
+		result.Output, "oversized tool result is dropped silently, oversized text leaves a marker")
+	assert.Equal(t, result.Output, handled.String())
+	assert.Equal(t, "<<<RALPHEX:ALL_TASKS_DONE>>>", result.Signal)
+}
+
+func BenchmarkClaudeExecutor_parseStream(b *testing.B) {
+	var sb strings.Builder
+	for range 5000 {
+		sb.WriteString(`{"type":"content_block_delta","delta":{"type":"text_delta","text":"working on the task, editing files\n"}}` + "\n")
+		sb.WriteString(`{"type":"user","message":{"content":[{"type":"tool_result","content":"` + strings.Repeat("x", 2000) + `"}]}}` + "\n")
+	}
+	input := sb.String()
+	e := &ClaudeExecutor{MaxEventBytes: DefaultMaxEventBytes, MaxOutputBytes: 1024 * 1024, OutputHandler: func(string) {}}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+	for b.Loop() {
+		_ = e.parseStream(context.Background(), strings.NewReader(input))
+	}
+}
@@ -13,17 +13,37 @@ import (
 // strips trailing \n and \r\n from lines before passing to handler (matching bufio.ScanLines behavior).
 // returns nil on EOF, or a wrapped error on context cancellation or read failure.
 func readLines(ctx context.Context, r io.Reader, handler func(string)) error {
-	reader := bufio.NewReader(r)
+	return readLinesLimit(ctx, r, 0, func(line string, _ bool) { handler(line) })
+}
+
+// readLinesLimit is readLines with a cap on memory used by a single line.
+// lines longer than maxLen are cut to their first maxLen bytes and handler gets truncated=true,
+// the rest of such a line is read and discarded in chunks without being buffered.
+// maxLen <= 0 disables the cap.
+func readLinesLimit(ctx context.Context, r io.Reader, maxLen int, handler func(line string, truncated bool)) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	var buf []byte
+	size := 0 // bytes of the current line read so far, including dropped ones
 	for {
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("read lines: %w", ctx.Err())
 		default:
 		}
-		line, err := reader.ReadString('\n')
-		if line != "" {
-			line = trimLineEnding(line)
-			handler(line)
+		chunk, err := reader.ReadSlice('\n')
+		size += len(chunk)
+		switch {
+		case maxLen <= 0:
+			buf = append(buf, chunk...)
+		case len(buf) < maxLen+2: // +2 leaves room for the \r\n line ending
+			buf = append(buf, chunk[:min(len(chunk), maxLen+2-len(buf))]...)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue // line continues past the reader buffer
+		}
+		if size > 0 {
+			handler(limitLine(buf, size, maxLen))
+			buf, size = buf[:0], 0
 		}
 		if err != nil {
 			if errors.Is(err, io.EOF) {
@@ -34,6 +54,20 @@ func readLines(ctx context.Context, r io.Reader, handler func(string)) error {
 	}
 }
 
+// limitLine converts buffered line bytes to a string with the line ending trimmed, cut to maxLen.
+// size is the full length of the line as read; when it exceeds len(buf) the line was cut while reading.
+func limitLine(buf []byte, size, maxLen int) (string, bool) {
+	cut := size > len(buf)
+	line := string(buf)
+	if !cut {
+		line = trimLineEnding(line)
+	}
+	if maxLen > 0 && len(line) > maxLen {
+		return line[:maxLen], true
+	}
+	return line, cut
+}
+
 // trimLineEnding removes trailing line ending to match bufio.ScanLines semantics:
 // strips \n, \r\n, or a bare trailing \r (which ScanLines drops via dropCR at EOF).
 // unlike strings.TrimRight("\r\n"), this preserves embedded \r characters in content.
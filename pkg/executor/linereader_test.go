@@ -189,3 +189,52 @@ func TestReadLines_MixedContent(t *testing.T) {
 		`{"type":"delta","text":"hello"}`,
 	}, lines)
 }
+
+func TestReadLinesLimit(t *testing.T) {
+	type line struct {
+		text      string
+		truncated bool
+	}
+	tests := []struct {
+		name   string
+		input  string
+		maxLen int
+		want   []line
+	}{
+		{name: "no limit", input: "short\nlonger line\n", maxLen: 0,
+			want: []line{{"short", false}, {"longer line", false}}},
+		{name: "under limit", input: "abc\r\nde\n", maxLen: 3,
+			want: []line{{"abc", false}, {"de", false}}},
+		{name: "over limit", input: "abcdefgh\nxy\n", maxLen: 3,
+			want: []line{{"abc", true}, {"xy", false}}},
+		{name: "over limit without newline", input: "abcdefgh", maxLen: 4,
+			want: []line{{"abcd", true}}},
+		{name: "one byte over limit", input: "abcd\n", maxLen: 3,
+			want: []line{{"abc", true}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []line
+			err := readLinesLimit(context.Background(), strings.NewReader(tc.input), tc.maxLen, func(text string, truncated bool) {
+				got = append(got, line{text, truncated})
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestReadLinesLimit_LongLineSpanningReaderBuffer(t *testing.T) {
+	// a line much larger than the 64KB reader buffer must be cut without being buffered whole
+	input := strings.Repeat("x", 1024*1024) + "\nnext\n"
+
+	var lines []string
+	var flags []bool
+	err := readLinesLimit(context.Background(), strings.NewReader(input), 100, func(text string, truncated bool) {
+		lines = append(lines, text)
+		flags = append(flags, truncated)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{strings.Repeat("x", 100), "next"}, lines)
+	assert.Equal(t, []bool{true, false}, flags)
+}
@@ -0,0 +1,167 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// outputBuffer accumulates executor output with a head+tail retention policy.
+// when total output exceeds limit, the first half of the limit is kept as head, the last half
+// as tail, and everything in between is dropped from memory. limit <= 0 keeps everything.
+// with spill on, the output is streamed to a temp file from the first drop on, so the full output
+// is still available from the file returned by Spilled.
+type outputBuffer struct {
+	limit   int
+	head    []byte
+	tail    []byte
+	dropped int
+
+	spill       bool     // stream the full output to a temp file once something is dropped
+	file        *os.File // full output so far, open while writing
+	spillPath   string   // full output, set by String once the file is complete
+	spillFailed bool     // set after a spill failure, the output is only truncated then
+}
+
+// newOutputBuffer creates an output buffer retaining at most limit bytes. with spill on the dropped
+// output is streamed to a temp file, see Spilled.
+func newOutputBuffer(limit int, spill bool) *outputBuffer {
+	return &outputBuffer{limit: limit, spill: spill}
+}
+
+// WriteString appends s, evicting the oldest tail bytes once the tail window is full.
+// the tail is allowed to grow to twice its window before compaction to keep writes amortized O(len(s)).
+func (b *outputBuffer) WriteString(s string) {
+	if b.limit <= 0 {
+		b.head = append(b.head, s...)
+		return
+	}
+
+	headCap := b.limit / 2
+	if n := headCap - len(b.head); n > 0 {
+		n = min(n, len(s))
+		b.head = append(b.head, s[:n]...)
+		s = s[n:]
+	}
+	if s == "" {
+		return
+	}
+
+	tailCap := b.limit - headCap
+	b.tail = append(b.tail, s...)
+	if len(b.tail) > 2*tailCap {
+		b.evict(len(b.tail) - tailCap)
+	}
+}
+
+// String returns retained output. if anything was dropped, head and tail are joined
+// with a marker noting how many bytes were cut. calling String finalizes the tail window.
+func (b *outputBuffer) String() string {
+	if b.limit <= 0 {
+		return string(b.head)
+	}
+	if excess := len(b.tail) - (b.limit - b.limit/2); excess > 0 {
+		b.evict(excess)
+	}
+	if b.file != nil {
+		b.writeSpill(b.tail)
+		if b.file != nil {
+			b.closeSpill()
+		}
+	}
+	if b.dropped == 0 {
+		return string(b.head) + string(b.tail)
+	}
+
+	// keep the cut on rune boundaries so the marker isn't surrounded by broken UTF-8
+	head, tail := b.head, b.tail
+	for i := 0; i < utf8.UTFMax-1 && len(head) > 0; i++ {
+		if r, size := utf8.DecodeLastRune(head); r != utf8.RuneError || size > 1 {
+			break
+		}
+		head = head[:len(head)-1]
+	}
+	for i := 0; i < utf8.UTFMax-1 && len(tail) > 0 && !utf8.RuneStart(tail[0]); i++ {
+		tail = tail[1:]
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(head) + len(tail) + 100)
+	sb.Write(head)
+	fmt.Fprintf(&sb, "\n\n[... %d bytes of output truncated, only the start and end of the output are kept ...]\n\n", b.dropped)
+	sb.Write(tail)
+	return sb.String()
+}
+
+// Spilled returns the temp file holding the full output, empty if nothing was dropped, spill is off or
+// writing the file failed. set by String, the caller removes the file.
+func (b *outputBuffer) Spilled() string {
+	return b.spillPath
+}
+
+// evict drops the oldest n bytes of the tail, streaming them to the spill file first.
+func (b *outputBuffer) evict(n int) {
+	if b.spill && !b.spillFailed && b.spillPath == "" {
+		if b.file == nil {
+			b.openSpill()
+		}
+		b.writeSpill(b.tail[:n])
+	}
+	b.dropped += n
+	b.tail = append(b.tail[:0], b.tail[n:]...)
+}
+
+// openSpill creates the spill file with the head written to it.
+func (b *outputBuffer) openSpill() {
+	f, err := os.CreateTemp("", "ralphex-output-*.txt")
+	if err != nil {
+		b.spillFailed = true
+		return
+	}
+	b.file = f
+	b.writeSpill(b.head)
+}
+
+// writeSpill appends p to the spill file. on failure the file is removed and spilling stops.
+func (b *outputBuffer) writeSpill(p []byte) {
+	if b.file == nil {
+		return
+	}
+	if _, err := b.file.Write(p); err != nil {
+		b.failSpill()
+	}
+}
+
+// closeSpill completes the spill file and makes it available from Spilled.
+func (b *outputBuffer) closeSpill() {
+	name := b.file.Name()
+	err := b.file.Close()
+	b.file = nil
+	if err != nil {
+		b.spillFailed = true
+		_ = os.Remove(name)
+		return
+	}
+	b.spillPath = name
+}
+
+// failSpill drops the incomplete spill file and stops spilling.
+func (b *outputBuffer) failSpill() {
+	name := b.file.Name()
+	_ = b.file.Close()
+	_ = os.Remove(name)
+	b.file = nil
+	b.spillFailed = true
+}
+
+// discard removes the spill file, for output that is thrown away.
+func (b *outputBuffer) discard() {
+	if b.file != nil {
+		b.failSpill()
+	}
+	if b.spillPath != "" {
+		_ = os.Remove(b.spillPath)
+		b.spillPath = ""
+	}
+}
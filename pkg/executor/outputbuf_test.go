@@ -0,0 +1,188 @@
+package executor
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputBuffer(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int
+		writes []string
+		want   string
+	}{
+		{name: "unlimited", limit: 0, writes: []string{"abc", "def"}, want: "abcdef"},
+		{name: "under limit", limit: 10, writes: []string{"abc", "def"}, want: "abcdef"},
+		{name: "exactly limit", limit: 6, writes: []string{"abc", "def"}, want: "abcdef"},
+		{name: "over limit keeps head and tail", limit: 6, writes: []string{"abcdefgh", "ij"},
+			want: "abc\n\n[... 4 bytes of output truncated, only the start and end of the output are kept ...]\n\nhij"},
+		{name: "many small writes", limit: 4, writes: strings.Split("0123456789", ""),
+			want: "01\n\n[... 6 bytes of output truncated, only the start and end of the output are kept ...]\n\n89"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newOutputBuffer(tc.limit, false)
+			for _, w := range tc.writes {
+				b.WriteString(w)
+			}
+			assert.Equal(t, tc.want, b.String())
+			assert.Equal(t, tc.want, b.String(), "String should be idempotent")
+		})
+	}
+}
+
+func TestOutputBuffer_BoundedMemory(t *testing.T) {
+	b := newOutputBuffer(1000, false)
+	line := strings.Repeat("x", 99) + "\n"
+	for range 10000 {
+		b.WriteString(line)
+	}
+	assert.LessOrEqual(t, len(b.head)+len(b.tail), 1500, "retained bytes stay within 1.5x limit while writing")
+
+	out := b.String()
+	assert.Contains(t, out, "999000 bytes of output truncated")
+	assert.Len(t, b.head, 500)
+	assert.Len(t, b.tail, 500)
+}
+
+func TestOutputBuffer_RuneBoundaries(t *testing.T) {
+	b := newOutputBuffer(6, false)
+	b.WriteString("ab€cdef€g") // € is 3 bytes, head and tail windows both cut through one
+	out := b.String()
+
+	require.Contains(t, out, "truncated")
+	assert.True(t, strings.HasPrefix(out, "ab\n"), "split rune at head end is dropped: %q", out)
+	assert.True(t, strings.HasSuffix(out, "...]\n\ng"), "split rune at tail start is dropped: %q", out)
+}
+
+func TestClaudeExecutor_parseStream_MaxOutputBytes(t *testing.T) {
+	var handled strings.Builder
+	e := &ClaudeExecutor{MaxOutputBytes: 20, OutputHandler: func(text string) { handled.WriteString(text) }}
+	input := strings.Repeat("line of plain text\n", 10)
+
+	result := e.parseStream(t.Context(), strings.NewReader(input))
+
+	require.NoError(t, result.Error)
+	assert.Contains(t, result.Output, "bytes of output truncated")
+	assert.Less(t, len(result.Output), len(input))
+	assert.Equal(t, input, handled.String(), "output handler still receives everything")
+}
+
+func TestCodexExecutor_readStdout_MaxOutputBytes(t *testing.T) {
+	var handled strings.Builder
+	e := &CodexExecutor{MaxOutputBytes: 10, OutputHandler: func(text string) { handled.WriteString(text) }}
+	content := "HEAD1" + strings.Repeat("m", 100) + "TAIL1"
+
+	got, file, err := e.readStdout(strings.NewReader(content))
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(got, "HEAD1"))
+	assert.True(t, strings.HasSuffix(got, "TAIL1"))
+	assert.Contains(t, got, "100 bytes of output truncated")
+	assert.Empty(t, handled.String(), "dropped stdout isn't printed out of context")
+	assert.Empty(t, file, "nothing spilled without SpillOutput")
+}
+
+func TestOutputBuffer_Spill(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		writes  []string
+		spilled bool
+	}{
+		{name: "unlimited", limit: 0, writes: []string{"abc", "def"}},
+		{name: "under limit", limit: 10, writes: []string{"abc", "def"}},
+		{name: "over limit", limit: 6, writes: []string{"abcdefgh", "ij"}, spilled: true},
+		{name: "many small writes", limit: 4, writes: strings.Split("0123456789", ""), spilled: true},
+		{name: "large output", limit: 100, writes: strings.Split(strings.Repeat("line of output\n", 1000), "\n"), spilled: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TMPDIR", t.TempDir())
+			b := newOutputBuffer(tc.limit, true)
+			for _, w := range tc.writes {
+				b.WriteString(w)
+			}
+			out := b.String()
+			assert.Equal(t, out, b.String(), "String should be idempotent")
+			if !tc.spilled {
+				assert.Empty(t, b.Spilled())
+				return
+			}
+			assert.Contains(t, out, "bytes of output truncated")
+			path := b.Spilled()
+			require.NotEmpty(t, path)
+			data, err := os.ReadFile(path) //nolint:gosec // test temp file
+			require.NoError(t, err)
+			assert.Equal(t, strings.Join(tc.writes, ""), string(data), "spill file holds the full output")
+
+			b.discard()
+			assert.NoFileExists(t, path)
+		})
+	}
+}
+
+func TestCodexExecutor_readStdout_SpillOutput(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	e := &CodexExecutor{MaxOutputBytes: 10, SpillOutput: true}
+	content := "HEAD1" + strings.Repeat("m", 100_000) + "TAIL1"
+
+	got, file, err := e.readStdout(iotest.HalfReader(strings.NewReader(content)))
+
+	require.NoError(t, err)
+	assert.Contains(t, got, "100000 bytes of output truncated")
+	require.NotEmpty(t, file)
+	data, err := os.ReadFile(file) //nolint:gosec // test temp file
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data), "dropped middle streamed to the file")
+
+	t.Run("read error removes the file", func(t *testing.T) {
+		require.NoError(t, os.Remove(file))
+		r := io.MultiReader(strings.NewReader(content), iotest.ErrReader(errors.New("broken pipe")))
+		_, file, err := e.readStdout(r)
+		require.Error(t, err)
+		assert.Empty(t, file)
+		entries, err := os.ReadDir(tmp)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestClaudeExecutor_parseStream_SpillOutput(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	e := &ClaudeExecutor{MaxOutputBytes: 20, SpillOutput: true}
+	input := strings.Repeat("line of plain text\n", 10)
+
+	result := e.parseStream(t.Context(), strings.NewReader(input))
+
+	require.NoError(t, result.Error)
+	assert.Contains(t, result.Output, "bytes of output truncated")
+	require.NotEmpty(t, result.OutputFile)
+	data, err := os.ReadFile(result.OutputFile) //nolint:gosec // test temp file
+	require.NoError(t, err)
+	assert.Equal(t, input, string(data))
+}
+
+func TestCustomExecutor_processOutput_SpillOutput(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	e := &CustomExecutor{MaxOutputBytes: 20, SpillOutput: true}
+	input := strings.Repeat("finding in file.go\n", 10)
+
+	output, file, _, err := e.processOutput(t.Context(), strings.NewReader(input))
+
+	require.NoError(t, err)
+	assert.Contains(t, output, "bytes of output truncated")
+	require.NotEmpty(t, file)
+	data, err := os.ReadFile(file) //nolint:gosec // test temp file
+	require.NoError(t, err)
+	assert.Equal(t, input, string(data))
+}
@@ -0,0 +1,32 @@
+package executor
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Panic is a panic recovered in a goroutine started by a call, passed on to the calling goroutine
+// with the stack of the original panic, see CatchPanic.
+type Panic struct {
+	Value any
+	Stack []byte
+}
+
+// String returns the panic value with its stack.
+func (p *Panic) String() string {
+	return fmt.Sprintf("%v\n\n%s", p.Value, p.Stack)
+}
+
+// CatchPanic recovers a panic of the goroutine it is deferred in and stores it in dst, so the goroutine
+// waiting for it can re-panic with it. must be deferred directly.
+func CatchPanic(dst **Panic) {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+	if p, ok := rec.(*Panic); ok {
+		*dst = p // passed on from a nested goroutine, keep the original stack
+		return
+	}
+	*dst = &Panic{Value: rec, Stack: debug.Stack()}
+}
@@ -0,0 +1,32 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatchPanic(t *testing.T) {
+	run := func(f func()) (p *Panic) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer CatchPanic(&p)
+			f()
+		}()
+		<-done
+		return p
+	}
+
+	assert.Nil(t, run(func() {}))
+
+	p := run(func() { panic("boom") })
+	require.NotNil(t, p)
+	assert.Equal(t, "boom", p.Value)
+	assert.Contains(t, string(p.Stack), "panic_test.go")
+	assert.Contains(t, p.String(), "boom\n\n")
+
+	nested := run(func() { panic(p) })
+	assert.Same(t, p, nested, "a passed on panic keeps its original stack")
+}
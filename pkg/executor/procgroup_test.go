@@ -53,6 +53,23 @@ func TestExecClaudeRunner_KillsProcessGroup(t *testing.T) {
 		"child process (PID %d) should be killed when parent's process group is killed", childPID)
 }
 
+func TestProcessGroupCleanup_WaitsForKill(t *testing.T) {
+	// bash exits on SIGTERM right away, wait returns only after the SIGKILL of the whole group
+	// following it, so nothing survives ralphex exiting after the wait
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := &execClaudeRunner{}
+	stdout, wait, err := runner.Run(ctx, "bash", "-c", `sleep 300 & echo "CHILD_PID:$!"; wait`)
+	require.NoError(t, err)
+	require.NotZero(t, readChildPID(t, stdout))
+
+	start := time.Now()
+	cancel()
+	require.Error(t, wait())
+	assert.GreaterOrEqual(t, time.Since(start), gracefulShutdownDelay, "wait returned before the kill completed")
+}
+
 func TestProcessGroupCleanup_Idempotent(t *testing.T) {
 	// verify that Wait() can be called multiple times without panicking
 
@@ -18,10 +18,11 @@ const gracefulShutdownDelay = 100 * time.Millisecond
 // It ensures that when context is canceled, the entire process tree is killed,
 // not just the direct child process.
 type processGroupCleanup struct {
-	cmd  *exec.Cmd
-	done chan struct{}
-	once sync.Once
-	err  error
+	cmd     *exec.Cmd
+	done    chan struct{}
+	watched chan struct{} // closed when watchForCancel returns, after the kill it started
+	once    sync.Once
+	err     error
 }
 
 // setupProcessGroup configures command to run in its own session and process group.
@@ -39,8 +40,9 @@ func setupProcessGroup(cmd *exec.Cmd) {
 // Caller must eventually call Wait() to ensure proper resource cleanup.
 func newProcessGroupCleanup(cmd *exec.Cmd, cancelCh <-chan struct{}) *processGroupCleanup {
 	pg := &processGroupCleanup{
-		cmd:  cmd,
-		done: make(chan struct{}),
+		cmd:     cmd,
+		done:    make(chan struct{}),
+		watched: make(chan struct{}),
 	}
 
 	// monitor for cancellation in background
@@ -51,6 +53,7 @@ func newProcessGroupCleanup(cmd *exec.Cmd, cancelCh <-chan struct{}) *processGro
 
 // watchForCancel monitors the cancel channel and kills the process group if triggered.
 func (pg *processGroupCleanup) watchForCancel(cancelCh <-chan struct{}) {
+	defer close(pg.watched)
 	select {
 	case <-cancelCh:
 		pg.killProcessGroup()
@@ -89,13 +92,15 @@ func (pg *processGroupCleanup) killProcessGroup() {
 	}
 }
 
-// Wait waits for the command to complete and cleans up resources.
+// Wait waits for the command to complete and cleans up resources. when the command was canceled, it returns
+// after the kill is done, so no process of the command outlives ralphex exiting right after.
 // It is safe to call multiple times - subsequent calls return the cached result.
 // Callers must eventually call Wait to avoid leaking resources.
 func (pg *processGroupCleanup) Wait() error {
 	pg.once.Do(func() {
 		pg.err = pg.cmd.Wait()
 		close(pg.done)
+		<-pg.watched // a kill in progress completes, the direct child can exit before the rest of its group is killed
 		if pg.err != nil {
 			pg.err = fmt.Errorf("command wait: %w", pg.err)
 		}
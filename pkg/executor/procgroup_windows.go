@@ -11,10 +11,11 @@ import (
 // processGroupCleanup manages process lifecycle for graceful shutdown on Windows.
 // Note: Windows doesn't support Unix process groups, so this only kills the direct process.
 type processGroupCleanup struct {
-	cmd  *exec.Cmd
-	done chan struct{}
-	once sync.Once
-	err  error
+	cmd     *exec.Cmd
+	done    chan struct{}
+	watched chan struct{} // closed when watchForCancel returns, after the kill it started
+	once    sync.Once
+	err     error
 }
 
 // setupProcessGroup is a no-op on Windows since process groups work differently.
@@ -27,8 +28,9 @@ func setupProcessGroup(_ *exec.Cmd) {
 // Caller must eventually call Wait() to ensure proper resource cleanup.
 func newProcessGroupCleanup(cmd *exec.Cmd, cancelCh <-chan struct{}) *processGroupCleanup {
 	pg := &processGroupCleanup{
-		cmd:  cmd,
-		done: make(chan struct{}),
+		cmd:     cmd,
+		done:    make(chan struct{}),
+		watched: make(chan struct{}),
 	}
 
 	// monitor for cancellation in background
@@ -39,6 +41,7 @@ func newProcessGroupCleanup(cmd *exec.Cmd, cancelCh <-chan struct{}) *processGro
 
 // watchForCancel monitors the cancel channel and kills the process if triggered.
 func (pg *processGroupCleanup) watchForCancel(cancelCh <-chan struct{}) {
+	defer close(pg.watched)
 	select {
 	case <-cancelCh:
 		pg.killProcess()
@@ -59,13 +62,15 @@ func (pg *processGroupCleanup) killProcess() {
 	_ = process.Kill()
 }
 
-// Wait waits for the command to complete and cleans up resources.
+// Wait waits for the command to complete and cleans up resources. when the command was canceled,
+// it returns after the kill is done.
 // It is safe to call multiple times - subsequent calls return the cached result.
 // Callers must eventually call Wait to avoid leaking resources.
 func (pg *processGroupCleanup) Wait() error {
 	pg.once.Do(func() {
 		pg.err = pg.cmd.Wait()
 		close(pg.done)
+		<-pg.watched // a kill in progress completes before the result is returned
 		if pg.err != nil {
 			pg.err = fmt.Errorf("command wait: %w", pg.err)
 		}
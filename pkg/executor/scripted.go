@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScriptStep is one scripted agent response of ScriptedExecutor.
+type ScriptStep struct {
+	Output string       // response text, streamed to OutputHandler line by line
+	Signal string       // signal returned with the response, empty for none
+	Action func() error // optional side effect run before responding, e.g. editing files like an agent would
+}
+
+// ScriptedExecutor replays scripted responses instead of running an agent CLI.
+// it backs demo mode, showing a run without external tools or network access.
+type ScriptedExecutor struct {
+	Steps         []ScriptStep      // responses, one per Run call in order
+	OutputHandler func(text string) // called for each output line, can be nil
+	LineDelay     time.Duration     // pause between streamed lines, simulates the pace of a real agent
+
+	mu   sync.Mutex
+	next int
+}
+
+// Run returns the next scripted response, ignoring the prompt. an error is returned once the script is exhausted.
+func (e *ScriptedExecutor) Run(ctx context.Context, _ string) Result {
+	e.mu.Lock()
+	if e.next >= len(e.Steps) {
+		e.mu.Unlock()
+		return Result{Error: fmt.Errorf("script exhausted after %d steps", len(e.Steps))}
+	}
+	step := e.Steps[e.next]
+	e.next++
+	e.mu.Unlock()
+
+	if step.Action != nil {
+		if err := step.Action(); err != nil {
+			return Result{Error: fmt.Errorf("scripted action: %w", err)}
+		}
+	}
+
+	output := step.Output
+	if step.Signal != "" {
+		output = strings.TrimRight(output, "\n") + "\n\n" + step.Signal
+	}
+	for line := range strings.SplitSeq(strings.TrimRight(output, "\n"), "\n") {
+		if e.LineDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return Result{Output: output, Error: fmt.Errorf("scripted run: %w", ctx.Err())}
+			case <-time.After(e.LineDelay):
+			}
+		}
+		if e.OutputHandler != nil {
+			e.OutputHandler(line + "\n")
+		}
+	}
+	return Result{Output: output, Signal: step.Signal}
+}
@@ -0,0 +1,52 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptedExecutor_Run(t *testing.T) {
+	var lines []string
+	actions := 0
+	e := &ScriptedExecutor{
+		Steps: []ScriptStep{
+			{Output: "working on task 1\ndone", Action: func() error { actions++; return nil }},
+			{Output: "all done\n", Signal: "<<<RALPHEX:ALL_TASKS_DONE>>>"},
+		},
+		OutputHandler: func(text string) { lines = append(lines, text) },
+	}
+
+	res := e.Run(context.Background(), "prompt")
+	require.NoError(t, res.Error)
+	assert.Equal(t, "working on task 1\ndone", res.Output)
+	assert.Empty(t, res.Signal)
+	assert.Equal(t, 1, actions)
+
+	res = e.Run(context.Background(), "prompt")
+	require.NoError(t, res.Error)
+	assert.Equal(t, "<<<RALPHEX:ALL_TASKS_DONE>>>", res.Signal)
+	assert.Equal(t, "all done\n\n<<<RALPHEX:ALL_TASKS_DONE>>>", res.Output)
+	assert.Equal(t, []string{"working on task 1\n", "done\n", "all done\n", "\n", "<<<RALPHEX:ALL_TASKS_DONE>>>\n"}, lines)
+
+	res = e.Run(context.Background(), "prompt")
+	require.EqualError(t, res.Error, "script exhausted after 2 steps")
+}
+
+func TestScriptedExecutor_Run_ActionError(t *testing.T) {
+	e := &ScriptedExecutor{Steps: []ScriptStep{{Output: "x", Action: func() error { return errors.New("disk full") }}}}
+	res := e.Run(context.Background(), "prompt")
+	require.EqualError(t, res.Error, "scripted action: disk full")
+}
+
+func TestScriptedExecutor_Run_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	e := &ScriptedExecutor{Steps: []ScriptStep{{Output: "x"}}, LineDelay: time.Hour}
+	res := e.Run(ctx, "prompt")
+	require.ErrorIs(t, res.Error, context.Canceled)
+}
@@ -1,31 +1,50 @@
 package git
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+
+	"github.com/umputun/ralphex/pkg/debuglog"
 )
 
+// defaultGitCommand is the git binary used when none is configured.
+const defaultGitCommand = "git"
+
 // externalBackend implements the backend interface by shelling out to the git CLI.
 type externalBackend struct {
-	path string // absolute path to repository root
+	path    string         // absolute path to repository root
+	gitPath string         // git binary to run
+	debug   debuglog.Flags // git traces commands run
 }
 
-// newExternalBackend creates an externalBackend that shells out to the git CLI.
+// newExternalBackend creates an externalBackend that shells out to the default git CLI.
 // validates the path is inside a git repository using git rev-parse.
 func newExternalBackend(path string) (*externalBackend, error) {
+	return newExternalBackendWithCommand(path, defaultGitCommand)
+}
+
+// newExternalBackendWithCommand creates an externalBackend running the given git binary.
+// an empty command uses "git" from PATH.
+func newExternalBackendWithCommand(path, command string) (*externalBackend, error) {
+	if command == "" {
+		command = defaultGitCommand
+	}
+
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		return nil, fmt.Errorf("resolve path: %w", err)
 	}
 
 	// validate path is a git repo and get the toplevel
-	cmd := exec.CommandContext(context.Background(), "git", "rev-parse", "--show-toplevel")
+	cmd := exec.CommandContext(context.Background(), command, "rev-parse", "--show-toplevel")
 	cmd.Dir = absPath
 	out, err := cmd.Output()
 	if err != nil {
@@ -44,15 +63,22 @@ func newExternalBackend(path string) (*externalBackend, error) {
 		return nil, fmt.Errorf("eval symlinks: %w", err)
 	}
 
-	return &externalBackend{path: root}, nil
+	return &externalBackend{path: root, gitPath: command}, nil
+}
+
+// command builds a git command running in the repository root.
+func (e *externalBackend) command(args ...string) *exec.Cmd {
+	e.debug.Printf(debuglog.Git, "%s %s", e.gitPath, strings.Join(args, " "))
+	cmd := exec.CommandContext(context.Background(), e.gitPath, args...)
+	cmd.Dir = e.path
+	return cmd
 }
 
 // run executes a git command and returns combined stdout+stderr with trailing whitespace removed.
 // leading whitespace is preserved (important for porcelain format parsing).
 // on failure, returns error with the combined output for diagnostics.
 func (e *externalBackend) run(args ...string) (string, error) {
-	cmd := exec.CommandContext(context.Background(), "git", args...)
-	cmd.Dir = e.path
+	cmd := e.command(args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		msg := strings.TrimSpace(string(out))
@@ -83,8 +109,7 @@ func (e *externalBackend) headHash() (string, error) {
 
 // HasCommits returns true if the repository has at least one commit.
 func (e *externalBackend) HasCommits() (bool, error) {
-	cmd := exec.CommandContext(context.Background(), "git", "rev-parse", "HEAD")
-	cmd.Dir = e.path
+	cmd := e.command("rev-parse", "HEAD")
 	cmd.Env = append(os.Environ(), "LC_ALL=C") // force English stderr for reliable parsing
 	if _, err := cmd.Output(); err != nil {
 		var exitErr *exec.ExitError
@@ -105,8 +130,7 @@ func (e *externalBackend) HasCommits() (bool, error) {
 
 // CurrentBranch returns the name of the current branch, or empty string for detached HEAD.
 func (e *externalBackend) CurrentBranch() (string, error) {
-	cmd := exec.CommandContext(context.Background(), "git", "symbolic-ref", "--short", "HEAD")
-	cmd.Dir = e.path
+	cmd := e.command("symbolic-ref", "--short", "HEAD")
 	cmd.Env = append(os.Environ(), "LC_ALL=C") // force English stderr for reliable parsing
 	out, err := cmd.Output()
 	if err != nil {
@@ -129,8 +153,7 @@ func (e *externalBackend) CurrentBranch() (string, error) {
 // detects from origin/HEAD symbolic reference, falls back to checking common branch names.
 func (e *externalBackend) GetDefaultBranch() string {
 	// try origin/HEAD first
-	cmd := exec.CommandContext(context.Background(), "git", "symbolic-ref", "refs/remotes/origin/HEAD")
-	cmd.Dir = e.path
+	cmd := e.command("symbolic-ref", "refs/remotes/origin/HEAD")
 	out, err := cmd.Output()
 	if err == nil {
 		ref := strings.TrimSpace(string(out))
@@ -253,8 +276,7 @@ func (e *externalBackend) HasChangesOtherThan(path string) (bool, error) {
 
 // IsIgnored checks if a path is ignored by gitignore rules.
 func (e *externalBackend) IsIgnored(path string) (bool, error) {
-	cmd := exec.CommandContext(context.Background(), "git", "check-ignore", "-q", "--", path)
-	cmd.Dir = e.path
+	cmd := e.command("check-ignore", "-q", "--", path)
 	err := cmd.Run()
 	if err == nil {
 		return true, nil // exit 0 = ignored
@@ -347,8 +369,7 @@ func (e *externalBackend) diffStats(baseBranch string) (DiffStats, error) {
 		return DiffStats{}, nil //nolint:nilerr // no HEAD means no stats
 	}
 
-	baseCmd := exec.CommandContext(context.Background(), "git", "rev-parse", baseRef)
-	baseCmd.Dir = e.path
+	baseCmd := e.command("rev-parse", baseRef)
 	baseOut, err := baseCmd.Output()
 	if err != nil {
 		return DiffStats{}, nil //nolint:nilerr // can't resolve base, return zero
@@ -390,6 +411,407 @@ func (e *externalBackend) diffStats(baseBranch string) (DiffStats, error) {
 	return result, nil
 }
 
+// specialChanges finds submodule pointer changes and LFS-tracked files in the diff.
+// submodules are detected by gitlink mode (160000) in raw diff output,
+// LFS files by the "filter=lfs" attribute of the remaining changed paths.
+func (e *externalBackend) specialChanges(baseBranch string) (SpecialChanges, error) {
+	args := []string{"diff", "--raw", "--no-abbrev", "-z"}
+	if baseBranch != "" {
+		baseRef := e.resolveRef(baseBranch)
+		if baseRef == "" {
+			return SpecialChanges{}, nil
+		}
+		args = append(args, baseRef+"...HEAD")
+	}
+	out, err := e.command(args...).Output() // stdout only, stderr warnings would break parsing
+	if err != nil {
+		return SpecialChanges{}, fmt.Errorf("diff raw: %w", err)
+	}
+
+	var res SpecialChanges
+	var paths []string
+	for _, entry := range parseRawDiff(string(out)) {
+		if entry.oldMode == gitlinkMode || entry.newMode == gitlinkMode {
+			res.Submodules = append(res.Submodules, entry.submodule())
+			continue
+		}
+		if entry.newMode != nullMode { // deleted files have no content to review either way
+			paths = append(paths, entry.path)
+		}
+	}
+
+	if res.LFSFiles, err = e.lfsFiles(paths); err != nil {
+		return SpecialChanges{}, err
+	}
+	return res, nil
+}
+
+// changedFiles lists paths changed in the working tree since the merge base with baseBranch, plus untracked files.
+func (e *externalBackend) changedFiles(baseBranch string) ([]string, error) {
+	base := "HEAD"
+	if baseRef := e.resolveRef(baseBranch); baseRef != "" {
+		base = baseRef
+		if out, err := e.run("merge-base", baseRef, "HEAD"); err == nil && out != "" {
+			base = out
+		}
+	}
+
+	diffOut, err := e.command("diff", "--name-only", "--no-renames", "-z", base).Output()
+	if err != nil {
+		return nil, fmt.Errorf("diff names: %w", err)
+	}
+	untrackedOut, err := e.command("ls-files", "--others", "--exclude-standard", "-z").Output()
+	if err != nil {
+		return nil, fmt.Errorf("list untracked: %w", err)
+	}
+
+	var res []string
+	for _, out := range [][]byte{diffOut, untrackedOut} {
+		for p := range strings.SplitSeq(string(out), "\x00") {
+			if p != "" {
+				res = append(res, p)
+			}
+		}
+	}
+	slices.Sort(res)
+	return slices.Compact(res), nil
+}
+
+// formatPatches runs git format-patch for baseBranch..HEAD into dir.
+func (e *externalBackend) formatPatches(baseBranch, dir string) ([]string, error) {
+	baseRef := e.resolveRef(baseBranch)
+	if baseRef == "" {
+		return nil, nil
+	}
+	out, err := e.command("format-patch", "--no-color", "--output-directory", dir, baseRef+"..HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("format-patch: %w", err)
+	}
+	var res []string
+	for line := range strings.SplitSeq(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			res = append(res, line)
+		}
+	}
+	return res, nil
+}
+
+// diffSince runs git diff with stat against rev.
+func (e *externalBackend) diffSince(rev string) (string, error) {
+	out, err := e.command("diff", "--no-color", "--no-ext-diff", "--stat", "--patch", rev, "--").Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff: %w", err)
+	}
+	return string(out), nil
+}
+
+// isClean returns true if the worktree has no uncommitted changes or untracked files, ignored files aside.
+func (e *externalBackend) isClean() (bool, error) {
+	out, err := e.run("status", "--porcelain")
+	if err != nil {
+		return false, fmt.Errorf("get status: %w", err)
+	}
+	return out == "", nil
+}
+
+// extractChanges commits uncommitted changes, formats commits since rev as a single patch series
+// and resets the worktree to rev. on failure before the reset, changes are left committed.
+func (e *externalBackend) extractChanges(rev string) ([]byte, error) {
+	if _, err := e.run("add", "--all"); err != nil {
+		return nil, fmt.Errorf("stage changes: %w", err)
+	}
+	// exit code 1 means there are staged changes
+	if err := e.command("diff", "--cached", "--quiet").Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("check staged changes: %w", err)
+		}
+		if _, err := e.run("commit", "--no-verify", "-m", "uncommitted changes"); err != nil {
+			return nil, fmt.Errorf("commit changes: %w", err)
+		}
+	}
+	out, err := e.command("format-patch", "--stdout", "--no-color", "--binary", rev+"..HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("format-patch: %w", err)
+	}
+	if _, err := e.run("reset", "--hard", rev); err != nil {
+		return nil, fmt.Errorf("reset worktree: %w", err)
+	}
+	return out, nil
+}
+
+// snapshot records HEAD and, for a dirty worktree, a commit of its content made through a temporary index,
+// so the real index stays untouched. the commit is not referenced by any branch.
+func (e *externalBackend) snapshot() (Snapshot, error) {
+	head, err := e.headHash()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	clean, err := e.isClean()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if clean {
+		return Snapshot{Head: head, State: head}, nil
+	}
+
+	tmp, err := os.MkdirTemp("", "ralphex-snapshot-")
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(tmp, "index"),
+		"GIT_AUTHOR_NAME=ralphex", "GIT_AUTHOR_EMAIL=ralphex@localhost",
+		"GIT_COMMITTER_NAME=ralphex", "GIT_COMMITTER_EMAIL=ralphex@localhost")
+	runEnv := func(args ...string) (string, error) {
+		cmd := e.command(args...)
+		cmd.Env = env
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	if _, err := runEnv("read-tree", head); err != nil {
+		return Snapshot{}, err
+	}
+	if _, err := runEnv("add", "--all"); err != nil {
+		return Snapshot{}, err
+	}
+	tree, err := runEnv("write-tree")
+	if err != nil {
+		return Snapshot{}, err
+	}
+	state, err := runEnv("commit-tree", tree, "-p", head, "-m", "ralphex worktree snapshot")
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Head: head, State: state}, nil
+}
+
+// restoreSnapshot resets to the snapshot's HEAD, removes untracked files and brings back the
+// snapshot's worktree content as uncommitted changes.
+func (e *externalBackend) restoreSnapshot(snap Snapshot) error {
+	if _, err := e.run("reset", "--hard", "--quiet", snap.Head); err != nil {
+		return err
+	}
+	if _, err := e.run("clean", "-fd", "--quiet"); err != nil {
+		return err
+	}
+	if snap.State == "" || snap.State == snap.Head {
+		return nil
+	}
+	if _, err := e.run("read-tree", "--reset", "-u", snap.State); err != nil {
+		return err
+	}
+	// back to HEAD in the index, the snapshot's changes stay in the worktree
+	if _, err := e.run("reset", "--quiet"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// applyPatch applies and commits a patch with git am, aborting it on failure.
+func (e *externalBackend) applyPatch(text []byte) error {
+	cmd := e.command("am", "--3way")
+	cmd.Stdin = bytes.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		_, _ = e.run("am", "--abort")
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("git am: %s", msg)
+		}
+		return fmt.Errorf("git am: %w", err)
+	}
+	return nil
+}
+
+// lfsFiles returns paths that have the "filter=lfs" attribute.
+func (e *externalBackend) lfsFiles(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	cmd := e.command("check-attr", "-z", "--stdin", "filter")
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("check-attr: %w", err)
+	}
+
+	// output is a sequence of <path> NUL <attribute> NUL <value> NUL
+	var res []string
+	fields := strings.Split(string(out), "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if fields[i+2] == "lfs" {
+			res = append(res, fields[i])
+		}
+	}
+	return res, nil
+}
+
+// prepareDiff implements Service.PrepareDiff.
+func (e *externalBackend) prepareDiff(baseBranch string, fetch bool) (DiffReadiness, error) {
+	remote := e.promisorRemote()
+	res := DiffReadiness{PartialClone: remote != "", SparseCheckout: e.configTrue("core.sparseCheckout")}
+	if !res.PartialClone && !res.SparseCheckout {
+		return res, nil
+	}
+	baseRef := e.resolveRef(baseBranch)
+	if baseRef == "" {
+		return res, nil
+	}
+
+	// --no-renames avoids rename detection, which would read (and lazily fetch) blob contents
+	out, err := e.command("diff", "--raw", "--no-abbrev", "--no-renames", "-z", baseRef+"...HEAD").Output()
+	if err != nil {
+		return res, fmt.Errorf("diff raw: %w", err)
+	}
+	entries := parseRawDiff(string(out))
+
+	if res.PartialClone {
+		var missing []string
+		for _, d := range entries {
+			if d.oldMode == gitlinkMode || d.newMode == gitlinkMode {
+				continue // submodule commits live in another repository
+			}
+			for _, id := range []string{d.oldHash, d.newHash} {
+				if strings.Trim(id, "0") != "" && !e.hasObject(id) {
+					missing = append(missing, id)
+				}
+			}
+		}
+		res.Missing = len(missing)
+		if fetch && len(missing) > 0 {
+			if err := e.fetchObjects(remote, missing); err != nil {
+				return res, err
+			}
+			res.Fetched = len(missing)
+		}
+	}
+
+	if res.SparseCheckout {
+		var paths []string
+		for _, d := range entries {
+			if d.newMode != nullMode && d.newMode != gitlinkMode {
+				paths = append(paths, d.path)
+			}
+		}
+		if res.OutsideSparse, err = e.skipWorktree(paths); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// promisorRemote returns the remote objects are lazily fetched from, empty if this is not a partial clone.
+func (e *externalBackend) promisorRemote() string {
+	if out, err := e.run("config", "--get", "extensions.partialClone"); err == nil && out != "" {
+		return out
+	}
+	out, err := e.run("config", "--get-regexp", `^remote\..*\.promisor$`)
+	if err != nil {
+		return ""
+	}
+	for line := range strings.SplitSeq(out, "\n") {
+		key, val, ok := strings.Cut(line, " ")
+		if ok && val == "true" {
+			return strings.TrimSuffix(strings.TrimPrefix(key, "remote."), ".promisor")
+		}
+	}
+	return ""
+}
+
+// configTrue returns true if a boolean git config key is set to true.
+func (e *externalBackend) configTrue(key string) bool {
+	out, err := e.run("config", "--bool", "--get", key)
+	return err == nil && out == "true"
+}
+
+// hasObject checks whether an object is present locally, without triggering a lazy fetch.
+func (e *externalBackend) hasObject(id string) bool {
+	cmd := e.command("cat-file", "-e", id)
+	cmd.Env = append(os.Environ(), "GIT_NO_LAZY_FETCH=1")
+	return cmd.Run() == nil
+}
+
+// objectBatchSize limits the number of ids or paths passed on a single git command line.
+const objectBatchSize = 500
+
+// fetchObjects fetches objects by id from the promisor remote, in batches.
+// this is what git does for lazy fetches, but done once per batch instead of once per object.
+func (e *externalBackend) fetchObjects(remote string, ids []string) error {
+	for batch := range slices.Chunk(ids, objectBatchSize) {
+		args := append([]string{"-c", "fetch.negotiationAlgorithm=noop", "fetch", remote, "--no-tags",
+			"--no-write-fetch-head", "--recurse-submodules=no", "--filter=blob:none"}, batch...)
+		if _, err := e.run(args...); err != nil {
+			return fmt.Errorf("fetch missing objects: %w", err)
+		}
+	}
+	return nil
+}
+
+// skipWorktree returns paths that are marked skip-worktree, i.e. not checked out in a sparse checkout.
+func (e *externalBackend) skipWorktree(paths []string) ([]string, error) {
+	var res []string
+	for batch := range slices.Chunk(paths, objectBatchSize) {
+		out, err := e.command(append([]string{"ls-files", "-t", "-z", "--"}, batch...)...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("ls-files: %w", err)
+		}
+		for entry := range strings.SplitSeq(string(out), "\x00") {
+			if path, ok := strings.CutPrefix(entry, "S "); ok {
+				res = append(res, path)
+			}
+		}
+	}
+	return res, nil
+}
+
+const (
+	gitlinkMode = "160000" // tree entry mode of a submodule commit
+	nullMode    = "000000" // mode of a missing side (added or deleted path)
+)
+
+// rawDiffEntry is a single record of "git diff --raw -z" output.
+type rawDiffEntry struct {
+	oldMode, newMode string
+	oldHash, newHash string
+	path             string
+}
+
+// submodule converts a gitlink entry to SubmoduleChange.
+func (d rawDiffEntry) submodule() SubmoduleChange {
+	res := SubmoduleChange{Path: d.path}
+	if d.oldMode == gitlinkMode {
+		res.From = d.oldHash
+	}
+	if d.newMode == gitlinkMode {
+		res.To = d.newHash
+	}
+	return res
+}
+
+// parseRawDiff parses NUL-separated "git diff --raw -z" output.
+// each record is ":<old mode> <new mode> <old hash> <new hash> <status>" followed by one path,
+// or two paths (source and destination) for renames and copies.
+func parseRawDiff(out string) []rawDiffEntry {
+	fields := strings.Split(out, "\x00")
+	var res []rawDiffEntry
+	for i := 0; i < len(fields); i++ {
+		meta := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if len(meta) < 5 || i+1 >= len(fields) {
+			continue
+		}
+		entry := rawDiffEntry{oldMode: meta[0], newMode: meta[1], oldHash: meta[2], newHash: meta[3], path: fields[i+1]}
+		i++
+		if status := meta[4]; (status[0] == 'R' || status[0] == 'C') && i+1 < len(fields) {
+			entry.path = fields[i+1] // destination path
+			i++
+		}
+		res = append(res, entry)
+	}
+	return res
+}
+
 // resolveRef tries to resolve a branch name to a valid git ref.
 // checks local branch, remote tracking (origin/<name>), "origin/" prefixed names,
 // and finally arbitrary refs like commit hashes or tags via rev-parse.
@@ -413,8 +835,7 @@ func (e *externalBackend) resolveRef(branchName string) string {
 	}
 
 	// try as arbitrary ref (commit hash, tag, etc.) via rev-parse
-	cmd := exec.CommandContext(context.Background(), "git", "rev-parse", "--verify", "--quiet", branchName)
-	cmd.Dir = e.path
+	cmd := e.command("rev-parse", "--verify", "--quiet", branchName)
 	if cmd.Run() == nil {
 		return branchName
 	}
@@ -424,8 +845,7 @@ func (e *externalBackend) resolveRef(branchName string) string {
 
 // refExists checks if a git reference exists.
 func (e *externalBackend) refExists(ref string) bool {
-	cmd := exec.CommandContext(context.Background(), "git", "show-ref", "--verify", "--quiet", ref)
-	cmd.Dir = e.path
+	cmd := e.command("show-ref", "--verify", "--quiet", ref)
 	return cmd.Run() == nil
 }
 
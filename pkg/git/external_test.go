@@ -832,3 +832,204 @@ func TestExternalBackend_extractPathFromPorcelain(t *testing.T) {
 		assert.Empty(t, eb.extractPathFromPorcelain("??"))
 	})
 }
+
+func TestExternalBackend_specialChanges(t *testing.T) {
+	const subHash = "1234567890123456789012345678901234567890"
+
+	t.Run("finds submodule and lfs changes on branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		runGit(t, dir, "checkout", "-b", "feature")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte("*.bin filter=lfs diff=lfs merge=lfs -text\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "model.bin"), []byte("version https://git-lfs.github.com/spec/v1\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o600))
+		runGit(t, dir, "add", ".gitattributes", "model.bin", "main.go")
+		runGit(t, dir, "update-index", "--add", "--cacheinfo", "160000,"+subHash+",libs/dep")
+		runGit(t, dir, "commit", "-m", "add files")
+
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+		sc, err := eb.specialChanges("master")
+		require.NoError(t, err)
+		assert.Equal(t, []SubmoduleChange{{Path: "libs/dep", To: subHash}}, sc.Submodules)
+		assert.Equal(t, []string{"model.bin"}, sc.LFSFiles)
+	})
+
+	t.Run("plain changes are not special", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# changed\n"), 0o600))
+
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+		sc, err := eb.specialChanges("")
+		require.NoError(t, err)
+		assert.True(t, sc.Empty())
+	})
+
+	t.Run("nonexistent base branch returns zero value", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+		sc, err := eb.specialChanges("nonexistent")
+		require.NoError(t, err)
+		assert.True(t, sc.Empty())
+	})
+}
+
+func TestParseRawDiff(t *testing.T) {
+	out := ":100644 100644 aaa bbb M\x00main.go\x00" +
+		":000000 160000 000 ccc A\x00libs/dep\x00" +
+		":100644 100644 ddd eee R087\x00old.go\x00new.go\x00"
+
+	assert.Equal(t, []rawDiffEntry{
+		{oldMode: "100644", newMode: "100644", oldHash: "aaa", newHash: "bbb", path: "main.go"},
+		{oldMode: "000000", newMode: "160000", oldHash: "000", newHash: "ccc", path: "libs/dep"},
+		{oldMode: "100644", newMode: "100644", oldHash: "ddd", newHash: "eee", path: "new.go"},
+	}, parseRawDiff(out))
+	assert.Empty(t, parseRawDiff(""))
+}
+
+// setupPartialClone creates a repo with master and feature branches and a blob-less clone of it
+func TestExternalBackend_changedFiles(t *testing.T) {
+	t.Run("branch commits, uncommitted and untracked files", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "old.go"), []byte("package old\n"), 0o600))
+		runGit(t, dir, "add", "old.go")
+		runGit(t, dir, "commit", "-m", "add old")
+		runGit(t, dir, "checkout", "-b", "feature")
+
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "a.go"), []byte("package pkg\n"), 0o600))
+		runGit(t, dir, "add", "pkg/a.go")
+		runGit(t, dir, "mv", "old.go", "new.go")
+		runGit(t, dir, "commit", "-m", "feature work")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# changed\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "untracked.txt"), []byte("x"), 0o600))
+
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+		files, err := eb.changedFiles("master")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"README.md", "new.go", "old.go", "pkg/a.go", "untracked.txt"}, files)
+	})
+
+	t.Run("nonexistent base branch lists uncommitted changes only", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# changed\n"), 0o600))
+
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+		files, err := eb.changedFiles("nonexistent")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"README.md"}, files)
+	})
+}
+
+func TestExternalBackend_formatPatches(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	runGit(t, dir, "checkout", "-b", "feature")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0o600))
+	runGit(t, dir, "add", "a.go")
+	runGit(t, dir, "commit", "-m", "add a")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.go"), []byte("package a\n"), 0o600))
+	runGit(t, dir, "add", "b.go")
+	runGit(t, dir, "commit", "-m", "add b")
+
+	eb, err := newExternalBackend(dir)
+	require.NoError(t, err)
+
+	t.Run("patch per branch commit", func(t *testing.T) {
+		out := t.TempDir()
+		files, err := eb.formatPatches("master", out)
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		assert.Equal(t, "0001-add-a.patch", filepath.Base(files[0]))
+		assert.Equal(t, "0002-add-b.patch", filepath.Base(files[1]))
+		data, err := os.ReadFile(files[1]) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "+package a")
+	})
+
+	t.Run("nonexistent base branch", func(t *testing.T) {
+		files, err := eb.formatPatches("nonexistent", t.TempDir())
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+}
+
+// with feature checked out, so master's version of changed files is missing locally.
+func setupPartialClone(t *testing.T) string {
+	t.Helper()
+	src := setupExternalTestRepo(t)
+	runGit(t, src, "config", "uploadpack.allowFilter", "true")
+	runGit(t, src, "config", "uploadpack.allowAnySHA1InWant", "true")
+	runGit(t, src, "checkout", "-b", "feature")
+	require.NoError(t, os.WriteFile(filepath.Join(src, "README.md"), []byte("# Changed\n"), 0o600))
+	runGit(t, src, "commit", "-am", "change readme")
+	runGit(t, src, "checkout", "master")
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	runGit(t, src, "clone", "--filter=blob:none", "--no-checkout", "file://"+filepath.ToSlash(src), clone)
+	runGit(t, clone, "checkout", "feature")
+	return clone
+}
+
+func TestExternalBackend_prepareDiff(t *testing.T) {
+	t.Run("regular clone does nothing", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		res, err := eb.prepareDiff("master", true)
+		require.NoError(t, err)
+		assert.Equal(t, DiffReadiness{}, res)
+	})
+
+	t.Run("partial clone reports missing blobs without fetch", func(t *testing.T) {
+		eb, err := newExternalBackend(setupPartialClone(t))
+		require.NoError(t, err)
+
+		res, err := eb.prepareDiff("master", false)
+		require.NoError(t, err)
+		assert.True(t, res.PartialClone)
+		assert.Equal(t, 1, res.Missing, "master's README blob was never fetched")
+		assert.Zero(t, res.Fetched)
+
+		res, err = eb.prepareDiff("master", false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, res.Missing, "checking must not trigger a lazy fetch")
+	})
+
+	t.Run("partial clone fetches missing blobs", func(t *testing.T) {
+		eb, err := newExternalBackend(setupPartialClone(t))
+		require.NoError(t, err)
+
+		res, err := eb.prepareDiff("master", true)
+		require.NoError(t, err)
+		assert.Equal(t, 1, res.Missing)
+		assert.Equal(t, 1, res.Fetched)
+
+		res, err = eb.prepareDiff("master", false)
+		require.NoError(t, err)
+		assert.Zero(t, res.Missing)
+	})
+
+	t.Run("sparse checkout reports changed files outside the cone", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		runGit(t, dir, "checkout", "-b", "feature")
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "app"), 0o750))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "lib"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "app", "main.go"), []byte("package main\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "lib", "lib.go"), []byte("package lib\n"), 0o600))
+		runGit(t, dir, "add", "app", "lib")
+		runGit(t, dir, "commit", "-m", "add app and lib")
+		runGit(t, dir, "sparse-checkout", "set", "app")
+
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+		res, err := eb.prepareDiff("master", true)
+		require.NoError(t, err)
+		assert.True(t, res.SparseCheckout)
+		assert.False(t, res.PartialClone)
+		assert.Equal(t, []string{"lib/lib.go"}, res.OutsideSparse)
+	})
+}
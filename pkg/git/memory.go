@@ -0,0 +1,467 @@
+package git
+
+import (
+	"crypto/sha1" //nolint:gosec // fake commit ids only, not security sensitive
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MemoryRepo is an in-memory git repository for tests of git-dependent features.
+// it tracks branches, commits and dirty paths without touching the file system or running git.
+// working tree contents are not modeled, only which paths have uncommitted changes.
+type MemoryRepo struct {
+	mu            sync.Mutex
+	root          string
+	current       string
+	defaultBranch string
+	branches      map[string][]MemoryCommit // branch name -> commits, oldest first
+	changes       map[string]bool           // uncommitted paths (relative to root) -> staged
+	ignored       map[string]bool           // ignored paths relative to root
+	stats         map[string]DiffStats      // diff stats per base branch
+	special       map[string]SpecialChanges // special changes per base branch, "" for uncommitted
+	readiness     DiffReadiness
+	changed       map[string][]string        // changed files per base branch
+	diffs         map[string]string          // diff per revision
+	snapshots     map[string]map[string]bool // uncommitted paths per snapshot state
+}
+
+// MemoryCommit is a commit recorded by MemoryRepo.
+type MemoryCommit struct {
+	Hash    string
+	Message string
+	Files   []string // paths relative to root, sorted
+}
+
+// NewMemoryRepo creates an in-memory repository rooted at root with a single empty branch.
+// the branch has no commits until CreateInitialCommit or Commit is called.
+func NewMemoryRepo(root, branch string) *MemoryRepo {
+	return &MemoryRepo{
+		root:          root,
+		current:       branch,
+		defaultBranch: branch,
+		branches:      map[string][]MemoryCommit{branch: nil},
+		changes:       map[string]bool{},
+		ignored:       map[string]bool{},
+		stats:         map[string]DiffStats{},
+		special:       map[string]SpecialChanges{},
+		changed:       map[string][]string{},
+		diffs:         map[string]string{},
+		snapshots:     map[string]map[string]bool{},
+	}
+}
+
+// NewMemoryService returns a Service backed by repo.
+func NewMemoryService(repo *MemoryRepo, log Logger) *Service {
+	return &Service{repo: repo, log: log}
+}
+
+// Touch marks paths as having unstaged changes, like editing files in the working tree.
+func (m *MemoryRepo) Touch(paths ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range paths {
+		rel := m.rel(p)
+		if !m.changes[rel] {
+			m.changes[rel] = false
+		}
+	}
+}
+
+// SetIgnored marks paths as ignored by .gitignore.
+func (m *MemoryRepo) SetIgnored(paths ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range paths {
+		m.ignored[m.rel(p)] = true
+	}
+}
+
+// SetDiffStats sets the stats returned by DiffStats for baseBranch.
+func (m *MemoryRepo) SetDiffStats(baseBranch string, stats DiffStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats[baseBranch] = stats
+}
+
+// SetSpecialChanges sets the result of SpecialChanges for baseBranch, "" for uncommitted changes.
+func (m *MemoryRepo) SetSpecialChanges(baseBranch string, changes SpecialChanges) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.special[baseBranch] = changes
+}
+
+// SetDiffReadiness sets the result of PrepareDiff. Fetched is reported only when fetching is requested.
+func (m *MemoryRepo) SetDiffReadiness(r DiffReadiness) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.readiness = r
+}
+
+// SetChangedFiles sets the result of ChangedFiles for baseBranch.
+func (m *MemoryRepo) SetChangedFiles(baseBranch string, files []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.changed[baseBranch] = slices.Clone(files)
+}
+
+// SetDiffSince sets the result of DiffSince for rev.
+func (m *MemoryRepo) SetDiffSince(rev, diff string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.diffs[rev] = diff
+}
+
+// Commits returns commits of the branch, oldest first.
+func (m *MemoryRepo) Commits(branch string) []MemoryCommit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.branches[branch])
+}
+
+// Root returns the repository root.
+func (m *MemoryRepo) Root() string { return m.root }
+
+func (m *MemoryRepo) headHash() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	commits := m.branches[m.current]
+	if len(commits) == 0 {
+		return "", errors.New("no commits")
+	}
+	return commits[len(commits)-1].Hash, nil
+}
+
+// HasCommits returns true if the current branch has at least one commit.
+func (m *MemoryRepo) HasCommits() (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.branches[m.current]) > 0, nil
+}
+
+// CurrentBranch returns the checked out branch.
+func (m *MemoryRepo) CurrentBranch() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current, nil
+}
+
+// GetDefaultBranch returns the branch the repository was created with.
+func (m *MemoryRepo) GetDefaultBranch() string { return m.defaultBranch }
+
+// BranchExists checks whether the branch exists.
+func (m *MemoryRepo) BranchExists(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.branches[name]
+	return ok
+}
+
+// CreateBranch creates a branch from the current one and switches to it.
+func (m *MemoryRepo) CreateBranch(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.branches[name]; ok {
+		return fmt.Errorf("branch %q already exists", name)
+	}
+	m.branches[name] = slices.Clone(m.branches[m.current])
+	m.current = name
+	return nil
+}
+
+// CheckoutBranch switches to an existing branch. uncommitted changes are carried over, as in git.
+func (m *MemoryRepo) CheckoutBranch(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.branches[name]; !ok {
+		return fmt.Errorf("branch %q not found", name)
+	}
+	m.current = name
+	return nil
+}
+
+// IsDirty returns true if there are uncommitted changes to non-ignored paths.
+func (m *MemoryRepo) IsDirty() (bool, error) {
+	return m.HasChangesOtherThan("")
+}
+
+// FileHasChanges returns true if path has uncommitted changes.
+func (m *MemoryRepo) FileHasChanges(path string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.changes[m.rel(path)]
+	return ok, nil
+}
+
+// HasChangesOtherThan returns true if any non-ignored path other than path has uncommitted changes.
+func (m *MemoryRepo) HasChangesOtherThan(path string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	skip := ""
+	if path != "" {
+		skip = m.rel(path)
+	}
+	for p := range m.changes {
+		if p != skip && !m.ignored[p] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// IsIgnored returns true if path was marked with SetIgnored.
+func (m *MemoryRepo) IsIgnored(path string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ignored[m.rel(path)], nil
+}
+
+// Add stages path.
+func (m *MemoryRepo) Add(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.changes[m.rel(path)] = true
+	return nil
+}
+
+// MoveFile records a rename as staged changes of both paths.
+func (m *MemoryRepo) MoveFile(src, dst string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.changes[m.rel(src)] = true
+	m.changes[m.rel(dst)] = true
+	return nil
+}
+
+// Commit records a commit of all staged paths on the current branch.
+func (m *MemoryRepo) Commit(msg string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var files []string
+	for p, staged := range m.changes {
+		if staged {
+			files = append(files, p)
+		}
+	}
+	if len(files) == 0 {
+		return errors.New("nothing to commit")
+	}
+	for _, p := range files {
+		delete(m.changes, p)
+	}
+	m.commit(msg, files)
+	return nil
+}
+
+// CreateInitialCommit stages all non-ignored changes and commits them.
+func (m *MemoryRepo) CreateInitialCommit(msg string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var files []string
+	for p := range m.changes {
+		if !m.ignored[p] {
+			files = append(files, p)
+		}
+	}
+	if len(files) == 0 {
+		return errors.New("no files to commit")
+	}
+	for _, p := range files {
+		delete(m.changes, p)
+	}
+	m.commit(msg, files)
+	return nil
+}
+
+func (m *MemoryRepo) diffStats(baseBranch string) (DiffStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats[baseBranch], nil
+}
+
+func (m *MemoryRepo) specialChanges(baseBranch string) (SpecialChanges, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.special[baseBranch], nil
+}
+
+func (m *MemoryRepo) prepareDiff(_ string, fetch bool) (DiffReadiness, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := m.readiness
+	if !fetch {
+		res.Fetched = 0
+	}
+	return res, nil
+}
+
+func (m *MemoryRepo) changedFiles(baseBranch string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.changed[baseBranch]), nil
+}
+
+// formatPatches writes a patch file per commit of the current branch that is not on baseBranch.
+func (m *MemoryRepo) formatPatches(baseBranch, dir string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	base, ok := m.branches[baseBranch]
+	if !ok {
+		return nil, nil
+	}
+	var res []string
+	for _, c := range m.branches[m.current] {
+		if slices.ContainsFunc(base, func(b MemoryCommit) bool { return b.Hash == c.Hash }) {
+			continue
+		}
+		subject, _, _ := strings.Cut(c.Message, "\n")
+		name := filepath.Join(dir, fmt.Sprintf("%04d-%s.patch", len(res)+1, patchSlug(subject)))
+		if err := os.WriteFile(name, []byte(memoryPatch(c)), 0o600); err != nil {
+			return nil, fmt.Errorf("write patch: %w", err)
+		}
+		res = append(res, name)
+	}
+	return res, nil
+}
+
+func (m *MemoryRepo) diffSince(rev string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.diffs[rev], nil
+}
+
+// isClean returns true if there are no uncommitted paths.
+func (m *MemoryRepo) isClean() (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.changes) == 0, nil
+}
+
+// extractChanges commits uncommitted paths, returns patches of commits after rev and resets the branch to rev.
+func (m *MemoryRepo) extractChanges(rev string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	idx := slices.IndexFunc(m.branches[m.current], func(c MemoryCommit) bool { return c.Hash == rev })
+	if idx < 0 {
+		return nil, fmt.Errorf("unknown revision %q", rev)
+	}
+	if len(m.changes) > 0 {
+		m.commit("uncommitted changes", slices.Collect(maps.Keys(m.changes)))
+		clear(m.changes)
+	}
+	var b strings.Builder
+	for _, c := range m.branches[m.current][idx+1:] {
+		b.WriteString(memoryPatch(c))
+	}
+	m.branches[m.current] = m.branches[m.current][:idx+1]
+	return []byte(b.String()), nil
+}
+
+// snapshot records the head commit and, with uncommitted paths, a fake state holding them.
+func (m *MemoryRepo) snapshot() (Snapshot, error) {
+	head, err := m.headHash()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.changes) == 0 {
+		return Snapshot{Head: head, State: head}, nil
+	}
+	state := fmt.Sprintf("snapshot-%s-%d", head, len(m.snapshots))
+	m.snapshots[state] = maps.Clone(m.changes)
+	return Snapshot{Head: head, State: state}, nil
+}
+
+// restoreSnapshot drops commits made after the snapshot's head and restores its uncommitted paths, unstaged.
+func (m *MemoryRepo) restoreSnapshot(snap Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	idx := slices.IndexFunc(m.branches[m.current], func(c MemoryCommit) bool { return c.Hash == snap.Head })
+	if idx < 0 {
+		return fmt.Errorf("unknown revision %q", snap.Head)
+	}
+	m.branches[m.current] = m.branches[m.current][:idx+1]
+	clear(m.changes)
+	for p := range m.snapshots[snap.State] {
+		m.changes[p] = false
+	}
+	return nil
+}
+
+// applyPatch commits a patch made by memoryPatch with its subject and file list.
+func (m *MemoryRepo) applyPatch(text []byte) error {
+	patches := SplitPatches(text)
+	if len(patches) != 1 {
+		return fmt.Errorf("expected a single patch, got %d", len(patches))
+	}
+	_, list, _ := strings.Cut(patches[0].Text, "\n---\n")
+	var files []string
+	for line := range strings.SplitSeq(list, "\n") {
+		if f := strings.TrimSpace(line); f != "" {
+			files = append(files, f)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commit(patches[0].Subject, files)
+	return nil
+}
+
+// memoryPatch formats a commit like git format-patch. in-memory commits have no content,
+// so the patch holds the header and the list of changed files only.
+func memoryPatch(c MemoryCommit) string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return fmt.Sprintf("From %s Mon Sep 17 00:00:00 2001\nSubject: [PATCH] %s\n\n---\n %s\n",
+		c.Hash, subject, strings.Join(c.Files, "\n "))
+}
+
+// patchSlug turns a commit subject into a file name part like git format-patch does,
+// collapsing runs of other characters into a single dash.
+func patchSlug(subject string) string {
+	var b strings.Builder
+	for _, r := range subject {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '_' {
+			b.WriteRune(r)
+			continue
+		}
+		if s := b.String(); s != "" && !strings.HasSuffix(s, "-") {
+			b.WriteByte('-')
+		}
+	}
+	slug := strings.Trim(b.String(), "-.")
+	if len(slug) > 52 {
+		slug = strings.TrimRight(slug[:52], "-.")
+	}
+	return slug
+}
+
+// commit appends a commit to the current branch. caller must hold the lock.
+func (m *MemoryRepo) commit(msg string, files []string) {
+	slices.Sort(files)
+	parent := ""
+	if commits := m.branches[m.current]; len(commits) > 0 {
+		parent = commits[len(commits)-1].Hash
+	}
+	sum := sha1.Sum([]byte(parent + "\x00" + msg + "\x00" + strings.Join(files, "\x00"))) //nolint:gosec // fake commit id
+	m.branches[m.current] = append(m.branches[m.current], MemoryCommit{Hash: hex.EncodeToString(sum[:]), Message: msg, Files: files})
+}
+
+// rel converts path to a slash-separated path relative to root. paths outside root are kept as is.
+func (m *MemoryRepo) rel(path string) string {
+	if filepath.IsAbs(path) {
+		if r, err := filepath.Rel(m.root, path); err == nil && !strings.HasPrefix(r, "..") {
+			path = r
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// compile-time check: MemoryRepo must satisfy the backend interface
+var _ backend = (*MemoryRepo)(nil)
@@ -0,0 +1,226 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMemoryRepo(t *testing.T) *MemoryRepo {
+	t.Helper()
+	repo := NewMemoryRepo("/repo", "master")
+	repo.Touch("README.md")
+	require.NoError(t, repo.CreateInitialCommit("initial commit"))
+	return repo
+}
+
+func TestMemoryRepo_Commit(t *testing.T) {
+	repo := newTestMemoryRepo(t)
+
+	repo.Touch("/repo/a.go", "b.go")
+	dirty, err := repo.IsDirty()
+	require.NoError(t, err)
+	assert.True(t, dirty)
+
+	assert.EqualError(t, repo.Commit("nothing staged"), "nothing to commit")
+
+	require.NoError(t, repo.Add("a.go"))
+	require.NoError(t, repo.Commit("add a"))
+
+	commits := repo.Commits("master")
+	require.Len(t, commits, 2)
+	assert.Equal(t, "add a", commits[1].Message)
+	assert.Equal(t, []string{"a.go"}, commits[1].Files)
+	assert.NotEqual(t, commits[0].Hash, commits[1].Hash)
+
+	head, err := repo.headHash()
+	require.NoError(t, err)
+	assert.Equal(t, commits[1].Hash, head)
+
+	hasB, err := repo.FileHasChanges("b.go")
+	require.NoError(t, err)
+	assert.True(t, hasB, "unstaged file stays dirty after commit")
+}
+
+func TestMemoryRepo_Branches(t *testing.T) {
+	repo := newTestMemoryRepo(t)
+
+	require.NoError(t, repo.CreateBranch("feature"))
+	require.Error(t, repo.CreateBranch("feature"))
+	repo.Touch("x.go")
+	require.NoError(t, repo.Add("x.go"))
+	require.NoError(t, repo.Commit("on feature"))
+
+	assert.Len(t, repo.Commits("feature"), 2)
+	assert.Len(t, repo.Commits("master"), 1, "commits on feature don't affect master")
+
+	require.NoError(t, repo.CheckoutBranch("master"))
+	branch, err := repo.CurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "master", branch)
+	require.Error(t, repo.CheckoutBranch("missing"))
+}
+
+func TestMemoryRepo_IgnoredPathsAreNotDirty(t *testing.T) {
+	repo := newTestMemoryRepo(t)
+	repo.SetIgnored("progress.txt")
+	repo.Touch("progress.txt")
+
+	dirty, err := repo.IsDirty()
+	require.NoError(t, err)
+	assert.False(t, dirty)
+
+	ignored, err := repo.IsIgnored("/repo/progress.txt")
+	require.NoError(t, err)
+	assert.True(t, ignored)
+}
+
+func TestMemoryService_CreateBranchForPlan(t *testing.T) {
+	planFile := filepath.Join("docs", "plans", "add-feature.md")
+
+	t.Run("commits plan on new branch", func(t *testing.T) {
+		repo := newTestMemoryRepo(t)
+		repo.Touch(planFile)
+		log := &mockLogger{}
+		svc := NewMemoryService(repo, log)
+
+		require.NoError(t, svc.CreateBranchForPlan(planFile))
+
+		branch, err := svc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "add-feature", branch)
+		commits := repo.Commits("add-feature")
+		require.Len(t, commits, 2)
+		assert.Equal(t, "add plan: add-feature", commits[1].Message)
+		assert.Equal(t, []string{"docs/plans/add-feature.md"}, commits[1].Files)
+		assert.Len(t, repo.Commits("master"), 1)
+		assert.Contains(t, log.logs, "creating branch: add-feature\n")
+	})
+
+	t.Run("refuses with other uncommitted changes", func(t *testing.T) {
+		repo := newTestMemoryRepo(t)
+		repo.Touch(planFile, "main.go")
+		svc := NewMemoryService(repo, noopServiceLogger())
+
+		err := svc.CreateBranchForPlan(planFile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "worktree has uncommitted changes")
+		assert.False(t, repo.BranchExists("add-feature"))
+	})
+
+	t.Run("switches to existing branch", func(t *testing.T) {
+		repo := newTestMemoryRepo(t)
+		require.NoError(t, repo.CreateBranch("add-feature"))
+		require.NoError(t, repo.CheckoutBranch("master"))
+		svc := NewMemoryService(repo, noopServiceLogger())
+
+		require.NoError(t, svc.CreateBranchForPlan(planFile))
+		branch, err := svc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "add-feature", branch)
+	})
+
+	t.Run("stays on feature branch", func(t *testing.T) {
+		repo := newTestMemoryRepo(t)
+		require.NoError(t, repo.CreateBranch("work"))
+		svc := NewMemoryService(repo, noopServiceLogger())
+
+		require.NoError(t, svc.CreateBranchForPlan(planFile))
+		assert.False(t, repo.BranchExists("add-feature"))
+	})
+}
+
+func TestMemoryService_EnsureHasCommits(t *testing.T) {
+	repo := NewMemoryRepo("/repo", "main")
+	repo.Touch("go.mod")
+	svc := NewMemoryService(repo, noopServiceLogger())
+
+	require.NoError(t, svc.EnsureHasCommits(func() bool { return true }))
+	commits := repo.Commits("main")
+	require.Len(t, commits, 1)
+	assert.Equal(t, "initial commit", commits[0].Message)
+}
+
+func TestMemoryService_DiffStats(t *testing.T) {
+	repo := newTestMemoryRepo(t)
+	repo.SetDiffStats("master", DiffStats{Files: 2, Additions: 10, Deletions: 3})
+	svc := NewMemoryService(repo, noopServiceLogger())
+
+	stats, err := svc.DiffStats("master")
+	require.NoError(t, err)
+	assert.Equal(t, DiffStats{Files: 2, Additions: 10, Deletions: 3}, stats)
+
+	stats, err = svc.DiffStats("other")
+	require.NoError(t, err)
+	assert.Equal(t, DiffStats{}, stats)
+}
+
+func TestMemoryService_SpecialChanges(t *testing.T) {
+	repo := newTestMemoryRepo(t)
+	want := SpecialChanges{LFSFiles: []string{"assets/logo.png"}}
+	repo.SetSpecialChanges("master", want)
+	svc := NewMemoryService(repo, noopServiceLogger())
+
+	sc, err := svc.SpecialChanges("master")
+	require.NoError(t, err)
+	assert.Equal(t, want, sc)
+
+	sc, err = svc.SpecialChanges("")
+	require.NoError(t, err)
+	assert.True(t, sc.Empty())
+}
+
+func TestMemoryService_PrepareDiff(t *testing.T) {
+	repo := newTestMemoryRepo(t)
+	repo.SetDiffReadiness(DiffReadiness{PartialClone: true, Missing: 2, Fetched: 2})
+	svc := NewMemoryService(repo, noopServiceLogger())
+
+	res, err := svc.PrepareDiff("master", true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Fetched)
+
+	res, err = svc.PrepareDiff("master", false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Missing)
+	assert.Zero(t, res.Fetched, "nothing is fetched when fetching is disabled")
+}
+
+func TestMemoryService_ChangedFiles(t *testing.T) {
+	repo := newTestMemoryRepo(t)
+	repo.SetChangedFiles("master", []string{"a.go", "b/c.go"})
+	svc := NewMemoryService(repo, noopServiceLogger())
+
+	files, err := svc.ChangedFiles("master")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go", "b/c.go"}, files)
+
+	files, err = svc.ChangedFiles("other")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestMemoryService_FormatPatches(t *testing.T) {
+	repo := newTestMemoryRepo(t)
+	require.NoError(t, repo.CreateBranch("feature"))
+	repo.Touch("a.go")
+	require.NoError(t, repo.Add("a.go"))
+	require.NoError(t, repo.Commit("add a: first part"))
+	svc := NewMemoryService(repo, noopServiceLogger())
+
+	dir := t.TempDir()
+	files, err := svc.FormatPatches("master", dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, filepath.Join(dir, "0001-add-a-first-part.patch"), files[0])
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Subject: [PATCH] add a: first part")
+	assert.Contains(t, string(data), "a.go")
+
+	files, err = svc.FormatPatches("nonexistent", t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Patch is a single commit of a patch series in "git format-patch" format.
+type Patch struct {
+	Subject string // commit subject without the "[PATCH n/m]" prefix
+	Text    string // full patch text, including the mail header
+}
+
+// patchStartRe matches the mbox separator line format-patch starts each patch with
+var patchStartRe = regexp.MustCompile(`(?m)^From [0-9a-f]{40} Mon Sep 17 00:00:00 2001$`)
+
+// subjectPrefixRe matches the "[PATCH]" or "[PATCH n/m]" subject prefix
+var subjectPrefixRe = regexp.MustCompile(`^\[PATCH[^\]]*\]\s*`)
+
+// SplitPatches splits a patch series, as written by ExtractChanges or "git format-patch --stdout",
+// into single patches. text before the first patch is ignored.
+func SplitPatches(series []byte) []Patch {
+	text := string(series)
+	starts := patchStartRe.FindAllStringIndex(text, -1)
+	res := make([]Patch, 0, len(starts))
+	for i, loc := range starts {
+		end := len(text)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		p := Patch{Text: text[loc[0]:end]}
+		for line := range strings.SplitSeq(p.Text, "\n") {
+			if line == "" {
+				break // end of the mail header
+			}
+			if subject, ok := strings.CutPrefix(line, "Subject: "); ok {
+				p.Subject = subjectPrefixRe.ReplaceAllString(subject, "")
+				break
+			}
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+// Summary returns the patch without its diff: the header, commit message and diffstat.
+func (p Patch) Summary() string {
+	if idx := strings.Index(p.Text, "\ndiff --git "); idx >= 0 {
+		return p.Text[:idx+1]
+	}
+	return p.Text
+}
@@ -0,0 +1,40 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitPatches(t *testing.T) {
+	series := `From 1111111111111111111111111111111111111111 Mon Sep 17 00:00:00 2001
+From: Dev <dev@example.com>
+Subject: [PATCH 1/2] fix nil check
+
+---
+ a.go | 2 +-
+ 1 file changed, 1 insertion(+), 1 deletion(-)
+
+diff --git a/a.go b/a.go
+-old
++new
+From 2222222222222222222222222222222222222222 Mon Sep 17 00:00:00 2001
+From: Dev <dev@example.com>
+Subject: [PATCH 2/2] uncommitted changes
+
+---
+ b.go | 1 +
+`
+	patches := SplitPatches([]byte("preamble\n" + series))
+	require.Len(t, patches, 2)
+	assert.Equal(t, "fix nil check", patches[0].Subject)
+	assert.Equal(t, "uncommitted changes", patches[1].Subject)
+	assert.Equal(t, len(series), len(patches[0].Text)+len(patches[1].Text), "patches cover the series")
+
+	assert.Contains(t, patches[0].Summary(), "1 file changed")
+	assert.NotContains(t, patches[0].Summary(), "diff --git")
+	assert.Equal(t, patches[1].Text, patches[1].Summary(), "no diff, summary is the whole patch")
+
+	assert.Empty(t, SplitPatches([]byte("not a patch")))
+}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/umputun/ralphex/pkg/debuglog"
 	"github.com/umputun/ralphex/pkg/plan"
 )
 
@@ -37,6 +40,16 @@ type backend interface {
 	Commit(msg string) error
 	CreateInitialCommit(msg string) error
 	diffStats(baseBranch string) (DiffStats, error)
+	specialChanges(baseBranch string) (SpecialChanges, error)
+	prepareDiff(baseBranch string, fetch bool) (DiffReadiness, error)
+	changedFiles(baseBranch string) ([]string, error)
+	formatPatches(baseBranch, dir string) ([]string, error)
+	isClean() (bool, error)
+	extractChanges(rev string) ([]byte, error)
+	applyPatch(text []byte) error
+	diffSince(rev string) (string, error)
+	snapshot() (Snapshot, error)
+	restoreSnapshot(snap Snapshot) error
 }
 
 // DiffStats holds statistics about changes between two commits.
@@ -46,6 +59,41 @@ type DiffStats struct {
 	Deletions int // lines deleted
 }
 
+// SubmoduleChange describes a submodule whose recorded commit changed.
+type SubmoduleChange struct {
+	Path string
+	From string // previous commit, empty if the submodule was added
+	To   string // new commit, empty if the submodule was removed
+}
+
+// SpecialChanges lists changed paths whose textual diff is not useful for review:
+// submodule pointer moves and files stored in Git LFS (the diff only shows pointer files).
+type SpecialChanges struct {
+	Submodules []SubmoduleChange
+	LFSFiles   []string // paths relative to repository root
+}
+
+// Empty returns true if there are no special changes.
+func (c SpecialChanges) Empty() bool {
+	return len(c.Submodules) == 0 && len(c.LFSFiles) == 0
+}
+
+// DiffReadiness reports whether objects and files needed to review a diff are available locally.
+// only partial clones can miss objects and only sparse checkouts can miss working tree files.
+type DiffReadiness struct {
+	PartialClone   bool
+	SparseCheckout bool
+	Missing        int      // diff blobs missing locally before fetching
+	Fetched        int      // missing blobs fetched from the promisor remote
+	OutsideSparse  []string // changed paths not present in the sparse working tree
+}
+
+// Snapshot is the state of the worktree saved by Service.Snapshot.
+type Snapshot struct {
+	Head  string // commit checked out
+	State string // commit holding the worktree content, tracked and untracked files; Head if the worktree was clean
+}
+
 // Service provides git operations for ralphex workflows.
 // It is the single public API for the git package.
 type Service struct {
@@ -64,6 +112,24 @@ func NewService(path string, log Logger) (*Service, error) {
 	return &Service{repo: b, log: log}, nil
 }
 
+// NewServiceWithCommand is like NewService but runs the given git binary instead of "git" from PATH.
+// an empty gitCommand falls back to "git".
+func NewServiceWithCommand(path, gitCommand string, log Logger) (*Service, error) {
+	b, err := newExternalBackendWithCommand(path, gitCommand)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{repo: b, log: log}, nil
+}
+
+// SetDebug sets debug flags, with git enabled the commands run are printed.
+// the in-memory backend runs no commands and ignores it.
+func (s *Service) SetDebug(f debuglog.Flags) {
+	if b, ok := s.repo.(*externalBackend); ok {
+		b.debug = f
+	}
+}
+
 // Root returns the absolute path to the repository root.
 func (s *Service) Root() string {
 	return s.repo.Root()
@@ -115,6 +181,14 @@ func (s *Service) CreateBranch(name string) error {
 	return nil
 }
 
+// Checkout switches to an existing branch.
+func (s *Service) Checkout(name string) error {
+	if err := s.repo.CheckoutBranch(name); err != nil {
+		return fmt.Errorf("checkout branch %s: %w", name, err)
+	}
+	return nil
+}
+
 // CreateBranchForPlan creates or switches to a feature branch for plan execution.
 // If already on a feature branch (not main/master), returns nil immediately.
 // If on main/master, extracts branch name from plan file and creates/switches to it.
@@ -203,9 +277,37 @@ func (s *Service) MovePlanToCompleted(planFile string) error {
 		}
 	}
 
-	// use git mv
+	if err := s.movePlan(planFile, destPath, "move completed plan: "+filepath.Base(planFile)); err != nil {
+		return err
+	}
+	s.log.Printf("moved plan to %s\n", destPath)
+	return nil
+}
+
+// ArchivePlan moves a completed plan file into archiveDir, prefixed with the completion timestamp, and commits.
+// relative archiveDir is resolved from the repository root. the directory is created if it doesn't exist.
+func (s *Service) ArchivePlan(planFile, archiveDir string, ts time.Time) error {
+	if archiveDir == "" {
+		return errors.New("archive dir is not set")
+	}
+	if !filepath.IsAbs(archiveDir) {
+		archiveDir = filepath.Join(s.repo.Root(), archiveDir)
+	}
+	if err := os.MkdirAll(archiveDir, 0o750); err != nil {
+		return fmt.Errorf("create archive dir: %w", err)
+	}
+	destPath := filepath.Join(archiveDir, ts.Format("20060102-150405")+"-"+filepath.Base(planFile))
+	if err := s.movePlan(planFile, destPath, "archive completed plan: "+filepath.Base(planFile)); err != nil {
+		return err
+	}
+	s.log.Printf("archived plan to %s\n", destPath)
+	return nil
+}
+
+// movePlan moves the plan file to destPath with git mv and commits the move.
+// falls back to a regular move for untracked files.
+func (s *Service) movePlan(planFile, destPath, commitMsg string) error {
 	if err := s.repo.MoveFile(planFile, destPath); err != nil {
-		// fallback to regular move for untracked files
 		if renameErr := os.Rename(planFile, destPath); renameErr != nil {
 			return fmt.Errorf("move plan: %w", renameErr)
 		}
@@ -214,14 +316,45 @@ func (s *Service) MovePlanToCompleted(planFile string) error {
 			s.log.Printf("warning: failed to stage moved plan: %v\n", addErr)
 		}
 	}
-
-	// commit the move
-	commitMsg := "move completed plan: " + filepath.Base(planFile)
 	if err := s.repo.Commit(commitMsg); err != nil {
 		return fmt.Errorf("commit plan move: %w", err)
 	}
+	return nil
+}
 
-	s.log.Printf("moved plan to %s\n", destPath)
+// CommitPlanUpdate stages the plan file and commits it with the given message.
+// does nothing if the plan file has no changes.
+func (s *Service) CommitPlanUpdate(planFile, msg string) error {
+	changed, err := s.repo.FileHasChanges(planFile)
+	if err != nil {
+		return fmt.Errorf("check plan file status: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+	if err := s.repo.Add(planFile); err != nil {
+		return fmt.Errorf("stage plan file: %w", err)
+	}
+	if err := s.repo.Commit(msg); err != nil {
+		return fmt.Errorf("commit plan file: %w", err)
+	}
+	return nil
+}
+
+// CommitFiles stages files, paths relative to the repository root, and commits them with the given message.
+// does nothing if files is empty.
+func (s *Service) CommitFiles(files []string, msg string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	for _, f := range files {
+		if err := s.repo.Add(f); err != nil {
+			return fmt.Errorf("stage %s: %w", f, err)
+		}
+	}
+	if err := s.repo.Commit(msg); err != nil {
+		return fmt.Errorf("commit files: %w", err)
+	}
 	return nil
 }
 
@@ -256,6 +389,118 @@ func (s *Service) DiffStats(baseBranch string) (DiffStats, error) {
 	return s.repo.diffStats(baseBranch)
 }
 
+// SpecialChanges returns submodule and LFS changes between baseBranch and HEAD.
+// an empty baseBranch inspects uncommitted changes instead, matching plain "git diff".
+// returns zero value if baseBranch doesn't exist.
+func (s *Service) SpecialChanges(baseBranch string) (SpecialChanges, error) {
+	res, err := s.repo.specialChanges(baseBranch)
+	if err != nil {
+		return SpecialChanges{}, fmt.Errorf("special changes: %w", err)
+	}
+	return res, nil
+}
+
+// PrepareDiff checks that blobs of the baseBranch...HEAD diff are present in a partial clone,
+// fetching missing ones from the promisor remote in one batch if fetch is true,
+// and finds changed files that are outside a sparse checkout.
+// does nothing for regular clones or if baseBranch doesn't exist.
+func (s *Service) PrepareDiff(baseBranch string, fetch bool) (DiffReadiness, error) {
+	res, err := s.repo.prepareDiff(baseBranch, fetch)
+	if err != nil {
+		return res, fmt.Errorf("prepare diff: %w", err)
+	}
+	return res, nil
+}
+
+// ChangedFiles returns paths changed since the merge base with baseBranch, including
+// uncommitted and untracked files. deleted and renamed-away paths are included too.
+// only uncommitted changes are returned if baseBranch doesn't exist.
+func (s *Service) ChangedFiles(baseBranch string) ([]string, error) {
+	res, err := s.repo.changedFiles(baseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("changed files: %w", err)
+	}
+	return res, nil
+}
+
+// FormatPatches writes commits made since baseBranch as numbered patch files into dir,
+// one per commit in "git format-patch" format. returns paths of written files, none if
+// baseBranch doesn't exist or there are no commits.
+func (s *Service) FormatPatches(baseBranch, dir string) ([]string, error) {
+	res, err := s.repo.formatPatches(baseBranch, dir)
+	if err != nil {
+		return nil, fmt.Errorf("format patches: %w", err)
+	}
+	return res, nil
+}
+
+// DiffSince returns the diffstat and diff of tracked files between rev and the worktree,
+// covering both commits made since rev and uncommitted changes.
+func (s *Service) DiffSince(rev string) (string, error) {
+	res, err := s.repo.diffSince(rev)
+	if err != nil {
+		return "", fmt.Errorf("diff since %s: %w", rev, err)
+	}
+	return res, nil
+}
+
+// StartCapture returns the HEAD commit to extract changes from later with ExtractChanges.
+// fails if the worktree has uncommitted changes or untracked files, as extracting resets
+// the worktree and would lose them.
+func (s *Service) StartCapture() (string, error) {
+	clean, err := s.repo.isClean()
+	if err != nil {
+		return "", fmt.Errorf("start capture: %w", err)
+	}
+	if !clean {
+		return "", errors.New("start capture: worktree has uncommitted changes or untracked files, commit or stash them first")
+	}
+	head, err := s.repo.headHash()
+	if err != nil {
+		return "", fmt.Errorf("start capture: %w", err)
+	}
+	return head, nil
+}
+
+// ExtractChanges returns changes made since rev, commits and uncommitted changes, as a patch series
+// in "git format-patch --stdout" format to apply with "git am", and resets the worktree to rev.
+// ignored files are kept. returns an empty series if nothing changed.
+func (s *Service) ExtractChanges(rev string) ([]byte, error) {
+	res, err := s.repo.extractChanges(rev)
+	if err != nil {
+		return nil, fmt.Errorf("extract changes: %w", err)
+	}
+	return res, nil
+}
+
+// ApplyPatch commits a single patch with "git am --3way". a patch that doesn't apply is aborted,
+// leaving the worktree unchanged.
+func (s *Service) ApplyPatch(p Patch) error {
+	if err := s.repo.applyPatch([]byte(p.Text)); err != nil {
+		return fmt.Errorf("apply patch %q: %w", p.Subject, err)
+	}
+	return nil
+}
+
+// Snapshot saves the state of the worktree, commits and uncommitted changes including untracked files,
+// to return to with RestoreSnapshot. the index, the worktree and the branch are left unchanged.
+func (s *Service) Snapshot() (Snapshot, error) {
+	res, err := s.repo.snapshot()
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("snapshot worktree: %w", err)
+	}
+	return res, nil
+}
+
+// RestoreSnapshot resets the branch and the worktree to a snapshot: commits made since are dropped,
+// untracked files removed and uncommitted changes of the snapshot restored, unstaged. ignored files are kept.
+func (s *Service) RestoreSnapshot(snap Snapshot) error {
+	if err := s.repo.restoreSnapshot(snap); err != nil {
+		return fmt.Errorf("restore snapshot %s: %w", snap.Head, err)
+	}
+	return nil
+}
+
 // EnsureIgnored ensures a pattern is in .gitignore.
 // uses probePath to check if pattern is already ignored before adding.
 // if pattern is already ignored, does nothing.
@@ -289,3 +534,61 @@ func (s *Service) EnsureIgnored(pattern, probePath string) error {
 	s.log.Printf("added %s to .gitignore\n", pattern)
 	return nil
 }
+
+// EnsureNestedIgnored keeps probe paths out of git with a .gitignore in dir, relative to the repository root.
+// the file is created with content, or content is appended to an existing file lacking it.
+// returns an error if a probe is still not ignored afterwards, e.g. because of a negating rule elsewhere.
+// probes git fails to check are not reported, the rules are written anyway.
+func (s *Service) EnsureNestedIgnored(dir, content string, probes ...string) error {
+	if s.allIgnored(probes) {
+		return nil
+	}
+
+	rel := filepath.Join(dir, ".gitignore")
+	path := filepath.Join(s.repo.Root(), rel)
+	existing, err := os.ReadFile(path) //nolint:gosec // .gitignore inside the repository
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read %s: %w", rel, err)
+	}
+	if !strings.Contains(string(existing), content) {
+		data := content
+		if len(existing) > 0 {
+			data = strings.TrimRight(string(existing), "\n") + "\n\n" + content
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			return fmt.Errorf("create %s: %w", dir, err)
+		}
+		if err = os.WriteFile(path, []byte(data), 0o644); err != nil { //nolint:gosec // .gitignore needs world-readable
+			return fmt.Errorf("write %s: %w", rel, err)
+		}
+		s.log.Printf("added ralphex ignore rules to %s\n", rel)
+	}
+
+	if missing := s.notIgnored(probes); len(missing) > 0 {
+		return fmt.Errorf("%s still not ignored with %s in place, check for negating rules in other .gitignore files",
+			strings.Join(missing, ", "), rel)
+	}
+	return nil
+}
+
+// allIgnored reports whether git confirms all paths are ignored
+func (s *Service) allIgnored(paths []string) bool {
+	for _, p := range paths {
+		if ignored, err := s.repo.IsIgnored(p); err != nil || !ignored {
+			return false
+		}
+	}
+	return true
+}
+
+// notIgnored returns paths not ignored by git, paths git fails to check are skipped
+func (s *Service) notIgnored(paths []string) []string {
+	var res []string
+	for _, p := range paths {
+		ignored, err := s.repo.IsIgnored(p)
+		if err == nil && !ignored {
+			res = append(res, p)
+		}
+	}
+	return res
+}
@@ -1,11 +1,14 @@
 package git
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -46,6 +49,33 @@ func TestNewService(t *testing.T) {
 	})
 }
 
+func TestNewServiceWithCommand(t *testing.T) {
+	t.Run("uses configured git binary", func(t *testing.T) {
+		gitPath, err := exec.LookPath("git")
+		require.NoError(t, err)
+		dir := setupExternalTestRepo(t)
+
+		svc, err := NewServiceWithCommand(dir, gitPath, noopServiceLogger())
+		require.NoError(t, err)
+		branch, err := svc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "master", branch)
+	})
+
+	t.Run("empty command falls back to git", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewServiceWithCommand(dir, "", noopServiceLogger())
+		require.NoError(t, err)
+		assert.NotEmpty(t, svc.Root())
+	})
+
+	t.Run("fails with missing binary", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		_, err := NewServiceWithCommand(dir, filepath.Join(t.TempDir(), "no-such-git"), noopServiceLogger())
+		assert.Error(t, err)
+	})
+}
+
 func TestService_IsMainBranch(t *testing.T) {
 	t.Run("returns true for master branch", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -104,6 +134,22 @@ func TestService_IsMainBranch(t *testing.T) {
 	})
 }
 
+func TestService_Checkout(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+	start, err := svc.CurrentBranch()
+	require.NoError(t, err)
+
+	require.NoError(t, svc.CreateBranch("feature-test"))
+	require.NoError(t, svc.Checkout(start))
+	branch, err := svc.CurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, start, branch)
+
+	require.ErrorContains(t, svc.Checkout("missing"), "checkout branch missing")
+}
+
 func TestService_CreateBranchForPlan(t *testing.T) {
 	t.Run("returns nil on feature branch", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -388,6 +434,88 @@ func TestService_MovePlanToCompleted(t *testing.T) {
 	})
 }
 
+func TestService_ArchivePlan(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("archives tracked plan under repo root", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		planFile := filepath.Join(dir, "docs", "plans", "feature.md")
+		require.NoError(t, os.MkdirAll(filepath.Dir(planFile), 0o750))
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+		require.NoError(t, svc.repo.Add(planFile))
+		require.NoError(t, svc.repo.Commit("add plan"))
+
+		require.NoError(t, svc.ArchivePlan(planFile, ".ralphex/done", ts))
+		assert.NoFileExists(t, planFile)
+		assert.FileExists(t, filepath.Join(dir, ".ralphex", "done", "20260102-030405-feature.md"))
+		assert.Equal(t, "archive completed plan: feature.md", strings.TrimSpace(runGit(t, dir, "log", "-1", "--format=%s")))
+		assert.Empty(t, strings.TrimSpace(runGit(t, dir, "status", "--porcelain")))
+	})
+
+	t.Run("archives untracked plan to absolute dir", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		planFile := filepath.Join(dir, "feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+		archiveDir := filepath.Join(dir, "archive")
+
+		require.NoError(t, svc.ArchivePlan(planFile, archiveDir, ts))
+		assert.FileExists(t, filepath.Join(archiveDir, "20260102-030405-feature.md"))
+	})
+
+	t.Run("archive dir not set", func(t *testing.T) {
+		svc, err := NewService(setupExternalTestRepo(t), noopServiceLogger())
+		require.NoError(t, err)
+		require.EqualError(t, svc.ArchivePlan("plan.md", "", ts), "archive dir is not set")
+	})
+}
+
+func TestService_CommitPlanUpdate(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	planFile := filepath.Join(dir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n"), 0o600))
+	require.NoError(t, svc.repo.Add(planFile))
+	require.NoError(t, svc.repo.Commit("add plan"))
+
+	t.Run("no changes", func(t *testing.T) {
+		head := runGit(t, dir, "rev-parse", "HEAD")
+		require.NoError(t, svc.CommitPlanUpdate(planFile, "record run outcome: plan.md"))
+		assert.Equal(t, head, runGit(t, dir, "rev-parse", "HEAD"))
+	})
+
+	t.Run("commits changed plan", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n\n## Run History\n"), 0o600))
+		require.NoError(t, svc.CommitPlanUpdate(planFile, "record run outcome: plan.md"))
+		assert.Equal(t, "record run outcome: plan.md", strings.TrimSpace(runGit(t, dir, "log", "-1", "--format=%s")))
+		assert.Empty(t, strings.TrimSpace(runGit(t, dir, "status", "--porcelain")))
+	})
+}
+
+func TestService_CommitFiles(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	head := runGit(t, dir, "rev-parse", "HEAD")
+	require.NoError(t, svc.CommitFiles(nil, "style: format"))
+	assert.Equal(t, head, runGit(t, dir, "rev-parse", "HEAD"), "nothing to commit")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "a.go"), []byte("package pkg\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.go"), []byte("package main\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("not committed\n"), 0o600))
+	require.NoError(t, svc.CommitFiles([]string{"pkg/a.go", "b.go"}, "style: format"))
+	assert.Equal(t, "style: format", strings.TrimSpace(runGit(t, dir, "log", "-1", "--format=%s")))
+	assert.Equal(t, "b.go\npkg/a.go", strings.TrimSpace(runGit(t, dir, "show", "--name-only", "--format=", "HEAD")))
+	assert.Equal(t, "?? other.txt", strings.TrimSpace(runGit(t, dir, "status", "--porcelain")))
+}
+
 func TestService_EnsureHasCommits(t *testing.T) {
 	t.Run("returns nil when repo has commits", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -479,6 +607,55 @@ func TestService_EnsureHasCommits(t *testing.T) {
 	})
 }
 
+func TestService_EnsureNestedIgnored(t *testing.T) {
+	content := "*\n!.gitignore\n!config\n"
+	probes := []string{".ralphex/progress/progress-test.txt", ".ralphex/state.json"}
+
+	t.Run("creates nested gitignore", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		log := &mockLogger{}
+		svc, err := NewService(dir, log)
+		require.NoError(t, err)
+
+		require.NoError(t, svc.EnsureNestedIgnored(".ralphex", content, probes...))
+		data, err := os.ReadFile(filepath.Join(dir, ".ralphex", ".gitignore")) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+		require.Len(t, log.logs, 1)
+		assert.Contains(t, log.logs[0], filepath.Join(".ralphex", ".gitignore"))
+		assert.NoFileExists(t, filepath.Join(dir, ".gitignore"), "root gitignore is left alone")
+
+		ignored, err := svc.repo.IsIgnored(".ralphex/config")
+		require.NoError(t, err)
+		assert.False(t, ignored, "shared config stays trackable")
+
+		// second call finds everything ignored
+		require.NoError(t, svc.EnsureNestedIgnored(".ralphex", content, probes...))
+		assert.Len(t, log.logs, 1)
+	})
+
+	t.Run("appends to existing nested gitignore", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, ".ralphex"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".ralphex", ".gitignore"), []byte("local.txt\n"), 0o600))
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, svc.EnsureNestedIgnored(".ralphex", content, probes...))
+		data, err := os.ReadFile(filepath.Join(dir, ".ralphex", ".gitignore")) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Equal(t, "local.txt\n\n"+content, string(data))
+	})
+
+	t.Run("error if still not ignored", func(t *testing.T) {
+		dir := t.TempDir()
+		svc := NewMemoryService(NewMemoryRepo(dir, "master"), noopServiceLogger())
+		err := svc.EnsureNestedIgnored(".ralphex", content, probes...)
+		require.ErrorContains(t, err, ".ralphex/progress/progress-test.txt, .ralphex/state.json still not ignored")
+		assert.FileExists(t, filepath.Join(dir, ".ralphex", ".gitignore"))
+	})
+}
+
 func TestService_EnsureIgnored(t *testing.T) {
 	t.Run("adds pattern to gitignore", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -666,3 +843,224 @@ func TestService_DiffStats(t *testing.T) {
 		assert.Equal(t, 0, stats.Deletions)
 	})
 }
+
+func TestService_ExtractChanges(t *testing.T) {
+	t.Run("commits and uncommitted changes", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\n"), 0o600))
+		runGit(t, dir, "add", ".gitignore")
+		runGit(t, dir, "commit", "-m", "ignore logs")
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		base, err := svc.StartCapture()
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "fix.go"), []byte("package fix\n"), 0o600))
+		runGit(t, dir, "add", "fix.go")
+		runGit(t, dir, "commit", "-m", "fix issue")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# fixed\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "run.log"), []byte("log\n"), 0o600))
+
+		patch, err := svc.ExtractChanges(base)
+		require.NoError(t, err)
+		assert.Contains(t, string(patch), "Subject: [PATCH 1/2] fix issue")
+		assert.Contains(t, string(patch), "Subject: [PATCH 2/2] uncommitted changes")
+		assert.Contains(t, string(patch), "+# fixed")
+		assert.Contains(t, string(patch), "+new")
+		assert.NotContains(t, string(patch), "run.log")
+
+		// worktree restored, ignored files kept
+		assert.Equal(t, base, strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD")))
+		assert.Empty(t, runGit(t, dir, "status", "--porcelain"))
+		assert.NoFileExists(t, filepath.Join(dir, "fix.go"))
+		assert.FileExists(t, filepath.Join(dir, "run.log"))
+
+		// the series applies back
+		cmd := exec.Command("git", "am")
+		cmd.Dir = dir
+		cmd.Stdin = bytes.NewReader(patch)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		assert.FileExists(t, filepath.Join(dir, "fix.go"))
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		base, err := svc.StartCapture()
+		require.NoError(t, err)
+		patch, err := svc.ExtractChanges(base)
+		require.NoError(t, err)
+		assert.Empty(t, patch)
+	})
+
+	t.Run("dirty worktree", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "untracked.txt"), []byte("x"), 0o600))
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		_, err = svc.StartCapture()
+		require.ErrorContains(t, err, "uncommitted changes or untracked files")
+	})
+
+	t.Run("memory repo", func(t *testing.T) {
+		repo := newTestMemoryRepo(t)
+		svc := NewMemoryService(repo, noopServiceLogger())
+		base, err := svc.StartCapture()
+		require.NoError(t, err)
+
+		repo.Touch("a.go")
+		require.NoError(t, repo.Add("a.go"))
+		require.NoError(t, repo.Commit("fix a"))
+		repo.Touch("b.go")
+
+		patch, err := svc.ExtractChanges(base)
+		require.NoError(t, err)
+		assert.Contains(t, string(patch), "Subject: [PATCH] fix a")
+		assert.Contains(t, string(patch), "Subject: [PATCH] uncommitted changes")
+		assert.Len(t, repo.Commits("master"), 1)
+		dirty, err := repo.IsDirty()
+		require.NoError(t, err)
+		assert.False(t, dirty)
+
+		_, err = svc.ExtractChanges("unknown")
+		require.ErrorContains(t, err, "unknown revision")
+	})
+}
+
+func TestService_Snapshot(t *testing.T) {
+	t.Run("dirty worktree", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\n"), 0o600))
+		runGit(t, dir, "add", ".gitignore")
+		runGit(t, dir, "commit", "-m", "ignore logs")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# edited before\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("untracked before\n"), 0o600))
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		statusBefore := runGit(t, dir, "status", "--porcelain")
+
+		snap, err := svc.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD")), snap.Head)
+		assert.NotEqual(t, snap.Head, snap.State)
+		assert.Equal(t, statusBefore, runGit(t, dir, "status", "--porcelain"), "worktree and index untouched")
+
+		// changes of a run: a commit, an edit, a new file, a deleted file and a log
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "fix.go"), []byte("package fix\n"), 0o600))
+		runGit(t, dir, "add", "fix.go")
+		runGit(t, dir, "commit", "-m", "fix issue")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# edited by run\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "half.go"), []byte("package half\n"), 0o600))
+		require.NoError(t, os.Remove(filepath.Join(dir, "notes.txt")))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "run.log"), []byte("log\n"), 0o600))
+
+		require.NoError(t, svc.RestoreSnapshot(snap))
+		assert.Equal(t, snap.Head, strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD")))
+		assert.Equal(t, statusBefore, runGit(t, dir, "status", "--porcelain"))
+		assert.NoFileExists(t, filepath.Join(dir, "fix.go"))
+		assert.NoFileExists(t, filepath.Join(dir, "half.go"))
+		data, err := os.ReadFile(filepath.Join(dir, "README.md")) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Equal(t, "# edited before\n", string(data))
+		assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+		assert.FileExists(t, filepath.Join(dir, "run.log"), "ignored files kept")
+	})
+
+	t.Run("clean worktree", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		snap, err := svc.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, snap.Head, snap.State)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# changed\n"), 0o600))
+		require.NoError(t, svc.RestoreSnapshot(snap))
+		assert.Empty(t, runGit(t, dir, "status", "--porcelain"))
+	})
+
+	t.Run("memory repo", func(t *testing.T) {
+		repo := newTestMemoryRepo(t)
+		svc := NewMemoryService(repo, noopServiceLogger())
+		repo.Touch("before.go")
+		snap, err := svc.Snapshot()
+		require.NoError(t, err)
+
+		require.NoError(t, repo.Add("before.go"))
+		require.NoError(t, repo.Commit("run commit"))
+		repo.Touch("half.go")
+
+		require.NoError(t, svc.RestoreSnapshot(snap))
+		assert.Len(t, repo.Commits("master"), 1)
+		changed, err := repo.FileHasChanges("before.go")
+		require.NoError(t, err)
+		assert.True(t, changed)
+		changed, err = repo.FileHasChanges("half.go")
+		require.NoError(t, err)
+		assert.False(t, changed)
+
+		require.ErrorContains(t, svc.RestoreSnapshot(Snapshot{Head: "unknown"}), "unknown revision")
+	})
+}
+
+func TestService_ApplyPatch(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+	base, err := svc.StartCapture()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# fixed\n"), 0o600))
+	series, err := svc.ExtractChanges(base)
+	require.NoError(t, err)
+	patches := SplitPatches(series)
+	require.Len(t, patches, 1)
+
+	t.Run("conflict is aborted", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# other\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "conflicting change")
+
+		err := svc.ApplyPatch(patches[0])
+		require.ErrorContains(t, err, `apply patch "uncommitted changes"`)
+		assert.Empty(t, runGit(t, dir, "status", "--porcelain"))
+		assert.NoDirExists(t, filepath.Join(dir, ".git", "rebase-apply"))
+		runGit(t, dir, "reset", "--hard", "HEAD~1")
+	})
+
+	t.Run("applies and commits", func(t *testing.T) {
+		require.NoError(t, svc.ApplyPatch(patches[0]))
+		data, err := os.ReadFile(filepath.Join(dir, "README.md")) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Equal(t, "# fixed\n", string(data))
+		assert.Contains(t, runGit(t, dir, "log", "-1", "--format=%s"), "uncommitted changes")
+	})
+}
+
+func TestService_DiffSince(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+	base, err := svc.HeadHash()
+	require.NoError(t, err)
+
+	diff, err := svc.DiffSince(base)
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0o600))
+	runGit(t, dir, "add", "a.go")
+	runGit(t, dir, "commit", "-m", "add a")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# changed\n"), 0o600))
+
+	diff, err = svc.DiffSince(base)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "2 files changed", "stat covers commits and uncommitted changes")
+	assert.Contains(t, diff, "+package a")
+	assert.Contains(t, diff, "+# changed")
+
+	_, err = svc.DiffSince("0000000000000000000000000000000000000000")
+	require.ErrorContains(t, err, "diff since")
+}
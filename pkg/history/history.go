@@ -0,0 +1,399 @@
+// Package history keeps a per-repository log of run outcomes in the run artifacts directory and
+// summarizes it: success rate, mean task iterations and stall frequency, overall and per week,
+// so drifting convergence shows when prompts or models need retuning. runs following a change of
+// prompt templates are marked as canary runs, their convergence is compared with the runs before, and each
+// prompt change is listed with the templates it touched.
+// external review findings recurring across runs are reported as chronic findings, findings dismissed
+// as invalid in several runs are suggested for the project baseline.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/seal"
+)
+
+// File is the name of the history file in the run artifacts directory.
+const File = "history.jsonl"
+
+// CanaryRuns is the number of runs after a prompt change marked as canary runs.
+const CanaryRuns = 5
+
+// ChronicRuns is the number of runs an external review finding has to recur in to be chronic.
+const ChronicRuns = 3
+
+// DismissedRuns is the number of runs a finding has to be dismissed in to be suggested for the baseline.
+const DismissedRuns = 2
+
+// Finding is an external review finding, identified by its file and message.
+type Finding struct {
+	File    string `json:"file"`
+	Message string `json:"message"`
+}
+
+// Key returns the finding normalized for matching across runs, ignoring case and whitespace differences.
+func (f Finding) Key() string {
+	return strings.ToLower(f.File + "\x00" + strings.Join(strings.Fields(f.Message), " "))
+}
+
+// Entry is the outcome of a single run.
+type Entry struct {
+	Time       time.Time     `json:"time"`
+	Mode       string        `json:"mode"`
+	Success    bool          `json:"success"`
+	Failure    string        `json:"failure,omitempty"` // failure class of a failed run
+	Stalled    bool          `json:"stalled,omitempty"` // ran out of iterations or time without finishing
+	Iterations int           `json:"iterations"`        // task iterations
+	Duration   time.Duration `json:"duration"`
+	Prompts    string        `json:"prompts,omitempty"`   // hash of the prompt templates used
+	Canary     bool          `json:"canary,omitempty"`    // one of the first CanaryRuns runs after a prompt change
+	Findings   []Finding     `json:"findings,omitempty"`  // distinct external review findings
+	Dismissed  []Finding     `json:"dismissed,omitempty"` // findings dismissed as invalid by the evaluation
+
+	PromptVersions map[string]string `json:"prompt_versions,omitempty"` // hash of each prompt template by name
+}
+
+// Append adds an entry to the history file in dir, creating both if needed. the entry is encrypted with s,
+// a nil s writes it in plaintext.
+func Append(dir string, e Entry, s *seal.Sealer) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal history entry: %w", err)
+	}
+	data = s.Seal(data)
+	if err = os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("create history dir: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, File), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // artifact dir
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	if _, err = f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write history: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("close history: %w", err)
+	}
+	return nil
+}
+
+// Load reads the history file in dir, oldest entry first, decrypting entries encrypted with s. a missing file
+// is an empty history, lines that don't parse, e.g. a write cut short by a crash, are skipped.
+func Load(dir string, s *seal.Sealer) ([]Entry, error) {
+	f, err := os.Open(filepath.Join(dir, File)) //nolint:gosec // artifact dir
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open history: %w", err)
+	}
+	defer f.Close()
+
+	var res []Entry
+	var openErr error // last encrypted entry failing to decrypt, the history can't be read when none did
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, err := s.Open(scanner.Bytes())
+		if errors.Is(err, seal.ErrNoKey) {
+			return nil, fmt.Errorf("read history: %w", err)
+		}
+		if err != nil {
+			openErr = err
+			continue
+		}
+		var e Entry
+		if json.Unmarshal(line, &e) != nil {
+			continue
+		}
+		res = append(res, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	if len(res) == 0 && openErr != nil {
+		return nil, fmt.Errorf("read history: %w", openErr)
+	}
+	return res, nil
+}
+
+// Summary aggregates a set of runs.
+type Summary struct {
+	Start      time.Time      // first day of the period, the first run for an overall summary
+	Runs       int            // all runs
+	Successes  int            // runs that completed
+	Stalls     int            // runs that ran out of iterations or time
+	Canaries   int            // canary runs, following a prompt change
+	Iterations int            // task iterations of all runs
+	Failures   map[string]int // failed runs by failure class
+}
+
+// SuccessRate returns the share of successful runs, 0 without runs.
+func (s Summary) SuccessRate() float64 {
+	return ratio(s.Successes, s.Runs)
+}
+
+// StallRate returns the share of stalled runs, 0 without runs.
+func (s Summary) StallRate() float64 {
+	return ratio(s.Stalls, s.Runs)
+}
+
+// MeanIterations returns the average task iterations per run, 0 without runs.
+func (s Summary) MeanIterations() float64 {
+	return ratio(s.Iterations, s.Runs)
+}
+
+// add folds an entry into the summary
+func (s *Summary) add(e Entry) {
+	if s.Runs == 0 || e.Time.Before(s.Start) {
+		s.Start = e.Time
+	}
+	s.Runs++
+	s.Iterations += e.Iterations
+	if e.Canary {
+		s.Canaries++
+	}
+	if e.Success {
+		s.Successes++
+		return
+	}
+	if e.Stalled {
+		s.Stalls++
+	}
+	if s.Failures == nil {
+		s.Failures = map[string]int{}
+	}
+	s.Failures[e.Failure]++
+}
+
+// Summarize aggregates all entries.
+func Summarize(entries []Entry) Summary {
+	var s Summary
+	for _, e := range entries {
+		s.add(e)
+	}
+	return s
+}
+
+// PromptsChanged reports whether prompts differ from the prompt templates of the last run that recorded them.
+// false without such a run, there is nothing to compare with.
+func PromptsChanged(entries []Entry, prompts string) bool {
+	for _, e := range slices.Backward(entries) {
+		if e.Prompts != "" {
+			return e.Prompts != prompts
+		}
+	}
+	return false
+}
+
+// PromptChange is a change of the prompt templates between two runs.
+type PromptChange struct {
+	Time    time.Time // the first run with the new prompts
+	From    string    // prompt templates hash before the change, see Entry.Prompts
+	To      string    // prompt templates hash after the change
+	Changed []string  // templates changed, see ChangedPrompts. empty if either run didn't record template versions
+}
+
+// PromptChanges returns the prompt changes between runs recording their prompt templates, oldest first.
+func PromptChanges(entries []Entry) []PromptChange {
+	var res []PromptChange
+	var prev *Entry
+	for i, e := range entries {
+		if e.Prompts == "" {
+			continue
+		}
+		if prev != nil && prev.Prompts != e.Prompts {
+			res = append(res, PromptChange{Time: e.Time, From: prev.Prompts, To: e.Prompts,
+				Changed: ChangedPrompts(prev.PromptVersions, e.PromptVersions)})
+		}
+		prev = &entries[i]
+	}
+	return res
+}
+
+// LastPromptVersions returns the prompt template versions of the last run that recorded them, nil without such a run.
+func LastPromptVersions(entries []Entry) map[string]string {
+	for _, e := range slices.Backward(entries) {
+		if e.Prompts != "" {
+			return e.PromptVersions
+		}
+	}
+	return nil
+}
+
+// ChangedPrompts returns the names of the templates differing between two sets of prompt versions, sorted.
+// templates only one of the sets has are marked as added or removed. nil if either set is empty,
+// e.g. recorded by a version of ralphex keeping only the hash of all templates.
+func ChangedPrompts(before, after map[string]string) []string {
+	if len(before) == 0 || len(after) == 0 {
+		return nil
+	}
+	var res []string
+	for name, v := range after {
+		old, ok := before[name]
+		switch {
+		case !ok:
+			res = append(res, name+" (added)")
+		case old != v:
+			res = append(res, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			res = append(res, name+" (removed)")
+		}
+	}
+	slices.Sort(res)
+	return res
+}
+
+// Canary reports whether a run with the given prompt templates is a canary run: the prompts changed
+// since the last run, or a canary started with these prompts has fewer than CanaryRuns runs so far.
+func Canary(entries []Entry, prompts string) bool {
+	first := slices.IndexFunc(entries, func(e Entry) bool { return e.Prompts == prompts })
+	if first < 0 {
+		return PromptsChanged(entries, prompts)
+	}
+	if !entries[first].Canary || PromptsChanged(entries, prompts) {
+		return false // the baseline, or prompts switched back to an earlier set
+	}
+	runs := 0
+	for _, e := range entries[first:] {
+		if e.Prompts == prompts {
+			runs++
+		}
+	}
+	return runs < CanaryRuns
+}
+
+// LastCanary returns the summary of the runs since the last prompt change, the canary runs and the ones
+// following them with the same prompts, and of the runs before it. ok is false if no run followed a prompt change.
+func LastCanary(entries []Entry) (since, before Summary, ok bool) {
+	start := -1
+	for i, e := range slices.Backward(entries) {
+		if e.Canary && (i == 0 || entries[i-1].Prompts != e.Prompts || !entries[i-1].Canary) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return Summary{}, Summary{}, false
+	}
+	prompts := entries[start].Prompts
+	for _, e := range entries[start:] {
+		if e.Prompts == prompts {
+			since.add(e)
+		}
+	}
+	return since, Summarize(entries[:start]), true
+}
+
+// Chronic is a finding recurring across runs.
+type Chronic struct {
+	Finding
+	Runs int       // runs reporting the finding
+	Last time.Time // the last run reporting it
+}
+
+// ChronicFindings returns findings reported by at least ChronicRuns runs, most frequent first.
+// findings match on file and message, ignoring case and whitespace differences.
+func ChronicFindings(entries []Entry) []Chronic {
+	return recurring(entries, func(e Entry) []Finding { return e.Findings }, ChronicRuns)
+}
+
+// DismissedFindings returns findings dismissed as invalid by at least DismissedRuns runs, most frequent first.
+func DismissedFindings(entries []Entry) []Chronic {
+	return recurring(entries, func(e Entry) []Finding { return e.Dismissed }, DismissedRuns)
+}
+
+// recurring returns findings of entries listed by at least minRuns runs, most frequent first
+func recurring(entries []Entry, findings func(Entry) []Finding, minRuns int) []Chronic {
+	byKey := map[string]*Chronic{}
+	var keys []string
+	for _, e := range entries {
+		seen := map[string]bool{}
+		for _, f := range findings(e) {
+			key := f.Key()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			c, ok := byKey[key]
+			if !ok {
+				c = &Chronic{}
+				byKey[key] = c
+				keys = append(keys, key)
+			}
+			c.Finding, c.Runs = f, c.Runs+1 // the latest wording is shown
+			if e.Time.After(c.Last) {
+				c.Last = e.Time
+			}
+		}
+	}
+	var res []Chronic
+	for _, key := range keys {
+		if byKey[key].Runs >= minRuns {
+			res = append(res, *byKey[key])
+		}
+	}
+	slices.SortStableFunc(res, func(a, b Chronic) int { return b.Runs - a.Runs })
+	return res
+}
+
+// Recurring returns the chronic findings among findings, in the order of chronic.
+func Recurring(chronic []Chronic, findings []Finding) []Chronic {
+	var res []Chronic
+	for _, c := range chronic {
+		key := c.Key()
+		if slices.ContainsFunc(findings, func(f Finding) bool { return f.Key() == key }) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Weekly aggregates entries of the last weeks weeks up to now, one summary per calendar week
+// starting on Monday, oldest first. weeks without runs are included, with Runs 0.
+func Weekly(entries []Entry, weeks int, now time.Time) []Summary {
+	if weeks <= 0 {
+		return nil
+	}
+	res := make([]Summary, weeks)
+	last := weekStart(now)
+	for i := range res {
+		res[i].Start = last.AddDate(0, 0, -7*(weeks-1-i))
+	}
+	for _, e := range entries {
+		t := e.Time.In(now.Location())
+		idx := weeks - 1 - int(last.Sub(weekStart(t)).Hours()/24/7+0.5)
+		if idx < 0 || idx >= weeks {
+			continue
+		}
+		start := res[idx].Start
+		res[idx].add(e)
+		res[idx].Start = start // keep the week start, not the first run
+	}
+	return res
+}
+
+// weekStart returns midnight of the Monday of t's week, in t's location
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+	y, m, d := t.Date()
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
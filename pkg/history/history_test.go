@@ -0,0 +1,200 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/seal"
+)
+
+func TestAppendLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".ralphex")
+	entries, err := Load(dir, nil)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "missing file is an empty history")
+
+	ts := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, Append(dir, Entry{Time: ts, Mode: "full", Success: true, Iterations: 4, Duration: time.Hour}, nil))
+	require.NoError(t, Append(dir, Entry{Time: ts.Add(time.Hour), Mode: "review", Failure: "max_iterations", Stalled: true}, nil))
+	// a line cut short by a crash is skipped
+	f, err := os.OpenFile(filepath.Join(dir, File), os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"time":"2026-10-`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	entries, err = Load(dir, nil)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, Entry{Time: ts, Mode: "full", Success: true, Iterations: 4, Duration: time.Hour}, entries[0])
+	assert.True(t, entries[1].Stalled)
+	assert.Equal(t, "max_iterations", entries[1].Failure)
+}
+
+func TestAppendLoad_Encrypted(t *testing.T) {
+	dir := t.TempDir()
+	s, err := seal.New("history passphrase")
+	require.NoError(t, err)
+	ts := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, Append(dir, Entry{Time: ts, Mode: "full", Iterations: 1}, nil)) // written before encryption
+	require.NoError(t, Append(dir, Entry{Time: ts, Mode: "review", Findings: []Finding{{File: "a.go", Message: "leak"}}}, s))
+
+	data, err := os.ReadFile(filepath.Join(dir, File))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "leak")
+
+	entries, err := Load(dir, s)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "full", entries[0].Mode)
+	assert.Equal(t, []Finding{{File: "a.go", Message: "leak"}}, entries[1].Findings)
+
+	_, err = Load(dir, nil)
+	require.ErrorIs(t, err, seal.ErrNoKey)
+
+	other, err := seal.New("wrong passphrase")
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(dir, File)))
+	require.NoError(t, Append(dir, Entry{Time: ts, Mode: "full"}, s))
+	_, err = Load(dir, other)
+	require.ErrorContains(t, err, "wrong key")
+}
+
+func TestSummarize(t *testing.T) {
+	ts := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	s := Summarize([]Entry{
+		{Time: ts.Add(time.Hour), Success: true, Iterations: 3},
+		{Time: ts, Success: true, Iterations: 5},
+		{Time: ts.Add(2 * time.Hour), Failure: "max_iterations", Stalled: true, Iterations: 50},
+		{Time: ts.Add(3 * time.Hour), Failure: "canceled", Iterations: 2},
+	})
+	assert.Equal(t, ts, s.Start)
+	assert.Equal(t, 4, s.Runs)
+	assert.InDelta(t, 0.5, s.SuccessRate(), 0.001)
+	assert.InDelta(t, 0.25, s.StallRate(), 0.001)
+	assert.InDelta(t, 15.0, s.MeanIterations(), 0.001)
+	assert.Equal(t, map[string]int{"max_iterations": 1, "canceled": 1}, s.Failures)
+
+	empty := Summarize(nil)
+	assert.Zero(t, empty.SuccessRate())
+	assert.Zero(t, empty.MeanIterations())
+}
+
+func TestWeekly(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) // friday
+	entries := []Entry{
+		{Time: time.Date(2026, 10, 12, 0, 30, 0, 0, time.UTC), Success: true, Iterations: 2},      // monday, this week
+		{Time: time.Date(2026, 10, 11, 23, 0, 0, 0, time.UTC), Failure: "timeout", Stalled: true}, // sunday, last week
+		{Time: time.Date(2026, 10, 6, 10, 0, 0, 0, time.UTC), Success: true, Iterations: 4},
+		{Time: time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC), Success: true}, // out of range
+	}
+	weeks := Weekly(entries, 3, now)
+	require.Len(t, weeks, 3)
+	assert.Equal(t, time.Date(2026, 9, 28, 0, 0, 0, 0, time.UTC), weeks[0].Start)
+	assert.Zero(t, weeks[0].Runs)
+	assert.Equal(t, time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), weeks[1].Start)
+	assert.Equal(t, 2, weeks[1].Runs)
+	assert.Equal(t, 1, weeks[1].Stalls)
+	assert.Equal(t, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), weeks[2].Start)
+	assert.Equal(t, 1, weeks[2].Runs)
+	assert.InDelta(t, 1.0, weeks[2].SuccessRate(), 0.001)
+
+	assert.Nil(t, Weekly(entries, 0, now))
+}
+
+func TestCanary(t *testing.T) {
+	ts := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	var entries []Entry
+	record := func(prompts string) bool {
+		canary := Canary(entries, prompts)
+		entries = append(entries, Entry{Time: ts.Add(time.Duration(len(entries)) * time.Hour), Prompts: prompts,
+			Canary: canary, Success: true})
+		return canary
+	}
+
+	assert.False(t, PromptsChanged(nil, "a"), "nothing to compare with")
+	assert.False(t, record("a"), "first run is the baseline")
+	assert.False(t, record("a"))
+	assert.True(t, PromptsChanged(entries, "b"))
+	for i := range CanaryRuns {
+		assert.True(t, record("b"), "run %d after the change", i)
+	}
+	assert.False(t, record("b"), "canary is over")
+	assert.False(t, record("a"), "switched back to the earlier prompts")
+	assert.False(t, record("b"), "switched back to the earlier prompts")
+
+	since, before, ok := LastCanary(entries)
+	require.True(t, ok)
+	assert.Equal(t, ts.Add(2*time.Hour), since.Start)
+	assert.Equal(t, CanaryRuns+2, since.Runs, "canary runs and the later runs with the same prompts")
+	assert.Equal(t, CanaryRuns, since.Canaries)
+	assert.Equal(t, 2, before.Runs)
+
+	_, _, ok = LastCanary(entries[:2])
+	assert.False(t, ok, "no prompt change")
+}
+
+func TestPromptChanges(t *testing.T) {
+	ts := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	v1 := map[string]string{"task": "1", "codex": "1", "agent:a": "1"}
+	v2 := map[string]string{"task": "2", "codex": "1", "agent:b": "1"}
+	entries := []Entry{
+		{Time: ts, Prompts: "a"}, // recorded before template versions were
+		{Time: ts.Add(time.Hour), Prompts: "b", PromptVersions: v1},
+		{Time: ts.Add(2 * time.Hour)}, // prompts not recorded
+		{Time: ts.Add(3 * time.Hour), Prompts: "b", PromptVersions: v1},
+		{Time: ts.Add(4 * time.Hour), Prompts: "c", PromptVersions: v2},
+	}
+
+	changes := PromptChanges(entries)
+	require.Len(t, changes, 2)
+	assert.Equal(t, PromptChange{Time: ts.Add(time.Hour), From: "a", To: "b"}, changes[0])
+	assert.Equal(t, PromptChange{Time: ts.Add(4 * time.Hour), From: "b", To: "c",
+		Changed: []string{"agent:a (removed)", "agent:b (added)", "task"}}, changes[1])
+
+	assert.Equal(t, v2, LastPromptVersions(entries))
+	assert.Nil(t, LastPromptVersions(entries[:1]))
+	assert.Empty(t, PromptChanges(entries[:1]))
+	assert.Nil(t, ChangedPrompts(v1, v1))
+}
+
+func TestChronicFindings(t *testing.T) {
+	ts := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	nilDeref := Finding{File: "a.go", Message: "possible nil dereference"}
+	unused := Finding{File: "b.go", Message: "unused parameter"}
+	entries := []Entry{
+		{Time: ts, Findings: []Finding{nilDeref, unused}},
+		{Time: ts.Add(time.Hour), Findings: []Finding{{File: "a.go", Message: "Possible  nil dereference"}}},
+		{Time: ts.Add(2 * time.Hour)},
+		{Time: ts.Add(3 * time.Hour), Findings: []Finding{nilDeref, nilDeref, unused}},
+		{Time: ts.Add(4 * time.Hour), Findings: []Finding{unused}},
+		{Time: ts.Add(5 * time.Hour), Findings: []Finding{{File: "c.go", Message: "once"}}},
+	}
+	chronic := ChronicFindings(entries)
+	require.Len(t, chronic, 2)
+	assert.Equal(t, Chronic{Finding: nilDeref, Runs: 3, Last: ts.Add(3 * time.Hour)}, chronic[0])
+	assert.Equal(t, Chronic{Finding: unused, Runs: 3, Last: ts.Add(4 * time.Hour)}, chronic[1])
+
+	assert.Equal(t, chronic[:1], Recurring(chronic, []Finding{{File: "A.go", Message: "possible nil dereference"},
+		{File: "c.go", Message: "once"}}))
+	assert.Empty(t, Recurring(chronic, []Finding{{File: "c.go", Message: "once"}}))
+	assert.Empty(t, ChronicFindings(entries[:2]))
+}
+
+func TestDismissedFindings(t *testing.T) {
+	ts := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	falsePositive := Finding{File: "a.go", Message: "error not checked"}
+	entries := []Entry{
+		{Time: ts, Findings: []Finding{falsePositive}, Dismissed: []Finding{falsePositive}},
+		{Time: ts.Add(time.Hour), Dismissed: []Finding{{File: "b.go", Message: "once"}}},
+		{Time: ts.Add(2 * time.Hour), Dismissed: []Finding{{File: "a.go", Message: "Error not  checked"}}},
+	}
+	assert.Equal(t, []Chronic{{Finding: Finding{File: "a.go", Message: "Error not  checked"}, Runs: 2, Last: ts.Add(2 * time.Hour)}},
+		DismissedFindings(entries))
+	assert.Empty(t, ChronicFindings(entries), "dismissals are not counted as reports")
+}
@@ -230,6 +230,22 @@ func (c *TerminalCollector) readCustomAnswer(ctx context.Context, reader *bufio.
 	return answer, nil
 }
 
+// ReadAnswer prints a question of the agent and reads a free-text answer line from stdin.
+// returns an error on an empty answer, a read error or EOF, or context cancellation.
+func ReadAnswer(ctx context.Context, question string, stdin io.Reader, stdout io.Writer) (string, error) {
+	fmt.Fprintf(stdout, "\nQUESTION: %s\nEnter your answer: ", question)
+	line, err := ReadLineWithContext(ctx, bufio.NewReader(stdin))
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		fmt.Fprintln(stdout) // newline so subsequent output doesn't appear on the same line
+		return "", fmt.Errorf("read answer: %w", err)
+	}
+	answer := strings.TrimSpace(line)
+	if answer == "" {
+		return "", errors.New("answer cannot be empty")
+	}
+	return answer, nil
+}
+
 // AskYesNo prompts with [y/N] and returns true for yes.
 // defaults to no on EOF, empty input, context cancellation, or any read error.
 func AskYesNo(ctx context.Context, prompt string, stdin io.Reader, stdout io.Writer) bool {
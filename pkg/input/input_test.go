@@ -299,6 +299,29 @@ func TestTerminalCollector_getStdinStdout(t *testing.T) {
 	})
 }
 
+func TestReadAnswer(t *testing.T) {
+	var stdout bytes.Buffer
+	answer, err := ReadAnswer(context.Background(), "Keep v1?", strings.NewReader("  remove it \n"), &stdout)
+	require.NoError(t, err)
+	assert.Equal(t, "remove it", answer)
+	assert.Equal(t, "\nQUESTION: Keep v1?\nEnter your answer: ", stdout.String())
+
+	answer, err = ReadAnswer(context.Background(), "Keep v1?", strings.NewReader("keep it"), &stdout)
+	require.NoError(t, err)
+	assert.Equal(t, "keep it", answer, "last line without newline")
+
+	_, err = ReadAnswer(context.Background(), "Keep v1?", strings.NewReader(""), &stdout)
+	require.ErrorIs(t, err, io.EOF)
+
+	_, err = ReadAnswer(context.Background(), "Keep v1?", strings.NewReader("\n"), &stdout)
+	require.EqualError(t, err, "answer cannot be empty")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ReadAnswer(ctx, "Keep v1?", strings.NewReader("late\n"), &stdout)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
 func TestAskYesNo(t *testing.T) {
 	tests := []struct {
 		name  string
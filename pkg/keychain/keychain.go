@@ -0,0 +1,103 @@
+// Package keychain stores and retrieves secrets in the OS keychain.
+// macOS uses the login keychain via security(1), Linux and BSD use the Secret Service via secret-tool(1),
+// and Windows uses the Credential Manager API. config values can reference stored secrets as "keychain:<name>".
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Service is the keychain service name all ralphex secrets are stored under.
+const Service = "ralphex"
+
+// RefPrefix marks a config value as a reference to a keychain entry.
+const RefPrefix = "keychain:"
+
+// ErrNotFound is returned when no secret is stored under the requested name.
+var ErrNotFound = errors.New("secret not found in keychain")
+
+// backend is implemented per platform.
+type backend interface {
+	get(name string) (string, error)
+	set(name, secret string) error
+	remove(name string) error
+}
+
+// Store provides access to secrets kept in the OS keychain.
+type Store struct {
+	be backend
+}
+
+// New returns a Store backed by the platform keychain.
+func New() *Store {
+	return &Store{be: newPlatformBackend()}
+}
+
+// Get returns the secret stored under name.
+func (s *Store) Get(name string) (string, error) {
+	if err := validateName(name); err != nil {
+		return "", err
+	}
+	secret, err := s.be.get(name)
+	if err != nil {
+		return "", fmt.Errorf("get %s: %w", name, err)
+	}
+	return secret, nil
+}
+
+// Set stores secret under name, replacing any existing value.
+func (s *Store) Set(name, secret string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if secret == "" {
+		return errors.New("empty secret")
+	}
+	if err := s.be.set(name, secret); err != nil {
+		return fmt.Errorf("set %s: %w", name, err)
+	}
+	return nil
+}
+
+// Delete removes the secret stored under name.
+func (s *Store) Delete(name string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if err := s.be.remove(name); err != nil {
+		return fmt.Errorf("delete %s: %w", name, err)
+	}
+	return nil
+}
+
+// Resolve returns value unchanged unless it is a "keychain:<name>" reference,
+// in which case the referenced secret is looked up.
+func (s *Store) Resolve(value string) (string, error) {
+	name, ok := IsRef(value)
+	if !ok {
+		return value, nil
+	}
+	return s.Get(name)
+}
+
+// IsRef reports whether value is a keychain reference and returns the referenced name.
+func IsRef(value string) (string, bool) {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, RefPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(trimmed[len(RefPrefix):]), true
+}
+
+// validateName rejects names that can't be used as keychain account identifiers.
+func validateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("empty secret name")
+	}
+	if strings.ContainsAny(name, " \t\r\n") {
+		return fmt.Errorf("invalid secret name %q: must not contain whitespace", name)
+	}
+	return nil
+}
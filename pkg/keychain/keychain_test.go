@@ -0,0 +1,205 @@
+//go:build !windows
+
+package keychain
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memBackend is an in-memory backend for testing Store.
+type memBackend struct {
+	items map[string]string
+	err   error
+}
+
+func (m *memBackend) get(name string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	v, ok := m.items[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func (m *memBackend) set(name, secret string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.items[name] = secret
+	return nil
+}
+
+func (m *memBackend) remove(name string) error {
+	if m.err != nil {
+		return m.err
+	}
+	if _, ok := m.items[name]; !ok {
+		return ErrNotFound
+	}
+	delete(m.items, name)
+	return nil
+}
+
+func TestStore_SetGetDelete(t *testing.T) {
+	s := &Store{be: &memBackend{items: map[string]string{}}}
+
+	require.NoError(t, s.Set("telegram", "123:abc"))
+	got, err := s.Get("telegram")
+	require.NoError(t, err)
+	assert.Equal(t, "123:abc", got)
+
+	require.NoError(t, s.Delete("telegram"))
+	_, err = s.Get("telegram")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStore_Validation(t *testing.T) {
+	s := &Store{be: &memBackend{items: map[string]string{}}}
+
+	tests := []struct {
+		name string
+		fn   func() error
+		want string
+	}{
+		{name: "empty name get", fn: func() error { _, err := s.Get(""); return err }, want: "empty secret name"},
+		{name: "whitespace name", fn: func() error { return s.Set("my token", "x") }, want: "must not contain whitespace"},
+		{name: "empty secret", fn: func() error { return s.Set("slack", "") }, want: "empty secret"},
+		{name: "empty name delete", fn: func() error { return s.Delete(" ") }, want: "empty secret name"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.fn()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+		})
+	}
+}
+
+func TestStore_BackendError(t *testing.T) {
+	s := &Store{be: &memBackend{items: map[string]string{}, err: errors.New("locked")}}
+	_, err := s.Get("slack")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "get slack: locked")
+}
+
+func TestStore_Resolve(t *testing.T) {
+	s := &Store{be: &memBackend{items: map[string]string{"slack": "xoxb-1"}}}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain value", value: "xoxb-plain", want: "xoxb-plain"},
+		{name: "empty value", value: "", want: ""},
+		{name: "reference", value: "keychain:slack", want: "xoxb-1"},
+		{name: "reference with spaces", value: "  keychain: slack ", want: "xoxb-1"},
+		{name: "missing reference", value: "keychain:telegram", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.Resolve(tc.value)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestIsRef(t *testing.T) {
+	name, ok := IsRef("keychain:telegram")
+	assert.True(t, ok)
+	assert.Equal(t, "telegram", name)
+
+	_, ok = IsRef("telegram")
+	assert.False(t, ok)
+}
+
+// recordedCall captures a single cmdRunner invocation.
+type recordedCall struct {
+	stdin string
+	cmd   string
+}
+
+func TestCLIBackend_Commands(t *testing.T) {
+	tests := []struct {
+		name    string
+		goos    string
+		fn      func(b *cliBackend) error
+		wantCmd string
+		wantIn  string
+	}{
+		{name: "darwin get", goos: "darwin", fn: func(b *cliBackend) error { _, err := b.get("slack"); return err },
+			wantCmd: "security find-generic-password -s ralphex -a slack -w"},
+		{name: "darwin set uses stdin", goos: "darwin", fn: func(b *cliBackend) error { return b.set("slack", "s3cret") },
+			wantCmd: "security add-generic-password -U -s ralphex -a slack -w", wantIn: "s3cret\ns3cret\n"},
+		{name: "darwin delete", goos: "darwin", fn: func(b *cliBackend) error { return b.remove("slack") },
+			wantCmd: "security delete-generic-password -s ralphex -a slack"},
+		{name: "linux get", goos: "linux", fn: func(b *cliBackend) error { _, err := b.get("slack"); return err },
+			wantCmd: "secret-tool lookup service ralphex account slack"},
+		{name: "linux set uses stdin", goos: "linux", fn: func(b *cliBackend) error { return b.set("slack", "s3cret") },
+			wantCmd: "secret-tool store --label ralphex slack service ralphex account slack", wantIn: "s3cret"},
+		{name: "linux delete", goos: "linux", fn: func(b *cliBackend) error { return b.remove("slack") },
+			wantCmd: "secret-tool clear service ralphex account slack"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []recordedCall
+			b := &cliBackend{goos: tc.goos, run: func(stdin, name string, args ...string) (string, error) {
+				calls = append(calls, recordedCall{stdin: stdin, cmd: strings.Join(append([]string{name}, args...), " ")})
+				return "value\n", nil
+			}}
+			require.NoError(t, tc.fn(b))
+			require.Len(t, calls, 1)
+			assert.Equal(t, tc.wantCmd, calls[0].cmd)
+			assert.Equal(t, tc.wantIn, calls[0].stdin)
+		})
+	}
+}
+
+func TestCLIBackend_GetTrimsNewline(t *testing.T) {
+	b := &cliBackend{goos: "linux", run: func(_, _ string, _ ...string) (string, error) { return "tok\n", nil }}
+	got, err := b.get("slack")
+	require.NoError(t, err)
+	assert.Equal(t, "tok", got)
+}
+
+func TestCLIBackend_GetNotFound(t *testing.T) {
+	t.Run("linux empty output", func(t *testing.T) {
+		b := &cliBackend{goos: "linux", run: func(_, _ string, _ ...string) (string, error) { return "", nil }}
+		_, err := b.get("slack")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("linux exit 1", func(t *testing.T) {
+		exitErr := exec.Command("sh", "-c", "exit 1").Run() //nolint:noctx // test helper producing a real ExitError
+		b := &cliBackend{goos: "linux", run: func(_, _ string, _ ...string) (string, error) { return "", exitErr }}
+		_, err := b.get("slack")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("darwin exit 44", func(t *testing.T) {
+		exitErr := exec.Command("sh", "-c", "exit 44").Run() //nolint:noctx // test helper producing a real ExitError
+		b := &cliBackend{goos: "darwin", run: func(_, _ string, _ ...string) (string, error) { return "", exitErr }}
+		_, err := b.get("slack")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestExecRun_MissingBinary(t *testing.T) {
+	_, err := execRun("", "ralphex-no-such-binary-xyz")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "keychain is unavailable")
+}
@@ -0,0 +1,104 @@
+//go:build !windows
+
+package keychain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// cmdRunner runs a command with optional stdin and returns its stdout.
+type cmdRunner func(stdin, name string, args ...string) (string, error)
+
+// cliBackend implements backend by shelling out to security(1) on macOS or secret-tool(1) elsewhere.
+type cliBackend struct {
+	goos string
+	run  cmdRunner
+}
+
+func newPlatformBackend() backend {
+	return &cliBackend{goos: runtime.GOOS, run: execRun}
+}
+
+func (b *cliBackend) get(name string) (string, error) {
+	if b.goos == "darwin" {
+		out, err := b.run("", "security", "find-generic-password", "-s", Service, "-a", name, "-w")
+		if err != nil {
+			if isNotFoundErr(err) {
+				return "", ErrNotFound
+			}
+			return "", err
+		}
+		return strings.TrimRight(out, "\r\n"), nil
+	}
+
+	out, err := b.run("", "secret-tool", "lookup", "service", Service, "account", name)
+	if err != nil {
+		// secret-tool exits 1 with no output when the item doesn't exist
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	if out == "" {
+		return "", ErrNotFound
+	}
+	return strings.TrimRight(out, "\r\n"), nil
+}
+
+func (b *cliBackend) set(name, secret string) error {
+	if b.goos == "darwin" {
+		// -U updates the item if it already exists. -w as the last argument makes security prompt for the
+		// secret and its confirmation on stdin, keeping it out of the process list
+		_, err := b.run(secret+"\n"+secret+"\n", "security", "add-generic-password", "-U", "-s", Service, "-a", name, "-w")
+		return err
+	}
+	// secret-tool reads the secret from stdin as well
+	_, err := b.run(secret, "secret-tool", "store", "--label", Service+" "+name, "service", Service, "account", name)
+	return err
+}
+
+func (b *cliBackend) remove(name string) error {
+	if b.goos == "darwin" {
+		_, err := b.run("", "security", "delete-generic-password", "-s", Service, "-a", name)
+		if err != nil && isNotFoundErr(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	_, err := b.run("", "secret-tool", "clear", "service", Service, "account", name)
+	return err
+}
+
+// isNotFoundErr detects the "item could not be found" exit status (44) used by security(1).
+func isNotFoundErr(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == 44
+}
+
+// execRun is the default cmdRunner using os/exec.
+func execRun(stdin, name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s not found in PATH, keychain is unavailable: %w", name, err)
+	}
+	cmd := exec.CommandContext(context.Background(), name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,110 @@
+//go:build windows
+
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	credTypeGeneric         = 1 // CRED_TYPE_GENERIC
+	credPersistLocalMachine = 2 // CRED_PERSIST_LOCAL_MACHINE
+)
+
+var (
+	advapi32       = windows.NewLazySystemDLL("advapi32.dll")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+// credential mirrors the CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// credBackend implements backend with the Windows Credential Manager.
+type credBackend struct{}
+
+func newPlatformBackend() backend {
+	return credBackend{}
+}
+
+func targetName(name string) string {
+	return Service + ":" + name
+}
+
+func (credBackend) get(name string) (string, error) {
+	target, err := windows.UTF16PtrFromString(targetName(name))
+	if err != nil {
+		return "", fmt.Errorf("encode target: %w", err)
+	}
+	var pcred *credential
+	r, _, callErr := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&pcred)))
+	if r == 0 {
+		if errors.Is(callErr, windows.ERROR_NOT_FOUND) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("CredReadW: %w", callErr)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(pcred))) //nolint:errcheck // CredFree has no meaningful result
+	if pcred.CredentialBlobSize == 0 {
+		return "", nil
+	}
+	blob := unsafe.Slice(pcred.CredentialBlob, pcred.CredentialBlobSize)
+	return string(blob), nil
+}
+
+func (credBackend) set(name, secret string) error {
+	target, err := windows.UTF16PtrFromString(targetName(name))
+	if err != nil {
+		return fmt.Errorf("encode target: %w", err)
+	}
+	user, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return fmt.Errorf("encode user: %w", err)
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		UserName:           user,
+		CredentialBlobSize: uint32(len(blob)), //nolint:gosec // secrets are far below 4GB
+		CredentialBlob:     &blob[0],
+		Persist:            credPersistLocalMachine,
+	}
+	if r, _, callErr := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0); r == 0 {
+		return fmt.Errorf("CredWriteW: %w", callErr)
+	}
+	return nil
+}
+
+func (credBackend) remove(name string) error {
+	target, err := windows.UTF16PtrFromString(targetName(name))
+	if err != nil {
+		return fmt.Errorf("encode target: %w", err)
+	}
+	if r, _, callErr := procCredDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); r == 0 {
+		if errors.Is(callErr, windows.ERROR_NOT_FOUND) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("CredDeleteW: %w", callErr)
+	}
+	return nil
+}
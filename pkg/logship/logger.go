@@ -0,0 +1,119 @@
+package logship
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// event kinds
+const (
+	KindOutput   = "output"
+	KindSection  = "section"
+	KindDiff     = "diff"
+	KindQuestion = "question"
+	KindAnswer   = "answer"
+)
+
+//go:generate moq -out mocks/logger.go -pkg mocks -skip-ensure -fmt goimports . Logger
+
+// Logger provides progress logging, wrapped to ship what is logged.
+type Logger interface {
+	Print(format string, args ...any)
+	PrintRaw(format string, args ...any)
+	PrintSection(section status.Section)
+	PrintAligned(text string)
+	PrintDiff(diff string)
+	LogQuestion(question string, options []string)
+	LogAnswer(answer string)
+	LogDraftReview(action string, feedback string)
+	Path() string
+}
+
+// ShipLogger wraps a Logger and ships everything logged as events, tagged with the current phase.
+// all calls are forwarded to the inner logger first, shipping never affects local logging.
+type ShipLogger struct {
+	inner   Logger
+	shipper *Shipper
+	holder  *status.PhaseHolder
+}
+
+// NewLogger creates a logger that wraps inner and ships to shipper.
+func NewLogger(inner Logger, shipper *Shipper, holder *status.PhaseHolder) *ShipLogger {
+	return &ShipLogger{inner: inner, shipper: shipper, holder: holder}
+}
+
+// Print writes a timestamped message and ships it.
+func (l *ShipLogger) Print(format string, args ...any) {
+	l.inner.Print(format, args...)
+	l.ship(KindOutput, formatText(format, args...))
+}
+
+// PrintRaw writes without timestamp and ships it.
+func (l *ShipLogger) PrintRaw(format string, args ...any) {
+	l.inner.PrintRaw(format, args...)
+	l.ship(KindOutput, strings.TrimRight(formatText(format, args...), "\n"))
+}
+
+// PrintSection writes a section header and ships it.
+func (l *ShipLogger) PrintSection(section status.Section) {
+	l.inner.PrintSection(section)
+	l.ship(KindSection, section.Label)
+}
+
+// PrintAligned writes text with timestamp on each line and ships it.
+func (l *ShipLogger) PrintAligned(text string) {
+	l.inner.PrintAligned(text)
+	l.ship(KindOutput, text)
+}
+
+// PrintDiff writes a diff and ships it.
+func (l *ShipLogger) PrintDiff(diff string) {
+	l.inner.PrintDiff(diff)
+	l.ship(KindDiff, diff)
+}
+
+// LogQuestion logs a question with its options and ships it.
+func (l *ShipLogger) LogQuestion(question string, options []string) {
+	l.inner.LogQuestion(question, options)
+	l.ship(KindQuestion, question+" ["+strings.Join(options, ", ")+"]")
+}
+
+// LogAnswer logs the user's answer and ships it.
+func (l *ShipLogger) LogAnswer(answer string) {
+	l.inner.LogAnswer(answer)
+	l.ship(KindAnswer, answer)
+}
+
+// LogDraftReview logs the user's draft review action with optional feedback and ships it.
+func (l *ShipLogger) LogDraftReview(action, feedback string) {
+	l.inner.LogDraftReview(action, feedback)
+	text := "draft review: " + action
+	if feedback != "" {
+		text += ", feedback: " + feedback
+	}
+	l.ship(KindAnswer, text)
+}
+
+// Path returns the progress file path.
+func (l *ShipLogger) Path() string {
+	return l.inner.Path()
+}
+
+// ship queues an event in the current phase, empty text is skipped.
+func (l *ShipLogger) ship(kind, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	l.shipper.Ship(Event{Time: time.Now(), Kind: kind, Phase: string(l.holder.Get()), Text: text})
+}
+
+// formatText formats a string with args, like fmt.Sprintf.
+func formatText(format string, args ...any) string {
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
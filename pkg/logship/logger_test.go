@@ -0,0 +1,65 @@
+package logship
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/logship/mocks"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestShipLogger(t *testing.T) {
+	var mu sync.Mutex
+	var got []Event
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Events []Event `json:"events"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		got = append(got, body.Events...)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	shipper, err := New(Params{Destination: srv.URL})
+	require.NoError(t, err)
+	inner := &mocks.LoggerMock{
+		PrintFunc:        func(string, ...any) {},
+		PrintRawFunc:     func(string, ...any) {},
+		PrintSectionFunc: func(status.Section) {},
+		PrintAlignedFunc: func(string) {},
+		LogQuestionFunc:  func(string, []string) {},
+		PathFunc:         func() string { return "progress.txt" },
+	}
+	holder := &status.PhaseHolder{}
+	holder.Set(status.PhaseTask)
+	l := NewLogger(inner, shipper, holder)
+
+	l.Print("iteration %d", 1)
+	l.PrintRaw("\n")
+	l.PrintSection(status.NewGenericSection("claude review 0"))
+	holder.Set(status.PhaseReview)
+	l.PrintAligned("found nothing")
+	l.LogQuestion("which db?", []string{"pg", "sqlite"})
+	assert.Equal(t, "progress.txt", l.Path())
+	require.NoError(t, shipper.Close())
+
+	assert.Len(t, inner.PrintCalls(), 1)
+	assert.Len(t, inner.PrintRawCalls(), 1)
+	mu.Lock()
+	defer mu.Unlock()
+	var kinds, phases, texts []string
+	for _, e := range got {
+		kinds, phases, texts = append(kinds, e.Kind), append(phases, e.Phase), append(texts, e.Text)
+	}
+	assert.Equal(t, []string{KindOutput, KindSection, KindOutput, KindQuestion}, kinds, "blank output skipped")
+	assert.Equal(t, []string{"task", "task", "review", "review"}, phases)
+	assert.Equal(t, []string{"iteration 1", "claude review 0", "found nothing", "which db? [pg, sqlite]"}, texts)
+}
@@ -0,0 +1,384 @@
+// Package logship ships structured run events (output lines, sections, questions) to an external
+// log collector, so runs on build servers show up in an existing observability stack.
+// supported collectors are syslog (RFC 5424 over udp or tcp), Loki and a generic HTTP endpoint
+// taking JSON. events are sent in batches from a background goroutine and never block the run,
+// events that don't fit the buffer of a slow collector are dropped and counted.
+package logship
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaults for Params fields left zero
+const (
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = 2 * time.Second
+	DefaultTimeout       = 5 * time.Second
+)
+
+// closeTimeout bounds flushing the remaining events on Close.
+const closeTimeout = 10 * time.Second
+
+// bufferBatches is the number of full batches buffered while the collector is busy.
+const bufferBatches = 10
+
+// Event is a single run event.
+type Event struct {
+	Time  time.Time `json:"time"`
+	Kind  string    `json:"kind"` // output, section, diff, question, answer
+	Phase string    `json:"phase"`
+	Text  string    `json:"text"`
+}
+
+// Params configures a Shipper.
+type Params struct {
+	Destination   string            // syslog://host:port, syslog+tcp://host:port, loki://host:port, http(s)://...
+	Labels        map[string]string // attached to every event, e.g. plan, mode and branch
+	BatchSize     int               // events per batch, DefaultBatchSize if 0
+	FlushInterval time.Duration     // max delay of a partial batch, DefaultFlushInterval if 0
+	Timeout       time.Duration     // bounds sending a batch, DefaultTimeout if 0
+}
+
+// Shipper batches events and sends them to the collector. a nil Shipper is a no-op.
+type Shipper struct {
+	sink          sink
+	labels        map[string]string
+	batchSize     int
+	flushInterval time.Duration
+	timeout       time.Duration
+
+	events chan Event
+	done   chan struct{}
+
+	mu       sync.Mutex
+	closed   bool
+	dropped  int
+	failed   int // batches the collector didn't accept
+	batches  int
+	lastErr  error
+	closeErr error
+}
+
+// sink sends a batch of events to a collector.
+type sink interface {
+	send(ctx context.Context, labels map[string]string, events []Event) error
+	close() error
+}
+
+// New creates a Shipper from params and starts its background sender. returns nil, nil if shipping
+// is not configured, the nil Shipper is safe to use.
+func New(p Params) (*Shipper, error) {
+	if p.Destination == "" {
+		return nil, nil //nolint:nilnil // nil shipper means "not configured", all methods are nil-safe
+	}
+	snk, err := newSink(p.Destination)
+	if err != nil {
+		return nil, err
+	}
+	s := &Shipper{
+		sink:          snk,
+		labels:        p.Labels,
+		batchSize:     p.BatchSize,
+		flushInterval: p.FlushInterval,
+		timeout:       p.Timeout,
+		done:          make(chan struct{}),
+	}
+	if s.batchSize <= 0 {
+		s.batchSize = DefaultBatchSize
+	}
+	if s.flushInterval <= 0 {
+		s.flushInterval = DefaultFlushInterval
+	}
+	if s.timeout <= 0 {
+		s.timeout = DefaultTimeout
+	}
+	s.events = make(chan Event, s.batchSize*bufferBatches)
+	go s.loop()
+	return s, nil
+}
+
+// newSink picks the collector for a destination URL.
+func newSink(destination string) (sink, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log shipping destination %q: %w", destination, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid log shipping destination %q: host is missing", destination)
+	}
+	switch u.Scheme {
+	case "syslog", "syslog+udp":
+		return &syslogSink{network: "udp", addr: u.Host}, nil
+	case "syslog+tcp":
+		return &syslogSink{network: "tcp", addr: u.Host}, nil
+	case "loki", "loki+http", "loki+https":
+		scheme := "http"
+		if u.Scheme == "loki+https" {
+			scheme = "https"
+		}
+		path := strings.TrimSuffix(u.Path, "/")
+		if path == "" {
+			path = "/loki/api/v1/push"
+		}
+		return &lokiSink{url: scheme + "://" + u.Host + path}, nil
+	case "http", "https":
+		return &httpSink{url: destination}, nil
+	default:
+		return nil, fmt.Errorf("invalid log shipping destination %q: unsupported scheme, use syslog://, loki:// or http(s)://",
+			destination)
+	}
+}
+
+// Ship queues an event. never blocks, the event is dropped if the buffer is full. nil-safe.
+func (s *Shipper) Ship(e Event) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.events <- e:
+	default:
+		s.dropped++
+	}
+}
+
+// Close sends the queued events and releases the collector connection. returns an error describing
+// dropped events and failed batches, if any. nil-safe, later calls return the same result.
+func (s *Shipper) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	if s.closed {
+		defer s.mu.Unlock()
+		return s.closeErr
+	}
+	s.closed = true
+	close(s.events)
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+	case <-time.After(closeTimeout):
+		return errors.New("log shipping: timed out sending remaining events")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	if s.failed > 0 {
+		errs = append(errs, fmt.Errorf("%d of %d batches not accepted, last error: %w", s.failed, s.batches, s.lastErr))
+	}
+	if s.dropped > 0 {
+		errs = append(errs, fmt.Errorf("%d events dropped, collector too slow", s.dropped))
+	}
+	if err := s.sink.close(); err != nil {
+		errs = append(errs, fmt.Errorf("close collector connection: %w", err))
+	}
+	if err := errors.Join(errs...); err != nil {
+		s.closeErr = fmt.Errorf("log shipping: %w", err)
+	}
+	return s.closeErr
+}
+
+// loop collects events into batches, sent when full, after the flush interval and on close.
+func (s *Shipper) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, s.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		s.send(batch)
+		batch = make([]Event, 0, s.batchSize)
+	}
+	for {
+		select {
+		case e, ok := <-s.events:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= s.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// send delivers a batch, recording failures for Close.
+func (s *Shipper) send(batch []Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	err := s.sink.send(ctx, s.labels, batch)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches++
+	if err != nil {
+		s.failed++
+		s.lastErr = err
+	}
+}
+
+// syslogSink sends each event as an RFC 5424 message, one datagram per event over udp,
+// octet-counted framing (RFC 6587) over tcp. labels and the event kind and phase go into structured data.
+type syslogSink struct {
+	network, addr string
+	conn          net.Conn
+}
+
+// syslogPriority is facility user (1) with severity informational (6).
+const syslogPriority = 1*8 + 6
+
+// syslogSDID is the structured data id. RFC 5424 requires "name@enterprise number" for private ids,
+// ralphex has no registered number, so the one reserved for examples is used.
+const syslogSDID = "ralphex@32473"
+
+func (k *syslogSink) send(ctx context.Context, labels map[string]string, events []Event) error {
+	if k.conn == nil {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, k.network, k.addr)
+		if err != nil {
+			return fmt.Errorf("connect to syslog: %w", err)
+		}
+		k.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = k.conn.SetWriteDeadline(deadline)
+	}
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "-"
+	}
+	for _, e := range events {
+		msg := formatSyslog(host, labels, e)
+		if k.network == "tcp" {
+			msg = strconv.Itoa(len(msg)) + " " + msg
+		}
+		if _, err := k.conn.Write([]byte(msg)); err != nil {
+			// reconnect with the next batch, a tcp collector may have restarted
+			_ = k.conn.Close()
+			k.conn = nil
+			return fmt.Errorf("write to syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+func (k *syslogSink) close() error {
+	if k.conn == nil {
+		return nil
+	}
+	if err := k.conn.Close(); err != nil {
+		return fmt.Errorf("close syslog connection: %w", err)
+	}
+	return nil
+}
+
+// formatSyslog builds an RFC 5424 message for the event.
+func formatSyslog(host string, labels map[string]string, e Event) string {
+	params := map[string]string{"kind": e.Kind, "phase": e.Phase}
+	for k, v := range labels {
+		params[k] = v
+	}
+	var sd strings.Builder
+	sd.WriteString("[" + syslogSDID)
+	for _, k := range slices.Sorted(maps.Keys(params)) {
+		if params[k] == "" {
+			continue
+		}
+		sd.WriteString(" " + k + `="` + escapeSDValue(params[k]) + `"`)
+	}
+	sd.WriteString("]")
+	return fmt.Sprintf("<%d>1 %s %s ralphex %d - %s %s", syslogPriority, e.Time.UTC().Format(time.RFC3339Nano),
+		host, os.Getpid(), sd.String(), e.Text)
+}
+
+// escapeSDValue escapes characters RFC 5424 requires escaped in structured data values.
+func escapeSDValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(v)
+}
+
+// lokiSink pushes batches to the Loki push API as a single stream, labeled with job=ralphex and
+// the shipper labels. each line is the event as JSON, queryable with "| json".
+type lokiSink struct {
+	url string
+}
+
+func (k *lokiSink) send(ctx context.Context, labels map[string]string, events []Event) error {
+	stream := map[string]string{"job": "ralphex"}
+	for key, v := range labels {
+		if v != "" {
+			stream[key] = v
+		}
+	}
+	values := make([][2]string, 0, len(events))
+	for _, e := range events {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("marshal event: %w", err)
+		}
+		values = append(values, [2]string{strconv.FormatInt(e.Time.UnixNano(), 10), string(line)})
+	}
+	body := map[string]any{"streams": []any{map[string]any{"stream": stream, "values": values}}}
+	return postJSON(ctx, k.url, body)
+}
+
+func (k *lokiSink) close() error { return nil }
+
+// httpSink POSTs batches as {"labels": {...}, "events": [...]}.
+type httpSink struct {
+	url string
+}
+
+func (k *httpSink) send(ctx context.Context, labels map[string]string, events []Event) error {
+	return postJSON(ctx, k.url, map[string]any{"labels": labels, "events": events})
+}
+
+func (k *httpSink) close() error { return nil }
+
+// postJSON sends body as JSON, any non-2xx response is an error.
+func postJSON(ctx context.Context, endpoint string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal batch: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send batch: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("send batch: unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package logship
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		wantSink    sink
+		wantErr     string
+	}{
+		{name: "not configured", destination: ""},
+		{name: "syslog udp", destination: "syslog://logs.local:514", wantSink: &syslogSink{network: "udp", addr: "logs.local:514"}},
+		{name: "syslog tcp", destination: "syslog+tcp://logs.local:601", wantSink: &syslogSink{network: "tcp", addr: "logs.local:601"}},
+		{name: "loki default path", destination: "loki://loki:3100", wantSink: &lokiSink{url: "http://loki:3100/loki/api/v1/push"}},
+		{name: "loki https custom path", destination: "loki+https://grafana.example.com/api/prom/push",
+			wantSink: &lokiSink{url: "https://grafana.example.com/api/prom/push"}},
+		{name: "http", destination: "https://logs.example.com/ingest?key=1", wantSink: &httpSink{url: "https://logs.example.com/ingest?key=1"}},
+		{name: "unsupported scheme", destination: "kafka://broker:9092", wantErr: "unsupported scheme"},
+		{name: "missing host", destination: "loki:///push", wantErr: "host is missing"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := New(Params{Destination: tc.destination})
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tc.wantSink == nil {
+				assert.Nil(t, s)
+				return
+			}
+			defer s.Close()
+			assert.Equal(t, tc.wantSink, s.sink)
+		})
+	}
+}
+
+func TestShipper_NilSafe(t *testing.T) {
+	var s *Shipper
+	s.Ship(Event{Text: "x"})
+	assert.NoError(t, s.Close())
+}
+
+func TestShipper_HTTPBatches(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]Event
+	var labels map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Labels map[string]string `json:"labels"`
+			Events []Event           `json:"events"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		batches, labels = append(batches, body.Events), body.Labels
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	s, err := New(Params{Destination: srv.URL, Labels: map[string]string{"plan": "feature.md"}, BatchSize: 2,
+		FlushInterval: time.Hour})
+	require.NoError(t, err)
+	for _, text := range []string{"one", "two", "three"} {
+		s.Ship(Event{Time: time.Now(), Kind: KindOutput, Phase: "task", Text: text})
+	}
+	require.NoError(t, s.Close())
+
+	// a full batch is sent right away, the rest on close
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, batches, 2)
+	assert.Len(t, batches[0], 2)
+	assert.Equal(t, "three", batches[1][0].Text)
+	assert.Equal(t, map[string]string{"plan": "feature.md"}, labels)
+
+	s.Ship(Event{Text: "after close"}) // ignored
+}
+
+func TestShipper_FlushInterval(t *testing.T) {
+	received := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		received <- string(data)
+	}))
+	defer srv.Close()
+
+	s, err := New(Params{Destination: srv.URL, FlushInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	defer s.Close()
+	s.Ship(Event{Kind: KindOutput, Text: "partial batch"})
+	select {
+	case body := <-received:
+		assert.Contains(t, body, "partial batch")
+	case <-time.After(5 * time.Second):
+		t.Fatal("partial batch not flushed")
+	}
+}
+
+func TestShipper_Loki(t *testing.T) {
+	var body struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	s, err := New(Params{Destination: "loki://" + strings.TrimPrefix(srv.URL, "http://"),
+		Labels: map[string]string{"mode": "full", "branch": ""}})
+	require.NoError(t, err)
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	s.Ship(Event{Time: ts, Kind: KindSection, Phase: "review", Text: "claude review 0"})
+	require.NoError(t, s.Close())
+
+	assert.Equal(t, "/loki/api/v1/push", path)
+	require.Len(t, body.Streams, 1)
+	assert.Equal(t, map[string]string{"job": "ralphex", "mode": "full"}, body.Streams[0].Stream, "empty labels dropped")
+	require.Len(t, body.Streams[0].Values, 1)
+	assert.Equal(t, "1767323045000000006", body.Streams[0].Values[0][0])
+	var e Event
+	require.NoError(t, json.Unmarshal([]byte(body.Streams[0].Values[0][1]), &e))
+	assert.Equal(t, "claude review 0", e.Text)
+	assert.Equal(t, "review", e.Phase)
+}
+
+func TestShipper_Failures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	s, err := New(Params{Destination: srv.URL, BatchSize: 1})
+	require.NoError(t, err)
+	s.Ship(Event{Text: "a"})
+	s.Ship(Event{Text: "b"})
+	err = s.Close()
+	require.ErrorContains(t, err, "log shipping: 2 of 2 batches not accepted")
+	assert.ErrorContains(t, err, "503")
+	assert.Equal(t, err, s.Close(), "repeated close returns the same result")
+}
+
+func TestShipper_DropsWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+
+	s, err := New(Params{Destination: srv.URL, BatchSize: 1, FlushInterval: time.Hour})
+	require.NoError(t, err)
+	// the first batch blocks in the collector, the buffer holds bufferBatches more
+	for range 2 + bufferBatches + 5 {
+		s.Ship(Event{Text: "x"})
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	require.ErrorContains(t, s.Close(), "events dropped, collector too slow")
+}
+
+func TestShipper_SyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s, err := New(Params{Destination: "syslog://" + conn.LocalAddr().String(), Labels: map[string]string{"plan": `a"b].md`}})
+	require.NoError(t, err)
+	s.Ship(Event{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Kind: KindOutput, Phase: "task", Text: "hello"})
+	require.NoError(t, s.Close())
+
+	buf := make([]byte, 2048)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	msg := string(buf[:n])
+	assert.True(t, strings.HasPrefix(msg, "<14>1 2026-01-02T03:04:05Z "), msg)
+	assert.Contains(t, msg, ` ralphex `)
+	assert.Contains(t, msg, `[ralphex@32473 kind="output" phase="task" plan="a\"b\].md"] hello`)
+}
+
+func TestShipper_SyslogTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	received := make(chan string, 1)
+	go func() {
+		c, acceptErr := ln.Accept()
+		if acceptErr != nil {
+			return
+		}
+		defer c.Close()
+		r := bufio.NewReader(c)
+		size, _ := r.ReadString(' ')
+		data, _ := io.ReadAll(r)
+		received <- size + string(data)
+	}()
+
+	s, err := New(Params{Destination: "syslog+tcp://" + ln.Addr().String()})
+	require.NoError(t, err)
+	s.Ship(Event{Time: time.Now(), Kind: KindSection, Phase: "codex", Text: "codex external review"})
+	require.NoError(t, s.Close())
+
+	select {
+	case got := <-received:
+		size, msg, ok := strings.Cut(got, " ")
+		require.True(t, ok)
+		assert.Equal(t, size, strconv.Itoa(len(msg)), "octet-counted framing")
+		assert.True(t, strings.HasSuffix(msg, "codex external review"), msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no syslog message received")
+	}
+}
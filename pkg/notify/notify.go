@@ -34,12 +34,25 @@ type Params struct {
 	EmailTo       []string
 	WebhookURLs   []string
 	CustomScript  string
+	Plugins       map[string]Sender // plugin notifiers by name, for "plugin:<name>" channels
+}
+
+// Sender sends a result through a channel provided from outside the package, e.g. a plugin.
+type Sender interface {
+	Send(ctx context.Context, r Result) error
+}
+
+// namedSender is a Sender with the channel name used in warnings
+type namedSender struct {
+	name   string
+	sender Sender
 }
 
 // Service orchestrates sending notifications through configured channels.
 type Service struct {
 	channels   []channel      // paired notifier + destination
 	custom     *customChannel // optional custom script channel
+	senders    []namedSender  // plugin channels
 	onError    bool
 	onComplete bool
 	timeoutMs  int
@@ -61,15 +74,16 @@ type logger interface {
 
 // Result holds completion data for notifications.
 type Result struct {
-	Status    string `json:"status"` // "success" or "failure"
-	Mode      string `json:"mode"`
-	PlanFile  string `json:"plan_file"`
-	Branch    string `json:"branch"`
-	Duration  string `json:"duration"`
-	Files     int    `json:"files"`
-	Additions int    `json:"additions"`
-	Deletions int    `json:"deletions"`
-	Error     string `json:"error,omitempty"`
+	Status    string   `json:"status"` // "success", "failure" or "needs_human" for a paused run waiting for a fix
+	Mode      string   `json:"mode"`
+	PlanFile  string   `json:"plan_file"`
+	Branch    string   `json:"branch"`
+	Duration  string   `json:"duration"`
+	Files     int      `json:"files"`
+	Additions int      `json:"additions"`
+	Deletions int      `json:"deletions"`
+	Error     string   `json:"error,omitempty"`
+	Artifacts []string `json:"artifacts,omitempty"` // links to published run artifacts
 }
 
 // New creates a notification Service from the given Params.
@@ -97,60 +111,89 @@ func New(p Params, log logger) (*Service, error) {
 	}
 
 	for _, ch := range p.Channels {
-		switch strings.TrimSpace(strings.ToLower(ch)) {
-		case "telegram":
-			if p.TelegramToken == "" {
-				return nil, errors.New("telegram channel: notify_telegram_token is required")
-			}
-			if p.TelegramChat == "" {
-				return nil, errors.New("telegram channel: notify_telegram_chat is required")
-			}
-			c, cErr := telegramChannelMaker(p)
-			if cErr != nil {
-				// telegram init makes a live API call to verify the bot token;
-				// if the network/API is unavailable, skip the channel instead of blocking
-				// startup — notifications are best-effort.
-				// redact the token from the error to avoid leaking it in logs
-				errMsg := strings.ReplaceAll(cErr.Error(), p.TelegramToken, "[REDACTED]")
-				log.Print("[WARN] telegram channel disabled: %s", errMsg)
-				continue
-			}
-			svc.channels = append(svc.channels, c)
-		case "email":
-			c, cErr := makeEmailChannel(p)
-			if cErr != nil {
-				return nil, fmt.Errorf("email channel: %w", cErr)
-			}
-			svc.channels = append(svc.channels, c)
-		case "slack":
-			c, cErr := makeSlackChannel(p)
-			if cErr != nil {
-				return nil, fmt.Errorf("slack channel: %w", cErr)
-			}
-			svc.channels = append(svc.channels, c)
-		case "webhook":
-			chs, cErr := makeWebhookChannels(p)
-			if cErr != nil {
-				return nil, fmt.Errorf("webhook channel: %w", cErr)
-			}
-			svc.channels = append(svc.channels, chs...)
-		case "custom":
-			if p.CustomScript == "" {
-				return nil, errors.New("custom channel: notify_custom_script is required")
-			}
-			svc.custom = newCustomChannel(p.CustomScript)
-		default:
-			return nil, fmt.Errorf("unknown notification channel: %q", ch)
+		if err := svc.addChannel(p, ch); err != nil {
+			return nil, err
 		}
 	}
 
-	if len(svc.channels) == 0 && svc.custom == nil {
+	if len(svc.channels) == 0 && svc.custom == nil && len(svc.senders) == 0 {
 		log.Print("[WARN] all notification channels were disabled due to initialization errors")
 	}
 
 	return svc, nil
 }
 
+// addChannel sets up the named channel from p.
+func (s *Service) addChannel(p Params, ch string) error {
+	name := strings.TrimSpace(strings.ToLower(ch))
+	switch name {
+	case "telegram":
+		return s.addTelegramChannel(p)
+	case "email":
+		c, err := makeEmailChannel(p)
+		if err != nil {
+			return fmt.Errorf("email channel: %w", err)
+		}
+		s.channels = append(s.channels, c)
+	case "slack":
+		c, err := makeSlackChannel(p)
+		if err != nil {
+			return fmt.Errorf("slack channel: %w", err)
+		}
+		s.channels = append(s.channels, c)
+	case "webhook":
+		chs, err := makeWebhookChannels(p)
+		if err != nil {
+			return fmt.Errorf("webhook channel: %w", err)
+		}
+		s.channels = append(s.channels, chs...)
+	case "custom":
+		if p.CustomScript == "" {
+			return errors.New("custom channel: notify_custom_script is required")
+		}
+		s.custom = newCustomChannel(p.CustomScript)
+	default:
+		plugin, ok := strings.CutPrefix(name, "plugin:")
+		if !ok {
+			return fmt.Errorf("unknown notification channel: %q", ch)
+		}
+		return s.addPluginChannel(p, plugin)
+	}
+	return nil
+}
+
+// addTelegramChannel sets up the telegram channel. a failed bot init disables the channel with a warning.
+func (s *Service) addTelegramChannel(p Params) error {
+	if p.TelegramToken == "" {
+		return errors.New("telegram channel: notify_telegram_token is required")
+	}
+	if p.TelegramChat == "" {
+		return errors.New("telegram channel: notify_telegram_chat is required")
+	}
+	c, err := telegramChannelMaker(p)
+	if err != nil {
+		// telegram init makes a live API call to verify the bot token;
+		// if the network/API is unavailable, skip the channel instead of blocking
+		// startup — notifications are best-effort.
+		// redact the token from the error to avoid leaking it in logs
+		errMsg := strings.ReplaceAll(err.Error(), p.TelegramToken, "[REDACTED]")
+		s.log.Print("[WARN] telegram channel disabled: %s", errMsg)
+		return nil
+	}
+	s.channels = append(s.channels, c)
+	return nil
+}
+
+// addPluginChannel sets up the channel of the named plugin notifier.
+func (s *Service) addPluginChannel(p Params, name string) error {
+	sender, found := p.Plugins[name]
+	if !found {
+		return fmt.Errorf("plugin channel: plugin %q not configured or provides no notifier", name)
+	}
+	s.senders = append(s.senders, namedSender{name: "plugin:" + name, sender: sender})
+	return nil
+}
+
 // Send sends a notification for the given result. nil-safe on receiver — callers don't need nil checks.
 // checks onError/onComplete flags and sends to all configured channels.
 // errors are logged but never returned (best-effort).
@@ -163,7 +206,7 @@ func (s *Service) Send(ctx context.Context, r Result) {
 	if r.Status == "success" && !s.onComplete {
 		return
 	}
-	if r.Status == "failure" && !s.onError {
+	if (r.Status == "failure" || r.Status == "needs_human") && !s.onError {
 		return
 	}
 
@@ -190,15 +233,25 @@ func (s *Service) Send(ctx context.Context, r Result) {
 			s.log.Print("[WARN] custom notification failed: %v", err)
 		}
 	}
+
+	// send to plugin channels
+	for _, ns := range s.senders {
+		if err := ns.sender.Send(sendCtx, r); err != nil {
+			s.log.Print("[WARN] %s notification failed: %v", ns.name, err)
+		}
+	}
 }
 
 // formatMessage creates a plain text notification message from the result.
 func (s *Service) formatMessage(r Result) string {
 	var b strings.Builder
 
-	if r.Status == "success" {
+	switch r.Status {
+	case "success":
 		fmt.Fprintf(&b, "ralphex completed on %s\n", s.hostname)
-	} else {
+	case "needs_human":
+		fmt.Fprintf(&b, "ralphex paused on %s, needs attention\n", s.hostname)
+	default:
 		fmt.Fprintf(&b, "ralphex failed on %s\n", s.hostname)
 	}
 
@@ -225,6 +278,10 @@ func (s *Service) formatMessage(r Result) string {
 		fmt.Fprintf(&b, "error:    %s\n", r.Error)
 	}
 
+	for _, link := range r.Artifacts {
+		fmt.Fprintf(&b, "artifact: %s\n", link)
+	}
+
 	return b.String()
 }
 
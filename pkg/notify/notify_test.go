@@ -320,6 +320,18 @@ func TestService_Send(t *testing.T) {
 		assert.Contains(t, calls[0].text, "ralphex failed on test-host")
 	})
 
+	t.Run("needs human follows onError", func(t *testing.T) {
+		mock := &mockNotifier{schema: "http"}
+		svc := &Service{channels: []channel{{notifier: mock, dest: "https://example.com/hook"}}, onError: true,
+			timeoutMs: 5000, hostname: "test-host", log: &mockLogger{}}
+		svc.Send(context.Background(), Result{Status: "needs_human", Error: "disk guard: 10 MB free"})
+		require.Len(t, mock.getCalls(), 1)
+
+		svc.onError = false
+		svc.Send(context.Background(), Result{Status: "needs_human", Error: "disk guard: 10 MB free"})
+		assert.Len(t, mock.getCalls(), 1)
+	})
+
 	t.Run("failure skipped when onError is false", func(t *testing.T) {
 		mock := &mockNotifier{schema: "http"}
 		log := &mockLogger{}
@@ -405,6 +417,32 @@ func TestService_Send(t *testing.T) {
 	})
 }
 
+// senderFunc adapts a function to Sender
+type senderFunc func(ctx context.Context, r Result) error
+
+func (f senderFunc) Send(ctx context.Context, r Result) error { return f(ctx, r) }
+
+func TestService_PluginChannel(t *testing.T) {
+	_, err := New(Params{Channels: []string{"plugin:pager"}}, &mockLogger{})
+	require.EqualError(t, err, `plugin channel: plugin "pager" not configured or provides no notifier`)
+
+	var got []Result
+	log := &mockLogger{}
+	svc, err := New(Params{
+		Channels:   []string{"plugin:pager", "Plugin:Broken"},
+		OnComplete: true,
+		Plugins: map[string]Sender{
+			"pager":  senderFunc(func(_ context.Context, r Result) error { got = append(got, r); return nil }),
+			"broken": senderFunc(func(context.Context, Result) error { return errors.New("pager api down") }),
+		},
+	}, log)
+	require.NoError(t, err)
+
+	svc.Send(context.Background(), Result{Status: "success", PlanFile: "plan.md"})
+	assert.Equal(t, []Result{{Status: "success", PlanFile: "plan.md"}}, got)
+	assert.Equal(t, []string{"[WARN] plugin:broken notification failed: pager api down"}, log.getMsgs())
+}
+
 func TestService_FormatMessage(t *testing.T) {
 	svc := &Service{hostname: "build-server"}
 
@@ -442,6 +480,13 @@ func TestService_FormatMessage(t *testing.T) {
 		assert.NotContains(t, msg, "changes:")
 	})
 
+	t.Run("needs human message", func(t *testing.T) {
+		msg := svc.formatMessage(Result{Status: "needs_human", Mode: "full", Error: "disk guard: 10 MB free"})
+		assert.Contains(t, msg, "ralphex paused on build-server, needs attention")
+		assert.Contains(t, msg, "error:    disk guard: 10 MB free")
+		assert.NotContains(t, msg, "changes:")
+	})
+
 	t.Run("missing optional fields", func(t *testing.T) {
 		msg := svc.formatMessage(Result{Status: "success"})
 		assert.Contains(t, msg, "ralphex completed on build-server")
@@ -453,6 +498,12 @@ func TestService_FormatMessage(t *testing.T) {
 		assert.Contains(t, msg, "changes:  0 files (+0/-0 lines)")
 	})
 
+	t.Run("artifact links", func(t *testing.T) {
+		msg := svc.formatMessage(Result{Status: "success", Artifacts: []string{"https://a.example.com/1", "https://a.example.com/2"}})
+		assert.Contains(t, msg, "artifact: https://a.example.com/1\n")
+		assert.Contains(t, msg, "artifact: https://a.example.com/2\n")
+	})
+
 	t.Run("message line count", func(t *testing.T) {
 		msg := svc.formatMessage(Result{
 			Status:    "success",
@@ -0,0 +1,23 @@
+package plan
+
+import (
+	"regexp"
+	"strings"
+)
+
+// allowedDepsRe matches an "Allowed dependencies: a, b" line of a plan, optionally a list item or in bold
+var allowedDepsRe = regexp.MustCompile(`(?im)^[\s>*-]*\**allowed dependencies\**\s*:\**\s*(.*)$`)
+
+// AllowedDependencies returns the modules a plan allows to add as dependencies, listed on
+// "Allowed dependencies:" lines separated by commas or spaces. "*" allows any.
+func AllowedDependencies(content string) []string {
+	var res []string
+	for _, m := range allowedDepsRe.FindAllStringSubmatch(content, -1) {
+		for dep := range strings.FieldsFuncSeq(m[1], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			if dep = strings.Trim(dep, "`"); dep != "" {
+				res = append(res, dep)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,29 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedDependencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "none", content: "# Plan\n- [ ] add cache\n", want: nil},
+		{name: "comma separated", content: "# Plan\nAllowed dependencies: github.com/a/b, golang.org/x/sync\n",
+			want: []string{"github.com/a/b", "golang.org/x/sync"}},
+		{name: "list item in bold with backticks", content: "## Notes\n- **Allowed dependencies:** `github.com/a/b`\n",
+			want: []string{"github.com/a/b"}},
+		{name: "several lines", content: "allowed dependencies: a\ntext\nAllowed Dependencies: b c\n", want: []string{"a", "b", "c"}},
+		{name: "any", content: "Allowed dependencies: *\n", want: []string{"*"}},
+		{name: "mentioned in text", content: "No allowed dependencies: keep it lean\n", want: nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, AllowedDependencies(tc.content))
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// lint severities
+const (
+	SeverityError   = "error"   // the plan can't be executed as written
+	SeverityWarning = "warning" // the plan runs, but likely with wasted iterations
+)
+
+// maxTaskItems is the item count above which a task is reported as too big.
+// the plan prompt aims for 3-7 items per task.
+const maxTaskItems = 10
+
+// minItemWords is the word count below which an item is reported as too vague
+const minItemWords = 3
+
+// Issue is a single problem found by Lint.
+type Issue struct {
+	Line       int    // 1-based line in the plan, 0 for plan-wide issues
+	Severity   string // SeverityError or SeverityWarning
+	Task       string // task header without "### ", empty for plan-wide issues
+	Message    string
+	Suggestion string // what to change to fix the issue
+}
+
+// String formats the issue as "line N: severity: task: message (suggestion)".
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", i.Line)
+	}
+	b.WriteString(i.Severity + ": ")
+	if i.Task != "" {
+		b.WriteString(i.Task + ": ")
+	}
+	b.WriteString(i.Message)
+	if i.Suggestion != "" {
+		b.WriteString(" (" + i.Suggestion + ")")
+	}
+	return b.String()
+}
+
+var (
+	// ambiguousRe matches wording that leaves the agent guessing what done means
+	ambiguousRe = regexp.MustCompile(`(?i)\b(etc|various|somehow|as needed|if needed|and so on|tbd|maybe|probably|appropriately?|properly|improve|clean up|misc)\b`)
+	// verificationRe matches items that verify the task's changes
+	verificationRe = regexp.MustCompile(`(?i)\b(tests?|testing|verify|verification|lint|linter|build|benchmarks?)\b`)
+	// docsTaskRe matches documentation-only tasks, which don't need a verification step
+	docsTaskRe = regexp.MustCompile(`(?i)\b(docs?|documentation|readme)\b`)
+)
+
+// Lint runs static quality checks over plan content: missing or empty tasks, tasks too big to
+// finish in an iteration, vague or ambiguous items and tasks without a verification step.
+// issues are returned in plan order.
+func Lint(content string) []Issue {
+	tasks := ParseTasks(content)
+	if len(tasks) == 0 {
+		return []Issue{{Severity: SeverityError, Message: "no tasks found",
+			Suggestion: `add "### Task N: <title>" sections with "- [ ]" items`}}
+	}
+	var res []Issue
+	for _, t := range tasks {
+		res = append(res, lintTaskIssues(t)...)
+	}
+	return res
+}
+
+// lintTaskIssues returns issues of a single task
+func lintTaskIssues(t Task) []Issue {
+	var res []Issue
+	add := func(line int, severity, msg, suggestion string) {
+		res = append(res, Issue{Line: line, Severity: severity, Task: t.Header, Message: msg, Suggestion: suggestion})
+	}
+
+	if _, title, _ := strings.Cut(t.Header, ":"); t.Explicit && strings.TrimSpace(title) == "" {
+		add(t.Line, SeverityWarning, "task has no title", "describe the task's goal in the header")
+	}
+	if len(t.Items) == 0 {
+		add(t.Line, SeverityError, "task has no checkbox items", `list the steps as "- [ ]" items`)
+		return res
+	}
+	if len(t.Items) > maxTaskItems {
+		add(t.Line, SeverityWarning, fmt.Sprintf("task is too big: %d items", len(t.Items)),
+			fmt.Sprintf("split it into smaller tasks of up to %d items", maxTaskItems))
+	}
+
+	verified := false
+	for _, it := range t.Items {
+		isVerification := verificationRe.MatchString(it.Text)
+		verified = verified || isVerification
+		// short items are fine if they verify or point at a concrete file or symbol
+		if len(strings.Fields(it.Text)) < minItemWords && !isVerification && !strings.ContainsAny(it.Text, "`/") {
+			add(it.Line, SeverityWarning, fmt.Sprintf("item %q is too vague", it.Text), "describe what to change and where")
+			continue
+		}
+		if m := ambiguousRe.FindString(it.Text); m != "" {
+			add(it.Line, SeverityWarning, fmt.Sprintf("item %q uses ambiguous wording %q", it.Text, m),
+				"state the concrete change and when it is done")
+		}
+	}
+	if !verified && !docsTaskRe.MatchString(t.Header) {
+		add(t.Line, SeverityWarning, "task has no verification step", "add an item to write or run tests")
+	}
+	return res
+}
@@ -0,0 +1,76 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "clean plan", content: `# Plan
+
+## Overview
+some context
+
+### Task 1: add config option
+- [ ] add field to pkg/config/values.go
+- [ ] write tests for the new field
+- [x] run project test suite
+
+### Task 2: update docs
+- [ ] update README.md options table
+`},
+		{name: "no tasks", content: "# Plan\n\n## Overview\n- [ ] not in a task\n",
+			want: []string{`error: no tasks found (add "### Task N: <title>" sections with "- [ ]" items)`}},
+		{name: "numbered headers and context sections", content: `# Plan
+
+### Context
+notes only
+
+### 1. Add parser
+- [ ] add ` + "`Parse`" + ` to pkg/x/parse.go
+- [ ] write tests for Parse
+`},
+		{name: "task without items and title", content: "### Task 1:\nsome prose\n\n### Task 2: b\n- [ ] run the test suite\n",
+			want: []string{
+				"line 1: warning: Task 1:: task has no title (describe the task's goal in the header)",
+				`line 1: error: Task 1:: task has no checkbox items (list the steps as "- [ ]" items)`,
+			}},
+		{name: "vague, ambiguous and unverified", content: `### Task 1: refactor
+- [ ] fix stuff
+- [ ] clean up the handlers as needed
+- [ ] run linter
+- [ ] update ` + "`x.go`" + `
+
+### Task 2: implement cache
+- [ ] add cache layer to the service
+`,
+			want: []string{
+				`line 2: warning: Task 1: refactor: item "fix stuff" is too vague (describe what to change and where)`,
+				`line 3: warning: Task 1: refactor: item "clean up the handlers as needed" uses ambiguous wording "clean up" (state the concrete change and when it is done)`,
+				"line 7: warning: Task 2: implement cache: task has no verification step (add an item to write or run tests)",
+			}},
+		{name: "too big", content: "### Task 1: big\n" + strings.Repeat("- [ ] write tests for another case\n", 11),
+			want: []string{"line 1: warning: Task 1: big: task is too big: 11 items (split it into smaller tasks of up to 10 items)"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := Lint(tc.content)
+			got := make([]string, 0, len(issues))
+			for _, issue := range issues {
+				got = append(got, issue.String())
+			}
+			if len(tc.want) == 0 {
+				assert.Empty(t, got)
+				return
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
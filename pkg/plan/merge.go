@@ -0,0 +1,69 @@
+package plan
+
+import (
+	"strings"
+)
+
+// MergeChecked returns the edited plan content with the checkbox items checked in the previous content checked
+// again, matched by item text, and the texts of the items it checked. a person editing the plan from a copy older
+// than the agent's last update doesn't undo the progress of the agent this way.
+func MergeChecked(prev, edited string) (merged string, rechecked []string) {
+	checked := make(map[string]bool)
+	for line := range strings.SplitSeq(prev, "\n") {
+		if it, ok := parseItem(strings.TrimSpace(line)); ok && it.Checked {
+			checked[it.Text] = true
+		}
+	}
+	lines := strings.Split(edited, "\n")
+	for i, line := range lines {
+		it, ok := parseItem(strings.TrimSpace(line))
+		if !ok || it.Checked || !checked[it.Text] {
+			continue
+		}
+		lines[i] = strings.Replace(line, "- [ ]", "- [x]", 1)
+		rechecked = append(rechecked, it.Text)
+	}
+	return strings.Join(lines, "\n"), rechecked
+}
+
+// ChangedLines returns the non-blank lines of the edited plan content missing from the previous content and the
+// lines of the previous content missing from the edited one, trimmed and in plan order. a checkbox item differing
+// only in its checked state is not a change.
+func ChangedLines(prev, edited string) (added, removed []string) {
+	return missingLines(edited, prev), missingLines(prev, edited)
+}
+
+// missingLines returns the non-blank lines of content not in other, each line of other matching one line of content
+func missingLines(content, other string) []string {
+	counts := make(map[string]int)
+	for line := range strings.SplitSeq(other, "\n") {
+		if key, ok := lineKey(line); ok {
+			counts[key]++
+		}
+	}
+	var res []string
+	for line := range strings.SplitSeq(content, "\n") {
+		key, ok := lineKey(line)
+		if !ok {
+			continue
+		}
+		if counts[key] > 0 {
+			counts[key]--
+			continue
+		}
+		res = append(res, strings.TrimSpace(line))
+	}
+	return res
+}
+
+// lineKey returns the line compared by ChangedLines, checkbox items without their checked state. false for a blank line.
+func lineKey(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", false
+	}
+	if it, ok := parseItem(line); ok {
+		return "- [ ] " + it.Text, true
+	}
+	return line, true
+}
@@ -0,0 +1,34 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeChecked(t *testing.T) {
+	prev := "# Plan\n\n### Task 1: docs\n- [x] readme\n- [x] changelog\n- [ ] faq\n"
+	edited := "# Plan\n\nUse the wording of the website.\n\n### Task 1: docs\n- [ ] readme\n  - [ ] changelog\n- [ ] faq\n- [ ] llms.txt\n"
+
+	merged, rechecked := MergeChecked(prev, edited)
+	assert.Equal(t, "# Plan\n\nUse the wording of the website.\n\n### Task 1: docs\n- [x] readme\n  - [x] changelog\n"+
+		"- [ ] faq\n- [ ] llms.txt\n", merged)
+	assert.Equal(t, []string{"readme", "changelog"}, rechecked)
+
+	merged, rechecked = MergeChecked(prev, prev)
+	assert.Equal(t, prev, merged)
+	assert.Empty(t, rechecked)
+}
+
+func TestChangedLines(t *testing.T) {
+	prev := "# Plan\n\n### Task 1: docs\n- [x] readme\n- [ ] faq\n- [ ] faq\n"
+	edited := "# Plan\n\nUse the wording of the website.\n\n### Task 1: docs\n- [ ] readme\n- [ ] faq\n\n### Task 2: site\n- [ ] landing page\n"
+
+	added, removed := ChangedLines(prev, edited)
+	assert.Equal(t, []string{"Use the wording of the website.", "### Task 2: site", "- [ ] landing page"}, added)
+	assert.Equal(t, []string{"- [ ] faq"}, removed, "one of the two faq items removed, the checked state is no change")
+
+	added, removed = ChangedLines(prev, prev)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
@@ -0,0 +1,66 @@
+package plan
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// RunHistoryHeader starts the section run outcomes are appended to.
+const RunHistoryHeader = "## Run History"
+
+// Outcome describes a finished run for the plan's run history.
+type Outcome struct {
+	RunID      string
+	Date       time.Time
+	Status     string // "success", "failure" or "stopped"
+	Mode       string
+	Duration   string
+	Iterations int    // task iterations, 0 if the mode has no task phase
+	Error      string // failure reason, empty on success
+}
+
+// AppendOutcome appends the run outcome to the run history section at the end of the plan file,
+// adding the section if the plan doesn't have it yet. tasks left with unchecked checkboxes are
+// listed as blocked.
+func AppendOutcome(planFile string, o Outcome) error {
+	data, err := os.ReadFile(planFile) //nolint:gosec // plan file selected by the user
+	if err != nil {
+		return fmt.Errorf("read plan: %w", err)
+	}
+	content := string(data)
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(content, "\n"))
+	if !strings.Contains(content, "\n"+RunHistoryHeader+"\n") && !strings.HasPrefix(content, RunHistoryHeader+"\n") {
+		b.WriteString("\n\n" + RunHistoryHeader)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "\n### Run %s\n\n", o.RunID)
+	fmt.Fprintf(&b, "- date: %s\n", o.Date.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- outcome: %s\n", o.Status)
+	if o.Mode != "" {
+		fmt.Fprintf(&b, "- mode: %s\n", o.Mode)
+	}
+	if o.Duration != "" {
+		fmt.Fprintf(&b, "- duration: %s\n", o.Duration)
+	}
+	if o.Iterations > 0 {
+		fmt.Fprintf(&b, "- iterations: %d\n", o.Iterations)
+	}
+	if o.Error != "" {
+		fmt.Fprintf(&b, "- error: %s\n", strings.Join(strings.Fields(o.Error), " "))
+	}
+	if blocked := IncompleteTasks(content); len(blocked) > 0 {
+		b.WriteString("- blocked tasks:\n")
+		for _, t := range blocked {
+			fmt.Fprintf(&b, "  - %s\n", t)
+		}
+	}
+
+	if err := os.WriteFile(planFile, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("write plan: %w", err)
+	}
+	return nil
+}
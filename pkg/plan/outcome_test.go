@@ -0,0 +1,184 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncompleteTasks(t *testing.T) {
+	content := `# Plan
+
+## Overview
+- [ ] not a task item
+
+### Task 1: done
+- [x] first
+- [x] second
+
+### Task 2: partially done
+- [x] first
+- [ ] second
+- [ ] third
+
+### Iteration 3: untouched
+- [ ] only
+
+## Notes
+- [ ] outside of tasks
+`
+	assert.Equal(t, []string{"Task 2: partially done", "Iteration 3: untouched"}, IncompleteTasks(content))
+	assert.Empty(t, IncompleteTasks("# Plan\n\n### Task 1: done\n- [x] item\n"))
+	assert.Equal(t, []string{"2. Wire config"}, IncompleteTasks("### 1. Add field\n- [x] a\n\n### 2. Wire config\n- [ ] b\n"))
+}
+
+func TestParseMilestones(t *testing.T) {
+	content := `# Plan
+
+### Task 0: setup before any section
+- [x] done
+
+## Overview
+context only
+
+## Milestone 1: storage
+### Task 1: schema
+- [x] first
+### Task 2: repository
+- [ ] second
+
+## Milestone 2: api
+### Task 3: handlers
+- [ ] third
+
+## Notes
+- [ ] outside of tasks
+`
+	ms := ParseMilestones(content)
+	require.Len(t, ms, 3)
+	assert.Empty(t, ms[0].Header)
+	assert.Equal(t, 0, ms[0].Line)
+	assert.True(t, ms[0].Done())
+	assert.Equal(t, "Milestone 1: storage", ms[1].Header)
+	assert.Equal(t, 9, ms[1].Line)
+	require.Len(t, ms[1].Tasks, 2)
+	assert.Equal(t, "Task 2: repository", ms[1].Tasks[1].Header)
+	assert.False(t, ms[1].Done())
+	assert.Equal(t, "Milestone 2: api", ms[2].Header)
+	require.Len(t, ms[2].Tasks, 1)
+
+	assert.Len(t, ParseMilestones("# Plan\n\n### Task 1: a\n- [ ] a\n### Task 2: b\n- [ ] b\n"), 1, "plan without sections")
+	assert.Empty(t, ParseMilestones("# Plan\n\n## Overview\ntext\n"))
+}
+
+func TestSelectTasks(t *testing.T) {
+	tasks := ParseTasks(`# Plan
+
+### Task 1: config field
+- [x] add field
+
+### Task 2: wire config
+- [ ] pass to runner
+
+### Task 4: docs
+- [ ] update README
+
+### cleanup
+- [ ] drop dead code
+`)
+	headers := func(ts []Task) []string {
+		var res []string
+		for _, t := range ts {
+			res = append(res, t.Header)
+		}
+		return res
+	}
+	tests := []struct {
+		name    string
+		start   string
+		only    []string
+		want    []string
+		wantErr string
+	}{
+		{name: "all", want: []string{"Task 1: config field", "Task 2: wire config", "Task 4: docs", "cleanup"}},
+		{name: "start number", start: "2", want: []string{"Task 2: wire config", "Task 4: docs", "cleanup"}},
+		{name: "start regex", start: "(?i)readme", want: []string{"Task 4: docs", "cleanup"}},
+		{name: "only numbers", only: []string{"4", "1"}, want: []string{"Task 1: config field", "Task 4: docs"}},
+		{name: "only regex", only: []string{"cleanup", "dead"}, want: []string{"cleanup"}},
+		{name: "start and only", start: "2", only: []string{"config"}, want: []string{"Task 2: wire config"}},
+		{name: "start not found", start: "7", wantErr: `no task matches "7"`},
+		{name: "only not found", start: "docs", only: []string{"field"}, wantErr: "no task matches field"},
+		{name: "bad regex", only: []string{"[a"}, wantErr: `bad task selector "[a"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := SelectTasks(tasks, tc.start, tc.only)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, headers(res))
+		})
+	}
+
+	res, err := SelectTasks(ParseTasks("### setup\n- [ ] a\n\n### api\n- [ ] b\n"), "2", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api"}, headers(res), "position in a plan without numbered headers")
+	res, err = SelectTasks(ParseTasks("### 1. setup\n- [ ] a\n\n### 2. api\n- [ ] b\n"), "", []string{"1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1. setup"}, headers(res))
+
+	require.NoError(t, ValidTaskSelector("3"))
+	require.NoError(t, ValidTaskSelector("wire.*config"))
+	require.ErrorContains(t, ValidTaskSelector("[a"), `bad task selector "[a"`)
+}
+
+func TestAppendOutcome(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n\n### Task 1: add x\n- [x] done\n- [ ] blocked\n\n"), 0o600))
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	err := AppendOutcome(planFile, Outcome{RunID: "20260102-030405-add-x", Date: date, Status: "failure", Mode: "full",
+		Duration: "5m", Iterations: 3, Error: "max iterations\nreached"})
+	require.NoError(t, err)
+	err = AppendOutcome(planFile, Outcome{RunID: "20260102-040000-add-x", Date: date.Add(time.Hour), Status: "success"})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(planFile) //nolint:gosec // test file
+	require.NoError(t, err)
+	want := `# Plan
+
+### Task 1: add x
+- [x] done
+- [ ] blocked
+
+## Run History
+
+### Run 20260102-030405-add-x
+
+- date: 2026-01-02 03:04:05
+- outcome: failure
+- mode: full
+- duration: 5m
+- iterations: 3
+- error: max iterations reached
+- blocked tasks:
+  - Task 1: add x
+
+### Run 20260102-040000-add-x
+
+- date: 2026-01-02 04:04:05
+- outcome: success
+- blocked tasks:
+  - Task 1: add x
+`
+	assert.Equal(t, want, string(data))
+
+	err = AppendOutcome(filepath.Join(t.TempDir(), "missing.md"), Outcome{})
+	require.ErrorContains(t, err, "read plan")
+}
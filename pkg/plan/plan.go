@@ -122,6 +122,30 @@ func (s *Selector) selectWithFzf(ctx context.Context) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// ListPlans returns the absolute paths of the plan files in dir, sorted by name.
+// subdirectories such as completed/ are not searched.
+func ListPlans(dir string) ([]string, error) {
+	plans, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, fmt.Errorf("list plans: %w", err)
+	}
+	res := make([]string, 0, len(plans))
+	for _, p := range plans {
+		if info, statErr := os.Stat(p); statErr != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		abs, absErr := filepath.Abs(p)
+		if absErr != nil {
+			return nil, fmt.Errorf("resolve plan path: %w", absErr)
+		}
+		res = append(res, abs)
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoPlansFound, dir)
+	}
+	return res, nil // glob results are sorted
+}
+
 // FindRecent finds the most recently modified plan file in the plans directory
 // that was modified after the given start time.
 func (s *Selector) FindRecent(startTime time.Time) string {
@@ -154,6 +154,23 @@ func TestSelector_FindRecent(t *testing.T) {
 	})
 }
 
+func TestListPlans(t *testing.T) {
+	dir := t.TempDir()
+	_, err := ListPlans(dir)
+	require.ErrorIs(t, err, ErrNoPlansFound)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "completed"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "completed", "done.md"), []byte("# Done"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dir.md"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b-second.md"), []byte("# B"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a-first.md"), []byte("# A"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0o600))
+
+	plans, err := ListPlans(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a-first.md"), filepath.Join(dir, "b-second.md")}, plans)
+}
+
 func TestExtractBranchName(t *testing.T) {
 	tests := []struct {
 		name     string
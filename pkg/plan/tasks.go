@@ -0,0 +1,240 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Task is a task section of a plan: a "### " header followed by checkbox items.
+type Task struct {
+	Header   string // header text without "### "
+	Line     int    // 1-based line of the header
+	Explicit bool   // "### Task N:" or "### Iteration N:" header
+	Items    []Item
+}
+
+// Item is a checkbox item of a task.
+type Item struct {
+	Text    string
+	Line    int // 1-based line in the plan
+	Checked bool
+}
+
+// taskHeaderRe matches task headers, same as the dashboard's plan parser
+var taskHeaderRe = regexp.MustCompile(`^###\s+(?:Task|Iteration)\s+\d+:\s*.*$`)
+
+// ParseTasks returns the tasks of plan content in plan order. any "### " section with checkbox items
+// is a task, plans also use "### 1. Title" style headers; sections without checkboxes are context or
+// notes, unless explicitly headed as tasks. checkboxes outside of "### " sections are ignored.
+func ParseTasks(content string) []Task {
+	var tasks []Task
+	current := -1
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if header, ok := strings.CutPrefix(trimmed, "### "); ok {
+			tasks = append(tasks, Task{Header: strings.TrimSpace(header), Line: i + 1, Explicit: taskHeaderRe.MatchString(trimmed)})
+			current = len(tasks) - 1
+			continue
+		}
+		if strings.HasPrefix(trimmed, "## ") {
+			current = -1
+			continue
+		}
+		if current < 0 {
+			continue
+		}
+		if item, ok := parseItem(trimmed); ok {
+			item.Line = i + 1
+			tasks[current].Items = append(tasks[current].Items, item)
+		}
+	}
+
+	res := tasks[:0]
+	for _, t := range tasks {
+		if t.Explicit || len(t.Items) > 0 {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// Done reports if all checkbox items of the task are checked.
+func (t Task) Done() bool {
+	for _, it := range t.Items {
+		if !it.Checked {
+			return false
+		}
+	}
+	return true
+}
+
+// Milestone is a "## " section of a plan holding tasks.
+type Milestone struct {
+	Header string // header text without "## ", empty for tasks before the first "## " header
+	Line   int    // 1-based line of the header, 0 without header
+	Tasks  []Task
+}
+
+// Done reports if all checkbox items of the milestone's tasks are checked.
+func (m Milestone) Done() bool {
+	for _, t := range m.Tasks {
+		if !t.Done() {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseMilestones returns the "## " sections of plan content holding tasks, in plan order.
+// sections without tasks, like an overview, are left out.
+func ParseMilestones(content string) []Milestone {
+	var res []Milestone
+	current := Milestone{}
+	tasks := ParseTasks(content)
+	flush := func() {
+		if len(current.Tasks) > 0 {
+			res = append(res, current)
+		}
+	}
+	for i, line := range strings.Split(content, "\n") {
+		header, ok := strings.CutPrefix(strings.TrimSpace(line), "## ")
+		for len(tasks) > 0 && tasks[0].Line < i+1 {
+			current.Tasks = append(current.Tasks, tasks[0])
+			tasks = tasks[1:]
+		}
+		if ok {
+			flush()
+			current = Milestone{Header: strings.TrimSpace(header), Line: i + 1}
+		}
+	}
+	current.Tasks = append(current.Tasks, tasks...)
+	flush()
+	return res
+}
+
+// IncompleteTasks returns headers of tasks that still have unchecked checkboxes.
+func IncompleteTasks(content string) []string {
+	var res []string
+	for _, t := range ParseTasks(content) {
+		for _, it := range t.Items {
+			if !it.Checked {
+				res = append(res, t.Header)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// parseItem parses a checkbox item line
+func parseItem(line string) (Item, bool) {
+	if text, ok := strings.CutPrefix(line, "- [ ]"); ok {
+		return Item{Text: strings.TrimSpace(text)}, true
+	}
+	for _, prefix := range []string{"- [x]", "- [X]"} {
+		if text, ok := strings.CutPrefix(line, prefix); ok {
+			return Item{Text: strings.TrimSpace(text), Checked: true}, true
+		}
+	}
+	return Item{}, false
+}
+
+// taskNumberRe extracts the number of "### Task N:", "### Iteration N:" and "### N. Title" headers
+var taskNumberRe = regexp.MustCompile(`^(?:(?:Task|Iteration)\s+(\d+):|(\d+)[.)]\s)`)
+
+// ValidTaskSelector returns an error if sel is neither a task number nor a valid regular expression, see SelectTasks.
+func ValidTaskSelector(sel string) error {
+	if _, err := strconv.Atoi(sel); err == nil {
+		return nil
+	}
+	if _, err := regexp.Compile(sel); err != nil {
+		return fmt.Errorf("bad task selector %q: %w", sel, err)
+	}
+	return nil
+}
+
+// SelectTasks returns the tasks from the first one matching start on, narrowed to those matching any of only.
+// a selector is a task number or a regular expression matched against the header and item text. the number
+// is the one of "### Task N:" or "### N. Title" headers, in plans without numbered headers the 1-based
+// position of the task. empty start and only select all tasks.
+func SelectTasks(tasks []Task, start string, only []string) ([]Task, error) {
+	numbers := taskNumbers(tasks)
+	first := 0
+	if start != "" {
+		first = -1
+		for i, t := range tasks {
+			ok, err := matchTask(t, numbers[i], start)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				first = i
+				break
+			}
+		}
+		if first < 0 {
+			return nil, fmt.Errorf("no task matches %q", start)
+		}
+	}
+	if len(only) == 0 {
+		return tasks[first:], nil
+	}
+	var res []Task
+	for i := first; i < len(tasks); i++ {
+		for _, sel := range only {
+			ok, err := matchTask(tasks[i], numbers[i], sel)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				res = append(res, tasks[i])
+				break
+			}
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no task matches %s", strings.Join(only, ", "))
+	}
+	return res, nil
+}
+
+// taskNumbers returns the numbers of tasks from their headers, 0 for unnumbered ones.
+// in a plan without numbered headers tasks are numbered by position.
+func taskNumbers(tasks []Task) []int {
+	res := make([]int, len(tasks))
+	numbered := false
+	for i, t := range tasks {
+		if m := taskNumberRe.FindStringSubmatch(t.Header); m != nil {
+			res[i], _ = strconv.Atoi(m[1] + m[2])
+			numbered = true
+		}
+	}
+	if !numbered {
+		for i := range res {
+			res[i] = i + 1
+		}
+	}
+	return res
+}
+
+// matchTask reports if task t with number num matches the selector sel.
+func matchTask(t Task, num int, sel string) (bool, error) {
+	if n, err := strconv.Atoi(sel); err == nil {
+		return num != 0 && num == n, nil
+	}
+	re, err := regexp.Compile(sel)
+	if err != nil {
+		return false, fmt.Errorf("bad task selector %q: %w", sel, err)
+	}
+	if re.MatchString(t.Header) {
+		return true, nil
+	}
+	for _, it := range t.Items {
+		if re.MatchString(it.Text) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
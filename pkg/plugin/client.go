@@ -0,0 +1,180 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/analyzer"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/notify"
+)
+
+// handshakeTimeout limits the wait for a started plugin to answer the handshake
+const handshakeTimeout = 10 * time.Second
+
+// stderrLimit is the tail of plugin stderr kept for error reports
+const stderrLimit = 4 * 1024
+
+// Client is a running plugin process. it is safe for concurrent use.
+type Client struct {
+	name   string
+	cmd    *exec.Cmd
+	rpc    *rpc.Client
+	kinds  []string
+	stderr *tailWriter
+	once   sync.Once
+}
+
+// Start starts the plugin command, an executable path with optional arguments, and checks it speaks
+// the plugin protocol. name identifies the plugin in errors.
+func Start(name, command string) (*Client, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("plugin %s: empty command", name)
+	}
+	cmd := exec.Command(fields[0], fields[1:]...) //nolint:gosec // plugin command from config
+	cmd.Env = append(os.Environ(), cookieKey+"="+cookieValue)
+	c := &Client{name: name, cmd: cmd, stderr: &tailWriter{limit: stderrLimit}}
+	cmd.Stderr = c.stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("plugin %s: start: %w", name, err)
+	}
+	c.rpc = jsonrpc.NewClient(clientConn{Reader: stdout, WriteCloser: stdin})
+
+	ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+	defer cancel()
+	var info InfoReply
+	if err := c.call(ctx, "Info", Empty{}, &info); err != nil {
+		c.Close() // waits for the process, its stderr is complete then
+		if tail := strings.TrimSpace(c.stderr.String()); tail != "" {
+			return nil, fmt.Errorf("handshake: %w, stderr: %s", err, tail)
+		}
+		return nil, fmt.Errorf("handshake: %w", err)
+	}
+	if info.Protocol != ProtocolVersion {
+		c.Close()
+		return nil, fmt.Errorf("plugin %s: protocol version %d, ralphex speaks %d", name, info.Protocol, ProtocolVersion)
+	}
+	c.kinds = info.Kinds
+	return c, nil
+}
+
+// Name returns the name of the plugin.
+func (c *Client) Name() string { return c.name }
+
+// Provides tells whether the plugin provides an implementation of kind, e.g. KindExecutor.
+func (c *Client) Provides(kind string) bool { return slices.Contains(c.kinds, kind) }
+
+// Run runs a prompt with the plugin executor, signals are detected in the output like for other executors.
+func (c *Client) Run(ctx context.Context, prompt string) executor.Result {
+	var reply ExecuteReply
+	if err := c.call(ctx, "Execute", ExecuteArgs{Prompt: prompt}, &reply); err != nil {
+		return executor.Result{Output: reply.Output, Error: err}
+	}
+	return executor.Result{Output: reply.Output, Signal: executor.DetectSignal(reply.Output)}
+}
+
+// Send passes a run result to the plugin notifier.
+func (c *Client) Send(ctx context.Context, r notify.Result) error {
+	return c.call(ctx, "Notify", NotifyArgs{Result: r}, &Empty{})
+}
+
+// FindingsProvider returns the plugin findings provider.
+func (c *Client) FindingsProvider() analyzer.FindingsProvider {
+	return findingsProvider{c: c}
+}
+
+// Close stops the plugin: closing its stdin ends Serve, a plugin still running after a grace period is killed.
+func (c *Client) Close() {
+	c.once.Do(func() {
+		_ = c.rpc.Close()
+		done := make(chan struct{})
+		go func() {
+			_ = c.cmd.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			_ = c.cmd.Process.Kill()
+			<-done
+		}
+	})
+}
+
+// call calls a plugin method. canceling ctx kills the plugin, a call can't be interrupted otherwise.
+func (c *Client) call(ctx context.Context, method string, args, reply any) error {
+	call := c.rpc.Go(serviceName+"."+method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+	case <-ctx.Done():
+		_ = c.cmd.Process.Kill()
+		return fmt.Errorf("plugin %s: %s: %w", c.name, strings.ToLower(method), ctx.Err())
+	}
+	if call.Error == nil {
+		return nil
+	}
+	return fmt.Errorf("plugin %s: %s: %w", c.name, strings.ToLower(method), call.Error)
+}
+
+// findingsProvider adapts the plugin findings provider to analyzer.FindingsProvider
+type findingsProvider struct {
+	c *Client
+}
+
+// Run runs the plugin findings provider over the diff.
+func (p findingsProvider) Run(ctx context.Context, diff string) ([]analyzer.Finding, error) {
+	var reply FindingsReply
+	if err := p.c.call(ctx, "Findings", FindingsArgs{Diff: diff}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Findings, nil
+}
+
+// clientConn joins the plugin stdout and stdin into a connection, closing stdin tells the plugin to exit
+type clientConn struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// tailWriter keeps the last limit bytes written to it
+type tailWriter struct {
+	mu    sync.Mutex
+	limit int
+	buf   []byte
+}
+
+// Write appends p, dropping the oldest bytes over the limit.
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	if over := len(w.buf) - w.limit; over > 0 {
+		w.buf = w.buf[over:]
+	}
+	return len(p), nil
+}
+
+// String returns the kept bytes.
+func (w *tailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.buf)
+}
@@ -0,0 +1,100 @@
+// Package plugin runs out-of-process plugins, so private integrations ship as separate binaries instead
+// of forks. a plugin is an executable started by ralphex, talking JSON-RPC (net/rpc/jsonrpc) over its
+// stdin and stdout, and provides any of: an executor usable as the external review tool, a notifier
+// usable as a notification channel, and a findings provider run as an analyzer.
+//
+// plugin binaries call Serve with their implementations. the protocol is versioned and checked on start,
+// binaries not started by ralphex refuse to run, as they would block waiting for requests.
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+
+	"github.com/umputun/ralphex/pkg/analyzer"
+	"github.com/umputun/ralphex/pkg/notify"
+)
+
+// ProtocolVersion is the version of the plugin protocol, plugins speaking another version are rejected.
+const ProtocolVersion = 1
+
+// cookieKey and cookieValue mark a process started by ralphex as a plugin
+const (
+	cookieKey   = "RALPHEX_PLUGIN"
+	cookieValue = "b1c4d7e2-ralphex-plugin"
+)
+
+// kinds of plugin implementations
+const (
+	KindExecutor = "executor" // runs prompts, usable as the external review tool
+	KindNotifier = "notifier" // receives run results, usable as a notification channel
+	KindFindings = "findings" // reports findings on the diff under review, run as an analyzer
+)
+
+// Executor runs a prompt and returns the output, signals included.
+type Executor interface {
+	Execute(ctx context.Context, prompt string) (string, error)
+}
+
+// Notifier receives the result of a run.
+type Notifier interface {
+	Notify(ctx context.Context, r notify.Result) error
+}
+
+// Plugin holds the implementations a plugin binary provides, nil for kinds it doesn't provide.
+type Plugin struct {
+	Executor Executor
+	Notifier Notifier
+	Findings analyzer.FindingsProvider
+}
+
+// kinds returns the kinds of the implementations the plugin provides
+func (p Plugin) kinds() []string {
+	var res []string
+	if p.Executor != nil {
+		res = append(res, KindExecutor)
+	}
+	if p.Notifier != nil {
+		res = append(res, KindNotifier)
+	}
+	if p.Findings != nil {
+		res = append(res, KindFindings)
+	}
+	return res
+}
+
+// Serve serves the plugin implementations over stdin and stdout until ralphex closes the connection.
+// stdout belongs to the protocol, plugins log to stderr, which ralphex keeps for error reports.
+func Serve(p Plugin) error {
+	if os.Getenv(cookieKey) != cookieValue {
+		return errors.New("plugin: not started by ralphex, this binary is a ralphex plugin and is run by it")
+	}
+	if len(p.kinds()) == 0 {
+		return errors.New("plugin: no implementations to serve")
+	}
+	return serve(p, stdioConn{Reader: os.Stdin, Writer: os.Stdout})
+}
+
+// serve serves the plugin implementations over conn
+func serve(p Plugin, conn io.ReadWriteCloser) error {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName(serviceName, &service{impl: p}); err != nil {
+		return fmt.Errorf("plugin: register service: %w", err)
+	}
+	srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+	return nil
+}
+
+// stdioConn joins stdin and stdout into a connection
+type stdioConn struct {
+	io.Reader
+	io.Writer
+}
+
+// Close does nothing, stdin and stdout are closed with the process.
+func (stdioConn) Close() error { return nil }
@@ -0,0 +1,156 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/analyzer"
+	"github.com/umputun/ralphex/pkg/notify"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// testModeEnv selects the plugin the test binary serves when started as a plugin
+const testModeEnv = "RALPHEX_PLUGIN_TEST_MODE"
+
+// TestMain runs the test binary as a plugin when started by a test through Start
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(testModeEnv); mode != "" {
+		servePluginForTest(mode)
+		return
+	}
+	os.Exit(m.Run())
+}
+
+type testExecutor struct{}
+
+func (testExecutor) Execute(_ context.Context, prompt string) (string, error) {
+	switch prompt {
+	case "fail":
+		return "", errors.New("review backend down")
+	case "hang":
+		time.Sleep(time.Minute)
+	}
+	return "reviewed: " + prompt + "\n" + status.CodexDone, nil
+}
+
+type testNotifier struct{}
+
+func (testNotifier) Notify(_ context.Context, r notify.Result) error {
+	if r.Status != "success" {
+		return fmt.Errorf("unexpected status %s", r.Status)
+	}
+	return nil
+}
+
+type testFindings struct{}
+
+func (testFindings) Run(_ context.Context, diff string) ([]analyzer.Finding, error) {
+	if !strings.Contains(diff, "+bad") {
+		return nil, nil
+	}
+	return []analyzer.Finding{{File: "a.go", Line: 2, Severity: "major", Message: "bad line"}}, nil
+}
+
+func servePluginForTest(mode string) {
+	var err error
+	switch mode {
+	case "all":
+		err = Serve(Plugin{Executor: testExecutor{}, Notifier: testNotifier{}, Findings: testFindings{}})
+	case "findings":
+		err = Serve(Plugin{Findings: testFindings{}})
+	case "none":
+		err = Serve(Plugin{})
+	case "garbage":
+		fmt.Println("not a plugin")
+		fmt.Fprintln(os.Stderr, "starting up failed")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func startTestPlugin(t *testing.T, mode string) (*Client, error) {
+	t.Helper()
+	t.Setenv(testModeEnv, mode)
+	c, err := Start("test", os.Args[0])
+	if c != nil {
+		t.Cleanup(c.Close)
+	}
+	return c, err
+}
+
+func TestClient(t *testing.T) {
+	c, err := startTestPlugin(t, "all")
+	require.NoError(t, err)
+	assert.Equal(t, "test", c.Name())
+	assert.True(t, c.Provides(KindExecutor))
+	assert.True(t, c.Provides(KindNotifier))
+	assert.True(t, c.Provides(KindFindings))
+
+	res := c.Run(context.Background(), "check the diff")
+	require.NoError(t, res.Error)
+	assert.Equal(t, "reviewed: check the diff\n"+status.CodexDone, res.Output)
+	assert.Equal(t, status.CodexDone, res.Signal)
+
+	res = c.Run(context.Background(), "fail")
+	require.EqualError(t, res.Error, "plugin test: execute: review backend down")
+
+	require.NoError(t, c.Send(context.Background(), notify.Result{Status: "success"}))
+	require.EqualError(t, c.Send(context.Background(), notify.Result{Status: "failure"}),
+		"plugin test: notify: unexpected status failure")
+
+	findings, err := c.FindingsProvider().Run(context.Background(), "+++ b/a.go\n+bad\n")
+	require.NoError(t, err)
+	assert.Equal(t, []analyzer.Finding{{File: "a.go", Line: 2, Severity: "major", Message: "bad line"}}, findings)
+}
+
+func TestClient_Cancel(t *testing.T) {
+	c, err := startTestPlugin(t, "all")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	res := c.Run(ctx, "hang")
+	require.ErrorIs(t, res.Error, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestClient_PartialPlugin(t *testing.T) {
+	c, err := startTestPlugin(t, "findings")
+	require.NoError(t, err)
+	assert.False(t, c.Provides(KindExecutor))
+	assert.True(t, c.Provides(KindFindings))
+	res := c.Run(context.Background(), "prompt")
+	require.ErrorContains(t, res.Error, "plugin provides no executor")
+}
+
+func TestStart_Errors(t *testing.T) {
+	_, err := startTestPlugin(t, "none")
+	require.ErrorContains(t, err, "handshake")
+
+	_, err = startTestPlugin(t, "garbage")
+	require.ErrorContains(t, err, "handshake")
+	require.ErrorContains(t, err, "starting up failed", "stderr of the plugin is reported")
+
+	_, err = Start("missing", "/nonexistent/plugin")
+	require.ErrorContains(t, err, "plugin missing: start")
+
+	_, err = Start("empty", " ")
+	require.EqualError(t, err, "plugin empty: empty command")
+}
+
+func TestServe_NotStartedByRalphex(t *testing.T) {
+	err := Serve(Plugin{Findings: testFindings{}})
+	require.ErrorContains(t, err, "not started by ralphex")
+}
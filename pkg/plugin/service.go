@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/umputun/ralphex/pkg/analyzer"
+	"github.com/umputun/ralphex/pkg/notify"
+)
+
+// serviceName is the name of the RPC service plugins serve
+const serviceName = "Plugin"
+
+// Empty is the argument or reply of calls without one.
+type Empty struct{}
+
+// InfoReply describes a plugin, sent in reply to the handshake.
+type InfoReply struct {
+	Protocol int
+	Kinds    []string
+}
+
+// ExecuteArgs is the argument of an executor call.
+type ExecuteArgs struct {
+	Prompt string
+}
+
+// ExecuteReply is the reply of an executor call.
+type ExecuteReply struct {
+	Output string
+}
+
+// NotifyArgs is the argument of a notifier call.
+type NotifyArgs struct {
+	Result notify.Result
+}
+
+// FindingsArgs is the argument of a findings provider call.
+type FindingsArgs struct {
+	Diff string
+}
+
+// FindingsReply is the reply of a findings provider call.
+type FindingsReply struct {
+	Findings []analyzer.Finding
+}
+
+// service exposes plugin implementations as RPC methods. calls run with a background context,
+// ralphex kills the plugin process to cancel them.
+type service struct {
+	impl Plugin
+}
+
+// Info returns the protocol version and the kinds of implementations the plugin provides.
+func (s *service) Info(_ Empty, reply *InfoReply) error {
+	reply.Protocol, reply.Kinds = ProtocolVersion, s.impl.kinds()
+	return nil
+}
+
+// Execute runs a prompt with the executor.
+func (s *service) Execute(args ExecuteArgs, reply *ExecuteReply) error {
+	if s.impl.Executor == nil {
+		return errors.New("plugin provides no executor")
+	}
+	out, err := s.impl.Executor.Execute(context.Background(), args.Prompt)
+	reply.Output = out
+	return err
+}
+
+// Notify passes a run result to the notifier.
+func (s *service) Notify(args NotifyArgs, _ *Empty) error {
+	if s.impl.Notifier == nil {
+		return errors.New("plugin provides no notifier")
+	}
+	return s.impl.Notifier.Notify(context.Background(), args.Result)
+}
+
+// Findings runs the findings provider over a diff.
+func (s *service) Findings(args FindingsArgs, reply *FindingsReply) error {
+	if s.impl.Findings == nil {
+		return errors.New("plugin provides no findings provider")
+	}
+	findings, err := s.impl.Findings.Run(context.Background(), args.Diff)
+	reply.Findings = findings
+	return err
+}
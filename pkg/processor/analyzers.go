@@ -0,0 +1,103 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/analyzer"
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/verify"
+)
+
+// spellingAnalyzer is the name of the built-in spelling check among the analyzers, see config.Config.SpellCheck
+const spellingAnalyzer = "spelling"
+
+// newAnalyzers builds the analyzers configured with analyzer_<name> keys and the spelling check, by name
+func newAnalyzers(appCfg *config.Config) map[string]analyzer.FindingsProvider {
+	if appCfg == nil || (len(appCfg.Analyzers) == 0 && !appCfg.SpellCheck) {
+		return nil
+	}
+	shell := verify.SelectShell(appCfg.VerifyShell, runtime.GOOS)
+	res := make(map[string]analyzer.FindingsProvider, len(appCfg.Analyzers)+1)
+	if appCfg.SpellCheck {
+		res[spellingAnalyzer] = &analyzer.Spelling{Ignore: appCfg.SpellIgnore}
+	}
+	for name, command := range appCfg.Analyzers {
+		res[name] = &analyzer.Command{Command: command, Shell: shell}
+	}
+	return res
+}
+
+// withAnalyzers wraps an external review run, adding the findings of the analyzers to its output,
+// so they are evaluated and fixed along with the external review findings.
+func (r *Runner) withAnalyzers(run func(ctx context.Context, prompt string) executor.Result) func(ctx context.Context, prompt string) executor.Result {
+	if len(r.analyzers) == 0 {
+		return run
+	}
+	return func(ctx context.Context, prompt string) executor.Result {
+		res := run(ctx, prompt)
+		if res.Error != nil {
+			return res
+		}
+		report, err := r.runAnalyzers(ctx)
+		if err != nil {
+			return executor.Result{Error: err}
+		}
+		if report != "" {
+			res.Output = strings.TrimSpace(res.Output + "\n\n" + report)
+		}
+		return res
+	}
+}
+
+// runAnalyzers runs the analyzers over the branch changes and returns their findings as review text,
+// empty if none reported anything. a failing analyzer is logged and skipped, only cancellation is returned.
+func (r *Runner) runAnalyzers(ctx context.Context) (string, error) {
+	diff := r.branchDiff()
+	var sections []string
+	for _, name := range slices.Sorted(maps.Keys(r.analyzers)) {
+		r.log.Print("running %s analyzer", name)
+		findings, err := r.analyzers[name].Run(ctx, diff)
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("%s analyzer: %w", name, ctx.Err())
+		}
+		if err != nil {
+			r.log.Print("[WARN] %s analyzer failed, continuing: %v", name, err)
+			continue
+		}
+		r.log.Print("%s analyzer: %d findings", name, len(findings))
+		if len(findings) == 0 {
+			continue
+		}
+		lines := make([]string, 0, len(findings)+1)
+		lines = append(lines, name+" analyzer findings:")
+		for _, f := range findings {
+			lines = append(lines, "- "+f.String())
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	return strings.Join(sections, "\n\n"), nil
+}
+
+// branchDiff returns the changes of the branch against the default branch, committed or not.
+// empty without git or if the diff fails, analyzers then look at the worktree on their own.
+func (r *Runner) branchDiff() string {
+	if r.git == nil {
+		return ""
+	}
+	base := r.cfg.DefaultBranch
+	if base == "" {
+		base = "HEAD"
+	}
+	diff, err := r.git.DiffSince(base)
+	if err != nil {
+		r.log.Print("[WARN] failed to get diff for analyzers: %v", err)
+		return ""
+	}
+	return diff
+}
@@ -0,0 +1,75 @@
+package processor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/analyzer"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// providerFunc adapts a function to analyzer.FindingsProvider
+type providerFunc func(ctx context.Context, diff string) ([]analyzer.Finding, error)
+
+func (f providerFunc) Run(ctx context.Context, diff string) ([]analyzer.Finding, error) {
+	return f(ctx, diff)
+}
+
+func TestRunner_Analyzers(t *testing.T) {
+	claude := newMockExecutor([]executor.Result{
+		{Output: "review done", Signal: status.ReviewDone}, // first review
+		{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop
+		{Output: "fixed", Signal: status.CodexDone},        // evaluation of codex and analyzer findings
+		{Output: "review done", Signal: status.ReviewDone}, // post-codex review
+	})
+	codex := newMockExecutor([]executor.Result{{Output: "a.go:3 missing error check"}})
+	gitMock := &mocks.GitCheckerMock{
+		PrepareDiffFunc:    func(string, bool) (git.DiffReadiness, error) { return git.DiffReadiness{}, nil },
+		ChangedFilesFunc:   func(string) ([]string, error) { return nil, nil },
+		HeadHashFunc:       func() (string, error) { return "abc123", nil },
+		DiffSinceFunc:      func(string) (string, error) { return "--- a/b.go\n+++ b/b.go\n+exec(input)\n", nil },
+		SpecialChangesFunc: func(string) (git.SpecialChanges, error) { return git.SpecialChanges{}, nil },
+	}
+
+	var gotDiff string
+	log := newMockLogger("progress.txt")
+	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+		DefaultBranch: "master", AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+	r.SetGitChecker(gitMock)
+	r.SetFindingsProviders(map[string]analyzer.FindingsProvider{
+		"semgrep": providerFunc(func(_ context.Context, diff string) ([]analyzer.Finding, error) {
+			gotDiff = diff
+			return []analyzer.Finding{{File: "b.go", Line: 1, Severity: "critical", Message: "command injection"}}, nil
+		}),
+		"broken": providerFunc(func(context.Context, string) ([]analyzer.Finding, error) {
+			return nil, errors.New("scanner crashed")
+		}),
+	})
+	_, err := r.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "--- a/b.go\n+++ b/b.go\n+exec(input)\n", gotDiff)
+	assert.Equal(t, "master", gitMock.DiffSinceCalls()[0].Rev, "analyzers get the branch diff")
+	evalPrompt := claude.RunCalls()[2].Prompt
+	assert.Contains(t, evalPrompt, "a.go:3 missing error check")
+	assert.Contains(t, evalPrompt, "semgrep analyzer findings:\n- [critical] b.go:1: command injection")
+	assert.ElementsMatch(t, []processor.Finding{{File: "a.go", Message: "missing error check"},
+		{File: "b.go", Message: "command injection"}}, r.Findings())
+
+	var warned bool
+	for _, call := range log.PrintCalls() {
+		if call.Format == "[WARN] %s analyzer failed, continuing: %v" && call.Args[0] == "broken" {
+			warned = true
+		}
+	}
+	assert.True(t, warned, "a failing analyzer is logged and skipped")
+}
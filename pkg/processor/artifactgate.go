@@ -0,0 +1,82 @@
+package processor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/verify"
+)
+
+// artifactGateOn reports if the artifact gate runs after task iterations, see config.Config.ArtifactGate.
+func (r *Runner) artifactGateOn() bool {
+	if r.cfg.AppConfig == nil {
+		return false
+	}
+	mode := r.cfg.AppConfig.ArtifactGate
+	return mode == config.ArtifactGateWarn || mode == config.ArtifactGateFeedback || mode == config.ArtifactGateFail
+}
+
+// artifactCheck returns the artifact check of the configured size limit and allow patterns.
+func (r *Runner) artifactCheck() verify.ArtifactCheck {
+	return verify.ArtifactCheck{MaxSize: int64(r.cfg.AppConfig.ArtifactMaxSizeKB) * 1024, Allow: r.cfg.AppConfig.ArtifactAllow}
+}
+
+// recordArtifactBaseline records the artifacts on the branch when the run starts, the gate leaves them alone.
+func (r *Runner) recordArtifactBaseline() {
+	if !r.artifactGateOn() || r.git == nil {
+		return
+	}
+	artifacts, err := r.findArtifacts()
+	if err != nil {
+		r.log.Print("[WARN] artifact gate: %v", err)
+		return
+	}
+	for _, a := range artifacts {
+		r.artifactBaseline = append(r.artifactBaseline, a.File)
+	}
+}
+
+// findArtifacts returns the binary and oversized files changed on the branch.
+func (r *Runner) findArtifacts() ([]verify.Artifact, error) {
+	files, err := r.changedFiles()
+	if err != nil {
+		return nil, err
+	}
+	artifacts, err := r.artifactCheck().Find(files)
+	if err != nil {
+		return nil, fmt.Errorf("find artifacts: %w", err)
+	}
+	return artifacts, nil
+}
+
+// runArtifactGate looks for binary and oversized files added on the branch since the run started, after
+// a task iteration. in warn mode they are logged, in feedback mode returned as feedback for the next task
+// iteration, in fail mode returned as error. returns empty feedback if there are none or the gate is off.
+func (r *Runner) runArtifactGate() (string, error) {
+	if !r.artifactGateOn() {
+		return "", nil
+	}
+	artifacts, err := r.findArtifacts()
+	if err != nil {
+		r.log.Print("[WARN] artifact gate: %v", err)
+		return "", nil
+	}
+	artifacts = slices.DeleteFunc(artifacts, func(a verify.Artifact) bool { return slices.Contains(r.artifactBaseline, a.File) })
+	if len(artifacts) == 0 {
+		return "", nil
+	}
+	names := make([]string, len(artifacts))
+	for i, a := range artifacts {
+		names[i] = a.String()
+	}
+	r.log.Print("[WARN] artifact gate: %d binary or oversized files added: %s", len(artifacts), strings.Join(names, ", "))
+	switch r.cfg.AppConfig.ArtifactGate {
+	case config.ArtifactGateFeedback:
+		return verify.ArtifactFeedback(artifacts), nil
+	case config.ArtifactGateFail:
+		return "", fmt.Errorf("artifact gate: binary or oversized files added: %s", strings.Join(names, ", "))
+	}
+	return "", nil
+}
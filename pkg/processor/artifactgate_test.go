@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+)
+
+func TestRunner_runArtifactGate(t *testing.T) {
+	setup := func(t *testing.T, mode string) (*Runner, *mocks.GitCheckerMock) {
+		t.Helper()
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile("old.bin", []byte{0x7f, 'E', 'L', 'F', 0}, 0o600))
+		require.NoError(t, os.WriteFile("a.go", []byte("package a\n"), 0o600))
+		gitMock := &mocks.GitCheckerMock{ChangedFilesFunc: func(string) ([]string, error) { return []string{"old.bin", "a.go"}, nil }}
+		r := &Runner{log: newMockLogger(""), git: gitMock, cfg: Config{AppConfig: &config.Config{ArtifactGate: mode,
+			ArtifactMaxSizeKB: 1}}}
+		r.recordArtifactBaseline()
+		// the iteration adds a build output and a large fixture
+		require.NoError(t, os.WriteFile("app", []byte{0x7f, 'E', 'L', 'F', 0, 1}, 0o600))
+		require.NoError(t, os.WriteFile("big.json", []byte(strings.Repeat("[1, 2],", 290)), 0o600))
+		gitMock.ChangedFilesFunc = func(string) ([]string, error) { return []string{"old.bin", "a.go", "app", "big.json"}, nil }
+		return r, gitMock
+	}
+
+	t.Run("off", func(t *testing.T) {
+		r, gitMock := setup(t, config.ArtifactGateOff)
+		feedback, err := r.runArtifactGate()
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+		assert.Empty(t, gitMock.ChangedFilesCalls())
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		r, _ := setup(t, config.ArtifactGateWarn)
+		assert.Equal(t, []string{"old.bin"}, r.artifactBaseline)
+		feedback, err := r.runArtifactGate()
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+	})
+
+	t.Run("feedback", func(t *testing.T) {
+		r, _ := setup(t, config.ArtifactGateFeedback)
+		feedback, err := r.runArtifactGate()
+		require.NoError(t, err)
+		assert.Contains(t, feedback, "  app (binary, 1 KB)\n  big.json (2 KB)\nremove them")
+		assert.NotContains(t, feedback, "old.bin", "files there before the run are left alone")
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		r, _ := setup(t, config.ArtifactGateFail)
+		_, err := r.runArtifactGate()
+		require.EqualError(t, err, "artifact gate: binary or oversized files added: app (binary, 1 KB), big.json (2 KB)")
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		r, _ := setup(t, config.ArtifactGateFail)
+		r.cfg.AppConfig.ArtifactAllow = []string{"app", "*.json"}
+		feedback, err := r.runArtifactGate()
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+	})
+}
@@ -0,0 +1,351 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/seal"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// CheckpointFile is the default location of the run checkpoint, relative to the repository root.
+const CheckpointFile = ".ralphex/state.json"
+
+// checkpointMigrations upgrade a checkpoint, decoded as a JSON object, from format i+1 to i+2. a change of
+// the format older checkpoints can't be read with, e.g. a renamed field, adds the migration converting them.
+// fields added with a zero value default need none. checkpoints without a version are format 1.
+var checkpointMigrations = []func(cp map[string]any) error{
+	migrateReviewState, // 1 to 2
+}
+
+// migrateReviewState moves the external review state, kept at the top level by format 1, into the review
+// object format 2 added with the milestone review base. format 1 has no milestone, the review of a
+// milestone run resumed from it diffs against the default branch.
+func migrateReviewState(cp map[string]any) error {
+	review := map[string]any{}
+	for _, key := range []string{"findings", "claude_response"} {
+		v, ok := cp[key]
+		if !ok {
+			continue
+		}
+		if _, isString := v.(string); !isString {
+			return fmt.Errorf("%s is %T, not a string", key, v)
+		}
+		review[key] = v
+		delete(cp, key)
+	}
+	if len(review) > 0 {
+		cp["review"] = review
+	}
+	return nil
+}
+
+// checkpointVersion returns the checkpoint format written by this build, the one after the last migration.
+func checkpointVersion() int {
+	return len(checkpointMigrations) + 1
+}
+
+// checkpointOutputLimit caps the agent output kept in a checkpoint, the tail is kept
+const checkpointOutputLimit = 16 * 1024
+
+// Step identifies a resumable step of the pipeline.
+type Step string
+
+// pipeline steps in run order
+const (
+	StepTask        Step = "task"
+	StepFirstReview Step = "first-review"
+	StepReview      Step = "review"          // critical/major review loop before the external review
+	StepExternal    Step = "external-review" // codex or custom review loop
+	StepPostReview  Step = "post-review"     // critical/major review loop after the external review
+	StepFinalize    Step = "finalize"
+)
+
+var stepOrder = []Step{StepTask, StepFirstReview, StepReview, StepExternal, StepPostReview, StepFinalize}
+
+// Checkpoint is the runner state persisted after each iteration, allowing an interrupted run to be resumed.
+type Checkpoint struct {
+	Version        int              `json:"version"` // checkpoint format, see checkpointVersion
+	PlanFile       string           `json:"plan_file,omitempty"`
+	Mode           Mode             `json:"mode"`
+	Step           Step             `json:"step"`
+	Phase          status.Phase     `json:"phase"`
+	Iteration      int              `json:"iteration"`           // iterations of Step completed
+	Round          int              `json:"round,omitempty"`     // external review round, see Config.RepeatUntilClean
+	Stage          int              `json:"stage,omitempty"`     // custom pipeline phase, see Config.Phases
+	Milestone      int              `json:"milestone,omitempty"` // 1-based milestone run, see config.Config.MilestoneReviews
+	TaskIterations int              `json:"task_iterations"`
+	LastOutput     string           `json:"last_output,omitempty"`    // tail of the last agent output, see resumedOutputNote
+	PartialOutput  string           `json:"partial_output,omitempty"` // tail of the output of the agent call the run was canceled in
+	Review         CheckpointReview `json:"review,omitzero"`          // milestone review base and external review state
+	SessionID      string           `json:"session_id,omitempty"`     // agent session of the last task iteration, see continue_session
+	Prompts        string           `json:"prompts,omitempty"`        // hash of the prompt templates of the run, see config.Config.PromptsHash
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// CheckpointReview is the review state of a checkpoint, format 1 kept the findings and response at the top level.
+type CheckpointReview struct {
+	Base           string `json:"base,omitempty"`            // commit the milestone started at, its reviews diff against it
+	Findings       string `json:"findings,omitempty"`        // last external review findings
+	ClaudeResponse string `json:"claude_response,omitempty"` // claude's answer to Findings, context for the next external review
+}
+
+// LoadCheckpoint reads a checkpoint saved by an interrupted run, decrypting one encrypted with s and migrating
+// one written by an older version of ralphex to the current format. it fails for a checkpoint it can't resume,
+// e.g. one written by a newer version.
+func LoadCheckpoint(path string, s *seal.Sealer) (*Checkpoint, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is the checkpoint location, not user input
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+	if data, err = s.Open(data); err != nil {
+		return nil, fmt.Errorf("checkpoint %s: %w", path, err)
+	}
+	var raw map[string]any
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse checkpoint %s: %w", path, err)
+	}
+	if data, err = migrateCheckpoint(raw); err != nil {
+		return nil, fmt.Errorf("checkpoint %s: %w", path, err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parse checkpoint %s: %w", path, err)
+	}
+	if !slices.Contains(stepOrder, cp.Step) {
+		return nil, fmt.Errorf("checkpoint %s: unknown step %q, this version of ralphex can't resume it, "+
+			"remove the file to start over", path, cp.Step)
+	}
+	return &cp, nil
+}
+
+// migrateCheckpoint upgrades a decoded checkpoint to checkpointVersion and returns it encoded again
+func migrateCheckpoint(raw map[string]any) ([]byte, error) {
+	version := 1
+	if v, ok := raw["version"].(float64); ok {
+		version = int(v)
+	}
+	if version < 1 {
+		return nil, fmt.Errorf("invalid format version %d, remove the file to start over", version)
+	}
+	current := checkpointVersion()
+	if version > current {
+		return nil, fmt.Errorf("format %d is newer than this version of ralphex supports (%d), "+
+			"upgrade ralphex to resume or remove the file to start over", version, current)
+	}
+	for v := version; v < current; v++ {
+		if err := checkpointMigrations[v-1](raw); err != nil {
+			return nil, fmt.Errorf("migrate format %d to %d: %w", v, v+1, err)
+		}
+	}
+	raw["version"] = current
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("marshal migrated checkpoint: %w", err)
+	}
+	return data, nil
+}
+
+// save writes the checkpoint atomically, a crash never leaves a truncated file behind. it is encrypted
+// with s, a nil s writes it in plaintext.
+func (cp Checkpoint) save(path string, s *seal.Sealer) error {
+	cp.Version = checkpointVersion()
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+	data = s.Seal(data)
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create checkpoint dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("replace checkpoint: %w", err)
+	}
+	return nil
+}
+
+// saveCheckpoint records the step about to run and the iterations of it already completed.
+// failures are logged once and don't stop the run, a missing checkpoint only costs the ability to resume.
+func (r *Runner) saveCheckpoint(cp Checkpoint) {
+	r.position = Checkpoint{Step: cp.Step, Iteration: cp.Iteration}
+	r.recordStep(cp.Step)
+	r.emit(Event{Type: EventIterationStarted, Step: cp.Step, Iteration: cp.Iteration + 1})
+	if r.cfg.CheckpointPath == "" {
+		return
+	}
+	cp.PlanFile, cp.Mode, cp.TaskIterations, cp.Round, cp.Stage = r.cfg.PlanFile, r.cfg.Mode, r.taskIterations, r.round, r.stage
+	cp.Milestone, cp.Review.Base = r.milestone, r.milestoneBase
+	cp.Phase = r.phaseHolder.Get()
+	if r.cfg.AppConfig != nil {
+		cp.Prompts = r.cfg.AppConfig.PromptsHash()
+	}
+	cp.LastOutput = outputTail(r.lastOutput)
+	cp.UpdatedAt = time.Now()
+	r.saved = cp
+	if err := cp.save(r.cfg.CheckpointPath, artifactSealer(r.cfg.AppConfig)); err != nil && !r.checkpointFailed {
+		r.checkpointFailed = true
+		r.log.Print("[WARN] failed to save checkpoint, the run can't be resumed: %v", err)
+	}
+}
+
+// savePartialOutput adds the output of the agent call interrupted by a cancellation, e.g. Ctrl+C or a timeout,
+// to the checkpoint of the iteration it ran in, so the work of the call is not lost for the resumed run.
+func (r *Runner) savePartialOutput() {
+	if r.partialOutput == "" {
+		return
+	}
+	r.log.Print("agent call interrupted, %d bytes of its output kept in the checkpoint and run report", len(r.partialOutput))
+	if r.cfg.CheckpointPath == "" || r.saved.Step == "" {
+		return
+	}
+	cp := r.saved
+	cp.LastOutput, cp.PartialOutput, cp.UpdatedAt = outputTail(r.lastOutput), outputTail(r.partialOutput), time.Now()
+	if err := cp.save(r.cfg.CheckpointPath, artifactSealer(r.cfg.AppConfig)); err != nil {
+		r.log.Print("[WARN] failed to save partial output to checkpoint: %v", err)
+	}
+}
+
+// artifactSealer returns the sealer the checkpoint and transcripts are encrypted with, nil without an artifact key
+func artifactSealer(appCfg *config.Config) *seal.Sealer {
+	if appCfg == nil {
+		return nil
+	}
+	return appCfg.ArtifactSealer
+}
+
+// outputTail returns the tail of agent output kept in a checkpoint, see checkpointOutputLimit.
+// the cut is moved forward to a rune boundary, so the checkpoint and the prompt of the first resumed
+// iteration, see resumedOutputNote, get valid UTF-8.
+func outputTail(output string) string {
+	if len(output) <= checkpointOutputLimit {
+		return output
+	}
+	start := len(output) - checkpointOutputLimit
+	for start < len(output) && output[start]&0xC0 == 0x80 {
+		start++
+	}
+	return output[start:]
+}
+
+// warnResumedPrompts warns when the prompt templates changed since the interrupted run being resumed,
+// the rest of the run follows instructions the completed part didn't.
+func (r *Runner) warnResumedPrompts() {
+	if r.resume == nil || r.resume.Prompts == "" || r.cfg.AppConfig == nil {
+		return
+	}
+	if prompts := r.cfg.AppConfig.PromptsHash(); prompts != r.resume.Prompts {
+		r.log.Print("[WARN] prompt templates changed since the interrupted run (%s, now %s), "+
+			"the resumed run uses the current ones", r.resume.Prompts, prompts)
+	}
+}
+
+// clearCheckpoint removes the checkpoint of a completed run.
+func (r *Runner) clearCheckpoint() {
+	if r.cfg.CheckpointPath == "" {
+		return
+	}
+	if err := os.Remove(r.cfg.CheckpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		r.log.Print("[WARN] failed to remove checkpoint: %v", err)
+	}
+}
+
+// skipStep reports whether step was completed by the run being resumed.
+func (r *Runner) skipStep(step Step) bool {
+	if r.resume == nil {
+		return false
+	}
+	return slices.Index(stepOrder, step) < slices.Index(stepOrder, r.resume.Step)
+}
+
+// resumeStep returns the checkpoint if step is the one the resumed run stopped in, nil otherwise.
+// the checkpoint is consumed, steps running later start from scratch.
+func (r *Runner) resumeStep(step Step) *Checkpoint {
+	if r.resume == nil || r.resume.Step != step {
+		return nil
+	}
+	cp := r.resume
+	r.resume = nil
+	r.log.Print("resuming interrupted run at %s step, %d iterations of it completed", step, cp.Iteration)
+	r.resumedOutput = resumedOutputNote(cp)
+	return cp
+}
+
+// takeResumedOutput returns the output saved by the interrupted run, formatted for the prompt of the first
+// resumed iteration. empty if the run isn't resumed, nothing was saved or it was taken already.
+func (r *Runner) takeResumedOutput() string {
+	note := r.resumedOutput
+	r.resumedOutput = ""
+	return note
+}
+
+// resumedOutputNote returns the output saved in cp, appended to the prompt of the first resumed iteration
+// so the agent continues the work of the interrupted run instead of redoing it. the output of the agent call
+// the run was canceled in is preferred over the one of the last completed call. empty without saved output.
+func resumedOutputNote(cp *Checkpoint) string {
+	switch {
+	case cp.PartialOutput != "":
+		return "\n\n---\nINTERRUPTED RUN:\nThe run was interrupted while the agent worked on this step. The end of " +
+			"its output up to the interruption is below. Check what of it is done in the working tree and continue " +
+			"from there, don't redo completed work.\n\n" + cp.PartialOutput + "\n"
+	case cp.LastOutput != "":
+		return "\n\n---\nRESUMED RUN:\nThe run was interrupted and is resumed now. The end of the output of the " +
+			"last agent call before the interruption is below, for context on where the work stopped.\n\n" +
+			cp.LastOutput + "\n"
+	}
+	return ""
+}
+
+// firstIteration returns the iteration step starts at, following the completed ones when resumed.
+func (r *Runner) firstIteration(step Step) int {
+	if cp := r.resumeStep(step); cp != nil {
+		return cp.Iteration + 1
+	}
+	return 1
+}
+
+// outputRecorder keeps the output of the last agent call for checkpoints, the output of a call interrupted
+// by a cancellation and the signals received for the run report and the event handler
+type outputRecorder struct {
+	name    string
+	exec    Executor
+	last    *string
+	partial *string // output of the last call if it was canceled, empty otherwise
+	signals *[]string
+	mu      *sync.Mutex // shared by recorders of executors running in parallel
+	emit    func(Event)
+}
+
+// Run executes the wrapped executor and records its output and signal.
+func (o *outputRecorder) Run(ctx context.Context, prompt string) executor.Result {
+	res := o.exec.Run(ctx, prompt)
+	o.mu.Lock()
+	if res.Output != "" {
+		*o.last = res.Output
+	}
+	*o.partial = ""
+	if res.Error != nil && ctx.Err() != nil {
+		*o.partial = res.Output
+	}
+	if res.Signal != "" {
+		*o.signals = append(*o.signals, res.Signal)
+	}
+	o.mu.Unlock()
+	if res.Signal != "" {
+		o.emit(Event{Type: EventSignalDetected, Executor: o.name, Signal: res.Signal})
+	}
+	return res
+}
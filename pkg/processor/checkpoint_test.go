@@ -0,0 +1,295 @@
+package processor_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+	"github.com/umputun/ralphex/pkg/seal"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestRunner_CheckpointAndResume(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+	cpPath := filepath.Join(tmpDir, ".ralphex", "state.json")
+	appCfg := testAppConfig(t)
+
+	// first run is interrupted by a codex failure in the second external review iteration
+	claude := newMockExecutor([]executor.Result{
+		{Output: "task done", Signal: status.Completed},
+		{Output: "review done", Signal: status.ReviewDone},
+		{Output: "review done", Signal: status.ReviewDone},
+		{Output: "fixed the nil check"}, // evaluation of the first codex findings
+	})
+	codex := newMockExecutor([]executor.Result{{Output: "nil deref in foo.go:10"}, {Error: errors.New("codex crashed")}})
+	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true,
+		IterationDelayMs: 1, CheckpointPath: cpPath, AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+	require.ErrorContains(t, err, "codex crashed")
+
+	cp, err := processor.LoadCheckpoint(cpPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, processor.StepExternal, cp.Step)
+	assert.Equal(t, 1, cp.Iteration)
+	assert.Equal(t, 1, cp.TaskIterations)
+	assert.Equal(t, processor.ModeFull, cp.Mode)
+	assert.Equal(t, planFile, cp.PlanFile)
+	assert.Equal(t, status.PhaseCodex, cp.Phase)
+	assert.Equal(t, "nil deref in foo.go:10", cp.Review.Findings)
+	assert.Equal(t, "fixed the nil check", cp.Review.ClaudeResponse)
+	assert.Equal(t, "fixed the nil check", cp.LastOutput)
+	assert.Equal(t, appCfg.PromptsHash(), cp.Prompts)
+
+	// resumed run continues with the second external review iteration, completed steps are skipped
+	claude = newMockExecutor([]executor.Result{
+		{Output: "done", Signal: status.CodexDone},
+		{Output: "review done", Signal: status.ReviewDone},
+	})
+	codex = newMockExecutor([]executor.Result{{Output: "no issues"}})
+	cfg.Resume = cp
+	r = processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+	_, err = r.Run(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, codex.RunCalls(), 1)
+	assert.Contains(t, codex.RunCalls()[0].Prompt, "fixed the nil check", "claude response is passed to the resumed review")
+	assert.Len(t, claude.RunCalls(), 2)
+	assert.Contains(t, claude.RunCalls()[0].Prompt, "RESUMED RUN:", "saved output is passed to the first resumed prompt")
+	assert.Contains(t, claude.RunCalls()[0].Prompt, "fixed the nil check")
+	assert.NotContains(t, claude.RunCalls()[1].Prompt, "RESUMED RUN:")
+	assert.Equal(t, 1, r.TaskIterations())
+	assert.NoFileExists(t, cpPath, "checkpoint is removed after a successful run")
+}
+
+func TestRunner_ResumeTaskPhase(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	var sections []string
+	log := newMockLogger("progress.txt")
+	log.PrintSectionFunc = func(s status.Section) { sections = append(sections, s.Label) }
+	claude := newMockExecutor([]executor.Result{{Output: "task done", Signal: status.Completed}})
+
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1,
+		AppConfig: testAppConfig(t), Resume: &processor.Checkpoint{Step: processor.StepTask, Iteration: 3, TaskIterations: 3}}
+	r := processor.NewWithExecutors(cfg, log, claude, nil, nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"task iteration 4"}, sections)
+	assert.Equal(t, 4, r.TaskIterations())
+	assert.False(t, printed(log, "prompt templates changed"), "checkpoint without prompts hash")
+
+	t.Run("saved output passed to the first resumed iteration", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+		claude := &mocks.ExecutorMock{}
+		claude.RunFunc = func(context.Context, string) executor.Result {
+			if len(claude.RunCalls()) == 1 {
+				return executor.Result{Output: "still working"}
+			}
+			require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+			return executor.Result{Output: "task done", Signal: status.Completed}
+		}
+		cfg.Resume = &processor.Checkpoint{Step: processor.StepTask, Iteration: 3, LastOutput: "edited foo.go, running tests",
+			PartialOutput: "edited foo.go, running tests"}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, nil, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, claude.RunCalls(), 2)
+		assert.Contains(t, claude.RunCalls()[0].Prompt, "INTERRUPTED RUN:")
+		assert.True(t, strings.HasSuffix(claude.RunCalls()[0].Prompt, "\n\nedited foo.go, running tests\n"))
+		assert.NotContains(t, claude.RunCalls()[1].Prompt, "edited foo.go", "only the first resumed iteration gets it")
+	})
+
+	t.Run("prompts changed since the interrupted run", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{{Output: "task done", Signal: status.Completed}})
+		cfg.Resume = &processor.Checkpoint{Step: processor.StepTask, Iteration: 3, Prompts: "0123456789ab"}
+		r := processor.NewWithExecutors(cfg, log, claude, nil, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+		assert.True(t, printed(log, "prompt templates changed since the interrupted run (0123456789ab, now "+
+			cfg.AppConfig.PromptsHash()+")"))
+	})
+}
+
+func TestRunner_CheckpointEncrypted(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+	cpPath := filepath.Join(tmpDir, ".ralphex", "state.json")
+	appCfg := testAppConfig(t)
+	var err error
+	appCfg.ArtifactSealer, err = seal.New("checkpoint passphrase")
+	require.NoError(t, err)
+
+	claude := newMockExecutor([]executor.Result{
+		{Output: "task done", Signal: status.Completed},
+		{Output: "review done", Signal: status.ReviewDone},
+		{Output: "review done", Signal: status.ReviewDone},
+	})
+	codex := newMockExecutor([]executor.Result{{Output: "secret algorithm in foo.go:10", Error: errors.New("codex crashed")}})
+	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true,
+		IterationDelayMs: 1, CheckpointPath: cpPath, AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+	_, err = r.Run(context.Background())
+	require.ErrorContains(t, err, "codex crashed")
+
+	data, err := os.ReadFile(cpPath)
+	require.NoError(t, err)
+	assert.True(t, seal.IsSealed(data))
+	assert.NotContains(t, string(data), "review done")
+
+	cp, err := processor.LoadCheckpoint(cpPath, appCfg.ArtifactSealer)
+	require.NoError(t, err)
+	assert.Equal(t, processor.StepExternal, cp.Step)
+	assert.Equal(t, "review done", cp.LastOutput)
+
+	_, err = processor.LoadCheckpoint(cpPath, nil)
+	require.ErrorIs(t, err, seal.ErrNoKey)
+}
+
+func TestRunner_CheckpointOutputTail(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+	cpPath := filepath.Join(tmpDir, "state.json")
+
+	output := strings.Repeat("€", 6000) + "ab" // 18002 bytes, a byte cut at 16 KiB from the end splits a rune
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	claude := &mocks.ExecutorMock{}
+	claude.RunFunc = func(ctx context.Context, _ string) executor.Result {
+		if len(claude.RunCalls()) == 1 {
+			return executor.Result{Output: output}
+		}
+		cancel()
+		return executor.Result{Error: ctx.Err()}
+	}
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1,
+		CheckpointPath: cpPath, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	_, err := r.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	cp, err := processor.LoadCheckpoint(cpPath, nil)
+	require.NoError(t, err)
+	assert.True(t, utf8.ValidString(cp.LastOutput))
+	assert.LessOrEqual(t, len(cp.LastOutput), 16*1024)
+	assert.Equal(t, strings.Repeat("€", 5460)+"ab", cp.LastOutput, "starts at the first whole rune")
+}
+
+func TestLoadCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := processor.LoadCheckpoint(filepath.Join(dir, "missing.json"), nil)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	bad := filepath.Join(dir, "bad.json")
+	require.NoError(t, os.WriteFile(bad, []byte(`{"step":"deploy"}`), 0o600))
+	_, err = processor.LoadCheckpoint(bad, nil)
+	require.ErrorContains(t, err, `unknown step "deploy"`)
+
+	broken := filepath.Join(dir, "broken.json")
+	require.NoError(t, os.WriteFile(broken, []byte(`{`), 0o600))
+	_, err = processor.LoadCheckpoint(broken, nil)
+	require.ErrorContains(t, err, "parse checkpoint")
+
+	t.Run("unversioned checkpoint is format 1, migrated to 2", func(t *testing.T) {
+		path := filepath.Join(dir, "v1.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"plan_file":"/repo/plan.md","mode":"full","step":"external-review",`+
+			`"phase":"codex","iteration":2,"task_iterations":5,"findings":"a.go:1 bug","claude_response":"fixed a.go",`+
+			`"updated_at":"2026-01-02T10:00:00Z"}`), 0o600))
+		cp, err := processor.LoadCheckpoint(path, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 2, cp.Version)
+		assert.Equal(t, processor.StepExternal, cp.Step)
+		assert.Equal(t, 2, cp.Iteration)
+		assert.Equal(t, 5, cp.TaskIterations)
+		assert.Equal(t, processor.CheckpointReview{Findings: "a.go:1 bug", ClaudeResponse: "fixed a.go"}, cp.Review,
+			"review state moved into the review object")
+		assert.Zero(t, cp.Milestone)
+		assert.Equal(t, "/repo/plan.md", cp.PlanFile)
+
+		require.NoError(t, os.WriteFile(path, []byte(`{"step":"task"}`), 0o600))
+		cp, err = processor.LoadCheckpoint(path, nil)
+		require.NoError(t, err)
+		assert.Equal(t, processor.CheckpointReview{}, cp.Review)
+
+		require.NoError(t, os.WriteFile(path, []byte(`{"step":"external-review","findings":["a.go:1 bug"]}`), 0o600))
+		_, err = processor.LoadCheckpoint(path, nil)
+		require.ErrorContains(t, err, "migrate format 1 to 2: findings is []interface {}, not a string")
+	})
+
+	t.Run("format 2 round trip", func(t *testing.T) {
+		path := filepath.Join(dir, "v2.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"version":2,"step":"review","milestone":2,`+
+			`"review":{"base":"abc123","findings":"a.go:1 bug"}}`), 0o600))
+		cp, err := processor.LoadCheckpoint(path, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 2, cp.Milestone)
+		assert.Equal(t, processor.CheckpointReview{Base: "abc123", Findings: "a.go:1 bug"}, cp.Review)
+	})
+
+	t.Run("checkpoint of an older format is migrated", func(t *testing.T) {
+		// format 1 named the completed iterations "done", format 2 kept the findings as a list
+		restore := processor.SetCheckpointMigrations([]func(map[string]any) error{
+			func(cp map[string]any) error {
+				cp["iteration"] = cp["done"]
+				delete(cp, "done")
+				cp["findings"] = []any{cp["findings"]}
+				return nil
+			},
+			func(cp map[string]any) error {
+				list, ok := cp["findings"].([]any)
+				if !ok {
+					return errors.New("findings is not a list")
+				}
+				lines := make([]string, 0, len(list))
+				for _, f := range list {
+					lines = append(lines, fmt.Sprint(f))
+				}
+				cp["review"] = map[string]any{"findings": strings.Join(lines, "\n")}
+				delete(cp, "findings")
+				return nil
+			},
+		})
+		defer restore()
+
+		path := filepath.Join(dir, "old.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"mode":"full","step":"external-review","done":2,`+
+			`"findings":"a.go:1 bug"}`), 0o600))
+		cp, err := processor.LoadCheckpoint(path, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, cp.Version)
+		assert.Equal(t, 2, cp.Iteration)
+		assert.Equal(t, "a.go:1 bug", cp.Review.Findings)
+
+		require.NoError(t, os.WriteFile(path, []byte(`{"version":2,"step":"task","findings":"not a list"}`), 0o600))
+		_, err = processor.LoadCheckpoint(path, nil)
+		require.ErrorContains(t, err, "migrate format 2 to 3: findings is not a list")
+	})
+
+	t.Run("checkpoint of a newer version", func(t *testing.T) {
+		path := filepath.Join(dir, "v99.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"version":99,"step":"task"}`), 0o600))
+		_, err := processor.LoadCheckpoint(path, nil)
+		require.ErrorContains(t, err, "format 99 is newer than this version of ralphex supports (2)")
+	})
+}
@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"errors"
+	"os"
+	"slices"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// ErrNothingToDo is returned by Run when every task of the plan is checked and completed_plan is "exit".
+var ErrNothingToDo = errors.New("nothing to do, all plan tasks are completed")
+
+// completedPlan returns what to do with a plan having all its tasks checked, a CompletedPlan* value.
+func (r *Runner) completedPlan() string {
+	if r.cfg.AppConfig == nil || r.cfg.AppConfig.CompletedPlan == "" {
+		return config.CompletedPlanReview
+	}
+	return r.cfg.AppConfig.CompletedPlan
+}
+
+// runsTaskPhase reports if the mode of the run has a task phase.
+func (r *Runner) runsTaskPhase() bool {
+	switch r.cfg.Mode {
+	case ModeTasksOnly:
+		return true
+	case ModeFull:
+		return len(r.cfg.Phases) == 0 || slices.ContainsFunc(r.cfg.Phases, func(p config.PhaseSpec) bool {
+			return p.Name == config.PhaseTask
+		})
+	default:
+		return false
+	}
+}
+
+// planCompleted reports if the plan file has checkbox items and all of them are checked.
+func (r *Runner) planCompleted() bool {
+	content, err := os.ReadFile(r.resolvePlanFilePath())
+	if err != nil {
+		return false
+	}
+	tasks := plan.ParseTasks(string(content))
+	return slices.ContainsFunc(tasks, func(t plan.Task) bool { return len(t.Items) > 0 }) &&
+		len(plan.IncompleteTasks(string(content))) == 0
+}
+
+// checkCompletedPlan runs before the run starts. when the plan has nothing left to implement, it ends the run
+// with ErrNothingToDo or makes the task phase skipped, as completed_plan says. a resumed run is left alone.
+func (r *Runner) checkCompletedPlan() error {
+	if r.cfg.PlanFile == "" || r.resume != nil || !r.runsTaskPhase() || !r.planCompleted() {
+		return nil
+	}
+	switch r.completedPlan() {
+	case config.CompletedPlanExit:
+		r.log.Print("all plan tasks are already completed, nothing to do")
+		return ErrNothingToDo
+	case config.CompletedPlanReview:
+		r.skipTasks = true
+	}
+	return nil
+}
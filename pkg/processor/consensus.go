@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// consensusLineDistance is how many lines apart findings of the two reviews in the same file may be
+// and still count as the same issue, reviewers point at different lines of one problem
+const consensusLineDistance = 5
+
+// locatedFinding is a line of review output referring to a file:line location
+type locatedFinding struct {
+	file string
+	line int
+	text string // the finding line as reported
+}
+
+// parseLocatedFindings returns the lines of review output outside code blocks referring to a file:line location
+func parseLocatedFindings(output string) []locatedFinding {
+	var res []locatedFinding
+	inCode := false
+	for line := range strings.SplitSeq(output, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		m := findingLocationRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue
+		}
+		res = append(res, locatedFinding{file: strings.TrimPrefix(m[1], "./"), line: n, text: strings.TrimSpace(line)})
+	}
+	return res
+}
+
+// consensusFindings splits the findings of the external review into the ones claude reported too, in the
+// same file within consensusLineDistance lines, and the rest. findings claude reported alone are returned
+// with the rest, neither review confirmed them.
+func consensusFindings(claudeOutput, extOutput string) (agreed, unconfirmed []string) {
+	claudeFindings := parseLocatedFindings(claudeOutput)
+	matched := make([]bool, len(claudeFindings))
+	for _, ef := range parseLocatedFindings(extOutput) {
+		found := false
+		for i, cf := range claudeFindings {
+			if cf.file == ef.file && cf.line-ef.line <= consensusLineDistance && ef.line-cf.line <= consensusLineDistance {
+				matched[i], found = true, true
+			}
+		}
+		if found {
+			agreed = append(agreed, ef.text)
+			continue
+		}
+		unconfirmed = append(unconfirmed, ef.text)
+	}
+	for i, cf := range claudeFindings {
+		if !matched[i] {
+			unconfirmed = append(unconfirmed, cf.text)
+		}
+	}
+	return agreed, unconfirmed
+}
+
+// consensusReview tells whether only findings both the claude and the external first review report get fixed
+func (r *Runner) consensusReview() bool {
+	return r.cfg.AppConfig != nil && r.cfg.AppConfig.ConsensusReview
+}
+
+// logUnconfirmed reports the findings left unfixed by consensus review, flagged by one reviewer only
+func (r *Runner) logUnconfirmed(unconfirmed []string) {
+	if len(unconfirmed) == 0 {
+		return
+	}
+	r.log.Print("%d findings flagged by one reviewer only, left unfixed:", len(unconfirmed))
+	for _, f := range unconfirmed {
+		r.log.PrintAligned(fmt.Sprintf("  %s", f))
+	}
+}
@@ -0,0 +1,27 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsensusFindings(t *testing.T) {
+	claude := "- a.go:12 major unchecked error\n" +
+		"- ./pkg/b.go:40 minor naming\n" +
+		"- c.go:5 missing test\n" +
+		"```\nd.go:1 in a code block\n```"
+	ext := "1. a.go:10 error from Close ignored\n" +
+		"2. pkg/b.go:60 possible nil dereference\n" +
+		"3. d.go:1 racy counter\n" +
+		"no location here"
+
+	agreed, unconfirmed := consensusFindings(claude, ext)
+	assert.Equal(t, []string{"1. a.go:10 error from Close ignored"}, agreed)
+	assert.Equal(t, []string{"2. pkg/b.go:60 possible nil dereference", "3. d.go:1 racy counter",
+		"- ./pkg/b.go:40 minor naming", "- c.go:5 missing test"}, unconfirmed)
+
+	agreed, unconfirmed = consensusFindings("", ext)
+	assert.Empty(t, agreed)
+	assert.Len(t, unconfirmed, 3)
+}
@@ -0,0 +1,101 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// crashEventLimit is how many of the last events of the run a crash report lists
+const crashEventLimit = 30
+
+// PanicError reports a run ended by a panic in ralphex itself, with the phase, step and iteration it was in,
+// the stack of the panic and the last events of the run. the checkpoint of the iteration in progress is kept,
+// so the run can be resumed.
+type PanicError struct {
+	Value      any
+	Stack      []byte
+	Phase      status.Phase
+	Step       Step
+	Iteration  int     // 1-based iteration of Step in progress
+	Events     []Event // last events of the run, oldest first
+	LastOutput string  // output of the last agent call
+}
+
+// Error returns the panic value and where the run crashed.
+func (e *PanicError) Error() string {
+	where := fmt.Sprintf("%s phase", e.Phase)
+	if e.Step != "" {
+		where += fmt.Sprintf(", %s step iteration %d", e.Step, e.Iteration)
+	}
+	return fmt.Sprintf("crashed in %s: panic: %v", where, e.Value)
+}
+
+// Report returns the crash report: the error, the stack of the panic, the last events and the last agent output.
+func (e *PanicError) Report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n===== stack =====\n%s\n", e.Error(), e.Stack)
+	fmt.Fprintf(&b, "\n===== last %d events =====\n", len(e.Events))
+	for _, ev := range e.Events {
+		fmt.Fprintf(&b, "%s %s", ev.Time.Format("15:04:05.000"), ev.Type)
+		for _, f := range []struct{ name, val string }{{"phase", string(ev.Phase)}, {"step", string(ev.Step)},
+			{"executor", ev.Executor}, {"signal", ev.Signal}} {
+			if f.val != "" {
+				fmt.Fprintf(&b, " %s=%s", f.name, f.val)
+			}
+		}
+		if ev.Iteration > 0 {
+			fmt.Fprintf(&b, " iteration=%d", ev.Iteration)
+		}
+		if ev.Err != nil {
+			fmt.Fprintf(&b, " err=%q", ev.Err.Error())
+		}
+		if ev.Text != "" {
+			fmt.Fprintf(&b, " text=%q", ev.Text)
+		}
+		b.WriteString("\n")
+	}
+	if e.LastOutput != "" {
+		fmt.Fprintf(&b, "\n===== last agent output =====\n%s\n", e.LastOutput)
+	}
+	return b.String()
+}
+
+// runRecovered runs the main loop, turning a panic of the run, or of a goroutine it passed on, into a PanicError
+func (r *Runner) runRecovered(ctx context.Context) (err error) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+		perr := &PanicError{Value: rec, Phase: r.phaseHolder.Get(), Step: r.position.Step,
+			Iteration: r.position.Iteration + 1, Events: r.recentEvents(), LastOutput: r.lastOutput}
+		if p, ok := rec.(*executor.Panic); ok {
+			perr.Value, perr.Stack = p.Value, p.Stack
+		} else {
+			perr.Stack = debug.Stack()
+		}
+		err = perr
+	}()
+	return r.run(ctx)
+}
+
+// recordEvent keeps the event for a crash report, the last crashEventLimit only. called with eventMu held.
+func (r *Runner) recordEvent(ev Event) {
+	if len(r.recentEvs) == crashEventLimit {
+		r.recentEvs = slices.Delete(r.recentEvs, 0, 1)
+	}
+	r.recentEvs = append(r.recentEvs, ev)
+}
+
+// recentEvents returns a copy of the last events of the run, oldest first
+func (r *Runner) recentEvents() []Event {
+	r.eventMu.Lock()
+	defer r.eventMu.Unlock()
+	return slices.Clone(r.recentEvs)
+}
@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"context"
+	"os"
+
+	"github.com/umputun/ralphex/pkg/debuglog"
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// debugExecutor prints the signal returned by the wrapped executor and, with prompts debugging on,
+// dumps each prompt and response to the run transcript
+type debugExecutor struct {
+	name       string
+	exec       Executor
+	debug      debuglog.Flags
+	transcript *transcript
+	log        Logger
+}
+
+// Run runs the wrapped executor, records the call in the transcript and prints the resulting signal
+func (d *debugExecutor) Run(ctx context.Context, prompt string) executor.Result {
+	res := d.exec.Run(ctx, prompt)
+	if d.transcript != nil {
+		path, err := d.transcript.write(d.name, prompt, res)
+		switch {
+		case err != nil:
+			d.log.Print("[WARN] transcript disabled: %v", err)
+		case path != "":
+			d.debug.Printf(debuglog.Prompts, "%s prompt (%d bytes), response (%d bytes) -> %s", d.name, len(prompt), len(res.Output), path)
+		}
+	}
+	if res.OutputFile != "" {
+		// the full output is in the transcript now
+		_ = os.Remove(res.OutputFile)
+		res.OutputFile = ""
+	}
+	d.debug.Printf(debuglog.Signals, "%s result signal %q", d.name, res.Signal)
+	return res
+}
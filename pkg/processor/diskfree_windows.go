@@ -0,0 +1,22 @@
+//go:build windows
+
+package processor
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// freeSpace returns the bytes available to the user on the volume of dir.
+func freeSpace(dir string) (uint64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, fmt.Errorf("free space of %s: %w", dir, err)
+	}
+	var avail, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &avail, &total, &free); err != nil {
+		return 0, fmt.Errorf("free space of %s: %w", dir, err)
+	}
+	return avail, nil
+}
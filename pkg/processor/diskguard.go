@@ -0,0 +1,95 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// diskGuard checks disk usage before iterations, see config.Config.DiskMinFreeMB and DiskMaxGrowthMB.
+// checks are best-effort, a directory which can't be measured passes.
+type diskGuard struct {
+	dirs      []string // directories whose filesystems need free space: the worktree and temp
+	root      string   // worktree measured for growth
+	minFree   uint64   // bytes, 0 disables the free space check
+	maxGrowth int64    // bytes, 0 disables the growth check
+	baseline  int64    // worktree size when the run started
+}
+
+// newDiskGuard makes a guard for the worktree in the current directory, nil if both checks are disabled.
+func (r *Runner) newDiskGuard() *diskGuard {
+	if r.cfg.AppConfig == nil || (r.cfg.AppConfig.DiskMinFreeMB <= 0 && r.cfg.AppConfig.DiskMaxGrowthMB <= 0) {
+		return nil
+	}
+	g := &diskGuard{dirs: []string{".", os.TempDir()}, root: ".",
+		minFree: uint64(max(r.cfg.AppConfig.DiskMinFreeMB, 0)) << 20, maxGrowth: int64(r.cfg.AppConfig.DiskMaxGrowthMB) << 20}
+	if g.maxGrowth > 0 {
+		g.baseline = dirSize(g.root)
+	}
+	return g
+}
+
+// check returns the problem found, empty if disk usage is fine.
+func (g *diskGuard) check() string {
+	if g.minFree > 0 {
+		for _, dir := range g.dirs {
+			free, err := freeSpace(dir)
+			if err == nil && free < g.minFree {
+				return fmt.Sprintf("%d MB free on the filesystem of %s, below %d MB", free>>20, dir, g.minFree>>20)
+			}
+		}
+	}
+	if g.maxGrowth > 0 {
+		if growth := dirSize(g.root) - g.baseline; growth > g.maxGrowth {
+			return fmt.Sprintf("worktree grew by %d MB since the run started, over %d MB", growth>>20, g.maxGrowth>>20)
+		}
+	}
+	return ""
+}
+
+// dirSize returns the total size of regular files under root, leaving out .git directories.
+func dirSize(root string) int64 {
+	var size int64
+	_ = filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr // files removed while walking don't count
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if d.Type().IsRegular() {
+			if info, infoErr := d.Info(); infoErr == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// guardDisk pauses the run while the disk guard finds a problem, notifying about it, and checks again
+// once the run is continued. without a pause to wait on the run fails instead.
+func (r *Runner) guardDisk(ctx context.Context) error {
+	if r.disk == nil {
+		return nil
+	}
+	for {
+		problem := r.disk.check()
+		if problem == "" {
+			return nil
+		}
+		if r.cfg.Pause == nil {
+			return fmt.Errorf("disk guard: %s", problem)
+		}
+		r.log.Print("[WARN] disk guard: %s, pausing the run, free space and continue it", problem)
+		if r.cfg.NeedsHuman != nil {
+			r.cfg.NeedsHuman("disk guard: " + problem)
+		}
+		r.cfg.Pause.Request()
+		if err := r.waitIfPaused(ctx); err != nil {
+			return err
+		}
+	}
+}
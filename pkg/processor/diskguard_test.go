@@ -0,0 +1,80 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskGuard_check(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), make([]byte, 1024), 0o600))
+
+	t.Run("free space", func(t *testing.T) {
+		g := &diskGuard{dirs: []string{dir}, minFree: 1 << 20}
+		assert.Empty(t, g.check())
+		g.minFree = 1 << 62
+		assert.Contains(t, g.check(), "MB free on the filesystem of "+dir)
+	})
+
+	t.Run("unmeasurable dir passes", func(t *testing.T) {
+		g := &diskGuard{dirs: []string{filepath.Join(dir, "missing")}, minFree: 1 << 62}
+		assert.Empty(t, g.check())
+	})
+
+	t.Run("growth", func(t *testing.T) {
+		g := &diskGuard{root: dir, maxGrowth: 1 << 20, baseline: dirSize(dir)}
+		assert.Equal(t, int64(1024), g.baseline)
+		assert.Empty(t, g.check())
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "big.bin"), make([]byte, 2<<20), 0o600))
+		assert.Equal(t, "worktree grew by 2 MB since the run started, over 1 MB", g.check())
+	})
+}
+
+func TestDirSize_SkipsGit(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git", "objects"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "objects", "pack"), make([]byte, 4096), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "a.go"), make([]byte, 100), 0o600))
+	assert.Equal(t, int64(100), dirSize(dir))
+}
+
+func TestRunner_guardDisk(t *testing.T) {
+	t.Run("fails without a pause", func(t *testing.T) {
+		r := &Runner{log: newMockLogger(""), disk: &diskGuard{dirs: []string{t.TempDir()}, minFree: 1 << 62}}
+		require.ErrorContains(t, r.guardDisk(context.Background()), "disk guard: ")
+	})
+
+	t.Run("pauses and checks again", func(t *testing.T) {
+		log := newMockLogger("")
+		var reasons []string
+		pause := NewPause()
+		g := &diskGuard{dirs: []string{t.TempDir()}, minFree: 1 << 62}
+		r := &Runner{log: log, disk: g, cfg: Config{Pause: pause, NeedsHuman: func(reason string) {
+			reasons = append(reasons, reason)
+		}}}
+
+		done := make(chan error, 1)
+		go func() { done <- r.guardDisk(context.Background()) }()
+		require.Eventually(t, func() bool { return pause.requested() != nil }, time.Second, time.Millisecond)
+		g.minFree = 1 // disk space freed
+		pause.Continue()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("guard didn't continue")
+		}
+		require.Len(t, reasons, 1)
+		assert.True(t, strings.HasPrefix(reasons[0], "disk guard: "))
+		require.NotEmpty(t, log.PrintCalls())
+		assert.True(t, strings.HasPrefix(log.PrintCalls()[0].Format, "[WARN] disk guard:"))
+	})
+}
@@ -0,0 +1,101 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// dryRunFindings stands in for external review output in evaluation prompts rendered by a dry run
+const dryRunFindings = "<external review findings would be inserted here>"
+
+// dryRunPrompt is a prompt the pipeline would send, with the phase it belongs to
+type dryRunPrompt struct {
+	phase status.Phase
+	label string // section label, e.g. "claude review 0: all findings"
+	agent string
+	text  string
+}
+
+// runDryRun walks the pipeline of the configured mode and logs every prompt it would send,
+// without calling executors or changing anything. iterations of a phase repeat the same prompt,
+// so each distinct prompt is shown once.
+func (r *Runner) runDryRun() error {
+	prompts, err := r.dryRunPrompts()
+	if err != nil {
+		return err
+	}
+	r.log.PrintRaw("dry run: rendering prompts for %s mode, no executor will be called\n", r.cfg.Mode)
+	for _, p := range prompts {
+		r.phaseHolder.Set(p.phase)
+		r.log.PrintSection(status.NewGenericSection("dry run: " + p.label + " (" + p.agent + ")"))
+		r.log.PrintRaw("%s\n", p.text)
+	}
+	r.log.Print("dry run complete, %d prompts rendered", len(prompts))
+	return nil
+}
+
+// dryRunPrompts renders prompts of the configured mode in pipeline order
+func (r *Runner) dryRunPrompts() ([]dryRunPrompt, error) {
+	var res []dryRunPrompt
+	add := func(phase status.Phase, label, agent, text string) {
+		res = append(res, dryRunPrompt{phase: phase, label: label, agent: agent, text: text})
+	}
+
+	if r.cfg.Mode == ModeFull && len(r.cfg.Phases) > 0 {
+		return r.dryRunPipelinePrompts()
+	}
+
+	switch r.cfg.Mode {
+	case ModePlan:
+		if r.cfg.PlanDescription == "" {
+			return nil, errors.New("plan description required for plan mode")
+		}
+		add(status.PhasePlan, "plan iteration", "claude", r.buildPlanPrompt())
+		return res, nil
+	case ModeFull, ModeTasksOnly:
+		if r.cfg.PlanFile == "" {
+			return nil, fmt.Errorf("plan file required for %s mode", r.cfg.Mode)
+		}
+		prompt, _, err := r.taskPrompt()
+		if err != nil {
+			return nil, err
+		}
+		add(status.PhaseTask, "task iteration", "claude", prompt)
+		if r.cfg.Mode == ModeTasksOnly {
+			return res, nil
+		}
+	case ModeReview, ModeCodexOnly:
+	default:
+		return nil, fmt.Errorf("unknown mode: %s", r.cfg.Mode)
+	}
+
+	// the critical/major review prompt runs both before and after the external review, shown at its first use
+	secondReview := r.reviewPrompt(r.cfg.AppConfig.ReviewSecondPrompt)
+	if r.cfg.Mode != ModeCodexOnly && !r.cfg.SkipFirstReview {
+		add(status.PhaseReview, "claude review 0: all findings", "claude", r.reviewPrompt(r.cfg.AppConfig.ReviewFirstPrompt))
+		add(status.PhaseReview, "claude review: critical/major", "claude", secondReview)
+	}
+
+	tool := r.externalReviewTool()
+	if r.cfg.SkipCodex {
+		tool = "none"
+	}
+	switch tool {
+	case "codex":
+		add(status.PhaseCodex, "codex review", "codex", r.buildCodexPrompt(true, ""))
+		add(status.PhaseClaudeEval, "claude evaluating codex findings", "claude", r.buildCodexEvaluationPrompt(dryRunFindings))
+	case "custom":
+		add(status.PhaseCodex, "custom review", "custom", r.buildCustomReviewPrompt(true, ""))
+		add(status.PhaseClaudeEval, "claude evaluating custom review findings", "claude", r.buildCustomEvaluationPrompt(dryRunFindings))
+	}
+
+	if (r.cfg.Mode == ModeCodexOnly || r.cfg.SkipFirstReview) && !r.cfg.SkipSecondReview {
+		add(status.PhaseReview, "claude review: critical/major", "claude", secondReview)
+	}
+	if r.cfg.FinalizeEnabled {
+		add(status.PhaseFinalize, "finalize step", "claude", r.replacePromptVariables(r.cfg.AppConfig.FinalizePrompt))
+	}
+	return res, nil
+}
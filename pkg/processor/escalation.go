@@ -0,0 +1,132 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// PhaseEscalation is the escalation review run when a verification pass of the external review still
+// reports findings after the post-codex review, see config.Config.EscalationReview
+const PhaseEscalation = "escalation"
+
+// escalationExecutors returns copies of claude and codex with the escalation settings of appCfg, nil
+// executors where none are set. escalation claude args are used as configured, not adjusted to the mode.
+func escalationExecutors(appCfg *config.Config, claude *executor.ClaudeExecutor, codex *executor.CodexExecutor) Executors {
+	var res Executors
+	if appCfg.EscalationClaudeArgs != "" {
+		c := *claude
+		c.Args = appCfg.EscalationClaudeArgs
+		res.Claude = &c
+	}
+	if appCfg.EscalationCodexModel != "" || appCfg.EscalationCodexReasoningEffort != "" {
+		c := *codex
+		if appCfg.EscalationCodexModel != "" {
+			c.Model = appCfg.EscalationCodexModel
+		}
+		if appCfg.EscalationCodexReasoningEffort != "" {
+			c.ReasoningEffort = appCfg.EscalationCodexReasoningEffort
+		}
+		res.Codex = &c
+	}
+	return res
+}
+
+// withPhaseExecutors runs fn with the executor overrides of phase in place of the default executors.
+func (r *Runner) withPhaseExecutors(phase string, fn func() error) error {
+	execs, ok := r.phaseExecutors[phase]
+	if !ok {
+		return fn()
+	}
+	claude, codex := r.claude, r.codex
+	defer func() { r.claude, r.codex = claude, codex }()
+	if execs.Claude != nil {
+		r.claude = execs.Claude
+	}
+	if execs.Codex != nil {
+		r.codex = execs.Codex
+	}
+	return fn()
+}
+
+// runEscalation runs a verification pass of the external review after the external review rounds. if it
+// still reports findings, the escalation review has claude fix them with the escalation executors and
+// another pass checks the result. findings left after that are logged, the run goes on.
+// does nothing unless config.Config.EscalationReview is set and an external review is enabled.
+func (r *Runner) runEscalation(ctx context.Context) error {
+	if r.cfg.AppConfig == nil || !r.cfg.AppConfig.EscalationReview {
+		return nil
+	}
+	if r.resume != nil && r.resume.Step == StepFinalize {
+		return nil // a run resumed at finalize has completed the reviews
+	}
+	tool := r.externalReviewTool()
+	if tool == "none" {
+		return nil
+	}
+
+	output, err := r.verificationPass(ctx, tool, "verification")
+	if err != nil || output == "" {
+		return err
+	}
+
+	return r.withPhaseExecutors(PhaseEscalation, func() error {
+		cfg, err := r.externalReview(tool)
+		if err != nil {
+			return err
+		}
+		r.log.Print("%s still reports %d findings after the review, escalating...", cfg.name, len(ParseFindings(output)))
+		r.phaseHolder.Set(status.PhaseReview)
+		r.log.PrintSection(status.NewGenericSection("escalation review"))
+		mark := r.diffMark(config.ShowDiffPhase)
+		result := r.claude.Run(ctx, cfg.buildEvalPrompt(output))
+		if result.Error != nil {
+			if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
+				return err
+			}
+			return fmt.Errorf("escalation review: claude execution: %w", result.Error)
+		}
+		r.recordDismissed(result.Output)
+		r.showDiff(mark, "escalation review")
+
+		left, err := r.verificationPass(ctx, tool, "escalation verification")
+		if err != nil {
+			return err
+		}
+		if left != "" {
+			r.log.Print("[WARN] %s still reports %d findings after the escalation review, continuing...",
+				cfg.name, len(ParseFindings(left)))
+			return nil
+		}
+		r.log.Print("escalation review resolved the remaining findings")
+		return nil
+	})
+}
+
+// verificationPass runs a single external review iteration without fixes and returns its output
+// if it reports findings, empty if it found nothing.
+func (r *Runner) verificationPass(ctx context.Context, tool, label string) (string, error) {
+	cfg, err := r.externalReview(tool)
+	if err != nil {
+		return "", err
+	}
+	r.phaseHolder.Set(status.PhaseCodex)
+	r.log.PrintSection(status.NewGenericSection(fmt.Sprintf("%s %s pass", cfg.name, label)))
+	result := cfg.runReview(ctx, cfg.buildPrompt(true, ""))
+	if result.Error != nil {
+		if err := r.handlePatternMatchError(result.Error, cfg.name); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("%s %s pass: %w", cfg.name, label, result.Error)
+	}
+	if len(ParseFindings(result.Output)) == 0 {
+		r.log.Print("%s %s pass found no issues", cfg.name, label)
+		return "", nil
+	}
+	cfg.showSummary(result.Output)
+	r.recordFindings(result.Output)
+	return result.Output, nil
+}
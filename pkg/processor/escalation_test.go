@@ -0,0 +1,45 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+)
+
+func TestEscalationExecutors(t *testing.T) {
+	claude := &executor.ClaudeExecutor{Command: "codex", Args: "exec -c model_reasoning_effort=high"}
+	codex := &executor.CodexExecutor{Command: "codex", Model: "gpt-5.3-codex", ReasoningEffort: "high"}
+
+	assert.Equal(t, Executors{}, escalationExecutors(&config.Config{EscalationReview: true}, claude, codex), "nothing to override")
+
+	execs := escalationExecutors(&config.Config{EscalationClaudeArgs: "exec -c model_reasoning_effort=xhigh",
+		EscalationCodexReasoningEffort: "xhigh"}, claude, codex)
+	require.IsType(t, &executor.ClaudeExecutor{}, execs.Claude)
+	assert.Equal(t, "exec -c model_reasoning_effort=xhigh", execs.Claude.(*executor.ClaudeExecutor).Args)
+	assert.Equal(t, "codex", execs.Claude.(*executor.ClaudeExecutor).Command)
+	require.IsType(t, &executor.CodexExecutor{}, execs.Codex)
+	assert.Equal(t, "gpt-5.3-codex", execs.Codex.(*executor.CodexExecutor).Model)
+	assert.Equal(t, "xhigh", execs.Codex.(*executor.CodexExecutor).ReasoningEffort)
+	assert.Equal(t, "exec -c model_reasoning_effort=high", claude.Args, "original unchanged")
+	assert.Equal(t, "high", codex.ReasoningEffort, "original unchanged")
+}
+
+func TestRunner_withPhaseExecutors(t *testing.T) {
+	claude, codex, override := &mocks.ExecutorMock{}, &mocks.ExecutorMock{}, &mocks.ExecutorMock{}
+	r := &Runner{claude: claude, codex: codex, phaseExecutors: map[string]Executors{PhaseEscalation: {Claude: override}}}
+	require.NoError(t, r.withPhaseExecutors(PhaseEscalation, func() error {
+		assert.Same(t, override, r.claude)
+		assert.Same(t, codex, r.codex, "no codex override")
+		return nil
+	}))
+	assert.Same(t, claude, r.claude, "restored")
+	require.NoError(t, r.withPhaseExecutors("other", func() error {
+		assert.Same(t, claude, r.claude)
+		return nil
+	}))
+}
@@ -0,0 +1,55 @@
+package processor
+
+import (
+	"time"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// EventType is the type of a run event, see Runner.SetEventHandler.
+type EventType string
+
+// event types reported to the event handler.
+const (
+	EventPhaseStarted     EventType = "phase_started"     // a phase starts, Phase is set
+	EventIterationStarted EventType = "iteration_started" // an iteration of a step starts, Step and Iteration are set
+	EventExecutorOutput   EventType = "executor_output"   // an agent printed a piece of output, Executor and Text are set
+	EventSignalDetected   EventType = "signal_detected"   // an agent call ended with a signal, Executor and Signal are set
+	EventPhaseCompleted   EventType = "phase_completed"   // a phase ended, Err is set if it failed
+	EventRunFinished      EventType = "run_finished"      // the run ended, Report is set, Err if it failed
+)
+
+// Event is a run event for tools embedding the runner with their own UI.
+type Event struct {
+	Type      EventType
+	Time      time.Time
+	Phase     status.Phase // phase in progress, or the phase started or completed
+	Step      Step         // step of an iteration
+	Iteration int          // 1-based iteration of the step
+	Executor  string       // agent of output and signals: claude, codex, custom or the external review tool
+	Text      string
+	Signal    string
+	Err       error
+	Report    *RunReport
+}
+
+// SetEventHandler sets the handler receiving the events of the run. events are delivered one at a time,
+// in order, from the goroutine the event happened in, so the handler should return quickly.
+func (r *Runner) SetEventHandler(h func(Event)) {
+	r.eventHandler = h
+}
+
+// emit delivers an event to the handler, filling the time and the phase in progress if not set.
+// the last events are kept for a crash report with or without a handler, see PanicError.
+func (r *Runner) emit(ev Event) {
+	ev.Time = time.Now()
+	if ev.Phase == "" && r.phaseHolder != nil {
+		ev.Phase = r.phaseHolder.Get()
+	}
+	r.eventMu.Lock()
+	defer r.eventMu.Unlock()
+	r.recordEvent(ev)
+	if r.eventHandler != nil {
+		r.eventHandler(ev)
+	}
+}
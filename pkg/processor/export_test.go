@@ -26,3 +26,16 @@ func (r *Runner) TestHasUncompletedTasks() bool {
 func (r *Runner) TestBuildCodexPrompt(isFirst bool, claudeResponse string) string {
 	return r.buildCodexPrompt(isFirst, claudeResponse)
 }
+
+// TestSetNow replaces the clock of the run window checks.
+func (r *Runner) TestSetNow(now func() time.Time) {
+	r.now = now
+}
+
+// SetCheckpointMigrations replaces the checkpoint format migrations, the format written follows them.
+// the returned function restores the original ones.
+func SetCheckpointMigrations(migrations []func(cp map[string]any) error) (restore func()) {
+	orig := checkpointMigrations
+	checkpointMigrations = migrations
+	return func() { checkpointMigrations = orig }
+}
@@ -0,0 +1,48 @@
+package processor
+
+import (
+	"context"
+	"errors"
+)
+
+// VerificationReport is the result of the final verification pass, see config.Config.FinalVerification.
+type VerificationReport struct {
+	Tool     string    `json:"tool"`               // external review tool which ran the pass
+	Residual []Finding `json:"residual,omitempty"` // findings left unfixed, empty if the pass found nothing
+}
+
+// runFinalVerification runs a verification pass of the external review on the final diff, before finalize.
+// nothing is fixed, the findings it reports are logged and recorded in the run report. like finalize it is
+// best-effort: a failed pass is logged and the run goes on, context cancellation is propagated.
+// does nothing unless config.Config.FinalVerification is set and an external review is enabled.
+func (r *Runner) runFinalVerification(ctx context.Context) error {
+	if r.cfg.AppConfig == nil || !r.cfg.AppConfig.FinalVerification {
+		return nil
+	}
+	if r.resume != nil && r.resume.Step == StepFinalize {
+		return nil // a run resumed at finalize has completed the reviews
+	}
+	tool := r.externalReviewTool()
+	if tool == "none" {
+		return nil
+	}
+
+	output, err := r.verificationPass(ctx, tool, "final verification")
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		r.log.Print("[WARN] final verification failed, continuing: %v", err)
+		return nil
+	}
+	residual := ParseFindings(output)
+	r.report.FinalVerification = &VerificationReport{Tool: tool, Residual: residual}
+	if len(residual) == 0 {
+		return nil
+	}
+	r.log.Print("[WARN] final verification still reports %d findings, left unfixed:", len(residual))
+	for _, f := range residual {
+		r.log.Print("  %s: %s", f.File, f.Message)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package processor
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+// findingLocationRe matches a file:line reference, optionally with a line range or column
+var findingLocationRe = regexp.MustCompile("(?:^|[\\s(\\[`'\"])([\\w./-]+\\.[A-Za-z0-9]+):(\\d+)(?:[-:]\\d+)?[`'\")\\]]?")
+
+// findingMarkerRe matches list markers and severity tags at the start of a finding line
+var findingMarkerRe = regexp.MustCompile(`^(?:\*\*[A-Za-z0-9 ]+\*\*:?|[-*•]|\d+[.)]|\[[A-Za-z0-9 ]+\]|\s)+`)
+
+// dismissedPrefix marks a finding the evaluation of external review output dismissed as invalid
+const dismissedPrefix = "DISMISSED:"
+
+// skippedPrefix marks a valid finding the evaluation left unfixed, followed by its location and the reason
+const skippedPrefix = "SKIPPED:"
+
+// Finding is an issue reported by the external review, identified by its file and message.
+// the line is left out, it shifts as code around the issue changes.
+type Finding struct {
+	File    string `json:"file"`
+	Message string `json:"message"`
+}
+
+// findingSeverityRe matches the first severity word of a finding line
+var findingSeverityRe = regexp.MustCompile(`(?i)\b(critical|blocker|p0|major|high|important|p1|medium|moderate|p2|` +
+	`minor|low|p3|info|informational|nit|nitpick|note|suggestion|style)\b`)
+
+// ratedFinding is a finding with the severity it was reported with, an index into config.Severities
+type ratedFinding struct {
+	Finding
+	severity int
+}
+
+// ParseFindings extracts findings from external review output: every line outside code blocks
+// referring to a file:line location, with list markers, severity tags and the location removed
+// from the message. lines with nothing left besides the location are skipped.
+func ParseFindings(output string) []Finding {
+	rated := parseRatedFindings(output)
+	if len(rated) == 0 {
+		return nil
+	}
+	res := make([]Finding, 0, len(rated))
+	for _, f := range rated {
+		res = append(res, f.Finding)
+	}
+	return res
+}
+
+// parseRatedFindings extracts findings like ParseFindings, along with the severity of each, see findingSeverity
+func parseRatedFindings(output string) []ratedFinding {
+	var res []ratedFinding
+	inCode := false
+	for line := range strings.SplitSeq(output, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		loc := findingLocationRe.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+		file := line[loc[2]:loc[3]]
+		msg := line[:loc[0]] + " " + line[loc[1]:]
+		msg = findingMarkerRe.ReplaceAllString(strings.TrimSpace(msg), "")
+		msg = strings.Trim(strings.Join(strings.Fields(msg), " "), " -:—–,.")
+		if msg == "" {
+			continue
+		}
+		f := Finding{File: strings.TrimPrefix(file, "./"), Message: msg}
+		if !slices.ContainsFunc(res, func(rf ratedFinding) bool { return rf.Finding == f }) {
+			res = append(res, ratedFinding{Finding: f, severity: findingSeverity(line)})
+		}
+	}
+	return res
+}
+
+// findingSeverity returns the severity of a finding line by its first severity word, e.g. "[P1]" or "**Minor**".
+// a line without one rates as major, so unrated findings never count as negligible.
+func findingSeverity(line string) int {
+	m := findingSeverityRe.FindStringSubmatch(line)
+	if m == nil {
+		return slices.Index(config.Severities, config.SeverityMajor)
+	}
+	var sev string
+	switch strings.ToLower(m[1]) {
+	case "critical", "blocker", "p0":
+		sev = config.SeverityCritical
+	case "minor", "low", "p3":
+		sev = config.SeverityMinor
+	case "info", "informational", "nit", "nitpick", "note", "suggestion", "style":
+		sev = config.SeverityInfo
+	default:
+		sev = config.SeverityMajor
+	}
+	return slices.Index(config.Severities, sev)
+}
+
+// postReviewSkip tells whether the claude review after an external review can be skipped for the findings
+// the external review reported, per post_review_skip_severity and post_review_skip_findings. reason explains the decision.
+func postReviewSkip(cfg *config.Config, findings []ratedFinding) (skip bool, reason string) {
+	if cfg == nil {
+		return false, ""
+	}
+	if cfg.PostReviewSkipFindings > 0 && len(findings) < cfg.PostReviewSkipFindings {
+		return true, fmt.Sprintf("%d findings, fewer than %d", len(findings), cfg.PostReviewSkipFindings)
+	}
+	threshold := slices.Index(config.Severities, cfg.PostReviewSkipSeverity)
+	if threshold < 0 {
+		return false, ""
+	}
+	for _, f := range findings {
+		if f.severity >= threshold {
+			return false, ""
+		}
+	}
+	return true, fmt.Sprintf("%d findings, all below %s severity", len(findings), cfg.PostReviewSkipSeverity)
+}
+
+// ParseDismissed extracts findings the evaluation of external review output dismissed as invalid,
+// listed by the evaluation prompts one per line as "DISMISSED: <file>:<line> <finding>".
+func ParseDismissed(output string) []Finding {
+	var lines []string
+	for line := range strings.SplitSeq(output, "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimLeft(line, " \t-*"), dismissedPrefix); ok {
+			lines = append(lines, rest)
+		}
+	}
+	return ParseFindings(strings.Join(lines, "\n"))
+}
+
+// findingLocations returns the distinct file:line locations referred to by lines of output outside code blocks
+func findingLocations(output string) []string {
+	var res []string
+	inCode := false
+	for line := range strings.SplitSeq(output, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		if m := findingLocationRe.FindStringSubmatch(line); m != nil {
+			loc := strings.TrimPrefix(m[1], "./") + ":" + m[2]
+			if !slices.Contains(res, loc) {
+				res = append(res, loc)
+			}
+		}
+	}
+	return res
+}
+
+// explainedLocations returns the locations of findings the evaluation output dismissed or skipped with a reason
+func explainedLocations(output string) []string {
+	var res []string
+	for line := range strings.SplitSeq(output, "\n") {
+		line = strings.TrimLeft(line, " \t-*")
+		rest, ok := strings.CutPrefix(line, dismissedPrefix)
+		if !ok {
+			rest, ok = strings.CutPrefix(line, skippedPrefix)
+		}
+		if !ok {
+			continue
+		}
+		loc := findingLocationRe.FindStringSubmatchIndex(rest)
+		if loc == nil || strings.TrimSpace(rest[loc[1]:]) == "" {
+			continue // no location or no reason
+		}
+		res = append(res, strings.TrimPrefix(rest[loc[2]:loc[3]], "./")+":"+rest[loc[4]:loc[5]])
+	}
+	return res
+}
+
+// diffFiles returns the files changed by a git diff, by their paths before and after the change
+func diffFiles(diff string) []string {
+	var res []string
+	for line := range strings.SplitSeq(diff, "\n") {
+		for _, prefix := range []string{"--- a/", "+++ b/"} {
+			if file, ok := strings.CutPrefix(line, prefix); ok && !slices.Contains(res, file) {
+				res = append(res, file)
+			}
+		}
+	}
+	return res
+}
+
+// unresolvedFindings returns locations of findings in review output the evaluation neither fixed, by changing
+// the file in diff, nor explained with a DISMISSED or SKIPPED line in response.
+func unresolvedFindings(review, response, diff string) []string {
+	changed, explained := diffFiles(diff), explainedLocations(response)
+	var res []string
+	for _, loc := range findingLocations(review) {
+		file := loc[:strings.LastIndex(loc, ":")]
+		if slices.Contains(changed, file) || slices.Contains(explained, loc) {
+			continue
+		}
+		res = append(res, loc)
+	}
+	return res
+}
+
+// Findings returns the distinct findings reported by the external review during the run.
+func (r *Runner) Findings() []Finding {
+	return r.findings
+}
+
+// Dismissed returns the distinct findings dismissed as invalid by the evaluation of external review output.
+func (r *Runner) Dismissed() []Finding {
+	return r.dismissed
+}
+
+// recordFindings adds the findings of an external review output to the run's findings and to the findings
+// of the current external review loop
+func (r *Runner) recordFindings(output string) {
+	r.findings = appendFindings(r.findings, ParseFindings(output))
+	for _, f := range parseRatedFindings(output) {
+		if !slices.ContainsFunc(r.loopFindings, func(rf ratedFinding) bool { return rf.Finding == f.Finding }) {
+			r.loopFindings = append(r.loopFindings, f)
+		}
+	}
+}
+
+// recordDismissed adds the findings an evaluation dismissed to the run's dismissed findings
+func (r *Runner) recordDismissed(output string) {
+	r.dismissed = appendFindings(r.dismissed, ParseDismissed(output))
+}
+
+// appendFindings appends findings missing from dst
+func appendFindings(dst, findings []Finding) []Finding {
+	for _, f := range findings {
+		if !slices.Contains(dst, f) {
+			dst = append(dst, f)
+		}
+	}
+	return dst
+}
+
+// baselineNote lists the accepted findings of the project baseline for the external review, empty without any
+func baselineNote(baseline []Finding) string {
+	if len(baseline) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\n---\nACCEPTED FINDINGS:\nThese findings were triaged as false positives before. Do not report them again:\n")
+	for _, f := range baseline {
+		fmt.Fprintf(&sb, "- %s: %s\n", f.File, f.Message)
+	}
+	return sb.String()
+}
@@ -0,0 +1,124 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+func TestParseFindings(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []Finding
+	}{
+		{name: "empty", output: "", want: nil},
+		{name: "no location", output: "NO ISSUES FOUND", want: nil},
+		{name: "plain", output: "found issue in foo.go:10",
+			want: []Finding{{File: "foo.go", Message: "found issue in"}}},
+		{name: "list with severity", output: "Findings:\n- [P1] pkg/a/b.go:12-15: missing error check\n" +
+			"2. **Major**: `./cmd/main.go:7` unused variable x.",
+			want: []Finding{{File: "pkg/a/b.go", Message: "missing error check"},
+				{File: "cmd/main.go", Message: "unused variable x"}}},
+		{name: "location only", output: "- pkg/a/b.go:12", want: nil},
+		{name: "duplicates collapse", output: "- a.go:1 nil deref\n- a.go:20 nil deref",
+			want: []Finding{{File: "a.go", Message: "nil deref"}}},
+		{name: "code blocks skipped", output: "- a.go:1 bad\n```\nb.go:2 panic\n```\n",
+			want: []Finding{{File: "a.go", Message: "bad"}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ParseFindings(tc.output))
+		})
+	}
+}
+
+func TestParseDismissed(t *testing.T) {
+	output := "a.go:1 is fixed now\n" +
+		"The second finding doesn't apply, the value is never nil.\n" +
+		"DISMISSED: b.go:20 possible nil dereference\n" +
+		"- DISMISSED: c.go:3: unused parameter\n" +
+		"DISMISSED: no location"
+	assert.Equal(t, []Finding{{File: "b.go", Message: "possible nil dereference"}, {File: "c.go", Message: "unused parameter"}},
+		ParseDismissed(output))
+	assert.Empty(t, ParseDismissed("all fixed"))
+}
+
+func TestPostReviewSkip(t *testing.T) {
+	output := "- [P3] a.go:1 long function\n- **Nit**: b.go:2 naming\n- c.go:3 `err` shadowed, minor"
+	findings := parseRatedFindings(output)
+	require.Len(t, findings, 3)
+	assert.Equal(t, []int{1, 0, 1}, []int{findings[0].severity, findings[1].severity, findings[2].severity})
+
+	tests := []struct {
+		name       string
+		severity   string
+		count      int
+		findings   []ratedFinding
+		wantSkip   bool
+		wantReason string
+	}{
+		{name: "disabled", findings: findings},
+		{name: "none", severity: "none", findings: findings},
+		{name: "all below major", severity: config.SeverityMajor, findings: findings, wantSkip: true,
+			wantReason: "3 findings, all below major severity"},
+		{name: "minor not below minor", severity: config.SeverityMinor, findings: findings},
+		{name: "untagged counts as major", severity: config.SeverityMajor,
+			findings: parseRatedFindings("- d.go:4 possible race")},
+		{name: "critical", severity: config.SeverityCritical,
+			findings: parseRatedFindings("- [blocker] d.go:4 possible race")},
+		{name: "fewer findings", count: 4, findings: findings, wantSkip: true, wantReason: "3 findings, fewer than 4"},
+		{name: "enough findings", count: 3, findings: findings},
+		{name: "clean", severity: config.SeverityInfo, wantSkip: true, wantReason: "0 findings, all below info severity"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{PostReviewSkipSeverity: tc.severity, PostReviewSkipFindings: tc.count}
+			skip, reason := postReviewSkip(cfg, tc.findings)
+			assert.Equal(t, tc.wantSkip, skip)
+			assert.Equal(t, tc.wantReason, reason)
+		})
+	}
+}
+
+func TestUnresolvedFindings(t *testing.T) {
+	review := "1. a.go:10 - unchecked error\n" +
+		"2. pkg/b.go:20: possible nil dereference\n" +
+		"3. ./c.go:30 unused parameter\n" +
+		"4. d.go:40 racy counter\n" +
+		"```\ne.go:1 in a code block\n```"
+	diff := " a.go | 2 +-\n--- a/a.go\n+++ b/a.go\n@@ -10 +10 @@\n-x\n+y\n"
+
+	tests := []struct {
+		name     string
+		response string
+		diff     string
+		want     []string
+	}{
+		{name: "nothing resolved", want: []string{"a.go:10", "pkg/b.go:20", "c.go:30", "d.go:40"}},
+		{name: "fixed by diff", diff: diff, want: []string{"pkg/b.go:20", "c.go:30", "d.go:40"}},
+		{name: "all resolved", diff: diff,
+			response: "DISMISSED: pkg/b.go:20 never nil\n- SKIPPED: c.go:30 part of the public API\nSKIPPED: ./d.go:40: out of scope"},
+		{name: "skipped without reason", diff: diff, response: "DISMISSED: pkg/b.go:20 never nil\nSKIPPED: c.go:30\n" +
+			"SKIPPED: d.go:41 other line", want: []string{"c.go:30", "d.go:40"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, unresolvedFindings(review, tc.response, tc.diff))
+		})
+	}
+}
+
+func TestRunner_buildCodexPrompt_Baseline(t *testing.T) {
+	r := &Runner{cfg: Config{AppConfig: &config.Config{}}}
+	assert.NotContains(t, r.buildCodexPrompt(true, ""), "ACCEPTED FINDINGS")
+
+	r.cfg.Baseline = []Finding{{File: "a.go", Message: "error not checked"}}
+	assert.Contains(t, r.buildCodexPrompt(true, ""), "ACCEPTED FINDINGS:\nThese findings were triaged as false positives before. "+
+		"Do not report them again:\n- a.go: error not checked\n")
+	r.cfg.AppConfig.CustomReviewPrompt = "review {{DIFF_INSTRUCTION}}"
+	assert.Contains(t, r.buildCustomReviewPrompt(true, ""), "- a.go: error not checked\n")
+}
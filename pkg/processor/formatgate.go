@@ -0,0 +1,60 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/verify"
+)
+
+// formatCommitMsg is the message of the commit with the files formatted by the formatting gate in fix mode
+const formatCommitMsg = "style: format changed files"
+
+// formatGateOn reports if the formatting gate runs after task iterations, see config.Config.FormatGate.
+func (r *Runner) formatGateOn() bool {
+	if r.cfg.AppConfig == nil || len(r.cfg.AppConfig.Formatters) == 0 {
+		return false
+	}
+	mode := r.cfg.AppConfig.FormatGate
+	return mode == config.FormatGateFix || mode == config.FormatGateFeedback
+}
+
+// runFormatGate runs the formatters on the files changed on the branch after a task iteration. in fix mode
+// the formatted files are committed, in feedback mode they are restored and returned as feedback for the
+// next task iteration. returns empty feedback if the files are formatted or the gate is off.
+func (r *Runner) runFormatGate(ctx context.Context) (string, error) {
+	if !r.formatGateOn() {
+		return "", nil
+	}
+	files, err := r.changedFiles()
+	if err != nil {
+		r.log.Print("[WARN] formatting gate: %v", err)
+		return "", nil
+	}
+	fix := r.cfg.AppConfig.FormatGate == config.FormatGateFix
+	gate := verify.FormatGate{Shell: verify.SelectShell(r.cfg.AppConfig.VerifyShell, runtime.GOOS),
+		Formatters: r.cfg.AppConfig.Formatters, Fix: fix}
+	changed, problems, err := gate.Check(ctx, files)
+	if err != nil {
+		return "", fmt.Errorf("formatting gate: %w", err)
+	}
+	for _, p := range problems {
+		r.log.Print("[WARN] formatting gate: %s", p)
+	}
+	if len(changed) == 0 {
+		return verify.FormatFeedback(nil, problems), nil
+	}
+	if !fix {
+		r.log.Print("[WARN] formatting gate: %d files not formatted: %s", len(changed), strings.Join(changed, ", "))
+		return verify.FormatFeedback(changed, problems), nil
+	}
+	if err := r.git.CommitFiles(changed, formatCommitMsg); err != nil {
+		r.log.Print("[WARN] formatting gate: %v", err)
+		return verify.FormatFeedback(nil, problems), nil
+	}
+	r.log.Print("formatted and committed %d files: %s", len(changed), strings.Join(changed, ", "))
+	return verify.FormatFeedback(nil, problems), nil
+}
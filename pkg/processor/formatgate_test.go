@@ -0,0 +1,91 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+)
+
+func TestRunner_runFormatGate(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("tr not in PATH")
+	}
+	// squeezes repeated spaces of the files given as arguments
+	const squeeze = `sh -c 'for f do tr -s " " < "$f" > "$f.tmp" && mv "$f.tmp" "$f"; done' fmt`
+	setup := func(t *testing.T, mode string) (*Runner, *mocks.GitCheckerMock) {
+		t.Helper()
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile("a.go", []byte("package  a\n"), 0o600))
+		require.NoError(t, os.WriteFile("b.go", []byte("package b\n"), 0o600))
+		require.NoError(t, os.WriteFile("c.txt", []byte("not  formatted\n"), 0o600))
+		gitMock := &mocks.GitCheckerMock{
+			ChangedFilesFunc: func(string) ([]string, error) { return []string{"a.go", "b.go", "c.txt", "deleted.go"}, nil },
+			CommitFilesFunc:  func([]string, string) error { return nil },
+		}
+		r := &Runner{log: newMockLogger(""), git: gitMock, cfg: Config{AppConfig: &config.Config{FormatGate: mode,
+			Formatters: map[string]string{"go": squeeze}}}}
+		return r, gitMock
+	}
+	readFile := func(t *testing.T, name string) string {
+		t.Helper()
+		data, err := os.ReadFile(name) //nolint:gosec // test file
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("off", func(t *testing.T) {
+		r, gitMock := setup(t, config.FormatGateOff)
+		feedback, err := r.runFormatGate(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+		assert.Empty(t, gitMock.ChangedFilesCalls())
+	})
+
+	t.Run("feedback", func(t *testing.T) {
+		r, gitMock := setup(t, config.FormatGateFeedback)
+		feedback, err := r.runFormatGate(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "formatting gate failed:\n- these files are not formatted, run the formatter on them "+
+			"and commit the result:\n  a.go", feedback)
+		assert.Equal(t, "package  a\n", readFile(t, "a.go"), "restored")
+		assert.Empty(t, gitMock.CommitFilesCalls())
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		r, gitMock := setup(t, config.FormatGateFix)
+		feedback, err := r.runFormatGate(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+		assert.Equal(t, "package a\n", readFile(t, "a.go"))
+		assert.Equal(t, "not  formatted\n", readFile(t, "c.txt"), "no formatter for txt")
+		require.Len(t, gitMock.CommitFilesCalls(), 1)
+		assert.Equal(t, []string{"a.go"}, gitMock.CommitFilesCalls()[0].Files)
+		assert.Equal(t, formatCommitMsg, gitMock.CommitFilesCalls()[0].Msg)
+	})
+
+	t.Run("failing formatter", func(t *testing.T) {
+		r, gitMock := setup(t, config.FormatGateFix)
+		r.cfg.AppConfig.Formatters = map[string]string{"go": "echo bad syntax >&2; exit 2"}
+		feedback, err := r.runFormatGate(context.Background())
+		require.NoError(t, err)
+		assert.Contains(t, feedback, "formatting gate failed:\n- run \"echo bad syntax")
+		assert.Contains(t, feedback, "bad syntax")
+		assert.Empty(t, gitMock.CommitFilesCalls())
+	})
+
+	t.Run("changed files unavailable", func(t *testing.T) {
+		r, gitMock := setup(t, config.FormatGateFeedback)
+		gitMock.ChangedFilesFunc = func(string) ([]string, error) { return nil, errors.New("no repo") }
+		feedback, err := r.runFormatGate(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+	})
+}
@@ -0,0 +1,39 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"runtime"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/verify"
+)
+
+// runGenerateGate re-runs the code generators once all tasks are completed. returns feedback for the next
+// task iteration, empty if the generated files are up to date or the gate is off. in fail mode drift is
+// returned as error.
+func (r *Runner) runGenerateGate(ctx context.Context) (string, error) {
+	if r.cfg.AppConfig == nil {
+		return "", nil
+	}
+	mode := r.cfg.AppConfig.GenerateGate
+	if mode != config.GenerateGateFeedback && mode != config.GenerateGateFail {
+		return "", nil
+	}
+	gate := verify.GenerateGate{Dir: ".", Shell: verify.SelectShell(r.cfg.AppConfig.VerifyShell, runtime.GOOS),
+		Commands: r.cfg.AppConfig.GenerateCommands, Git: r.cfg.AppConfig.GitCommand}
+	feedback, err := gate.Check(ctx)
+	if err != nil {
+		return "", err
+	}
+	if feedback == "" {
+		r.log.Print("generated code gate passed")
+		return "", nil
+	}
+	r.log.Print("[WARN] generated code gate failed")
+	r.log.PrintAligned(feedback)
+	if mode == config.GenerateGateFail {
+		return "", errors.New(feedback)
+	}
+	return feedback, nil
+}
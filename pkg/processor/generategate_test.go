@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+func TestRunner_runGenerateGate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not in PATH")
+	}
+	// repo with a committed generated file edited by hand since
+	setup := func(t *testing.T, mode string) *Runner {
+		t.Helper()
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile("gen.txt", []byte("generated\n"), 0o600))
+		require.NoError(t, os.WriteFile("out.go", []byte("generated\n"), 0o600))
+		for _, args := range [][]string{{"init", "-q"}, {"add", "."},
+			{"-c", "user.email=test@example.com", "-c", "user.name=test", "commit", "-q", "-m", "init"}} {
+			require.NoError(t, exec.Command("git", args...).Run())
+		}
+		require.NoError(t, os.WriteFile("out.go", []byte("hand edit\n"), 0o600))
+		return &Runner{log: newMockLogger(""), cfg: Config{AppConfig: &config.Config{GenerateGate: mode,
+			GenerateCommands: []string{"cp gen.txt out.go"}}}}
+	}
+
+	t.Run("off", func(t *testing.T) {
+		r := setup(t, config.GenerateGateOff)
+		feedback, err := r.runGenerateGate(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+	})
+
+	t.Run("feedback", func(t *testing.T) {
+		r := setup(t, config.GenerateGateFeedback)
+		feedback, err := r.runGenerateGate(context.Background())
+		require.NoError(t, err)
+		assert.Contains(t, feedback, "generated code gate failed:")
+		assert.Contains(t, feedback, "\n  out.go")
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		r := setup(t, config.GenerateGateFail)
+		_, err := r.runGenerateGate(context.Background())
+		require.ErrorContains(t, err, "generated files differ from what cp gen.txt out.go produces")
+	})
+
+	t.Run("passes", func(t *testing.T) {
+		r := setup(t, config.GenerateGateFail)
+		require.NoError(t, os.WriteFile("out.go", []byte("generated\n"), 0o600))
+		feedback, err := r.runGenerateGate(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+	})
+}
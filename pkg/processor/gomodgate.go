@@ -0,0 +1,121 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/plan"
+	"github.com/umputun/ralphex/pkg/verify"
+)
+
+// goModGateOn reports if the go.mod gate runs once all tasks are completed, see config.Config.GoModGate.
+func (r *Runner) goModGateOn() bool {
+	if r.cfg.AppConfig == nil {
+		return false
+	}
+	mode := r.cfg.AppConfig.GoModGate
+	return mode == config.GoModGateFeedback || mode == config.GoModGateFail
+}
+
+// depFixAttempts limits the corrective claude runs at the end of a phase which added disallowed dependencies
+const depFixAttempts = 2
+
+// recordModBaseline records the modules required when the run starts, for the new dependencies check,
+// the dependency policy and the license check.
+func (r *Runner) recordModBaseline() {
+	if !r.depPolicy().Enabled() && !r.licenseCheckOn() && (!r.goModGateOn() || !r.cfg.AppConfig.GoModBlockNewDeps) {
+		return
+	}
+	mods, err := verify.RequiredModules(".")
+	if err != nil {
+		r.log.Print("[WARN] go.mod gate: %v", err)
+	}
+	r.modules = mods
+}
+
+// runGoModGate checks the module files once all tasks are completed. returns feedback for the next
+// task iteration, empty if the files are fine or the gate is off. in fail mode problems are returned as error.
+func (r *Runner) runGoModGate(ctx context.Context) (string, error) {
+	if !r.goModGateOn() {
+		return "", nil
+	}
+	gate := verify.GoModGate{Dir: ".", Shell: verify.SelectShell(r.cfg.AppConfig.VerifyShell, runtime.GOOS),
+		BlockNewDeps: r.cfg.AppConfig.GoModBlockNewDeps, Baseline: r.modules}
+	if gate.BlockNewDeps {
+		if content, err := os.ReadFile(r.resolvePlanFilePath()); err == nil {
+			gate.Allowed = plan.AllowedDependencies(string(content))
+		}
+	}
+	feedback, err := gate.Check(ctx)
+	if err != nil {
+		return "", err
+	}
+	if feedback == "" {
+		r.log.Print("go.mod gate passed")
+		return "", nil
+	}
+	r.log.Print("[WARN] go.mod gate failed")
+	r.log.PrintAligned(feedback)
+	if r.cfg.AppConfig.GoModGate == config.GoModGateFail {
+		return "", errors.New(feedback)
+	}
+	return feedback, nil
+}
+
+// depPolicy returns the dependency policy of config.Config.DepsAllow and DepsDeny.
+func (r *Runner) depPolicy() verify.DepPolicy {
+	if r.cfg.AppConfig == nil {
+		return verify.DepPolicy{}
+	}
+	return verify.DepPolicy{Allow: r.cfg.AppConfig.DepsAllow, Deny: r.cfg.AppConfig.DepsDeny}
+}
+
+// disallowedDeps returns the modules added to go.mod since the run started which the dependency policy
+// doesn't allow, nil without a policy.
+func (r *Runner) disallowedDeps() []string {
+	policy := r.depPolicy()
+	if !policy.Enabled() {
+		return nil
+	}
+	mods, err := verify.RequiredModules(".")
+	if err != nil {
+		r.log.Print("[WARN] dependency policy: %v", err)
+		return nil
+	}
+	res := policy.Disallowed(verify.AddedModules(r.modules, mods))
+	if len(res) > 0 {
+		r.log.Print("[WARN] dependency policy: %s not allowed", strings.Join(res, ", "))
+	}
+	return res
+}
+
+// depsFeedback tells the agent which modules it has to remove.
+func depsFeedback(mods []string) string {
+	return fmt.Sprintf("dependency policy violated, these modules may not be added to go.mod: %s. "+
+		"remove them, use the standard library or an allowed module instead, run go mod tidy and commit",
+		strings.Join(mods, ", "))
+}
+
+// enforceDepPolicy runs at the end of a phase. if the phase added disallowed dependencies, claude is asked
+// to remove them, up to depFixAttempts times, and the phase fails if they are still there.
+func (r *Runner) enforceDepPolicy(ctx context.Context) error {
+	for attempt := 1; ; attempt++ {
+		mods := r.disallowedDeps()
+		if len(mods) == 0 {
+			return nil
+		}
+		if attempt > depFixAttempts {
+			return fmt.Errorf("dependency policy: %s not allowed", strings.Join(mods, ", "))
+		}
+		r.log.Print("removing disallowed dependencies, attempt %d/%d", attempt, depFixAttempts)
+		result := r.claude.Run(ctx, buildDepsFixPrompt(depsFeedback(mods)))
+		if result.Error != nil {
+			return fmt.Errorf("claude execution: %w", result.Error)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+)
+
+func TestRunner_runGoModGate(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not in PATH")
+	}
+	t.Setenv("GOTOOLCHAIN", "local")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOFLAGS", "")
+
+	// module tidy apart from a dependency required since the run started
+	setup := func(t *testing.T, planContent string) *Runner {
+		t.Helper()
+		dir := t.TempDir()
+		t.Chdir(dir)
+		require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0o600))
+		require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.21\n"), 0o600))
+		require.NoError(t, os.WriteFile("plan.md", []byte(planContent), 0o600))
+		r := &Runner{log: newMockLogger(""), cfg: Config{PlanFile: filepath.Join(dir, "plan.md"),
+			AppConfig: &config.Config{GoModGate: config.GoModGateFeedback, GoModBlockNewDeps: true}}}
+		r.recordModBaseline()
+		require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.21\n\nrequire example.com/dep v1.0.0\n"), 0o600))
+		return r
+	}
+
+	t.Run("off", func(t *testing.T) {
+		r := setup(t, "# plan\n")
+		r.cfg.AppConfig.GoModGate = config.GoModGateOff
+		feedback, err := r.runGoModGate(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+	})
+
+	t.Run("feedback", func(t *testing.T) {
+		r := setup(t, "# plan\n")
+		feedback, err := r.runGoModGate(context.Background())
+		require.NoError(t, err)
+		assert.Contains(t, feedback, "go.mod gate failed:")
+		assert.Contains(t, feedback, "new dependencies are not allowed by the plan: example.com/dep")
+	})
+
+	t.Run("allowed by the plan", func(t *testing.T) {
+		r := setup(t, "# plan\n\nAllowed dependencies: `example.com/dep`\n")
+		feedback, err := r.runGoModGate(context.Background())
+		require.NoError(t, err)
+		assert.NotContains(t, feedback, "new dependencies")
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		r := setup(t, "# plan\n")
+		r.cfg.AppConfig.GoModGate = config.GoModGateFail
+		_, err := r.runGoModGate(context.Background())
+		require.ErrorContains(t, err, "new dependencies are not allowed by the plan: example.com/dep")
+	})
+}
+
+func TestRunner_enforceDepPolicy(t *testing.T) {
+	const (
+		baseMod = "module example.com/app\n\ngo 1.21\n\nrequire github.com/old/dep v1.0.0\n"
+		badMod  = "module example.com/app\n\ngo 1.21\n\nrequire (\n\tgithub.com/old/dep v1.0.0\n\tgithub.com/bad/dep v1.0.0\n)\n"
+	)
+	setup := func(t *testing.T, claude *mocks.ExecutorMock) *Runner {
+		t.Helper()
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile("go.mod", []byte(baseMod), 0o600))
+		r := &Runner{log: newMockLogger(""), claude: claude,
+			cfg: Config{AppConfig: &config.Config{DepsDeny: []string{"github.com/bad/..."}}}}
+		r.recordModBaseline()
+		assert.Equal(t, []string{"github.com/old/dep"}, r.modules)
+		require.NoError(t, os.WriteFile("go.mod", []byte(badMod), 0o600))
+		return r
+	}
+
+	t.Run("fixed by claude", func(t *testing.T) {
+		claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			require.NoError(t, os.WriteFile("go.mod", []byte(baseMod), 0o600))
+			return executor.Result{}
+		}}
+		r := setup(t, claude)
+		require.NoError(t, r.enforceDepPolicy(context.Background()))
+		require.Len(t, claude.RunCalls(), 1)
+		assert.Contains(t, claude.RunCalls()[0].Prompt, "DEPENDENCY POLICY VIOLATED")
+		assert.Contains(t, claude.RunCalls()[0].Prompt, "may not be added to go.mod: github.com/bad/dep.")
+	})
+
+	t.Run("fails after attempts", func(t *testing.T) {
+		claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result { return executor.Result{} }}
+		r := setup(t, claude)
+		require.EqualError(t, r.enforceDepPolicy(context.Background()), "dependency policy: github.com/bad/dep not allowed")
+		assert.Len(t, claude.RunCalls(), depFixAttempts)
+	})
+
+	t.Run("no policy", func(t *testing.T) {
+		claude := &mocks.ExecutorMock{}
+		r := setup(t, claude)
+		r.cfg.AppConfig.DepsDeny = nil
+		require.NoError(t, r.enforceDepPolicy(context.Background()))
+		assert.Empty(t, claude.RunCalls())
+	})
+}
@@ -0,0 +1,44 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/status"
+	"github.com/umputun/ralphex/pkg/verify"
+)
+
+// phaseHooks maps phases to their pre and post hook points
+var phaseHooks = map[status.Phase][2]string{
+	status.PhaseTask:   {config.HookPreTask, config.HookPostTask},
+	status.PhaseReview: {config.HookPreReview, config.HookPostReview},
+	status.PhaseCodex:  {config.HookPreCodex, config.HookPostCodex},
+}
+
+// runHook runs the hook configured for point, streaming its output to the log. a failing required hook
+// and cancellation return an error, other failures are logged and the run continues.
+func (r *Runner) runHook(ctx context.Context, point string) error {
+	if r.cfg.AppConfig == nil || point == "" {
+		return nil
+	}
+	hook, ok := r.cfg.AppConfig.Hooks[point]
+	if !ok || hook.Command == "" {
+		return nil
+	}
+	r.log.Print("running %s hook: %s", point, hook.Command)
+	shell := verify.SelectShell(r.cfg.AppConfig.VerifyShell, runtime.GOOS)
+	err := verify.Stream(ctx, shell, "", hook.Command, func(line string) { r.log.PrintAligned(line) })
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return fmt.Errorf("%s hook: %w", point, ctx.Err())
+	case hook.Required:
+		return fmt.Errorf("%s hook: %w", point, err)
+	default:
+		r.log.Print("[WARN] %s hook failed, continuing: %v", point, err)
+		return nil
+	}
+}
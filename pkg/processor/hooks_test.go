@@ -0,0 +1,94 @@
+//go:build !windows
+
+package processor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestRunner_Hooks(t *testing.T) {
+	t.Run("pre and post hooks around the phase", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "hooks.txt")
+		appCfg := testAppConfig(t)
+		appCfg.Hooks = map[string]config.Hook{
+			config.HookPreReview:  {Command: "echo pre >> " + out + " && echo regenerated"},
+			config.HookPostReview: {Command: "echo post >> " + out},
+		}
+		var aligned []string
+		log := newMockLogger("progress.txt")
+		log.PrintAlignedFunc = func(text string) { aligned = append(aligned, text) }
+		claude := newMockExecutor([]executor.Result{
+			{Output: "review done", Signal: status.ReviewDone},
+			{Output: "review done", Signal: status.ReviewDone},
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review
+		})
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, IterationDelayMs: 1, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(out) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Equal(t, "pre\npost\npre\npost\n", string(data), "hooks run around both review phases")
+		assert.Contains(t, aligned, "regenerated", "hook output streamed to the log")
+	})
+
+	t.Run("failed optional hook continues", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.Hooks = map[string]config.Hook{config.HookPreReview: {Command: "exit 3"}}
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "review done", Signal: status.ReviewDone},
+			{Output: "review done", Signal: status.ReviewDone},
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review
+		})
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, IterationDelayMs: 1, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+
+		var warned bool
+		for _, c := range log.PrintCalls() {
+			warned = warned || strings.Contains(fmt.Sprintf(c.Format, c.Args...), "pre_review hook failed, continuing")
+		}
+		assert.True(t, warned)
+	})
+
+	t.Run("failed required hook aborts", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.Hooks = map[string]config.Hook{config.HookPreReview: {Command: "exit 3", Required: true}}
+		claude := newMockExecutor(nil)
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, IterationDelayMs: 1, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.ErrorContains(t, err, "pre_review hook")
+		require.ErrorContains(t, err, "exit status 3")
+		assert.Empty(t, claude.RunCalls(), "phase not started")
+	})
+
+	t.Run("post hook skipped on phase failure", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "hooks.txt")
+		appCfg := testAppConfig(t)
+		appCfg.Hooks = map[string]config.Hook{config.HookPostCodex: {Command: "echo post >> " + out}}
+		codex := newMockExecutor([]executor.Result{{Error: assert.AnError}})
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+			AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), newMockExecutor(nil), codex, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.Error(t, err)
+		assert.NoFileExists(t, out)
+	})
+}
@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// InboxFile is the default location of the file of user notes for the agent, relative to the repository root.
+const InboxFile = ".ralphex/inbox"
+
+// AddNote queues a note from the user for the prompt of the next agent iteration, the same as a line added
+// to the inbox file. safe to call from another goroutine.
+func (r *Runner) AddNote(note string) {
+	if note = strings.TrimSpace(note); note == "" {
+		return
+	}
+	r.notesMu.Lock()
+	defer r.notesMu.Unlock()
+	r.notes = append(r.notes, note)
+}
+
+// takeNotes returns the notes sent since the previous agent iteration, with AddNote or as lines of the inbox
+// file, formatted for the prompt of the next one. empty without notes. the notes and the inbox file are consumed.
+func (r *Runner) takeNotes() string {
+	r.notesMu.Lock()
+	notes := r.notes
+	r.notes = nil
+	r.notesMu.Unlock()
+	notes = append(notes, r.readInbox()...)
+	if len(notes) == 0 {
+		return ""
+	}
+	for _, n := range notes {
+		r.log.Print("note from user: %s", n)
+	}
+	return userNotesNote(notes)
+}
+
+// readInbox returns the non-blank lines of the inbox file and removes it. the file is renamed before reading,
+// so a note appended meanwhile goes to a new inbox file instead of getting lost.
+func (r *Runner) readInbox() []string {
+	if r.cfg.InboxFile == "" {
+		return nil
+	}
+	taken := r.cfg.InboxFile + ".read"
+	if err := os.Rename(r.cfg.InboxFile, taken); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			r.log.Print("[WARN] failed to read inbox file: %v", err)
+		}
+		return nil
+	}
+	defer func() { _ = os.Remove(taken) }()
+	data, err := os.ReadFile(taken) //nolint:gosec // path is the inbox location, not user input
+	if err != nil {
+		r.log.Print("[WARN] failed to read inbox file: %v", err)
+		return nil
+	}
+	var res []string
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			res = append(res, line)
+		}
+	}
+	return res
+}
+
+// userNotesNote returns the notes of the user, appended to the prompt of the next agent iteration.
+func userNotesNote(notes []string) string {
+	var b strings.Builder
+	b.WriteString("\n\n---\nNOTES FROM THE USER:\nThe user sent these notes while the run was going. Follow them " +
+		"from this iteration on, they take precedence over the plan where the two conflict. When a note changes " +
+		"the plan, e.g. adds or skips a task, update the plan file so later iterations follow it too.\n")
+	for _, n := range notes {
+		fmt.Fprintf(&b, "- %s\n", n)
+	}
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package processor
+
+import (
+	"math"
+	"os"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// budgetProgressWindow is the number of last task iterations the plan has to advance in for the iteration
+// limit to be extended
+const budgetProgressWindow = 3
+
+// budgetExtender extends the iteration limit of the task phase while the plan keeps advancing,
+// see config.Config.BudgetExtensionFactor.
+type budgetExtender struct {
+	factor  float64 // limit multiplier per extension, 0 disables extensions
+	left    int     // extensions left
+	checked []int   // checked plan items at the start of the last iterations, budgetProgressWindow+1 at most
+}
+
+// newBudgetExtender makes the extender from budget_extension_factor and budget_max_extensions.
+func (r *Runner) newBudgetExtender() *budgetExtender {
+	if r.cfg.AppConfig == nil || r.cfg.AppConfig.BudgetExtensionFactor <= 1 || r.cfg.AppConfig.BudgetMaxExtensions <= 0 {
+		return &budgetExtender{}
+	}
+	return &budgetExtender{factor: r.cfg.AppConfig.BudgetExtensionFactor, left: r.cfg.AppConfig.BudgetMaxExtensions}
+}
+
+// observe records the checked plan items at the start of a task iteration, counted by checked.
+func (b *budgetExtender) observe(checked func() int) {
+	if b.factor <= 0 {
+		return
+	}
+	b.checked = append(b.checked, checked())
+	if len(b.checked) > budgetProgressWindow+1 {
+		b.checked = b.checked[1:]
+	}
+}
+
+// extend returns limit multiplied by the factor if the plan advanced in the last budgetProgressWindow
+// iterations and extensions are left, limit otherwise.
+func (b *budgetExtender) extend(limit int) int {
+	if b.factor <= 0 || b.left <= 0 || len(b.checked) < 2 || b.checked[len(b.checked)-1] <= b.checked[0] {
+		return limit
+	}
+	b.left--
+	return max(limit+1, int(math.Ceil(float64(limit)*b.factor)))
+}
+
+// checkedItems returns the number of checked items of the plan tasks, 0 if the plan can't be read.
+func (r *Runner) checkedItems() int {
+	content, err := os.ReadFile(r.resolvePlanFilePath())
+	if err != nil {
+		return 0
+	}
+	res := 0
+	for _, t := range plan.ParseTasks(string(content)) {
+		for _, it := range t.Items {
+			if it.Checked {
+				res++
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,45 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudgetExtender(t *testing.T) {
+	tests := []struct {
+		name    string
+		factor  float64
+		left    int
+		checked []int
+		limit   int
+		want    int
+	}{
+		{name: "disabled", factor: 0, left: 2, checked: []int{0, 1, 2}, limit: 10, want: 10},
+		{name: "advancing", factor: 1.5, left: 2, checked: []int{0, 1, 2, 3}, limit: 10, want: 15},
+		{name: "rounds up", factor: 1.5, left: 1, checked: []int{1, 2}, limit: 3, want: 5},
+		{name: "at least one more", factor: 1.01, left: 1, checked: []int{1, 2}, limit: 3, want: 4},
+		{name: "advanced before the window only", factor: 2, left: 1, checked: []int{0, 5, 5, 5, 5}, limit: 10, want: 10},
+		{name: "advanced within the window", factor: 2, left: 1, checked: []int{0, 5, 5, 5, 6}, limit: 10, want: 20},
+		{name: "no extensions left", factor: 2, left: 0, checked: []int{0, 1}, limit: 10, want: 10},
+		{name: "single observation", factor: 2, left: 1, checked: []int{3}, limit: 10, want: 10},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &budgetExtender{factor: tc.factor, left: tc.left}
+			for _, c := range tc.checked {
+				b.observe(func() int { return c })
+			}
+			assert.Equal(t, tc.want, b.extend(tc.limit))
+		})
+	}
+
+	t.Run("uses up extensions", func(t *testing.T) {
+		b := &budgetExtender{factor: 2, left: 1}
+		b.observe(func() int { return 0 })
+		b.observe(func() int { return 1 })
+		assert.Equal(t, 20, b.extend(10))
+		b.observe(func() int { return 2 })
+		assert.Equal(t, 20, b.extend(20))
+	})
+}
@@ -0,0 +1,77 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/verify"
+)
+
+// licenseCheckOn reports if licenses of added modules are checked when the run completes, see config.Config.LicenseCheck.
+func (r *Runner) licenseCheckOn() bool {
+	if r.cfg.AppConfig == nil {
+		return false
+	}
+	mode := r.cfg.AppConfig.LicenseCheck
+	return mode == config.LicenseCheckWarn || mode == config.LicenseCheckFail
+}
+
+// checkLicenses resolves the licenses of modules added to go.mod since the run started and records them
+// in the run report. modules with a license not allowed are logged, in fail mode they fail the run.
+func (r *Runner) checkLicenses(ctx context.Context) error {
+	if !r.licenseCheckOn() {
+		return nil
+	}
+	current, err := verify.RequiredModules(".")
+	if err != nil {
+		r.log.Print("[WARN] license check: %v", err)
+		return nil
+	}
+	added := verify.AddedModules(r.modules, current)
+	if len(added) == 0 {
+		return nil
+	}
+	r.log.Print("checking licenses of %d added modules", len(added))
+	licenses, err := verify.ModuleLicenses(ctx, verify.SelectShell(r.cfg.AppConfig.VerifyShell, runtime.GOOS), ".", added)
+	if err != nil {
+		return fmt.Errorf("license check: %w", err)
+	}
+	var rejected []string
+	for i, ml := range licenses {
+		licenses[i].Allowed = licenseAllowed(r.cfg.AppConfig.LicenseAllow, ml.License)
+		if licenses[i].Allowed {
+			continue
+		}
+		rejected = append(rejected, fmt.Sprintf("%s (%s)", ml.Module, ml.License))
+		if ml.Error != "" {
+			r.log.Print("[WARN] license check: %s: %s", ml.Module, ml.Error)
+		}
+	}
+	r.report.Licenses = licenses
+	if len(rejected) == 0 {
+		r.log.Print("license check passed")
+		return nil
+	}
+	r.log.Print("[WARN] license check: licenses not allowed: %s", strings.Join(rejected, ", "))
+	if r.cfg.AppConfig.LicenseCheck == config.LicenseCheckFail {
+		return fmt.Errorf("license check: licenses not allowed: %s", strings.Join(rejected, ", "))
+	}
+	return nil
+}
+
+// licenseAllowed reports if license is one of the allowed SPDX identifiers, compared case-insensitively.
+// an unknown license is never allowed.
+func licenseAllowed(allowed []string, license string) bool {
+	if license == verify.UnknownLicense {
+		return false
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(a, license) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/verify"
+)
+
+func TestRunner_checkLicenses(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not in PATH")
+	}
+	t.Setenv("GOTOOLCHAIN", "local")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOFLAGS", "")
+
+	// a module replaced by a local directory with the given license is added after the baseline
+	setup := func(t *testing.T, mode, license string) *Runner {
+		t.Helper()
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.Mkdir("dep", 0o700))
+		require.NoError(t, os.WriteFile("dep/go.mod", []byte("module example.com/dep\n"), 0o600))
+		require.NoError(t, os.WriteFile("dep/LICENSE", []byte(license), 0o600))
+		require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.21\n"), 0o600))
+		r := &Runner{log: newMockLogger(""), cfg: Config{AppConfig: &config.Config{LicenseCheck: mode,
+			LicenseAllow: []string{"mit", "Apache-2.0"}}}}
+		r.recordModBaseline()
+		require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.21\n\n"+
+			"require example.com/dep v1.0.0\n\nreplace example.com/dep => ./dep\n"), 0o600))
+		return r
+	}
+	const gpl = "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007"
+
+	t.Run("allowed", func(t *testing.T) {
+		r := setup(t, config.LicenseCheckFail, "Permission is hereby granted, free of charge")
+		require.NoError(t, r.checkLicenses(context.Background()))
+		assert.Equal(t, []verify.ModuleLicense{{Module: "example.com/dep", Version: "v1.0.0", License: "MIT", Allowed: true}},
+			r.report.Licenses)
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		r := setup(t, config.LicenseCheckWarn, gpl)
+		require.NoError(t, r.checkLicenses(context.Background()))
+		require.Len(t, r.report.Licenses, 1)
+		assert.Equal(t, "GPL-3.0", r.report.Licenses[0].License)
+		assert.False(t, r.report.Licenses[0].Allowed)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		r := setup(t, config.LicenseCheckFail, gpl)
+		require.EqualError(t, r.checkLicenses(context.Background()), "license check: licenses not allowed: example.com/dep (GPL-3.0)")
+		assert.Len(t, r.report.Licenses, 1)
+	})
+
+	t.Run("off", func(t *testing.T) {
+		r := setup(t, config.LicenseCheckOff, gpl)
+		require.NoError(t, r.checkLicenses(context.Background()))
+		assert.Empty(t, r.report.Licenses)
+	})
+}
+
+func TestLicenseAllowed(t *testing.T) {
+	assert.True(t, licenseAllowed([]string{"MIT"}, "MIT"))
+	assert.True(t, licenseAllowed([]string{"apache-2.0"}, "Apache-2.0"))
+	assert.False(t, licenseAllowed([]string{"MIT"}, "GPL-3.0"))
+	assert.False(t, licenseAllowed([]string{verify.UnknownLicense}, verify.UnknownLicense), "unknown is never allowed")
+}
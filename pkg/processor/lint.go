@@ -0,0 +1,68 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// lintCodexReasoningEffort keeps the plan lint pass cheap when codex is the primary command
+const lintCodexReasoningEffort = "low"
+
+// NewPlanLintExecutor creates the primary agent executor for the plan lint pass.
+// uses plan_lint_args if set, otherwise claude_args; for codex in a read-only sandbox with low reasoning effort.
+func NewPlanLintExecutor(appCfg *config.Config) Executor {
+	args := appCfg.PlanLintArgs
+	if args == "" {
+		args = appCfg.ClaudeArgs
+		if isCodexPrimaryCommand(appCfg.ClaudeCommand) {
+			args = readOnlyCodexArgs(normalizeCodexPrimaryArgs(args, lintCodexReasoningEffort, false))
+		}
+	}
+	return &executor.ClaudeExecutor{
+		Command:        appCfg.ClaudeCommand,
+		Args:           args,
+		ErrorPatterns:  appCfg.ClaudeErrorPatterns,
+		MaxOutputBytes: appCfg.MaxOutputBytes,
+		MaxEventBytes:  executor.DefaultMaxEventBytes,
+	}
+}
+
+// LintPlan runs the plan lint prompt over planFile and returns the agent's suggestions.
+// issues are the static lint findings, passed to the agent so it doesn't repeat them.
+func LintPlan(ctx context.Context, exec Executor, appCfg *config.Config, planFile, defaultBranch string, issues []string) (string, error) {
+	r := &Runner{cfg: Config{PlanFile: planFile, DefaultBranch: defaultBranch, AppConfig: appCfg}}
+	found := "none"
+	if len(issues) > 0 {
+		found = strings.Join(issues, "\n")
+	}
+	prompt := strings.ReplaceAll(r.replaceBaseVariables(appCfg.PlanLintPrompt), "{{LINT_ISSUES}}", found)
+
+	res := exec.Run(ctx, prompt)
+	if res.Error != nil {
+		return "", fmt.Errorf("plan lint: %w", res.Error)
+	}
+	return strings.TrimSpace(res.Output), nil
+}
+
+// readOnlyCodexArgs replaces sandbox settings in codex args with a read-only sandbox
+func readOnlyCodexArgs(args string) string {
+	fields := strings.Fields(args)
+	res := make([]string, 0, len(fields)+2)
+	for i := 0; i < len(fields); i++ {
+		switch {
+		case fields[i] == "--dangerously-bypass-approvals-and-sandbox" || fields[i] == "--full-auto":
+			continue
+		case (fields[i] == "--sandbox" || fields[i] == "-s") && i+1 < len(fields):
+			i++ // skip the sandbox mode value
+			continue
+		case strings.HasPrefix(fields[i], "--sandbox="):
+			continue
+		}
+		res = append(res, fields[i])
+	}
+	return strings.Join(append(res, "--sandbox", "read-only"), " ")
+}
@@ -0,0 +1,82 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+)
+
+func TestNewPlanLintExecutor(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		args     string
+		lintArgs string
+		want     string
+	}{
+		{name: "codex read-only with low effort", command: "codex",
+			args: `exec --dangerously-bypass-approvals-and-sandbox -c model="gpt-5.3-codex" -c model_reasoning_effort=high`,
+			want: `exec -c model="gpt-5.3-codex" -c model_reasoning_effort=low --sandbox read-only`},
+		{name: "codex sandbox mode replaced", command: "/usr/bin/codex", args: "exec --sandbox workspace-write",
+			want: "exec -c model_reasoning_effort=low --sandbox read-only"},
+		{name: "other command keeps args", command: "claude", args: "--dangerously-skip-permissions --output-format stream-json",
+			want: "--dangerously-skip-permissions --output-format stream-json"},
+		{name: "lint args win", command: "codex", args: "exec", lintArgs: `exec -c model="gpt-5.3-codex-mini"`,
+			want: `exec -c model="gpt-5.3-codex-mini"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			appCfg := testAppConfig(t)
+			appCfg.ClaudeCommand, appCfg.ClaudeArgs, appCfg.PlanLintArgs = tc.command, tc.args, tc.lintArgs
+			exec, ok := NewPlanLintExecutor(appCfg).(*executor.ClaudeExecutor)
+			require.True(t, ok)
+			assert.Equal(t, tc.command, exec.Command)
+			assert.Equal(t, tc.want, exec.Args)
+		})
+	}
+}
+
+func TestLintPlan(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+	appCfg := testAppConfig(t)
+
+	t.Run("passes static issues and returns suggestions", func(t *testing.T) {
+		exec := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			return executor.Result{Output: "\n- Task 1: too big -> split it\n"}
+		}}
+		res, err := LintPlan(context.Background(), exec, appCfg, planFile, "main", []string{"line 3: warning: a", "line 5: error: b"})
+		require.NoError(t, err)
+		assert.Equal(t, "- Task 1: too big -> split it", res)
+
+		prompt := exec.RunCalls()[0].Prompt
+		assert.Contains(t, prompt, planFile)
+		assert.Contains(t, prompt, "line 3: warning: a\nline 5: error: b")
+		assert.NotContains(t, prompt, "{{")
+	})
+
+	t.Run("no static issues", func(t *testing.T) {
+		exec := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			return executor.Result{Output: "no additional issues"}
+		}}
+		_, err := LintPlan(context.Background(), exec, appCfg, planFile, "", nil)
+		require.NoError(t, err)
+		assert.Contains(t, exec.RunCalls()[0].Prompt, "---\nnone\n---")
+	})
+
+	t.Run("executor error", func(t *testing.T) {
+		exec := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			return executor.Result{Error: errors.New("rate limited")}
+		}}
+		_, err := LintPlan(context.Background(), exec, appCfg, planFile, "", nil)
+		require.EqualError(t, err, "plan lint: rate limited")
+	})
+}
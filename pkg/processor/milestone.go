@@ -0,0 +1,118 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/umputun/ralphex/pkg/plan"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// planMilestones returns the milestones of the plan, nil if it can't be read.
+func (r *Runner) planMilestones() []plan.Milestone {
+	content, err := os.ReadFile(r.resolvePlanFilePath())
+	if err != nil {
+		return nil
+	}
+	return plan.ParseMilestones(string(content))
+}
+
+// milestoneReviews reports if the full mode runs milestone by milestone, see config.Config.MilestoneReviews.
+func (r *Runner) milestoneReviews() bool {
+	return r.cfg.AppConfig != nil && r.cfg.AppConfig.MilestoneReviews && len(r.planMilestones()) > 1
+}
+
+// milestoneDone reports if the task phase completed the milestone it runs, false when it runs the whole plan.
+func (r *Runner) milestoneDone() bool {
+	if r.milestone == 0 {
+		return false
+	}
+	milestones := r.planMilestones()
+	return r.milestone <= len(milestones) && milestones[r.milestone-1].Done()
+}
+
+// runMilestones runs the full mode milestone by milestone: the tasks of a milestone, then the review and
+// the external review of its changes. finalize runs once, after the last milestone. a run resumed in the
+// review of a milestone finishes it first, diffing against the milestone start saved in the checkpoint.
+func (r *Runner) runMilestones(ctx context.Context) error {
+	resumedReview := r.skipStep(StepTask)
+	for {
+		milestones := r.planMilestones()
+		idx := slices.IndexFunc(milestones, func(m plan.Milestone) bool { return !m.Done() })
+		if resumedReview {
+			resumedReview = false
+			r.milestone, r.milestoneBase = r.resume.Milestone, r.resume.Review.Base
+			if err := r.reviewMilestone(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+		if idx < 0 {
+			break
+		}
+
+		r.log.PrintSection(status.NewGenericSection(fmt.Sprintf("milestone %d of %d: %s",
+			idx+1, len(milestones), milestones[idx].Header)))
+		r.milestone, r.milestoneBase = idx+1, r.milestoneStart(idx)
+		r.phaseHolder.Set(status.PhaseTask)
+		if err := r.runPhase(ctx, status.PhaseTask, r.cfg.TaskTimeout, r.runTaskPhase); err != nil {
+			return fmt.Errorf("task phase, milestone %d: %w", idx+1, err)
+		}
+		if err := r.reviewMilestone(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := r.runFinalVerification(ctx); err != nil {
+		return err
+	}
+	if err := r.runFinalize(ctx); err != nil {
+		return err
+	}
+	r.log.Print("all phases completed successfully")
+	return nil
+}
+
+// milestoneStart returns the commit milestone idx starts at, empty for the first one, reviewed against the
+// default branch. a run resumed in the task phase of the milestone keeps the commit of the interrupted run,
+// HEAD already has the commits of its completed iterations.
+func (r *Runner) milestoneStart(idx int) string {
+	if cp := r.resume; cp != nil && cp.Step == StepTask && cp.Milestone == idx+1 {
+		return cp.Review.Base
+	}
+	if idx == 0 {
+		return ""
+	}
+	return r.headHash()
+}
+
+// reviewMilestone runs the reviews of the current milestone against its start, see runMilestoneReview.
+func (r *Runner) reviewMilestone(ctx context.Context) error {
+	milestone := r.milestone
+	r.reviewBase = r.milestoneBase
+	err := r.runMilestoneReview(ctx)
+	r.reviewBase, r.milestone, r.milestoneBase = "", 0, ""
+	if err != nil && milestone > 0 {
+		return fmt.Errorf("milestone %d: %w", milestone, err)
+	}
+	return err
+}
+
+// runMilestoneReview runs the review, the external review rounds and the escalation review after the tasks
+// of a milestone.
+func (r *Runner) runMilestoneReview(ctx context.Context) error {
+	r.prepareReviewDiff()
+	if err := r.runFirstReviewPhase(ctx); err != nil {
+		return err
+	}
+	err := r.runExternalRounds(ctx)
+	if err == nil {
+		err = r.runEscalation(ctx)
+	}
+	if r.resume != nil && r.resume.Step != StepFinalize {
+		r.resume = nil // the resumed review is done, later milestones start from scratch
+	}
+	return err
+}
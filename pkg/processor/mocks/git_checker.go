@@ -4,6 +4,7 @@
 package mocks
 
 import (
+	"github.com/umputun/ralphex/pkg/git"
 	"sync"
 )
 
@@ -13,9 +14,30 @@ import (
 //
 //		// make and configure a mocked processor.GitChecker
 //		mockedGitChecker := &GitCheckerMock{
+//			ChangedFilesFunc: func(baseBranch string) ([]string, error) {
+//				panic("mock out the ChangedFiles method")
+//			},
+//			CommitFilesFunc: func(files []string, msg string) error {
+//				panic("mock out the CommitFiles method")
+//			},
+//			DiffSinceFunc: func(rev string) (string, error) {
+//				panic("mock out the DiffSince method")
+//			},
 //			HeadHashFunc: func() (string, error) {
 //				panic("mock out the HeadHash method")
 //			},
+//			PrepareDiffFunc: func(baseBranch string, fetch bool) (git.DiffReadiness, error) {
+//				panic("mock out the PrepareDiff method")
+//			},
+//			RestoreSnapshotFunc: func(snap git.Snapshot) error {
+//				panic("mock out the RestoreSnapshot method")
+//			},
+//			SnapshotFunc: func() (git.Snapshot, error) {
+//				panic("mock out the Snapshot method")
+//			},
+//			SpecialChangesFunc: func(baseBranch string) (git.SpecialChanges, error) {
+//				panic("mock out the SpecialChanges method")
+//			},
 //		}
 //
 //		// use mockedGitChecker in code that requires processor.GitChecker
@@ -23,16 +45,181 @@ import (
 //
 //	}
 type GitCheckerMock struct {
+	// ChangedFilesFunc mocks the ChangedFiles method.
+	ChangedFilesFunc func(baseBranch string) ([]string, error)
+
+	// CommitFilesFunc mocks the CommitFiles method.
+	CommitFilesFunc func(files []string, msg string) error
+
+	// DiffSinceFunc mocks the DiffSince method.
+	DiffSinceFunc func(rev string) (string, error)
+
 	// HeadHashFunc mocks the HeadHash method.
 	HeadHashFunc func() (string, error)
 
+	// PrepareDiffFunc mocks the PrepareDiff method.
+	PrepareDiffFunc func(baseBranch string, fetch bool) (git.DiffReadiness, error)
+
+	// RestoreSnapshotFunc mocks the RestoreSnapshot method.
+	RestoreSnapshotFunc func(snap git.Snapshot) error
+
+	// SnapshotFunc mocks the Snapshot method.
+	SnapshotFunc func() (git.Snapshot, error)
+
+	// SpecialChangesFunc mocks the SpecialChanges method.
+	SpecialChangesFunc func(baseBranch string) (git.SpecialChanges, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// ChangedFiles holds details about calls to the ChangedFiles method.
+		ChangedFiles []struct {
+			// BaseBranch is the baseBranch argument value.
+			BaseBranch string
+		}
+		// CommitFiles holds details about calls to the CommitFiles method.
+		CommitFiles []struct {
+			// Files is the files argument value.
+			Files []string
+			// Msg is the msg argument value.
+			Msg string
+		}
+		// DiffSince holds details about calls to the DiffSince method.
+		DiffSince []struct {
+			// Rev is the rev argument value.
+			Rev string
+		}
 		// HeadHash holds details about calls to the HeadHash method.
 		HeadHash []struct {
 		}
+		// PrepareDiff holds details about calls to the PrepareDiff method.
+		PrepareDiff []struct {
+			// BaseBranch is the baseBranch argument value.
+			BaseBranch string
+			// Fetch is the fetch argument value.
+			Fetch bool
+		}
+		// RestoreSnapshot holds details about calls to the RestoreSnapshot method.
+		RestoreSnapshot []struct {
+			// Snap is the snap argument value.
+			Snap git.Snapshot
+		}
+		// Snapshot holds details about calls to the Snapshot method.
+		Snapshot []struct {
+		}
+		// SpecialChanges holds details about calls to the SpecialChanges method.
+		SpecialChanges []struct {
+			// BaseBranch is the baseBranch argument value.
+			BaseBranch string
+		}
+	}
+	lockChangedFiles    sync.RWMutex
+	lockCommitFiles     sync.RWMutex
+	lockDiffSince       sync.RWMutex
+	lockHeadHash        sync.RWMutex
+	lockPrepareDiff     sync.RWMutex
+	lockRestoreSnapshot sync.RWMutex
+	lockSnapshot        sync.RWMutex
+	lockSpecialChanges  sync.RWMutex
+}
+
+// ChangedFiles calls ChangedFilesFunc.
+func (mock *GitCheckerMock) ChangedFiles(baseBranch string) ([]string, error) {
+	if mock.ChangedFilesFunc == nil {
+		panic("GitCheckerMock.ChangedFilesFunc: method is nil but GitChecker.ChangedFiles was just called")
+	}
+	callInfo := struct {
+		BaseBranch string
+	}{
+		BaseBranch: baseBranch,
+	}
+	mock.lockChangedFiles.Lock()
+	mock.calls.ChangedFiles = append(mock.calls.ChangedFiles, callInfo)
+	mock.lockChangedFiles.Unlock()
+	return mock.ChangedFilesFunc(baseBranch)
+}
+
+// ChangedFilesCalls gets all the calls that were made to ChangedFiles.
+// Check the length with:
+//
+//	len(mockedGitChecker.ChangedFilesCalls())
+func (mock *GitCheckerMock) ChangedFilesCalls() []struct {
+	BaseBranch string
+} {
+	var calls []struct {
+		BaseBranch string
+	}
+	mock.lockChangedFiles.RLock()
+	calls = mock.calls.ChangedFiles
+	mock.lockChangedFiles.RUnlock()
+	return calls
+}
+
+// CommitFiles calls CommitFilesFunc.
+func (mock *GitCheckerMock) CommitFiles(files []string, msg string) error {
+	if mock.CommitFilesFunc == nil {
+		panic("GitCheckerMock.CommitFilesFunc: method is nil but GitChecker.CommitFiles was just called")
+	}
+	callInfo := struct {
+		Files []string
+		Msg   string
+	}{
+		Files: files,
+		Msg:   msg,
+	}
+	mock.lockCommitFiles.Lock()
+	mock.calls.CommitFiles = append(mock.calls.CommitFiles, callInfo)
+	mock.lockCommitFiles.Unlock()
+	return mock.CommitFilesFunc(files, msg)
+}
+
+// CommitFilesCalls gets all the calls that were made to CommitFiles.
+// Check the length with:
+//
+//	len(mockedGitChecker.CommitFilesCalls())
+func (mock *GitCheckerMock) CommitFilesCalls() []struct {
+	Files []string
+	Msg   string
+} {
+	var calls []struct {
+		Files []string
+		Msg   string
+	}
+	mock.lockCommitFiles.RLock()
+	calls = mock.calls.CommitFiles
+	mock.lockCommitFiles.RUnlock()
+	return calls
+}
+
+// DiffSince calls DiffSinceFunc.
+func (mock *GitCheckerMock) DiffSince(rev string) (string, error) {
+	if mock.DiffSinceFunc == nil {
+		panic("GitCheckerMock.DiffSinceFunc: method is nil but GitChecker.DiffSince was just called")
+	}
+	callInfo := struct {
+		Rev string
+	}{
+		Rev: rev,
+	}
+	mock.lockDiffSince.Lock()
+	mock.calls.DiffSince = append(mock.calls.DiffSince, callInfo)
+	mock.lockDiffSince.Unlock()
+	return mock.DiffSinceFunc(rev)
+}
+
+// DiffSinceCalls gets all the calls that were made to DiffSince.
+// Check the length with:
+//
+//	len(mockedGitChecker.DiffSinceCalls())
+func (mock *GitCheckerMock) DiffSinceCalls() []struct {
+	Rev string
+} {
+	var calls []struct {
+		Rev string
 	}
-	lockHeadHash sync.RWMutex
+	mock.lockDiffSince.RLock()
+	calls = mock.calls.DiffSince
+	mock.lockDiffSince.RUnlock()
+	return calls
 }
 
 // HeadHash calls HeadHashFunc.
@@ -61,3 +248,130 @@ func (mock *GitCheckerMock) HeadHashCalls() []struct {
 	mock.lockHeadHash.RUnlock()
 	return calls
 }
+
+// PrepareDiff calls PrepareDiffFunc.
+func (mock *GitCheckerMock) PrepareDiff(baseBranch string, fetch bool) (git.DiffReadiness, error) {
+	if mock.PrepareDiffFunc == nil {
+		panic("GitCheckerMock.PrepareDiffFunc: method is nil but GitChecker.PrepareDiff was just called")
+	}
+	callInfo := struct {
+		BaseBranch string
+		Fetch      bool
+	}{
+		BaseBranch: baseBranch,
+		Fetch:      fetch,
+	}
+	mock.lockPrepareDiff.Lock()
+	mock.calls.PrepareDiff = append(mock.calls.PrepareDiff, callInfo)
+	mock.lockPrepareDiff.Unlock()
+	return mock.PrepareDiffFunc(baseBranch, fetch)
+}
+
+// PrepareDiffCalls gets all the calls that were made to PrepareDiff.
+// Check the length with:
+//
+//	len(mockedGitChecker.PrepareDiffCalls())
+func (mock *GitCheckerMock) PrepareDiffCalls() []struct {
+	BaseBranch string
+	Fetch      bool
+} {
+	var calls []struct {
+		BaseBranch string
+		Fetch      bool
+	}
+	mock.lockPrepareDiff.RLock()
+	calls = mock.calls.PrepareDiff
+	mock.lockPrepareDiff.RUnlock()
+	return calls
+}
+
+// RestoreSnapshot calls RestoreSnapshotFunc.
+func (mock *GitCheckerMock) RestoreSnapshot(snap git.Snapshot) error {
+	if mock.RestoreSnapshotFunc == nil {
+		panic("GitCheckerMock.RestoreSnapshotFunc: method is nil but GitChecker.RestoreSnapshot was just called")
+	}
+	callInfo := struct {
+		Snap git.Snapshot
+	}{
+		Snap: snap,
+	}
+	mock.lockRestoreSnapshot.Lock()
+	mock.calls.RestoreSnapshot = append(mock.calls.RestoreSnapshot, callInfo)
+	mock.lockRestoreSnapshot.Unlock()
+	return mock.RestoreSnapshotFunc(snap)
+}
+
+// RestoreSnapshotCalls gets all the calls that were made to RestoreSnapshot.
+// Check the length with:
+//
+//	len(mockedGitChecker.RestoreSnapshotCalls())
+func (mock *GitCheckerMock) RestoreSnapshotCalls() []struct {
+	Snap git.Snapshot
+} {
+	var calls []struct {
+		Snap git.Snapshot
+	}
+	mock.lockRestoreSnapshot.RLock()
+	calls = mock.calls.RestoreSnapshot
+	mock.lockRestoreSnapshot.RUnlock()
+	return calls
+}
+
+// Snapshot calls SnapshotFunc.
+func (mock *GitCheckerMock) Snapshot() (git.Snapshot, error) {
+	if mock.SnapshotFunc == nil {
+		panic("GitCheckerMock.SnapshotFunc: method is nil but GitChecker.Snapshot was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockSnapshot.Lock()
+	mock.calls.Snapshot = append(mock.calls.Snapshot, callInfo)
+	mock.lockSnapshot.Unlock()
+	return mock.SnapshotFunc()
+}
+
+// SnapshotCalls gets all the calls that were made to Snapshot.
+// Check the length with:
+//
+//	len(mockedGitChecker.SnapshotCalls())
+func (mock *GitCheckerMock) SnapshotCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockSnapshot.RLock()
+	calls = mock.calls.Snapshot
+	mock.lockSnapshot.RUnlock()
+	return calls
+}
+
+// SpecialChanges calls SpecialChangesFunc.
+func (mock *GitCheckerMock) SpecialChanges(baseBranch string) (git.SpecialChanges, error) {
+	if mock.SpecialChangesFunc == nil {
+		panic("GitCheckerMock.SpecialChangesFunc: method is nil but GitChecker.SpecialChanges was just called")
+	}
+	callInfo := struct {
+		BaseBranch string
+	}{
+		BaseBranch: baseBranch,
+	}
+	mock.lockSpecialChanges.Lock()
+	mock.calls.SpecialChanges = append(mock.calls.SpecialChanges, callInfo)
+	mock.lockSpecialChanges.Unlock()
+	return mock.SpecialChangesFunc(baseBranch)
+}
+
+// SpecialChangesCalls gets all the calls that were made to SpecialChanges.
+// Check the length with:
+//
+//	len(mockedGitChecker.SpecialChangesCalls())
+func (mock *GitCheckerMock) SpecialChangesCalls() []struct {
+	BaseBranch string
+} {
+	var calls []struct {
+		BaseBranch string
+	}
+	mock.lockSpecialChanges.RLock()
+	calls = mock.calls.SpecialChanges
+	mock.lockSpecialChanges.RUnlock()
+	return calls
+}
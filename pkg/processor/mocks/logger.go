@@ -33,6 +33,9 @@ import (
 //			PrintAlignedFunc: func(text string)  {
 //				panic("mock out the PrintAligned method")
 //			},
+//			PrintDiffFunc: func(diff string)  {
+//				panic("mock out the PrintDiff method")
+//			},
 //			PrintRawFunc: func(format string, args ...any)  {
 //				panic("mock out the PrintRaw method")
 //			},
@@ -64,6 +67,9 @@ type LoggerMock struct {
 	// PrintAlignedFunc mocks the PrintAligned method.
 	PrintAlignedFunc func(text string)
 
+	// PrintDiffFunc mocks the PrintDiff method.
+	PrintDiffFunc func(diff string)
+
 	// PrintRawFunc mocks the PrintRaw method.
 	PrintRawFunc func(format string, args ...any)
 
@@ -106,6 +112,11 @@ type LoggerMock struct {
 			// Text is the text argument value.
 			Text string
 		}
+		// PrintDiff holds details about calls to the PrintDiff method.
+		PrintDiff []struct {
+			// Diff is the diff argument value.
+			Diff string
+		}
 		// PrintRaw holds details about calls to the PrintRaw method.
 		PrintRaw []struct {
 			// Format is the format argument value.
@@ -125,6 +136,7 @@ type LoggerMock struct {
 	lockPath           sync.RWMutex
 	lockPrint          sync.RWMutex
 	lockPrintAligned   sync.RWMutex
+	lockPrintDiff      sync.RWMutex
 	lockPrintRaw       sync.RWMutex
 	lockPrintSection   sync.RWMutex
 }
@@ -328,6 +340,38 @@ func (mock *LoggerMock) PrintAlignedCalls() []struct {
 	return calls
 }
 
+// PrintDiff calls PrintDiffFunc.
+func (mock *LoggerMock) PrintDiff(diff string) {
+	if mock.PrintDiffFunc == nil {
+		panic("LoggerMock.PrintDiffFunc: method is nil but Logger.PrintDiff was just called")
+	}
+	callInfo := struct {
+		Diff string
+	}{
+		Diff: diff,
+	}
+	mock.lockPrintDiff.Lock()
+	mock.calls.PrintDiff = append(mock.calls.PrintDiff, callInfo)
+	mock.lockPrintDiff.Unlock()
+	mock.PrintDiffFunc(diff)
+}
+
+// PrintDiffCalls gets all the calls that were made to PrintDiff.
+// Check the length with:
+//
+//	len(mockedLogger.PrintDiffCalls())
+func (mock *LoggerMock) PrintDiffCalls() []struct {
+	Diff string
+} {
+	var calls []struct {
+		Diff string
+	}
+	mock.lockPrintDiff.RLock()
+	calls = mock.calls.PrintDiff
+	mock.lockPrintDiff.RUnlock()
+	return calls
+}
+
 // PrintRaw calls PrintRawFunc.
 func (mock *LoggerMock) PrintRaw(format string, args ...any) {
 	if mock.PrintRawFunc == nil {
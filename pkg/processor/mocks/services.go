@@ -0,0 +1,115 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"sync"
+)
+
+// ServicesMock is a mock implementation of processor.Services.
+//
+//	func TestSomethingThatUsesServices(t *testing.T) {
+//
+//		// make and configure a mocked processor.Services
+//		mockedServices := &ServicesMock{
+//			DownFunc: func(ctx context.Context) error {
+//				panic("mock out the Down method")
+//			},
+//			UpFunc: func(ctx context.Context) ([]string, error) {
+//				panic("mock out the Up method")
+//			},
+//		}
+//
+//		// use mockedServices in code that requires processor.Services
+//		// and then make assertions.
+//
+//	}
+type ServicesMock struct {
+	// DownFunc mocks the Down method.
+	DownFunc func(ctx context.Context) error
+
+	// UpFunc mocks the Up method.
+	UpFunc func(ctx context.Context) ([]string, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Down holds details about calls to the Down method.
+		Down []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// Up holds details about calls to the Up method.
+		Up []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockDown sync.RWMutex
+	lockUp   sync.RWMutex
+}
+
+// Down calls DownFunc.
+func (mock *ServicesMock) Down(ctx context.Context) error {
+	if mock.DownFunc == nil {
+		panic("ServicesMock.DownFunc: method is nil but Services.Down was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockDown.Lock()
+	mock.calls.Down = append(mock.calls.Down, callInfo)
+	mock.lockDown.Unlock()
+	return mock.DownFunc(ctx)
+}
+
+// DownCalls gets all the calls that were made to Down.
+// Check the length with:
+//
+//	len(mockedServices.DownCalls())
+func (mock *ServicesMock) DownCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockDown.RLock()
+	calls = mock.calls.Down
+	mock.lockDown.RUnlock()
+	return calls
+}
+
+// Up calls UpFunc.
+func (mock *ServicesMock) Up(ctx context.Context) ([]string, error) {
+	if mock.UpFunc == nil {
+		panic("ServicesMock.UpFunc: method is nil but Services.Up was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockUp.Lock()
+	mock.calls.Up = append(mock.calls.Up, callInfo)
+	mock.lockUp.Unlock()
+	return mock.UpFunc(ctx)
+}
+
+// UpCalls gets all the calls that were made to Up.
+// Check the length with:
+//
+//	len(mockedServices.UpCalls())
+func (mock *ServicesMock) UpCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockUp.RLock()
+	calls = mock.calls.Up
+	mock.lockUp.RUnlock()
+	return calls
+}
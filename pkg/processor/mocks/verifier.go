@@ -0,0 +1,72 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/umputun/ralphex/pkg/verify"
+	"sync"
+)
+
+// VerifierMock is a mock implementation of processor.Verifier.
+//
+//	func TestSomethingThatUsesVerifier(t *testing.T) {
+//
+//		// make and configure a mocked processor.Verifier
+//		mockedVerifier := &VerifierMock{
+//			VerifyFunc: func(ctx context.Context) verify.Report {
+//				panic("mock out the Verify method")
+//			},
+//		}
+//
+//		// use mockedVerifier in code that requires processor.Verifier
+//		// and then make assertions.
+//
+//	}
+type VerifierMock struct {
+	// VerifyFunc mocks the Verify method.
+	VerifyFunc func(ctx context.Context) verify.Report
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Verify holds details about calls to the Verify method.
+		Verify []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockVerify sync.RWMutex
+}
+
+// Verify calls VerifyFunc.
+func (mock *VerifierMock) Verify(ctx context.Context) verify.Report {
+	if mock.VerifyFunc == nil {
+		panic("VerifierMock.VerifyFunc: method is nil but Verifier.Verify was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockVerify.Lock()
+	mock.calls.Verify = append(mock.calls.Verify, callInfo)
+	mock.lockVerify.Unlock()
+	return mock.VerifyFunc(ctx)
+}
+
+// VerifyCalls gets all the calls that were made to Verify.
+// Check the length with:
+//
+//	len(mockedVerifier.VerifyCalls())
+func (mock *VerifierMock) VerifyCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockVerify.RLock()
+	calls = mock.calls.Verify
+	mock.lockVerify.RUnlock()
+	return calls
+}
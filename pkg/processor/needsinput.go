@@ -0,0 +1,41 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// handleNeedsInput asks a person the question of a NEEDS_INPUT signal in the output of a task iteration,
+// notifying that the run waits for them. returns the question and answer to pass to the next iteration,
+// empty without the signal. fails when nobody can answer, see Config.AnswerInput.
+func (r *Runner) handleNeedsInput(ctx context.Context, output string) (string, error) {
+	question, err := ParseNeedsInputPayload(output)
+	if err != nil {
+		// log malformed signals (but not "no signal" which is expected)
+		if !errors.Is(err, ErrNoNeedsInputSignal) {
+			r.log.Print("warning: %v", err)
+		}
+		return "", nil
+	}
+
+	r.log.Print("agent needs input: %s", question)
+	if r.cfg.AnswerInput == nil {
+		return "", fmt.Errorf("agent needs input, nobody to answer: %s", question)
+	}
+	if r.cfg.NeedsHuman != nil {
+		r.cfg.NeedsHuman("agent needs input: " + question)
+	}
+	answer, err := r.cfg.AnswerInput(ctx, question)
+	if err != nil {
+		return "", fmt.Errorf("answer to agent question: %w", err)
+	}
+	r.log.LogAnswer(answer)
+	return needsInputNote(question, answer), nil
+}
+
+// needsInputNote returns the question of the previous iteration and its answer, appended to the task prompt.
+func needsInputNote(question, answer string) string {
+	return fmt.Sprintf("\n\n---\nANSWER TO YOUR QUESTION:\nThe previous iteration stopped with this question:\n%s\n\n"+
+		"The user answered:\n%s\n\nContinue the task with this answer.", question, answer)
+}
@@ -0,0 +1,171 @@
+package processor
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// firstReview returns the first review of the full and review modes: the claude first review followed by
+// the claude review loop, or with parallel_first_review or consensus_review set, claude and the external
+// review analyzing the diff at the same time.
+func (r *Runner) firstReview() func(context.Context) error {
+	parallel := r.cfg.AppConfig != nil && (r.cfg.AppConfig.ParallelFirstReview || r.cfg.AppConfig.ConsensusReview)
+	if parallel && r.externalReviewTool() != "none" {
+		return r.runParallelFirstReview
+	}
+	return r.runPreExternalReview
+}
+
+// runParallelFirstReview runs the claude first review, reporting findings only, and the first external
+// review iteration concurrently, then a single claude pass fixing both sets of findings. the pass completes
+// the first iteration of the external review loop, which continues with the next one. the claude review
+// loop before the external review is left out, the review after it covers critical and major issues.
+// with consensus_review set only findings both reviews report are fixed, without any the external review is done.
+func (r *Runner) runParallelFirstReview(ctx context.Context) error {
+	r.phaseHolder.Set(status.PhaseReview)
+	if r.skipStep(StepFirstReview) {
+		return nil
+	}
+	ext, err := r.externalReview(r.externalReviewTool())
+	if err != nil {
+		return err
+	}
+	r.resumeStep(StepFirstReview)
+	r.saveCheckpoint(Checkpoint{Step: StepFirstReview})
+	r.log.PrintSection(status.NewGenericSection(fmt.Sprintf("parallel first review: claude and %s", ext.name)))
+
+	claudePrompt := buildReportOnlyPrompt(r.reviewPrompt(r.cfg.AppConfig.ReviewFirstPrompt), ext.name, r.consensusReview()) +
+		r.takeResumedOutput()
+	extPrompt := ext.buildPrompt(true, "")
+	var claudeRes, extRes executor.Result
+	var claudePanic, extPanic *executor.Panic
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer executor.CatchPanic(&claudePanic)
+		claudeRes = r.claude.Run(ctx, claudePrompt)
+	}()
+	go func() {
+		defer wg.Done()
+		defer executor.CatchPanic(&extPanic)
+		extRes = ext.review(true)(ctx, extPrompt)
+	}()
+	wg.Wait()
+	if p := cmp.Or(claudePanic, extPanic); p != nil {
+		panic(p) // crash the run with the stack of the goroutine
+	}
+
+	if claudeRes.Error != nil {
+		if err := r.handlePatternMatchError(claudeRes.Error, "claude"); err != nil {
+			return err
+		}
+		return fmt.Errorf("first review: claude execution: %w", claudeRes.Error)
+	}
+	if extRes.Error != nil {
+		if err := r.handlePatternMatchError(extRes.Error, ext.name); err != nil {
+			return err
+		}
+		return fmt.Errorf("first review: %s execution: %w", ext.name, extRes.Error)
+	}
+	if claudeRes.Signal == SignalFailed {
+		return errors.New("first review: review failed (FAILED signal received)")
+	}
+	if extRes.Output != "" {
+		ext.showSummary(extRes.Output)
+		r.recordFindings(extRes.Output)
+	}
+
+	// single fix pass over the findings of both reviews, evaluated like external review findings
+	merged := fmt.Sprintf("Claude review findings:\n%s\n\n%s review findings:\n%s", claudeRes.Output, ext.name, extRes.Output)
+	if r.consensusReview() {
+		agreed, unconfirmed := consensusFindings(claudeRes.Output, extRes.Output)
+		r.logUnconfirmed(unconfirmed)
+		if len(agreed) == 0 {
+			r.consensusClean = true
+			r.parallelDone = &Checkpoint{Step: StepExternal, Iteration: 1, Review: CheckpointReview{Findings: extRes.Output}}
+			return nil
+		}
+		r.log.Print("%d findings flagged by both claude and %s", len(agreed), ext.name)
+		merged = fmt.Sprintf("Findings both claude and %s review report:\n%s", ext.name, strings.Join(agreed, "\n"))
+	}
+	r.log.PrintSection(status.NewClaudeEvalSection())
+	iterMark := r.diffMark(config.ShowDiffIteration)
+	fixRes := r.claude.Run(ctx, ext.buildEvalPrompt(merged))
+	if fixRes.Error != nil {
+		if err := r.handlePatternMatchError(fixRes.Error, "claude"); err != nil {
+			return err
+		}
+		return fmt.Errorf("first review fixes: claude execution: %w", fixRes.Error)
+	}
+	r.recordDismissed(fixRes.Output)
+	r.showDiff(iterMark, "parallel first review fixes")
+
+	r.parallelDone = &Checkpoint{Step: StepExternal, Iteration: 1,
+		Review: CheckpointReview{Findings: extRes.Output, ClaudeResponse: fixRes.Output}}
+	r.saveCheckpoint(*r.parallelDone)
+	return nil
+}
+
+// buildReportOnlyPrompt turns the first review prompt into a read-only pass, run while another reviewer
+// analyzes the same diff. with consensus set only findings both reviews report get fixed.
+func buildReportOnlyPrompt(reviewPrompt, other string, consensus bool) string {
+	fixed := "the findings of both reviews\nare fixed together in a follow-up pass."
+	if consensus {
+		fixed = "only findings both reviews\nreport at the same location are fixed in a follow-up pass."
+	}
+	return fmt.Sprintf(`REPORT ONLY: %s reviews the same diff at the same time. In this pass do NOT modify, commit
+or revert any files. Report every finding as "<file>:<line> <severity> <finding>"; %s
+
+---
+%s`, other, fixed, reviewPrompt)
+}
+
+// syncLogger serializes a logger shared by executors running in parallel
+type syncLogger struct {
+	mu sync.Mutex
+	Logger
+}
+
+// Print writes a formatted message
+func (l *syncLogger) Print(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Logger.Print(format, args...)
+}
+
+// PrintRaw writes a formatted message without a timestamp
+func (l *syncLogger) PrintRaw(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Logger.PrintRaw(format, args...)
+}
+
+// PrintSection writes a section header
+func (l *syncLogger) PrintSection(section status.Section) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Logger.PrintSection(section)
+}
+
+// PrintAligned writes text aligned with the timestamps, e.g. streamed agent output
+func (l *syncLogger) PrintAligned(text string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Logger.PrintAligned(text)
+}
+
+// PrintDiff writes a diff
+func (l *syncLogger) PrintDiff(diff string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Logger.PrintDiff(diff)
+}
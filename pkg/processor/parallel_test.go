@@ -0,0 +1,152 @@
+package processor_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestRunner_ParallelFirstReview(t *testing.T) {
+	codexStarted := make(chan struct{})
+	var once sync.Once
+	claudeResults := []executor.Result{
+		{Output: "fix pass done"},                          // fixes of both reviews
+		{Output: "done", Signal: status.CodexDone},         // evaluation of the second codex iteration
+		{Output: "review done", Signal: status.ReviewDone}, // post-codex review
+	}
+	var prompts []string
+	claude := &mocks.ExecutorMock{RunFunc: func(_ context.Context, prompt string) executor.Result {
+		prompts = append(prompts, prompt)
+		if len(prompts) == 1 {
+			select {
+			case <-codexStarted: // codex runs while claude reviews
+			case <-time.After(5 * time.Second):
+				return executor.Result{Error: assert.AnError}
+			}
+			return executor.Result{Output: "a.go:1 major unchecked error", Signal: status.ReviewDone}
+		}
+		return claudeResults[len(prompts)-2]
+	}}
+	codexOutputs := []string{"b.go:2 racy counter", "NO ISSUES FOUND"}
+	codexCalls := 0
+	codex := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		once.Do(func() { close(codexStarted) })
+		codexCalls++
+		return executor.Result{Output: codexOutputs[codexCalls-1]}
+	}}
+
+	appCfg := testAppConfig(t)
+	appCfg.ParallelFirstReview = true
+	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+		AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, prompts, 4)
+	assert.True(t, strings.HasPrefix(prompts[0], "REPORT ONLY: codex reviews the same diff"))
+	assert.Contains(t, prompts[1], "a.go:1 major unchecked error", "fix pass gets claude findings")
+	assert.Contains(t, prompts[1], "b.go:2 racy counter", "fix pass gets codex findings")
+	assert.Equal(t, 2, codexCalls, "the external review loop continues at its second iteration")
+	assert.Contains(t, codex.RunCalls()[1].Prompt, "fix pass done", "codex sees the fix pass response")
+	assert.Equal(t, []processor.Finding{{File: "b.go", Message: "racy counter"}}, r.Findings())
+}
+
+func TestRunner_ConsensusReview(t *testing.T) {
+	t.Run("fixes only findings both reviews report", func(t *testing.T) {
+		claudeResults := []executor.Result{
+			{Output: "- a.go:11 major unchecked error\n- c.go:3 minor naming", Signal: status.ReviewDone}, // report only
+			{Output: "fix pass done"},                          // fixes of consensus findings
+			{Output: "done", Signal: status.CodexDone},         // evaluation of the second codex iteration
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review
+		}
+		claude := newMockExecutor(claudeResults)
+		codexOutputs := []string{"a.go:10 error ignored\nb.go:2 racy counter", "NO ISSUES FOUND"}
+		codexCalls := 0
+		codex := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			codexCalls++
+			return executor.Result{Output: codexOutputs[codexCalls-1]}
+		}}
+
+		appCfg := testAppConfig(t)
+		appCfg.ConsensusReview = true
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+			AppConfig: appCfg}
+		log := newMockLogger("progress.txt")
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+
+		prompts := claude.RunCalls()
+		require.Len(t, prompts, 4)
+		assert.Contains(t, prompts[0].Prompt, "only findings both reviews")
+		assert.Contains(t, prompts[1].Prompt, "a.go:10 error ignored", "fix pass gets the consensus finding")
+		assert.NotContains(t, prompts[1].Prompt, "b.go:2 racy counter", "codex only finding left out")
+		assert.NotContains(t, prompts[1].Prompt, "c.go:3 minor naming", "claude only finding left out")
+		assert.Equal(t, 2, codexCalls)
+		assert.True(t, printed(log, "2 findings flagged by one reviewer only"))
+	})
+
+	t.Run("no consensus ends the external review", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{
+			{Output: "- c.go:3 minor naming", Signal: status.ReviewDone}, // report only
+			{Output: "review done", Signal: status.ReviewDone},           // post-codex review
+		})
+		codex := newMockExecutor([]executor.Result{{Output: "b.go:2 racy counter"}})
+
+		appCfg := testAppConfig(t)
+		appCfg.ConsensusReview = true
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+			AppConfig: appCfg}
+		log := newMockLogger("progress.txt")
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+
+		assert.Len(t, codex.RunCalls(), 1)
+		for _, c := range claude.RunCalls() {
+			assert.NotContains(t, c.Prompt, "b.go:2 racy counter", "no fix pass without consensus")
+		}
+		assert.True(t, printed(log, "no findings both reviews agree on"))
+	})
+}
+
+// printed tells whether log printed a message containing substr
+func printed(log *mocks.LoggerMock, substr string) bool {
+	for _, c := range log.PrintCalls() {
+		if strings.Contains(fmt.Sprintf(c.Format, c.Args...), substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRunner_ParallelFirstReview_Panic(t *testing.T) {
+	claude := newMockExecutor([]executor.Result{{Output: "a.go:1 unchecked error", Signal: status.ReviewDone}})
+	codex := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		panic("codex goroutine failed")
+	}}
+
+	appCfg := testAppConfig(t)
+	appCfg.ParallelFirstReview = true
+	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+		AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+
+	var panicErr *processor.PanicError
+	require.ErrorAs(t, err, &panicErr, "a panic of a review goroutine ends the run as a crash")
+	assert.Equal(t, "codex goroutine failed", panicErr.Value)
+	assert.Contains(t, string(panicErr.Stack), "parallel_test.go", "stack of the goroutine")
+}
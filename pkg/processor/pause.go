@@ -0,0 +1,81 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Pause holds a run between iterations on request, see Config.Pause. it is safe for concurrent use,
+// requests usually come from a signal handler.
+type Pause struct {
+	mu      sync.Mutex
+	resumed chan struct{} // closed by Continue, nil while no pause is requested
+}
+
+// NewPause makes a pause with nothing requested.
+func NewPause() *Pause {
+	return &Pause{}
+}
+
+// Request asks the run to pause once the iteration in progress completes.
+func (p *Pause) Request() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resumed == nil {
+		p.resumed = make(chan struct{})
+	}
+}
+
+// Continue resumes a paused run, a pause requested but not reached yet is dropped.
+func (p *Pause) Continue() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resumed != nil {
+		close(p.resumed)
+		p.resumed = nil
+	}
+}
+
+// requested returns a channel closed on Continue if a pause is requested, nil otherwise.
+func (p *Pause) requested() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.resumed
+}
+
+// beforeIteration runs at the start of an iteration, after its checkpoint is saved. it stops the run
+// on request or once the run window closes, and holds it while the disk guard finds a problem or a pause is requested.
+func (r *Runner) beforeIteration(ctx context.Context) error {
+	if err := r.checkStop(); err != nil {
+		return err
+	}
+	if err := r.checkWindow(); err != nil {
+		return err
+	}
+	if err := r.guardDisk(ctx); err != nil {
+		return err
+	}
+	return r.waitIfPaused(ctx)
+}
+
+// waitIfPaused holds the run at the start of an iteration while a pause is requested.
+// the checkpoint of the iteration is saved before, so a paused run stopped for good can be resumed.
+func (r *Runner) waitIfPaused(ctx context.Context) error {
+	if r.cfg.Pause == nil {
+		return nil
+	}
+	resumed := r.cfg.Pause.requested()
+	if resumed == nil {
+		return nil
+	}
+	r.log.Print("[PAUSED] run paused before %s iteration %d, checkpoint saved, waiting to continue",
+		r.position.Step, r.position.Iteration+1)
+	select {
+	case <-resumed:
+		r.log.Print("continuing after pause")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("paused: %w", ctx.Err())
+	}
+}
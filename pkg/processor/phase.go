@@ -0,0 +1,58 @@
+package processor
+
+import (
+	"context"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// runPhase runs a phase between its pre and post hooks, limited to d, see withPhaseTimeout.
+// a failed phase is handled by its failure policy, see phaseErrorPolicy. the post hook runs only
+// if the phase succeeded. hooks are not part of the phase time limit.
+func (r *Runner) runPhase(ctx context.Context, phase status.Phase, d time.Duration, fn func(context.Context) error) (err error) {
+	r.emit(Event{Type: EventPhaseStarted, Phase: phase})
+	defer func() { r.emit(Event{Type: EventPhaseCompleted, Phase: phase, Err: err}) }()
+	points := phaseHooks[phase]
+	if err = r.runHook(ctx, points[0]); err != nil {
+		return err
+	}
+	err = r.withPhaseTimeout(ctx, phase, d, fn)
+	if err != nil && ctx.Err() == nil {
+		switch r.phaseErrorPolicy(phase) {
+		case config.OnErrorRetry:
+			r.log.Print("[WARN] %s phase failed: %v, retrying the phase", phase, err)
+			err = r.withPhaseTimeout(ctx, phase, d, fn)
+		case config.OnErrorSkip:
+			r.log.Print("[WARN] %s phase failed, skipping: %v", phase, err)
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err = r.enforceDepPolicy(ctx); err != nil {
+		return err
+	}
+	return r.runHook(ctx, points[1])
+}
+
+// phaseErrorPolicy returns the configured failure policy of a phase, config.OnErrorAbort if not set.
+// cancellation of the run, including its time budget, always aborts.
+func (r *Runner) phaseErrorPolicy(phase status.Phase) string {
+	if r.cfg.AppConfig == nil {
+		return config.OnErrorAbort
+	}
+	policy := ""
+	switch phase {
+	case status.PhaseCodex:
+		policy = r.cfg.AppConfig.OnCodexError
+	case status.PhaseReview:
+		policy = r.cfg.AppConfig.OnReviewError
+	}
+	if policy == "" {
+		return config.OnErrorAbort
+	}
+	return policy
+}
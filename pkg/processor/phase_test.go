@@ -0,0 +1,91 @@
+package processor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestRunner_PhaseErrorPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		codex      []executor.Result
+		claude     []executor.Result
+		wantErr    string
+		wantCodex  int
+		wantClaude int
+	}{
+		{name: "abort by default", codex: []executor.Result{{Error: errors.New("codex crashed")}},
+			wantErr: "codex loop: codex execution: codex crashed", wantCodex: 1},
+		{name: "skip", policy: config.OnErrorSkip, codex: []executor.Result{{Error: errors.New("codex crashed")}},
+			claude: []executor.Result{{Output: "review done", Signal: status.ReviewDone}}, wantCodex: 1, wantClaude: 1},
+		{name: "retry succeeds", policy: config.OnErrorRetry,
+			codex: []executor.Result{{Error: errors.New("codex crashed")}, {Output: "a.go:1 unchecked error"}},
+			claude: []executor.Result{
+				{Output: "fixed", Signal: status.CodexDone},
+				{Output: "review done", Signal: status.ReviewDone},
+			}, wantCodex: 2, wantClaude: 2},
+		{name: "retry fails", policy: config.OnErrorRetry,
+			codex:   []executor.Result{{Error: errors.New("codex crashed")}, {Error: errors.New("codex crashed again")}},
+			wantErr: "codex crashed again", wantCodex: 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			appCfg := testAppConfig(t)
+			appCfg.OnCodexError = tc.policy
+			claude, codex := newMockExecutor(tc.claude), newMockExecutor(tc.codex)
+			cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true,
+				IterationDelayMs: 1, AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+			_, err := r.Run(context.Background())
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Len(t, codex.RunCalls(), tc.wantCodex)
+			assert.Len(t, claude.RunCalls(), tc.wantClaude)
+		})
+	}
+
+	t.Run("skipped review", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.OnReviewError = config.OnErrorSkip
+		claude := newMockExecutor([]executor.Result{
+			{Signal: status.Failed},                            // first review fails
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review
+		})
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, IterationDelayMs: 1, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, claude.RunCalls(), 2)
+	})
+
+	t.Run("cancellation aborts", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		appCfg := testAppConfig(t)
+		appCfg.OnCodexError = config.OnErrorSkip
+		codex := newMockExecutor(nil)
+		codex.RunFunc = func(context.Context, string) executor.Result {
+			cancel()
+			return executor.Result{Error: context.Canceled}
+		}
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true,
+			IterationDelayMs: 1, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), newMockExecutor(nil), codex, nil,
+			&status.PhaseHolder{})
+		_, err := r.Run(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,142 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// runPipeline executes the custom pipeline of Config.Phases in place of the full mode.
+// phases run in the configured order and may repeat. a failed optional phase is logged and
+// the pipeline continues, cancellation and failures of other phases stop it.
+func (r *Runner) runPipeline(ctx context.Context) error {
+	phases := r.cfg.Phases
+	if r.cfg.PlanFile == "" && slices.ContainsFunc(phases, func(p config.PhaseSpec) bool { return p.Name == config.PhaseTask }) {
+		return errors.New("plan file required for a pipeline with task phase")
+	}
+	first := 0
+	if r.resume != nil {
+		if r.resume.Stage >= len(phases) {
+			return fmt.Errorf("checkpoint is at phase %d, the pipeline has %d phases", r.resume.Stage+1, len(phases))
+		}
+		first = r.resume.Stage
+	}
+	firstReview := slices.IndexFunc(phases, func(p config.PhaseSpec) bool { return p.Name == config.PhaseReview })
+	r.log.Print("pipeline: %s", FormatPipeline(phases))
+
+	diffPrepared := false
+	for i := first; i < len(phases); i++ {
+		spec := phases[i]
+		r.stage = i
+		if spec.Name != config.PhaseTask && !diffPrepared {
+			r.prepareReviewDiff()
+			diffPrepared = true
+		}
+		err := r.runPipelinePhase(ctx, spec.Name, i == firstReview)
+		r.resume = nil // the resumed phase is done, later phases start from scratch
+		if err == nil {
+			continue
+		}
+		if !spec.Optional || ctx.Err() != nil {
+			return fmt.Errorf("%s phase (%d of %d): %w", spec.Name, i+1, len(phases), err)
+		}
+		r.log.Print("[WARN] optional %s phase failed, continuing: %v", spec.Name, err)
+	}
+
+	r.log.Print("all phases completed successfully")
+	return nil
+}
+
+// runPipelinePhase runs a single phase of a custom pipeline. the first review of the pipeline
+// starts with a pass addressing all findings, later reviews run the critical/major review loop only.
+func (r *Runner) runPipelinePhase(ctx context.Context, name string, firstReview bool) error {
+	switch name {
+	case config.PhaseTask:
+		r.phaseHolder.Set(status.PhaseTask)
+		r.log.PrintRaw("starting task execution phase\n")
+		return r.runPhase(ctx, status.PhaseTask, r.cfg.TaskTimeout, r.runTaskPhase)
+	case config.PhaseReview:
+		review := r.runPreExternalReview
+		if !firstReview {
+			review = func(ctx context.Context) error {
+				r.phaseHolder.Set(status.PhaseReview)
+				return r.runClaudeReviewLoop(ctx, StepPostReview)
+			}
+		}
+		mark := r.diffMark(config.ShowDiffPhase)
+		err := r.runPhase(ctx, status.PhaseReview, r.cfg.ReviewTimeout, review)
+		r.showDiff(mark, "claude review phase")
+		return err
+	case config.PhaseCodex:
+		r.phaseHolder.Set(status.PhaseCodex)
+		r.log.PrintSection(status.NewGenericSection("codex external review"))
+		mark := r.diffMark(config.ShowDiffPhase)
+		err := r.runPhase(ctx, status.PhaseCodex, r.cfg.CodexTimeout, r.runCodexLoop)
+		r.showDiff(mark, "external review phase")
+		return err
+	case config.PhaseFinalize:
+		return r.finalize(ctx)
+	default:
+		return fmt.Errorf("unknown phase %q", name)
+	}
+}
+
+// dryRunPipelinePrompts renders prompts of the custom pipeline in order, each distinct prompt once
+func (r *Runner) dryRunPipelinePrompts() ([]dryRunPrompt, error) {
+	var res []dryRunPrompt
+	shown := map[string]bool{}
+	add := func(phase status.Phase, label, agent, text string) {
+		if shown[label] {
+			return
+		}
+		shown[label] = true
+		res = append(res, dryRunPrompt{phase: phase, label: label, agent: agent, text: text})
+	}
+
+	firstReview := true
+	for _, p := range r.cfg.Phases {
+		switch p.Name {
+		case config.PhaseTask:
+			if r.cfg.PlanFile == "" {
+				return nil, errors.New("plan file required for a pipeline with task phase")
+			}
+			prompt, _, err := r.taskPrompt()
+			if err != nil {
+				return nil, err
+			}
+			add(status.PhaseTask, "task iteration", "claude", prompt)
+		case config.PhaseReview:
+			if firstReview {
+				add(status.PhaseReview, "claude review 0: all findings", "claude", r.reviewPrompt(r.cfg.AppConfig.ReviewFirstPrompt))
+				firstReview = false
+			}
+			add(status.PhaseReview, "claude review: critical/major", "claude", r.reviewPrompt(r.cfg.AppConfig.ReviewSecondPrompt))
+		case config.PhaseCodex:
+			switch r.externalReviewTool() {
+			case "codex":
+				add(status.PhaseCodex, "codex review", "codex", r.buildCodexPrompt(true, ""))
+				add(status.PhaseClaudeEval, "claude evaluating codex findings", "claude", r.buildCodexEvaluationPrompt(dryRunFindings))
+			case "custom":
+				add(status.PhaseCodex, "custom review", "custom", r.buildCustomReviewPrompt(true, ""))
+				add(status.PhaseClaudeEval, "claude evaluating custom review findings", "claude", r.buildCustomEvaluationPrompt(dryRunFindings))
+			}
+		case config.PhaseFinalize:
+			add(status.PhaseFinalize, "finalize step", "claude", r.replacePromptVariables(r.cfg.AppConfig.FinalizePrompt))
+		}
+	}
+	return res, nil
+}
+
+// FormatPipeline returns the phases of a custom pipeline as an arrow-separated list, e.g. "task → review → codex? → review".
+func FormatPipeline(phases []config.PhaseSpec) string {
+	names := make([]string, 0, len(phases))
+	for _, p := range phases {
+		names = append(names, p.String())
+	}
+	return strings.Join(names, " → ")
+}
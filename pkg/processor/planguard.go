@@ -0,0 +1,110 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// errPlanRestart asks the task phase to start over with the plan edited during the run, see checkPlanEdited
+var errPlanRestart = errors.New("plan file edited, restarting the task phase")
+
+// planEditedQuestion is asked when the plan file changed between task iterations
+const planEditedQuestion = "The plan file was edited during the run. Answer reload to continue with the edited plan, " +
+	"restart to start the task phase over with it, or abort to stop the run."
+
+// planContent returns the content of the plan file, empty if it can't be read
+func (r *Runner) planContent() string {
+	data, err := os.ReadFile(r.resolvePlanFilePath())
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// checkPlanEdited asks a person what to do when the plan file changed since the agent's last iteration,
+// e.g. edited between iterations or while the run was paused, instead of the agent and the person fighting
+// over it. returns true to continue with the edited plan, errPlanRestart to start the task phase over.
+// the plan is reloaded with a warning when nobody can answer, see Config.AnswerInput.
+func (r *Runner) checkPlanEdited(ctx context.Context) (bool, error) {
+	if r.cfg.PlanFile == "" {
+		return false, nil
+	}
+	content := r.planContent()
+	if content == r.planText {
+		return false, nil
+	}
+	r.log.Print("[WARN] plan file %s was edited outside of the agent", r.resolvePlanFilePath())
+	if r.cfg.AnswerInput == nil {
+		r.log.Print("nobody to ask, continuing with the edited plan")
+		r.reloadPlan(content)
+		return true, nil
+	}
+	if r.cfg.NeedsHuman != nil {
+		r.cfg.NeedsHuman("plan file edited during the run")
+	}
+	for {
+		answer, err := r.cfg.AnswerInput(ctx, planEditedQuestion)
+		if err != nil {
+			return false, fmt.Errorf("answer to plan edit: %w", err)
+		}
+		r.log.LogAnswer(answer)
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "reload":
+			r.reloadPlan(content)
+			return true, nil
+		case "restart":
+			r.reloadPlan(content)
+			return true, errPlanRestart
+		case "abort":
+			return false, errors.New("plan file edited during the run, aborted")
+		}
+		r.log.Print("unknown answer %q, expected reload, restart or abort", answer)
+	}
+}
+
+// reloadPlan takes the edited plan content for the next task iteration. items the agent checked stay checked,
+// the edit is written back with them when needed, see plan.MergeChecked, and the next task prompt gets the lines
+// the person added and removed, see planUpdateNote.
+func (r *Runner) reloadPlan(content string) {
+	merged, rechecked := plan.MergeChecked(r.planText, content)
+	if len(rechecked) > 0 {
+		if err := os.WriteFile(r.resolvePlanFilePath(), []byte(merged), 0o600); err != nil {
+			r.log.Print("[WARN] can't keep items checked by the agent in the edited plan: %v", err)
+			merged = content
+		} else {
+			r.log.Print("%d items checked by the agent were unchecked in the edit, kept them checked: %s",
+				len(rechecked), strings.Join(rechecked, "; "))
+		}
+	}
+	added, removed := plan.ChangedLines(r.planText, merged)
+	r.planText = merged
+	r.planNote = planUpdateNote(added, removed)
+}
+
+// planUpdateNote returns the note about the plan edited by a person, appended to the next task prompt.
+func planUpdateNote(added, removed []string) string {
+	var b strings.Builder
+	b.WriteString("\n\n---\nPLAN UPDATED:\nA person edited the plan file since your previous iteration. " +
+		"Re-read it before continuing and follow the edited tasks and notes. Items you checked are kept checked.\n")
+	if len(added) == 0 && len(removed) == 0 {
+		b.WriteString("Only the checked state of items changed.\n")
+	}
+	if len(added) > 0 {
+		b.WriteString("\nAdded lines:\n")
+		for _, l := range added {
+			fmt.Fprintf(&b, "+ %s\n", l)
+		}
+	}
+	if len(removed) > 0 {
+		b.WriteString("\nRemoved lines:\n")
+		for _, l := range removed {
+			fmt.Fprintf(&b, "- %s\n", l)
+		}
+	}
+	return b.String()
+}
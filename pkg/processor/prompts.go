@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/plan"
 )
 
 // agentRefPattern matches {{agent:name}} template syntax
@@ -67,32 +69,106 @@ func (r *Runner) getProgressFileRef() string {
 // replaceBaseVariables replaces common template variables in prompts.
 // supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{PLANS_DIR}}
 // this is the core replacement function used by all prompt builders.
+// values are substituted in a single pass, so a value containing {{...}} is not expanded again.
 func (r *Runner) replaceBaseVariables(prompt string) string {
-	result := prompt
-	result = strings.ReplaceAll(result, "{{PLAN_FILE}}", r.getPlanFileRef())
-	result = strings.ReplaceAll(result, "{{PROGRESS_FILE}}", r.getProgressFileRef())
-	result = strings.ReplaceAll(result, "{{GOAL}}", r.getGoal())
-	result = strings.ReplaceAll(result, "{{DEFAULT_BRANCH}}", r.getDefaultBranch())
-	result = strings.ReplaceAll(result, "{{PLANS_DIR}}", r.getPlansDir())
-	return result
+	return strings.NewReplacer(
+		"{{PLAN_FILE}}", r.getPlanFileRef(),
+		"{{PROGRESS_FILE}}", r.getProgressFileRef(),
+		"{{GOAL}}", r.getGoal(),
+		"{{DEFAULT_BRANCH}}", r.getDefaultBranch(),
+		"{{PLANS_DIR}}", r.getPlansDir(),
+	).Replace(prompt)
 }
 
 // getDiffInstruction returns the appropriate git diff command based on iteration.
 // first iteration: compares default branch to HEAD (all changes in feature branch)
 // subsequent iterations: shows uncommitted changes only (fixes from previous iteration)
-func (r *Runner) getDiffInstruction(isFirstIteration bool) string {
+// submodule changes are shown as commit logs, LFS files are excluded since the diff only has their pointers.
+func (r *Runner) getDiffInstruction(isFirstIteration bool, sc git.SpecialChanges) string {
+	cmd := "git diff"
+	if len(sc.Submodules) > 0 {
+		cmd += " --submodule=log"
+	}
+	if isFirstIteration {
+		cmd += fmt.Sprintf(" %s...HEAD", r.getDefaultBranch())
+	}
+	if len(sc.LFSFiles) > 0 {
+		cmd += " -- ."
+		for _, f := range sc.LFSFiles {
+			cmd += " " + shellQuote(":(exclude)"+f)
+		}
+	}
+	return cmd
+}
+
+// specialChanges returns submodule and LFS changes in the diff reviewed at this iteration,
+// using the same range as getDiffInstruction. returns zero value without a git checker or on error.
+func (r *Runner) specialChanges(isFirstIteration bool) git.SpecialChanges {
+	if r.git == nil {
+		return git.SpecialChanges{}
+	}
+	baseBranch := ""
 	if isFirstIteration {
-		return fmt.Sprintf("git diff %s...HEAD", r.getDefaultBranch())
+		baseBranch = r.getDefaultBranch()
+	}
+	sc, err := r.git.SpecialChanges(baseBranch)
+	if err != nil {
+		r.log.Print("[WARN] failed to check submodule and LFS changes: %v", err)
+		return git.SpecialChanges{}
+	}
+	return sc
+}
+
+// specialChangesNote summarizes submodule and LFS changes for reviewers, empty if there are none.
+// their textual diff is only commit ids or LFS pointer files, which reviewers tend to misread.
+func specialChangesNote(sc git.SpecialChanges) string {
+	if sc.Empty() {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\n---\nSUBMODULE AND LFS CHANGES:\n")
+	if len(sc.Submodules) > 0 {
+		sb.WriteString("Submodule pointer changes (the diff lists submodule commits, files inside submodules are not shown):\n")
+		for _, s := range sc.Submodules {
+			switch {
+			case s.From == "":
+				fmt.Fprintf(&sb, "- %s: added at %s\n", s.Path, shortHash(s.To))
+			case s.To == "":
+				fmt.Fprintf(&sb, "- %s: removed, was at %s\n", s.Path, shortHash(s.From))
+			default:
+				fmt.Fprintf(&sb, "- %s: %s -> %s\n", s.Path, shortHash(s.From), shortHash(s.To))
+			}
+		}
+	}
+	if len(sc.LFSFiles) > 0 {
+		sb.WriteString("Git LFS files changed (content is stored outside git and excluded from the diff command):\n")
+		for _, f := range sc.LFSFiles {
+			fmt.Fprintf(&sb, "- %s\n", f)
+		}
 	}
-	return "git diff"
+	sb.WriteString("Do not report these paths as missing, binary or unreadable content.")
+	return sb.String()
+}
+
+// shortHash abbreviates a commit hash for display.
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// shellQuote quotes s for use as a single POSIX shell argument.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
 // replaceVariablesWithIteration replaces all template variables including iteration-aware ones.
 // supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{PLANS_DIR}}, {{DIFF_INSTRUCTION}}, {{agent:name}}
 // this variant is used when iteration context is needed (e.g., custom review prompts).
-func (r *Runner) replaceVariablesWithIteration(prompt string, isFirstIteration bool) string {
+func (r *Runner) replaceVariablesWithIteration(prompt string, isFirstIteration bool, sc git.SpecialChanges) string {
 	result := r.replaceBaseVariables(prompt)
-	result = strings.ReplaceAll(result, "{{DIFF_INSTRUCTION}}", r.getDiffInstruction(isFirstIteration))
+	result = strings.ReplaceAll(result, "{{DIFF_INSTRUCTION}}", r.getDiffInstruction(isFirstIteration, sc))
 	result = r.expandAgentReferences(result)
 	return result
 }
@@ -122,17 +198,11 @@ func (r *Runner) expandAgentReferences(prompt string) string {
 	if r.cfg.AppConfig == nil {
 		return prompt
 	}
-	agents := r.cfg.AppConfig.CustomAgents
-	if len(agents) == 0 {
+	agentMap := r.agents()
+	if len(agentMap) == 0 {
 		return prompt
 	}
 
-	// build agent lookup map
-	agentMap := make(map[string]config.CustomAgent, len(agents))
-	for _, agent := range agents {
-		agentMap[agent.Name] = agent
-	}
-
 	return agentRefPattern.ReplaceAllStringFunc(prompt, func(match string) string {
 		// extract name directly from match: {{agent:NAME}} -> NAME
 		name := match[8 : len(match)-2] // skip "{{agent:" and "}}"
@@ -152,17 +222,62 @@ func (r *Runner) expandAgentReferences(prompt string) string {
 	})
 }
 
+// agents returns configured custom agents indexed by name. the index is built once,
+// agents don't change after config is loaded.
+func (r *Runner) agents() map[string]config.CustomAgent {
+	if r.agentIndex == nil {
+		r.agentIndex = make(map[string]config.CustomAgent, len(r.cfg.AppConfig.CustomAgents))
+		for _, agent := range r.cfg.AppConfig.CustomAgents {
+			r.agentIndex[agent.Name] = agent
+		}
+	}
+	return r.agentIndex
+}
+
 // replacePromptVariables replaces all template variables including agent references.
 // supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{PLANS_DIR}}, {{agent:name}}
 // note: {{CODEX_OUTPUT}} and {{PLAN_DESCRIPTION}} are handled by specific build functions.
+// the language note is appended, see languageNote.
 func (r *Runner) replacePromptVariables(prompt string) string {
 	result := r.replaceBaseVariables(prompt)
 	result = r.expandAgentReferences(result)
-	return result
+	return result + r.languageNote()
+}
+
+// reviewPrompt returns a claude review prompt from its template, with the review instructions of the project.
+func (r *Runner) reviewPrompt(tmpl string) string {
+	return r.replacePromptVariables(tmpl + instructionsNote(r.cfg.AppConfig.ReviewInstructions))
+}
+
+// instructionsNote returns the project instructions appended to the prompts of a phase, empty without any,
+// see config.Config.TaskInstructions, ReviewInstructions and CodexInstructions.
+func instructionsNote(instructions string) string {
+	if instructions == "" {
+		return ""
+	}
+	return "\n\n---\nPROJECT INSTRUCTIONS:\nFollow these rules of the project in addition to the instructions above:\n" + instructions
+}
+
+// languageNote returns the instruction to write for people in the configured language, appended to the
+// agent prompts, empty without a language, see config.Config.Language. signals, the phrases and the
+// formats ralphex parses are kept as they are.
+func (r *Runner) languageNote() string {
+	if r.cfg.AppConfig == nil || r.cfg.AppConfig.Language == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\n---\nLANGUAGE:\nWrite everything meant for people in %s: progress notes, summaries, review "+
+		"findings, plan text and commit messages. Keep signals like <<<RALPHEX:...>>>, the exact phrases and formats "+
+		"the instructions above ask for, the markdown structure of the plan (headings, checkboxes), file paths, code "+
+		"and commands unchanged.", r.cfg.AppConfig.Language)
 }
 
 // getDefaultBranch returns the default branch name or "master" as fallback.
+// while a later milestone is reviewed it returns the commit the milestone started at, so reviews and
+// everything else diffing against the default branch see the changes of the milestone only.
 func (r *Runner) getDefaultBranch() string {
+	if r.reviewBase != "" {
+		return r.reviewBase
+	}
 	if r.cfg.DefaultBranch == "" {
 		return "master"
 	}
@@ -180,25 +295,89 @@ func (r *Runner) getPlansDir() string {
 // buildCodexEvaluationPrompt creates the prompt for claude to evaluate codex review output.
 // uses the codex prompt loaded from config (either user-provided or embedded default).
 // agent references ({{agent:name}}) are expanded via replacePromptVariables.
+// codex output is fenced as untrusted content, see guardExternal.
 func (r *Runner) buildCodexEvaluationPrompt(codexOutput string) string {
 	prompt := r.replacePromptVariables(r.cfg.AppConfig.CodexPrompt)
-	return strings.ReplaceAll(prompt, "{{CODEX_OUTPUT}}", codexOutput)
+	return strings.ReplaceAll(prompt, "{{CODEX_OUTPUT}}", r.guardExternal("codex", codexOutput))
 }
 
-// buildPlanPrompt creates the prompt for interactive plan creation.
+// buildPlanPrompt creates the prompt for plan creation.
 // uses the make_plan prompt loaded from config (either user-provided or embedded default).
-// replaces {{PLAN_DESCRIPTION}} plus all base variables.
+// replaces {{PLAN_DESCRIPTION}} plus all base variables, with the spec note when drafted from a spec file.
 func (r *Runner) buildPlanPrompt() string {
 	prompt := r.cfg.AppConfig.MakePlanPrompt
 	prompt = strings.ReplaceAll(prompt, "{{PLAN_DESCRIPTION}}", r.cfg.PlanDescription)
-	return r.replaceBaseVariables(prompt)
+	return r.replaceBaseVariables(prompt) + specNote(r.cfg.PlanSpec) + r.languageNote()
+}
+
+// specNote returns the note telling the plan creation that the request is a spec file and nobody answers questions,
+// empty for interactive plan creation.
+func specNote(spec string) string {
+	if spec == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\n---\nSPEC FILE:\nThe request above is the content of the spec file %s. Nobody answers "+
+		"questions in this run: do not emit QUESTION signals, decide open points from the spec and the codebase and "+
+		"list them as assumptions in the plan. A PLAN_DRAFT is accepted as is, write the plan file right after it.", spec)
+}
+
+// buildVerifyFixPrompt prepends a verification failure to the task prompt,
+// so the agent fixes the build before picking up the next task.
+func buildVerifyFixPrompt(taskPrompt, feedback string) string {
+	return fmt.Sprintf(`VERIFICATION FAILED after the previous iteration. Before anything else, fix this failure
+and commit the fix. Do not start or mark another task until verification passes.
+
+%s
+
+---
+%s`, feedback, taskPrompt)
+}
+
+// buildTaskSelectionPrompt prepends the tasks selected with Config.StartTask and Config.OnlyTasks to the task
+// prompt, so the agent skips the rest of the plan.
+func buildTaskSelectionPrompt(taskPrompt string, tasks []plan.Task) string {
+	var b strings.Builder
+	for _, t := range tasks {
+		fmt.Fprintf(&b, "- ### %s (line %d)\n", t.Header, t.Line)
+	}
+	return fmt.Sprintf(`TASK SELECTION for this run. Work only on these Task sections of the plan, in this order, and skip
+every other section, even if it has [ ] checkboxes:
+
+%s
+Once none of these sections has [ ] checkboxes left, output exactly: %s
+regardless of the other sections.
+
+---
+%s`, b.String(), SignalCompleted, taskPrompt)
+}
+
+// buildDepsFixPrompt asks the agent to undo dependencies added against the dependency policy
+func buildDepsFixPrompt(feedback string) string {
+	return fmt.Sprintf(`DEPENDENCY POLICY VIOLATED by changes of this run. Fix only this, do not start other work:
+
+%s
+
+Keep the behavior of the changed code, run the tests, and commit the fix. Do not output any signal.`, feedback)
+}
+
+// buildReviewDoneRejectedPrompt prepends the reason the previous review done signal was rejected to the review prompt
+func buildReviewDoneRejectedPrompt(reviewPrompt, reason string) string {
+	return fmt.Sprintf(`REVIEW DONE REJECTED after the previous review iteration. Resolve this before signaling
+that the review is done again:
+
+%s
+
+---
+%s`, reason, reviewPrompt)
 }
 
 // buildCustomReviewPrompt creates the prompt for custom review tool execution.
 // uses the custom_review prompt loaded from config with {{DIFF_INSTRUCTION}} expanded.
 // claudeResponse from previous iteration is appended if present.
 func (r *Runner) buildCustomReviewPrompt(isFirst bool, claudeResponse string) string {
-	prompt := r.replaceVariablesWithIteration(r.cfg.AppConfig.CustomReviewPrompt, isFirst)
+	sc := r.specialChanges(isFirst)
+	prompt := r.replaceVariablesWithIteration(r.cfg.AppConfig.CustomReviewPrompt, isFirst, sc) + specialChangesNote(sc) +
+		baselineNote(r.cfg.Baseline) + instructionsNote(r.cfg.AppConfig.CodexInstructions) + r.languageNote()
 
 	if claudeResponse != "" {
 		prompt = fmt.Sprintf(`%s
@@ -219,7 +398,8 @@ If Claude's arguments are invalid, explain why the issues still exist.`, prompt,
 // buildCustomEvaluationPrompt creates the prompt for claude to evaluate custom review tool output.
 // uses the custom_eval prompt loaded from config (either user-provided or embedded default).
 // agent references ({{agent:name}}) are expanded via replacePromptVariables.
+// tool output is fenced as untrusted content, see guardExternal.
 func (r *Runner) buildCustomEvaluationPrompt(customOutput string) string {
 	prompt := r.replacePromptVariables(r.cfg.AppConfig.CustomEvalPrompt)
-	return strings.ReplaceAll(prompt, "{{CUSTOM_OUTPUT}}", customOutput)
+	return strings.ReplaceAll(prompt, "{{CUSTOM_OUTPUT}}", r.guardExternal("custom review tool", customOutput))
 }
@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,6 +11,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
 )
 
 func TestRunner_replacePromptVariables_TaskPrompt(t *testing.T) {
@@ -180,7 +183,8 @@ func TestRunner_buildCodexEvaluationPrompt_CustomPrompt(t *testing.T) {
 	r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", AppConfig: appCfg}}
 	prompt := r.buildCodexEvaluationPrompt("found bug in main.go")
 
-	assert.Equal(t, "Custom codex evaluation with output: found bug in main.go for implementation of plan at docs/plans/test.md", prompt)
+	assert.Equal(t, "Custom codex evaluation with output: "+wrapUntrusted("codex", "found bug in main.go")+
+		" for implementation of plan at docs/plans/test.md", prompt)
 }
 
 func TestRunner_replacePromptVariables(t *testing.T) {
@@ -709,33 +713,168 @@ func TestRunner_buildPlanPrompt(t *testing.T) {
 func TestRunner_getDiffInstruction(t *testing.T) {
 	t.Run("first iteration uses branch diff", func(t *testing.T) {
 		r := &Runner{cfg: Config{DefaultBranch: "main"}}
-		result := r.getDiffInstruction(true)
+		result := r.getDiffInstruction(true, git.SpecialChanges{})
 		assert.Equal(t, "git diff main...HEAD", result)
 	})
 
 	t.Run("subsequent iteration uses uncommitted diff", func(t *testing.T) {
 		r := &Runner{cfg: Config{DefaultBranch: "main"}}
-		result := r.getDiffInstruction(false)
+		result := r.getDiffInstruction(false, git.SpecialChanges{})
 		assert.Equal(t, "git diff", result)
 	})
 
 	t.Run("uses default branch fallback", func(t *testing.T) {
 		r := &Runner{cfg: Config{}}
-		result := r.getDiffInstruction(true)
+		result := r.getDiffInstruction(true, git.SpecialChanges{})
 		assert.Equal(t, "git diff master...HEAD", result)
 	})
 }
 
+func TestRunner_getDiffInstruction_SpecialChanges(t *testing.T) {
+	r := &Runner{cfg: Config{DefaultBranch: "main"}}
+	sc := git.SpecialChanges{
+		Submodules: []git.SubmoduleChange{{Path: "libs/dep", From: "aaa", To: "bbb"}},
+		LFSFiles:   []string{"assets/logo.png", "data/it's.bin"},
+	}
+
+	assert.Equal(t, `git diff --submodule=log main...HEAD -- . ':(exclude)assets/logo.png' ':(exclude)data/it'\''s.bin'`,
+		r.getDiffInstruction(true, sc))
+	assert.Equal(t, "git diff --submodule=log", r.getDiffInstruction(false, git.SpecialChanges{Submodules: sc.Submodules}))
+}
+
+func TestSpecialChangesNote(t *testing.T) {
+	assert.Empty(t, specialChangesNote(git.SpecialChanges{}))
+
+	note := specialChangesNote(git.SpecialChanges{
+		Submodules: []git.SubmoduleChange{
+			{Path: "libs/moved", From: "1111111111111111111111111111111111111111", To: "2222222222222222222222222222222222222222"},
+			{Path: "libs/added", To: "3333333333333333333333333333333333333333"},
+			{Path: "libs/removed", From: "4444444444444444444444444444444444444444"},
+		},
+		LFSFiles: []string{"assets/video.mp4"},
+	})
+	assert.Contains(t, note, "- libs/moved: 111111111111 -> 222222222222\n")
+	assert.Contains(t, note, "- libs/added: added at 333333333333\n")
+	assert.Contains(t, note, "- libs/removed: removed, was at 444444444444\n")
+	assert.Contains(t, note, "Git LFS files changed")
+	assert.Contains(t, note, "- assets/video.mp4\n")
+}
+
+func TestRunner_Instructions(t *testing.T) {
+	const note = "\n\n---\nPROJECT INSTRUCTIONS:\nFollow these rules of the project in addition to the instructions above:\n"
+	gitMock := &mocks.GitCheckerMock{SpecialChangesFunc: func(string) (git.SpecialChanges, error) { return git.SpecialChanges{}, nil }}
+	appCfg := testAppConfig(t)
+	appCfg.TaskPrompt, appCfg.ReviewFirstPrompt, appCfg.CustomReviewPrompt = "Do {{PLAN_FILE}}", "Review", "Custom review"
+	r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", DefaultBranch: "main", AppConfig: appCfg},
+		log: newMockLogger(""), git: gitMock}
+
+	t.Run("none", func(t *testing.T) {
+		prompt, _, err := r.taskPrompt()
+		require.NoError(t, err)
+		assert.Equal(t, "Do docs/plans/test.md", prompt)
+		assert.Equal(t, "Review", r.reviewPrompt(appCfg.ReviewFirstPrompt))
+		assert.NotContains(t, r.buildCodexPrompt(true, ""), "PROJECT INSTRUCTIONS")
+	})
+
+	t.Run("per phase", func(t *testing.T) {
+		appCfg.TaskInstructions = "always update {{PLAN_FILE}}"
+		appCfg.ReviewInstructions = "check docs"
+		appCfg.CodexInstructions = "ignore generated files"
+		t.Cleanup(func() { appCfg.TaskInstructions, appCfg.ReviewInstructions, appCfg.CodexInstructions = "", "", "" })
+
+		prompt, _, err := r.taskPrompt()
+		require.NoError(t, err)
+		assert.Equal(t, "Do docs/plans/test.md"+note+"always update docs/plans/test.md", prompt, "variables expanded")
+		assert.Equal(t, "Review"+note+"check docs", r.reviewPrompt(appCfg.ReviewFirstPrompt))
+		assert.Contains(t, r.buildCodexPrompt(true, ""), note+"ignore generated files")
+		assert.Equal(t, "Custom review"+note+"ignore generated files", r.buildCustomReviewPrompt(true, ""))
+	})
+}
+
+func TestRunner_Language(t *testing.T) {
+	gitMock := &mocks.GitCheckerMock{SpecialChangesFunc: func(string) (git.SpecialChanges, error) { return git.SpecialChanges{}, nil }}
+	appCfg := testAppConfig(t)
+	appCfg.TaskPrompt, appCfg.ReviewFirstPrompt, appCfg.CustomReviewPrompt = "Do {{PLAN_FILE}}", "Review", "Custom review"
+	appCfg.MakePlanPrompt = "Plan {{PLAN_DESCRIPTION}}"
+	r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", PlanDescription: "auth", DefaultBranch: "main", AppConfig: appCfg},
+		log: newMockLogger(""), git: gitMock}
+
+	t.Run("none", func(t *testing.T) {
+		prompt, _, err := r.taskPrompt()
+		require.NoError(t, err)
+		assert.Equal(t, "Do docs/plans/test.md", prompt)
+		assert.Equal(t, "Plan auth", r.buildPlanPrompt())
+		assert.NotContains(t, r.buildCodexPrompt(true, ""), "LANGUAGE:")
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		appCfg.Language = "German"
+		t.Cleanup(func() { appCfg.Language = "" })
+
+		note := r.languageNote()
+		assert.Contains(t, note, "\n\n---\nLANGUAGE:\nWrite everything meant for people in German")
+		assert.Contains(t, note, "<<<RALPHEX:...>>>", "signals kept")
+
+		prompt, _, err := r.taskPrompt()
+		require.NoError(t, err)
+		assert.Equal(t, "Do docs/plans/test.md"+note, prompt)
+		assert.Equal(t, "Review"+note, r.reviewPrompt(appCfg.ReviewFirstPrompt))
+		assert.Equal(t, "Plan auth"+note, r.buildPlanPrompt())
+		assert.Equal(t, "Custom review"+note, r.buildCustomReviewPrompt(true, ""))
+		assert.Contains(t, r.buildCodexPrompt(true, ""), note)
+	})
+}
+
+func TestRunner_buildCodexPrompt_SpecialChanges(t *testing.T) {
+	gitMock := &mocks.GitCheckerMock{
+		SpecialChangesFunc: func(baseBranch string) (git.SpecialChanges, error) {
+			if baseBranch == "" {
+				return git.SpecialChanges{}, nil // no special changes among uncommitted fixes
+			}
+			return git.SpecialChanges{LFSFiles: []string{"model.bin"}}, nil
+		},
+	}
+	r := &Runner{cfg: Config{DefaultBranch: "main", AppConfig: testAppConfig(t)}, log: newMockLogger(""), git: gitMock}
+
+	prompt := r.buildCodexPrompt(true, "")
+	assert.Contains(t, prompt, "Run: git diff main...HEAD -- . ':(exclude)model.bin'")
+	assert.Contains(t, prompt, "- model.bin\n")
+
+	prompt = r.buildCodexPrompt(false, "")
+	assert.Contains(t, prompt, "Run: git diff\n")
+	assert.NotContains(t, prompt, "SUBMODULE AND LFS CHANGES")
+
+	calls := gitMock.SpecialChangesCalls()
+	require.Len(t, calls, 2)
+	assert.Equal(t, "main", calls[0].BaseBranch)
+	assert.Empty(t, calls[1].BaseBranch)
+}
+
+func TestRunner_buildCustomReviewPrompt_SpecialChangesError(t *testing.T) {
+	var warned bool
+	log := newMockLogger("")
+	log.PrintFunc = func(format string, _ ...any) { warned = warned || strings.HasPrefix(format, "[WARN]") }
+	gitMock := &mocks.GitCheckerMock{
+		SpecialChangesFunc: func(string) (git.SpecialChanges, error) { return git.SpecialChanges{}, errors.New("boom") },
+	}
+	appCfg := testAppConfig(t)
+	appCfg.CustomReviewPrompt = "Review: {{DIFF_INSTRUCTION}}"
+	r := &Runner{cfg: Config{DefaultBranch: "main", AppConfig: appCfg}, log: log, git: gitMock}
+
+	assert.Equal(t, "Review: git diff main...HEAD", r.buildCustomReviewPrompt(true, ""))
+	assert.True(t, warned, "git failure is logged as warning")
+}
+
 func TestRunner_replaceVariablesWithIteration(t *testing.T) {
 	t.Run("replaces DIFF_INSTRUCTION for first iteration", func(t *testing.T) {
 		r := &Runner{cfg: Config{DefaultBranch: "main"}}
-		result := r.replaceVariablesWithIteration("Run: {{DIFF_INSTRUCTION}}", true)
+		result := r.replaceVariablesWithIteration("Run: {{DIFF_INSTRUCTION}}", true, git.SpecialChanges{})
 		assert.Equal(t, "Run: git diff main...HEAD", result)
 	})
 
 	t.Run("replaces DIFF_INSTRUCTION for subsequent iteration", func(t *testing.T) {
 		r := &Runner{cfg: Config{DefaultBranch: "main"}}
-		result := r.replaceVariablesWithIteration("Run: {{DIFF_INSTRUCTION}}", false)
+		result := r.replaceVariablesWithIteration("Run: {{DIFF_INSTRUCTION}}", false, git.SpecialChanges{})
 		assert.Equal(t, "Run: git diff", result)
 	})
 
@@ -746,7 +885,7 @@ func TestRunner_replaceVariablesWithIteration(t *testing.T) {
 			DefaultBranch: "develop",
 		}}
 		prompt := "Plan: {{PLAN_FILE}}, Progress: {{PROGRESS_FILE}}, Goal: {{GOAL}}, Branch: {{DEFAULT_BRANCH}}, Diff: {{DIFF_INSTRUCTION}}"
-		result := r.replaceVariablesWithIteration(prompt, true)
+		result := r.replaceVariablesWithIteration(prompt, true, git.SpecialChanges{})
 
 		assert.Contains(t, result, "Plan: docs/plans/test.md")
 		assert.Contains(t, result, "Progress: progress.txt")
@@ -761,7 +900,7 @@ func TestRunner_replaceVariablesWithIteration(t *testing.T) {
 			CustomAgents: []config.CustomAgent{{Name: "test-agent", Prompt: "test prompt"}},
 		}
 		r := &Runner{cfg: Config{DefaultBranch: "main", AppConfig: appCfg}, log: newMockLogger("")}
-		result := r.replaceVariablesWithIteration("Diff: {{DIFF_INSTRUCTION}}, Agent: {{agent:test-agent}}", true)
+		result := r.replaceVariablesWithIteration("Diff: {{DIFF_INSTRUCTION}}, Agent: {{agent:test-agent}}", true, git.SpecialChanges{})
 
 		assert.Contains(t, result, "Diff: git diff main...HEAD")
 		assert.Contains(t, result, "test prompt")
@@ -770,7 +909,7 @@ func TestRunner_replaceVariablesWithIteration(t *testing.T) {
 
 	t.Run("handles prompt without DIFF_INSTRUCTION", func(t *testing.T) {
 		r := &Runner{cfg: Config{DefaultBranch: "main"}}
-		result := r.replaceVariablesWithIteration("Plan: {{PLAN_FILE}}", true)
+		result := r.replaceVariablesWithIteration("Plan: {{PLAN_FILE}}", true, git.SpecialChanges{})
 		assert.Contains(t, result, "(no plan file - reviewing current branch)")
 	})
 }
@@ -879,6 +1018,59 @@ func TestRunner_buildCustomEvaluationPrompt(t *testing.T) {
 
 		prompt := r.buildCustomEvaluationPrompt("security issue found")
 
-		assert.Equal(t, "Evaluate output: security issue found. Goal: implementation of plan at docs/plans/test.md", prompt)
+		assert.Equal(t, "Evaluate output: "+wrapUntrusted("custom review tool", "security issue found")+
+			". Goal: implementation of plan at docs/plans/test.md", prompt)
 	})
 }
+
+func BenchmarkRunner_buildCodexEvaluationPrompt(b *testing.B) {
+	appCfg := testAppConfig(b)
+	r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", DefaultBranch: "main", AppConfig: appCfg}}
+	output := strings.Repeat("pkg/foo/bar.go:42 - unchecked error returned from Close, wrap and handle it\n", 20000)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = r.buildCodexEvaluationPrompt(output)
+	}
+}
+
+func BenchmarkRunner_replaceVariablesWithIteration(b *testing.B) {
+	appCfg := testAppConfig(b)
+	r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", DefaultBranch: "main", AppConfig: appCfg}, log: newMockLogger("")}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = r.replaceVariablesWithIteration(appCfg.ReviewFirstPrompt, true, git.SpecialChanges{})
+	}
+}
+
+func BenchmarkRunner_buildCustomReviewPrompt(b *testing.B) {
+	appCfg := testAppConfig(b)
+	r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", DefaultBranch: "main", AppConfig: appCfg}, log: newMockLogger("")}
+	// simulate a long previous response quoting a large diff
+	response := strings.Repeat("+\tif err := doSomething(ctx); err != nil {\n+\t\treturn fmt.Errorf(\"do: %w\", err)\n+\t}\n", 10000)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = r.buildCustomReviewPrompt(false, response)
+	}
+}
+
+func TestRunner_replaceBaseVariables_SinglePass(t *testing.T) {
+	// a value containing a variable must not be expanded again
+	r := &Runner{cfg: Config{PlanFile: "docs/plans/{{GOAL}}.md", AppConfig: &config.Config{}}}
+	got := r.replaceBaseVariables("plan: {{PLAN_FILE}}")
+	assert.Equal(t, "plan: docs/plans/{{GOAL}}.md", got)
+}
+
+func TestRunner_agents_IndexBuiltOnce(t *testing.T) {
+	appCfg := &config.Config{CustomAgents: []config.CustomAgent{{Name: "quality", Prompt: "check quality"}}}
+	r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
+
+	first := r.agents()
+	require.Contains(t, first, "quality")
+
+	appCfg.CustomAgents = nil // agents are fixed after config load, cached index is reused
+	assert.Equal(t, first, r.agents())
+	assert.Contains(t, r.expandAgentReferences("{{agent:quality}}"), "check quality")
+}
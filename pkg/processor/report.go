@@ -0,0 +1,76 @@
+package processor
+
+import (
+	"time"
+
+	"github.com/umputun/ralphex/pkg/verify"
+)
+
+// RunReport summarizes a run for wrappers and CI, Run returns it also when the run fails.
+type RunReport struct {
+	Mode     Mode          `json:"mode"`
+	Steps    []StepReport  `json:"steps"` // steps in the order they ran, a step repeated by a later round is listed again
+	Duration time.Duration `json:"duration"`
+	Signals  []string      `json:"signals,omitempty"` // signals received from the agents, in order
+	Findings int           `json:"findings"`          // distinct external review findings
+	Files    []string      `json:"files,omitempty"`   // files changed on the branch, committed or not
+
+	PartialOutput string       `json:"partial_output,omitempty"` // tail of the output of the agent call the run was canceled in
+	Usage         *UsageReport `json:"usage,omitempty"`          // time and token use of the agent calls, nil without calls
+
+	Prompts        string            `json:"prompts,omitempty"`         // hash of the prompt templates used, see config.Config.PromptsHash
+	PromptVersions map[string]string `json:"prompt_versions,omitempty"` // hash of each prompt template by name
+
+	Licenses          []verify.ModuleLicense `json:"licenses,omitempty"`           // licenses of modules added by the run, see config.Config.LicenseCheck
+	FinalVerification *VerificationReport    `json:"final_verification,omitempty"` // nil if the final verification didn't run
+}
+
+// StepReport is a step of a run, see Step.
+type StepReport struct {
+	Step       Step          `json:"step"`
+	Iterations int           `json:"iterations"` // iterations started by the run, a resumed step counts only its new ones
+	Duration   time.Duration `json:"duration"`
+}
+
+// recordStep counts an iteration of step, starting a new step entry if another step ran before.
+func (r *Runner) recordStep(step Step) {
+	steps := r.report.Steps
+	if n := len(steps); n > 0 && steps[n-1].Step == step {
+		steps[n-1].Iterations++
+		return
+	}
+	now := time.Now()
+	r.closeStep(now)
+	r.report.Steps = append(steps, StepReport{Step: step, Iterations: 1})
+	r.stepStart = now
+}
+
+// closeStep sets the duration of the last step entry, ended at end.
+func (r *Runner) closeStep(end time.Time) {
+	if n := len(r.report.Steps); n > 0 {
+		r.report.Steps[n-1].Duration = end.Sub(r.stepStart)
+	}
+}
+
+// finishReport completes the report of a run started at start.
+func (r *Runner) finishReport(start time.Time) RunReport {
+	end := time.Now()
+	r.closeStep(end)
+	r.report.Mode, r.report.Duration, r.report.Findings = r.cfg.Mode, end.Sub(start), len(r.findings)
+	r.report.PartialOutput = outputTail(r.partialOutput)
+	if r.usage.Calls > 0 {
+		usage := r.usage
+		r.report.Usage = &usage
+	}
+	if r.cfg.AppConfig != nil {
+		r.report.Prompts, r.report.PromptVersions = r.cfg.AppConfig.PromptsHash(), r.cfg.AppConfig.PromptVersions()
+	}
+	if r.git != nil && !r.cfg.DryRun {
+		files, err := r.changedFiles()
+		if err != nil {
+			r.log.Print("[WARN] run report: %v", err)
+		}
+		r.report.Files = files
+	}
+	return r.report
+}
@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// RetryPolicy configures retries of failed executor calls with exponential backoff.
+type RetryPolicy struct {
+	Count    int           // retries after the first attempt, 0 disables retries
+	Delay    time.Duration // delay before the first retry, doubled with each further attempt
+	MaxDelay time.Duration // upper limit of the delay, 0 = no limit
+	Jitter   float64       // random share of the delay added or removed, 0-1
+}
+
+// backoff returns the delay before the given retry, starting at 1, with jitter applied
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.Delay
+	for range retry - 1 {
+		if p.MaxDelay > 0 && d >= p.MaxDelay {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxDelay > 0 {
+		d = min(d, p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d += time.Duration(float64(d) * p.Jitter * (2*rand.Float64() - 1)) //nolint:gosec // jitter doesn't need crypto rand
+	}
+	return d
+}
+
+// retryExecutor runs the wrapped executor again after a failed call, waiting longer before each attempt.
+// cancellation and configured error patterns are final, retrying them can't help.
+type retryExecutor struct {
+	name   string
+	exec   Executor
+	policy RetryPolicy
+	log    Logger
+}
+
+// Run runs the wrapped executor, retrying failed calls up to the policy count.
+func (e *retryExecutor) Run(ctx context.Context, prompt string) executor.Result {
+	res := e.exec.Run(ctx, prompt)
+	for retry := 1; retry <= e.policy.Count && retryable(ctx, res.Error); retry++ {
+		delay := e.policy.backoff(retry)
+		e.log.Print("[WARN] %s call failed: %v, retrying in %s (%d of %d)", e.name, res.Error,
+			delay.Round(time.Second), retry, e.policy.Count)
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return executor.Result{Output: res.Output, Usage: res.Usage, Error: fmt.Errorf("retry interrupted: %w", ctx.Err())}
+		case <-t.C:
+		}
+		res = e.exec.Run(ctx, prompt)
+	}
+	return res
+}
+
+// retryable reports whether a call that failed with err is worth another attempt
+func retryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var patternErr *executor.PatternMatchError
+	return !errors.As(err, &patternErr)
+}
+
+// retrying wraps exec to retry failed calls per Config.Retry, exec itself when retries are disabled
+func (r *Runner) retrying(name string, exec Executor) Executor {
+	if r.cfg.Retry.Count <= 0 {
+		return exec
+	}
+	return &retryExecutor{name: name, exec: exec, policy: r.cfg.Retry, log: r.log}
+}
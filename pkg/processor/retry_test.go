@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+)
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{Count: 5, Delay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, p.backoff(1))
+	assert.Equal(t, 2*time.Second, p.backoff(2))
+	assert.Equal(t, 4*time.Second, p.backoff(3))
+	assert.Equal(t, 5*time.Second, p.backoff(4), "capped at max delay")
+	assert.Equal(t, 5*time.Second, p.backoff(50))
+
+	p = RetryPolicy{Count: 1, Delay: time.Second, Jitter: 0.5}
+	for range 20 {
+		d := p.backoff(1)
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.LessOrEqual(t, d, 1500*time.Millisecond)
+	}
+}
+
+func TestRetryExecutor_Run(t *testing.T) {
+	sequence := func(results ...executor.Result) *mocks.ExecutorMock {
+		idx := 0
+		return &mocks.ExecutorMock{RunFunc: func(_ context.Context, _ string) executor.Result {
+			res := results[min(idx, len(results)-1)]
+			idx++
+			return res
+		}}
+	}
+	policy := RetryPolicy{Count: 2, Delay: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		results   []executor.Result
+		wantCalls int
+		wantErr   string
+	}{
+		{name: "success", results: []executor.Result{{Output: "ok"}}, wantCalls: 1},
+		{name: "transient failure", results: []executor.Result{{Error: errors.New("connection reset")}, {Output: "ok"}},
+			wantCalls: 2},
+		{name: "retries exhausted", results: []executor.Result{{Error: errors.New("connection reset")}}, wantCalls: 3,
+			wantErr: "connection reset"},
+		{name: "error pattern not retried",
+			results:   []executor.Result{{Error: &executor.PatternMatchError{Pattern: "rate limit"}}},
+			wantCalls: 1, wantErr: "rate limit"},
+		{name: "cancellation not retried", results: []executor.Result{{Error: fmt.Errorf("run: %w", context.Canceled)}},
+			wantCalls: 1, wantErr: "context canceled"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			exec := sequence(tc.results...)
+			log := &mocks.LoggerMock{PrintFunc: func(string, ...any) {}}
+			e := &retryExecutor{name: "claude", exec: exec, policy: policy, log: log}
+			res := e.Run(context.Background(), "prompt")
+			assert.Len(t, exec.RunCalls(), tc.wantCalls)
+			assert.Len(t, log.PrintCalls(), tc.wantCalls-1, "each retry is logged")
+			if tc.wantErr == "" {
+				require.NoError(t, res.Error)
+				assert.Equal(t, "ok", res.Output)
+				return
+			}
+			require.ErrorContains(t, res.Error, tc.wantErr)
+		})
+	}
+
+	t.Run("canceled while waiting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		exec := sequence(executor.Result{Error: errors.New("connection reset")})
+		log := &mocks.LoggerMock{PrintFunc: func(string, ...any) { cancel() }}
+		e := &retryExecutor{name: "codex", exec: exec, policy: RetryPolicy{Count: 3, Delay: time.Hour}, log: log}
+		res := e.Run(ctx, "prompt")
+		require.ErrorIs(t, res.Error, context.Canceled)
+		assert.Len(t, exec.RunCalls(), 1)
+	})
+}
@@ -0,0 +1,37 @@
+package processor
+
+import (
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+// saveRollbackPoint saves the worktree state to return to if the task phase fails, when mode is the
+// configured rollback, see config.Config.RollbackOnFailure. without a git checker nothing is saved.
+func (r *Runner) saveRollbackPoint(mode string) {
+	if r.git == nil || r.cfg.AppConfig == nil || r.cfg.AppConfig.RollbackOnFailure != mode {
+		return
+	}
+	snap, err := r.git.Snapshot()
+	if err != nil {
+		r.log.Print("[WARN] rollback: %v", err)
+		r.rollbackPoint = nil
+		return
+	}
+	r.rollbackPoint = &snap
+}
+
+// rollback resets the worktree to the saved rollback point after the task phase failed.
+// a failed reset is logged, the run fails with the task error either way.
+func (r *Runner) rollback() {
+	if r.rollbackPoint == nil {
+		return
+	}
+	what := "the run"
+	if r.cfg.AppConfig.RollbackOnFailure == config.RollbackIteration {
+		what = "the failed iteration"
+	}
+	if err := r.git.RestoreSnapshot(*r.rollbackPoint); err != nil {
+		r.log.Print("[WARN] rollback failed, worktree left as the failed task left it: %v", err)
+		return
+	}
+	r.log.Print("rolled back the worktree to the state before %s (%s)", what, shortHash(r.rollbackPoint.Head))
+}
@@ -9,12 +9,19 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/umputun/ralphex/pkg/analyzer"
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/debuglog"
 	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/git"
 	"github.com/umputun/ralphex/pkg/status"
+	"github.com/umputun/ralphex/pkg/verify"
 )
 
 // DefaultIterationDelay is the pause between iterations to allow system to settle.
@@ -30,6 +37,9 @@ const (
 	maxCodexSummaryLen     = 5000 // max chars for codex output summary
 )
 
+// servicesStopTimeout bounds tearing down verification services after the task phase.
+const servicesStopTimeout = 2 * time.Minute
+
 const planModeCodexReasoningEffort = "xhigh"
 const defaultCodexReasoningEffort = "high"
 
@@ -43,17 +53,58 @@ const (
 	ModeReview    Mode = "review"     // skip tasks, run full review pipeline
 	ModeCodexOnly Mode = "codex-only" // skip tasks and first review, run only codex loop
 	ModeTasksOnly Mode = "tasks-only" // run only task phase, skip all reviews
-	ModePlan      Mode = "plan"       // interactive plan creation mode
+	ModePlan      Mode = "plan"       // plan creation mode, interactive or drafted from a spec file
 )
 
 // Config holds runner configuration.
 type Config struct {
 	PlanFile         string         // path to plan file (required for full mode)
 	PlanDescription  string         // plan description for interactive plan creation mode
+	PlanSpec         string         // spec file PlanDescription was read from, drafts the plan without questions or draft review
 	ProgressPath     string         // path to progress file
 	Mode             Mode           // execution mode
 	MaxIterations    int            // maximum iterations for task phase
-	Debug            bool           // enable debug output
+	Debug            debuglog.Flags // per-subsystem debug output
+	DryRun           bool           // log prompts the pipeline would send without calling executors
+	RepeatUntilClean int            // extra codex + post-codex review rounds while codex keeps finding issues
+	TaskTimeout      time.Duration  // limit for the whole task phase, 0 = no limit
+	ReviewTimeout    time.Duration  // limit for each claude review phase (before and after codex), 0 = no limit
+	CodexTimeout     time.Duration  // limit for each codex loop, 0 = no limit
+	MaxRunDuration   time.Duration  // wall-clock budget of the whole run, 0 = no limit
+
+	// RunWindow is the time of day the run is allowed in: a run started outside it waits for it to open,
+	// and once it closes the run stops before the next iteration with a StopError. the zero value allows any time
+	RunWindow config.RunWindow
+
+	Phases   []config.PhaseSpec // custom pipeline run in place of the full mode, empty for task → review → codex → review
+	Baseline []Finding          // accepted findings the external review is asked not to report
+	Retry    RetryPolicy        // retry of failed claude, codex and custom review calls, zero value disables it
+
+	ResolveFindings  bool // fail the external review when a finding is neither fixed nor explained by the evaluation
+	VerifyReviewDone bool // reject review done signals contradicted by the diff or the verification gate
+
+	// plugin implementations by plugin name: executors usable as external_review_tool = plugin:<name>,
+	// findings providers run next to the configured analyzers
+	PluginExecutors map[string]Executor
+	PluginAnalyzers map[string]analyzer.FindingsProvider
+
+	// executor overrides by phase, e.g. PhaseEscalation. nil executors of a phase use the default ones
+	PhaseExecutors map[string]Executors
+
+	// Pause holds the run between iterations on request, after the checkpoint is saved. nil never pauses.
+	Pause *Pause
+	// NeedsHuman is called with the reason when the run pauses for a problem only a human can fix, e.g. a full disk
+	NeedsHuman func(reason string)
+	// AnswerInput returns a person's answer to the question of a NEEDS_INPUT signal of a task iteration,
+	// passed to the next iteration, or to what to do about a plan file edited during the task phase.
+	// nil fails the run on the signal and continues with an edited plan
+	AnswerInput func(ctx context.Context, question string) (string, error)
+
+	TranscriptDir    string         // directory for prompt transcripts, TranscriptDir if empty
+	CheckpointPath   string         // file to save run state to after each iteration, empty disables checkpoints
+	StopFile         string         // file stopping the run before the next iteration when it appears, empty disables it
+	InboxFile        string         // file of user notes for the next agent iteration, see AddNote, empty disables it
+	Resume           *Checkpoint    // checkpoint of an interrupted run to continue from
 	NoColor          bool           // disable color output
 	IterationDelayMs int            // delay between iterations in milliseconds
 	TaskRetryCount   int            // number of times to retry failed tasks
@@ -61,24 +112,84 @@ type Config struct {
 	FinalizeEnabled  bool           // whether finalize step is enabled
 	DefaultBranch    string         // default branch name (detected from repo)
 	AppConfig        *config.Config // full application config (for executors and prompts)
+
+	// phases of the fixed pipelines left out, e.g. tasks followed by the external review only with SkipFirstReview
+	// and SkipSecondReview. custom pipelines (Phases) list the phases they run instead
+	SkipFirstReview  bool // the claude review before the external review
+	SkipCodex        bool // the external review loop
+	SkipSecondReview bool // the claude review loop after the external review
+
+	// task selection, a task number or a regular expression on the task text, see plan.SelectTasks.
+	// the task phase works on the tasks from StartTask on, narrowed to those matching one of OnlyTasks
+	StartTask string
+	OnlyTasks []string
 }
 
 //go:generate moq -out mocks/executor.go -pkg mocks -skip-ensure -fmt goimports . Executor
 //go:generate moq -out mocks/logger.go -pkg mocks -skip-ensure -fmt goimports . Logger
 //go:generate moq -out mocks/input_collector.go -pkg mocks -skip-ensure -fmt goimports . InputCollector
 //go:generate moq -out mocks/git_checker.go -pkg mocks -skip-ensure -fmt goimports . GitChecker
+//go:generate moq -out mocks/verifier.go -pkg mocks -skip-ensure -fmt goimports . Verifier
+//go:generate moq -out mocks/services.go -pkg mocks -skip-ensure -fmt goimports . Services
+
+// PhaseTimeoutError reports a phase that ran past its configured limit.
+// it wraps context.DeadlineExceeded, so errors.Is(err, context.DeadlineExceeded) holds.
+type PhaseTimeoutError struct {
+	Phase   status.Phase
+	Timeout time.Duration
+}
+
+// Error returns the phase and its limit.
+func (e *PhaseTimeoutError) Error() string {
+	return fmt.Sprintf("%s phase timed out after %s", e.Phase, e.Timeout)
+}
+
+// Unwrap returns context.DeadlineExceeded.
+func (e *PhaseTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// RunBudgetError reports a run stopped because it ran past Config.MaxRunDuration,
+// with the phase, step and iteration it was in. it wraps context.DeadlineExceeded.
+type RunBudgetError struct {
+	Budget    time.Duration
+	Phase     status.Phase
+	Step      Step
+	Iteration int // 1-based iteration of Step in progress
+}
+
+// Error returns the budget and where the run stopped.
+func (e *RunBudgetError) Error() string {
+	where := fmt.Sprintf("%s phase", e.Phase)
+	if e.Step != "" {
+		where += fmt.Sprintf(", %s step iteration %d", e.Step, e.Iteration)
+	}
+	return fmt.Sprintf("run time budget of %s exceeded in %s", e.Budget, where)
+}
+
+// Unwrap returns context.DeadlineExceeded.
+func (e *RunBudgetError) Unwrap() error {
+	return context.DeadlineExceeded
+}
 
 // Executor runs CLI commands and returns results.
 type Executor interface {
 	Run(ctx context.Context, prompt string) executor.Result
 }
 
+// Executors are the claude and codex executors of a phase, see Config.PhaseExecutors.
+type Executors struct {
+	Claude Executor
+	Codex  Executor
+}
+
 // Logger provides logging functionality.
 type Logger interface {
 	Print(format string, args ...any)
 	PrintRaw(format string, args ...any)
 	PrintSection(section status.Section)
 	PrintAligned(text string)
+	PrintDiff(diff string)
 	LogQuestion(question string, options []string)
 	LogAnswer(answer string)
 	LogDraftReview(action string, feedback string)
@@ -94,45 +205,118 @@ type InputCollector interface {
 // GitChecker provides git state inspection for the review loop.
 type GitChecker interface {
 	HeadHash() (string, error)
+	SpecialChanges(baseBranch string) (git.SpecialChanges, error)
+	PrepareDiff(baseBranch string, fetch bool) (git.DiffReadiness, error)
+	ChangedFiles(baseBranch string) ([]string, error)
+	DiffSince(rev string) (string, error)
+	Snapshot() (git.Snapshot, error)
+	RestoreSnapshot(snap git.Snapshot) error
+	CommitFiles(files []string, msg string) error
+}
+
+// Verifier runs the verification gate (build, test, lint commands) after task iterations.
+type Verifier interface {
+	Verify(ctx context.Context) verify.Report
+}
+
+// Services manages ephemeral services (databases, caches) used by integration tests during the task phase.
+type Services interface {
+	Up(ctx context.Context) ([]string, error)
+	Down(ctx context.Context) error
 }
 
 // Runner orchestrates the execution loop.
 type Runner struct {
-	cfg            Config
-	log            Logger
-	claude         Executor
-	codex          Executor
-	custom         *executor.CustomExecutor
-	git            GitChecker
-	inputCollector InputCollector
-	phaseHolder    *status.PhaseHolder
-	iterationDelay time.Duration
-	taskRetryCount int
+	cfg              Config
+	log              Logger
+	claude           Executor
+	codex            Executor
+	phaseExecutors   map[string]Executors // executor overrides by phase, decorated like claude and codex
+	custom           *executor.CustomExecutor
+	git              GitChecker
+	verifier         Verifier
+	services         Services
+	analyzers        map[string]analyzer.FindingsProvider // third-party analyzers by name, run next to the external review
+	inputCollector   InputCollector
+	phaseHolder      *status.PhaseHolder
+	iterationDelay   time.Duration
+	taskRetryCount   int
+	taskIterations   int                           // task iterations started by the last run
+	planText         string                        // plan file content as the agent left it, see checkPlanEdited
+	planNote         string                        // plan edit for the next task prompt, see reloadPlan
+	agentIndex       map[string]config.CustomAgent // agents by name, built on first use
+	resume           *Checkpoint                   // checkpoint to continue from, consumed when its step is reached
+	resumedOutput    string                        // saved output for the first resumed prompt, see takeResumedOutput
+	lastOutput       string                        // output of the last agent call, saved in checkpoints
+	usage            UsageReport                   // time and token use of the agent calls of the run
+	transcript       *transcript                   // prompts and responses of the run, nil without prompts debugging
+	partialOutput    string                        // output of the last agent call if a cancellation interrupted it
+	saved            Checkpoint                    // last checkpoint saved, see savePartialOutput
+	round            int                           // external review round, see Config.RepeatUntilClean
+	externalClean    bool                          // the last external review loop found nothing in its first iteration
+	checkpointFailed bool                          // a checkpoint save failed, further failures are not logged
+	position         Checkpoint                    // step and iteration in progress, reported when the run budget runs out
+	findings         []Finding                     // distinct external review findings of the run
+	loopFindings     []ratedFinding                // distinct findings of the last external review loop
+	parallelDone     *Checkpoint                   // external review iteration completed by the parallel first review
+	consensusClean   bool                          // the consensus first review found no finding both reviews report
+	dismissed        []Finding                     // distinct findings dismissed as invalid by evaluations
+	stage            int                           // index of the custom pipeline phase in progress, see Config.Phases
+	report           RunReport                     // summary of the run, completed when Run returns
+	disk             *diskGuard                    // disk usage checks before iterations, nil if disabled
+	modules          []string                      // modules required when the run started, for the go.mod gate
+	artifactBaseline []string                      // artifacts on the branch when the run started, see runArtifactGate
+	milestone        int                           // 1-based milestone run with its reviews, 0 for the whole plan
+	milestoneBase    string                        // commit the milestone started at, empty for the first one
+	reviewBase       string                        // commit the reviews of a milestone diff against, default branch if empty
+	rollbackPoint    *git.Snapshot                 // worktree state to reset to when the task phase fails, see rollback
+	eventHandler     func(Event)                   // receives run events, see SetEventHandler
+	eventMu          sync.Mutex                    // delivers events one at a time
+	recentEvs        []Event                       // last events of the run, for a crash report
+	stepStart        time.Time                     // start of the last step in the report
+	stop             atomic.Bool                   // a stop of the run is requested, see RequestStop
+	notesMu          sync.Mutex                    // guards notes
+	notes            []string                      // user notes for the next agent iteration, see AddNote
+	now              func() time.Time              // clock of the run window checks
+	skipTasks        bool                          // all plan tasks were checked before the run, see checkCompletedPlan
+	cancelRun        context.CancelCauseFunc       // cancels the run context, set while the run is in progress
 }
 
 // New creates a new Runner with the given configuration and shared phase holder.
 // If codex is enabled but the binary is not found in PATH, it is automatically disabled with a warning.
 func New(cfg Config, log Logger, holder *status.PhaseHolder) *Runner {
+	if cfg.AppConfig != nil && (cfg.AppConfig.ParallelFirstReview || cfg.AppConfig.ConsensusReview ||
+		cfg.AppConfig.CodexSplitConcurrency > 1) {
+		log = &syncLogger{Logger: log} // executors stream output at the same time
+	}
+	var r *Runner // set below, executors stream output only once the runner runs
+	outputHandler := func(name string) func(string) {
+		return func(text string) {
+			log.PrintAligned(text)
+			r.emit(Event{Type: EventExecutorOutput, Executor: name, Text: text})
+		}
+	}
+
 	// build claude executor with config values
 	claudeExec := &executor.ClaudeExecutor{
-		OutputHandler: func(text string) {
-			log.PrintAligned(text)
-		},
-		Debug: cfg.Debug,
+		OutputHandler: outputHandler("claude"),
+		Debug:         cfg.Debug,
+		MaxEventBytes: executor.DefaultMaxEventBytes,
+		SpillOutput:   cfg.Debug.Prompts, // truncated output goes to the transcript in full
 	}
 	if cfg.AppConfig != nil {
 		claudeExec.Command = cfg.AppConfig.ClaudeCommand
 		claudeExec.Args = cfg.AppConfig.ClaudeArgs
 		claudeExec.Args = adjustCodexPrimaryArgsForMode(cfg.Mode, claudeExec.Command, claudeExec.Args)
 		claudeExec.ErrorPatterns = cfg.AppConfig.ClaudeErrorPatterns
+		claudeExec.MaxOutputBytes = cfg.AppConfig.MaxOutputBytes
 	}
 
 	// build codex executor with config values
 	codexExec := &executor.CodexExecutor{
-		OutputHandler: func(text string) {
-			log.PrintAligned(text)
-		},
-		Debug: cfg.Debug,
+		OutputHandler: outputHandler("codex"),
+		Debug:         cfg.Debug,
+		SpillOutput:   cfg.Debug.Prompts,
 	}
 	if cfg.AppConfig != nil {
 		codexExec.Command = cfg.AppConfig.CodexCommand
@@ -141,17 +325,18 @@ func New(cfg Config, log Logger, holder *status.PhaseHolder) *Runner {
 		codexExec.TimeoutMs = cfg.AppConfig.CodexTimeoutMs
 		codexExec.Sandbox = cfg.AppConfig.CodexSandbox
 		codexExec.ErrorPatterns = cfg.AppConfig.CodexErrorPatterns
+		codexExec.MaxOutputBytes = cfg.AppConfig.MaxOutputBytes
 	}
 
 	// build custom executor if custom review script is configured
 	var customExec *executor.CustomExecutor
 	if cfg.AppConfig != nil && cfg.AppConfig.CustomReviewScript != "" {
 		customExec = &executor.CustomExecutor{
-			Script: cfg.AppConfig.CustomReviewScript,
-			OutputHandler: func(text string) {
-				log.PrintAligned(text)
-			},
-			ErrorPatterns: cfg.AppConfig.CodexErrorPatterns, // reuse codex error patterns
+			Script:         cfg.AppConfig.CustomReviewScript,
+			OutputHandler:  outputHandler("custom"),
+			ErrorPatterns:  cfg.AppConfig.CodexErrorPatterns, // reuse codex error patterns
+			MaxOutputBytes: cfg.AppConfig.MaxOutputBytes,
+			SpillOutput:    cfg.Debug.Prompts,
 		}
 	}
 
@@ -168,7 +353,68 @@ func New(cfg Config, log Logger, holder *status.PhaseHolder) *Runner {
 		}
 	}
 
-	return NewWithExecutors(cfg, log, claudeExec, codexExec, customExec, holder)
+	if cfg.AppConfig != nil && cfg.AppConfig.EscalationReview {
+		if execs := escalationExecutors(cfg.AppConfig, claudeExec, codexExec); execs != (Executors{}) {
+			cfg.PhaseExecutors = map[string]Executors{PhaseEscalation: execs}
+		}
+	}
+
+	r = NewWithExecutors(cfg, log, claudeExec, codexExec, customExec, holder)
+	if cfg.Mode == ModeFull || cfg.Mode == ModeTasksOnly {
+		if v, svc := newVerifier(cfg.AppConfig, log, r.changedFiles); v != nil {
+			r.verifier = v
+			if svc != nil {
+				r.services = svc
+			}
+		}
+	}
+	return r
+}
+
+// newVerifier builds the verification gate from config, nil if disabled or no commands apply.
+// the profile is detected in the current directory, which is the repository root.
+// changedFiles feeds scoped profiles (bazel) testing only targets affected by the branch changes.
+// returns compose services the verifier depends on, if configured.
+func newVerifier(appCfg *config.Config, log Logger, changedFiles func() ([]string, error)) (Verifier, *verify.Services) {
+	if appCfg == nil || !appCfg.VerifyEnabled {
+		return nil, nil
+	}
+	name, commands, err := verify.Resolve(".", appCfg.VerifyProfile, appCfg.VerifyTargets, appCfg.VerifyCommands)
+	if err != nil {
+		log.Print("warning: %v, verification disabled", err)
+		return nil, nil
+	}
+	runner := verify.Runner{
+		Timeout:          time.Duration(appCfg.VerifyTimeoutMs) * time.Millisecond,
+		Image:            appCfg.VerifyImage,
+		ContainerCommand: appCfg.VerifyContainerCommand,
+		Shell:            verify.SelectShell(appCfg.VerifyShell, runtime.GOOS),
+	}
+	if appCfg.VerifyServices != "" {
+		runner.Services = verify.NewServices(appCfg.VerifyServices, ".", appCfg.VerifyContainerCommand)
+	}
+	where := ""
+	if runner.Image != "" {
+		where = " in " + runner.Image
+	}
+	if p, ok := verify.Lookup(name); ok && p.Scoped {
+		if len(appCfg.VerifyGoVersions) > 0 {
+			log.Print("warning: verify_go_versions is not supported by the %s profile, ignored", name)
+		}
+		log.Print("verification gate (%s%s): tests affected by changed files", name, where)
+		return &verify.Bazel{Runner: runner, ChangedFiles: changedFiles}, runner.Services
+	}
+	if len(commands) == 0 {
+		log.Print("warning: verification enabled but project type not detected, set verify_commands; verification disabled")
+		return nil, nil
+	}
+	runner.Commands = commands
+	if versions := appCfg.VerifyGoVersions; len(versions) > 0 {
+		log.Print("verification gate (%s%s, go %s): %s", name, where, strings.Join(versions, ", "), strings.Join(commands, ", "))
+		return &verify.Matrix{Runner: runner, Versions: versions}, runner.Services
+	}
+	log.Print("verification gate (%s%s): %s", name, where, strings.Join(commands, ", "))
+	return &runner, runner.Services
 }
 
 // NewWithExecutors creates a new Runner with custom executors (for testing).
@@ -188,16 +434,63 @@ func NewWithExecutors(cfg Config, log Logger, claude, codex Executor, custom *ex
 		retryCount = cfg.TaskRetryCount
 	}
 
-	return &Runner{
+	// prompts and resulting signals are logged where agents are called, covering every phase
+	var tr *transcript
+	if cfg.Debug.Prompts {
+		sl := &sectionLogger{Logger: log}
+		log = sl
+		dir := cfg.TranscriptDir
+		if dir == "" {
+			dir = TranscriptDir
+		}
+		tr = newTranscript(dir, log.Path(), time.Now(), newTranscriptRedactor(cfg.AppConfig), artifactSealer(cfg.AppConfig),
+			sl.current, holder)
+	}
+
+	r := &Runner{
 		cfg:            cfg,
 		log:            log,
-		claude:         claude,
-		codex:          codex,
 		custom:         custom,
 		phaseHolder:    holder,
 		iterationDelay: iterDelay,
 		taskRetryCount: retryCount,
+		resume:         cfg.Resume,
+		analyzers:      newAnalyzers(cfg.AppConfig),
+		transcript:     tr,
+		now:            time.Now,
+	}
+	for name, p := range cfg.PluginAnalyzers {
+		if r.analyzers == nil {
+			r.analyzers = map[string]analyzer.FindingsProvider{}
+		}
+		r.analyzers["plugin "+name] = p
+	}
+	if cfg.Resume != nil {
+		r.taskIterations = cfg.Resume.TaskIterations
+	}
+	mu := &sync.Mutex{}
+	decorate := func(name string, exec Executor) Executor {
+		if exec == nil {
+			return nil
+		}
+		if cfg.Debug.Prompts || cfg.Debug.Signals {
+			exec = &debugExecutor{name: name, exec: exec, debug: cfg.Debug, transcript: tr, log: log}
+		}
+		if cfg.Retry.Count > 0 {
+			exec = &retryExecutor{name: name, exec: exec, policy: cfg.Retry, log: log}
+		}
+		exec = &usageExecutor{name: name, exec: exec, total: &r.usage, mu: mu, log: log}
+		return &outputRecorder{name: name, exec: exec, last: &r.lastOutput, partial: &r.partialOutput, signals: &r.report.Signals,
+			mu: mu, emit: r.emit}
+	}
+	r.claude, r.codex = decorate("claude", claude), decorate("codex", codex)
+	for phase, execs := range cfg.PhaseExecutors {
+		if r.phaseExecutors == nil {
+			r.phaseExecutors = map[string]Executors{}
+		}
+		r.phaseExecutors[phase] = Executors{Claude: decorate("claude", execs.Claude), Codex: decorate("codex", execs.Codex)}
 	}
+	return r
 }
 
 // SetInputCollector sets the input collector for plan creation mode.
@@ -205,15 +498,106 @@ func (r *Runner) SetInputCollector(c InputCollector) {
 	r.inputCollector = c
 }
 
+// SetVerifier sets the verification gate run after task iterations.
+func (r *Runner) SetVerifier(v Verifier) {
+	r.verifier = v
+}
+
+// SetServices sets the services started for the task phase.
+func (r *Runner) SetServices(s Services) {
+	r.services = s
+}
+
+// SetFindingsProviders sets third-party analyzers run next to the external review, by name.
+func (r *Runner) SetFindingsProviders(providers map[string]analyzer.FindingsProvider) {
+	r.analyzers = providers
+}
+
 // SetGitChecker sets the git checker for no-commit detection in review loops.
 func (r *Runner) SetGitChecker(g GitChecker) {
 	r.git = g
 }
 
-// Run executes the main loop based on configured mode.
-func (r *Runner) Run(ctx context.Context) error {
+// TaskIterations returns the number of task iterations started by the run.
+func (r *Runner) TaskIterations() int {
+	return r.taskIterations
+}
+
+// TranscriptPath returns the directory the prompt transcripts of the run are written to, empty without
+// prompts debugging. the directory is created with the first transcript.
+func (r *Runner) TranscriptPath() string {
+	if r.transcript == nil {
+		return ""
+	}
+	return r.transcript.dir
+}
+
+// Run executes the main loop based on configured mode and returns the report of the run,
+// filled as far as the run got when it fails.
+func (r *Runner) Run(ctx context.Context) (RunReport, error) {
+	start := time.Now()
+	err := r.runRecovered(ctx)
+	if err != nil {
+		r.savePartialOutput()
+	}
+	report := r.finishReport(start)
+	r.emit(Event{Type: EventRunFinished, Report: &report, Err: err})
+	return report, err
+}
+
+// run executes the main loop based on configured mode.
+func (r *Runner) run(ctx context.Context) error {
+	r.cfg.Debug.Printf(debuglog.Processor, "run mode %s, max iterations %d, codex enabled %v",
+		r.cfg.Mode, r.cfg.MaxIterations, r.cfg.CodexEnabled)
+	if r.cfg.DryRun {
+		return r.runDryRun()
+	}
+	if err := r.checkCompletedPlan(); err != nil {
+		return err
+	}
+	r.disk = r.newDiskGuard()
+	r.warnResumedPrompts()
+	r.recordModBaseline()
+	r.recordArtifactBaseline()
+	r.saveRollbackPoint(config.RollbackRun)
+	// the wait for the run window is not part of the run budget
+	if err := r.waitForWindow(ctx); err != nil {
+		return err
+	}
+	runCtx := ctx
+	if r.cfg.MaxRunDuration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, r.cfg.MaxRunDuration)
+		defer cancel()
+	}
+	runCtx, r.cancelRun = context.WithCancelCause(runCtx)
+	defer r.cancelRun(nil)
+	if err := r.runMode(runCtx); err != nil {
+		if cause := context.Cause(runCtx); errors.Is(cause, errStopRequested) || errors.Is(cause, errWindowClosed) {
+			stopErr := &StopError{Phase: r.phaseHolder.Get(), Step: r.position.Step, Iteration: r.position.Iteration + 1}
+			if errors.Is(cause, errWindowClosed) {
+				stopErr.Window = r.cfg.RunWindow.String()
+			}
+			return stopErr
+		}
+		if r.cfg.MaxRunDuration > 0 && ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			r.cfg.Debug.Printf(debuglog.Processor, "run budget exceeded: %v", err)
+			return &RunBudgetError{Budget: r.cfg.MaxRunDuration, Phase: r.phaseHolder.Get(),
+				Step: r.position.Step, Iteration: r.position.Iteration + 1}
+		}
+		return err
+	}
+	r.clearCheckpoint()
+	return r.checkLicenses(ctx)
+}
+
+// runMode runs the pipeline of the configured mode.
+func (r *Runner) runMode(ctx context.Context) error {
 	switch r.cfg.Mode {
 	case ModeFull:
+		if len(r.cfg.Phases) > 0 {
+			return r.runPipeline(ctx)
+		}
 		return r.runFull(ctx)
 	case ModeReview:
 		return r.runReviewOnly(ctx)
@@ -233,26 +617,24 @@ func (r *Runner) runFull(ctx context.Context) error {
 	if r.cfg.PlanFile == "" {
 		return errors.New("plan file required for full mode")
 	}
-
-	// phase 1: task execution
-	r.phaseHolder.Set(status.PhaseTask)
-	r.log.PrintRaw("starting task execution phase\n")
-
-	if err := r.runTaskPhase(ctx); err != nil {
-		return fmt.Errorf("task phase: %w", err)
+	if r.milestoneReviews() {
+		return r.runMilestones(ctx)
 	}
 
-	// phase 2: first review pass - address ALL findings
-	r.phaseHolder.Set(status.PhaseReview)
-	r.log.PrintSection(status.NewGenericSection("claude review 0: all findings"))
+	// phase 1: task execution
+	if !r.skipStep(StepTask) {
+		r.phaseHolder.Set(status.PhaseTask)
+		r.log.PrintRaw("starting task execution phase\n")
 
-	if err := r.runClaudeReview(ctx, r.replacePromptVariables(r.cfg.AppConfig.ReviewFirstPrompt)); err != nil {
-		return fmt.Errorf("first review: %w", err)
+		if err := r.runPhase(ctx, status.PhaseTask, r.cfg.TaskTimeout, r.runTaskPhase); err != nil {
+			return fmt.Errorf("task phase: %w", err)
+		}
 	}
+	r.prepareReviewDiff()
 
-	// phase 2.1: claude review loop (critical/major) before codex
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("pre-codex review loop: %w", err)
+	// phase 2: first review pass - address ALL findings, then claude review loop (critical/major) before codex
+	if err := r.runFirstReviewPhase(ctx); err != nil {
+		return err
 	}
 
 	// phase 2.5+3: codex → post-codex review → finalize
@@ -266,17 +648,11 @@ func (r *Runner) runFull(ctx context.Context) error {
 
 // runReviewOnly executes only the review pipeline: review → codex → review.
 func (r *Runner) runReviewOnly(ctx context.Context) error {
-	// phase 1: first review
-	r.phaseHolder.Set(status.PhaseReview)
-	r.log.PrintSection(status.NewGenericSection("claude review 0: all findings"))
+	r.prepareReviewDiff()
 
-	if err := r.runClaudeReview(ctx, r.replacePromptVariables(r.cfg.AppConfig.ReviewFirstPrompt)); err != nil {
-		return fmt.Errorf("first review: %w", err)
-	}
-
-	// phase 1.1: claude review loop (critical/major) before codex
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("pre-codex review loop: %w", err)
+	// phase 1: first review, then claude review loop (critical/major) before codex
+	if err := r.runFirstReviewPhase(ctx); err != nil {
+		return err
 	}
 
 	// phase 2+3: codex → post-codex review → finalize
@@ -288,8 +664,45 @@ func (r *Runner) runReviewOnly(ctx context.Context) error {
 	return nil
 }
 
+// runFirstReviewPhase runs the review phase before the external review, unless SkipFirstReview leaves it out
+func (r *Runner) runFirstReviewPhase(ctx context.Context) error {
+	if r.cfg.SkipFirstReview {
+		r.log.Print("skipping first review phase")
+		return nil
+	}
+	reviewMark := r.diffMark(config.ShowDiffPhase)
+	if err := r.runPhase(ctx, status.PhaseReview, r.cfg.ReviewTimeout, r.firstReview()); err != nil {
+		return err
+	}
+	r.showDiff(reviewMark, "claude review phase")
+	return nil
+}
+
+// runPreExternalReview runs the first review pass addressing all findings,
+// followed by the claude review loop (critical/major). steps completed by a resumed run are skipped.
+func (r *Runner) runPreExternalReview(ctx context.Context) error {
+	r.phaseHolder.Set(status.PhaseReview)
+	if !r.skipStep(StepFirstReview) {
+		r.resumeStep(StepFirstReview)
+		r.saveCheckpoint(Checkpoint{Step: StepFirstReview})
+		r.log.PrintSection(status.NewGenericSection("claude review 0: all findings"))
+
+		if err := r.runClaudeReview(ctx, r.reviewPrompt(r.cfg.AppConfig.ReviewFirstPrompt)); err != nil {
+			return fmt.Errorf("first review: %w", err)
+		}
+	}
+
+	if !r.skipStep(StepReview) {
+		if err := r.runClaudeReviewLoop(ctx, StepReview); err != nil {
+			return fmt.Errorf("pre-codex review loop: %w", err)
+		}
+	}
+	return nil
+}
+
 // runCodexOnly executes only the codex pipeline: codex → review → finalize.
 func (r *Runner) runCodexOnly(ctx context.Context) error {
+	r.prepareReviewDiff()
 	if err := r.runCodexAndPostReview(ctx); err != nil {
 		return err
 	}
@@ -298,26 +711,89 @@ func (r *Runner) runCodexOnly(ctx context.Context) error {
 	return nil
 }
 
-// runCodexAndPostReview runs the shared codex → post-codex claude review → finalize pipeline.
+// runCodexAndPostReview runs the shared codex → post-codex claude review → escalation → final verification →
+// finalize pipeline.
 // used by runFull, runReviewOnly, and runCodexOnly to avoid duplicating this sequence.
 func (r *Runner) runCodexAndPostReview(ctx context.Context) error {
-	// codex external review loop
-	r.phaseHolder.Set(status.PhaseCodex)
-	r.log.PrintSection(status.NewGenericSection("codex external review"))
-
-	if err := r.runCodexLoop(ctx); err != nil {
-		return fmt.Errorf("codex loop: %w", err)
+	if err := r.runExternalRounds(ctx); err != nil {
+		return err
+	}
+	if err := r.runEscalation(ctx); err != nil {
+		return err
+	}
+	if err := r.runFinalVerification(ctx); err != nil {
+		return err
 	}
+	// optional finalize step (best-effort, but propagates context cancellation)
+	return r.runFinalize(ctx)
+}
 
-	// claude review loop (critical/major) after codex
-	r.phaseHolder.Set(status.PhaseReview)
+// runExternalRounds runs codex → post-codex claude review. with RepeatUntilClean set, codex and
+// the post-codex review run again while codex keeps finding issues.
+func (r *Runner) runExternalRounds(ctx context.Context) error {
+	externalMark := r.diffMark(config.ShowDiffPhase)
+	r.round = 1
+	if r.resume != nil && r.resume.Round > 0 {
+		r.round = r.resume.Round
+	}
+
+	// a run resumed at finalize has completed all rounds
+	for r.resume == nil || r.resume.Step != StepFinalize {
+		// codex external review loop
+		externalRan := false // the post-codex review skip is decided on findings of a loop run by this process
+		if r.cfg.SkipCodex {
+			r.log.Print("skipping external review phase")
+		} else if !r.skipStep(StepExternal) {
+			externalRan = true
+			r.phaseHolder.Set(status.PhaseCodex)
+			label := "codex external review"
+			if r.round > 1 {
+				label += fmt.Sprintf(" (round %d)", r.round)
+			}
+			r.log.PrintSection(status.NewGenericSection(label))
+
+			if err := r.runPhase(ctx, status.PhaseCodex, r.cfg.CodexTimeout, r.runCodexLoop); err != nil {
+				return fmt.Errorf("codex loop: %w", err)
+			}
+		}
+
+		// claude review loop (critical/major) after codex
+		r.phaseHolder.Set(status.PhaseReview)
 
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("post-codex review loop: %w", err)
+		skip, reason := false, ""
+		if externalRan {
+			skip, reason = postReviewSkip(r.cfg.AppConfig, r.loopFindings)
+		}
+		switch {
+		case skip:
+			r.logPostReviewSkip(reason)
+		case r.cfg.SkipSecondReview:
+			r.log.Print("skipping post-codex review phase")
+		case !r.skipStep(StepPostReview):
+			postReview := func(ctx context.Context) error { return r.runClaudeReviewLoop(ctx, StepPostReview) }
+			if err := r.runPhase(ctx, status.PhaseReview, r.cfg.ReviewTimeout, postReview); err != nil {
+				return fmt.Errorf("post-codex review loop: %w", err)
+			}
+		}
+
+		// without the external review there are no findings to repeat the round on
+		if r.cfg.SkipCodex || r.externalClean || r.round > r.cfg.RepeatUntilClean {
+			break
+		}
+		r.round++
+		r.log.Print("external review found issues, repeating external review and review (round %d of %d)...",
+			r.round, r.cfg.RepeatUntilClean+1)
 	}
+	r.showDiff(externalMark, "external review phase")
+	return nil
+}
 
-	// optional finalize step (best-effort, but propagates context cancellation)
-	return r.runFinalize(ctx)
+// logPostReviewSkip records the decision to skip the post-codex review, listing the findings left to it
+func (r *Runner) logPostReviewSkip(reason string) {
+	r.log.Print("skipping post-codex review: %s", reason)
+	for _, f := range r.loopFindings {
+		r.log.PrintAligned(fmt.Sprintf("- [%s] %s: %s", config.Severities[f.severity], f.File, f.Message))
+	}
 }
 
 // runTasksOnly executes only task phase, skipping all reviews.
@@ -329,7 +805,7 @@ func (r *Runner) runTasksOnly(ctx context.Context) error {
 	r.phaseHolder.Set(status.PhaseTask)
 	r.log.PrintRaw("starting task execution phase\n")
 
-	if err := r.runTaskPhase(ctx); err != nil {
+	if err := r.runPhase(ctx, status.PhaseTask, r.cfg.TaskTimeout, r.runTaskPhase); err != nil {
 		return fmt.Errorf("task phase: %w", err)
 	}
 
@@ -340,67 +816,410 @@ func (r *Runner) runTasksOnly(ctx context.Context) error {
 // runTaskPhase executes tasks until completion or max iterations.
 // executes ONE Task section per iteration.
 func (r *Runner) runTaskPhase(ctx context.Context) error {
-	prompt := r.replacePromptVariables(r.cfg.AppConfig.TaskPrompt)
-	retryCount := 0
+	if r.skipTasks {
+		r.log.PrintRaw("\nall plan tasks already completed, skipping task execution...\n")
+		return nil
+	}
+	if err := r.startServices(ctx); err != nil {
+		return err
+	}
+	defer r.stopServices()
 
-	for i := 1; i <= r.cfg.MaxIterations; i++ {
+	first, session := 1, ""
+	if cp := r.resumeStep(StepTask); cp != nil {
+		first, session = cp.Iteration+1, cp.SessionID
+	}
+	r.planText = r.planContent()
+	for {
+		err := r.runTaskIterations(ctx, first, session)
+		if !errors.Is(err, errPlanRestart) {
+			return err
+		}
+		// the iterations before the restart count against the iteration limit, a plan edited over and over
+		// must not get the task phase past it
+		r.log.PrintRaw("\nplan file edited, restarting task phase...\n")
+		first, session = r.taskIterations+1, ""
+	}
+}
+
+// taskStep is the outcome of a check run after a task iteration, see checkTaskIteration.
+type taskStep int
+
+const (
+	taskPass taskStep = iota // the check passed, the next check runs
+	taskNext                 // the next task iteration starts
+	taskDone                 // the task phase is done
+)
+
+// taskLoop is the state of the task iterations carried from one iteration to the next.
+type taskLoop struct {
+	prompt     string // task prompt, rebuilt when the edited plan is reloaded
+	session    string // agent session continued by the next iteration
+	retryCount int    // FAILED signals in a row
+	feedback   string // verification failure from the previous iteration
+	answer     string // question of the previous iteration with its answer, see handleNeedsInput
+	phaseMark  string // diff mark of the phase start, see showDiff
+	limit      int    // iteration limit, raised by the budget extender
+	stall      *stallDetector
+	budget     *taskBudget
+	extender   *budgetExtender
+}
+
+// runTaskIterations runs task iterations from first on, continuing the agent session of the previous
+// iteration, until the selected tasks are done. returns errPlanRestart when the plan was edited during
+// the phase and a person chose to start it over.
+func (r *Runner) runTaskIterations(ctx context.Context, first int, session string) error {
+	prompt, done, err := r.taskPrompt()
+	if err != nil {
+		return err
+	}
+	if done {
+		r.log.PrintRaw("\nselected tasks already completed, starting code review...\n")
+		return nil
+	}
+	loop := &taskLoop{prompt: prompt, session: session, phaseMark: r.diffMark(config.ShowDiffPhase),
+		limit: r.cfg.MaxIterations, stall: r.newStallDetector(), budget: r.newTaskBudget(), extender: r.newBudgetExtender()}
+
+	for i := first; r.withinTaskLimit(loop, i); i++ {
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("task phase: %w", ctx.Err())
 		default:
 		}
+		done, err := r.startTaskIteration(ctx, loop, i)
+		if err != nil || done {
+			return err
+		}
+		result, err := r.runTaskIteration(ctx, loop, i)
+		if err != nil {
+			return err
+		}
+		step, err := r.checkTaskIteration(ctx, loop, result)
+		if err != nil || step == taskDone {
+			return err
+		}
+	}
 
-		r.log.PrintSection(status.NewTaskIterationSection(i))
+	return fmt.Errorf("max iterations (%d) reached without completion", loop.limit)
+}
 
-		result := r.claude.Run(ctx, prompt)
-		if result.Error != nil {
-			if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
-				return err
-			}
-			return fmt.Errorf("claude execution: %w", result.Error)
+// withinTaskLimit reports whether task iteration i is within the iteration limit, extending the limit
+// while the plan keeps advancing.
+func (r *Runner) withinTaskLimit(loop *taskLoop, i int) bool {
+	loop.extender.observe(r.checkedItems)
+	if i <= loop.limit {
+		return true
+	}
+	extended := loop.extender.extend(loop.limit)
+	if extended == loop.limit {
+		return false
+	}
+	r.log.Print("max iterations (%d) reached but the plan keeps advancing, extending to %d iterations", loop.limit, extended)
+	loop.limit = extended
+	return true
+}
+
+// startTaskIteration charges the task budget, saves the checkpoint and checks the plan for edits made
+// outside of the agent before task iteration i. returns true if the selected tasks of the edited plan
+// are already completed.
+func (r *Runner) startTaskIteration(ctx context.Context, loop *taskLoop, i int) (bool, error) {
+	if err := loop.budget.charge(r.currentTask()); err != nil {
+		return false, err
+	}
+	r.saveCheckpoint(Checkpoint{Step: StepTask, Iteration: i - 1, SessionID: loop.session})
+	if err := r.beforeIteration(ctx); err != nil {
+		return false, fmt.Errorf("task phase: %w", err)
+	}
+	reload, err := r.checkPlanEdited(ctx)
+	if err != nil {
+		return false, fmt.Errorf("task phase: %w", err)
+	}
+	if !reload {
+		return false, nil
+	}
+	prompt, done, err := r.taskPrompt()
+	if err != nil {
+		return false, err
+	}
+	if done {
+		r.log.PrintRaw("\nselected tasks of the edited plan already completed, starting code review...\n")
+		return true, nil
+	}
+	loop.prompt = prompt
+	return false, nil
+}
+
+// runTaskIteration runs the agent for task iteration i, with the verification feedback, the answer and
+// the notes collected since the previous iteration.
+func (r *Runner) runTaskIteration(ctx context.Context, loop *taskLoop, i int) (executor.Result, error) {
+	r.taskIterations = i
+	r.log.PrintSection(status.NewTaskIterationSection(i))
+
+	if loop.retryCount == 0 {
+		r.saveRollbackPoint(config.RollbackIteration)
+	}
+	prompt := loop.prompt
+	if loop.feedback != "" {
+		prompt = buildVerifyFixPrompt(loop.prompt, loop.feedback)
+	}
+	prompt += r.takeResumedOutput() + loop.answer + r.planNote + r.takeNotes()
+	loop.answer, r.planNote = "", ""
+	iterMark := r.diffMark(config.ShowDiffIteration)
+	result := r.runInSession(ctx, prompt, loop.session)
+	if result.Error != nil {
+		if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
+			return result, err
 		}
+		return result, fmt.Errorf("claude execution: %w", result.Error)
+	}
+	if result.SessionID != "" {
+		loop.session = result.SessionID
+	}
+	r.planText = r.planContent()
+	r.showDiff(iterMark, fmt.Sprintf("task iteration %d", i))
+	r.cfg.Debug.Printf(debuglog.Processor, "task iteration %d/%d: signal %q, retries %d/%d, verify feedback %v",
+		i, loop.limit, result.Signal, loop.retryCount, r.taskRetryCount, loop.feedback != "")
+	return result, nil
+}
 
-		if result.Signal == SignalCompleted {
-			// verify plan actually has no uncompleted checkboxes
-			if r.hasUncompletedTasks() {
-				r.log.Print("warning: completion signal received but plan still has [ ] items, continuing...")
-				continue
-			}
-			r.log.PrintRaw("\nall tasks completed, starting code review...\n")
-			return nil
+// checkTaskIteration runs the checks after a task iteration in order, until one of them decides how the
+// task phase goes on.
+func (r *Runner) checkTaskIteration(ctx context.Context, loop *taskLoop, result executor.Result) (taskStep, error) {
+	checks := []func(context.Context, *taskLoop, executor.Result) (taskStep, error){
+		r.checkTaskNeedsInput, r.checkTaskStall, r.checkMilestoneDone, r.checkTasksCompleted, r.checkTaskFailed,
+		r.checkTaskProgress,
+	}
+	for _, check := range checks {
+		step, err := check(ctx, loop, result)
+		if err != nil || step != taskPass {
+			return step, err
 		}
+	}
+	return taskNext, nil
+}
 
-		if result.Signal == SignalFailed {
-			if retryCount < r.taskRetryCount {
-				r.log.Print("task failed, retrying...")
-				retryCount++
-				if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
-					return fmt.Errorf("interrupted: %w", err)
-				}
-				continue
-			}
-			return errors.New("task execution failed after retry (FAILED signal received)")
+// checkTaskNeedsInput asks a person when the agent stopped for a decision only a person can make,
+// the next iteration gets the answer.
+func (r *Runner) checkTaskNeedsInput(ctx context.Context, loop *taskLoop, result executor.Result) (taskStep, error) {
+	answer, err := r.handleNeedsInput(ctx, result.Output)
+	if err != nil {
+		return taskPass, fmt.Errorf("task phase: %w", err)
+	}
+	loop.answer = answer
+	if answer != "" {
+		return taskNext, nil
+	}
+	return taskPass, nil
+}
+
+// checkTaskStall fails the task phase when the iterations stopped moving the plan and the files.
+func (r *Runner) checkTaskStall(_ context.Context, loop *taskLoop, result executor.Result) (taskStep, error) {
+	// failed iterations are limited by the task retry count, and a completion signal with all tasks done
+	// needs no changes, every other iteration is expected to move the plan on
+	counted := result.Signal != SignalFailed && (result.Signal != SignalCompleted || r.hasUncompletedTasks())
+	if counted && loop.stall.observe(r.progressState(), result.Output) {
+		return taskPass, fmt.Errorf("no progress in %d task iterations in a row: plan and files unchanged, output repeated",
+			loop.stall.stalled)
+	}
+	return taskPass, nil
+}
+
+// checkMilestoneDone ends the task phase once the tasks of the milestone are done, the plan has more.
+func (r *Runner) checkMilestoneDone(ctx context.Context, loop *taskLoop, result executor.Result) (taskStep, error) {
+	if result.Signal == SignalFailed || !r.milestoneDone() || !r.hasUncompletedTasks() {
+		return taskPass, nil
+	}
+	var err error
+	if loop.feedback, err = r.runVerification(ctx); err != nil {
+		return taskPass, err
+	}
+	if loop.feedback != "" {
+		r.log.Print("milestone tasks completed but verification failed, continuing to fix...")
+		return taskNext, nil
+	}
+	r.showDiff(loop.phaseMark, "task phase")
+	r.log.PrintRaw("\nmilestone tasks completed, starting code review...\n")
+	return taskDone, nil
+}
+
+// checkTasksCompleted ends the task phase on the completion signal once the plan has no uncompleted
+// tasks and verification and the completion gates pass.
+func (r *Runner) checkTasksCompleted(ctx context.Context, loop *taskLoop, result executor.Result) (taskStep, error) {
+	if result.Signal != SignalCompleted {
+		return taskPass, nil
+	}
+	// verify plan actually has no uncompleted checkboxes
+	if r.hasUncompletedTasks() {
+		r.log.Print("warning: completion signal received but plan still has [ ] items, continuing...")
+		return taskNext, nil
+	}
+	var err error
+	if loop.feedback, err = r.runVerification(ctx); err != nil {
+		return taskPass, err
+	}
+	if loop.feedback != "" {
+		r.log.Print("all tasks completed but verification failed, continuing to fix...")
+		return taskNext, nil
+	}
+	gates := []struct {
+		name string
+		run  func(context.Context) (string, error)
+	}{
+		{name: "go.mod gate", run: r.runGoModGate},
+		{name: "generated code gate", run: r.runGenerateGate},
+	}
+	for _, gate := range gates {
+		if loop.feedback, err = gate.run(ctx); err != nil {
+			return taskPass, fmt.Errorf("task phase: %w", err)
+		}
+		if loop.feedback != "" {
+			r.log.Print("all tasks completed but %s failed, continuing to fix...", gate.name)
+			return taskNext, nil
 		}
+	}
+	r.showDiff(loop.phaseMark, "task phase")
+	r.log.PrintRaw("\nall tasks completed, starting code review...\n")
+	return taskDone, nil
+}
 
-		retryCount = 0
-		// continue with same prompt - it reads from plan file each time
-		if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
-			return fmt.Errorf("interrupted: %w", err)
+// checkTaskFailed retries the task on the FAILED signal, up to the task retry count, and rolls back
+// the changes once the retries are used up.
+func (r *Runner) checkTaskFailed(ctx context.Context, loop *taskLoop, result executor.Result) (taskStep, error) {
+	if result.Signal != SignalFailed {
+		return taskPass, nil
+	}
+	if loop.retryCount >= r.taskRetryCount {
+		r.rollback()
+		return taskPass, errors.New("task execution failed after retry (FAILED signal received)")
+	}
+	r.log.Print("task failed, retrying...")
+	loop.retryCount++
+	if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
+		return taskPass, fmt.Errorf("interrupted: %w", err)
+	}
+	return taskNext, nil
+}
+
+// checkTaskProgress verifies a regular task iteration, the next one continues with the same prompt
+// as it reads the plan file each time.
+func (r *Runner) checkTaskProgress(ctx context.Context, loop *taskLoop, _ executor.Result) (taskStep, error) {
+	loop.retryCount = 0
+	var err error
+	if loop.feedback, err = r.runVerification(ctx); err != nil {
+		return taskPass, err
+	}
+	if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
+		return taskPass, fmt.Errorf("interrupted: %w", err)
+	}
+	return taskNext, nil
+}
+
+// runVerification runs the formatting gate, the artifact gate and the verification gate after a task
+// iteration, and checks the dependency policy. returns feedback for the next iteration prompt, empty if
+// verification passed or is not configured.
+// only context cancellation and the artifact gate in fail mode are returned as error, failing commands are
+// feedback for the agent.
+func (r *Runner) runVerification(ctx context.Context) (string, error) {
+	formatFeedback, err := r.runFormatGate(ctx)
+	if err != nil {
+		return "", err
+	}
+	artifactFeedback, err := r.runArtifactGate()
+	if err != nil {
+		return "", err
+	}
+	feedback, err := r.runVerifier(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, f := range []string{formatFeedback, artifactFeedback} {
+		if f != "" {
+			feedback = strings.TrimLeft(feedback+"\n\n"+f, "\n")
 		}
 	}
+	if mods := r.disallowedDeps(); len(mods) > 0 {
+		feedback = strings.TrimLeft(feedback+"\n\n"+depsFeedback(mods), "\n")
+	}
+	return feedback, nil
+}
 
-	return fmt.Errorf("max iterations (%d) reached without completion", r.cfg.MaxIterations)
+// runVerifier runs the verification commands, see runVerification.
+func (r *Runner) runVerifier(ctx context.Context) (string, error) {
+	if r.verifier == nil {
+		return "", nil
+	}
+	report := r.verifier.Verify(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("verification: %w", err)
+	}
+	res, failed := report.Failure()
+	if !failed {
+		r.log.Print("verification passed (%d commands)", len(report.Results))
+		return "", nil
+	}
+	if res.Target != "" {
+		r.log.Print("[WARN] verification failed: %s (target %s): %v", res.Command, res.Target, res.Err)
+	} else {
+		r.log.Print("[WARN] verification failed: %s: %v", res.Command, res.Err)
+	}
+	if res.Output != "" {
+		r.log.PrintAligned(res.Output)
+	}
+	return report.Feedback(), nil
+}
+
+// startServices starts ephemeral services for the task phase, if configured.
+func (r *Runner) startServices(ctx context.Context) error {
+	if r.services == nil {
+		return nil
+	}
+	r.log.Print("starting services for verification")
+	env, err := r.services.Up(ctx)
+	if err != nil {
+		r.stopServices() // clean up partially started services
+		return fmt.Errorf("services: %w", err)
+	}
+	if len(env) > 0 {
+		r.log.Print("services started: %s", strings.Join(env, " "))
+	}
+	return nil
+}
+
+// stopServices tears services down. runs with its own timeout since the run context may be canceled.
+func (r *Runner) stopServices() {
+	if r.services == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), servicesStopTimeout)
+	defer cancel()
+	if err := r.services.Down(ctx); err != nil {
+		r.log.Print("[WARN] failed to stop services: %v", err)
+	}
+}
+
+// changedFiles returns files changed on the branch, including uncommitted ones, for scoped verification.
+func (r *Runner) changedFiles() ([]string, error) {
+	if r.git == nil {
+		return nil, errors.New("git is not available")
+	}
+	files, err := r.git.ChangedFiles(r.getDefaultBranch())
+	if err != nil {
+		return nil, fmt.Errorf("list changed files: %w", err)
+	}
+	return files, nil
 }
 
 // runClaudeReview runs Claude review with the given prompt until REVIEW_DONE.
 func (r *Runner) runClaudeReview(ctx context.Context, prompt string) error {
-	result := r.claude.Run(ctx, prompt)
+	iterMark := r.diffMark(config.ShowDiffIteration)
+	result := r.claude.Run(ctx, prompt+r.takeResumedOutput()+r.takeNotes())
 	if result.Error != nil {
 		if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
 			return err
 		}
 		return fmt.Errorf("claude execution: %w", result.Error)
 	}
+	r.showDiff(iterMark, "claude review 0")
 
 	if result.Signal == SignalFailed {
 		return errors.New("review failed (FAILED signal received)")
@@ -414,37 +1233,59 @@ func (r *Runner) runClaudeReview(ctx context.Context, prompt string) error {
 }
 
 // runClaudeReviewLoop runs claude review iterations using second review prompt.
-func (r *Runner) runClaudeReviewLoop(ctx context.Context) error {
+// step tells the loop running before the external review from the one running after it in checkpoints.
+func (r *Runner) runClaudeReviewLoop(ctx context.Context, step Step) error {
 	// review iterations = 10% of max_iterations
 	maxReviewIterations := max(minReviewIterations, r.cfg.MaxIterations/reviewIterationDivisor)
+	prompt := r.reviewPrompt(r.cfg.AppConfig.ReviewSecondPrompt)
+	rejected := "" // why the previous review done signal was rejected
 
-	for i := 1; i <= maxReviewIterations; i++ {
+	for i := r.firstIteration(step); i <= maxReviewIterations; i++ {
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("review: %w", ctx.Err())
 		default:
 		}
+		r.saveCheckpoint(Checkpoint{Step: step, Iteration: i - 1})
+		if err := r.beforeIteration(ctx); err != nil {
+			return fmt.Errorf("review: %w", err)
+		}
 
 		r.log.PrintSection(status.NewClaudeReviewSection(i, ": critical/major"))
 
 		// capture HEAD hash before running claude for no-commit detection
 		headBefore := r.headHash()
+		iterMark := r.diffMark(config.ShowDiffIteration)
 
-		result := r.claude.Run(ctx, r.replacePromptVariables(r.cfg.AppConfig.ReviewSecondPrompt))
+		iterPrompt := prompt
+		if rejected != "" {
+			iterPrompt = buildReviewDoneRejectedPrompt(prompt, rejected)
+		}
+		result := r.claude.Run(ctx, iterPrompt+r.takeResumedOutput()+r.takeNotes())
 		if result.Error != nil {
 			if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
 				return err
 			}
 			return fmt.Errorf("claude execution: %w", result.Error)
 		}
+		r.showDiff(iterMark, fmt.Sprintf("claude review %d", i))
+		r.cfg.Debug.Printf(debuglog.Processor, "claude review %d/%d: signal %q", i, maxReviewIterations, result.Signal)
 
 		if result.Signal == SignalFailed {
 			return errors.New("review failed (FAILED signal received)")
 		}
 
 		if IsReviewDone(result.Signal) {
-			r.log.Print("claude review complete - no more findings")
-			return nil
+			var err error
+			if rejected, err = r.checkReviewDone(ctx, headBefore, result.Output); err != nil {
+				return err
+			}
+			if rejected == "" {
+				r.log.Print("claude review complete - no more findings")
+				return nil
+			}
+			r.log.Print("review done signal rejected, running another review iteration...")
+			continue
 		}
 
 		// fallback: if HEAD hash hasn't changed, claude found nothing to fix
@@ -465,6 +1306,62 @@ func (r *Runner) runClaudeReviewLoop(ctx context.Context) error {
 	return nil
 }
 
+// fixClaimRe matches review responses reporting fixes
+var fixClaimRe = regexp.MustCompile(`(?i)\b(fixed|fixes applied|addressed|resolved)\b`)
+
+// checkReviewDone verifies a review done signal mechanically where possible: a response reporting fixes
+// of findings must come with changes since mark, and the verification gate must pass. returns why the
+// signal is rejected, empty if it stands. only context cancellation is returned as error.
+func (r *Runner) checkReviewDone(ctx context.Context, mark, output string) (string, error) {
+	if !r.cfg.VerifyReviewDone {
+		return "", nil
+	}
+	if mark != "" && fixClaimRe.MatchString(output) && len(ParseFindings(output)) > 0 {
+		diff, err := r.git.DiffSince(mark)
+		switch {
+		case err != nil:
+			r.log.Print("[WARN] failed to get diff, skipping review done check: %v", err)
+		case strings.TrimSpace(diff) == "":
+			r.log.Print("[WARN] review reports fixed findings, but nothing changed")
+			return "the response reports fixed findings, but nothing changed since the review iteration started", nil
+		}
+	}
+	feedback, err := r.runVerification(ctx)
+	if err != nil {
+		return "", err
+	}
+	return feedback, nil
+}
+
+// prepareReviewDiff makes the review diff usable in partial clones and sparse checkouts.
+// missing blobs are fetched in one batch (or reported if partial_clone_fetch is disabled),
+// changed files outside a sparse checkout are reported. failures are logged, not fatal.
+func (r *Runner) prepareReviewDiff() {
+	if r.git == nil {
+		return
+	}
+	fetch := r.cfg.AppConfig == nil || !r.cfg.AppConfig.PartialCloneFetchSet || r.cfg.AppConfig.PartialCloneFetch
+	res, err := r.git.PrepareDiff(r.getDefaultBranch(), fetch)
+	if err != nil {
+		r.log.Print("[WARN] failed to prepare review diff: %v", err)
+	}
+	if res.Fetched > 0 {
+		r.log.Print("fetched %d objects missing from partial clone for review diff", res.Fetched)
+	}
+	if missing := res.Missing - res.Fetched; missing > 0 {
+		r.log.Print("[WARN] %d objects needed for review diff are missing from partial clone, "+
+			"reviewers will fetch them one by one or fail when offline", missing)
+	}
+	if n := len(res.OutsideSparse); n > 0 {
+		const maxListed = 10
+		listed := strings.Join(res.OutsideSparse[:min(n, maxListed)], ", ")
+		if n > maxListed {
+			listed += fmt.Sprintf(" and %d more", n-maxListed)
+		}
+		r.log.Print("[WARN] %d changed files are outside the sparse checkout, reviewers only see them via git diff: %s", n, listed)
+	}
+}
+
 // headHash returns the current HEAD commit hash, or empty string if unavailable.
 func (r *Runner) headHash() string {
 	if r.git == nil {
@@ -478,6 +1375,48 @@ func (r *Runner) headHash() string {
 	return hash
 }
 
+// defaultShowDiffMaxLines limits printed diffs if show_diff_max_lines is not set
+const defaultShowDiffMaxLines = 200
+
+// diffMark returns the HEAD hash to show changes from with showDiff if show_diff is set to scope,
+// empty otherwise.
+func (r *Runner) diffMark(scope string) string {
+	if r.git == nil || r.cfg.AppConfig == nil || r.cfg.AppConfig.ShowDiff != scope {
+		return ""
+	}
+	return r.headHash()
+}
+
+// showDiff prints changes made since mark, committed or not, cutting long diffs to show_diff_max_lines.
+// does nothing if mark is empty.
+func (r *Runner) showDiff(mark, title string) {
+	if mark == "" {
+		return
+	}
+	diff, err := r.git.DiffSince(mark)
+	if err != nil {
+		r.log.Print("[WARN] failed to get diff: %v", err)
+		return
+	}
+	if strings.TrimSpace(diff) == "" {
+		r.log.Print("no changes in %s", title)
+		return
+	}
+
+	maxLines := defaultShowDiffMaxLines
+	if r.cfg.AppConfig.ShowDiffMaxLinesSet {
+		maxLines = r.cfg.AppConfig.ShowDiffMaxLines
+	}
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	if maxLines > 0 && len(lines) > maxLines {
+		short := mark[:min(len(mark), 12)]
+		lines = append(lines[:maxLines], fmt.Sprintf("[... %d more lines, run 'git diff %s' for the full diff ...]",
+			len(lines)-maxLines, short))
+	}
+	r.log.Print("changes in %s:", title)
+	r.log.PrintDiff(strings.Join(lines, "\n"))
+}
+
 // externalReviewTool returns the effective external review tool to use.
 // handles backward compatibility: codex_enabled = false → "none"
 // the CodexEnabled flag takes precedence for backward compatibility.
@@ -504,63 +1443,124 @@ func (r *Runner) runCodexLoop(ctx context.Context) error {
 	// skip external review phase if disabled
 	if tool == "none" {
 		r.log.Print("external review disabled, skipping...")
+		r.externalClean = true // nothing to repeat
 		return nil
 	}
 
+	cfg, err := r.externalReview(tool)
+	if err != nil {
+		return err
+	}
+	return r.runExternalReviewLoop(ctx, cfg)
+}
+
+// externalReview returns the callbacks of an external review tool, codex or custom
+func (r *Runner) externalReview(tool string) (externalReviewConfig, error) {
 	// custom review tool
 	if tool == "custom" {
 		if r.custom == nil {
-			return errors.New("custom review script not configured")
+			return externalReviewConfig{}, errors.New("custom review script not configured")
 		}
-		return r.runExternalReviewLoop(ctx, externalReviewConfig{
+		return externalReviewConfig{
 			name:            "custom",
-			runReview:       func(ctx context.Context, prompt string) executor.Result { return r.custom.Run(ctx, prompt) },
+			runReview:       r.withAnalyzers(r.retrying("custom", r.custom).Run),
 			buildPrompt:     r.buildCustomReviewPrompt,
 			buildEvalPrompt: r.buildCustomEvaluationPrompt,
 			showSummary:     r.showCustomSummary,
 			makeSection:     status.NewCustomIterationSection,
-		})
+		}, nil
+	}
+
+	// plugin executor, reviewing with the custom review prompts
+	if name, ok := strings.CutPrefix(tool, "plugin:"); ok {
+		exec, found := r.cfg.PluginExecutors[name]
+		if !found {
+			return externalReviewConfig{}, fmt.Errorf("plugin %q not configured or provides no executor", name)
+		}
+		return externalReviewConfig{
+			name:            tool,
+			runReview:       r.withAnalyzers(r.retrying(tool, exec).Run),
+			buildPrompt:     r.buildCustomReviewPrompt,
+			buildEvalPrompt: r.buildCustomEvaluationPrompt,
+			showSummary:     func(output string) { r.showExternalReviewSummary(tool, output) },
+			makeSection:     status.NewCustomIterationSection,
+		}, nil
 	}
 
 	// default: codex review
-	return r.runExternalReviewLoop(ctx, externalReviewConfig{
+	cfg := externalReviewConfig{
 		name:            "codex",
-		runReview:       r.codex.Run,
+		runReview:       r.withAnalyzers(r.codex.Run),
 		buildPrompt:     r.buildCodexPrompt,
 		buildEvalPrompt: r.buildCodexEvaluationPrompt,
 		showSummary:     r.showCodexSummary,
 		makeSection:     status.NewCodexIterationSection,
-	})
+	}
+	if split := r.splitCodexReview(); split != nil {
+		cfg.runFirstReview = r.withAnalyzers(split)
+	}
+	return cfg, nil
 }
 
 // externalReviewConfig holds callbacks for running an external review tool.
 type externalReviewConfig struct {
 	name            string                                                   // tool name for error messages
 	runReview       func(ctx context.Context, prompt string) executor.Result // run the external review tool
+	runFirstReview  func(ctx context.Context, prompt string) executor.Result // run of the first iteration, runReview if nil
 	buildPrompt     func(isFirst bool, claudeResponse string) string         // build prompt for review tool
 	buildEvalPrompt func(output string) string                               // build evaluation prompt for claude
 	showSummary     func(output string)                                      // display review findings summary
 	makeSection     func(iteration int) status.Section                       // create section header
 }
 
+// review returns the run of the external review tool for an iteration, the first one or a later one
+func (c externalReviewConfig) review(first bool) func(ctx context.Context, prompt string) executor.Result {
+	if first && c.runFirstReview != nil {
+		return c.runFirstReview
+	}
+	return c.runReview
+}
+
 // runExternalReviewLoop runs a generic external review tool-claude loop until no findings.
 func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewConfig) error {
 	// iterations = 20% of max_iterations (min 3)
 	maxIterations := max(3, r.cfg.MaxIterations/5)
 
-	var claudeResponse string // first iteration has no prior response
+	var claudeResponse, findings string // first iteration has no prior response
+	r.externalClean = false
+	if r.parallelDone == nil {
+		r.loopFindings = nil // findings of the parallel first review belong to this loop
+	}
+	first := 1
+	if cp := r.resumeStep(StepExternal); cp != nil {
+		first, claudeResponse, findings = cp.Iteration+1, cp.Review.ClaudeResponse, cp.Review.Findings
+	} else if cp := r.parallelDone; cp != nil {
+		r.parallelDone = nil // the parallel first review completed the first iteration
+		first, claudeResponse, findings = cp.Iteration+1, cp.Review.ClaudeResponse, cp.Review.Findings
+		if r.consensusClean {
+			r.consensusClean = false
+			r.log.Print("%s review complete - no findings both reviews agree on", cfg.name)
+			r.externalClean = true
+			return nil
+		}
+	}
 
-	for i := 1; i <= maxIterations; i++ {
+	for i := first; i <= maxIterations; i++ {
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("%s loop: %w", cfg.name, ctx.Err())
 		default:
 		}
+		r.saveCheckpoint(Checkpoint{Step: StepExternal, Iteration: i - 1,
+			Review: CheckpointReview{Findings: findings, ClaudeResponse: claudeResponse}})
+		if err := r.beforeIteration(ctx); err != nil {
+			return fmt.Errorf("%s loop: %w", cfg.name, err)
+		}
 
 		r.log.PrintSection(cfg.makeSection(i))
 
 		// run external review tool
-		reviewResult := cfg.runReview(ctx, cfg.buildPrompt(i == 1, claudeResponse))
+		reviewResult := cfg.review(i == 1)(ctx, cfg.buildPrompt(i == 1, claudeResponse))
 		if reviewResult.Error != nil {
 			if err := r.handlePatternMatchError(reviewResult.Error, cfg.name); err != nil {
 				return err
@@ -570,16 +1570,23 @@ func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewCo
 
 		if reviewResult.Output == "" {
 			r.log.Print("%s review returned no output, skipping...", cfg.name)
+			r.externalClean = i == 1
 			break
 		}
 
 		// show findings summary before Claude evaluation
 		cfg.showSummary(reviewResult.Output)
+		r.recordFindings(reviewResult.Output)
 
 		// pass output to claude for evaluation and fixing
 		r.phaseHolder.Set(status.PhaseClaudeEval)
 		r.log.PrintSection(status.NewClaudeEvalSection())
-		claudeResult := r.claude.Run(ctx, cfg.buildEvalPrompt(reviewResult.Output))
+		iterMark := r.diffMark(config.ShowDiffIteration)
+		resolveMark := ""
+		if r.cfg.ResolveFindings {
+			resolveMark = r.headHash()
+		}
+		claudeResult := r.claude.Run(ctx, cfg.buildEvalPrompt(reviewResult.Output)+r.takeResumedOutput())
 
 		// restore codex phase for next iteration
 		r.phaseHolder.Set(status.PhaseCodex)
@@ -590,11 +1597,18 @@ func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewCo
 			return fmt.Errorf("claude execution: %w", claudeResult.Error)
 		}
 
-		claudeResponse = claudeResult.Output
+		claudeResponse, findings = claudeResult.Output, reviewResult.Output
+		r.recordDismissed(claudeResult.Output)
+		r.showDiff(iterMark, fmt.Sprintf("%s iteration %d", cfg.name, i))
+		if err := r.checkResolution(resolveMark, reviewResult.Output, claudeResult.Output); err != nil {
+			return fmt.Errorf("%s iteration %d: %w", cfg.name, i, err)
+		}
+		r.cfg.Debug.Printf(debuglog.Processor, "%s iteration %d: evaluation signal %q", cfg.name, i, claudeResult.Signal)
 
 		// exit only when claude sees "no findings"
 		if IsCodexDone(claudeResult.Signal) {
 			r.log.Print("%s review complete - no more findings", cfg.name)
+			r.externalClean = i == 1
 			return nil
 		}
 
@@ -607,6 +1621,23 @@ func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewCo
 	return nil
 }
 
+// checkResolution fails if findings of the review output were neither fixed since mark nor explained
+// in the evaluation response. does nothing if mark is empty, without git or with the check disabled.
+func (r *Runner) checkResolution(mark, review, response string) error {
+	if mark == "" {
+		return nil
+	}
+	diff, err := r.git.DiffSince(mark)
+	if err != nil {
+		r.log.Print("[WARN] failed to get diff, skipping findings resolution check: %v", err)
+		return nil
+	}
+	if unresolved := unresolvedFindings(review, response, diff); len(unresolved) > 0 {
+		return fmt.Errorf("findings neither fixed nor explained with DISMISSED or SKIPPED: %s", strings.Join(unresolved, ", "))
+	}
+	return nil
+}
+
 // buildCodexPrompt creates the prompt for codex review.
 func (r *Runner) buildCodexPrompt(isFirst bool, claudeResponse string) string {
 	// build plan context if available
@@ -621,14 +1652,11 @@ The code implements the plan at: %s
 	}
 
 	// different diff command based on iteration
-	var diffInstruction, diffDescription string
+	sc := r.specialChanges(isFirst)
+	diffInstruction := "Run: " + r.getDiffInstruction(isFirst, sc)
+	diffDescription := "uncommitted changes (Claude's fixes from previous iteration)"
 	if isFirst {
-		defaultBranch := r.getDefaultBranch()
-		diffInstruction = fmt.Sprintf("Run: git diff %s...HEAD", defaultBranch)
-		diffDescription = fmt.Sprintf("code changes between %s and HEAD branch", defaultBranch)
-	} else {
-		diffInstruction = "Run: git diff"
-		diffDescription = "uncommitted changes (Claude's fixes from previous iteration)"
+		diffDescription = fmt.Sprintf("code changes between %s and HEAD branch", r.getDefaultBranch())
 	}
 
 	basePrompt := fmt.Sprintf(`%sReview the %s.
@@ -643,6 +1671,11 @@ Analyze for:
 - Code quality issues
 
 Report findings with file:line references. If no issues found, say "NO ISSUES FOUND".`, planContext, diffDescription, diffInstruction)
+	basePrompt += specialChangesNote(sc) + baselineNote(r.cfg.Baseline)
+	if r.cfg.AppConfig != nil {
+		basePrompt += instructionsNote(r.cfg.AppConfig.CodexInstructions)
+	}
+	basePrompt += r.languageNote()
 
 	if claudeResponse != "" {
 		return fmt.Sprintf(`%s
@@ -660,8 +1693,13 @@ If Claude's arguments are invalid, explain why the issues still exist.`, basePro
 	return basePrompt
 }
 
-// hasUncompletedTasks checks if plan file has any uncompleted checkboxes.
+// hasUncompletedTasks checks if plan file has any uncompleted checkboxes, only in the selected tasks
+// with a task selection.
 func (r *Runner) hasUncompletedTasks() bool {
+	if r.taskSelection() {
+		pending, err := r.pendingSelectedTasks()
+		return err != nil || len(pending) > 0
+	}
 	content, err := os.ReadFile(r.resolvePlanFilePath())
 	if err != nil {
 		return true // assume incomplete if can't read
@@ -741,7 +1779,7 @@ func (r *Runner) handlePlanDraft(ctx context.Context, output string) draftReview
 
 	r.log.Print("plan draft ready for review")
 
-	action, feedback, askErr := r.inputCollector.AskDraftReview(ctx, "Review the plan draft", planContent)
+	action, feedback, askErr := r.planInput().AskDraftReview(ctx, "Review the plan draft", planContent)
 	if askErr != nil {
 		return draftReviewResult{handled: true, err: fmt.Errorf("collect draft review: %w", askErr)}
 	}
@@ -779,7 +1817,7 @@ func (r *Runner) handlePlanQuestion(ctx context.Context, output string) (bool, e
 
 	r.log.LogQuestion(question.Question, question.Options)
 
-	answer, askErr := r.inputCollector.AskQuestion(ctx, question.Question, question.Options)
+	answer, askErr := r.planInput().AskQuestion(ctx, question.Question, question.Options)
 	if askErr != nil {
 		return true, fmt.Errorf("collect answer: %w", askErr)
 	}
@@ -788,20 +1826,50 @@ func (r *Runner) handlePlanQuestion(ctx context.Context, output string) (bool, e
 	return true, nil
 }
 
-// runPlanCreation executes the interactive plan creation loop.
+// planInput returns the collector answering plan questions and draft reviews,
+// specInput when the plan is drafted from a spec file.
+func (r *Runner) planInput() InputCollector {
+	if r.cfg.PlanSpec != "" {
+		return specInput{}
+	}
+	return r.inputCollector
+}
+
+// specInput stands in for the user while a plan is drafted from a spec file: nobody is there to ask,
+// so questions are sent back to be decided from the spec and drafts are accepted as they are.
+type specInput struct{}
+
+// AskQuestion returns the instruction to decide the question without the user.
+func (specInput) AskQuestion(context.Context, string, []string) (string, error) {
+	return "no one to ask, the plan is drafted from a spec file: pick the option fitting the spec and the codebase " +
+		"best and list the choice under assumptions in the plan", nil
+}
+
+// AskDraftReview accepts the draft.
+func (specInput) AskDraftReview(context.Context, string, string) (action, feedback string, err error) {
+	return "accept", "", nil
+}
+
+// runPlanCreation executes the plan creation loop.
 // the loop continues until PLAN_READY signal or max iterations reached.
-// handles QUESTION signals for Q&A and PLAN_DRAFT signals for draft review.
+// handles QUESTION signals for Q&A and PLAN_DRAFT signals for draft review, asking the input collector,
+// or answering them itself when the plan is drafted from Config.PlanSpec.
 func (r *Runner) runPlanCreation(ctx context.Context) error {
 	if r.cfg.PlanDescription == "" {
 		return errors.New("plan description required for plan mode")
 	}
-	if r.inputCollector == nil {
+	if r.planInput() == nil {
 		return errors.New("input collector required for plan mode")
 	}
 
 	r.phaseHolder.Set(status.PhasePlan)
-	r.log.PrintRaw("starting interactive plan creation\n")
-	r.log.Print("plan request: %s", r.cfg.PlanDescription)
+	if r.cfg.PlanSpec != "" {
+		r.log.PrintRaw("drafting plan from spec file\n")
+		r.log.Print("plan spec: %s", r.cfg.PlanSpec)
+	} else {
+		r.log.PrintRaw("starting interactive plan creation\n")
+		r.log.Print("plan request: %s", r.cfg.PlanDescription)
+	}
 
 	// plan iterations use 20% of max_iterations
 	maxPlanIterations := max(minPlanIterations, r.cfg.MaxIterations/planIterationDivisor)
@@ -949,11 +2017,19 @@ func (r *Runner) runFinalize(ctx context.Context) error {
 	if !r.cfg.FinalizeEnabled {
 		return nil
 	}
+	return r.finalize(ctx)
+}
 
+// finalize runs the finalize step regardless of FinalizeEnabled, a custom pipeline runs it when listed.
+func (r *Runner) finalize(ctx context.Context) (err error) {
 	r.phaseHolder.Set(status.PhaseFinalize)
+	r.emit(Event{Type: EventPhaseStarted, Phase: status.PhaseFinalize})
+	defer func() { r.emit(Event{Type: EventPhaseCompleted, Phase: status.PhaseFinalize, Err: err}) }()
+	r.resumeStep(StepFinalize)
+	r.saveCheckpoint(Checkpoint{Step: StepFinalize})
 	r.log.PrintSection(status.NewGenericSection("finalize step"))
 
-	prompt := r.replacePromptVariables(r.cfg.AppConfig.FinalizePrompt)
+	prompt := r.replacePromptVariables(r.cfg.AppConfig.FinalizePrompt) + r.takeResumedOutput()
 	result := r.claude.Run(ctx, prompt)
 
 	if result.Error != nil {
@@ -979,6 +2055,24 @@ func (r *Runner) runFinalize(ctx context.Context) error {
 	return nil
 }
 
+// withPhaseTimeout runs a phase with ctx limited to d, no limit if d is 0.
+// a phase running out of time returns *PhaseTimeoutError instead of whatever error the interrupted
+// step produced, cancellation of the parent context is passed through as is.
+func (r *Runner) withPhaseTimeout(ctx context.Context, phase status.Phase, d time.Duration,
+	fn func(context.Context) error) error {
+	if d <= 0 {
+		return fn(ctx)
+	}
+	phaseCtx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	err := fn(phaseCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(phaseCtx.Err(), context.DeadlineExceeded) {
+		r.cfg.Debug.Printf(debuglog.Processor, "%s phase timed out after %s: %v", phase, d, err)
+		return &PhaseTimeoutError{Phase: phase, Timeout: d}
+	}
+	return err
+}
+
 // sleepWithContext pauses for the given duration but returns immediately if context is canceled.
 // returns ctx.Err() on cancellation, nil on normal completion.
 func (r *Runner) sleepWithContext(ctx context.Context, d time.Duration) error {
@@ -993,13 +2087,15 @@ func (r *Runner) sleepWithContext(ctx context.Context, d time.Duration) error {
 }
 
 // needsCodexBinary returns true if the current configuration requires the codex binary.
-// returns false when external_review_tool is "custom" or "none", since codex isn't used.
+// returns false when external_review_tool is "custom", "none" or a plugin, since codex isn't used.
 func needsCodexBinary(appConfig *config.Config) bool {
 	if appConfig == nil {
 		return true // default behavior assumes codex
 	}
-	switch appConfig.ExternalReviewTool {
-	case "custom", "none":
+	switch {
+	case appConfig.ExternalReviewTool == "custom", appConfig.ExternalReviewTool == "none":
+		return false
+	case strings.HasPrefix(appConfig.ExternalReviewTool, "plugin:"):
 		return false
 	default:
 		return true // "codex" or empty (default) requires codex binary
@@ -70,3 +70,12 @@ func TestIsCodexPrimaryCommand(t *testing.T) {
 	assert.True(t, isCodexPrimaryCommand(`C:\Tools\codex.exe`))
 	assert.False(t, isCodexPrimaryCommand("claude"))
 }
+
+func BenchmarkAdjustCodexPrimaryArgsForMode(b *testing.B) {
+	args := `exec --dangerously-bypass-approvals-and-sandbox -c model="gpt-5.3-codex" -c model_reasoning_effort=high --search`
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = adjustCodexPrimaryArgsForMode(ModePlan, "/usr/local/bin/codex", args)
+	}
+}
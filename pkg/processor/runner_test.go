@@ -3,9 +3,12 @@ package processor_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -14,10 +17,14 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/debuglog"
 	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/git"
 	"github.com/umputun/ralphex/pkg/processor"
 	"github.com/umputun/ralphex/pkg/processor/mocks"
+	"github.com/umputun/ralphex/pkg/seal"
 	"github.com/umputun/ralphex/pkg/status"
+	"github.com/umputun/ralphex/pkg/verify"
 )
 
 // testAppConfig loads config with embedded defaults for testing.
@@ -50,6 +57,7 @@ func newMockLogger(path string) *mocks.LoggerMock {
 		PrintRawFunc:       func(_ string, _ ...any) {},
 		PrintSectionFunc:   func(_ status.Section) {},
 		PrintAlignedFunc:   func(_ string) {},
+		PrintDiffFunc:      func(_ string) {},
 		LogQuestionFunc:    func(_ string, _ []string) {},
 		LogAnswerFunc:      func(_ string) {},
 		LogDraftReviewFunc: func(_, _ string) {},
@@ -63,7 +71,7 @@ func TestRunner_Run_UnknownMode(t *testing.T) {
 	codex := newMockExecutor(nil)
 
 	r := processor.NewWithExecutors(processor.Config{Mode: "invalid"}, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "unknown mode")
@@ -75,7 +83,7 @@ func TestRunner_RunFull_NoPlanFile(t *testing.T) {
 	codex := newMockExecutor(nil)
 
 	r := processor.NewWithExecutors(processor.Config{Mode: processor.ModeFull}, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "plan file required")
@@ -101,12 +109,372 @@ func TestRunner_RunFull_Success(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, codex.RunCalls(), 1)
 }
 
+func TestRunner_RunFull_SkipPhases(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	t.Run("tasks and codex only", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "task done", Signal: status.Completed}, // task phase completes
+			{Output: "done", Signal: status.CodexDone},      // codex evaluation
+		})
+		codex := newMockExecutor([]executor.Result{{Output: "foo.go:1 unchecked error"}})
+
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true,
+			SkipFirstReview: true, SkipSecondReview: true, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+
+		assert.Len(t, claude.RunCalls(), 2, "no claude review")
+		assert.Len(t, codex.RunCalls(), 1)
+	})
+
+	t.Run("skip codex", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{
+			{Output: "task done", Signal: status.Completed},    // task phase completes
+			{Output: "review done", Signal: status.ReviewDone}, // first review
+			{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop
+		})
+		codex := newMockExecutor(nil)
+
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true,
+			RepeatUntilClean: 2, SkipCodex: true, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+
+		assert.Len(t, claude.RunCalls(), 4, "a single round without the external review")
+		assert.Empty(t, codex.RunCalls())
+	})
+}
+
+func TestRunner_RunFull_MilestoneReviews(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	const planTmpl = "# Plan\n\n## Milestone 1\n### Task 1: storage\n- [%s] schema\n\n## Milestone 2\n### Task 2: api\n- [%s] handlers\n"
+	require.NoError(t, os.WriteFile(planFile, fmt.Appendf(nil, planTmpl, " ", " "), 0o600))
+
+	steps := []func() executor.Result{
+		func() executor.Result { // milestone 1 task, more tasks left
+			require.NoError(t, os.WriteFile(planFile, fmt.Appendf(nil, planTmpl, "x", " "), 0o600))
+			return executor.Result{Output: "task 1 done"}
+		},
+		func() executor.Result { return executor.Result{Signal: status.ReviewDone} }, // first review
+		func() executor.Result { return executor.Result{Signal: status.ReviewDone} }, // pre-codex review loop
+		func() executor.Result { return executor.Result{Signal: status.CodexDone} },  // codex evaluation
+		func() executor.Result { return executor.Result{Signal: status.ReviewDone} }, // post-codex review loop
+		func() executor.Result { // milestone 2 task completes the plan
+			require.NoError(t, os.WriteFile(planFile, fmt.Appendf(nil, planTmpl, "x", "x"), 0o600))
+			return executor.Result{Output: "task 2 done", Signal: status.Completed}
+		},
+		func() executor.Result { return executor.Result{Signal: status.ReviewDone} },
+		func() executor.Result { return executor.Result{Signal: status.ReviewDone} },
+		func() executor.Result { return executor.Result{Signal: status.CodexDone} },
+		func() executor.Result { return executor.Result{Signal: status.ReviewDone} },
+	}
+	calls := 0
+	claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		require.Less(t, calls, len(steps), "unexpected claude call")
+		calls++
+		return steps[calls-1]()
+	}}
+	codex := newMockExecutor([]executor.Result{{Output: "found issue in a.go"}, {Output: "found issue in b.go"}})
+	gitMock := &mocks.GitCheckerMock{
+		HeadHashFunc:       func() (string, error) { return "abc123", nil },
+		DiffSinceFunc:      func(string) (string, error) { return "", nil },
+		ChangedFilesFunc:   func(string) ([]string, error) { return nil, nil },
+		PrepareDiffFunc:    func(string, bool) (git.DiffReadiness, error) { return git.DiffReadiness{}, nil },
+		SpecialChangesFunc: func(string) (git.SpecialChanges, error) { return git.SpecialChanges{}, nil },
+	}
+	appCfg := testAppConfig(t)
+	appCfg.MilestoneReviews = true
+	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true,
+		DefaultBranch: "master", AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+	r.SetGitChecker(gitMock)
+
+	_, err := r.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, len(steps), calls)
+	assert.Len(t, codex.RunCalls(), 2, "external review after each milestone")
+	require.Len(t, gitMock.PrepareDiffCalls(), 2)
+	assert.Equal(t, "master", gitMock.PrepareDiffCalls()[0].BaseBranch, "first milestone reviewed against the default branch")
+	assert.Equal(t, "abc123", gitMock.PrepareDiffCalls()[1].BaseBranch, "later milestones reviewed from their start")
+	assert.Contains(t, codex.RunCalls()[1].Prompt, "abc123")
+}
+
+func TestRunner_RunFull_MilestoneReviewsResume(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	cpPath := filepath.Join(tmpDir, "state.json")
+	const planTmpl = "# Plan\n\n## Milestone 1\n### Task 1: storage\n- [x] schema\n\n## Milestone 2\n### Task 2: api\n- [%s] handlers\n"
+	head := "abc123" // HEAD when milestone 2 starts, moved by its task
+	newGit := func() *mocks.GitCheckerMock {
+		return &mocks.GitCheckerMock{
+			HeadHashFunc:       func() (string, error) { return head, nil },
+			DiffSinceFunc:      func(string) (string, error) { return "", nil },
+			ChangedFilesFunc:   func(string) ([]string, error) { return nil, nil },
+			PrepareDiffFunc:    func(string, bool) (git.DiffReadiness, error) { return git.DiffReadiness{}, nil },
+			SpecialChangesFunc: func(string) (git.SpecialChanges, error) { return git.SpecialChanges{}, nil },
+		}
+	}
+	appCfg := testAppConfig(t)
+	appCfg.MilestoneReviews = true
+	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true,
+		IterationDelayMs: 1, DefaultBranch: "master", CheckpointPath: cpPath, AppConfig: appCfg}
+	reviewsDone := func() *mocks.ExecutorMock {
+		return newMockExecutor([]executor.Result{
+			{Signal: status.ReviewDone}, {Signal: status.ReviewDone}, {Signal: status.CodexDone}, {Signal: status.ReviewDone},
+		})
+	}
+
+	// the review of milestone 2 is interrupted after its task moved HEAD
+	require.NoError(t, os.WriteFile(planFile, fmt.Appendf(nil, planTmpl, " "), 0o600))
+	claude := &mocks.ExecutorMock{}
+	claude.RunFunc = func(context.Context, string) executor.Result {
+		if len(claude.RunCalls()) == 1 {
+			require.NoError(t, os.WriteFile(planFile, fmt.Appendf(nil, planTmpl, "x"), 0o600))
+			head = "def456"
+			return executor.Result{Output: "task 2 done", Signal: status.Completed}
+		}
+		return executor.Result{Error: errors.New("claude crashed")}
+	}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetGitChecker(newGit())
+	_, err := r.Run(context.Background())
+	require.ErrorContains(t, err, "milestone 2: first review")
+
+	cp, err := processor.LoadCheckpoint(cpPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, processor.StepFirstReview, cp.Step)
+	assert.Equal(t, 2, cp.Milestone)
+	assert.Equal(t, "abc123", cp.Review.Base, "milestone start, not HEAD at the interruption")
+
+	t.Run("resumed review diffs against the milestone start", func(t *testing.T) {
+		cfg := cfg
+		cfg.Resume = cp
+		gitMock := newGit()
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), reviewsDone(),
+			newMockExecutor([]executor.Result{{Output: "found issue in b.go"}}), nil, &status.PhaseHolder{})
+		r.SetGitChecker(gitMock)
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+		require.Len(t, gitMock.PrepareDiffCalls(), 1)
+		assert.Equal(t, "abc123", gitMock.PrepareDiffCalls()[0].BaseBranch)
+	})
+
+	t.Run("resumed task phase keeps the milestone start", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(planFile, fmt.Appendf(nil, planTmpl, " "), 0o600))
+		claude := &mocks.ExecutorMock{}
+		steps := reviewsDone()
+		claude.RunFunc = func(ctx context.Context, prompt string) executor.Result {
+			if len(claude.RunCalls()) == 1 {
+				require.NoError(t, os.WriteFile(planFile, fmt.Appendf(nil, planTmpl, "x"), 0o600))
+				return executor.Result{Output: "task 2 done", Signal: status.Completed}
+			}
+			return steps.Run(ctx, prompt)
+		}
+		cfg := cfg
+		cfg.Resume = &processor.Checkpoint{Version: 2, Step: processor.StepTask, Iteration: 1, TaskIterations: 2, Milestone: 2,
+			Review: processor.CheckpointReview{Base: "abc123"}}
+		gitMock := newGit() // HEAD is def456 after the iterations of the interrupted run
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude,
+			newMockExecutor([]executor.Result{{Output: "found issue in b.go"}}), nil, &status.PhaseHolder{})
+		r.SetGitChecker(gitMock)
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+		require.Len(t, gitMock.PrepareDiffCalls(), 1)
+		assert.Equal(t, "abc123", gitMock.PrepareDiffCalls()[0].BaseBranch)
+	})
+}
+
+func TestRunner_RunFull_DebugWrapsExecutors(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir) // transcripts are written relative to the working directory
+	t.Setenv("RALPHEX_TEST_TOKEN", "very-secret-value")
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	claude := newMockExecutor([]executor.Result{
+		{Output: "task done", Signal: status.Completed},
+		{Output: "review done", Signal: status.ReviewDone},
+		{Output: "review done", Signal: status.ReviewDone},
+		{Output: "done", Signal: status.CodexDone},
+		{Output: "review done", Signal: status.ReviewDone},
+	})
+	codex := newMockExecutor([]executor.Result{{Output: "found issue in foo.go, leaked very-secret-value"}})
+
+	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true,
+		AppConfig: testAppConfig(t), Debug: debuglog.Flags{Prompts: true, Signals: true, Processor: true}}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+	require.NoError(t, err)
+
+	// prompts pass through the debug wrapper unchanged
+	require.Len(t, claude.RunCalls(), 5)
+	assert.Contains(t, claude.RunCalls()[0].Prompt, planFile)
+	require.Len(t, codex.RunCalls(), 1)
+
+	// every agent call is dumped to the transcript, numbered and redacted
+	files, err := filepath.Glob(filepath.Join(processor.TranscriptDir, "progress-*", "*.txt"))
+	require.NoError(t, err)
+	require.Len(t, files, 6)
+	first, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(first), "agent: claude\nphase: task\n")
+	assert.Contains(t, string(first), "===== prompt (")
+	assert.Contains(t, string(first), "===== response (")
+	assert.Contains(t, string(first), "task done")
+
+	codexFile, err := filepath.Glob(filepath.Join(processor.TranscriptDir, "progress-*", "*-codex.txt"))
+	require.NoError(t, err)
+	require.Len(t, codexFile, 1)
+	data, err := os.ReadFile(codexFile[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "found issue in foo.go, leaked [REDACTED]")
+	assert.NotContains(t, string(data), "very-secret-value")
+}
+
+func TestRunner_Run_DebugPromptsEncrypted(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+	appCfg := testAppConfig(t)
+	var err error
+	appCfg.ArtifactSealer, err = seal.New("transcript passphrase")
+	require.NoError(t, err)
+
+	claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+		return executor.Result{Output: "proprietary code", Signal: status.Completed}
+	}}
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+		TranscriptDir: filepath.Join(tmpDir, "transcripts"), AppConfig: appCfg, Debug: debuglog.Flags{Prompts: true}}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	_, err = r.Run(context.Background())
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(tmpDir, "transcripts", "progress-*", "*.txt"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, filepath.Dir(files[0]), r.TranscriptPath())
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "proprietary code")
+	plain, err := appCfg.ArtifactSealer.Open(data)
+	require.NoError(t, err)
+	assert.Contains(t, string(plain), "===== response (")
+	assert.Contains(t, string(plain), "proprietary code")
+}
+
+func TestRunner_Run_DebugPromptsFullOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake claude is a shell script")
+	}
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", filepath.Join(tmpDir, "tmp")) // spill files of the executor
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "tmp"), 0o750))
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+
+	// fake claude printing plain text output far over the retention limit, with a marker in the middle,
+	// and the completion signal as a stream event
+	script := filepath.Join(tmpDir, "claude.sh")
+	body := "#!/bin/sh\nprintf '# Plan\\n- [x] Task 1' > " + planFile + "\necho start\n" +
+		"i=0; while [ $i -lt 200 ]; do echo \"line $i of a huge file\"; i=$((i+1)); done\n" +
+		"echo middle-marker\ni=0; while [ $i -lt 200 ]; do echo \"line $i of a huge file\"; i=$((i+1)); done\n" +
+		"echo '{\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"" + status.Completed + "\"}}'\n"
+	require.NoError(t, os.WriteFile(script, []byte(body), 0o700)) //nolint:gosec // executable test script
+
+	appCfg := testAppConfig(t)
+	appCfg.ClaudeCommand, appCfg.ClaudeArgs, appCfg.MaxOutputBytes = script, "", 200
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 3, IterationDelayMs: 1,
+		TranscriptDir: filepath.Join(tmpDir, "transcripts"), AppConfig: appCfg, Debug: debuglog.Flags{Prompts: true}}
+	r := processor.New(cfg, newMockLogger("progress.txt"), &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(r.TranscriptPath(), "*.txt"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0]) //nolint:gosec // test transcript
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "start\nline 0 of a huge file\n")
+	assert.Contains(t, string(data), "line 199 of a huge file\nmiddle-marker\nline 0 of a huge file\n",
+		"dropped middle of the output is in the transcript")
+	assert.NotContains(t, string(data), "bytes of output truncated")
+
+	entries, err := os.ReadDir(filepath.Join(tmpDir, "tmp"))
+	require.NoError(t, err)
+	for _, e := range entries {
+		assert.False(t, strings.HasPrefix(e.Name(), "ralphex-output-"), "spill file %s removed", e.Name())
+	}
+}
+
+func TestRunner_Run_DryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+
+	tests := []struct {
+		name     string
+		mode     processor.Mode
+		tool     string
+		finalize bool
+		want     []string
+	}{
+		{name: "full", mode: processor.ModeFull, finalize: true, want: []string{"dry run: task iteration (claude)",
+			"dry run: claude review 0: all findings (claude)", "dry run: claude review: critical/major (claude)",
+			"dry run: codex review (codex)", "dry run: claude evaluating codex findings (claude)", "dry run: finalize step (claude)"}},
+		{name: "tasks only", mode: processor.ModeTasksOnly, want: []string{"dry run: task iteration (claude)"}},
+		{name: "review without external", mode: processor.ModeReview, tool: "none", want: []string{
+			"dry run: claude review 0: all findings (claude)", "dry run: claude review: critical/major (claude)"}},
+		{name: "codex only with custom tool", mode: processor.ModeCodexOnly, tool: "custom", want: []string{
+			"dry run: custom review (custom)", "dry run: claude evaluating custom review findings (claude)",
+			"dry run: claude review: critical/major (claude)"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			appCfg := testAppConfig(t)
+			appCfg.ExternalReviewTool = tc.tool
+			var sections []string
+			var raw strings.Builder
+			log := newMockLogger("progress.txt")
+			log.PrintSectionFunc = func(s status.Section) { sections = append(sections, s.Label) }
+			log.PrintRawFunc = func(format string, args ...any) { raw.WriteString(fmt.Sprintf(format, args...)) }
+			claude, codex := newMockExecutor(nil), newMockExecutor(nil)
+
+			cfg := processor.Config{Mode: tc.mode, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true,
+				FinalizeEnabled: tc.finalize, DryRun: true, AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+			_, err := r.Run(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, sections)
+			assert.Empty(t, claude.RunCalls())
+			assert.Empty(t, codex.RunCalls())
+			if tc.mode != processor.ModeReview && tc.mode != processor.ModeCodexOnly {
+				assert.Contains(t, raw.String(), planFile, "task prompt is rendered with the plan file")
+			}
+		})
+	}
+
+	t.Run("plan file required", func(t *testing.T) {
+		cfg := processor.Config{Mode: processor.ModeFull, DryRun: true, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger(""), newMockExecutor(nil), nil, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.EqualError(t, err, "plan file required for full mode")
+	})
+}
+
 func TestRunner_RunFull_NoCodexFindings(t *testing.T) {
 	tmpDir := t.TempDir()
 	planFile := filepath.Join(tmpDir, "plan.md")
@@ -125,7 +493,7 @@ func TestRunner_RunFull_NoCodexFindings(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 }
@@ -144,7 +512,7 @@ func TestRunner_RunReviewOnly_Success(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, codex.RunCalls(), 1)
@@ -162,12 +530,538 @@ func TestRunner_RunCodexOnly_Success(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, codex.RunCalls(), 1)
 }
 
+func TestRunner_EscalationReview(t *testing.T) {
+	const finding = "- [P1] main.go:10: nil dereference of cfg"
+	tests := []struct {
+		name          string
+		verification  string   // output of the verification pass
+		escalated     []string // outputs of the escalation verification pass
+		wantEscalated int      // escalation claude calls
+		wantLog       string
+	}{
+		{name: "clean verification", verification: "NO ISSUES FOUND", wantLog: "codex verification pass found no issues"},
+		{name: "resolved", verification: finding, escalated: []string{"NO ISSUES FOUND"}, wantEscalated: 1,
+			wantLog: "escalation review resolved the remaining findings"},
+		{name: "findings left", verification: finding, escalated: []string{finding}, wantEscalated: 1,
+			wantLog: "[WARN] codex still reports 1 findings after the escalation review, continuing..."},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			claude := newMockExecutor([]executor.Result{
+				{Output: "done", Signal: status.CodexDone},         // evaluation of the clean codex pass
+				{Output: "review done", Signal: status.ReviewDone}, // post-codex review
+			})
+			codex := newMockExecutor([]executor.Result{{Output: "NO ISSUES FOUND"}, {Output: tc.verification}})
+			escalatedClaude := newMockExecutor([]executor.Result{{Output: "fixed main.go:10"}})
+			var escalatedResults []executor.Result
+			for _, out := range tc.escalated {
+				escalatedResults = append(escalatedResults, executor.Result{Output: out})
+			}
+			escalatedCodex := newMockExecutor(escalatedResults)
+
+			var logged []string
+			log := newMockLogger("")
+			log.PrintFunc = func(format string, args ...any) { logged = append(logged, fmt.Sprintf(format, args...)) }
+			appCfg := testAppConfig(t)
+			appCfg.EscalationReview = true
+			cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg,
+				PhaseExecutors: map[string]processor.Executors{processor.PhaseEscalation: {Claude: escalatedClaude, Codex: escalatedCodex}}}
+			r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+			_, err := r.Run(context.Background())
+			require.NoError(t, err)
+
+			assert.Len(t, codex.RunCalls(), 2, "review loop and verification pass")
+			assert.Len(t, claude.RunCalls(), 2)
+			require.Len(t, escalatedClaude.RunCalls(), tc.wantEscalated)
+			assert.Len(t, escalatedCodex.RunCalls(), len(tc.escalated))
+			if tc.wantEscalated > 0 {
+				assert.Contains(t, escalatedClaude.RunCalls()[0].Prompt, "nil dereference of cfg")
+			}
+			assert.Contains(t, logged, tc.wantLog)
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{
+			{Output: "done", Signal: status.CodexDone},
+			{Output: "review done", Signal: status.ReviewDone},
+		})
+		codex := newMockExecutor([]executor.Result{{Output: "NO ISSUES FOUND"}})
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger(""), claude, codex, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, codex.RunCalls(), 1)
+	})
+}
+
+func TestRunner_FinalVerification(t *testing.T) {
+	tests := []struct {
+		name         string
+		verification executor.Result
+		want         *processor.VerificationReport
+		wantLog      string
+	}{
+		{name: "clean", verification: executor.Result{Output: "NO ISSUES FOUND"},
+			want: &processor.VerificationReport{Tool: "codex"}, wantLog: "codex final verification pass found no issues"},
+		{name: "findings left", verification: executor.Result{Output: "- [P1] main.go:10: nil dereference of cfg"},
+			want: &processor.VerificationReport{Tool: "codex",
+				Residual: []processor.Finding{{File: "main.go", Message: "nil dereference of cfg"}}},
+			wantLog: "[WARN] final verification still reports 1 findings, left unfixed:"},
+		{name: "failed pass", verification: executor.Result{Error: errors.New("codex crashed")},
+			wantLog: "[WARN] final verification failed, continuing: codex final verification pass: codex crashed"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			claude := newMockExecutor([]executor.Result{
+				{Output: "done", Signal: status.CodexDone},         // evaluation of the clean codex pass
+				{Output: "review done", Signal: status.ReviewDone}, // post-codex review
+			})
+			codex := newMockExecutor([]executor.Result{{Output: "NO ISSUES FOUND"}, tc.verification})
+
+			var logged []string
+			log := newMockLogger("")
+			log.PrintFunc = func(format string, args ...any) { logged = append(logged, fmt.Sprintf(format, args...)) }
+			appCfg := testAppConfig(t)
+			appCfg.FinalVerification = true
+			cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+			report, err := r.Run(context.Background())
+			require.NoError(t, err)
+
+			assert.Len(t, codex.RunCalls(), 2, "review loop and final verification pass")
+			assert.Len(t, claude.RunCalls(), 2, "nothing fixed after the final verification")
+			assert.Equal(t, tc.want, report.FinalVerification)
+			assert.Contains(t, logged, tc.wantLog)
+		})
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{
+			{Output: "done", Signal: status.CodexDone},
+			{Output: "review done", Signal: status.ReviewDone},
+		})
+		codex := newMockExecutor([]executor.Result{{Output: "NO ISSUES FOUND"}, {Error: context.Canceled}})
+		appCfg := testAppConfig(t)
+		appCfg.FinalVerification = true
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger(""), claude, codex, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestRunner_RepeatUntilClean(t *testing.T) {
+	// a round where codex finds an issue, claude fixes it and the next codex pass is clean
+	fixRound := []executor.Result{
+		{Output: "fixed"},                                  // evaluation of codex findings, no signal
+		{Output: "done", Signal: status.CodexDone},         // evaluation of the clean codex pass
+		{Output: "review done", Signal: status.ReviewDone}, // post-codex review
+	}
+	cleanRound := []executor.Result{
+		{Output: "done", Signal: status.CodexDone},
+		{Output: "review done", Signal: status.ReviewDone},
+	}
+
+	tests := []struct {
+		name       string
+		repeat     int
+		claude     [][]executor.Result
+		codex      []string
+		wantRounds []string
+	}{
+		{name: "disabled", repeat: 0, claude: [][]executor.Result{fixRound}, codex: []string{"issue A", "ok"},
+			wantRounds: []string{"codex external review"}},
+		{name: "stops at clean round", repeat: 3, claude: [][]executor.Result{fixRound, cleanRound},
+			codex:      []string{"issue A", "ok", "nothing found"},
+			wantRounds: []string{"codex external review", "codex external review (round 2)"}},
+		{name: "stops at round limit", repeat: 1, claude: [][]executor.Result{fixRound, fixRound},
+			codex:      []string{"issue A", "ok", "issue B", "ok"},
+			wantRounds: []string{"codex external review", "codex external review (round 2)"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var claudeResults, codexResults []executor.Result
+			for _, round := range tc.claude {
+				claudeResults = append(claudeResults, round...)
+			}
+			for _, out := range tc.codex {
+				codexResults = append(codexResults, executor.Result{Output: out})
+			}
+			var rounds []string
+			log := newMockLogger("progress.txt")
+			log.PrintSectionFunc = func(s status.Section) {
+				if strings.HasPrefix(s.Label, "codex external review") {
+					rounds = append(rounds, s.Label)
+				}
+			}
+			claude, codex := newMockExecutor(claudeResults), newMockExecutor(codexResults)
+
+			cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+				RepeatUntilClean: tc.repeat, AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+			_, err := r.Run(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantRounds, rounds)
+			assert.Len(t, codex.RunCalls(), len(tc.codex))
+			assert.Len(t, claude.RunCalls(), len(claudeResults))
+		})
+	}
+}
+
+func TestRunner_Pipeline(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+	phases := func(names ...string) []config.PhaseSpec {
+		var res []config.PhaseSpec
+		for _, n := range names {
+			res = append(res, config.PhaseSpec{Name: strings.TrimSuffix(n, "?"), Optional: strings.HasSuffix(n, "?")})
+		}
+		return res
+	}
+
+	t.Run("repeated phases", func(t *testing.T) {
+		var sections []string
+		log := newMockLogger("progress.txt")
+		log.PrintSectionFunc = func(s status.Section) { sections = append(sections, s.Label) }
+		claude := newMockExecutor([]executor.Result{
+			{Output: "review done", Signal: status.ReviewDone}, // first review, all findings
+			{Output: "review done", Signal: status.ReviewDone}, // review loop
+			{Output: "done", Signal: status.CodexDone},         // codex evaluation
+			{Output: "done", Signal: status.CodexDone},         // second codex evaluation
+			{Output: "review done", Signal: status.ReviewDone}, // final review loop
+			{Output: "finalized"},                              // finalize
+		})
+		codex := newMockExecutor([]executor.Result{{Output: "issue in a.go:1"}, {Output: "issue in b.go:2"}})
+		cfg := processor.Config{Mode: processor.ModeFull, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+			Phases: phases("review", "codex", "codex", "review", "finalize"), AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+
+		assert.Len(t, codex.RunCalls(), 2)
+		assert.Len(t, claude.RunCalls(), 6, "finalize runs when listed, even with finalize disabled")
+		assert.Equal(t, 1, strings.Count(strings.Join(sections, "\n"), "claude review 0: all findings"))
+		assert.Contains(t, sections, "finalize step")
+	})
+
+	t.Run("optional phase failure", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "task done", Signal: status.Completed},
+			{Output: "review done", Signal: status.ReviewDone},
+			{Output: "review done", Signal: status.ReviewDone},
+		})
+		codex := newMockExecutor([]executor.Result{{Error: errors.New("codex crashed")}})
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true,
+			IterationDelayMs: 1, Phases: phases("task", "codex?", "review"), AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+
+		assert.Len(t, claude.RunCalls(), 3)
+		var warned bool
+		for _, c := range log.PrintCalls() {
+			warned = warned || strings.Contains(fmt.Sprintf(c.Format, c.Args...), "optional codex phase failed, continuing")
+		}
+		assert.True(t, warned)
+	})
+
+	t.Run("required phase failure", func(t *testing.T) {
+		codex := newMockExecutor([]executor.Result{{Error: errors.New("codex crashed")}})
+		cfg := processor.Config{Mode: processor.ModeFull, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+			Phases: phases("codex", "review"), AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), newMockExecutor(nil), codex, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.ErrorContains(t, err, "codex phase (1 of 2): codex execution: codex crashed")
+	})
+
+	t.Run("resume at a repeated phase", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{
+			{Output: "done", Signal: status.CodexDone},
+			{Output: "review done", Signal: status.ReviewDone},
+		})
+		codex := newMockExecutor([]executor.Result{{Output: "issue in a.go:1"}})
+		cfg := processor.Config{Mode: processor.ModeFull, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+			Phases: phases("review", "codex", "codex", "review"), AppConfig: testAppConfig(t),
+			Resume: &processor.Checkpoint{Mode: processor.ModeFull, Step: processor.StepExternal, Stage: 2}}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, codex.RunCalls(), 1, "phases before the checkpoint are skipped")
+		assert.Len(t, claude.RunCalls(), 2)
+	})
+
+	t.Run("task phase needs plan file", func(t *testing.T) {
+		cfg := processor.Config{Mode: processor.ModeFull, MaxIterations: 50, Phases: phases("task", "review"),
+			AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), newMockExecutor(nil), newMockExecutor(nil), nil,
+			&status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.EqualError(t, err, "plan file required for a pipeline with task phase")
+	})
+}
+
+func TestFormatPipeline(t *testing.T) {
+	assert.Equal(t, "task → review → codex? → review", processor.FormatPipeline([]config.PhaseSpec{{Name: "task"},
+		{Name: "review"}, {Name: "codex", Optional: true}, {Name: "review"}}))
+}
+
+func TestRunner_PhaseTimeout(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+	// blocked runs until the context is done, as a hung agent would
+	blocked := func(ctx context.Context, _ string) executor.Result {
+		<-ctx.Done()
+		return executor.Result{Error: ctx.Err()}
+	}
+	const limit = 50 * time.Millisecond
+
+	tests := []struct {
+		name      string
+		cfg       processor.Config
+		claude    *mocks.ExecutorMock
+		codex     *mocks.ExecutorMock
+		wantPhase status.Phase
+		wantErr   string
+	}{
+		{name: "task", cfg: processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, TaskTimeout: limit},
+			claude: &mocks.ExecutorMock{RunFunc: blocked}, codex: newMockExecutor(nil),
+			wantPhase: status.PhaseTask, wantErr: "task phase: task phase timed out after 50ms"},
+		{name: "review", cfg: processor.Config{Mode: processor.ModeReview, ReviewTimeout: limit},
+			claude: &mocks.ExecutorMock{RunFunc: blocked}, codex: newMockExecutor(nil),
+			wantPhase: status.PhaseReview, wantErr: "review phase timed out after 50ms"},
+		{name: "codex", cfg: processor.Config{Mode: processor.ModeCodexOnly, CodexEnabled: true, CodexTimeout: limit},
+			claude: newMockExecutor(nil), codex: &mocks.ExecutorMock{RunFunc: blocked},
+			wantPhase: status.PhaseCodex, wantErr: "codex loop: codex phase timed out after 50ms"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := tc.cfg
+			cfg.MaxIterations, cfg.IterationDelayMs, cfg.AppConfig = 50, 1, testAppConfig(t)
+			r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), tc.claude, tc.codex, nil, &status.PhaseHolder{})
+			_, err := r.Run(context.Background())
+			require.EqualError(t, err, tc.wantErr)
+			var timeoutErr *processor.PhaseTimeoutError
+			require.ErrorAs(t, err, &timeoutErr)
+			assert.Equal(t, tc.wantPhase, timeoutErr.Phase)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		})
+	}
+
+	t.Run("parent deadline is not a phase timeout", func(t *testing.T) {
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1,
+			TaskTimeout: time.Hour, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), &mocks.ExecutorMock{RunFunc: blocked},
+			newMockExecutor(nil), nil, &status.PhaseHolder{})
+		ctx, cancel := context.WithTimeout(context.Background(), limit)
+		defer cancel()
+		_, err := r.Run(ctx)
+		require.Error(t, err)
+		var timeoutErr *processor.PhaseTimeoutError
+		assert.NotErrorAs(t, err, &timeoutErr)
+	})
+}
+
+func TestRunner_MaxRunDuration(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+	checkpointPath := filepath.Join(tmpDir, "state.json")
+	// the first iteration makes progress, the second hangs until the budget runs out
+	calls := 0
+	claude := &mocks.ExecutorMock{RunFunc: func(ctx context.Context, _ string) executor.Result {
+		calls++
+		if calls == 1 {
+			return executor.Result{Output: "part of task 1 done"}
+		}
+		<-ctx.Done()
+		return executor.Result{Error: ctx.Err()}
+	}}
+
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1,
+		MaxRunDuration: 100 * time.Millisecond, CheckpointPath: checkpointPath, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+
+	require.EqualError(t, err, "run time budget of 100ms exceeded in task phase, task step iteration 2")
+	var budgetErr *processor.RunBudgetError
+	require.ErrorAs(t, err, &budgetErr)
+	assert.Equal(t, status.PhaseTask, budgetErr.Phase)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the checkpoint is kept for --resume, with the completed iteration
+	cp, err := processor.LoadCheckpoint(checkpointPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, processor.StepTask, cp.Step)
+	assert.Equal(t, 1, cp.Iteration)
+}
+
+func TestRunner_Stop(t *testing.T) {
+	tests := []struct {
+		name string
+		stop func(r *processor.Runner, stopFile string)
+	}{
+		{name: "request stop", stop: func(r *processor.Runner, _ string) { r.RequestStop() }},
+		{name: "stop file", stop: func(_ *processor.Runner, stopFile string) {
+			require.NoError(t, os.WriteFile(stopFile, nil, 0o600))
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			planFile := filepath.Join(tmpDir, "plan.md")
+			require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+			checkpointPath, stopFile := filepath.Join(tmpDir, "state.json"), filepath.Join(tmpDir, "stop")
+
+			var r *processor.Runner
+			claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+				tc.stop(r, stopFile) // requested during the call, which still completes
+				return executor.Result{Output: "part of task 1 done"}
+			}}
+			cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1,
+				CheckpointPath: checkpointPath, StopFile: stopFile, AppConfig: testAppConfig(t)}
+			r = processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+			_, err := r.Run(context.Background())
+
+			require.EqualError(t, err, "stopped by user in task phase, before task step iteration 2")
+			var stopErr *processor.StopError
+			require.ErrorAs(t, err, &stopErr)
+			assert.Equal(t, processor.StepTask, stopErr.Step)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Len(t, claude.RunCalls(), 1)
+			assert.NoFileExists(t, stopFile, "the stop file is consumed")
+
+			cp, err := processor.LoadCheckpoint(checkpointPath, nil)
+			require.NoError(t, err)
+			assert.Equal(t, processor.StepTask, cp.Step)
+			assert.Equal(t, 1, cp.Iteration)
+		})
+	}
+}
+
+func TestRunner_RunWindow(t *testing.T) {
+	window, err := config.ParseRunWindow("22:00-06:00")
+	require.NoError(t, err)
+	night := time.Date(2026, 3, 10, 23, 0, 0, 0, time.Local)
+
+	t.Run("stops once the window closes", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		planFile := filepath.Join(tmpDir, "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+		checkpointPath := filepath.Join(tmpDir, "state.json")
+
+		now := night
+		claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			now = night.Add(8 * time.Hour) // morning, the window closed during the call
+			return executor.Result{Output: "part of task 1 done"}
+		}}
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1,
+			CheckpointPath: checkpointPath, RunWindow: window, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		r.TestSetNow(func() time.Time { return now })
+		_, err := r.Run(context.Background())
+
+		require.EqualError(t, err, "stopped as run window 22:00-06:00 closed in task phase, before task step iteration 2")
+		var stopErr *processor.StopError
+		require.ErrorAs(t, err, &stopErr)
+		assert.Equal(t, "22:00-06:00", stopErr.Window)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Len(t, claude.RunCalls(), 1)
+		assert.True(t, printed(log, "[STOPPED] run window 22:00-06:00 closed"))
+
+		cp, err := processor.LoadCheckpoint(checkpointPath, nil)
+		require.NoError(t, err)
+		assert.Equal(t, processor.StepTask, cp.Step)
+		assert.Equal(t, 1, cp.Iteration)
+	})
+
+	t.Run("waits outside the window", func(t *testing.T) {
+		claude := newMockExecutor(nil)
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, MaxIterations: 50, RunWindow: window, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		r.TestSetNow(func() time.Time { return night.Add(-10 * time.Hour) })
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := r.Run(ctx)
+
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "waiting for run window")
+		assert.Empty(t, claude.RunCalls())
+		assert.True(t, printed(log, "outside run window 22:00-06:00, waiting until"))
+	})
+
+	t.Run("wait is not part of the run budget", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+		claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+			return executor.Result{Output: "done", Signal: status.Completed}
+		}}
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1,
+			RunWindow: window, MaxRunDuration: 100 * time.Millisecond, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		opens := time.Date(2026, 3, 10, 22, 0, 0, 0, time.Local)
+		calls := 0
+		r.TestSetNow(func() time.Time {
+			calls++
+			if calls == 1 {
+				return opens.Add(-300 * time.Millisecond) // the window opens after the budget would run out
+			}
+			return night
+		})
+		_, err := r.Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Len(t, claude.RunCalls(), 1)
+		assert.True(t, printed(log, "outside run window 22:00-06:00, waiting until"))
+	})
+}
+
+func TestRunner_PartialOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+	checkpointPath := filepath.Join(tmpDir, "state.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	claude := &mocks.ExecutorMock{}
+	claude.RunFunc = func(ctx context.Context, _ string) executor.Result {
+		if len(claude.RunCalls()) == 1 {
+			return executor.Result{Output: "task 1 started"}
+		}
+		cancel() // Ctrl+C while the agent works on the second iteration
+		return executor.Result{Output: "task 1 half done", Error: ctx.Err()}
+	}
+	log := newMockLogger("progress.txt")
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1,
+		CheckpointPath: checkpointPath, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	report, err := r.Run(ctx)
+
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "task 1 half done", report.PartialOutput)
+	assert.True(t, printed(log, "agent call interrupted, 16 bytes of its output kept"))
+
+	cp, err := processor.LoadCheckpoint(checkpointPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, processor.StepTask, cp.Step)
+	assert.Equal(t, 1, cp.Iteration, "the checkpoint of the interrupted iteration")
+	assert.Equal(t, "task 1 half done", cp.PartialOutput)
+	assert.Equal(t, "task 1 half done", cp.LastOutput)
+}
+
 func TestRunner_RunCodexOnly_NoFindings(t *testing.T) {
 	log := newMockLogger("progress.txt")
 	claude := newMockExecutor([]executor.Result{
@@ -177,47 +1071,461 @@ func TestRunner_RunCodexOnly_NoFindings(t *testing.T) {
 		{Output: ""}, // no findings
 	})
 
-	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
-	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+
+	require.NoError(t, err)
+}
+
+func TestRunner_CodexDisabled_SkipsCodexPhase(t *testing.T) {
+	log := newMockLogger("progress.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop
+	})
+	codex := newMockExecutor(nil)
+
+	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: false, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, codex.RunCalls(), "codex should not be called when disabled")
+}
+
+func TestRunner_RunTasksOnly_Success(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	// all tasks complete - no [ ] checkboxes
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	log := newMockLogger("progress.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: "task done", Signal: status.Completed}, // task phase completes
+	})
+	codex := newMockExecutor(nil)
+
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 50, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, codex.RunCalls(), "codex should not be called in tasks-only mode")
+	assert.Len(t, claude.RunCalls(), 1)
+	assert.Equal(t, 1, r.TaskIterations())
+}
+
+func TestRunner_TaskPhase_VerificationFailureFedBack(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	log := newMockLogger("progress.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: "task done", Signal: status.Completed},   // completes, but verification fails
+		{Output: "fixed build", Signal: status.Completed}, // fixes the failure
+	})
+	failing := verify.Report{Results: []verify.Result{
+		{Command: "go build ./..."},
+		{Command: "go test ./...", Err: errors.New("exit status 1"), Output: "--- FAIL: TestX"},
+	}}
+	passing := verify.Report{Results: []verify.Result{{Command: "go build ./..."}, {Command: "go test ./..."}}}
+	verifier := &mocks.VerifierMock{}
+	verifier.VerifyFunc = func(context.Context) verify.Report {
+		if len(verifier.VerifyCalls()) == 1 {
+			return failing
+		}
+		return passing
+	}
+
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetVerifier(verifier)
+	_, err := r.Run(context.Background())
+	require.NoError(t, err)
+
+	calls := claude.RunCalls()
+	require.Len(t, calls, 2)
+	assert.NotContains(t, calls[0].Prompt, "VERIFICATION FAILED")
+	assert.True(t, strings.HasPrefix(calls[1].Prompt, "VERIFICATION FAILED"))
+	assert.Contains(t, calls[1].Prompt, "Command: go test ./...\nError: exit status 1\nOutput:\n--- FAIL: TestX")
+	assert.Len(t, verifier.VerifyCalls(), 2)
+	assert.Equal(t, 2, r.TaskIterations())
+}
+
+func TestRunner_TaskPhase_NeedsInput(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+	needsInput := executor.Result{Output: "<<<RALPHEX:NEEDS_INPUT>>>\nKeep the v1 endpoints?\n<<<RALPHEX:END>>>",
+		Signal: status.NeedsInput}
+
+	t.Run("answer passed to the next iteration", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{needsInput, {Output: "task done", Signal: status.Completed}})
+		var asked, notified []string
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+			AppConfig:  testAppConfig(t),
+			NeedsHuman: func(reason string) { notified = append(notified, reason) },
+			AnswerInput: func(_ context.Context, question string) (string, error) {
+				asked = append(asked, question)
+				return "remove them", nil
+			}}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Keep the v1 endpoints?"}, asked)
+		assert.Equal(t, []string{"agent needs input: Keep the v1 endpoints?"}, notified)
+		calls := claude.RunCalls()
+		require.Len(t, calls, 2)
+		assert.NotContains(t, calls[0].Prompt, "ANSWER TO YOUR QUESTION")
+		assert.Contains(t, calls[1].Prompt, "ANSWER TO YOUR QUESTION:\nThe previous iteration stopped with this question:\n"+
+			"Keep the v1 endpoints?\n\nThe user answered:\nremove them")
+		require.Len(t, log.LogAnswerCalls(), 1)
+		assert.Equal(t, "remove them", log.LogAnswerCalls()[0].Answer)
+	})
+
+	t.Run("nobody to answer", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{needsInput})
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil,
+			&status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.ErrorContains(t, err, "agent needs input, nobody to answer: Keep the v1 endpoints?")
+	})
+
+	t.Run("answer fails", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{needsInput})
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t),
+			AnswerInput: func(context.Context, string) (string, error) {
+				return "", errors.New("no answer within 10m0s")
+			}}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil,
+			&status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.ErrorContains(t, err, "answer to agent question: no answer within 10m0s")
+	})
+}
+
+func TestRunner_TaskPhase_ContinueSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	// run returns the sessions the claude calls were made in
+	run := func(t *testing.T, enabled bool, resume *processor.Checkpoint, results []executor.Result) (sessions []string,
+		log *mocks.LoggerMock) {
+		t.Helper()
+		claude := newMockExecutor(results)
+		next := claude.RunFunc
+		claude.RunFunc = func(ctx context.Context, prompt string) executor.Result {
+			sessions = append(sessions, executor.SessionFromContext(ctx))
+			return next(ctx, prompt)
+		}
+		appCfg := testAppConfig(t)
+		appCfg.ContinueSession = enabled
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+			AppConfig: appCfg, Resume: resume}
+		log = newMockLogger("progress.txt")
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+		return sessions, log
+	}
+
+	t.Run("previous session continued", func(t *testing.T) {
+		sessions, _ := run(t, true, nil, []executor.Result{{Output: "one", SessionID: "s-1"}, {Output: "two", SessionID: "s-2"},
+			{Output: "done", Signal: status.Completed}})
+		assert.Equal(t, []string{"", "s-1", "s-2"}, sessions)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		sessions, _ := run(t, false, nil, []executor.Result{{Output: "one", SessionID: "s-1"},
+			{Output: "done", Signal: status.Completed}})
+		assert.Equal(t, []string{"", ""}, sessions)
+	})
+
+	t.Run("session that can't be continued replaced", func(t *testing.T) {
+		sessions, log := run(t, true, nil, []executor.Result{{Output: "one", SessionID: "s-1"},
+			{Error: errors.New("no conversation found with session ID: s-1")}, {Output: "done", Signal: status.Completed}})
+		assert.Equal(t, []string{"", "s-1", ""}, sessions)
+		assert.True(t, printed(log, "can't continue agent session s-1"))
+	})
+
+	t.Run("session of the checkpoint continued", func(t *testing.T) {
+		cp := &processor.Checkpoint{Step: processor.StepTask, Iteration: 2, TaskIterations: 2, SessionID: "s-7"}
+		sessions, _ := run(t, true, cp, []executor.Result{{Output: "done", Signal: status.Completed}})
+		assert.Equal(t, []string{"s-7"}, sessions)
+	})
+}
+
+func TestRunner_TaskPhase_PlanEdited(t *testing.T) {
+	// run edits the plan after the first task iteration, as a person would between iterations, and returns
+	// the task iteration sections printed and the questions asked
+	run := func(t *testing.T, answers []string) (sections, asked []string, log *mocks.LoggerMock, err error) {
+		t.Helper()
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+		claude := &mocks.ExecutorMock{}
+		claude.RunFunc = func(context.Context, string) executor.Result {
+			if len(claude.RunCalls()) == 1 {
+				return executor.Result{Output: "working on it"}
+			}
+			require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1\n- [x] Task 2"), 0o600))
+			return executor.Result{Output: "done", Signal: status.Completed}
+		}
+		verifier := &mocks.VerifierMock{}
+		verifier.VerifyFunc = func(context.Context) verify.Report {
+			if len(verifier.VerifyCalls()) == 1 {
+				require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1\n- [ ] Task 2"), 0o600))
+			}
+			return verify.Report{}
+		}
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t)}
+		if answers != nil {
+			cfg.AnswerInput = func(_ context.Context, question string) (string, error) {
+				asked = append(asked, question)
+				if len(asked) > len(answers) {
+					return "", errors.New("no more answers")
+				}
+				return answers[len(asked)-1], nil
+			}
+		}
+		log = newMockLogger("progress.txt")
+		log.PrintSectionFunc = func(s status.Section) { sections = append(sections, s.Label) }
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		r.SetVerifier(verifier)
+		_, err = r.Run(context.Background())
+		return sections, asked, log, err
+	}
+
+	t.Run("reload", func(t *testing.T) {
+		sections, asked, log, err := run(t, []string{"Reload"})
+		require.NoError(t, err)
+		require.Len(t, asked, 1)
+		assert.Contains(t, asked[0], "plan file was edited during the run")
+		assert.True(t, printed(log, "was edited outside of the agent"))
+		assert.Equal(t, []string{"task iteration 1", "task iteration 2"}, sections)
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		sections, _, _, err := run(t, []string{"restart"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"task iteration 1", "task iteration 2"}, sections)
+	})
+
+	t.Run("restarts counted against max iterations", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+		claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			return executor.Result{Output: "working on it"}
+		}}
+		verifier := &mocks.VerifierMock{}
+		verifier.VerifyFunc = func(context.Context) verify.Report { // a person edits the plan after every iteration
+			content := fmt.Sprintf("# Plan\n- [ ] Task 1\n- [ ] Task %d", len(verifier.VerifyCalls())+1)
+			require.NoError(t, os.WriteFile(planFile, []byte(content), 0o600))
+			return verify.Report{}
+		}
+		var asked int
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 3, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t), AnswerInput: func(context.Context, string) (string, error) {
+				if asked++; asked > 3 { // restarts from the first iteration would go on forever
+					return "abort", nil
+				}
+				return "restart", nil
+			}}
+		var sections []string
+		log := newMockLogger("progress.txt")
+		log.PrintSectionFunc = func(s status.Section) { sections = append(sections, s.Label) }
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		r.SetVerifier(verifier)
+		_, err := r.Run(context.Background())
+		require.ErrorContains(t, err, "max iterations (3) reached without completion")
+		assert.Equal(t, 2, asked)
+		assert.Equal(t, []string{"task iteration 1", "task iteration 2", "task iteration 3"}, sections)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		sections, _, _, err := run(t, []string{"abort"})
+		require.ErrorContains(t, err, "plan file edited during the run, aborted")
+		assert.Equal(t, []string{"task iteration 1"}, sections)
+	})
+
+	t.Run("unknown answer asked again", func(t *testing.T) {
+		_, asked, log, err := run(t, []string{"maybe", "reload"})
+		require.NoError(t, err)
+		assert.Len(t, asked, 2)
+		assert.True(t, printed(log, `unknown answer "maybe"`))
+	})
 
-	require.NoError(t, err)
+	t.Run("nobody to ask", func(t *testing.T) {
+		sections, _, log, err := run(t, nil)
+		require.NoError(t, err)
+		assert.True(t, printed(log, "nobody to ask, continuing with the edited plan"))
+		assert.Equal(t, []string{"task iteration 1", "task iteration 2"}, sections)
+	})
 }
 
-func TestRunner_CodexDisabled_SkipsCodexPhase(t *testing.T) {
+func TestRunner_TaskPhase_PlanReload(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n\n### Task 1: docs\n- [ ] readme\n"), 0o600))
+	var prompts, plans []string
+	claude := &mocks.ExecutorMock{}
+	claude.RunFunc = func(_ context.Context, prompt string) executor.Result {
+		prompts = append(prompts, prompt)
+		content, err := os.ReadFile(planFile) //nolint:gosec // test file
+		require.NoError(t, err)
+		plans = append(plans, string(content))
+		if len(claude.RunCalls()) == 1 {
+			require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n\n### Task 1: docs\n- [x] readme\n"), 0o600))
+			return executor.Result{Output: "readme done"}
+		}
+		require.NoError(t, os.WriteFile(planFile, []byte(strings.ReplaceAll(string(content), "- [ ]", "- [x]")), 0o600))
+		return executor.Result{Output: "done", Signal: status.Completed}
+	}
+	verifier := &mocks.VerifierMock{}
+	verifier.VerifyFunc = func(context.Context) verify.Report {
+		if len(verifier.VerifyCalls()) == 1 { // a person edits the plan from the copy opened before the agent checked readme
+			require.NoError(t, os.WriteFile(planFile,
+				[]byte("# Plan\n\nKeep the docs short.\n\n### Task 1: docs\n- [ ] readme\n- [ ] faq\n"), 0o600))
+		}
+		return verify.Report{}
+	}
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+		AppConfig: testAppConfig(t)}
 	log := newMockLogger("progress.txt")
-	claude := newMockExecutor([]executor.Result{
-		{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop
-	})
-	codex := newMockExecutor(nil)
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetVerifier(verifier)
+	_, err := r.Run(context.Background())
+	require.NoError(t, err)
 
-	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: false, AppConfig: testAppConfig(t)}
-	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	require.Len(t, prompts, 2)
+	assert.NotContains(t, prompts[0], "PLAN UPDATED")
+	assert.Contains(t, prompts[1], "PLAN UPDATED:\nA person edited the plan file since your previous iteration.")
+	assert.Contains(t, prompts[1], "Added lines:\n+ Keep the docs short.\n+ - [ ] faq\n")
+	assert.NotContains(t, prompts[1], "Removed lines")
+	assert.Equal(t, "# Plan\n\nKeep the docs short.\n\n### Task 1: docs\n- [x] readme\n- [ ] faq\n", plans[1],
+		"readme checked by the agent is kept checked")
+	assert.True(t, printed(log, "1 items checked by the agent were unchecked in the edit, kept them checked: readme"))
+}
+
+func TestRunner_TaskPhase_Notes(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile, inbox := filepath.Join(tmpDir, "plan.md"), filepath.Join(tmpDir, "inbox")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
 
+	var r *processor.Runner
+	var prompts []string
+	claude := &mocks.ExecutorMock{}
+	claude.RunFunc = func(_ context.Context, prompt string) executor.Result {
+		prompts = append(prompts, prompt)
+		switch len(prompts) {
+		case 1: // notes sent while the agent works
+			require.NoError(t, os.WriteFile(inbox, []byte("please also rename Foo to Bar\n\n  skip task 4\n"), 0o600))
+			r.AddNote("keep the old name as an alias")
+			return executor.Result{Output: "working"}
+		case 2:
+			return executor.Result{Output: "working"}
+		}
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+		return executor.Result{Output: "done", Signal: status.Completed}
+	}
+	log := newMockLogger("progress.txt")
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+		InboxFile: inbox, AppConfig: testAppConfig(t)}
+	r = processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
 	require.NoError(t, err)
-	assert.Empty(t, codex.RunCalls(), "codex should not be called when disabled")
+
+	require.Len(t, prompts, 3)
+	assert.NotContains(t, prompts[0], "NOTES FROM THE USER")
+	assert.Contains(t, prompts[1], "NOTES FROM THE USER:")
+	assert.Contains(t, prompts[1], "- keep the old name as an alias\n- please also rename Foo to Bar\n- skip task 4\n")
+	assert.NotContains(t, prompts[2], "NOTES FROM THE USER", "notes are sent once")
+	assert.NoFileExists(t, inbox, "the inbox is consumed")
+	assert.NoFileExists(t, inbox+".read")
+	assert.True(t, printed(log, "note from user: skip task 4"))
 }
 
-func TestRunner_RunTasksOnly_Success(t *testing.T) {
+func TestRunner_TaskPhase_VerificationPassClearsFeedback(t *testing.T) {
 	tmpDir := t.TempDir()
 	planFile := filepath.Join(tmpDir, "plan.md")
-	// all tasks complete - no [ ] checkboxes
-	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
 
 	log := newMockLogger("progress.txt")
-	claude := newMockExecutor([]executor.Result{
-		{Output: "task done", Signal: status.Completed}, // task phase completes
-	})
-	codex := newMockExecutor(nil)
+	claude := newMockExecutor([]executor.Result{{Output: "one"}, {Output: "two"}, {Output: "three"}})
+	reports := []verify.Report{
+		{Results: []verify.Result{{Command: "make test", Err: errors.New("exit status 2")}}},
+		{Results: []verify.Result{{Command: "make test"}}},
+		{Results: []verify.Result{{Command: "make test"}}},
+	}
+	verifier := &mocks.VerifierMock{}
+	verifier.VerifyFunc = func(context.Context) verify.Report { return reports[len(verifier.VerifyCalls())-1] }
+
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 3, IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetVerifier(verifier)
+	_, err := r.Run(context.Background())
+	require.ErrorContains(t, err, "max iterations")
+
+	calls := claude.RunCalls()
+	require.Len(t, calls, 3)
+	assert.NotContains(t, calls[0].Prompt, "VERIFICATION FAILED")
+	assert.Contains(t, calls[1].Prompt, "VERIFICATION FAILED")
+	assert.NotContains(t, calls[2].Prompt, "VERIFICATION FAILED", "feedback is dropped once verification passes")
+}
 
-	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 50, AppConfig: testAppConfig(t)}
-	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+func TestRunner_TaskPhase_Services(t *testing.T) {
+	newRunner := func(t *testing.T, services *mocks.ServicesMock) (*processor.Runner, *mocks.ExecutorMock) {
+		t.Helper()
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+		claude := newMockExecutor([]executor.Result{{Output: "done", Signal: status.Completed}})
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 3, IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		r.SetServices(services)
+		return r, claude
+	}
 
-	require.NoError(t, err)
-	assert.Empty(t, codex.RunCalls(), "codex should not be called in tasks-only mode")
-	assert.Len(t, claude.RunCalls(), 1)
+	t.Run("started before tasks and stopped after", func(t *testing.T) {
+		var order []string
+		services := &mocks.ServicesMock{
+			UpFunc: func(context.Context) ([]string, error) {
+				order = append(order, "up")
+				return []string{"POSTGRES_HOST=127.0.0.1"}, nil
+			},
+			DownFunc: func(ctx context.Context) error {
+				order = append(order, "down")
+				return ctx.Err()
+			},
+		}
+		r, claude := newRunner(t, services)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		claude.RunFunc = func(context.Context, string) executor.Result {
+			order = append(order, "task")
+			return executor.Result{Output: "done", Signal: status.Completed}
+		}
+		_, err := r.Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"up", "task", "down"}, order)
+	})
+
+	t.Run("start failure stops the run", func(t *testing.T) {
+		services := &mocks.ServicesMock{
+			UpFunc:   func(context.Context) ([]string, error) { return nil, errors.New("port is already allocated") },
+			DownFunc: func(context.Context) error { return nil },
+		}
+		r, claude := newRunner(t, services)
+		_, err := r.Run(context.Background())
+		require.ErrorContains(t, err, "services: port is already allocated")
+		assert.Empty(t, claude.RunCalls())
+		assert.Len(t, services.DownCalls(), 1, "partially started services are removed")
+	})
 }
 
 func TestRunner_RunTasksOnly_NoPlanFile(t *testing.T) {
@@ -226,7 +1534,7 @@ func TestRunner_RunTasksOnly_NoPlanFile(t *testing.T) {
 	codex := newMockExecutor(nil)
 
 	r := processor.NewWithExecutors(processor.Config{Mode: processor.ModeTasksOnly}, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "plan file required")
@@ -246,7 +1554,7 @@ func TestRunner_RunTasksOnly_TaskPhaseError(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "FAILED signal")
@@ -271,7 +1579,7 @@ func TestRunner_RunTasksOnly_NoReviews(t *testing.T) {
 		AppConfig:     testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	// verify no review or codex phases ran - only task phase
@@ -293,12 +1601,71 @@ func TestRunner_TaskPhase_FailedSignal(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "FAILED signal")
 }
 
+func TestRunner_TaskPhase_RollbackOnFailure(t *testing.T) {
+	run := func(t *testing.T, mode string) *mocks.GitCheckerMock {
+		t.Helper()
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1\n- [ ] Task 2"), 0o600))
+		// the first iteration completes task 1, task 2 fails and so does its retry
+		claude := &mocks.ExecutorMock{}
+		claude.RunFunc = func(context.Context, string) executor.Result {
+			if len(claude.RunCalls()) == 1 {
+				require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1\n- [ ] Task 2"), 0o600))
+				return executor.Result{Output: "task 1 done"}
+			}
+			return executor.Result{Output: "error", Signal: status.Failed}
+		}
+		snapshots := 0
+		gitMock := &mocks.GitCheckerMock{
+			HeadHashFunc:     func() (string, error) { return "abc123", nil },
+			DiffSinceFunc:    func(string) (string, error) { return "", nil },
+			ChangedFilesFunc: func(string) ([]string, error) { return nil, nil },
+			SnapshotFunc: func() (git.Snapshot, error) {
+				snapshots++
+				return git.Snapshot{Head: fmt.Sprintf("head%d", snapshots), State: fmt.Sprintf("state%d", snapshots)}, nil
+			},
+			RestoreSnapshotFunc: func(git.Snapshot) error { return nil },
+		}
+		appCfg := testAppConfig(t)
+		appCfg.RollbackOnFailure = mode
+		appCfg.TaskRetryCount = 1
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		r.SetGitChecker(gitMock)
+
+		_, err := r.Run(context.Background())
+		require.ErrorContains(t, err, "FAILED signal")
+		return gitMock
+	}
+
+	t.Run("run", func(t *testing.T) {
+		gitMock := run(t, config.RollbackRun)
+		assert.Len(t, gitMock.SnapshotCalls(), 1, "saved once, before the run")
+		require.Len(t, gitMock.RestoreSnapshotCalls(), 1)
+		assert.Equal(t, git.Snapshot{Head: "head1", State: "state1"}, gitMock.RestoreSnapshotCalls()[0].Snap)
+	})
+
+	t.Run("iteration", func(t *testing.T) {
+		gitMock := run(t, config.RollbackIteration)
+		assert.Len(t, gitMock.SnapshotCalls(), 2, "saved before each iteration, not before the retry")
+		require.Len(t, gitMock.RestoreSnapshotCalls(), 1)
+		assert.Equal(t, git.Snapshot{Head: "head2", State: "state2"}, gitMock.RestoreSnapshotCalls()[0].Snap)
+	})
+
+	t.Run("off", func(t *testing.T) {
+		gitMock := run(t, config.RollbackOff)
+		assert.Empty(t, gitMock.SnapshotCalls())
+		assert.Empty(t, gitMock.RestoreSnapshotCalls())
+	})
+}
+
 func TestRunner_TaskPhase_MaxIterations(t *testing.T) {
 	tmpDir := t.TempDir()
 	planFile := filepath.Join(tmpDir, "plan.md")
@@ -314,12 +1681,428 @@ func TestRunner_TaskPhase_MaxIterations(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 3, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "max iterations")
 }
 
+func TestRunner_TaskPhase_NoProgress(t *testing.T) {
+	run := func(t *testing.T, stallIterations int, touchPlan bool) (*mocks.ExecutorMock, error) {
+		t.Helper()
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+
+		iteration := 0
+		claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			iteration++
+			if touchPlan {
+				content := fmt.Sprintf("# Plan\n- [ ] Task 1\n<!-- %d -->", iteration)
+				require.NoError(t, os.WriteFile(planFile, []byte(content), 0o600))
+			}
+			return executor.Result{Output: "looked at the task, working on it"}
+		}}
+
+		appCfg := testAppConfig(t)
+		appCfg.StallIterations = stallIterations
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil,
+			&status.PhaseHolder{})
+		_, err := r.Run(context.Background())
+		return claude, err
+	}
+
+	t.Run("stalled", func(t *testing.T) {
+		claude, err := run(t, 3, false)
+		require.ErrorContains(t, err, "no progress in 3 task iterations in a row")
+		assert.Len(t, claude.RunCalls(), 3)
+	})
+
+	t.Run("plan changes", func(t *testing.T) {
+		claude, err := run(t, 3, true)
+		require.ErrorContains(t, err, "max iterations")
+		assert.Len(t, claude.RunCalls(), 10)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		claude, err := run(t, 0, false)
+		require.ErrorContains(t, err, "max iterations")
+		assert.Len(t, claude.RunCalls(), 10)
+	})
+}
+
+func TestRunner_TaskPhase_Pause(t *testing.T) {
+	run := func(t *testing.T, ctx context.Context) (*mocks.ExecutorMock, *processor.Pause, <-chan error) {
+		t.Helper()
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+		claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+			return executor.Result{Signal: status.Completed}
+		}}
+		pause := processor.NewPause()
+		pause.Request()
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+			Pause: pause, AppConfig: testAppConfig(t)}
+		log := newMockLogger("progress.txt")
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := r.Run(ctx)
+			done <- err
+		}()
+		require.Eventually(t, func() bool {
+			for _, c := range log.PrintCalls() {
+				if strings.HasPrefix(c.Format, "[PAUSED]") {
+					return true
+				}
+			}
+			return false
+		}, time.Second, 5*time.Millisecond)
+		assert.Empty(t, claude.RunCalls(), "no iteration runs while paused")
+		return claude, pause, done
+	}
+
+	t.Run("continued", func(t *testing.T) {
+		claude, pause, done := run(t, context.Background())
+		pause.Continue()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("run didn't continue after pause")
+		}
+		assert.Len(t, claude.RunCalls(), 1)
+	})
+
+	t.Run("canceled while paused", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		claude, _, done := run(t, ctx)
+		cancel()
+		select {
+		case err := <-done:
+			require.ErrorIs(t, err, context.Canceled)
+		case <-time.After(5 * time.Second):
+			t.Fatal("run didn't stop on cancel while paused")
+		}
+		assert.Empty(t, claude.RunCalls())
+	})
+}
+
+func TestRunner_TaskPhase_DependencyPolicy(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.21\n"), 0o600))
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+
+	iteration := 0
+	claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		iteration++
+		if iteration == 1 { // completes the task pulling in a module outside the allowlist
+			require.NoError(t, os.WriteFile("go.mod",
+				[]byte("module example.com/app\n\ngo 1.21\n\nrequire github.com/random/lib v1.0.0\n"), 0o600))
+		} else {
+			require.NoError(t, os.WriteFile("go.mod",
+				[]byte("module example.com/app\n\ngo 1.21\n\nrequire golang.org/x/sync v0.1.0\n"), 0o600))
+		}
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+		return executor.Result{Signal: status.Completed}
+	}}
+	appCfg := testAppConfig(t)
+	appCfg.DepsAllow = []string{"golang.org/x/..."}
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+		AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+
+	_, err := r.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, claude.RunCalls(), 2)
+	assert.NotContains(t, claude.RunCalls()[0].Prompt, "dependency policy")
+	assert.Contains(t, claude.RunCalls()[1].Prompt, "dependency policy violated, these modules may not be added to go.mod: github.com/random/lib.")
+}
+
+func TestRunner_TaskPhase_BudgetExtension(t *testing.T) {
+	const planContent = "# Plan\n\n### Task 1: api\n- [ ] a\n- [ ] b\n- [ ] c\n\n### Task 2: docs\n- [ ] d\n- [ ] e\n- [ ] f\n"
+	// ticks one item per iteration, signals completion with the last one
+	tickingClaude := func(t *testing.T, planFile string) *mocks.ExecutorMock {
+		return &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			content, err := os.ReadFile(planFile) //nolint:gosec // test file
+			require.NoError(t, err)
+			updated := strings.Replace(string(content), "- [ ]", "- [x]", 1)
+			require.NoError(t, os.WriteFile(planFile, []byte(updated), 0o600))
+			if !strings.Contains(updated, "- [ ]") {
+				return executor.Result{Signal: status.Completed}
+			}
+			return executor.Result{}
+		}}
+	}
+	tests := []struct {
+		name          string
+		factor        float64
+		maxExtensions int
+		wantErr       string
+		wantCalls     int
+	}{
+		{name: "extended", factor: 2, maxExtensions: 1, wantCalls: 6},
+		{name: "disabled", factor: 0, maxExtensions: 1, wantErr: "max iterations (3) reached without completion", wantCalls: 3},
+		{name: "extensions used up", factor: 1.5, maxExtensions: 1, wantErr: "max iterations (5) reached without completion",
+			wantCalls: 5},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			planFile := filepath.Join(t.TempDir(), "plan.md")
+			require.NoError(t, os.WriteFile(planFile, []byte(planContent), 0o600))
+			claude := tickingClaude(t, planFile)
+			appCfg := testAppConfig(t)
+			appCfg.BudgetExtensionFactor, appCfg.BudgetMaxExtensions = tc.factor, tc.maxExtensions
+			cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 3, IterationDelayMs: 1,
+				AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+
+			_, err := r.Run(context.Background())
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Len(t, claude.RunCalls(), tc.wantCalls)
+		})
+	}
+}
+
+func TestRunner_TaskPhase_TaskSelection(t *testing.T) {
+	const planContent = "# Plan\n\n### Task 1: setup\n- [x] done\n\n### Task 2: api\n- [ ] handlers\n\n" +
+		"### Task 3: docs\n- [ ] readme\n\n### Task 4: cleanup\n- [ ] dead code\n"
+
+	t.Run("runs only selected tasks", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte(planContent), 0o600))
+		claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			content, err := os.ReadFile(planFile) //nolint:gosec // test file
+			require.NoError(t, err)
+			// completes task 2, tasks 3 and 4 stay unchecked
+			updated := strings.Replace(string(content), "- [ ] handlers", "- [x] handlers", 1)
+			require.NoError(t, os.WriteFile(planFile, []byte(updated), 0o600))
+			return executor.Result{Signal: status.Completed}
+		}}
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t), StartTask: "2", OnlyTasks: []string{"api", "cleanup"}}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+
+		_, err := r.Run(context.Background())
+		require.ErrorContains(t, err, "no progress in 3 task iterations", "task 4 is selected and never completed")
+		prompt := claude.RunCalls()[0].Prompt
+		assert.Contains(t, prompt, "TASK SELECTION for this run")
+		assert.Contains(t, prompt, "- ### Task 2: api (line 6)\n- ### Task 4: cleanup (line 12)\n")
+		assert.NotContains(t, prompt, "Task 3: docs")
+	})
+
+	t.Run("completes with unselected tasks left", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte(planContent), 0o600))
+		claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			content, err := os.ReadFile(planFile) //nolint:gosec // test file
+			require.NoError(t, err)
+			updated := strings.Replace(string(content), "- [ ] readme", "- [x] readme", 1)
+			require.NoError(t, os.WriteFile(planFile, []byte(updated), 0o600))
+			return executor.Result{Signal: status.Completed}
+		}}
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t), OnlyTasks: []string{"(?i)README"}}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, claude.RunCalls(), 1)
+	})
+
+	t.Run("selected tasks already completed", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte(planContent), 0o600))
+		claude := newMockExecutor(nil)
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t), OnlyTasks: []string{"1"}}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, claude.RunCalls())
+	})
+
+	t.Run("no task matches", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte(planContent), 0o600))
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t), StartTask: "9"}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), newMockExecutor(nil), newMockExecutor(nil), nil,
+			&status.PhaseHolder{})
+
+		_, err := r.Run(context.Background())
+		require.ErrorContains(t, err, `task selection: no task matches "9"`)
+	})
+}
+
+func TestRunner_CompletedPlan(t *testing.T) {
+	const donePlan = "# Plan\n\n### Task 1: docs\n- [x] readme\n\n### Task 2: code\n- [x] handler\n"
+	tests := []struct {
+		name      string
+		action    string
+		plan      string
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "review by default", plan: donePlan},
+		{name: "review", action: config.CompletedPlanReview, plan: donePlan},
+		{name: "exit", action: config.CompletedPlanExit, plan: donePlan, wantErr: processor.ErrNothingToDo},
+		{name: "run", action: config.CompletedPlanRun, plan: donePlan, wantCalls: 1},
+		{name: "exit with pending task", action: config.CompletedPlanExit,
+			plan: "# Plan\n\n### Task 1: docs\n- [x] readme\n- [ ] changelog\n", wantCalls: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			planFile := filepath.Join(t.TempDir(), "plan.md")
+			require.NoError(t, os.WriteFile(planFile, []byte(tc.plan), 0o600))
+			claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+				content, err := os.ReadFile(planFile) //nolint:gosec // test file
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(planFile, []byte(strings.ReplaceAll(string(content), "- [ ]", "- [x]")), 0o600))
+				return executor.Result{Signal: status.Completed}
+			}}
+			appCfg := testAppConfig(t)
+			appCfg.CompletedPlan = tc.action
+			log := newMockLogger("progress.txt")
+			cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+				AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+
+			_, err := r.Run(context.Background())
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				assert.True(t, printed(log, "nothing to do"))
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Len(t, claude.RunCalls(), tc.wantCalls)
+		})
+	}
+}
+
+func TestRunner_Run_Report(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+
+	iteration := 0
+	claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		iteration++
+		if iteration == 1 {
+			return executor.Result{Output: "working on it"}
+		}
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+		return executor.Result{Output: "done", Signal: status.Completed}
+	}}
+	gitMock := &mocks.GitCheckerMock{
+		HeadHashFunc:     func() (string, error) { return "abc123", nil },
+		DiffSinceFunc:    func(string) (string, error) { return "", nil },
+		ChangedFilesFunc: func(string) ([]string, error) { return []string{"a.go", "b.go"}, nil },
+	}
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+		AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil,
+		&status.PhaseHolder{})
+	r.SetGitChecker(gitMock)
+
+	report, err := r.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, processor.ModeTasksOnly, report.Mode)
+	require.Len(t, report.Steps, 1)
+	assert.Equal(t, processor.StepTask, report.Steps[0].Step)
+	assert.Equal(t, 2, report.Steps[0].Iterations)
+	assert.Positive(t, report.Duration)
+	assert.GreaterOrEqual(t, report.Duration, report.Steps[0].Duration)
+	assert.Equal(t, []string{status.Completed}, report.Signals)
+	assert.Zero(t, report.Findings)
+	assert.Equal(t, []string{"a.go", "b.go"}, report.Files)
+	assert.Equal(t, cfg.AppConfig.PromptsHash(), report.Prompts)
+	assert.Equal(t, cfg.AppConfig.PromptVersions(), report.PromptVersions)
+	require.NotNil(t, report.Usage)
+	assert.Equal(t, 2, report.Usage.Calls)
+	assert.True(t, report.Usage.Estimated, "mock executor reports no usage")
+
+	t.Run("failed run", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{{Output: "stuck", Signal: status.Failed}})
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil,
+			&status.PhaseHolder{})
+		report, err := r.Run(context.Background())
+		require.Error(t, err)
+		require.Len(t, report.Steps, 1)
+		assert.Equal(t, 1, report.Steps[0].Iterations)
+		assert.Equal(t, []string{status.Failed}, report.Signals)
+		assert.Empty(t, report.Files, "no git checker, no files")
+	})
+}
+
+func TestRunner_Events(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+	claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+		return executor.Result{Output: "done", Signal: status.Completed}
+	}}
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+		AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil,
+		&status.PhaseHolder{})
+	var events []processor.Event
+	r.SetEventHandler(func(ev processor.Event) { events = append(events, ev) })
+
+	report, err := r.Run(context.Background())
+	require.NoError(t, err)
+
+	types := make([]processor.EventType, 0, len(events))
+	for _, ev := range events {
+		assert.False(t, ev.Time.IsZero())
+		types = append(types, ev.Type)
+	}
+	assert.Equal(t, []processor.EventType{processor.EventPhaseStarted, processor.EventIterationStarted,
+		processor.EventSignalDetected, processor.EventPhaseCompleted, processor.EventRunFinished}, types)
+	assert.Equal(t, status.PhaseTask, events[0].Phase)
+	assert.Equal(t, processor.StepTask, events[1].Step)
+	assert.Equal(t, 1, events[1].Iteration)
+	assert.Equal(t, "claude", events[2].Executor)
+	assert.Equal(t, status.Completed, events[2].Signal)
+	require.NoError(t, events[3].Err)
+	require.NotNil(t, events[4].Report)
+	assert.Equal(t, report, *events[4].Report)
+}
+
+func TestRunner_TaskPhase_TaskBudget(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n### Task 1: first\n- [ ] one\n### Task 2: second\n- [ ] two\n"), 0o600))
+
+	iteration := 0
+	claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		iteration++
+		if iteration == 1 { // the first task is done at once, the second one gets stuck
+			content := "# Plan\n### Task 1: first\n- [x] one\n### Task 2: second\n- [ ] two\n"
+			require.NoError(t, os.WriteFile(planFile, []byte(content), 0o600))
+		}
+		return executor.Result{Output: fmt.Sprintf("iteration %d, trying another approach", iteration)}
+	}}
+
+	appCfg := testAppConfig(t)
+	appCfg.TaskMaxIterations = 3
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1,
+		AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil,
+		&status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+	require.ErrorContains(t, err, `task "Task 2: second" still incomplete after 3 iterations`)
+	assert.Len(t, claude.RunCalls(), 4, "one iteration of the first task and three of the second")
+}
+
 func TestRunner_TaskPhase_ContextCanceled(t *testing.T) {
 	tmpDir := t.TempDir()
 	planFile := filepath.Join(tmpDir, "plan.md")
@@ -334,7 +2117,7 @@ func TestRunner_TaskPhase_ContextCanceled(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(ctx)
+	_, err := r.Run(ctx)
 
 	require.Error(t, err)
 	assert.ErrorIs(t, err, context.Canceled)
@@ -349,7 +2132,7 @@ func TestRunner_ClaudeReview_FailedSignal(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "FAILED signal")
@@ -367,7 +2150,7 @@ func TestRunner_CodexPhase_Error(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "codex")
@@ -387,7 +2170,7 @@ func TestRunner_ClaudeExecution_Error(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "claude execution")
@@ -559,7 +2342,7 @@ func TestRunner_TaskRetryCount_UsedCorrectly(t *testing.T) {
 		AppConfig:        testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "FAILED signal")
@@ -599,7 +2382,7 @@ func TestRunner_RunPlan_Success(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, claude.RunCalls(), 1)
@@ -629,7 +2412,7 @@ func TestRunner_RunPlan_WithQuestion(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, claude.RunCalls(), 2)
@@ -647,7 +2430,7 @@ func TestRunner_RunPlan_NoPlanDescription(t *testing.T) {
 	cfg := processor.Config{Mode: processor.ModePlan, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "plan description required")
@@ -661,7 +2444,7 @@ func TestRunner_RunPlan_NoInputCollector(t *testing.T) {
 	cfg := processor.Config{Mode: processor.ModePlan, PlanDescription: "test", AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	// don't set input collector
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "input collector required")
@@ -684,12 +2467,41 @@ func TestRunner_RunPlan_FailedSignal(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "FAILED signal")
 }
 
+func TestRunner_RunPlan_FromSpec(t *testing.T) {
+	log := newMockLogger("progress-plan-spec.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: "<<<RALPHEX:QUESTION>>>\n{\"question\": \"Which backend?\", \"options\": [\"A\", \"B\"]}\n<<<RALPHEX:END>>>"},
+		{Output: "<<<RALPHEX:PLAN_DRAFT>>>\n# Plan\n### Task 1: do it\n- [ ] step\n<<<RALPHEX:END>>>"},
+		{Output: "plan written", Signal: status.PlanReady},
+	})
+
+	cfg := processor.Config{
+		Mode:             processor.ModePlan,
+		PlanDescription:  "add rate limiting",
+		PlanSpec:         "spec.md",
+		MaxIterations:    50,
+		IterationDelayMs: 1,
+		AppConfig:        testAppConfig(t),
+	}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background()) // no input collector, nobody is asked
+	require.NoError(t, err)
+
+	calls := claude.RunCalls()
+	require.Len(t, calls, 3)
+	assert.Contains(t, calls[0].Prompt, "The request above is the content of the spec file spec.md")
+	require.Len(t, log.LogAnswerCalls(), 1)
+	assert.Contains(t, log.LogAnswerCalls()[0].Answer, "no one to ask")
+	require.Len(t, log.LogDraftReviewCalls(), 1)
+	assert.Equal(t, "accept", log.LogDraftReviewCalls()[0].Action)
+}
+
 func TestRunner_RunPlan_MaxIterations(t *testing.T) {
 	log := newMockLogger("progress-plan.txt")
 	claude := newMockExecutor([]executor.Result{
@@ -712,7 +2524,7 @@ func TestRunner_RunPlan_MaxIterations(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "max plan iterations")
@@ -736,7 +2548,7 @@ func TestRunner_RunPlan_ContextCanceled(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(ctx)
+	_, err := r.Run(ctx)
 
 	require.Error(t, err)
 	assert.ErrorIs(t, err, context.Canceled)
@@ -759,7 +2571,7 @@ func TestRunner_RunPlan_ClaudeError(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "claude execution")
@@ -790,7 +2602,7 @@ func TestRunner_RunPlan_InputCollectorError(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "collect answer")
@@ -903,7 +2715,7 @@ func TestRunner_ErrorPatternMatch_ClaudeInTaskPhase(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	var patternErr *executor.PatternMatchError
@@ -937,7 +2749,7 @@ func TestRunner_ErrorPatternMatch_CodexInReviewPhase(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	var patternErr *executor.PatternMatchError
@@ -965,7 +2777,7 @@ func TestRunner_ErrorPatternMatch_ClaudeInReviewLoop(t *testing.T) {
 
 	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, CodexEnabled: false, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	var patternErr *executor.PatternMatchError
@@ -991,7 +2803,7 @@ func TestRunner_ErrorPatternMatch_ClaudeInPlanCreation(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	var patternErr *executor.PatternMatchError
@@ -1062,7 +2874,7 @@ This is a test plan.
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, claude.RunCalls(), 2)
@@ -1108,7 +2920,7 @@ I’m checking for an existing matching plan file first.`
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, claude.RunCalls(), 2)
@@ -1149,7 +2961,7 @@ func TestRunner_RunPlan_TranscriptParsesAssistantQuestionOnly(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, claude.RunCalls(), 2)
@@ -1197,7 +3009,7 @@ func TestRunner_RunPlan_PlanDraft_ReviseFlow(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, claude.RunCalls(), 3)
@@ -1238,7 +3050,7 @@ func TestRunner_RunPlan_PlanDraft_RejectFlow(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	require.ErrorIs(t, err, processor.ErrUserRejectedPlan)
@@ -1273,7 +3085,7 @@ func TestRunner_RunPlan_PlanDraft_AskDraftReviewError(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "collect draft review")
@@ -1302,7 +3114,7 @@ This content has no END marker`
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	// should log warning but continue
@@ -1351,7 +3163,7 @@ func TestRunner_RunPlan_PlanDraft_WithQuestionThenDraft(t *testing.T) {
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, claude.RunCalls(), 3)
@@ -1383,7 +3195,7 @@ func TestRunner_Finalize_RunsWhenEnabled(t *testing.T) {
 		AppConfig:       testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	// verify finalize step ran (5 claude calls total)
@@ -1423,7 +3235,7 @@ func TestRunner_Finalize_SkippedWhenDisabled(t *testing.T) {
 		AppConfig:       testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	// verify finalize step did NOT run (only 4 claude calls)
@@ -1454,7 +3266,7 @@ func TestRunner_Finalize_FailureDoesNotBlockSuccess(t *testing.T) {
 		AppConfig:       testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	// run should succeed despite finalize failure
 	require.NoError(t, err)
@@ -1494,7 +3306,7 @@ func TestRunner_Finalize_FailedSignalDoesNotBlockSuccess(t *testing.T) {
 		AppConfig:       testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	// run should succeed despite finalize FAILED signal
 	require.NoError(t, err)
@@ -1528,7 +3340,7 @@ func TestRunner_Finalize_RunsInReviewOnlyMode(t *testing.T) {
 		AppConfig:       testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	// verify finalize ran (4 claude calls total)
@@ -1551,7 +3363,7 @@ func TestRunner_Finalize_RunsInCodexOnlyMode(t *testing.T) {
 		AppConfig:       testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	// verify finalize ran (2 claude calls total)
@@ -1633,7 +3445,7 @@ func TestRunner_CodexAndPostReview_PipelineOrder(t *testing.T) {
 				AppConfig:       testAppConfig(t),
 			}
 			r := processor.NewWithExecutors(cfg, log, claude, codex, nil, holder)
-			err := r.Run(context.Background())
+			_, err := r.Run(context.Background())
 
 			require.NoError(t, err)
 			assert.Len(t, claude.RunCalls(), tc.expClaude)
@@ -1661,7 +3473,7 @@ func TestRunner_Finalize_ContextCancellationPropagates(t *testing.T) {
 		AppConfig:       testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	// run should fail with context canceled error
 	require.Error(t, err)
@@ -1689,7 +3501,7 @@ func TestRunner_ExternalReviewTool_CodexEnabled(t *testing.T) {
 		AppConfig:     appCfg,
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, codex.RunCalls(), 1, "codex should be called when external_review_tool=codex")
@@ -1712,7 +3524,7 @@ func TestRunner_ExternalReviewTool_None(t *testing.T) {
 		AppConfig:     appCfg,
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Empty(t, codex.RunCalls(), "codex should not be called when external_review_tool=none")
@@ -1736,7 +3548,7 @@ func TestRunner_ExternalReviewTool_BackwardCompat_CodexDisabled(t *testing.T) {
 		AppConfig:     appCfg,
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Empty(t, codex.RunCalls(), "codex should not be called when CodexEnabled=false (backward compat)")
@@ -1781,11 +3593,12 @@ func TestRunner_ExternalReviewTool_Custom_Success(t *testing.T) {
 		AppConfig:     appCfg,
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, customExec, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Empty(t, codex.RunCalls(), "codex should not be called when external_review_tool=custom")
 	assert.Len(t, claude.RunCalls(), 2, "claude should be called for evaluation and post-review")
+	assert.Equal(t, []processor.Finding{{File: "foo.go", Message: "found issue in"}}, r.Findings())
 }
 
 func TestRunner_ExternalReviewTool_Custom_NotConfigured(t *testing.T) {
@@ -1805,12 +3618,39 @@ func TestRunner_ExternalReviewTool_Custom_NotConfigured(t *testing.T) {
 	}
 	// no custom executor passed
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "custom review script not configured")
 }
 
+func TestRunner_ExternalReviewTool_Plugin(t *testing.T) {
+	claude := newMockExecutor([]executor.Result{
+		{Output: "done", Signal: processor.SignalCodexDone},         // plugin review evaluation
+		{Output: "review done", Signal: processor.SignalReviewDone}, // post-codex review loop
+	})
+	codex := newMockExecutor(nil)
+	reviewPlugin := newMockExecutor([]executor.Result{{Output: "found issue in foo.go:10"}})
+
+	appCfg := testAppConfig(t)
+	appCfg.ExternalReviewTool = "plugin:org-review"
+	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg,
+		PluginExecutors: map[string]processor.Executor{"org-review": reviewPlugin}}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Empty(t, codex.RunCalls())
+	require.Len(t, reviewPlugin.RunCalls(), 1)
+	assert.Contains(t, claude.RunCalls()[0].Prompt, "found issue in foo.go:10")
+	assert.Equal(t, []processor.Finding{{File: "foo.go", Message: "found issue in"}}, r.Findings())
+
+	appCfg.ExternalReviewTool = "plugin:missing"
+	r = processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), newMockExecutor(nil), codex, nil, &status.PhaseHolder{})
+	_, err = r.Run(context.Background())
+	require.ErrorContains(t, err, `plugin "missing" not configured or provides no executor`)
+}
+
 // mockCustomRunnerImpl is a mock implementation of executor.CustomRunner for testing.
 type mockCustomRunnerImpl struct {
 	results []executor.Result
@@ -1839,6 +3679,8 @@ func TestRunner_ReviewLoop_NoCommitExit(t *testing.T) {
 
 	// mock git checker returns same hash both times (no commits made)
 	gitMock := &mocks.GitCheckerMock{
+		PrepareDiffFunc:  func(string, bool) (git.DiffReadiness, error) { return git.DiffReadiness{}, nil },
+		ChangedFilesFunc: func(string) ([]string, error) { return nil, nil },
 		HeadHashFunc: func() (string, error) {
 			return "abc123def456abc123def456abc123def456abcd", nil
 		},
@@ -1847,7 +3689,7 @@ func TestRunner_ReviewLoop_NoCommitExit(t *testing.T) {
 	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, CodexEnabled: false, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetGitChecker(gitMock)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, claude.RunCalls(), 3)
@@ -1885,6 +3727,8 @@ func TestRunner_ReviewLoop_CommitDetected_ContinuesLoop(t *testing.T) {
 	}
 	hashIdx := 0
 	gitMock := &mocks.GitCheckerMock{
+		PrepareDiffFunc:  func(string, bool) (git.DiffReadiness, error) { return git.DiffReadiness{}, nil },
+		ChangedFilesFunc: func(string) ([]string, error) { return nil, nil },
 		HeadHashFunc: func() (string, error) {
 			require.Less(t, hashIdx, len(hashes), "unexpected extra HeadHash call #%d", hashIdx)
 			h := hashes[hashIdx]
@@ -1896,13 +3740,88 @@ func TestRunner_ReviewLoop_CommitDetected_ContinuesLoop(t *testing.T) {
 	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, CodexEnabled: false, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetGitChecker(gitMock)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	assert.Len(t, claude.RunCalls(), 4)
 	assert.Len(t, gitMock.HeadHashCalls(), 4, "expected exactly 4 HeadHash calls")
 }
 
+func TestRunner_ShowDiff(t *testing.T) {
+	const head = "aaaa00000000000000000000000000000000aaaa"
+	// review mode: first review, pre-codex loop and post-codex loop exit after one iteration each
+	newClaude := func() *mocks.ExecutorMock {
+		return newMockExecutor([]executor.Result{
+			{Output: "review done", Signal: status.ReviewDone},
+			{Output: "review done", Signal: status.ReviewDone},
+			{Output: "review done", Signal: status.ReviewDone},
+		})
+	}
+	newGit := func(diff string) *mocks.GitCheckerMock {
+		return &mocks.GitCheckerMock{
+			PrepareDiffFunc:  func(string, bool) (git.DiffReadiness, error) { return git.DiffReadiness{}, nil },
+			ChangedFilesFunc: func(string) ([]string, error) { return nil, nil },
+			HeadHashFunc:     func() (string, error) { return head, nil },
+			DiffSinceFunc:    func(string) (string, error) { return diff, nil },
+		}
+	}
+	run := func(t *testing.T, showDiff string, maxLines int, gitMock *mocks.GitCheckerMock) *mocks.LoggerMock {
+		t.Helper()
+		appCfg := testAppConfig(t)
+		appCfg.ShowDiff = showDiff
+		if maxLines > 0 {
+			appCfg.ShowDiffMaxLines, appCfg.ShowDiffMaxLinesSet = maxLines, true
+		}
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, newClaude(), newMockExecutor(nil), nil, &status.PhaseHolder{})
+		r.SetGitChecker(gitMock)
+		_, err := r.Run(context.Background())
+		require.NoError(t, err)
+		return log
+	}
+
+	t.Run("per iteration", func(t *testing.T) {
+		gitMock := newGit("a.go | 1 +\n+x\n")
+		log := run(t, config.ShowDiffIteration, 0, gitMock)
+		// first review, pre-codex and post-codex review iterations
+		require.Len(t, log.PrintDiffCalls(), 3)
+		assert.Equal(t, "a.go | 1 +\n+x", log.PrintDiffCalls()[0].Diff)
+		assert.Equal(t, head, gitMock.DiffSinceCalls()[0].Rev)
+	})
+
+	t.Run("per phase", func(t *testing.T) {
+		log := run(t, config.ShowDiffPhase, 0, newGit("+x\n"))
+		// claude review phase and external review phase
+		assert.Len(t, log.PrintDiffCalls(), 2)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		gitMock := newGit("+x\n")
+		log := run(t, config.ShowDiffNone, 0, gitMock)
+		assert.Empty(t, log.PrintDiffCalls())
+		assert.Empty(t, gitMock.DiffSinceCalls())
+	})
+
+	t.Run("long diff is cut", func(t *testing.T) {
+		log := run(t, config.ShowDiffPhase, 2, newGit("+a\n+b\n+c\n+d\n"))
+		require.NotEmpty(t, log.PrintDiffCalls())
+		assert.Equal(t, "+a\n+b\n[... 2 more lines, run 'git diff aaaa00000000' for the full diff ...]", log.PrintDiffCalls()[0].Diff)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		log := run(t, config.ShowDiffPhase, 0, newGit(""))
+		assert.Empty(t, log.PrintDiffCalls())
+		var found bool
+		for _, call := range log.PrintCalls() {
+			if call.Format == "no changes in %s" {
+				found = true
+			}
+		}
+		assert.True(t, found)
+	})
+}
+
 func TestRunner_ReviewLoop_GitCheckerNil_SkipsNoCommitCheck(t *testing.T) {
 	log := newMockLogger("progress.txt")
 
@@ -1920,7 +3839,7 @@ func TestRunner_ReviewLoop_GitCheckerNil_SkipsNoCommitCheck(t *testing.T) {
 	// no git checker - nil
 	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 30, CodexEnabled: false, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	// first review + pre-codex loop (1 iteration) + post-codex loop (3 iterations, max reached)
@@ -1943,6 +3862,8 @@ func TestRunner_ReviewLoop_GitCheckerError_SkipsNoCommitCheck(t *testing.T) {
 
 	// git checker always returns error — should degrade gracefully (run to max iterations)
 	gitMock := &mocks.GitCheckerMock{
+		PrepareDiffFunc:  func(string, bool) (git.DiffReadiness, error) { return git.DiffReadiness{}, nil },
+		ChangedFilesFunc: func(string) ([]string, error) { return nil, nil },
 		HeadHashFunc: func() (string, error) {
 			return "", errors.New("git HEAD error")
 		},
@@ -1951,7 +3872,7 @@ func TestRunner_ReviewLoop_GitCheckerError_SkipsNoCommitCheck(t *testing.T) {
 	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 30, CodexEnabled: false, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 	r.SetGitChecker(gitMock)
-	err := r.Run(context.Background())
+	_, err := r.Run(context.Background())
 
 	require.NoError(t, err)
 	// first review + pre-codex loop (1 iteration) + post-codex loop (3 iterations, max reached)
@@ -1992,7 +3913,7 @@ func TestRunner_SleepWithContext_CancelDuringDelay(t *testing.T) {
 	}()
 
 	start := time.Now()
-	err := r.Run(ctx)
+	_, err := r.Run(ctx)
 	elapsed := time.Since(start)
 
 	require.ErrorIs(t, err, context.Canceled)
@@ -2000,3 +3921,225 @@ func TestRunner_SleepWithContext_CancelDuringDelay(t *testing.T) {
 	assert.Less(t, elapsed, time.Duration(longDelay)*time.Millisecond,
 		"should exit promptly on cancellation, not wait for full iteration delay")
 }
+
+func TestRunner_ReviewMode_PrepareDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		fetchSet  bool
+		fetch     bool
+		readiness git.DiffReadiness
+		wantFetch bool
+		wantLogs  []string
+	}{
+		{name: "fetches by default", wantFetch: true,
+			readiness: git.DiffReadiness{PartialClone: true, Missing: 3, Fetched: 3},
+			wantLogs:  []string{"fetched 3 objects missing from partial clone for review diff"}},
+		{name: "warns when fetch disabled", fetchSet: true, fetch: false,
+			readiness: git.DiffReadiness{PartialClone: true, Missing: 2},
+			wantLogs:  []string{"[WARN] 2 objects needed for review diff are missing from partial clone, reviewers will fetch them one by one or fail when offline"}},
+		{name: "reports files outside sparse checkout", wantFetch: true,
+			readiness: git.DiffReadiness{SparseCheckout: true, OutsideSparse: []string{"lib/a.go", "lib/b.go"}},
+			wantLogs:  []string{"[WARN] 2 changed files are outside the sparse checkout, reviewers only see them via git diff: lib/a.go, lib/b.go"}},
+		{name: "regular clone logs nothing", wantFetch: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := newMockLogger("progress.txt")
+			claude := newMockExecutor([]executor.Result{
+				{Output: "review done", Signal: status.ReviewDone},
+				{Output: "review done", Signal: status.ReviewDone},
+				{Output: "review done", Signal: status.ReviewDone},
+			})
+			gitMock := &mocks.GitCheckerMock{
+				PrepareDiffFunc:  func(string, bool) (git.DiffReadiness, error) { return tc.readiness, nil },
+				ChangedFilesFunc: func(string) ([]string, error) { return nil, nil },
+				HeadHashFunc:     func() (string, error) { return "abc", nil },
+			}
+			appCfg := testAppConfig(t)
+			appCfg.PartialCloneFetch, appCfg.PartialCloneFetchSet = tc.fetch, tc.fetchSet
+
+			cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, DefaultBranch: "main", AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+			r.SetGitChecker(gitMock)
+			_, err := r.Run(context.Background())
+			require.NoError(t, err)
+
+			calls := gitMock.PrepareDiffCalls()
+			require.Len(t, calls, 1)
+			assert.Equal(t, "main", calls[0].BaseBranch)
+			assert.Equal(t, tc.wantFetch, calls[0].Fetch)
+
+			var logged []string
+			for _, c := range log.PrintCalls() {
+				msg := fmt.Sprintf(c.Format, c.Args...)
+				if strings.Contains(msg, "partial clone") || strings.Contains(msg, "sparse checkout") {
+					logged = append(logged, msg)
+				}
+			}
+			assert.Equal(t, tc.wantLogs, logged)
+		})
+	}
+}
+
+func TestRunner_ResolveFindings(t *testing.T) {
+	run := func(t *testing.T, response string) error {
+		t.Helper()
+		claude := newMockExecutor([]executor.Result{
+			{Output: response, Signal: status.CodexDone},       // evaluation
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review
+		})
+		codex := newMockExecutor([]executor.Result{{Output: "1. a.go:1 unchecked error\n2. b.go:2 racy counter"}})
+		gitMock := &mocks.GitCheckerMock{
+			PrepareDiffFunc:    func(string, bool) (git.DiffReadiness, error) { return git.DiffReadiness{}, nil },
+			ChangedFilesFunc:   func(string) ([]string, error) { return nil, nil },
+			HeadHashFunc:       func() (string, error) { return "abc123", nil },
+			DiffSinceFunc:      func(string) (string, error) { return "--- a/a.go\n+++ b/a.go\n-x\n+y\n", nil },
+			SpecialChangesFunc: func(string) (git.SpecialChanges, error) { return git.SpecialChanges{}, nil },
+		}
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+			ResolveFindings: true, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+		r.SetGitChecker(gitMock)
+		_, err := r.Run(context.Background())
+		return err
+	}
+
+	t.Run("unexplained finding fails the phase", func(t *testing.T) {
+		err := run(t, "fixed a.go")
+		require.ErrorContains(t, err, "codex iteration 1: findings neither fixed nor explained with DISMISSED or SKIPPED: b.go:2")
+	})
+
+	t.Run("every finding fixed or explained", func(t *testing.T) {
+		require.NoError(t, run(t, "fixed a.go\nSKIPPED: b.go:2 needs a redesign of the counter, out of scope"))
+	})
+}
+
+func TestRunner_VerifyReviewDone(t *testing.T) {
+	newGit := func(diff string) *mocks.GitCheckerMock {
+		return &mocks.GitCheckerMock{
+			PrepareDiffFunc:  func(string, bool) (git.DiffReadiness, error) { return git.DiffReadiness{}, nil },
+			ChangedFilesFunc: func(string) ([]string, error) { return nil, nil },
+			HeadHashFunc:     func() (string, error) { return "abc123", nil },
+			DiffSinceFunc:    func(string) (string, error) { return diff, nil },
+		}
+	}
+	run := func(t *testing.T, claude *mocks.ExecutorMock, gitMock *mocks.GitCheckerMock, v processor.Verifier) error {
+		t.Helper()
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, IterationDelayMs: 1, VerifyReviewDone: true,
+			AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		r.SetGitChecker(gitMock)
+		if v != nil {
+			r.SetVerifier(v)
+		}
+		_, err := r.Run(context.Background())
+		return err
+	}
+
+	t.Run("fixes reported without changes", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{
+			{Output: "review done", Signal: status.ReviewDone},                  // first review
+			{Output: "fixed a.go:1 unchecked error", Signal: status.ReviewDone}, // rejected, nothing changed
+			{Output: "no issues found", Signal: status.ReviewDone},              // pre-codex review
+			{Output: "review done", Signal: status.ReviewDone},                  // post-codex review
+		})
+		require.NoError(t, run(t, claude, newGit(""), nil))
+		require.Len(t, claude.RunCalls(), 4)
+		assert.True(t, strings.HasPrefix(claude.RunCalls()[2].Prompt, "REVIEW DONE REJECTED"))
+		assert.Contains(t, claude.RunCalls()[2].Prompt, "nothing changed since the review iteration started")
+	})
+
+	t.Run("fixes reported with changes", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{
+			{Output: "review done", Signal: status.ReviewDone},
+			{Output: "fixed a.go:1 unchecked error", Signal: status.ReviewDone},
+			{Output: "review done", Signal: status.ReviewDone},
+		})
+		require.NoError(t, run(t, claude, newGit("--- a/a.go\n+++ b/a.go\n-x\n+y\n"), nil))
+		assert.Len(t, claude.RunCalls(), 3)
+	})
+
+	t.Run("verification gate fails", func(t *testing.T) {
+		failing := verify.Report{Results: []verify.Result{{Command: "go test ./...", Err: errors.New("exit status 1"),
+			Output: "FAIL pkg"}}}
+		verifier := &mocks.VerifierMock{}
+		verifier.VerifyFunc = func(context.Context) verify.Report {
+			if len(verifier.VerifyCalls()) == 1 {
+				return failing
+			}
+			return verify.Report{}
+		}
+		claude := newMockExecutor([]executor.Result{
+			{Output: "review done", Signal: status.ReviewDone},
+			{Output: "review done", Signal: status.ReviewDone}, // rejected, tests fail
+			{Output: "review done", Signal: status.ReviewDone},
+			{Output: "review done", Signal: status.ReviewDone},
+		})
+		require.NoError(t, run(t, claude, newGit(""), verifier))
+		require.Len(t, claude.RunCalls(), 4)
+		assert.Contains(t, claude.RunCalls()[2].Prompt, "go test ./...")
+	})
+}
+
+func TestRunner_PostReviewSkip(t *testing.T) {
+	appCfg := testAppConfig(t)
+	appCfg.PostReviewSkipSeverity = config.SeverityMajor
+	var aligned []string
+	log := newMockLogger("progress.txt")
+	log.PrintAlignedFunc = func(text string) { aligned = append(aligned, text) }
+	claude := newMockExecutor([]executor.Result{{Output: "fixed", Signal: status.CodexDone}})
+	codex := newMockExecutor([]executor.Result{{Output: "- [minor] a.go:1 long function"}})
+	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+		AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+	_, err := r.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Len(t, claude.RunCalls(), 1, "only the evaluation, the post-codex review is skipped")
+	var skipped bool
+	for _, c := range log.PrintCalls() {
+		skipped = skipped || fmt.Sprintf(c.Format, c.Args...) == "skipping post-codex review: 1 findings, all below major severity"
+	}
+	assert.True(t, skipped)
+	assert.Contains(t, aligned, "- [minor] a.go: long function")
+}
+
+func TestRunner_Panic(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+	checkpointPath := filepath.Join(tmpDir, "state.json")
+
+	claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		var m map[string]int
+		m["boom"]++ // assignment to a nil map
+		return executor.Result{}
+	}}
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1,
+		CheckpointPath: checkpointPath, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	var finished processor.Event
+	r.SetEventHandler(func(ev processor.Event) {
+		if ev.Type == processor.EventRunFinished {
+			finished = ev
+		}
+	})
+	_, err := r.Run(context.Background())
+
+	var panicErr *processor.PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.EqualError(t, err, "crashed in task phase, task step iteration 1: panic: assignment to entry in nil map")
+	assert.Contains(t, string(panicErr.Stack), "runner_test.go", "stack of the panic")
+	assert.True(t, slices.ContainsFunc(panicErr.Events, func(ev processor.Event) bool {
+		return ev.Type == processor.EventIterationStarted && ev.Step == processor.StepTask
+	}), "last events of the run")
+	assert.Equal(t, err, finished.Err, "the run finished event reports the crash")
+
+	report := panicErr.Report()
+	assert.Contains(t, report, "===== stack =====")
+	assert.Contains(t, report, "iteration_started phase=task step=task iteration=1")
+
+	cp, err := processor.LoadCheckpoint(checkpointPath, nil)
+	require.NoError(t, err, "the checkpoint is kept for resume")
+	assert.Equal(t, processor.StepTask, cp.Step)
+}
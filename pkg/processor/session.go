@@ -0,0 +1,29 @@
+package processor
+
+import (
+	"context"
+
+	"github.com/umputun/ralphex/pkg/debuglog"
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// continueSession tells whether task iterations continue the agent session of the previous iteration
+func (r *Runner) continueSession() bool {
+	return r.cfg.AppConfig != nil && r.cfg.AppConfig.ContinueSession
+}
+
+// runInSession runs a task prompt with claude in the given agent session, a new one if session is empty
+// or continue_session is off. a continued session failing, e.g. one the agent no longer knows after
+// a resume, is replaced by a new session
+func (r *Runner) runInSession(ctx context.Context, prompt, session string) executor.Result {
+	if session == "" || !r.continueSession() {
+		return r.claude.Run(ctx, prompt)
+	}
+	r.cfg.Debug.Printf(debuglog.Processor, "continuing agent session %s", session)
+	res := r.claude.Run(executor.WithSession(ctx, session), prompt)
+	if !retryable(ctx, res.Error) {
+		return res
+	}
+	r.log.Print("[WARN] can't continue agent session %s: %v, starting a new session", session, res.Error)
+	return r.claude.Run(ctx, prompt)
+}
@@ -20,6 +20,7 @@ const (
 	SignalQuestion   = status.Question
 	SignalPlanReady  = status.PlanReady
 	SignalPlanDraft  = status.PlanDraft
+	SignalNeedsInput = status.NeedsInput
 )
 
 // questionSignalRe matches the QUESTION signal block with JSON payload
@@ -28,6 +29,9 @@ var questionSignalRe = regexp.MustCompile(`<<<RALPHEX:QUESTION>>>\s*([\s\S]*?)\s
 // planDraftSignalRe matches the PLAN_DRAFT signal block with plan content
 var planDraftSignalRe = regexp.MustCompile(`<<<RALPHEX:PLAN_DRAFT>>>\s*([\s\S]*?)\s*<<<RALPHEX:END>>>`)
 
+// needsInputSignalRe matches the NEEDS_INPUT signal block with the question of the agent
+var needsInputSignalRe = regexp.MustCompile(`<<<RALPHEX:NEEDS_INPUT>>>\s*([\s\S]*?)\s*<<<RALPHEX:END>>>`)
+
 // QuestionPayload represents a question signal from Claude during plan creation
 type QuestionPayload struct {
 	Question string   `json:"question"`
@@ -56,6 +60,9 @@ var ErrNoQuestionSignal = errors.New("no question signal found")
 // ErrNoPlanDraftSignal indicates no plan draft signal was found in output
 var ErrNoPlanDraftSignal = errors.New("no plan draft signal found")
 
+// ErrNoNeedsInputSignal indicates no needs input signal was found in output
+var ErrNoNeedsInputSignal = errors.New("no needs input signal found")
+
 // ParseQuestionPayload extracts a QuestionPayload from output containing QUESTION signal.
 // returns ErrNoQuestionSignal if no question signal is found.
 // returns other error if signal is found but JSON is malformed.
@@ -114,3 +121,24 @@ func ParsePlanDraftPayload(output string) (string, error) {
 
 	return content, nil
 }
+
+// ParseNeedsInputPayload extracts the question of a NEEDS_INPUT signal from output.
+// returns ErrNoNeedsInputSignal if no needs input signal is found.
+// returns other error if signal is found but the question is missing.
+func ParseNeedsInputPayload(output string) (string, error) {
+	if !strings.Contains(output, SignalNeedsInput) {
+		return "", ErrNoNeedsInputSignal
+	}
+
+	matches := needsInputSignalRe.FindStringSubmatch(output)
+	if len(matches) < 2 {
+		return "", errors.New("malformed needs input signal: missing END marker or empty question")
+	}
+
+	question := strings.TrimSpace(matches[1])
+	if question == "" {
+		return "", errors.New("malformed needs input signal: empty question")
+	}
+
+	return question, nil
+}
@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -413,3 +414,49 @@ func TestParsePlanDraftPayload_NoSignal(t *testing.T) {
 		})
 	}
 }
+
+func TestParseNeedsInputPayload(t *testing.T) {
+	question, err := ParseNeedsInputPayload("checked the config\n<<<RALPHEX:NEEDS_INPUT>>>\n" +
+		"Keep the v1 endpoints or remove them?\n<<<RALPHEX:END>>>\n")
+	require.NoError(t, err)
+	assert.Equal(t, "Keep the v1 endpoints or remove them?", question)
+
+	_, err = ParseNeedsInputPayload("<<<RALPHEX:ALL_TASKS_DONE>>>")
+	require.ErrorIs(t, err, ErrNoNeedsInputSignal)
+
+	_, err = ParseNeedsInputPayload("<<<RALPHEX:NEEDS_INPUT>>>\nno end marker")
+	require.ErrorContains(t, err, "missing END marker")
+
+	_, err = ParseNeedsInputPayload("<<<RALPHEX:NEEDS_INPUT>>>\n  \n<<<RALPHEX:END>>>")
+	require.ErrorContains(t, err, "empty question")
+}
+
+func BenchmarkParseQuestionPayload(b *testing.B) {
+	output := strings.Repeat("exploring the codebase, reading pkg/foo/bar.go\n", 20000) +
+		"<<<RALPHEX:QUESTION>>>\n" +
+		`{"question": "Which cache backend?", "options": ["Redis", "In-memory", "File-based"]}` +
+		"\n<<<RALPHEX:END>>>\n"
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := ParseQuestionPayload(output); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParsePlanDraftPayload(b *testing.B) {
+	var plan strings.Builder
+	plan.WriteString("# Plan\n\n## Overview\nlarge plan\n\n")
+	for i := range 500 {
+		plan.WriteString("### Task " + strings.Repeat("x", i%10) + ": step\n- [ ] implement\n- [ ] write tests\n\n")
+	}
+	output := "drafting...\n<<<RALPHEX:PLAN_DRAFT>>>\n" + plan.String() + "<<<RALPHEX:END>>>\n"
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := ParsePlanDraftPayload(output); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
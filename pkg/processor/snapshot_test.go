@@ -0,0 +1,98 @@
+package processor
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+var updateSnapshots = flag.Bool("update", false, "rewrite the prompt snapshots in testdata/prompts")
+
+// TestPromptSnapshots renders every prompt builder with fixed inputs and compares the result with its snapshot
+// in testdata/prompts, so a change of prompt wording shows up as a diff there. after an intended change
+// rewrite the snapshots with: go test ./pkg/processor -run TestPromptSnapshots -update
+func TestPromptSnapshots(t *testing.T) {
+	const findings = "1. limiter.go:42 [major] bucket refill races with take\n2. middleware.go:10 [minor] unused parameter"
+	const response = "Fixed limiter.go:42 with a mutex.\nDISMISSED: middleware.go:10 required by the interface"
+
+	newRunner := func(t *testing.T, customize func(cfg *Config)) *Runner {
+		t.Helper()
+		appCfg := testAppConfig(t)
+		cfg := Config{Mode: ModeFull, PlanFile: "testdata/prompts/plan.md", ProgressPath: "progress-rate-limiting.txt",
+			DefaultBranch: "master", AppConfig: appCfg}
+		if customize != nil {
+			customize(&cfg)
+		}
+		return NewWithExecutors(cfg, newMockLogger("progress-rate-limiting.txt"), &mocks.ExecutorMock{},
+			&mocks.ExecutorMock{}, nil, &status.PhaseHolder{})
+	}
+	r := newRunner(t, nil)
+	taskPrompt, _, err := r.taskPrompt()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		prompt string
+	}{
+		{name: "task", prompt: taskPrompt},
+		{name: "task_selection", prompt: func() string {
+			rs := newRunner(t, func(cfg *Config) { cfg.OnlyTasks = []string{"2"} })
+			p, _, err := rs.taskPrompt()
+			require.NoError(t, err)
+			return p
+		}()},
+		{name: "task_needs_input_answer", prompt: taskPrompt + needsInputNote("Which store keeps the buckets?", "redis")},
+		{name: "task_plan_updated", prompt: taskPrompt + planUpdateNote(
+			[]string{"Keep the limits per api key.", "- [ ] document the limits"}, []string{"- [ ] limit websocket connections"})},
+		{name: "task_user_notes", prompt: taskPrompt + userNotesNote([]string{"please also rate limit /healthz", "skip task 3"})},
+		{name: "review_first", prompt: r.reviewPrompt(r.cfg.AppConfig.ReviewFirstPrompt)},
+		{name: "review_second", prompt: r.reviewPrompt(r.cfg.AppConfig.ReviewSecondPrompt)},
+		{name: "review_report_only", prompt: buildReportOnlyPrompt(r.reviewPrompt(r.cfg.AppConfig.ReviewFirstPrompt), "codex", false)},
+		{name: "codex_first", prompt: r.buildCodexPrompt(true, "")},
+		{name: "codex_continue", prompt: r.buildCodexPrompt(false, response)},
+		{name: "codex_evaluation", prompt: r.buildCodexEvaluationPrompt(findings)},
+		{name: "custom_review_first", prompt: r.buildCustomReviewPrompt(true, "")},
+		{name: "custom_review_continue", prompt: r.buildCustomReviewPrompt(false, response)},
+		{name: "custom_evaluation", prompt: r.buildCustomEvaluationPrompt(findings)},
+		{name: "finalize", prompt: r.replacePromptVariables(r.cfg.AppConfig.FinalizePrompt)},
+		{name: "plan", prompt: newRunner(t, func(cfg *Config) {
+			cfg.Mode, cfg.PlanFile, cfg.PlanDescription = ModePlan, "", "add rate limiting to the public api"
+		}).buildPlanPrompt()},
+		{name: "user_template", prompt: func() string {
+			ru := newRunner(t, func(cfg *Config) {
+				cfg.AppConfig.TaskPrompt = "Goal: {{GOAL}}\nPlan: {{PLAN_FILE}}\nProgress: {{PROGRESS_FILE}}\n" +
+					"Branch: {{DEFAULT_BRANCH}}\nPlans: {{PLANS_DIR}}\n\n{{agent:quality}}\n"
+				cfg.AppConfig.TaskInstructions = "Run make lint before each commit."
+				cfg.AppConfig.Language = "German"
+			})
+			p, _, err := ru.taskPrompt()
+			require.NoError(t, err)
+			return p
+		}()},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assertSnapshot(t, tc.name, tc.prompt)
+		})
+	}
+}
+
+// assertSnapshot compares got with the snapshot testdata/prompts/<name>.txt, rewriting the snapshot with -update
+func assertSnapshot(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "prompts", name+".txt")
+	if *updateSnapshots {
+		require.NoError(t, os.WriteFile(path, []byte(got), 0o600))
+		return
+	}
+	want, err := os.ReadFile(path) //nolint:gosec // test snapshot path
+	require.NoError(t, err, "missing snapshot, create it with -update")
+	assert.Equal(t, string(want), got, "prompt differs from %s, rewrite it with -update if the change is intended", path)
+}
@@ -0,0 +1,110 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// splitDirDepth is how many leading path elements name the directory a changed file is reviewed with,
+// e.g. pkg/processor for pkg/processor/runner.go
+const splitDirDepth = 2
+
+// splitDirs groups changed files by their directory, see splitDirDepth. files at the repository root
+// are grouped under ".". directories are returned sorted.
+func splitDirs(files []string) map[string][]string {
+	res := map[string][]string{}
+	for _, f := range files {
+		dir := path.Dir(f)
+		if parts := strings.Split(dir, "/"); len(parts) > splitDirDepth {
+			dir = strings.Join(parts[:splitDirDepth], "/")
+		}
+		res[dir] = append(res[dir], f)
+	}
+	return res
+}
+
+// splitCodexReview returns the codex review of the first iteration split by directory, see
+// config.Config.CodexSplitDirs, nil if splitting is disabled
+func (r *Runner) splitCodexReview() func(ctx context.Context, prompt string) executor.Result {
+	if r.cfg.AppConfig == nil || r.cfg.AppConfig.CodexSplitDirs == 0 {
+		return nil
+	}
+	return func(ctx context.Context, prompt string) executor.Result {
+		files, err := r.changedFiles()
+		if err != nil {
+			r.log.Print("[WARN] can't split codex review by directory, reviewing in one run: %v", err)
+			return r.codex.Run(ctx, prompt)
+		}
+		groups := splitDirs(files)
+		if len(groups) < r.cfg.AppConfig.CodexSplitDirs {
+			return r.codex.Run(ctx, prompt)
+		}
+		return r.runSplitReview(ctx, prompt, groups)
+	}
+}
+
+// runSplitReview runs codex once per directory, each run limited to the changes of its directory,
+// codex_split_concurrency at a time, and merges the outputs in directory order. the first failed run fails the review.
+func (r *Runner) runSplitReview(ctx context.Context, prompt string, groups map[string][]string) executor.Result {
+	dirs := slices.Sorted(maps.Keys(groups))
+	r.log.Print("diff spans %d directories, running codex once per directory", len(dirs))
+
+	results := make([]executor.Result, len(dirs))
+	panics := make([]*executor.Panic, len(dirs))
+	sem := make(chan struct{}, max(1, r.cfg.AppConfig.CodexSplitConcurrency))
+	var wg sync.WaitGroup
+	for i, dir := range dirs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer executor.CatchPanic(&panics[i])
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			r.log.Print("codex review of %s (%d files)", dir, len(groups[dir]))
+			results[i] = r.codex.Run(ctx, prompt+splitScopeNote(dir, groups[dir], r.getDefaultBranch()))
+		}()
+	}
+	wg.Wait()
+	for _, p := range panics {
+		if p != nil {
+			panic(p) // crash the run with the stack of the goroutine
+		}
+	}
+
+	var parts []string
+	for i, res := range results {
+		if res.Error != nil {
+			return executor.Result{Error: fmt.Errorf("codex review of %s: %w", dirs[i], res.Error)}
+		}
+		if out := strings.TrimSpace(res.Output); out != "" {
+			parts = append(parts, fmt.Sprintf("## Review of %s\n\n%s", dirs[i], out))
+		}
+	}
+	return executor.Result{Output: strings.Join(parts, "\n\n")}
+}
+
+// splitScopeNote limits a codex review prompt to the changes of one directory, the files at the root
+// of the repository for "."
+func splitScopeNote(dir string, files []string, base string) string {
+	pathspec, scope := shellQuote(dir), dir
+	if dir == "." {
+		quoted := make([]string, 0, len(files))
+		for _, f := range files {
+			quoted = append(quoted, shellQuote(f))
+		}
+		pathspec, scope = strings.Join(quoted, " "), "the files at the repository root"
+	}
+	return fmt.Sprintf(`
+
+---
+SCOPE: the diff is reviewed in parts, this run covers %s only (%d changed files). Run: git diff %s...HEAD -- %s
+Report findings in these files only, the other parts of the diff are reviewed separately.`,
+		scope, len(files), base, pathspec)
+}
@@ -0,0 +1,96 @@
+package processor_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestRunner_SplitCodexReview(t *testing.T) {
+	files := []string{"pkg/api/handler.go", "pkg/api/middleware/limit.go", "pkg/store/redis.go", "README.md", "go.mod"}
+	newRun := func(t *testing.T, splitDirs, concurrency int, codex *mocks.ExecutorMock) (*mocks.ExecutorMock, error) {
+		t.Helper()
+		claude := newMockExecutor([]executor.Result{
+			{Output: "fixed", Signal: status.CodexDone},        // evaluation of the merged findings
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review
+		})
+		gitMock := &mocks.GitCheckerMock{
+			PrepareDiffFunc:    func(string, bool) (git.DiffReadiness, error) { return git.DiffReadiness{}, nil },
+			ChangedFilesFunc:   func(string) ([]string, error) { return files, nil },
+			SpecialChangesFunc: func(string) (git.SpecialChanges, error) { return git.SpecialChanges{}, nil },
+			HeadHashFunc:       func() (string, error) { return "abc123", nil },
+			DiffSinceFunc:      func(string) (string, error) { return "", nil },
+		}
+		appCfg := testAppConfig(t)
+		appCfg.CodexSplitDirs, appCfg.CodexSplitConcurrency = splitDirs, concurrency
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+			DefaultBranch: "master", AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+		r.SetGitChecker(gitMock)
+		_, err := r.Run(context.Background())
+		return claude, err
+	}
+	codexByScope := func() *mocks.ExecutorMock {
+		return &mocks.ExecutorMock{RunFunc: func(_ context.Context, prompt string) executor.Result {
+			switch {
+			case strings.Contains(prompt, "covers pkg/api only"):
+				return executor.Result{Output: "pkg/api/handler.go:10 unchecked error"}
+			case strings.Contains(prompt, "covers pkg/store only"):
+				return executor.Result{Output: "NO ISSUES FOUND"}
+			case strings.Contains(prompt, "covers the files at the repository root"):
+				return executor.Result{Output: "go.mod:3 outdated module"}
+			}
+			return executor.Result{Output: "unscoped"}
+		}}
+	}
+
+	t.Run("split by directory", func(t *testing.T) {
+		codex := codexByScope()
+		claude, err := newRun(t, 3, 2, codex)
+		require.NoError(t, err)
+
+		require.Len(t, codex.RunCalls(), 3, "one run per directory")
+		var rootPrompt string
+		for _, c := range codex.RunCalls() {
+			if strings.Contains(c.Prompt, "repository root") {
+				rootPrompt = c.Prompt
+			}
+		}
+		assert.Contains(t, rootPrompt, "Run: git diff master...HEAD -- 'README.md' 'go.mod'")
+		evalPrompt := claude.RunCalls()[0].Prompt
+		assert.Contains(t, evalPrompt, "## Review of .\n\ngo.mod:3 outdated module")
+		assert.Contains(t, evalPrompt, "## Review of pkg/api\n\npkg/api/handler.go:10 unchecked error")
+		assert.Less(t, strings.Index(evalPrompt, "## Review of pkg/api"), strings.Index(evalPrompt, "## Review of pkg/store"),
+			"merged in directory order")
+	})
+
+	t.Run("below the threshold", func(t *testing.T) {
+		codex := codexByScope()
+		claude, err := newRun(t, 4, 1, codex)
+		require.NoError(t, err)
+		require.Len(t, codex.RunCalls(), 1)
+		assert.NotContains(t, codex.RunCalls()[0].Prompt, "SCOPE:")
+		assert.Contains(t, claude.RunCalls()[0].Prompt, "unscoped")
+	})
+
+	t.Run("failed part fails the review", func(t *testing.T) {
+		codex := &mocks.ExecutorMock{RunFunc: func(_ context.Context, prompt string) executor.Result {
+			if strings.Contains(prompt, "covers pkg/store only") {
+				return executor.Result{Error: assert.AnError}
+			}
+			return executor.Result{Output: "NO ISSUES FOUND"}
+		}}
+		_, err := newRun(t, 2, 3, codex)
+		require.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "codex review of pkg/store")
+	})
+}
@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+)
+
+// stallDetector tracks task iterations making no progress: the plan file, HEAD and uncommitted changes
+// stayed the same and claude answered about the same as in the iteration before.
+type stallDetector struct {
+	limit      int     // stalled iterations in a row to give up after, 0 disables detection
+	similarity float64 // minimal output similarity for an iteration to count as stalled
+	state      string  // progress state after the last iteration
+	output     string  // output of the last iteration
+	observed   bool    // set after the first iteration, which has no output to compare with
+	stalled    int     // stalled iterations in a row
+}
+
+// newStallDetector makes a detector from stall_iterations and stall_similarity, starting from the current state.
+func (r *Runner) newStallDetector() *stallDetector {
+	if r.cfg.AppConfig == nil || r.cfg.AppConfig.StallIterations <= 0 {
+		return &stallDetector{}
+	}
+	return &stallDetector{limit: r.cfg.AppConfig.StallIterations, similarity: r.cfg.AppConfig.StallSimilarity,
+		state: r.progressState()}
+}
+
+// observe records an iteration with the progress state and output after it.
+// returns true once the limit of stalled iterations in a row is reached.
+func (d *stallDetector) observe(state, output string) bool {
+	if d.limit <= 0 {
+		return false
+	}
+	if state == d.state && (!d.observed || outputSimilarity(d.output, output) >= d.similarity) {
+		d.stalled++
+	} else {
+		d.stalled = 0
+	}
+	d.state, d.output, d.observed = state, output, true
+	return d.stalled >= d.limit
+}
+
+// progressState returns a fingerprint of the plan file, HEAD and uncommitted changes of tracked files.
+// without a git checker only the plan file is taken into account.
+func (r *Runner) progressState() string {
+	h := sha256.New()
+	if content, err := os.ReadFile(r.resolvePlanFilePath()); err == nil {
+		h.Write(content)
+	}
+	if head := r.headHash(); head != "" {
+		h.Write([]byte(head))
+		if diff, err := r.git.DiffSince(head); err == nil {
+			h.Write([]byte(diff))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// outputSimilarity returns the share of common words of two outputs, from 0 for nothing in common to 1.
+func outputSimilarity(a, b string) float64 {
+	wordsA, wordsB := wordSet(a), wordSet(b)
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+	common := 0
+	for w := range wordsA {
+		if _, ok := wordsB[w]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(wordsA)+len(wordsB)-common)
+}
+
+// wordSet returns the set of lowercased words of s.
+func wordSet(s string) map[string]struct{} {
+	res := map[string]struct{}{}
+	for w := range strings.FieldsSeq(strings.ToLower(s)) {
+		res[w] = struct{}{}
+	}
+	return res
+}
@@ -0,0 +1,59 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{name: "identical", a: "working on task 1", b: "working on task 1", want: 1},
+		{name: "both empty", a: "", b: " \n", want: 1},
+		{name: "case and spacing ignored", a: "Working on\ntask 1", b: "working  on task 1", want: 1},
+		{name: "nothing in common", a: "alpha beta", b: "gamma delta", want: 0},
+		{name: "one empty", a: "alpha", b: "", want: 0},
+		{name: "half in common", a: "a b c", b: "a b d", want: 0.5},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, tc.want, outputSimilarity(tc.a, tc.b), 1e-9)
+		})
+	}
+}
+
+func TestStallDetector_observe(t *testing.T) {
+	t.Run("repeated output with unchanged state", func(t *testing.T) {
+		d := &stallDetector{limit: 3, similarity: 0.9, state: "s0"}
+		assert.False(t, d.observe("s0", "same"))
+		assert.False(t, d.observe("s0", "same"))
+		assert.True(t, d.observe("s0", "same"))
+	})
+
+	t.Run("state change resets", func(t *testing.T) {
+		d := &stallDetector{limit: 2, similarity: 0.9, state: "s0"}
+		assert.False(t, d.observe("s0", "same"))
+		assert.False(t, d.observe("s1", "same"))
+		assert.False(t, d.observe("s1", "same"))
+		assert.True(t, d.observe("s1", "same"))
+	})
+
+	t.Run("different output resets", func(t *testing.T) {
+		d := &stallDetector{limit: 2, similarity: 0.9, state: "s0"}
+		assert.False(t, d.observe("s0", "one thing"))
+		assert.False(t, d.observe("s0", "another thing entirely"))
+		assert.False(t, d.observe("s0", "another thing entirely"))
+		assert.True(t, d.observe("s0", "another thing entirely"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		d := &stallDetector{}
+		for range 5 {
+			assert.False(t, d.observe("", "same"))
+		}
+	})
+}
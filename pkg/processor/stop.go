@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// StopFile is the default location of the file requesting a stop of the run, relative to the repository root.
+const StopFile = ".ralphex/stop"
+
+// errStopRequested is the cause of the run context canceled by a stop request
+var errStopRequested = errors.New("stop requested")
+
+// StopError reports a run stopped on request, see Runner.RequestStop and Config.StopFile, or because
+// Config.RunWindow closed, with the phase, step and iteration it stopped before. it wraps context.Canceled.
+type StopError struct {
+	Phase     status.Phase
+	Step      Step
+	Iteration int    // 1-based iteration of Step not started
+	Window    string // the run window closed, empty for a stop on request
+}
+
+// Error returns why and where the run stopped.
+func (e *StopError) Error() string {
+	where := fmt.Sprintf("%s phase", e.Phase)
+	if e.Step != "" {
+		where += fmt.Sprintf(", before %s step iteration %d", e.Step, e.Iteration)
+	}
+	if e.Window != "" {
+		return fmt.Sprintf("stopped as run window %s closed in %s", e.Window, where)
+	}
+	return "stopped by user in " + where
+}
+
+// Unwrap returns context.Canceled.
+func (e *StopError) Unwrap() error {
+	return context.Canceled
+}
+
+// RequestStop asks the run to stop once the iteration in progress completes. the checkpoint of the next
+// iteration is saved, so the run can be resumed. safe to call from another goroutine.
+func (r *Runner) RequestStop() {
+	r.stop.Store(true)
+}
+
+// checkStop stops the run at the start of an iteration, after its checkpoint is saved, if a stop is requested
+// with RequestStop or the stop file. the run context is canceled, so no failure policy retries or skips the
+// phase, and Run returns a StopError.
+func (r *Runner) checkStop() error {
+	if !r.stop.Load() && !r.stopFileFound() {
+		return nil
+	}
+	r.stop.Store(true)
+	r.log.Print("[STOPPED] stop requested, stopping before %s iteration %d, checkpoint saved",
+		r.position.Step, r.position.Iteration+1)
+	if r.cancelRun != nil {
+		r.cancelRun(errStopRequested)
+	}
+	return errStopRequested
+}
+
+// stopFileFound reports if the stop file exists and removes it, so the resumed run doesn't stop right away.
+func (r *Runner) stopFileFound() bool {
+	if r.cfg.StopFile == "" {
+		return false
+	}
+	if _, err := os.Stat(r.cfg.StopFile); err != nil {
+		return false
+	}
+	if err := os.Remove(r.cfg.StopFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		r.log.Print("[WARN] failed to remove stop file: %v", err)
+	}
+	return true
+}
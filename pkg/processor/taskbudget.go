@@ -0,0 +1,71 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// minTaskIterations is the lowest per-task iteration cap derived from the plan
+const minTaskIterations = 3
+
+// taskBudget caps the iterations each plan task may take. the task in progress is the first one
+// with unchecked items, it gets charged for every iteration it is still incomplete at the start of.
+type taskBudget struct {
+	limit int            // iterations per task, 0 disables the cap
+	spent map[string]int // iterations by task header
+}
+
+// newTaskBudget makes the per-task budget from task_max_iterations, or derives it from the plan: twice the
+// even share of max iterations per task, at least minTaskIterations. plans without task sections get no cap.
+func (r *Runner) newTaskBudget() *taskBudget {
+	limit := 0
+	if r.cfg.AppConfig != nil {
+		limit = r.cfg.AppConfig.TaskMaxIterations
+	}
+	if limit == 0 {
+		content, err := os.ReadFile(r.resolvePlanFilePath())
+		if err != nil {
+			return &taskBudget{}
+		}
+		tasks := len(plan.ParseTasks(string(content)))
+		if tasks == 0 {
+			return &taskBudget{}
+		}
+		limit = max(minTaskIterations, (2*r.cfg.MaxIterations+tasks-1)/tasks)
+	}
+	return &taskBudget{limit: limit, spent: map[string]int{}}
+}
+
+// charge charges an iteration to the task in progress, failing if the task has used up its iterations.
+// task is the header of the task in progress, empty if none is known.
+func (b *taskBudget) charge(task string) error {
+	if b.limit <= 0 || task == "" {
+		return nil
+	}
+	if b.spent[task] >= b.limit {
+		return fmt.Errorf("task %q still incomplete after %d iterations, the per-task limit (task_max_iterations)", task, b.spent[task])
+	}
+	b.spent[task]++
+	return nil
+}
+
+// currentTask returns the header of the first plan task with unchecked items, selected ones with a task selection,
+// empty if none or the plan can't be read.
+func (r *Runner) currentTask() string {
+	if r.taskSelection() {
+		if pending, err := r.pendingSelectedTasks(); err == nil && len(pending) > 0 {
+			return pending[0].Header
+		}
+		return ""
+	}
+	content, err := os.ReadFile(r.resolvePlanFilePath())
+	if err != nil {
+		return ""
+	}
+	if tasks := plan.IncompleteTasks(string(content)); len(tasks) > 0 {
+		return tasks[0]
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_newTaskBudget(t *testing.T) {
+	twoTasks := "# Plan\n### Task 1: a\n- [ ] one\n### Task 2: b\n- [ ] two\n"
+	tests := []struct {
+		name          string
+		content       string
+		configured    int
+		maxIterations int
+		want          int
+	}{
+		{name: "derived", content: twoTasks, maxIterations: 10, want: 10},
+		{name: "derived rounds up", content: twoTasks, maxIterations: 5, want: 5},
+		{name: "derived minimum", content: twoTasks, maxIterations: 2, want: minTaskIterations},
+		{name: "configured", content: twoTasks, configured: 4, maxIterations: 50, want: 4},
+		{name: "no task sections", content: "# Plan\n- [ ] one\n", maxIterations: 10, want: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			planFile := filepath.Join(t.TempDir(), "plan.md")
+			require.NoError(t, os.WriteFile(planFile, []byte(tc.content), 0o600))
+			appCfg := testAppConfig(t)
+			appCfg.TaskMaxIterations = tc.configured
+			r := &Runner{cfg: Config{PlanFile: planFile, MaxIterations: tc.maxIterations, AppConfig: appCfg}}
+			assert.Equal(t, tc.want, r.newTaskBudget().limit)
+		})
+	}
+}
+
+func TestTaskBudget_charge(t *testing.T) {
+	b := &taskBudget{limit: 2, spent: map[string]int{}}
+	require.NoError(t, b.charge("Task 1: a"))
+	require.NoError(t, b.charge("Task 1: a"))
+	require.NoError(t, b.charge("Task 2: b"), "tasks have their own budgets")
+	require.NoError(t, b.charge(""), "unknown task is not charged")
+	require.EqualError(t, b.charge("Task 1: a"),
+		`task "Task 1: a" still incomplete after 2 iterations, the per-task limit (task_max_iterations)`)
+
+	require.NoError(t, (&taskBudget{}).charge("Task 1: a"), "disabled budget")
+}
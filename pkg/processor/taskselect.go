@@ -0,0 +1,58 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// taskSelection reports if Config.StartTask or Config.OnlyTasks narrow the task phase to some tasks of the plan.
+func (r *Runner) taskSelection() bool {
+	return r.cfg.StartTask != "" || len(r.cfg.OnlyTasks) > 0
+}
+
+// selectedTasks returns the tasks of the plan selected by Config.StartTask and Config.OnlyTasks.
+func (r *Runner) selectedTasks() ([]plan.Task, error) {
+	content, err := os.ReadFile(r.resolvePlanFilePath())
+	if err != nil {
+		return nil, fmt.Errorf("read plan file: %w", err)
+	}
+	tasks, err := plan.SelectTasks(plan.ParseTasks(string(content)), r.cfg.StartTask, r.cfg.OnlyTasks)
+	if err != nil {
+		return nil, fmt.Errorf("task selection: %w", err)
+	}
+	return tasks, nil
+}
+
+// pendingSelectedTasks returns the selected tasks with unchecked items, in plan order.
+func (r *Runner) pendingSelectedTasks() ([]plan.Task, error) {
+	tasks, err := r.selectedTasks()
+	if err != nil {
+		return nil, err
+	}
+	var res []plan.Task
+	for _, t := range tasks {
+		if !t.Done() {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+// taskPrompt returns the prompt of task iterations. with a task selection it lists the selected tasks still
+// pending, and done reports that none is.
+func (r *Runner) taskPrompt() (prompt string, done bool, err error) {
+	prompt = r.replacePromptVariables(r.cfg.AppConfig.TaskPrompt + instructionsNote(r.cfg.AppConfig.TaskInstructions))
+	if !r.taskSelection() {
+		return prompt, false, nil
+	}
+	pending, err := r.pendingSelectedTasks()
+	if err != nil {
+		return "", false, err
+	}
+	if len(pending) == 0 {
+		return prompt, true, nil
+	}
+	return buildTaskSelectionPrompt(prompt, pending), false, nil
+}
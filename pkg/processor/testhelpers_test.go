@@ -11,7 +11,7 @@ import (
 )
 
 // testAppConfig loads config with embedded defaults for testing.
-func testAppConfig(t *testing.T) *config.Config {
+func testAppConfig(t testing.TB) *config.Config {
 	t.Helper()
 	cfg, err := config.Load(t.TempDir())
 	require.NoError(t, err)
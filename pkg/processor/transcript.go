@@ -0,0 +1,141 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/debuglog"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/seal"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// TranscriptDir is where prompts and responses are dumped with prompts debugging on,
+// relative to the repository root. each run writes into its own subdirectory.
+const TranscriptDir = ".ralphex/transcripts"
+
+// nonSlugRe matches characters replaced in transcript file names
+var nonSlugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// transcript writes each agent call of a run, prompt and response, to a numbered file.
+// content is redacted before it is written, and encrypted with an artifact key set.
+type transcript struct {
+	dir      string
+	redactor *debuglog.Redactor
+	sealer   *seal.Sealer  // nil writes plaintext
+	section  func() string // label of the current section, e.g. "task iteration 2"
+	phase    *status.PhaseHolder
+
+	mu     sync.Mutex
+	seq    int
+	failed bool // set after a write error, so a broken directory is reported once
+}
+
+// newTranscript creates a transcript for a run in dir, in a subdirectory named after the progress file and start time
+func newTranscript(dir, progressPath string, start time.Time, redactor *debuglog.Redactor, sealer *seal.Sealer,
+	section func() string, phase *status.PhaseHolder) *transcript {
+	run := strings.TrimSuffix(filepath.Base(progressPath), filepath.Ext(progressPath))
+	if progressPath == "" {
+		run = "run"
+	}
+	return &transcript{
+		dir:      filepath.Join(dir, run+"-"+start.Format("20060102-150405")),
+		redactor: redactor,
+		sealer:   sealer,
+		section:  section,
+		phase:    phase,
+	}
+}
+
+// newTranscriptRedactor creates a redactor masking notification credentials, the artifact key and
+// secret-looking environment values
+func newTranscriptRedactor(appCfg *config.Config) *debuglog.Redactor {
+	secrets := debuglog.EnvSecrets()
+	if appCfg != nil {
+		p := appCfg.NotifyParams
+		secrets = append(secrets, p.TelegramToken, p.SlackToken, p.SMTPPassword, appCfg.ArtifactKey)
+		secrets = append(secrets, p.WebhookURLs...)
+	}
+	return debuglog.NewRedactor(secrets...)
+}
+
+// write dumps a prompt and the agent's response, returning the file written
+func (t *transcript) write(agent, prompt string, res executor.Result) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.failed {
+		return "", nil
+	}
+	t.seq++
+
+	section := t.section()
+	name := strings.Trim(nonSlugRe.ReplaceAllString(strings.ToLower(section), "-"), "-")
+	if name == "" {
+		name = "run"
+	}
+	path := filepath.Join(t.dir, fmt.Sprintf("%03d-%s-%s.txt", t.seq, name, agent))
+
+	var phase status.Phase
+	if t.phase != nil {
+		phase = t.phase.Get()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "agent: %s\nphase: %s\nsection: %s\nsignal: %s\n", agent, phase, section, res.Signal)
+	if res.Error != nil {
+		fmt.Fprintf(&b, "error: %v\n", res.Error)
+	}
+	fmt.Fprintf(&b, "\n===== prompt (%s, %s) =====\n%s\n", section, agent, prompt)
+	fmt.Fprintf(&b, "\n===== response (%s, %s) =====\n%s\n", section, agent, response(res))
+
+	if err := os.MkdirAll(t.dir, 0o750); err != nil {
+		t.failed = true
+		return "", fmt.Errorf("create transcript dir: %w", err)
+	}
+	if err := os.WriteFile(path, t.sealer.Seal([]byte(t.redactor.Redact(b.String()))), 0o600); err != nil {
+		t.failed = true
+		return "", fmt.Errorf("write transcript: %w", err)
+	}
+	return path, nil
+}
+
+// response returns the full output of an agent call. truncated output is read from the file the executor
+// streamed it to, falling back to the truncated output if the file can't be read.
+func response(res executor.Result) string {
+	if res.OutputFile == "" {
+		return res.Output
+	}
+	data, err := os.ReadFile(res.OutputFile)
+	if err != nil {
+		return res.Output
+	}
+	return string(data)
+}
+
+// sectionLogger passes everything to the wrapped logger and remembers the last section label,
+// so transcript files can be named after the phase and iteration they belong to
+type sectionLogger struct {
+	Logger
+	mu    sync.Mutex
+	label string
+}
+
+// PrintSection records the section label and prints the section
+func (l *sectionLogger) PrintSection(section status.Section) {
+	l.mu.Lock()
+	l.label = section.Label
+	l.mu.Unlock()
+	l.Logger.PrintSection(section)
+}
+
+// current returns the label of the last printed section
+func (l *sectionLogger) current() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.label
+}
@@ -0,0 +1,91 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// bytesPerToken approximates the tokens of prompts and output the CLI reports no usage for
+const bytesPerToken = 4
+
+// UsageReport is the time and token use of agent calls.
+type UsageReport struct {
+	Calls        int           `json:"calls"`
+	Duration     time.Duration `json:"duration"`     // wall-clock time of the calls
+	APIDuration  time.Duration `json:"api_duration"` // time spent waiting for the model provider, of calls the CLI reports it for
+	InputTokens  int           `json:"input_tokens"`
+	OutputTokens int           `json:"output_tokens"`
+	Estimated    bool          `json:"estimated,omitempty"` // some token counts are estimated from the text size
+}
+
+// add counts another call
+func (u *UsageReport) add(call UsageReport) {
+	u.Calls += call.Calls
+	u.Duration += call.Duration
+	u.APIDuration += call.APIDuration
+	u.InputTokens += call.InputTokens
+	u.OutputTokens += call.OutputTokens
+	u.Estimated = u.Estimated || call.Estimated
+}
+
+// String returns the usage for the progress output, e.g. "1m12s, 12.3k tokens in, 1.1k out, provider 48s".
+// estimated token counts are marked with "~".
+func (u UsageReport) String() string {
+	approx := ""
+	if u.Estimated {
+		approx = "~"
+	}
+	res := fmt.Sprintf("%s, %s%s tokens in, %s%s out", u.Duration.Round(time.Second), approx, formatTokens(u.InputTokens),
+		approx, formatTokens(u.OutputTokens))
+	if u.APIDuration > 0 {
+		res += fmt.Sprintf(", provider %s", u.APIDuration.Round(time.Second))
+	}
+	return res
+}
+
+// formatTokens returns a token count as 850 or 12.3k
+func formatTokens(n int) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d", n)
+	}
+	return fmt.Sprintf("%.1fk", float64(n)/1000)
+}
+
+// callUsage returns the usage of an agent call taking d, estimated from the size of the prompt and output
+// when the CLI reported none
+func callUsage(prompt string, res executor.Result, d time.Duration) UsageReport {
+	u := UsageReport{Calls: 1, Duration: d, APIDuration: res.Usage.APIDuration,
+		InputTokens: res.Usage.InputTokens, OutputTokens: res.Usage.OutputTokens}
+	if res.Usage.InputTokens == 0 && res.Usage.OutputTokens == 0 {
+		u.InputTokens, u.OutputTokens = len(prompt)/bytesPerToken, len(res.Output)/bytesPerToken
+		u.Estimated = true
+	}
+	return u
+}
+
+// usageExecutor prints the time and token use of each agent call with the totals of the run, so prompts
+// growing or responses slowing down during a long run show up in the progress output
+type usageExecutor struct {
+	name  string
+	exec  Executor
+	total *UsageReport
+	mu    *sync.Mutex // shared by the executors of the run, they may run in parallel
+	log   Logger
+}
+
+// Run executes the wrapped executor and records the usage of the call.
+func (e *usageExecutor) Run(ctx context.Context, prompt string) executor.Result {
+	start := time.Now()
+	res := e.exec.Run(ctx, prompt)
+	call := callUsage(prompt, res, time.Since(start))
+	e.mu.Lock()
+	e.total.add(call)
+	total := *e.total
+	e.mu.Unlock()
+	e.log.Print("%s call: %s | run: %d calls, %s", e.name, call, total.Calls, total)
+	return res
+}
@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+)
+
+func TestUsageReport_String(t *testing.T) {
+	u := UsageReport{Calls: 1, Duration: 72 * time.Second, APIDuration: 48 * time.Second, InputTokens: 12345, OutputTokens: 850}
+	assert.Equal(t, "1m12s, 12.3k tokens in, 850 out, provider 48s", u.String())
+
+	u = UsageReport{Calls: 1, Duration: 3 * time.Second, InputTokens: 2500, OutputTokens: 1000, Estimated: true}
+	assert.Equal(t, "3s, ~2.5k tokens in, ~1.0k out", u.String())
+}
+
+func TestCallUsage(t *testing.T) {
+	reported := executor.Result{Output: "done", Usage: executor.Usage{InputTokens: 9000, OutputTokens: 300, APIDuration: 5 * time.Second}}
+	assert.Equal(t, UsageReport{Calls: 1, Duration: 8 * time.Second, APIDuration: 5 * time.Second, InputTokens: 9000,
+		OutputTokens: 300}, callUsage("prompt", reported, 8*time.Second))
+
+	estimated := callUsage(strings.Repeat("p", 4000), executor.Result{Output: strings.Repeat("o", 400)}, time.Second)
+	assert.Equal(t, UsageReport{Calls: 1, Duration: time.Second, InputTokens: 1000, OutputTokens: 100, Estimated: true}, estimated)
+}
+
+func TestUsageExecutor_Run(t *testing.T) {
+	inner := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		return executor.Result{Output: "ok", Usage: executor.Usage{InputTokens: 1500, OutputTokens: 200}}
+	}}
+	log := newMockLogger("progress.txt")
+	total := UsageReport{}
+	e := &usageExecutor{name: "claude", exec: inner, total: &total, mu: &sync.Mutex{}, log: log}
+
+	e.Run(context.Background(), "prompt")
+	e.Run(context.Background(), "prompt")
+
+	assert.Equal(t, 2, total.Calls)
+	assert.Equal(t, 3000, total.InputTokens)
+	assert.Equal(t, 400, total.OutputTokens)
+	assert.False(t, total.Estimated)
+	calls := log.PrintCalls()
+	require.Len(t, calls, 2)
+	assert.Equal(t, "claude call: 0s, 1.5k tokens in, 200 out | run: 2 calls, 0s, 3.0k tokens in, 400 out",
+		fmt.Sprintf(calls[1].Format, calls[1].Args...))
+}
@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// errWindowClosed is the cause of the run context canceled when Config.RunWindow closes
+var errWindowClosed = errors.New("run window closed")
+
+// waitForWindow holds a run started outside Config.RunWindow until the window opens.
+func (r *Runner) waitForWindow(ctx context.Context) error {
+	if r.cfg.RunWindow.IsZero() {
+		return nil
+	}
+	now := r.now()
+	open := r.cfg.RunWindow.NextOpen(now)
+	if !open.After(now) {
+		return nil
+	}
+	r.log.Print("outside run window %s, waiting until %s", r.cfg.RunWindow, open.Format("2006-01-02 15:04"))
+	t := time.NewTimer(open.Sub(now))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for run window: %w", ctx.Err())
+	case <-t.C:
+		return nil
+	}
+}
+
+// checkWindow stops the run at the start of an iteration, after its checkpoint is saved, once Config.RunWindow
+// closed. like a requested stop, the run context is canceled and Run returns a StopError, resumable in the next window.
+func (r *Runner) checkWindow() error {
+	if r.cfg.RunWindow.IsZero() || r.cfg.RunWindow.Contains(r.now()) {
+		return nil
+	}
+	r.log.Print("[STOPPED] run window %s closed, stopping before %s iteration %d, checkpoint saved",
+		r.cfg.RunWindow, r.position.Step, r.position.Iteration+1)
+	if r.cancelRun != nil {
+		r.cancelRun(errWindowClosed)
+	}
+	return errWindowClosed
+}
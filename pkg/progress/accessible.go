@@ -0,0 +1,86 @@
+package progress
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// statusInterval is how often accessible output reports the run status while nothing else is printed
+var statusInterval = time.Minute
+
+// accessibleState tracks what the status lines of accessible output report, see Config.Accessible.
+type accessibleState struct {
+	mu         sync.Mutex
+	section    string    // label of the last section header
+	lastOutput time.Time // time of the last line written to stdout
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// startStatus starts writing a status line to stdout every statusInterval without other output,
+// so a screen reader user hears the run is still going and where it is. stopped by stopStatus.
+func (l *Logger) startStatus() {
+	l.acc.lastOutput = time.Now()
+	l.acc.stop, l.acc.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(l.acc.done)
+		ticker := time.NewTicker(statusInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-l.acc.stop:
+				return
+			case now := <-ticker.C:
+				if line := l.statusLine(now); line != "" {
+					fmt.Fprintln(l.stdout, line) // not through writeStdout, status lines are not output of the run
+				}
+			}
+		}
+	}()
+}
+
+// stopStatus stops the status lines started by startStatus, a no-op if they were not started.
+func (l *Logger) stopStatus() {
+	if l.acc == nil || l.acc.stop == nil {
+		return
+	}
+	close(l.acc.stop)
+	<-l.acc.done
+	l.acc.stop = nil
+}
+
+// statusLine returns the status line for now, empty if something was printed within the last interval.
+// e.g. "status: task phase, task iteration 3, running 12m0s, no output for 2m0s"
+func (l *Logger) statusLine(now time.Time) string {
+	l.acc.mu.Lock()
+	section, quiet := l.acc.section, now.Sub(l.acc.lastOutput)
+	l.acc.mu.Unlock()
+	if quiet < statusInterval {
+		return ""
+	}
+
+	parts := []string{"status:"}
+	if phase := l.holder.Get(); phase != "" {
+		parts = append(parts, fmt.Sprintf("%s phase,", phase))
+	}
+	if section != "" {
+		parts = append(parts, section+",")
+	}
+	parts = append(parts, fmt.Sprintf("running %s, no output for %s", l.Elapsed(), quiet.Truncate(time.Second)))
+	return strings.Join(parts, " ")
+}
+
+// outputWritten records a write to stdout for the status lines, a no-op without accessible output.
+func (l *Logger) outputWritten(section string) {
+	if l.acc == nil {
+		return
+	}
+	l.acc.mu.Lock()
+	defer l.acc.mu.Unlock()
+	l.acc.lastOutput = time.Now()
+	if section != "" {
+		l.acc.section = section
+	}
+}
@@ -0,0 +1,206 @@
+package progress
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// attribute keys set on every record emitted by Logger. handlers plugged in with Config.WrapHandler
+// can use them to route or filter records, e.g. by phase.
+const (
+	KindKey  = "kind"  // how the record is rendered, one of the Kind* values
+	PhaseKey = "phase" // execution phase the record was emitted in (task, review, codex, ...)
+)
+
+// record kinds, the value of KindKey
+const (
+	KindLine        = "line"         // timestamped message, ERROR: or WARN: prefixed at error and warn levels
+	KindRaw         = "raw"          // streaming output written as is
+	KindSection     = "section"      // section header
+	KindAligned     = "aligned"      // multi-line agent output, each line timestamped
+	KindDiff        = "diff"         // unified diff
+	KindQuestion    = "question"     // plan mode question, options in the "options" attribute
+	KindAnswer      = "answer"       // plan mode answer
+	KindDraftReview = "draft_review" // plan draft review action, feedback in the "feedback" attribute
+	KindDiffStats   = "diffstats"    // diff stats, file only, counts in "files", "additions", "deletions"
+)
+
+// handler is the slog.Handler writing records in the progress log format: plain to the progress
+// file and colored to stdout. it writes through the Logger, so output redirection applies.
+// the progress file is parsed by the web dashboard, so every record is rendered regardless of level.
+type handler struct {
+	l *Logger
+}
+
+// Enabled reports that all levels are rendered.
+func (h *handler) Enabled(context.Context, slog.Level) bool { return true }
+
+// WithAttrs returns the handler unchanged, the progress format has no place for extra attributes.
+func (h *handler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+// WithGroup returns the handler unchanged, the progress format has no place for extra attributes.
+func (h *handler) WithGroup(string) slog.Handler { return h }
+
+// Handle renders the record according to its kind.
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	var kind, feedback string
+	var phase status.Phase
+	var options []string
+	var files, additions, deletions int64
+	r.Attrs(func(a slog.Attr) bool {
+		switch a.Key {
+		case KindKey:
+			kind = a.Value.String()
+		case PhaseKey:
+			phase = status.Phase(a.Value.String())
+		case "options":
+			options, _ = a.Value.Any().([]string)
+		case "feedback":
+			feedback = a.Value.String()
+		case "files":
+			files = a.Value.Int64()
+		case "additions":
+			additions = a.Value.Int64()
+		case "deletions":
+			deletions = a.Value.Int64()
+		}
+		return true
+	})
+
+	l, colors := h.l, h.l.colors
+	timestamp := r.Time.Format(timestampFormat)
+	tsStr := h.stdoutPrefix(timestamp)
+	switch kind {
+	case KindRaw:
+		l.writeFile("%s", r.Message)
+		l.writeStdout("%s", r.Message)
+	case KindSection:
+		header := "\n--- " + r.Message + " ---\n"
+		l.writeFile("%s", header)
+		if l.acc != nil {
+			l.writeStdout("now: %s\n", r.Message)
+			l.outputWritten(r.Message)
+			break
+		}
+		l.writeStdout("%s", colors.Warn().Sprint(header))
+	case KindAligned:
+		h.writeAligned(r.Message, colors.ForPhase(phase))
+	case KindDiff:
+		h.writeDiff(r.Message, colors.ForPhase(phase))
+	case KindQuestion:
+		opts := strings.Join(options, ", ")
+		l.writeFile("[%s] QUESTION: %s\n", timestamp, r.Message)
+		l.writeFile("[%s] OPTIONS: %s\n", timestamp, opts)
+		l.writeStdout("%s%s\n", tsStr, colors.Info().Sprintf("QUESTION: %s", r.Message))
+		l.writeStdout("%s%s\n", tsStr, colors.Info().Sprintf("OPTIONS: %s", opts))
+	case KindAnswer:
+		l.writeFile("[%s] ANSWER: %s\n", timestamp, r.Message)
+		l.writeStdout("%s%s\n", tsStr, colors.Info().Sprintf("ANSWER: %s", r.Message))
+	case KindDraftReview:
+		l.writeFile("[%s] DRAFT REVIEW: %s\n", timestamp, r.Message)
+		l.writeStdout("%s%s\n", tsStr, colors.Info().Sprintf("DRAFT REVIEW: %s", r.Message))
+		if feedback != "" {
+			l.writeFile("[%s] FEEDBACK: %s\n", timestamp, feedback)
+			l.writeStdout("%s%s\n", tsStr, colors.Info().Sprintf("FEEDBACK: %s", feedback))
+		}
+	case KindDiffStats:
+		l.writeFile("[%s] DIFFSTATS: files=%d additions=%d deletions=%d\n", timestamp, files, additions, deletions)
+	default:
+		switch {
+		case r.Level >= slog.LevelError:
+			l.writeFile("[%s] ERROR: %s\n", timestamp, r.Message)
+			l.writeStdout("%s%s\n", tsStr, colors.Error().Sprintf("ERROR: %s", r.Message))
+		case r.Level >= slog.LevelWarn:
+			l.writeFile("[%s] WARN: %s\n", timestamp, r.Message)
+			l.writeStdout("%s%s\n", tsStr, colors.Warn().Sprintf("WARN: %s", r.Message))
+		default:
+			l.writeFile("[%s] %s\n", timestamp, r.Message)
+			l.writeStdout("%s%s\n", tsStr, colors.ForPhase(phase).Sprint(r.Message))
+		}
+	}
+	return nil
+}
+
+// stdoutPrefix returns the timestamp prefix of a stdout line, empty for accessible output
+// where timestamps read out on every line would drown the message.
+func (h *handler) stdoutPrefix(timestamp string) string {
+	if h.l.acc != nil {
+		return ""
+	}
+	return h.l.colors.Timestamp().Sprintf("[%s] ", timestamp)
+}
+
+// writeAligned writes text with timestamp on each line, wrapping long lines to the terminal width
+// and suppressing empty lines. list items are indented, signal lines shown by name in signal color.
+// accessible output is not wrapped, screen readers follow lines of any length.
+func (h *handler) writeAligned(text string, phaseColor *color.Color) {
+	width := getTerminalWidth()
+
+	// split into lines, wrap each long line, then process
+	var lines []string
+	for line := range strings.SplitSeq(text, "\n") {
+		if len(line) > width && h.l.acc == nil {
+			wrapped := wrapText(line, width)
+			for wrappedLine := range strings.SplitSeq(wrapped, "\n") {
+				lines = append(lines, wrappedLine)
+			}
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	for _, line := range lines {
+		if line == "" {
+			continue // skip empty lines
+		}
+
+		// add indent for list items
+		displayLine := formatListItem(line)
+
+		// timestamp each line
+		timestamp := time.Now().Format(timestampFormat)
+		h.l.writeFile("[%s] %s\n", timestamp, displayLine)
+
+		// use red for signal lines
+		lineColor := phaseColor
+
+		// format signal lines nicely
+		if sig := extractSignal(line); sig != "" {
+			displayLine = sig
+			lineColor = h.l.colors.Signal()
+			if h.l.acc != nil {
+				displayLine = "signal: " + sig
+			}
+		}
+
+		h.l.writeStdout("%s%s\n", h.stdoutPrefix(timestamp), lineColor.Sprint(displayLine))
+	}
+}
+
+// writeDiff writes a unified diff with timestamp on each line, colorizing added, removed,
+// hunk and file header lines on stdout.
+func (h *handler) writeDiff(diff string, phaseColor *color.Color) {
+	for line := range strings.SplitSeq(diff, "\n") {
+		timestamp := time.Now().Format(timestampFormat)
+		h.l.writeFile("[%s] %s\n", timestamp, line)
+
+		lineColor := phaseColor
+		switch {
+		case strings.HasPrefix(line, "diff --git "), strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			lineColor = diffHeaderColor
+		case strings.HasPrefix(line, "+"):
+			lineColor = diffAddColor
+		case strings.HasPrefix(line, "-"):
+			lineColor = diffDeleteColor
+		case strings.HasPrefix(line, "@@"):
+			lineColor = diffHunkColor
+		}
+		h.l.writeStdout("%s%s\n", h.stdoutPrefix(timestamp), lineColor.Sprint(line))
+	}
+}
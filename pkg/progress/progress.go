@@ -2,8 +2,10 @@
 package progress
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -121,6 +123,8 @@ type Logger struct {
 	startTime time.Time
 	holder    *status.PhaseHolder
 	colors    *Colors
+	log       *slog.Logger     // handler chain all output goes through, see Config.WrapHandler
+	acc       *accessibleState // accessible output state, nil unless Config.Accessible
 }
 
 // Config holds logger configuration.
@@ -129,7 +133,17 @@ type Config struct {
 	PlanDescription string // plan description for plan mode (used for filename)
 	Mode            string // execution mode: full, review, codex-only, plan
 	Branch          string // current git branch
+	Dir             string // directory for progress files, .ralphex/progress if empty
 	NoColor         bool   // disable color output (sets color.NoColor globally)
+
+	// Accessible makes stdout screen reader friendly: plain lines without colors, timestamps, section rules
+	// or wrapping, and a status line after each minute without output. the progress file is unchanged
+	Accessible bool
+
+	// WrapHandler wraps the handler writing the progress file and stdout, so embedding applications can
+	// route records to their own slog handlers, e.g. fan out to both or filter by PhaseKey and level.
+	// records dropped by the wrapper are missing from the progress file as well. nil keeps the default.
+	WrapHandler func(slog.Handler) slog.Handler
 }
 
 // NewLogger creates a logger writing to both a progress file and stdout.
@@ -140,11 +154,14 @@ type Config struct {
 // holder is the shared PhaseHolder for reading the current execution phase.
 func NewLogger(cfg Config, colors *Colors, holder *status.PhaseHolder) (*Logger, error) {
 	// set global color setting
-	if cfg.NoColor {
+	if cfg.NoColor || cfg.Accessible {
 		color.NoColor = true
 	}
 
 	progressPath := progressFilename(cfg.PlanFile, cfg.PlanDescription, cfg.Mode)
+	if cfg.Dir != "" {
+		progressPath = filepath.Join(cfg.Dir, filepath.Base(progressPath))
+	}
 
 	// ensure progress files are tracked by creating parent dir
 	if dir := filepath.Dir(progressPath); dir != "." {
@@ -200,6 +217,15 @@ func NewLogger(cfg Config, colors *Colors, holder *status.PhaseHolder) (*Logger,
 		holder:    holder,
 		colors:    colors,
 	}
+	if cfg.Accessible {
+		l.acc = &accessibleState{}
+		l.startStatus()
+	}
+	var h slog.Handler = &handler{l: l}
+	if cfg.WrapHandler != nil {
+		h = cfg.WrapHandler(h)
+	}
+	l.log = slog.New(h)
 
 	if restart {
 		// write restart separator (matches sectionRegex in web parser)
@@ -238,32 +264,18 @@ var separatorLine = strings.Repeat("-", 60)
 
 // Print writes a timestamped message to both file and stdout.
 func (l *Logger) Print(format string, args ...any) {
-	msg := fmt.Sprintf(format, args...)
-	timestamp := time.Now().Format(timestampFormat)
-
-	// write to file without color
-	l.writeFile("[%s] %s\n", timestamp, msg)
-
-	// write to stdout with color
-	phaseColor := l.colors.ForPhase(l.holder.Get())
-	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
-	msgStr := phaseColor.Sprint(msg)
-	l.writeStdout("%s %s\n", tsStr, msgStr)
+	l.emit(slog.LevelInfo, KindLine, fmt.Sprintf(format, args...))
 }
 
 // PrintRaw writes without timestamp (for streaming output).
 func (l *Logger) PrintRaw(format string, args ...any) {
-	msg := fmt.Sprintf(format, args...)
-	l.writeFile("%s", msg)
-	l.writeStdout("%s", msg)
+	l.emit(slog.LevelInfo, KindRaw, fmt.Sprintf(format, args...))
 }
 
 // PrintSection writes a section header without timestamp in yellow.
 // format: "\n--- {label} ---\n"
 func (l *Logger) PrintSection(section status.Section) {
-	header := fmt.Sprintf("\n--- %s ---\n", section.Label)
-	l.writeFile("%s", header)
-	l.writeStdout("%s", l.colors.Warn().Sprint(header))
+	l.emit(slog.LevelInfo, KindSection, section.Label)
 }
 
 // getTerminalWidth returns terminal width, using COLUMNS env var or syscall.
@@ -331,58 +343,30 @@ func wrapText(text string, width int) string {
 
 // PrintAligned writes text with timestamp on each line, suppressing empty lines.
 func (l *Logger) PrintAligned(text string) {
-	if text == "" {
-		return
-	}
-
 	// trim trailing newlines to avoid extra blank lines
 	text = strings.TrimRight(text, "\n")
 	if text == "" {
 		return
 	}
+	l.emit(slog.LevelInfo, KindAligned, text)
+}
 
-	phaseColor := l.colors.ForPhase(l.holder.Get())
-
-	// wrap text to terminal width
-	width := getTerminalWidth()
-
-	// split into lines, wrap each long line, then process
-	var lines []string
-	for line := range strings.SplitSeq(text, "\n") {
-		if len(line) > width {
-			wrapped := wrapText(line, width)
-			for wrappedLine := range strings.SplitSeq(wrapped, "\n") {
-				lines = append(lines, wrappedLine)
-			}
-		} else {
-			lines = append(lines, line)
-		}
-	}
-
-	for _, line := range lines {
-		if line == "" {
-			continue // skip empty lines
-		}
-
-		// add indent for list items
-		displayLine := formatListItem(line)
-
-		// timestamp each line
-		timestamp := time.Now().Format(timestampFormat)
-		tsPrefix := l.colors.Timestamp().Sprintf("[%s]", timestamp)
-		l.writeFile("[%s] %s\n", timestamp, displayLine)
-
-		// use red for signal lines
-		lineColor := phaseColor
-
-		// format signal lines nicely
-		if sig := extractSignal(line); sig != "" {
-			displayLine = sig
-			lineColor = l.colors.Signal()
-		}
+// diff line colors, fixed since diffs read the same in every color scheme
+var (
+	diffAddColor    = color.New(color.FgGreen)
+	diffDeleteColor = color.New(color.FgRed)
+	diffHunkColor   = color.New(color.FgCyan)
+	diffHeaderColor = color.New(color.Bold)
+)
 
-		l.writeStdout("%s %s\n", tsPrefix, lineColor.Sprint(displayLine))
+// PrintDiff writes a unified diff with timestamp on each line, colorizing added, removed,
+// hunk and file header lines on stdout. lines are not wrapped to keep the diff intact.
+func (l *Logger) PrintDiff(diff string) {
+	diff = strings.TrimRight(diff, "\n")
+	if diff == "" {
+		return
 	}
+	l.emit(slog.LevelInfo, KindDiff, diff)
 }
 
 // extractSignal extracts signal name from <<<RALPHEX:SIGNAL_NAME>>> format.
@@ -437,72 +421,31 @@ func isListItem(line string) bool {
 
 // Error writes an error message in red.
 func (l *Logger) Error(format string, args ...any) {
-	msg := fmt.Sprintf(format, args...)
-	timestamp := time.Now().Format(timestampFormat)
-
-	l.writeFile("[%s] ERROR: %s\n", timestamp, msg)
-
-	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
-	errStr := l.colors.Error().Sprintf("ERROR: %s", msg)
-	l.writeStdout("%s %s\n", tsStr, errStr)
+	l.emit(slog.LevelError, KindLine, fmt.Sprintf(format, args...))
 }
 
 // Warn writes a warning message in yellow.
 func (l *Logger) Warn(format string, args ...any) {
-	msg := fmt.Sprintf(format, args...)
-	timestamp := time.Now().Format(timestampFormat)
-
-	l.writeFile("[%s] WARN: %s\n", timestamp, msg)
-
-	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
-	warnStr := l.colors.Warn().Sprintf("WARN: %s", msg)
-	l.writeStdout("%s %s\n", tsStr, warnStr)
+	l.emit(slog.LevelWarn, KindLine, fmt.Sprintf(format, args...))
 }
 
 // LogQuestion logs a question and its options for plan creation mode.
 // format: QUESTION: <question>\n OPTIONS: <opt1>, <opt2>, ...
 func (l *Logger) LogQuestion(question string, options []string) {
-	timestamp := time.Now().Format(timestampFormat)
-
-	l.writeFile("[%s] QUESTION: %s\n", timestamp, question)
-	l.writeFile("[%s] OPTIONS: %s\n", timestamp, strings.Join(options, ", "))
-
-	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
-	questionStr := l.colors.Info().Sprintf("QUESTION: %s", question)
-	optionsStr := l.colors.Info().Sprintf("OPTIONS: %s", strings.Join(options, ", "))
-	l.writeStdout("%s %s\n", tsStr, questionStr)
-	l.writeStdout("%s %s\n", tsStr, optionsStr)
+	l.emit(slog.LevelInfo, KindQuestion, question, slog.Any("options", options))
 }
 
 // LogAnswer logs the user's answer for plan creation mode.
 // format: ANSWER: <answer>
 func (l *Logger) LogAnswer(answer string) {
-	timestamp := time.Now().Format(timestampFormat)
-
-	l.writeFile("[%s] ANSWER: %s\n", timestamp, answer)
-
-	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
-	answerStr := l.colors.Info().Sprintf("ANSWER: %s", answer)
-	l.writeStdout("%s %s\n", tsStr, answerStr)
+	l.emit(slog.LevelInfo, KindAnswer, answer)
 }
 
 // LogDraftReview logs the user's draft review action and optional feedback.
 // format: DRAFT REVIEW: <action>
 // if feedback is non-empty: FEEDBACK: <feedback>
 func (l *Logger) LogDraftReview(action, feedback string) {
-	timestamp := time.Now().Format(timestampFormat)
-
-	l.writeFile("[%s] DRAFT REVIEW: %s\n", timestamp, action)
-
-	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
-	actionStr := l.colors.Info().Sprintf("DRAFT REVIEW: %s", action)
-	l.writeStdout("%s %s\n", tsStr, actionStr)
-
-	if feedback != "" {
-		l.writeFile("[%s] FEEDBACK: %s\n", timestamp, feedback)
-		feedbackStr := l.colors.Info().Sprintf("FEEDBACK: %s", feedback)
-		l.writeStdout("%s %s\n", tsStr, feedbackStr)
-	}
+	l.emit(slog.LevelInfo, KindDraftReview, action, slog.String("feedback", feedback))
 }
 
 // LogDiffStats writes git diff stats to the progress file (file-only, no stdout).
@@ -511,9 +454,8 @@ func (l *Logger) LogDiffStats(files, additions, deletions int) {
 	if l.file == nil || files <= 0 {
 		return
 	}
-	timestamp := time.Now().Format(timestampFormat)
-	l.writeFile("[%s] DIFFSTATS: files=%d additions=%d deletions=%d\n",
-		timestamp, files, additions, deletions)
+	l.emit(slog.LevelInfo, KindDiffStats, "diff stats",
+		slog.Int("files", files), slog.Int("additions", additions), slog.Int("deletions", deletions))
 }
 
 // Elapsed returns formatted elapsed time since start.
@@ -528,6 +470,7 @@ func (l *Logger) Elapsed() string {
 
 // Close writes footer, releases the file lock, and closes the progress file.
 func (l *Logger) Close() error {
+	l.stopStatus()
 	if l.file == nil {
 		return nil
 	}
@@ -545,6 +488,13 @@ func (l *Logger) Close() error {
 	return nil
 }
 
+// emit sends a record of the given kind through the logger's slog handler chain,
+// tagged with the current execution phase
+func (l *Logger) emit(level slog.Level, kind, msg string, attrs ...slog.Attr) {
+	attrs = append(attrs, slog.String(KindKey, kind), slog.String(PhaseKey, string(l.holder.Get())))
+	l.log.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
 func (l *Logger) writeFile(format string, args ...any) {
 	if l.file != nil {
 		fmt.Fprintf(l.file, format, args...)
@@ -553,6 +503,7 @@ func (l *Logger) writeFile(format string, args ...any) {
 
 func (l *Logger) writeStdout(format string, args ...any) {
 	fmt.Fprintf(l.stdout, format, args...)
+	l.outputWritten("")
 }
 
 // isProgressCompleted checks if a progress file has a completion footer written by Close().
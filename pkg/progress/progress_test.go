@@ -2,6 +2,9 @@ package progress
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -47,6 +50,8 @@ func TestNewLogger(t *testing.T) {
 		{name: "full mode no plan", cfg: Config{Mode: "full", Branch: "main"}, wantBase: "progress.txt", wantDir: ".ralphex/progress"},
 		{name: "review mode no plan", cfg: Config{Mode: "review", Branch: "main"}, wantBase: "progress-review.txt", wantDir: ".ralphex/progress"},
 		{name: "codex-only mode no plan", cfg: Config{Mode: "codex-only", Branch: "main"}, wantBase: "progress-codex.txt", wantDir: ".ralphex/progress"},
+		{name: "custom dir", cfg: Config{PlanFile: "docs/plans/feature.md", Mode: "full", Branch: "main", Dir: "state/repo/progress"},
+			wantBase: "progress-feature.txt", wantDir: "state/repo/progress"},
 	}
 
 	for _, tc := range tests {
@@ -252,6 +257,73 @@ func TestLogger_PrintAligned(t *testing.T) {
 	assert.True(t, strings.HasSuffix(output, "\n"), "output should end with newline")
 }
 
+func TestLogger_Accessible(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	holder := &status.PhaseHolder{}
+	holder.Set(status.PhaseTask)
+	l, err := NewLogger(Config{Mode: "full", Branch: "test", Accessible: true}, testColors(), holder)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	var buf bytes.Buffer
+	l.stdout = &buf
+
+	l.PrintSection(status.NewGenericSection("task iteration 2"))
+	l.Print("working on %s", "task")
+	l.PrintAligned(strings.Repeat("long ", 40) + "\n<<<RALPHEX:ALL_TASKS_DONE>>>")
+	l.Warn("slow")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, "now: task iteration 2", lines[0])
+	assert.Equal(t, "working on task", lines[1])
+	assert.Equal(t, strings.Repeat("long ", 40), lines[2], "not wrapped")
+	assert.Equal(t, "signal: ALL_TASKS_DONE", lines[3])
+	assert.Equal(t, "WARN: slow", lines[4])
+
+	content, err := os.ReadFile(l.Path())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "--- task iteration 2 ---", "progress file unchanged")
+	assert.Contains(t, string(content), "] working on task")
+
+	now := time.Now()
+	assert.Empty(t, l.statusLine(now), "output within the interval")
+	line := l.statusLine(now.Add(statusInterval + 5*time.Second))
+	assert.True(t, strings.HasPrefix(line, "status: task phase, task iteration 2, running "), line)
+	assert.Contains(t, line, "no output for 1m5s")
+}
+
+func TestLogger_PrintDiff(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(origDir) }()
+
+	holder := &status.PhaseHolder{}
+	l, err := NewLogger(Config{Mode: "full", Branch: "test", NoColor: true}, testColors(), holder)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	var buf bytes.Buffer
+	l.stdout = &buf
+
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-old\n+new\n\n"
+	l.PrintDiff(diff)
+
+	content, err := os.ReadFile(l.Path())
+	require.NoError(t, err)
+	// each line timestamped, nothing wrapped or dropped, trailing newlines trimmed
+	assert.Regexp(t, `\] --- a/a\.go\n`, string(content))
+	assert.Regexp(t, `\] -old\n\[[^]]+\] \+new\n`, string(content))
+	assert.Equal(t, 6, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), "] @@ -1 +1 @@\n")
+
+	buf.Reset()
+	l.PrintDiff("")
+	assert.Empty(t, buf.String())
+}
+
 func TestLogger_PrintAligned_Empty(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
@@ -379,6 +451,46 @@ func TestLogger_ColorDisabled(t *testing.T) {
 	assert.Contains(t, output, "no color output")
 }
 
+func TestLogger_WrapHandler(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(origDir) }()
+
+	var appLog bytes.Buffer
+	appHandler := slog.NewJSONHandler(&appLog, &slog.HandlerOptions{Level: slog.LevelWarn})
+	holder := &status.PhaseHolder{}
+	holder.Set(status.PhaseReview)
+	l, err := NewLogger(Config{Mode: "full", Branch: "test", NoColor: true,
+		WrapHandler: func(h slog.Handler) slog.Handler { return slog.NewMultiHandler(h, appHandler) }}, testColors(), holder)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+	var buf bytes.Buffer
+	l.stdout = &buf
+
+	l.Print("info message")
+	l.Warn("warn message %d", 1)
+	l.Error("error message")
+
+	// progress file and stdout get everything
+	content, err := os.ReadFile(l.Path())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "info message")
+	assert.Contains(t, string(content), "WARN: warn message 1")
+	assert.Contains(t, buf.String(), "ERROR: error message")
+
+	// application handler gets records at its own level with kind and phase
+	lines := strings.Split(strings.TrimSpace(appLog.String()), "\n")
+	require.Len(t, lines, 2)
+	var rec map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+	assert.Equal(t, "WARN", rec["level"])
+	assert.Equal(t, "warn message 1", rec["msg"])
+	assert.Equal(t, KindLine, rec[KindKey])
+	assert.Equal(t, string(status.PhaseReview), rec[PhaseKey])
+	assert.Contains(t, lines[1], `"msg":"error message"`)
+}
+
 func TestLogger_Elapsed(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
@@ -1050,3 +1162,19 @@ func TestLogger_PlanModeFilename(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkLogger_PrintAligned(b *testing.B) {
+	b.Chdir(b.TempDir())
+	holder := &status.PhaseHolder{}
+	holder.Set(status.PhaseTask)
+	l, err := NewLogger(Config{Mode: "full", Branch: "bench", NoColor: true}, testColors(), holder)
+	require.NoError(b, err)
+	b.Cleanup(func() { _ = l.Close() })
+	l.stdout = io.Discard
+	text := strings.Repeat("editing pkg/foo/bar.go to wrap errors with context\n", 20)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		l.PrintAligned(text)
+	}
+}
@@ -0,0 +1,101 @@
+// Package seal encrypts run artifacts at rest: the resume checkpoint, prompt transcripts and the run history,
+// which hold code and agent responses that shouldn't sit in plaintext on shared build machines.
+// data is encrypted with AES-256-GCM, the key derived from a passphrase with PBKDF2. sealed data is a single
+// line of text, so line-based files like the run history can seal each line on its own and keep appending.
+package seal
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// prefix marks sealed data, the version selects the key derivation and cipher
+const prefix = "ralphex-sealed:v1:"
+
+// the salt is fixed, so a passphrase gives the same key on every machine and for every file. the nonce is random
+// per sealed value, which is what keeps equal plaintexts apart.
+const (
+	kdfSalt       = "ralphex artifacts"
+	kdfIterations = 600_000
+)
+
+// ErrNoKey is returned when opening sealed data without a key.
+var ErrNoKey = errors.New("encrypted with artifact_key, set artifact_key or RALPHEX_ARTIFACT_KEY to read it")
+
+// Sealer encrypts and decrypts artifacts with a key. a nil Sealer leaves data in plaintext.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// New creates a Sealer with the key derived from passphrase.
+func New(passphrase string) (*Sealer, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, []byte(kdfSalt), kdfIterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal returns plain encrypted as a single line of text without a line break, plain itself for a nil Sealer.
+func (s *Sealer) Seal(plain []byte) []byte {
+	if s == nil {
+		return plain
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	_, _ = rand.Read(nonce) // never fails, see crypto/rand.Read
+	sealed := s.aead.Seal(nonce, nonce, plain, nil)
+	res := make([]byte, len(prefix)+base64.StdEncoding.EncodedLen(len(sealed)))
+	copy(res, prefix)
+	base64.StdEncoding.Encode(res[len(prefix):], sealed)
+	return res
+}
+
+// Open returns the plaintext of data sealed with Seal. data that isn't sealed, e.g. written before encryption
+// was turned on, is returned as is. sealed data fails with ErrNoKey for a nil Sealer.
+func (s *Sealer) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	if s == nil {
+		return nil, ErrNoKey
+	}
+	data = bytes.TrimSpace(data)
+	sealed := make([]byte, base64.StdEncoding.DecodedLen(len(data)-len(prefix)))
+	n, err := base64.StdEncoding.Decode(sealed, data[len(prefix):])
+	if err != nil {
+		return nil, fmt.Errorf("decode sealed data: %w", err)
+	}
+	sealed = sealed[:n]
+	if len(sealed) < s.aead.NonceSize() {
+		return nil, errors.New("sealed data too short")
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("decrypt sealed data: wrong key or corrupted data")
+	}
+	return plain, nil
+}
+
+// IsSealed reports whether data was sealed with Seal.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(prefix))
+}
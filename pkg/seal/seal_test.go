@@ -0,0 +1,55 @@
+package seal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealer(t *testing.T) {
+	s, err := New("correct horse battery staple")
+	require.NoError(t, err)
+	plain := []byte("{\"step\": \"task\"}\nsecond line\n")
+
+	sealed := s.Seal(plain)
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, string(sealed), "task")
+	assert.False(t, bytes.ContainsAny(sealed, "\r\n"), "sealed data is a single line")
+	assert.NotEqual(t, sealed, s.Seal(plain), "each seal uses a new nonce")
+
+	got, err := s.Open(append(sealed, '\n'))
+	require.NoError(t, err)
+	assert.Equal(t, plain, got)
+
+	t.Run("plaintext passes through", func(t *testing.T) {
+		got, err := s.Open(plain)
+		require.NoError(t, err)
+		assert.Equal(t, plain, got)
+	})
+
+	t.Run("nil sealer", func(t *testing.T) {
+		var none *Sealer
+		assert.Equal(t, plain, none.Seal(plain))
+		_, err := none.Open(sealed)
+		require.ErrorIs(t, err, ErrNoKey)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		other, err := New("another passphrase")
+		require.NoError(t, err)
+		_, err = other.Open(sealed)
+		require.EqualError(t, err, "decrypt sealed data: wrong key or corrupted data")
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		_, err := s.Open([]byte(prefix + "not base64!"))
+		require.ErrorContains(t, err, "decode sealed data")
+		_, err = s.Open([]byte(prefix + "AAAA"))
+		require.EqualError(t, err, "sealed data too short")
+	})
+
+	_, err = New("")
+	require.EqualError(t, err, "empty passphrase")
+}
@@ -11,6 +11,7 @@ const (
 	Question   = "<<<RALPHEX:QUESTION>>>"
 	PlanReady  = "<<<RALPHEX:PLAN_READY>>>"
 	PlanDraft  = "<<<RALPHEX:PLAN_DRAFT>>>"
+	NeedsInput = "<<<RALPHEX:NEEDS_INPUT>>>"
 )
 
 // Phase represents execution phase for color coding.
@@ -0,0 +1,235 @@
+// Package telemetry keeps opt-in, anonymous aggregate usage stats: runs per mode, outcomes,
+// iteration counts and failure classes. no code, prompts, paths, branch names or identifiers
+// are recorded. stats are kept in the global config directory and, if an endpoint is configured,
+// sent there in batches. telemetry is off until enabled with "ralphex telemetry on".
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// StateFile is the name of the telemetry state file in the global config directory.
+const StateFile = "telemetry.json"
+
+// DefaultTimeout bounds a single report.
+const DefaultTimeout = 5 * time.Second
+
+// failure classes, the only detail kept about a failed run
+const (
+	FailureCanceled      = "canceled"
+	FailureTimeout       = "timeout"
+	FailureMaxIterations = "max_iterations"
+	FailureSignal        = "failed_signal" // agent reported FAILED
+	FailureOther         = "other"
+)
+
+// Stats are aggregate counters. they hold no per-run records, only sums.
+type Stats struct {
+	Runs       int            `json:"runs"`
+	Successes  int            `json:"successes"`
+	Iterations int            `json:"iterations"` // task iterations of all runs
+	Modes      map[string]int `json:"modes,omitempty"`
+	Failures   map[string]int `json:"failures,omitempty"` // by failure class
+}
+
+// State is the persisted telemetry state.
+type State struct {
+	Enabled bool  `json:"enabled"`
+	Pending Stats `json:"pending"` // collected since the last successful report
+	Total   Stats `json:"total"`
+}
+
+// Report is what is sent to the endpoint.
+type Report struct {
+	Version string `json:"version"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+	Stats   Stats  `json:"stats"`
+}
+
+// Run is the outcome of a single run, folded into the stats.
+type Run struct {
+	Mode       string
+	Iterations int
+	Err        error // nil for a successful run
+}
+
+// Params configures telemetry.
+type Params struct {
+	Dir      string        // directory of the state file, the state directory
+	Endpoint string        // URL the aggregate reports are POSTed to, empty to keep stats local only
+	Version  string        // ralphex version, sent with reports
+	Timeout  time.Duration // DefaultTimeout if 0
+}
+
+// Telemetry records runs and reports the aggregates.
+type Telemetry struct {
+	path     string
+	endpoint string
+	version  string
+	timeout  time.Duration
+	client   *http.Client
+}
+
+// New creates Telemetry from params.
+func New(p Params) *Telemetry {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Telemetry{path: filepath.Join(p.Dir, StateFile), endpoint: p.Endpoint, version: p.Version,
+		timeout: timeout, client: &http.Client{}}
+}
+
+// Endpoint returns the configured report endpoint, empty if stats are local only.
+func (t *Telemetry) Endpoint() string {
+	return t.endpoint
+}
+
+// Load reads the state. a missing state file means telemetry was never enabled.
+func (t *Telemetry) Load() (State, error) {
+	data, err := os.ReadFile(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, fmt.Errorf("read telemetry state: %w", err)
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return State{}, fmt.Errorf("parse telemetry state %s: %w", t.path, err)
+	}
+	return st, nil
+}
+
+// SetEnabled turns telemetry on or off. turning it off drops stats not reported yet.
+func (t *Telemetry) SetEnabled(enabled bool) error {
+	st, err := t.Load()
+	if err != nil {
+		return err
+	}
+	st.Enabled = enabled
+	if !enabled {
+		st.Pending = Stats{}
+	}
+	return t.save(st)
+}
+
+// Record adds a run to the stats and reports pending stats if an endpoint is configured.
+// does nothing unless telemetry is enabled. DO_NOT_TRACK set in the environment disables it as well.
+// a failed report keeps the stats pending for the next run.
+func (t *Telemetry) Record(ctx context.Context, r Run) error {
+	if t == nil || DoNotTrack() {
+		return nil
+	}
+	st, err := t.Load()
+	if err != nil || !st.Enabled {
+		return err
+	}
+	st.Pending.add(r)
+	st.Total.add(r)
+	if t.endpoint != "" {
+		if sendErr := t.send(ctx, st.Pending); sendErr != nil {
+			err = sendErr
+		} else {
+			st.Pending = Stats{}
+		}
+	}
+	if saveErr := t.save(st); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// Classify maps a run error to its failure class.
+func Classify(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return FailureCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return FailureTimeout
+	case strings.Contains(err.Error(), "max iterations"):
+		return FailureMaxIterations
+	case strings.Contains(err.Error(), "FAILED signal"):
+		return FailureSignal
+	default:
+		return FailureOther
+	}
+}
+
+// add folds a run into the stats
+func (s *Stats) add(r Run) {
+	s.Runs++
+	s.Iterations += r.Iterations
+	if s.Modes == nil {
+		s.Modes = map[string]int{}
+	}
+	s.Modes[r.Mode]++
+	if r.Err == nil {
+		s.Successes++
+		return
+	}
+	if s.Failures == nil {
+		s.Failures = map[string]int{}
+	}
+	s.Failures[Classify(r.Err)]++
+}
+
+// send POSTs the stats as a report to the endpoint
+func (t *Telemetry) send(ctx context.Context, s Stats) error {
+	body, err := json.Marshal(Report{Version: t.version, OS: runtime.GOOS, Arch: runtime.GOARCH, Stats: s})
+	if err != nil {
+		return fmt.Errorf("marshal telemetry report: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send telemetry report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("send telemetry report: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// save writes the state atomically, so an interrupted write doesn't lose the opt-in choice
+func (t *Telemetry) save(st State) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal telemetry state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o750); err != nil {
+		return fmt.Errorf("create telemetry state dir: %w", err)
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write telemetry state: %w", err)
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		return fmt.Errorf("write telemetry state: %w", err)
+	}
+	return nil
+}
+
+// DoNotTrack reports whether DO_NOT_TRACK in the environment asks to disable telemetry.
+func DoNotTrack() bool {
+	v := strings.TrimSpace(os.Getenv("DO_NOT_TRACK"))
+	return v != "" && v != "0" && !strings.EqualFold(v, "false")
+}
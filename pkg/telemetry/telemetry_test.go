@@ -0,0 +1,134 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelemetry_Record(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+
+	t.Run("disabled by default, nothing written", func(t *testing.T) {
+		dir := t.TempDir()
+		tel := New(Params{Dir: dir})
+		require.NoError(t, tel.Record(context.Background(), Run{Mode: "full", Iterations: 3}))
+		assert.NoFileExists(t, filepath.Join(dir, StateFile))
+		st, err := tel.Load()
+		require.NoError(t, err)
+		assert.False(t, st.Enabled)
+	})
+
+	t.Run("nil telemetry", func(t *testing.T) {
+		var tel *Telemetry
+		require.NoError(t, tel.Record(context.Background(), Run{Mode: "full"}))
+	})
+
+	t.Run("local only", func(t *testing.T) {
+		tel := New(Params{Dir: t.TempDir()})
+		require.NoError(t, tel.SetEnabled(true))
+		require.NoError(t, tel.Record(context.Background(), Run{Mode: "full", Iterations: 3}))
+		require.NoError(t, tel.Record(context.Background(), Run{Mode: "review", Err: errors.New("review failed (FAILED signal received)")}))
+
+		st, err := tel.Load()
+		require.NoError(t, err)
+		want := Stats{Runs: 2, Successes: 1, Iterations: 3, Modes: map[string]int{"full": 1, "review": 1},
+			Failures: map[string]int{FailureSignal: 1}}
+		assert.Equal(t, want, st.Total)
+		assert.Equal(t, want, st.Pending)
+
+		require.NoError(t, tel.SetEnabled(false))
+		st, err = tel.Load()
+		require.NoError(t, err)
+		assert.False(t, st.Enabled)
+		assert.Equal(t, Stats{}, st.Pending, "unreported stats dropped")
+		assert.Equal(t, 2, st.Total.Runs)
+	})
+
+	t.Run("reports pending stats", func(t *testing.T) {
+		var reports []Report
+		status := http.StatusOK
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var rep Report
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&rep))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			reports = append(reports, rep)
+			w.WriteHeader(status)
+		}))
+		defer srv.Close()
+
+		tel := New(Params{Dir: t.TempDir(), Endpoint: srv.URL, Version: "v1.2.3"})
+		require.NoError(t, tel.SetEnabled(true))
+
+		status = http.StatusInternalServerError
+		err := tel.Record(context.Background(), Run{Mode: "full", Iterations: 2})
+		require.ErrorContains(t, err, "unexpected status 500")
+		st, err := tel.Load()
+		require.NoError(t, err)
+		assert.Equal(t, 1, st.Pending.Runs, "kept pending after a failed report")
+
+		status = http.StatusOK
+		require.NoError(t, tel.Record(context.Background(), Run{Mode: "full", Iterations: 4, Err: context.Canceled}))
+		require.Len(t, reports, 2)
+		assert.Equal(t, "v1.2.3", reports[1].Version)
+		assert.NotEmpty(t, reports[1].OS)
+		assert.Equal(t, Stats{Runs: 2, Successes: 1, Iterations: 6, Modes: map[string]int{"full": 2},
+			Failures: map[string]int{FailureCanceled: 1}}, reports[1].Stats)
+
+		st, err = tel.Load()
+		require.NoError(t, err)
+		assert.Equal(t, Stats{}, st.Pending)
+		assert.Equal(t, 2, st.Total.Runs)
+	})
+
+	t.Run("do not track", func(t *testing.T) {
+		tel := New(Params{Dir: t.TempDir()})
+		require.NoError(t, tel.SetEnabled(true))
+		t.Setenv("DO_NOT_TRACK", "1")
+		require.NoError(t, tel.Record(context.Background(), Run{Mode: "full"}))
+		st, err := tel.Load()
+		require.NoError(t, err)
+		assert.Zero(t, st.Total.Runs)
+	})
+
+	t.Run("broken state file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, StateFile), []byte("{"), 0o600))
+		err := New(Params{Dir: dir}).Record(context.Background(), Run{Mode: "full"})
+		require.ErrorContains(t, err, "parse telemetry state")
+	})
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: fmt.Errorf("run: %w", context.Canceled), want: FailureCanceled},
+		{err: context.DeadlineExceeded, want: FailureTimeout},
+		{err: errors.New("max iterations (50) reached without completion"), want: FailureMaxIterations},
+		{err: errors.New("task execution failed after retry (FAILED signal received)"), want: FailureSignal},
+		{err: errors.New("git is not available"), want: FailureOther},
+	}
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			assert.Equal(t, tc.want, Classify(tc.err))
+		})
+	}
+}
+
+func TestDoNotTrack(t *testing.T) {
+	for v, want := range map[string]bool{"": false, "0": false, "false": false, "1": true, "true": true} {
+		t.Setenv("DO_NOT_TRACK", v)
+		assert.Equal(t, want, DoNotTrack(), "DO_NOT_TRACK=%q", v)
+	}
+}
@@ -0,0 +1,107 @@
+package verify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// binarySniffLen is how much of a file is looked at for NUL bytes, like git does to tell binary files
+const binarySniffLen = 8000
+
+// Artifact is a file flagged by the artifact check, binary or larger than the limit.
+type Artifact struct {
+	File   string
+	Size   int64
+	Binary bool
+}
+
+// String returns the file with what is wrong with it.
+func (a Artifact) String() string {
+	kb := (a.Size + 1023) / 1024
+	if a.Binary {
+		return fmt.Sprintf("%s (binary, %d KB)", a.File, kb)
+	}
+	return fmt.Sprintf("%s (%d KB)", a.File, kb)
+}
+
+// ArtifactCheck flags binary files and files above a size limit, build outputs and oversized fixtures
+// agents sometimes add to the tree.
+type ArtifactCheck struct {
+	Dir     string   // worktree root the file paths are relative to, current directory if empty
+	MaxSize int64    // files larger are flagged, 0 flags binary files only
+	Allow   []string // path.Match patterns of files never flagged, matched against the path and the file name
+}
+
+// Find returns the artifacts among files, in their order. files not existing or not regular are skipped,
+// a file which can't be read is reported as an error.
+func (c ArtifactCheck) Find(files []string) ([]Artifact, error) {
+	var res []Artifact
+	for _, f := range files {
+		if c.allowed(f) {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(c.Dir, f))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		binary, err := isBinary(filepath.Join(c.Dir, f))
+		if err != nil {
+			return nil, err
+		}
+		if binary || (c.MaxSize > 0 && info.Size() > c.MaxSize) {
+			res = append(res, Artifact{File: f, Size: info.Size(), Binary: binary})
+		}
+	}
+	return res, nil
+}
+
+// allowed reports if an allow pattern matches the path or the name of file.
+func (c ArtifactCheck) allowed(file string) bool {
+	file = filepath.ToSlash(file)
+	return slices.ContainsFunc(c.Allow, func(p string) bool {
+		if ok, _ := path.Match(p, file); ok {
+			return true
+		}
+		ok, _ := path.Match(p, path.Base(file))
+		return ok
+	})
+}
+
+// isBinary reports if the start of the file holds a NUL byte.
+func isBinary(name string) (bool, error) {
+	f, err := os.Open(name) //nolint:gosec // changed files of the worktree
+	if err != nil {
+		return false, fmt.Errorf("open %s: %w", name, err)
+	}
+	defer f.Close()
+	buf := make([]byte, binarySniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, fmt.Errorf("read %s: %w", name, err)
+	}
+	return bytes.IndexByte(buf[:n], 0) >= 0, nil
+}
+
+// ArtifactFeedback tells the agent which files to remove, empty if there are none.
+func ArtifactFeedback(artifacts []Artifact) string {
+	if len(artifacts) == 0 {
+		return ""
+	}
+	files := make([]string, 0, min(len(artifacts), maxDriftFiles)+1)
+	for _, a := range artifacts[:min(len(artifacts), maxDriftFiles)] {
+		files = append(files, "  "+a.String())
+	}
+	if len(artifacts) > maxDriftFiles {
+		files = append(files, fmt.Sprintf("  ... %d more files", len(artifacts)-maxDriftFiles))
+	}
+	return "artifact gate failed, these files are binary or too large to be added to the repository:\n" +
+		strings.Join(files, "\n") + "\nremove them from the tree and from git, generate them in tests or keep them " +
+		"out of the repository with .gitignore, then commit"
+}
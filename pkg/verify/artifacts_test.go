@@ -0,0 +1,56 @@
+package verify
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArtifactCheck_Find(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "testdata"), 0o750))
+	for name, data := range map[string][]byte{
+		"main.go":           []byte("package main\n"),
+		"bin/app":           {0x7f, 'E', 'L', 'F', 0, 0},
+		"testdata/big.json": bytes.Repeat([]byte("x"), 3000),
+		"testdata/img.png":  {0x89, 'P', 'N', 'G', 0},
+		"empty.txt":         {},
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+	}
+	files := []string{"main.go", "bin/app", "testdata/big.json", "testdata/img.png", "empty.txt", "deleted.bin", "testdata"}
+
+	tests := []struct {
+		name  string
+		check ArtifactCheck
+		want  []Artifact
+	}{
+		{name: "binary only", check: ArtifactCheck{Dir: dir},
+			want: []Artifact{{File: "bin/app", Size: 6, Binary: true}, {File: "testdata/img.png", Size: 5, Binary: true}}},
+		{name: "size limit", check: ArtifactCheck{Dir: dir, MaxSize: 1024},
+			want: []Artifact{{File: "bin/app", Size: 6, Binary: true}, {File: "testdata/big.json", Size: 3000},
+				{File: "testdata/img.png", Size: 5, Binary: true}}},
+		{name: "allowed by path and name", check: ArtifactCheck{Dir: dir, MaxSize: 1024, Allow: []string{"testdata/*.json", "*.png"}},
+			want: []Artifact{{File: "bin/app", Size: 6, Binary: true}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.check.Find(files)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestArtifactFeedback(t *testing.T) {
+	assert.Empty(t, ArtifactFeedback(nil))
+	assert.Equal(t, "artifact gate failed, these files are binary or too large to be added to the repository:\n"+
+		"  app (binary, 1 KB)\n  data.csv (2049 KB)\nremove them from the tree and from git, generate them in tests "+
+		"or keep them out of the repository with .gitignore, then commit",
+		ArtifactFeedback([]Artifact{{File: "app", Size: 10, Binary: true}, {File: "data.csv", Size: 2*1024*1024 + 1}}))
+}
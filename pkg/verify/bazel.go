@@ -0,0 +1,153 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+)
+
+// DefaultBazelCommand is the bazel binary used when Bazel.Command is empty, bazelisk installs it under this name too.
+const DefaultBazelCommand = "bazel"
+
+// bazel exit codes, see https://bazel.build/run/scripts#exit-codes
+const (
+	bazelExitPartial = 3 // --keep_going query with some unresolved targets
+	bazelExitNoTests = 4 // build succeeded but no test targets found
+)
+
+// maxDisplayTargets limits targets listed in the reported test command.
+const maxDisplayTargets = 10
+
+// workspaceFiles affect every target when changed, so the whole workspace is tested.
+var workspaceFiles = []string{"WORKSPACE", "WORKSPACE.bazel", "MODULE.bazel", "MODULE.bazel.lock", ".bazelrc", ".bazelversion"}
+
+// Bazel verifies a Bazel workspace by testing only targets affected by changed files.
+// changed files are mapped to test targets with "bazel query", making the gate cheap enough
+// to run between iterations in a monorepo.
+type Bazel struct {
+	Runner                                // Dir, Timeout, Env and MaxOutput apply to bazel commands, Commands is unused
+	Command      string                   // bazel binary, DefaultBazelCommand if empty
+	ChangedFiles func() ([]string, error) // changed paths relative to the workspace root
+}
+
+// Verify finds tests affected by changed files and runs them.
+// passes without running anything if no tests are affected.
+func (b *Bazel) Verify(ctx context.Context) Report {
+	files, err := b.ChangedFiles()
+	if err != nil {
+		return Report{Results: []Result{{Command: "list changed files", Err: err}}}
+	}
+
+	patterns, all := bazelScope(files)
+	switch {
+	case all:
+		return Report{Results: []Result{b.test(ctx, []string{"//..."})}}
+	case len(patterns) == 0:
+		return Report{Results: []Result{{Command: "bazel query", Output: "no changed files in bazel packages"}}}
+	}
+
+	query := b.query(ctx, patterns)
+	report := Report{Results: []Result{query.Result}}
+	if !query.Passed() {
+		return report
+	}
+	if len(query.targets) == 0 {
+		report.Results[0].Output = fmt.Sprintf("no tests affected by %d changed files", len(files))
+		return report
+	}
+	report.Results = append(report.Results, b.test(ctx, query.targets))
+	return report
+}
+
+// bazelScope maps changed files to query patterns. BUILD files select all targets of their package,
+// workspace-level files (WORKSPACE, MODULE.bazel, .bzl macros) select the whole workspace.
+func bazelScope(files []string) (patterns []string, all bool) {
+	for _, f := range files {
+		f = strings.TrimPrefix(f, "./")
+		name, dir := path.Base(f), path.Dir(f)
+		switch {
+		case strings.HasPrefix(f, "bazel-") || strings.Contains(f, `"`):
+			continue // output symlinks and paths the query language can't quote
+		case slices.Contains(workspaceFiles, f) || strings.HasSuffix(f, ".bzl"):
+			return nil, true
+		case name == "BUILD" || name == "BUILD.bazel":
+			if dir == "." {
+				dir = ""
+			}
+			patterns = append(patterns, "//"+dir+":all")
+		default:
+			patterns = append(patterns, f) // bazel resolves a source file path to its label
+		}
+	}
+	return patterns, false
+}
+
+// queryResult is the affected targets query outcome.
+type queryResult struct {
+	Result
+	targets []string
+}
+
+// query returns test targets depending on patterns. unresolved patterns, like deleted files
+// or files outside any package, are skipped with --keep_going.
+func (b *Bazel) query(ctx context.Context, patterns []string) queryResult {
+	quoted := make([]string, len(patterns))
+	for i, p := range patterns {
+		quoted[i] = `"` + p + `"`
+	}
+	expr := fmt.Sprintf("tests(rdeps(//..., set(%s)))", strings.Join(quoted, " "))
+	args := []string{"query", "--keep_going", "--output=label", expr}
+
+	var stdout bytes.Buffer
+	res := b.runCmd(ctx, b.command()+" query", func(ctx context.Context) *exec.Cmd {
+		cmd := exec.CommandContext(ctx, b.command(), args...)
+		cmd.Stdout = &stdout // labels only, stderr goes to the result output
+		return cmd
+	})
+	var exitErr *exec.ExitError
+	if errors.As(res.Err, &exitErr) && exitErr.ExitCode() == bazelExitPartial {
+		res.Err = nil
+	}
+	if !res.Passed() {
+		return queryResult{Result: res}
+	}
+	var targets []string
+	for line := range strings.SplitSeq(stdout.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			targets = append(targets, line)
+		}
+	}
+	res.Output = fmt.Sprintf("%d affected test targets", len(targets))
+	return queryResult{Result: res, targets: targets}
+}
+
+// test runs bazel test for targets. a "no test targets" exit is not a failure.
+func (b *Bazel) test(ctx context.Context, targets []string) Result {
+	args := append([]string{"test", "--build_tests_only", "--"}, targets...)
+	display := b.command() + " " + strings.Join(args, " ")
+	if len(targets) > maxDisplayTargets {
+		display = fmt.Sprintf("%s test --build_tests_only -- %s ... (%d targets)",
+			b.command(), strings.Join(targets[:maxDisplayTargets], " "), len(targets))
+	}
+	res := b.runCmd(ctx, display, func(ctx context.Context) *exec.Cmd {
+		return exec.CommandContext(ctx, b.command(), args...)
+	})
+	var exitErr *exec.ExitError
+	if errors.As(res.Err, &exitErr) && exitErr.ExitCode() == bazelExitNoTests {
+		res.Err = nil
+	}
+	return res
+}
+
+// command returns the bazel binary to run.
+func (b *Bazel) command() string {
+	if b.Command != "" {
+		return b.Command
+	}
+	return DefaultBazelCommand
+}
@@ -0,0 +1,134 @@
+package verify
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBazelScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		want    []string
+		wantAll bool
+	}{
+		{name: "source files", files: []string{"pkg/a/a.go", "./lib/b.cc"}, want: []string{"pkg/a/a.go", "lib/b.cc"}},
+		{name: "build files select package", files: []string{"pkg/a/BUILD.bazel", "BUILD"}, want: []string{"//pkg/a:all", "//:all"}},
+		{name: "module file selects everything", files: []string{"pkg/a/a.go", "MODULE.bazel"}, wantAll: true},
+		{name: "macro selects everything", files: []string{"tools/defs.bzl"}, wantAll: true},
+		{name: "output symlinks skipped", files: []string{"bazel-out/x", `odd"name.go`}},
+		{name: "no files"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, all := bazelScope(tc.files)
+			assert.Equal(t, tc.wantAll, all)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+// fakeBazel writes a bazel script answering queries with queryOut and exiting with the given codes.
+// returns the script path and the file its arguments are logged to, one invocation per line.
+func fakeBazel(t *testing.T, queryOut string, queryExit, testExit int) (script, argsLog string) {
+	t.Helper()
+	skipOnWindows(t)
+	dir := t.TempDir()
+	argsLog = filepath.Join(dir, "args.log")
+	script = filepath.Join(dir, "bazel")
+	body := `#!/bin/sh
+echo "$@" >> '` + argsLog + `'
+case "$1" in
+query) printf '` + queryOut + `'; echo "Loading: 0 packages loaded" >&2; exit ` + strconv.Itoa(queryExit) + ` ;;
+test) echo "//pkg/a:a_test FAILED in 0.1s" ; exit ` + strconv.Itoa(testExit) + ` ;;
+esac
+`
+	require.NoError(t, os.WriteFile(script, []byte(body), 0o700)) //nolint:gosec // test script must be executable
+	return script, argsLog
+}
+
+func readLog(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path) //nolint:gosec // test file
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestBazel_Verify(t *testing.T) {
+	changed := func(files ...string) func() ([]string, error) {
+		return func() ([]string, error) { return files, nil }
+	}
+
+	t.Run("tests affected targets", func(t *testing.T) {
+		script, argsLog := fakeBazel(t, `//pkg/a:a_test\n//pkg/b:b_test\n`, 3, 0)
+		b := &Bazel{Command: script, ChangedFiles: changed("pkg/a/a.go", "pkg/b/BUILD.bazel")}
+		report := b.Verify(t.Context())
+		require.True(t, report.Passed(), report.Feedback())
+		require.Len(t, report.Results, 2)
+		assert.Equal(t, "2 affected test targets", report.Results[0].Output)
+
+		calls := readLog(t, argsLog)
+		require.Len(t, calls, 2)
+		assert.Equal(t, `query --keep_going --output=label tests(rdeps(//..., set("pkg/a/a.go" "//pkg/b:all")))`, calls[0])
+		assert.Equal(t, "test --build_tests_only -- //pkg/a:a_test //pkg/b:b_test", calls[1])
+	})
+
+	t.Run("test failure", func(t *testing.T) {
+		script, _ := fakeBazel(t, `//pkg/a:a_test\n`, 0, 3)
+		b := &Bazel{Command: script, ChangedFiles: changed("pkg/a/a.go")}
+		report := b.Verify(t.Context())
+		res, failed := report.Failure()
+		require.True(t, failed)
+		assert.Equal(t, script+" test --build_tests_only -- //pkg/a:a_test", res.Command)
+		assert.Contains(t, res.Output, "//pkg/a:a_test FAILED")
+	})
+
+	t.Run("no affected tests", func(t *testing.T) {
+		script, argsLog := fakeBazel(t, ``, 0, 1)
+		b := &Bazel{Command: script, ChangedFiles: changed("docs/readme.md")}
+		report := b.Verify(t.Context())
+		require.True(t, report.Passed())
+		assert.Equal(t, "no tests affected by 1 changed files", report.Results[0].Output)
+		assert.Len(t, readLog(t, argsLog), 1, "only the query runs")
+	})
+
+	t.Run("query failure", func(t *testing.T) {
+		script, _ := fakeBazel(t, ``, 2, 0)
+		b := &Bazel{Command: script, ChangedFiles: changed("pkg/a/a.go")}
+		report := b.Verify(t.Context())
+		require.False(t, report.Passed())
+		assert.Len(t, report.Results, 1)
+		assert.Contains(t, report.Results[0].Output, "Loading: 0 packages loaded")
+	})
+
+	t.Run("workspace change tests everything", func(t *testing.T) {
+		script, argsLog := fakeBazel(t, ``, 0, 4)
+		b := &Bazel{Command: script, ChangedFiles: changed("MODULE.bazel")}
+		report := b.Verify(t.Context())
+		require.True(t, report.Passed(), "no test targets is not a failure")
+		assert.Equal(t, []string{"test --build_tests_only -- //..."}, readLog(t, argsLog))
+	})
+
+	t.Run("nothing changed", func(t *testing.T) {
+		script, argsLog := fakeBazel(t, ``, 0, 0)
+		b := &Bazel{Command: script, ChangedFiles: changed()}
+		require.True(t, b.Verify(t.Context()).Passed())
+		assert.Empty(t, readLog(t, argsLog))
+	})
+
+	t.Run("changed files error", func(t *testing.T) {
+		b := &Bazel{ChangedFiles: func() ([]string, error) { return nil, errors.New("no git") }}
+		report := b.Verify(t.Context())
+		assert.Equal(t, "Command: list changed files\nError: no git\nOutput:\n", report.Feedback())
+	})
+}
@@ -0,0 +1,74 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultContainerCommand is the container CLI used when Runner.ContainerCommand is empty.
+// podman accepts the same arguments.
+const DefaultContainerCommand = "docker"
+
+// containerSeq makes container names unique within the process
+var containerSeq atomic.Int64
+
+// containerCmd wraps cmd to run inside r.Image with the working directory mounted at the same path,
+// so file paths in the output match the host. on unix the container runs as the current user
+// to keep files it writes owned by them.
+func (r *Runner) containerCmd(ctx context.Context, cmd *exec.Cmd, name string) (*exec.Cmd, error) {
+	dir, err := filepath.Abs(r.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve working directory: %w", err)
+	}
+	workdir := filepath.ToSlash(dir)
+	if runtime.GOOS == "windows" {
+		workdir = "/workspace" // linux containers can't use windows paths
+	}
+
+	args := []string{"run", "--rm", "--init", "--name", name, "-v", dir + ":" + workdir, "-w", workdir}
+	if runtime.GOOS != "windows" {
+		// HOME for tool caches, an arbitrary uid has no writable home in most images
+		args = append(args, "--user", strconv.Itoa(os.Getuid())+":"+strconv.Itoa(os.Getgid()), "-e", "HOME=/tmp")
+	}
+	if r.Services != nil {
+		if network := r.Services.network(); network != "" {
+			args = append(args, "--network", network) // reach services by name
+		}
+	}
+	for _, kv := range r.env() {
+		args = append(args, "-e", kv)
+	}
+	args = append(args, r.Image)
+	args = append(args, cmd.Args...)
+
+	res := exec.CommandContext(ctx, r.containerCommand(), args...) //nolint:gosec // configured container command
+	res.Stdout = cmd.Stdout
+	return res, nil
+}
+
+// removeContainer force-removes a container left running after its client was killed on timeout or cancel.
+func (r *Runner) removeContainer(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_ = exec.CommandContext(ctx, r.containerCommand(), "rm", "-f", name).Run() //nolint:gosec // configured container command
+}
+
+// containerCommand returns the container CLI to run.
+func (r *Runner) containerCommand() string {
+	if r.ContainerCommand != "" {
+		return r.ContainerCommand
+	}
+	return DefaultContainerCommand
+}
+
+// containerName returns a unique name for a verification container.
+func containerName() string {
+	return fmt.Sprintf("ralphex-verify-%d-%d", os.Getpid(), containerSeq.Add(1))
+}
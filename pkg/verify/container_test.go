@@ -0,0 +1,76 @@
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeContainerCLI writes a docker-like script logging its arguments, one invocation per line.
+// "run" executes the command following an image argument starting with "img" or "golang" on the host.
+func fakeContainerCLI(t *testing.T) (script, argsLog string) {
+	t.Helper()
+	skipOnWindows(t)
+	dir := t.TempDir()
+	argsLog = filepath.Join(dir, "args.log")
+	script = filepath.Join(dir, "docker")
+	body := `#!/bin/sh
+echo "$@" >> '` + argsLog + `'
+[ "$1" = "run" ] || exit 0
+while true; do case "$1" in img*|golang*) break ;; esac; shift; done
+shift
+exec "$@"
+`
+	require.NoError(t, os.WriteFile(script, []byte(body), 0o700)) //nolint:gosec // test script must be executable
+	return script, argsLog
+}
+
+func TestRunner_Verify_Container(t *testing.T) {
+	t.Run("runs commands in the image", func(t *testing.T) {
+		script, argsLog := fakeContainerCLI(t)
+		dir := t.TempDir()
+		r := &Runner{Dir: dir, Image: "img:1", ContainerCommand: script, Env: []string{"DB_URL=postgres://x"},
+			Commands: []string{"echo in container; exit 2"}}
+		report := r.Verify(t.Context())
+		res, failed := report.Failure()
+		require.True(t, failed)
+		assert.Equal(t, "in container\n", res.Output)
+		assert.Equal(t, "echo in container; exit 2", res.Command, "reported as configured")
+
+		calls := readLog(t, argsLog)
+		require.Len(t, calls, 1)
+		user := strconv.Itoa(os.Getuid()) + ":" + strconv.Itoa(os.Getgid())
+		assert.Regexp(t, `^run --rm --init --name ralphex-verify-\d+-\d+ `, calls[0])
+		assert.True(t, strings.HasSuffix(calls[0], " -v "+dir+":"+dir+" -w "+dir+" --user "+user+
+			" -e HOME=/tmp -e DB_URL=postgres://x img:1 sh -c echo in container; exit 2"), calls[0])
+	})
+
+	t.Run("timed out container is removed", func(t *testing.T) {
+		script, argsLog := fakeContainerCLI(t)
+		r := &Runner{Image: "img:1", ContainerCommand: script, Timeout: 100 * time.Millisecond, Commands: []string{"sleep 5"}}
+		report := r.Verify(t.Context())
+		require.False(t, report.Passed())
+		assert.EqualError(t, report.Results[0].Err, "timed out after 100ms")
+
+		calls := readLog(t, argsLog)
+		require.Len(t, calls, 2)
+		name := strings.Fields(calls[0])[4]
+		assert.Equal(t, "rm -f "+name, calls[1])
+	})
+
+	t.Run("bazel commands run in the image", func(t *testing.T) {
+		script, argsLog := fakeContainerCLI(t)
+		b := &Bazel{Runner: Runner{Image: "img:1", ContainerCommand: script}, Command: "true",
+			ChangedFiles: func() ([]string, error) { return []string{"WORKSPACE"}, nil }}
+		require.True(t, b.Verify(t.Context()).Passed())
+		calls := readLog(t, argsLog)
+		require.Len(t, calls, 1)
+		assert.True(t, strings.HasSuffix(calls[0], " img:1 true test --build_tests_only -- //..."), calls[0])
+	})
+}
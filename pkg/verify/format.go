@@ -0,0 +1,114 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FormatGate runs formatters on files changed by the agent and reports the files they reformat.
+// formatters are commands rewriting the files given as arguments in place, like "gofmt -w".
+type FormatGate struct {
+	Dir        string            // worktree root the file paths are relative to, current directory if empty
+	Shell      string            // shell running the formatters, platform default if empty
+	Formatters map[string]string // formatter commands by file extension without the dot, e.g. "go"
+	Fix        bool              // keep the formatted files, otherwise they are restored
+}
+
+// Check runs the formatters on files and returns the files they changed, sorted, and the problems of formatters
+// which failed. files without a formatter or not existing are skipped. only cancellation of ctx is returned as error.
+func (g FormatGate) Check(ctx context.Context, files []string) (changed, problems []string, err error) {
+	byExt := map[string][]string{}
+	for _, f := range files {
+		ext := strings.TrimPrefix(filepath.Ext(f), ".")
+		if _, ok := g.Formatters[ext]; !ok {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(g.Dir, f)); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		byExt[ext] = append(byExt[ext], f)
+	}
+	exts := make([]string, 0, len(byExt))
+	for ext := range byExt {
+		exts = append(exts, ext)
+	}
+	slices.Sort(exts)
+
+	for _, ext := range exts {
+		extChanged, err := g.format(ctx, g.Formatters[ext], byExt[ext])
+		if ctx.Err() != nil {
+			return nil, nil, fmt.Errorf("format %s files: %w", ext, ctx.Err())
+		}
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		changed = append(changed, extChanged...)
+	}
+	slices.Sort(changed)
+	return changed, problems, nil
+}
+
+// format runs command on files and returns the files it changed. without Fix the files are restored.
+func (g FormatGate) format(ctx context.Context, command string, files []string) ([]string, error) {
+	before := make([][]byte, len(files))
+	for i, f := range files {
+		data, err := os.ReadFile(filepath.Join(g.Dir, f)) //nolint:gosec // changed files of the worktree
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", f, err)
+		}
+		before[i] = data
+	}
+	if !g.Fix {
+		defer func() {
+			for i, f := range files {
+				_ = os.WriteFile(filepath.Join(g.Dir, f), before[i], 0o644) //nolint:gosec // source files are not secret
+			}
+		}()
+	}
+
+	args := make([]string, len(files))
+	for i, f := range files {
+		args[i] = QuoteArg(g.Shell, f)
+	}
+	_, runErr := Output(ctx, g.Shell, g.Dir, command+" "+strings.Join(args, " "), nil)
+
+	var changed []string
+	for i, f := range files {
+		after, err := os.ReadFile(filepath.Join(g.Dir, f)) //nolint:gosec // changed files of the worktree
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return changed, fmt.Errorf("read %s: %w", f, err)
+		}
+		if !bytes.Equal(before[i], after) {
+			changed = append(changed, f)
+		}
+	}
+	return changed, runErr
+}
+
+// FormatFeedback tells the agent which files are not formatted, empty if there are none and no problems.
+func FormatFeedback(unformatted, problems []string) string {
+	if len(unformatted) == 0 && len(problems) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("formatting gate failed:")
+	if len(unformatted) > 0 {
+		b.WriteString("\n- these files are not formatted, run the formatter on them and commit the result:")
+		for _, f := range unformatted[:min(len(unformatted), maxDriftFiles)] {
+			fmt.Fprintf(&b, "\n  %s", f)
+		}
+		if len(unformatted) > maxDriftFiles {
+			fmt.Fprintf(&b, "\n  ... %d more files", len(unformatted)-maxDriftFiles)
+		}
+	}
+	for _, p := range problems {
+		b.WriteString("\n- " + p)
+	}
+	return b.String()
+}
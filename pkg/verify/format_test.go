@@ -0,0 +1,95 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteArg(t *testing.T) {
+	tests := []struct {
+		shell, arg, want string
+	}{
+		{shell: "sh", arg: "a b.go", want: "'a b.go'"},
+		{shell: "bash", arg: "it's.go", want: `'it'\''s.go'`},
+		{shell: "cmd", arg: `dir\a b.go`, want: `"dir\a b.go"`},
+		{shell: "pwsh", arg: "it's.go", want: "'it''s.go'"},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, QuoteArg(tc.shell, tc.arg), tc.shell)
+	}
+}
+
+func TestFormatGate_Check(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("tr not in PATH")
+	}
+	// upper-cases the files given as arguments
+	const upper = `sh -c 'for f do tr a-z A-Z < "$f" > "$f.tmp" && mv "$f.tmp" "$f"; done' fmt`
+	setup := func(t *testing.T) string {
+		t.Helper()
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub dir"), 0o750))
+		for name, content := range map[string]string{"a.go": "lower\n", "B.go": "UPPER\n", "sub dir/c.md": "doc\n", "d.txt": "text\n"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+		}
+		return dir
+	}
+	readFile := func(t *testing.T, name string) string {
+		t.Helper()
+		data, err := os.ReadFile(name) //nolint:gosec // test file
+		require.NoError(t, err)
+		return string(data)
+	}
+	files := []string{"a.go", "B.go", "sub dir/c.md", "d.txt", "gone.go"}
+
+	t.Run("report", func(t *testing.T) {
+		dir := setup(t)
+		g := FormatGate{Dir: dir, Formatters: map[string]string{"go": upper, "md": upper}}
+		changed, problems, err := g.Check(context.Background(), files)
+		require.NoError(t, err)
+		assert.Empty(t, problems)
+		assert.Equal(t, []string{"a.go", "sub dir/c.md"}, changed)
+		assert.Equal(t, "lower\n", readFile(t, filepath.Join(dir, "a.go")), "restored")
+		assert.Equal(t, "doc\n", readFile(t, filepath.Join(dir, "sub dir", "c.md")), "restored")
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		dir := setup(t)
+		g := FormatGate{Dir: dir, Formatters: map[string]string{"go": upper}, Fix: true}
+		changed, problems, err := g.Check(context.Background(), files)
+		require.NoError(t, err)
+		assert.Empty(t, problems)
+		assert.Equal(t, []string{"a.go"}, changed)
+		assert.Equal(t, "LOWER\n", readFile(t, filepath.Join(dir, "a.go")))
+		assert.Equal(t, "text\n", readFile(t, filepath.Join(dir, "d.txt")), "no formatter")
+	})
+
+	t.Run("formatter fails", func(t *testing.T) {
+		dir := setup(t)
+		g := FormatGate{Dir: dir, Formatters: map[string]string{"go": "exit 3;", "md": upper}}
+		changed, problems, err := g.Check(context.Background(), files)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sub dir/c.md"}, changed)
+		require.Len(t, problems, 1)
+		assert.Contains(t, problems[0], "exit status 3")
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, err := FormatGate{Dir: setup(t), Formatters: map[string]string{"go": upper}}.Check(ctx, files)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestFormatFeedback(t *testing.T) {
+	assert.Empty(t, FormatFeedback(nil, nil))
+	assert.Equal(t, "formatting gate failed:\n- these files are not formatted, run the formatter on them and commit "+
+		"the result:\n  a.go\n  b.go\n- run \"gofmt -w\": err", FormatFeedback([]string{"a.go", "b.go"}, []string{`run "gofmt -w": err`}))
+}
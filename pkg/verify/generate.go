@@ -0,0 +1,171 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultGenerateCommand regenerates the code of a Go project when no generator commands are configured
+const DefaultGenerateCommand = "go generate ./..."
+
+// maxDriftFiles limits the files reported by the generated code gate
+const maxDriftFiles = 20
+
+// GenerateGate re-runs code generators and reports the files they change: generated code edited by hand,
+// or sources changed without regenerating. it needs a git worktree, the changes of the generators are undone.
+type GenerateGate struct {
+	Dir      string   // worktree root, current directory if empty
+	Shell    string   // shell running the generator commands, platform default if empty
+	Commands []string // generator commands, DefaultGenerateCommand if empty
+	Git      string   // git binary used to find and restore the changed files, "git" from PATH if empty
+}
+
+// Check runs the generators and returns feedback for the agent, empty if the generated files are up to date.
+// failing generators are feedback, git failures and cancellation of ctx are returned as error.
+func (g GenerateGate) Check(ctx context.Context) (string, error) {
+	before, err := g.dirtyFiles(ctx)
+	if err != nil {
+		return "", err
+	}
+	// files changed already can't be restored from git, keep their content
+	snapshot := make(map[string][]byte, len(before))
+	for p := range before {
+		snapshot[p] = g.readFile(p)
+	}
+
+	commands := g.Commands
+	if len(commands) == 0 {
+		commands = []string{DefaultGenerateCommand}
+	}
+	var problems []string
+	for _, c := range commands {
+		if _, err := Output(ctx, g.Shell, g.Dir, c, nil); err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			problems = append(problems, err.Error())
+		}
+	}
+
+	after, err := g.dirtyFiles(context.WithoutCancel(ctx))
+	if err != nil {
+		return "", err
+	}
+	var drifted []string
+	for p := range after {
+		if prev, ok := snapshot[p]; !ok || !bytes.Equal(prev, g.readFile(p)) {
+			drifted = append(drifted, p)
+		}
+	}
+	for p := range snapshot {
+		if _, ok := after[p]; !ok {
+			drifted = append(drifted, p) // reverted to the committed content
+		}
+	}
+	slices.Sort(drifted)
+	if err := g.restore(ctx, drifted, snapshot, after); err != nil {
+		return "", err
+	}
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("run generators: %w", ctx.Err())
+	}
+
+	if len(drifted) > 0 {
+		files := drifted[:min(len(drifted), maxDriftFiles)]
+		msg := fmt.Sprintf("generated files differ from what %s produces. don't edit generated files, change their "+
+			"sources, regenerate and commit the result:\n  %s", strings.Join(commands, ", "), strings.Join(files, "\n  "))
+		if len(drifted) > maxDriftFiles {
+			msg += fmt.Sprintf("\n  ... %d more files", len(drifted)-maxDriftFiles)
+		}
+		problems = append(problems, msg)
+	}
+	if len(problems) == 0 {
+		return "", nil
+	}
+	return "generated code gate failed:\n- " + strings.Join(problems, "\n- "), nil
+}
+
+// dirtyFiles returns the files of the worktree differing from HEAD or untracked, with their git status code.
+func (g GenerateGate) dirtyFiles(ctx context.Context) (map[string]string, error) {
+	out, err := g.git(ctx, "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]string{}
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		if len(e) < 4 {
+			continue
+		}
+		res[e[3:]] = e[:2]
+		if e[0] == 'R' || e[0] == 'C' {
+			i++ // the source path of a rename or copy follows
+		}
+	}
+	return res, nil
+}
+
+// restore undoes the changes of the generators to files: files changed before get their content back,
+// files created by the generators are removed and the rest is checked out from git.
+func (g GenerateGate) restore(ctx context.Context, files []string, snapshot map[string][]byte, after map[string]string) error {
+	var checkout []string
+	for _, p := range files {
+		path := filepath.Join(g.Dir, p)
+		prev, changed := snapshot[p]
+		switch {
+		case changed && prev == nil:
+			_ = os.Remove(path)
+		case changed:
+			if err := os.WriteFile(path, prev, 0o644); err != nil { //nolint:gosec // worktree file
+				return fmt.Errorf("restore %s: %w", p, err)
+			}
+		case after[p] == "??":
+			_ = os.Remove(path)
+		default:
+			checkout = append(checkout, p)
+		}
+	}
+	if len(checkout) == 0 {
+		return nil
+	}
+	if _, err := g.git(context.WithoutCancel(ctx), append([]string{"checkout", "--"}, checkout...)...); err != nil {
+		return fmt.Errorf("restore generated files: %w", err)
+	}
+	return nil
+}
+
+// readFile returns the content of a worktree file, nil if it doesn't exist.
+func (g GenerateGate) readFile(p string) []byte {
+	data, err := os.ReadFile(filepath.Join(g.Dir, p)) //nolint:gosec // worktree file
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// git runs a git command in the worktree and returns its output.
+func (g GenerateGate) git(ctx context.Context, args ...string) ([]byte, error) {
+	gitCmd := g.Git
+	if gitCmd == "" {
+		gitCmd = "git"
+	}
+	cmd := exec.CommandContext(ctx, gitCmd, args...)
+	cmd.Dir = g.Dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
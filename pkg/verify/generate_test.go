@@ -0,0 +1,113 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateGate_Check(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not in PATH")
+	}
+	// repo with a generated file committed, gen.txt is what the generator writes to it
+	setup := func(t *testing.T) string {
+		t.Helper()
+		dir := t.TempDir()
+		for _, args := range [][]string{{"init", "-q"}, {"config", "user.email", "test@example.com"}, {"config", "user.name", "test"}} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = dir
+			require.NoError(t, cmd.Run())
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "gen.txt"), []byte("generated\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "out.go"), []byte("generated\n"), 0o600))
+		cmd := exec.Command("git", "add", ".")
+		cmd.Dir = dir
+		require.NoError(t, cmd.Run())
+		cmd = exec.Command("git", "commit", "-q", "-m", "init")
+		cmd.Dir = dir
+		require.NoError(t, cmd.Run())
+		return dir
+	}
+	const generator = "cp gen.txt out.go"
+	read := func(t *testing.T, path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path) //nolint:gosec // test file
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("up to date", func(t *testing.T) {
+		dir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("uncommitted\n"), 0o600))
+		feedback, err := GenerateGate{Dir: dir, Commands: []string{generator}}.Check(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+	})
+
+	t.Run("generated file edited by hand", func(t *testing.T) {
+		dir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "out.go"), []byte("hand edit\n"), 0o600))
+		feedback, err := GenerateGate{Dir: dir, Commands: []string{generator}}.Check(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "generated code gate failed:\n- generated files differ from what cp gen.txt out.go produces. "+
+			"don't edit generated files, change their sources, regenerate and commit the result:\n  out.go", feedback)
+		assert.Equal(t, "hand edit\n", read(t, filepath.Join(dir, "out.go")), "uncommitted edit restored")
+	})
+
+	t.Run("committed hand edit and new file", func(t *testing.T) {
+		dir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "out.go"), []byte("hand edit\n"), 0o600))
+		cmd := exec.Command("git", "commit", "-q", "-am", "edit")
+		cmd.Dir = dir
+		require.NoError(t, cmd.Run())
+		feedback, err := GenerateGate{Dir: dir, Commands: []string{generator, "echo x > extra_gen.go"}}.Check(context.Background())
+		require.NoError(t, err)
+		assert.Contains(t, feedback, "produces. don't edit generated files")
+		assert.Contains(t, feedback, "\n  extra_gen.go\n  out.go")
+		assert.Equal(t, "hand edit\n", read(t, filepath.Join(dir, "out.go")), "checked out again")
+		assert.NoFileExists(t, filepath.Join(dir, "extra_gen.go"))
+	})
+
+	t.Run("generator fails", func(t *testing.T) {
+		dir := setup(t)
+		feedback, err := GenerateGate{Dir: dir, Commands: []string{"exit 3"}}.Check(context.Background())
+		require.NoError(t, err)
+		assert.Contains(t, feedback, "generated code gate failed:\n- run \"exit 3\": exit status 3")
+	})
+
+	t.Run("configured git command", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("shell script wrapper")
+		}
+		dir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "out.go"), []byte("hand edit\n"), 0o600))
+		// wrapper logging its calls, proves the gate runs the configured binary and not git from PATH
+		bin := t.TempDir()
+		calls := filepath.Join(bin, "calls.log")
+		wrapper := filepath.Join(bin, "git-wrapper")
+		script := "#!/bin/sh\necho \"$1\" >> " + calls + "\nexec git \"$@\"\n"
+		require.NoError(t, os.WriteFile(wrapper, []byte(script), 0o700)) //nolint:gosec // executable test script
+		feedback, err := GenerateGate{Dir: dir, Commands: []string{generator}, Git: wrapper}.Check(context.Background())
+		require.NoError(t, err)
+		assert.Contains(t, feedback, "\n  out.go")
+		assert.Equal(t, "hand edit\n", read(t, filepath.Join(dir, "out.go")))
+		assert.Equal(t, "status\nstatus\n", read(t, calls))
+	})
+
+	t.Run("missing git command", func(t *testing.T) {
+		_, err := GenerateGate{Dir: setup(t), Commands: []string{generator}, Git: "/nonexistent/git"}.Check(context.Background())
+		require.ErrorContains(t, err, "git status")
+	})
+
+	t.Run("not a git worktree", func(t *testing.T) {
+		_, err := GenerateGate{Dir: t.TempDir(), Commands: []string{generator}}.Check(context.Background())
+		require.ErrorContains(t, err, "git status")
+	})
+}
@@ -0,0 +1,257 @@
+package verify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// maxTidyLines limits the changed lines of a module file reported by the go.mod gate
+const maxTidyLines = 20
+
+// GoModGate checks the module files of a Go project: go mod tidy leaves them unchanged, go mod verify passes
+// and, with BlockNewDeps, no module is required that wasn't required when the run started.
+type GoModGate struct {
+	Dir          string   // module root, current directory if empty
+	Shell        string   // shell running the go commands, platform default if empty
+	BlockNewDeps bool     // report modules added to the requirements since Baseline
+	Baseline     []string // module paths required when the run started, see RequiredModules
+	Allowed      []string // module paths allowed to be added, "*" allows any
+}
+
+// Check runs the gate and returns feedback for the agent, empty if the module files are fine or there is
+// no go.mod. only cancellation of ctx is returned as error, failing go commands are feedback.
+// files changed by go mod tidy are restored, fixing them is left to the agent.
+func (g GoModGate) Check(ctx context.Context) (string, error) {
+	modPath := filepath.Join(g.Dir, "go.mod")
+	if _, err := os.Stat(modPath); err != nil {
+		return "", nil //nolint:nilerr // not a go module, nothing to check
+	}
+	var problems []string
+
+	untidy, err := g.tidyChanges(ctx)
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("go mod tidy: %w", ctx.Err())
+	}
+	switch {
+	case err != nil:
+		problems = append(problems, err.Error())
+	case untidy != "":
+		problems = append(problems, "go mod tidy changes the module files, run it and commit the result:\n"+untidy)
+	}
+
+	if _, err := Output(ctx, g.Shell, g.Dir, "go mod verify", nil); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("go mod verify: %w", ctx.Err())
+		}
+		problems = append(problems, err.Error())
+	}
+
+	if g.BlockNewDeps {
+		added, err := g.addedModules()
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		if len(added) > 0 {
+			problems = append(problems, fmt.Sprintf("new dependencies are not allowed by the plan: %s. "+
+				"remove them, or list them in the plan as \"Allowed dependencies: <module>, ...\"", strings.Join(added, ", ")))
+		}
+	}
+	if len(problems) == 0 {
+		return "", nil
+	}
+	return "go.mod gate failed:\n- " + strings.Join(problems, "\n- "), nil
+}
+
+// tidyChanges runs go mod tidy and returns the lines it adds (+) and removes (-) in go.mod and go.sum,
+// empty if the files are tidy. the original files are restored.
+func (g GoModGate) tidyChanges(ctx context.Context) (string, error) {
+	files := []string{filepath.Join(g.Dir, "go.mod"), filepath.Join(g.Dir, "go.sum")}
+	before := make([][]byte, len(files))
+	existed := make([]bool, len(files))
+	for i, f := range files {
+		data, err := os.ReadFile(f) //nolint:gosec // module files of the worktree
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("read %s: %w", filepath.Base(f), err)
+		}
+		before[i], existed[i] = data, err == nil
+	}
+	defer func() {
+		for i, f := range files {
+			if !existed[i] {
+				_ = os.Remove(f)
+				continue
+			}
+			_ = os.WriteFile(f, before[i], 0o644) //nolint:gosec // module files are not secret
+		}
+	}()
+
+	if _, err := Output(ctx, g.Shell, g.Dir, "go mod tidy", nil); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for i, f := range files {
+		after, err := os.ReadFile(f) //nolint:gosec // module files of the worktree
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("read %s: %w", filepath.Base(f), err)
+		}
+		if bytes.Equal(before[i], after) {
+			continue
+		}
+		fmt.Fprintf(&b, "%s:\n", filepath.Base(f))
+		changes := lineChanges(string(before[i]), string(after))
+		for _, l := range changes[:min(len(changes), maxTidyLines)] {
+			fmt.Fprintf(&b, "  %s\n", l)
+		}
+		if len(changes) > maxTidyLines {
+			fmt.Fprintf(&b, "  ... %d more lines\n", len(changes)-maxTidyLines)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// addedModules returns modules required now but not in the baseline or the allowed list.
+func (g GoModGate) addedModules() ([]string, error) {
+	if slices.Contains(g.Allowed, "*") {
+		return nil, nil
+	}
+	current, err := RequiredModules(g.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, m := range AddedModules(g.Baseline, current) {
+		if !slices.Contains(g.Allowed, m) {
+			res = append(res, m)
+		}
+	}
+	return res, nil
+}
+
+// DepPolicy decides which modules may be added to the requirements of a Go project.
+// patterns are module paths, path.Match globs, or a path followed by "/..." for the path and everything under it.
+type DepPolicy struct {
+	Allow []string // if set, only modules matching one of these may be added
+	Deny  []string // modules matching one of these may not be added, wins over Allow
+}
+
+// Enabled reports if the policy restricts anything.
+func (p DepPolicy) Enabled() bool {
+	return len(p.Allow) > 0 || len(p.Deny) > 0
+}
+
+// Disallowed returns the modules of mods the policy doesn't allow, in order.
+func (p DepPolicy) Disallowed(mods []string) []string {
+	var res []string
+	for _, m := range mods {
+		if matchModule(p.Deny, m) || (len(p.Allow) > 0 && !matchModule(p.Allow, m)) {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// ValidModulePattern returns an error if pattern is not a valid DepPolicy pattern.
+func ValidModulePattern(pattern string) error {
+	if _, err := path.Match(strings.TrimSuffix(pattern, "/..."), ""); err != nil {
+		return fmt.Errorf("bad module pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// matchModule reports if module matches one of patterns.
+func matchModule(patterns []string, module string) bool {
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "/..."); ok {
+			if module == prefix || strings.HasPrefix(module, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if ok, err := path.Match(p, module); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AddedModules returns the modules of current not in baseline, in order.
+func AddedModules(baseline, current []string) []string {
+	var res []string
+	for _, m := range current {
+		if !slices.Contains(baseline, m) {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// RequiredModules returns the module paths required by go.mod in dir, direct and indirect, nil if there is no go.mod.
+func RequiredModules(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod")) //nolint:gosec // module file of the worktree
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read go.mod: %w", err)
+	}
+	var res []string
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case line == "require (":
+			inBlock = true
+		case inBlock:
+			if fields := strings.Fields(line); len(fields) >= 2 {
+				res = append(res, fields[0])
+			}
+		case strings.HasPrefix(line, "require "):
+			if fields := strings.Fields(line); len(fields) >= 3 {
+				res = append(res, fields[1])
+			}
+		}
+	}
+	return res, nil
+}
+
+// lineChanges lists lines only in after as "+ line" and lines only in before as "- line"
+func lineChanges(before, after string) []string {
+	lineSet := func(s string) map[string]bool {
+		res := map[string]bool{}
+		for l := range strings.SplitSeq(s, "\n") {
+			if l = strings.TrimSpace(l); l != "" {
+				res[l] = true
+			}
+		}
+		return res
+	}
+	beforeSet, afterSet := lineSet(before), lineSet(after)
+	var added, removed []string
+	for l := range afterSet {
+		if !beforeSet[l] {
+			added = append(added, "+ "+l)
+		}
+	}
+	for l := range beforeSet {
+		if !afterSet[l] {
+			removed = append(removed, "- "+l)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return append(added, removed...)
+}
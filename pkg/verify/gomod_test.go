@@ -0,0 +1,138 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredModules(t *testing.T) {
+	dir := t.TempDir()
+	mods, err := RequiredModules(dir)
+	require.NoError(t, err)
+	assert.Nil(t, mods, "no go.mod")
+
+	gomod := `module example.com/app
+
+go 1.24
+
+require github.com/a/single v1.0.0 // indirect
+
+require (
+	github.com/b/direct v1.2.0
+	// a comment
+	golang.org/x/c v0.1.0 // indirect
+)
+
+replace github.com/b/direct => ../direct
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0o600))
+	mods, err = RequiredModules(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github.com/a/single", "github.com/b/direct", "golang.org/x/c"}, mods)
+}
+
+func TestDepPolicy_Disallowed(t *testing.T) {
+	mods := []string{"golang.org/x/sync", "golang.org/x", "github.com/acme/lib", "github.com/acme/lib/v2", "github.com/other/lib"}
+	tests := []struct {
+		name   string
+		policy DepPolicy
+		want   []string
+	}{
+		{name: "no policy", policy: DepPolicy{}},
+		{name: "allow tree", policy: DepPolicy{Allow: []string{"golang.org/x/..."}},
+			want: []string{"github.com/acme/lib", "github.com/acme/lib/v2", "github.com/other/lib"}},
+		{name: "allow glob", policy: DepPolicy{Allow: []string{"github.com/*/lib"}},
+			want: []string{"golang.org/x/sync", "golang.org/x", "github.com/acme/lib/v2"}},
+		{name: "deny", policy: DepPolicy{Deny: []string{"github.com/acme/lib/..."}},
+			want: []string{"github.com/acme/lib", "github.com/acme/lib/v2"}},
+		{name: "deny wins", policy: DepPolicy{Allow: []string{"github.com/..."}, Deny: []string{"github.com/other/lib"}},
+			want: []string{"golang.org/x/sync", "golang.org/x", "github.com/other/lib"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.policy.Disallowed(mods))
+		})
+	}
+}
+
+func TestValidModulePattern(t *testing.T) {
+	require.NoError(t, ValidModulePattern("golang.org/x/..."))
+	require.NoError(t, ValidModulePattern("github.com/*/lib"))
+	require.ErrorContains(t, ValidModulePattern("github.com/[acme"), `bad module pattern "github.com/[acme"`)
+}
+
+func TestAddedModules(t *testing.T) {
+	assert.Equal(t, []string{"b", "d"}, AddedModules([]string{"a", "c"}, []string{"a", "b", "c", "d"}))
+	assert.Empty(t, AddedModules([]string{"a"}, []string{"a"}))
+}
+
+func TestLineChanges(t *testing.T) {
+	before := "module a\n\ngo 1.24\nrequire x v1\n"
+	after := "module a\n\ngo 1.24\nrequire y v2\nrequire z v3\n"
+	assert.Equal(t, []string{"+ require y v2", "+ require z v3", "- require x v1"}, lineChanges(before, after))
+	assert.Empty(t, lineChanges(before, before))
+}
+
+func TestGoModGate_Check(t *testing.T) {
+	t.Run("no go.mod", func(t *testing.T) {
+		feedback, err := GoModGate{Dir: t.TempDir(), BlockNewDeps: true}.Check(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+	})
+
+	t.Run("new dependencies", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"),
+			[]byte("module example.com/app\n\nrequire (\n\tgithub.com/old/dep v1.0.0\n\tgithub.com/new/dep v1.0.0\n)\n"), 0o600))
+		g := GoModGate{Dir: dir, BlockNewDeps: true, Baseline: []string{"github.com/old/dep"}}
+		added, err := g.addedModules()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"github.com/new/dep"}, added)
+
+		g.Allowed = []string{"github.com/new/dep"}
+		added, err = g.addedModules()
+		require.NoError(t, err)
+		assert.Empty(t, added)
+
+		g.Allowed = []string{"*"}
+		added, err = g.addedModules()
+		require.NoError(t, err)
+		assert.Empty(t, added)
+	})
+
+	t.Run("tidy", func(t *testing.T) {
+		if _, err := exec.LookPath("go"); err != nil {
+			t.Skip("go not in PATH")
+		}
+		t.Setenv("GOTOOLCHAIN", "local")
+		t.Setenv("GOPROXY", "off")
+		t.Setenv("GOFLAGS", "")
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o600))
+		untidy := []byte("module example.com/app\n")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), untidy, 0o600))
+
+		feedback, err := GoModGate{Dir: dir}.Check(context.Background())
+		require.NoError(t, err)
+		assert.Contains(t, feedback, "go.mod gate failed:\n- go mod tidy changes the module files")
+		assert.Contains(t, feedback, "+ go 1.")
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod")) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Equal(t, untidy, data, "go.mod restored")
+		_, err = os.Stat(filepath.Join(dir, "go.sum"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+
+		// a tidy module passes
+		_, err = Output(context.Background(), "", dir, "go mod tidy", nil)
+		require.NoError(t, err)
+		feedback, err = GoModGate{Dir: dir}.Check(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+	})
+}
@@ -0,0 +1,137 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// UnknownLicense is reported for modules without a license file or with a license not recognized
+const UnknownLicense = "unknown"
+
+// ModuleLicense is the license of a required module.
+type ModuleLicense struct {
+	Module  string `json:"module"`
+	Version string `json:"version,omitempty"`
+	License string `json:"license"`         // SPDX identifier or UnknownLicense
+	Allowed bool   `json:"allowed"`         // the license is allowed by the policy
+	Error   string `json:"error,omitempty"` // why the license couldn't be resolved
+}
+
+// ModuleLicenses detects the licenses of modules required by the Go module in dir from the license files
+// at their roots, downloading modules missing from the module cache. shell runs the go commands, platform
+// default if empty. modules which can't be resolved are reported with UnknownLicense and the error, only
+// cancellation of ctx is returned as error.
+func ModuleLicenses(ctx context.Context, shell, dir string, modules []string) ([]ModuleLicense, error) {
+	res := make([]ModuleLicense, 0, len(modules))
+	for _, mod := range modules {
+		ml := ModuleLicense{Module: mod, License: UnknownLicense}
+		version, modDir, err := moduleDir(ctx, shell, dir, mod)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("resolve module %s: %w", mod, ctx.Err())
+		}
+		if err != nil {
+			ml.Error = err.Error()
+		} else {
+			ml.Version, ml.License = version, detectLicense(modDir)
+		}
+		res = append(res, ml)
+	}
+	return res, nil
+}
+
+// moduleDir returns the version of a required module and the directory with its files, replacements
+// included. a module not in the module cache yet is downloaded.
+func moduleDir(ctx context.Context, shell, dir, mod string) (version, modDir string, err error) {
+	var listed struct{ Version, Dir string }
+	out, err := Output(ctx, shell, dir, "go list -m -json "+mod, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if err := json.Unmarshal(out, &listed); err != nil {
+		return "", "", fmt.Errorf("parse go list output: %w", err)
+	}
+	if listed.Dir != "" {
+		return listed.Version, listed.Dir, nil
+	}
+	var info struct{ Version, Dir, Error string }
+	if out, err = Output(ctx, shell, dir, "go mod download -json "+mod, nil); err != nil {
+		return "", "", err
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return "", "", fmt.Errorf("parse go mod download output: %w", err)
+	}
+	if info.Error != "" {
+		return "", "", errors.New(info.Error)
+	}
+	return info.Version, info.Dir, nil
+}
+
+// licenseFileRe matches license file names at a module root
+var licenseFileRe = regexp.MustCompile(`(?i)^(license|licence|copying)([.-].*)?$`)
+
+// detectLicense returns the license of the module in dir, UnknownLicense if there is no license file
+// or it is not recognized.
+func detectLicense(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return UnknownLicense
+	}
+	for _, e := range entries {
+		if e.IsDir() || !licenseFileRe.MatchString(e.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name())) //nolint:gosec // file of the module cache
+		if err != nil {
+			continue
+		}
+		if id := ClassifyLicense(string(data)); id != UnknownLicense {
+			return id
+		}
+	}
+	return UnknownLicense
+}
+
+// licenseMarkers identify licenses by phrases of their text, checked in order. more specific licenses
+// go first, the GPL family mentions each other.
+var licenseMarkers = []struct {
+	id      string
+	phrases []string
+}{
+	{id: "AGPL-3.0", phrases: []string{"gnu affero general public license"}},
+	{id: "LGPL-3.0", phrases: []string{"gnu lesser general public license", "version 3"}},
+	{id: "LGPL-2.1", phrases: []string{"gnu lesser general public license"}},
+	{id: "GPL-3.0", phrases: []string{"gnu general public license", "version 3"}},
+	{id: "GPL-2.0", phrases: []string{"gnu general public license"}},
+	{id: "MPL-2.0", phrases: []string{"mozilla public license", "2.0"}},
+	{id: "Apache-2.0", phrases: []string{"apache license", "version 2.0"}},
+	{id: "MIT", phrases: []string{"permission is hereby granted, free of charge"}},
+	{id: "BSD-3-Clause", phrases: []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{id: "BSD-3-Clause", phrases: []string{"redistribution and use in source and binary forms", "names of its contributors"}},
+	{id: "BSD-2-Clause", phrases: []string{"redistribution and use in source and binary forms"}},
+	{id: "ISC", phrases: []string{"permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{id: "Unlicense", phrases: []string{"this is free and unencumbered software released into the public domain"}},
+}
+
+// ClassifyLicense returns the SPDX identifier of a license text, UnknownLicense if not recognized.
+func ClassifyLicense(text string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	for _, m := range licenseMarkers {
+		found := true
+		for _, p := range m.phrases {
+			if !strings.Contains(normalized, p) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return m.id
+		}
+	}
+	return UnknownLicense
+}
@@ -0,0 +1,77 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyLicense(t *testing.T) {
+	tests := []struct {
+		name, text, want string
+	}{
+		{name: "mit", text: "MIT License\n\nPermission is hereby granted, free of charge, to any person", want: "MIT"},
+		{name: "apache", text: "                                 Apache License\n                           Version 2.0, January 2004", want: "Apache-2.0"},
+		{name: "bsd-3", text: "Redistribution and use in source and binary forms, with or without\nmodification ... " +
+			"Neither the name of Google Inc. nor the names", want: "BSD-3-Clause"},
+		{name: "bsd-2", text: "Redistribution and use in source and binary forms, with or without modification", want: "BSD-2-Clause"},
+		{name: "isc", text: "Permission to use, copy, modify, and/or distribute this software for any purpose", want: "ISC"},
+		{name: "mpl", text: "Mozilla Public License Version 2.0\n==================================", want: "MPL-2.0"},
+		{name: "gpl-3", text: "GNU GENERAL PUBLIC LICENSE\n Version 3, 29 June 2007", want: "GPL-3.0"},
+		{name: "gpl-2", text: "GNU GENERAL PUBLIC LICENSE\n Version 2, June 1991", want: "GPL-2.0"},
+		{name: "lgpl-3", text: "GNU LESSER GENERAL PUBLIC LICENSE\n Version 3, 29 June 2007\n GNU General Public License", want: "LGPL-3.0"},
+		{name: "agpl", text: "GNU AFFERO GENERAL PUBLIC LICENSE\n Version 3, 19 November 2007", want: "AGPL-3.0"},
+		{name: "unlicense", text: "This is free and unencumbered software released into the public domain.", want: "Unlicense"},
+		{name: "unknown", text: "all rights reserved", want: UnknownLicense},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ClassifyLicense(tc.text))
+		})
+	}
+}
+
+func TestDetectLicense(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, UnknownLicense, detectLicense(dir), "no license file")
+	assert.Equal(t, UnknownLicense, detectLicense(filepath.Join(dir, "missing")), "no directory")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("Permission is hereby granted, free of charge"), 0o600))
+	assert.Equal(t, UnknownLicense, detectLicense(dir), "not a license file")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "LICENSE.txt"), []byte("Permission is hereby granted, free of charge"), 0o600))
+	assert.Equal(t, "MIT", detectLicense(dir))
+}
+
+func TestModuleLicenses(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not in PATH")
+	}
+	t.Setenv("GOTOOLCHAIN", "local")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOFLAGS", "")
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "dep"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dep", "go.mod"), []byte("module example.com/dep\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dep", "LICENSE"), []byte("Apache License\nVersion 2.0"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n\n"+
+		"require (\n\texample.com/dep v1.0.0\n\texample.com/missing v1.0.0\n)\n\nreplace example.com/dep => ./dep\n"), 0o600))
+
+	res, err := ModuleLicenses(context.Background(), "", dir, []string{"example.com/dep", "example.com/missing"})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, ModuleLicense{Module: "example.com/dep", Version: "v1.0.0", License: "Apache-2.0"}, res[0])
+	assert.Equal(t, "example.com/missing", res[1].Module)
+	assert.Equal(t, UnknownLicense, res[1].License)
+	assert.NotEmpty(t, res[1].Error)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ModuleLicenses(ctx, "", dir, []string{"example.com/dep"})
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,62 @@
+package verify
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VersionPlaceholder in Runner.Image is replaced with the Go version of a matrix entry, e.g. "golang:{version}".
+const VersionPlaceholder = "{version}"
+
+// goVersionRe matches Go versions accepted in a matrix: 1.23, 1.23.4, go1.23.4, 1.24rc1
+var goVersionRe = regexp.MustCompile(`^(?:go)?1\.\d+(?:\.\d+|rc\d+)?$`)
+
+// Matrix runs the commands of Runner once per Go version, stopping at the first version that fails.
+// each version runs in the image with VersionPlaceholder replaced if Runner.Image has it, otherwise
+// on the host with GOTOOLCHAIN selecting the version, downloaded by the go command if not installed.
+type Matrix struct {
+	Runner
+	Versions []string // Go versions, see ValidGoVersion
+}
+
+// Verify runs the commands for each version. results are labeled with the version they ran with.
+func (m *Matrix) Verify(ctx context.Context) Report {
+	var report Report
+	for _, version := range m.Versions {
+		r := m.Runner
+		r.Env = append([]string(nil), m.Env...)
+		if strings.Contains(r.Image, VersionPlaceholder) {
+			r.Image = strings.ReplaceAll(r.Image, VersionPlaceholder, strings.TrimPrefix(version, "go"))
+		} else {
+			r.Env = append(r.Env, "GOTOOLCHAIN="+Toolchain(version))
+		}
+		vr := r.Verify(ctx)
+		for i := range vr.Results {
+			vr.Results[i].Label = "go " + strings.TrimPrefix(version, "go")
+		}
+		report.Results = append(report.Results, vr.Results...)
+		if !vr.Passed() {
+			break
+		}
+	}
+	return report
+}
+
+// ValidGoVersion checks a matrix version: 1.23, 1.23.4, go1.23.4 or a release candidate like 1.24rc1.
+func ValidGoVersion(v string) bool {
+	return goVersionRe.MatchString(v)
+}
+
+// Toolchain returns the GOTOOLCHAIN value for a version. since go 1.21 a language version without
+// patch means its first release, "1.23" is go1.23.0, as toolchains are only published per release.
+func Toolchain(version string) string {
+	v := strings.TrimPrefix(version, "go")
+	if _, minor, ok := strings.Cut(v, "."); ok && !strings.ContainsAny(minor, ".rc") {
+		if n, err := strconv.Atoi(minor); err == nil && n >= 21 {
+			v += ".0"
+		}
+	}
+	return "go" + v
+}
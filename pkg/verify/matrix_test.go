@@ -0,0 +1,71 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrix_Verify(t *testing.T) {
+	skipOnWindows(t)
+
+	t.Run("toolchain per version", func(t *testing.T) {
+		m := &Matrix{Runner: Runner{Env: []string{"X=1"}, Commands: []string{"echo $GOTOOLCHAIN $X"}}, Versions: []string{"1.23", "go1.24.1"}}
+		report := m.Verify(t.Context())
+		require.True(t, report.Passed(), report.Feedback())
+		require.Len(t, report.Results, 2)
+		assert.Equal(t, "go1.23.0 1\n", report.Results[0].Output)
+		assert.Equal(t, "go 1.23", report.Results[0].Label)
+		assert.Equal(t, "go1.24.1 1\n", report.Results[1].Output)
+		assert.Equal(t, "go 1.24.1", report.Results[1].Label)
+		assert.Equal(t, []string{"X=1"}, m.Env, "base env is not modified")
+	})
+
+	t.Run("stops at first failing version", func(t *testing.T) {
+		m := &Matrix{Runner: Runner{Commands: []string{`[ "$GOTOOLCHAIN" != go1.22.5 ] || { echo "undefined: slices.Repeat"; exit 1; }`}},
+			Versions: []string{"1.24.1", "1.22.5", "1.23.0"}}
+		report := m.Verify(t.Context())
+		require.False(t, report.Passed())
+		assert.Len(t, report.Results, 2)
+		feedback := report.Feedback()
+		assert.Contains(t, feedback, "\nFailed with: go 1.22.5 (other versions may pass")
+		assert.True(t, strings.HasSuffix(feedback, "Output:\nundefined: slices.Repeat\n"), feedback)
+	})
+
+	t.Run("image per version", func(t *testing.T) {
+		script, argsLog := fakeContainerCLI(t)
+		m := &Matrix{Runner: Runner{Image: "golang:{version}", ContainerCommand: script, Commands: []string{"true"}},
+			Versions: []string{"1.23", "go1.24.1"}}
+		require.True(t, m.Verify(t.Context()).Passed())
+		calls := readLog(t, argsLog)
+		require.Len(t, calls, 2)
+		assert.Contains(t, calls[0], " golang:1.23 sh -c true")
+		assert.Contains(t, calls[1], " golang:1.24.1 sh -c true")
+		assert.NotContains(t, calls[0], "GOTOOLCHAIN")
+	})
+}
+
+func TestToolchain(t *testing.T) {
+	tests := map[string]string{
+		"1.23":     "go1.23.0",
+		"go1.23":   "go1.23.0",
+		"1.23.4":   "go1.23.4",
+		"go1.24.1": "go1.24.1",
+		"1.24rc1":  "go1.24rc1",
+		"1.20":     "go1.20",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, Toolchain(in), in)
+	}
+}
+
+func TestValidGoVersion(t *testing.T) {
+	for _, v := range []string{"1.23", "1.23.4", "go1.23.4", "1.24rc1"} {
+		assert.True(t, ValidGoVersion(v), v)
+	}
+	for _, v := range []string{"", "1", "2.0", "1.23.x", "latest", "go1.23 "} {
+		assert.False(t, ValidGoVersion(v), v)
+	}
+}
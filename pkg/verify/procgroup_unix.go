@@ -0,0 +1,18 @@
+//go:build !windows
+
+package verify
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setupProcessGroup runs the command in its own process group and makes context
+// cancellation kill the whole group, so test binaries and other children started
+// by the shell don't outlive a timeout.
+func setupProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package verify
+
+import "os/exec"
+
+// setupProcessGroup is a no-op on Windows, context cancellation kills only the direct process.
+func setupProcessGroup(_ *exec.Cmd) {}
@@ -0,0 +1,127 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProfileAuto selects a profile by detecting the project type.
+const ProfileAuto = "auto"
+
+// Profile is a built-in set of verification commands for one ecosystem.
+// target runner profiles (make, task) have no fixed commands, they run targets defined in the marker file.
+type Profile struct {
+	Name     string
+	Markers  []string // files in the project root identifying the ecosystem
+	Commands []string // shell commands, run in order
+	Tool     string   // target runner binary, set for make and task profiles
+	Scoped   bool     // no fixed commands, tests only targets affected by changed files (see Bazel)
+}
+
+// profiles are checked in order during detection, so more specific ecosystems go first.
+// target runners go before languages since repos using them already centralize verification there,
+// and bazel goes first since its workspaces also carry language and make files that bypass it.
+var profiles = []Profile{
+	{Name: "bazel", Markers: []string{"MODULE.bazel", "WORKSPACE.bazel", "WORKSPACE"}, Scoped: true},
+	{Name: "task", Markers: []string{"Taskfile.yml", "Taskfile.yaml", "taskfile.yml", "taskfile.yaml"}, Tool: "task"},
+	{Name: "make", Markers: []string{"GNUmakefile", "makefile", "Makefile"}, Tool: "make"},
+	{Name: "go", Markers: []string{"go.mod"}, Commands: []string{"go build ./...", "go vet ./...", "go test ./..."}},
+	{Name: "rust", Markers: []string{"Cargo.toml"}, Commands: []string{"cargo build", "cargo test"}},
+	{Name: "node", Markers: []string{"package.json"}, Commands: []string{"npm test"}},
+	{Name: "python", Markers: []string{"pyproject.toml", "setup.py", "setup.cfg", "pytest.ini", "tox.ini"},
+		Commands: []string{"python -m pytest"}},
+}
+
+// Profiles returns the built-in profiles in detection order.
+func Profiles() []Profile {
+	res := make([]Profile, len(profiles))
+	copy(res, profiles)
+	return res
+}
+
+// Lookup returns the built-in profile with the given name.
+func Lookup(name string) (Profile, bool) {
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// Detect returns the first built-in profile with a marker file present in dir.
+// a target runner profile is only detected if its marker file defines any of DefaultTargets.
+func Detect(dir string) (Profile, bool) {
+	for _, p := range profiles {
+		if _, ok := markerFile(dir, p); !ok {
+			continue
+		}
+		if p.Tool != "" && len(presentTargets(DefaultTargets, listTargets(dir, p))) == 0 {
+			continue
+		}
+		return p, true
+	}
+	return Profile{}, false
+}
+
+// Resolve picks verification commands for the project in dir.
+// explicit commands win over profiles, profile is a built-in profile name or ProfileAuto (also used when empty).
+// targets select make/task targets to run, DefaultTargets present in the marker file are used if empty.
+// returns the name of what was selected ("custom" for explicit commands), empty if auto-detection
+// found nothing. scoped profiles return no commands, their commands depend on changed files.
+func Resolve(dir, profile string, targets, commands []string) (string, []string, error) {
+	if len(commands) > 0 {
+		return "custom", commands, nil
+	}
+	var p Profile
+	switch profile {
+	case "", ProfileAuto:
+		if len(targets) > 0 {
+			// explicit targets pick the first target runner present, regardless of default targets
+			for _, tp := range profiles {
+				if _, ok := markerFile(dir, tp); ok && tp.Tool != "" {
+					return tp.Name, []string{targetCommand(tp.Tool, targets)}, nil
+				}
+			}
+			return "", nil, errors.New("verification targets set but no Makefile or Taskfile found")
+		}
+		detected, ok := Detect(dir)
+		if !ok {
+			return "", nil, nil
+		}
+		p = detected
+	default:
+		found, ok := Lookup(profile)
+		if !ok {
+			return "", nil, fmt.Errorf("unknown verification profile %q", profile)
+		}
+		p = found
+	}
+
+	if p.Tool == "" {
+		if len(targets) > 0 {
+			return "", nil, fmt.Errorf("verification targets require make or task profile, not %q", p.Name)
+		}
+		return p.Name, p.Commands, nil
+	}
+	if len(targets) == 0 {
+		if targets = presentTargets(DefaultTargets, listTargets(dir, p)); len(targets) == 0 {
+			return "", nil, fmt.Errorf("no verification targets (%s) found for %s profile",
+				strings.Join(DefaultTargets, ", "), p.Name)
+		}
+	}
+	return p.Name, []string{targetCommand(p.Tool, targets)}, nil
+}
+
+// presentTargets returns wanted targets that are defined, keeping the order of wanted.
+func presentTargets(wanted, defined []string) []string {
+	var res []string
+	for _, t := range wanted {
+		if slices.Contains(defined, t) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
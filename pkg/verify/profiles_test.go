@@ -0,0 +1,101 @@
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// markerContent has marker files defining verification targets
+var markerContent = map[string][]byte{
+	"Makefile":     []byte("test:\n\tgo test ./...\n"),
+	"Taskfile.yml": []byte("version: '3'\ntasks:\n  lint:\n    cmds: [golangci-lint run]\n"),
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{name: "go module", files: []string{"go.mod"}, want: "go"},
+		{name: "rust crate", files: []string{"Cargo.toml"}, want: "rust"},
+		{name: "node package", files: []string{"package.json"}, want: "node"},
+		{name: "python pyproject", files: []string{"pyproject.toml"}, want: "python"},
+		{name: "python setup.py", files: []string{"setup.py"}, want: "python"},
+		{name: "go wins over node tooling", files: []string{"package.json", "go.mod"}, want: "go"},
+		{name: "bazel wins over everything", files: []string{"MODULE.bazel", "Makefile", "go.mod"}, want: "bazel"},
+		{name: "taskfile", files: []string{"Taskfile.yml", "go.mod"}, want: "task"},
+		{name: "makefile wins over go", files: []string{"Makefile", "go.mod"}, want: "make"},
+		{name: "nothing detected", files: []string{"README.md"}, want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tc.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, f), markerContent[f], 0o600))
+			}
+			p, ok := Detect(dir)
+			assert.Equal(t, tc.want != "", ok)
+			assert.Equal(t, tc.want, p.Name)
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	goDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(goDir, "go.mod"), []byte("module x\n"), 0o600))
+	emptyDir := t.TempDir()
+	makeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(makeDir, "go.mod"), []byte("module x\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(makeDir, "Makefile"),
+		[]byte("all: build\n\nbuild:\n\tgo build ./...\n\ntest:\n\tgo test ./...\n\ndocs:\n\techo docs\n"), 0o600))
+	noTargetsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(noTargetsDir, "go.mod"), []byte("module x\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(noTargetsDir, "Makefile"), []byte("docs:\n\techo docs\n"), 0o600))
+
+	tests := []struct {
+		name     string
+		dir      string
+		profile  string
+		targets  []string
+		commands []string
+		wantName string
+		wantCmds []string
+		wantErr  string
+	}{
+		{name: "auto detects", dir: goDir, profile: "auto", wantName: "go",
+			wantCmds: []string{"go build ./...", "go vet ./...", "go test ./..."}},
+		{name: "empty profile means auto", dir: goDir, wantName: "go",
+			wantCmds: []string{"go build ./...", "go vet ./...", "go test ./..."}},
+		{name: "named profile ignores detection", dir: goDir, profile: "rust", wantName: "rust",
+			wantCmds: []string{"cargo build", "cargo test"}},
+		{name: "explicit commands win", dir: goDir, profile: "node", commands: []string{"make check"},
+			wantName: "custom", wantCmds: []string{"make check"}},
+		{name: "nothing detected", dir: emptyDir, profile: "auto"},
+		{name: "unknown profile", dir: goDir, profile: "cobol", wantErr: `unknown verification profile "cobol"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			name, cmds, err := Resolve(tc.dir, tc.profile, tc.targets, tc.commands)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantName, name)
+			assert.Equal(t, tc.wantCmds, cmds)
+		})
+	}
+}
+
+func TestProfiles_ReturnsCopy(t *testing.T) {
+	ps := Profiles()
+	ps[0].Name = "changed"
+	p, ok := Lookup("go")
+	require.True(t, ok)
+	assert.Equal(t, "go", p.Name)
+}
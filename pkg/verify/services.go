@@ -0,0 +1,186 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Services runs docker compose services (databases, caches) needed by integration tests.
+// after Up, connection details are exported to verification commands of runners using them:
+// for a service "postgres" publishing port 5432, POSTGRES_HOST and POSTGRES_PORT
+// (plus POSTGRES_PORT_5432 for each published port) are set.
+type Services struct {
+	File    string // compose file
+	Dir     string // project directory, compose file is relative to it
+	Project string // compose project name, isolates concurrent runs
+	Command string // container CLI, DefaultContainerCommand if empty
+
+	mu        sync.Mutex
+	endpoints []serviceEndpoint // set by Up
+}
+
+// serviceEndpoint is a port published by a compose service.
+type serviceEndpoint struct {
+	service   string
+	target    int // port inside the service container
+	published int // port on the host
+}
+
+// composePS is a container entry of "docker compose ps --format json".
+type composePS struct {
+	Service    string
+	Publishers []struct {
+		TargetPort    int
+		PublishedPort int
+	}
+}
+
+// NewServices makes Services for the compose file in dir, with a project name unique to this process.
+func NewServices(file, dir, command string) *Services {
+	return &Services{File: file, Dir: dir, Command: command, Project: fmt.Sprintf("ralphex-verify-%d", os.Getpid())}
+}
+
+// Up starts the services and waits for them to be running and healthy.
+// returns the environment exported to host verification commands.
+func (s *Services) Up(ctx context.Context) ([]string, error) {
+	if _, err := s.compose(ctx, "up", "--detach", "--wait"); err != nil {
+		return nil, fmt.Errorf("start services: %w", err)
+	}
+	out, err := s.compose(ctx, "ps", "--format", "json")
+	if err != nil {
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+	endpoints, err := parseComposePS(out)
+	if err != nil {
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+
+	s.mu.Lock()
+	s.endpoints = endpoints
+	s.mu.Unlock()
+	return s.Env(false), nil
+}
+
+// Down stops the services and removes their containers and volumes.
+func (s *Services) Down(ctx context.Context) error {
+	s.mu.Lock()
+	s.endpoints = nil
+	s.mu.Unlock()
+	if _, err := s.compose(ctx, "down", "--volumes", "--remove-orphans"); err != nil {
+		return fmt.Errorf("stop services: %w", err)
+	}
+	return nil
+}
+
+// Env returns connection variables of running services, nil before Up.
+// commands running in a container on the services network (inContainer) connect by service name
+// and target port, host commands connect to published ports on localhost.
+func (s *Services) Env(inContainer bool) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var env []string
+	seen := map[string]bool{}
+	for _, e := range s.endpoints {
+		name := envName(e.service)
+		host, port := "127.0.0.1", e.published
+		if inContainer {
+			host, port = e.service, e.target
+		}
+		if !seen[name] { // endpoints are sorted, the lowest target port is the default
+			seen[name] = true
+			env = append(env, name+"_HOST="+host, name+"_PORT="+strconv.Itoa(port))
+		}
+		env = append(env, name+"_PORT_"+strconv.Itoa(e.target)+"="+strconv.Itoa(port))
+	}
+	return env
+}
+
+// network returns the compose default network commands in containers join, empty before Up.
+func (s *Services) network() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.endpoints == nil {
+		return ""
+	}
+	return s.Project + "_default"
+}
+
+// compose runs a docker compose subcommand for the services project, returning stdout.
+func (s *Services) compose(ctx context.Context, args ...string) ([]byte, error) {
+	command := s.Command
+	if command == "" {
+		command = DefaultContainerCommand
+	}
+	args = append([]string{"compose", "--file", s.File, "--project-name", s.Project}, args...)
+	cmd := exec.CommandContext(ctx, command, args...) //nolint:gosec // configured container command
+	cmd.Dir = s.Dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		tail := &tailBuffer{limit: 2048}
+		_, _ = tail.Write(stderr.Bytes())
+		return nil, fmt.Errorf("%s %s: %w: %s", command, strings.Join(args, " "), err, strings.TrimSpace(tail.String()))
+	}
+	return out, nil
+}
+
+// parseComposePS parses "docker compose ps --format json" output, a JSON array in older compose
+// versions and one object per line in newer ones. returns endpoints sorted by service and port.
+func parseComposePS(out []byte) ([]serviceEndpoint, error) {
+	var entries []composePS
+	trimmed := bytes.TrimSpace(out)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, fmt.Errorf("parse compose ps: %w", err)
+		}
+	} else {
+		for line := range bytes.SplitSeq(trimmed, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var e composePS
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("parse compose ps: %w", err)
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	res := []serviceEndpoint{} // non-nil marks services as up
+	for _, e := range entries {
+		for _, p := range e.Publishers {
+			ep := serviceEndpoint{service: e.Service, target: p.TargetPort, published: p.PublishedPort}
+			if p.PublishedPort == 0 || slices.Contains(res, ep) { // ipv4 and ipv6 bindings repeat ports
+				continue
+			}
+			res = append(res, ep)
+		}
+	}
+	slices.SortFunc(res, func(a, b serviceEndpoint) int {
+		if c := strings.Compare(a.service, b.service); c != 0 {
+			return c
+		}
+		return a.target - b.target
+	})
+	return res, nil
+}
+
+// envName converts a service name to an environment variable prefix, "my-db" becomes "MY_DB".
+func envName(service string) string {
+	return strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return '_'
+		}
+		return unicode.ToUpper(r)
+	}, service)
+}
@@ -0,0 +1,111 @@
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseComposePS(t *testing.T) {
+	want := []serviceEndpoint{
+		{service: "my-db", target: 5432, published: 55001},
+		{service: "redis", target: 6379, published: 55002},
+		{service: "redis", target: 16379, published: 55003},
+	}
+
+	t.Run("json lines", func(t *testing.T) {
+		out := `{"Service":"redis","Publishers":[{"TargetPort":16379,"PublishedPort":55003},{"TargetPort":6379,"PublishedPort":55002}]}
+{"Service":"my-db","Publishers":[{"URL":"0.0.0.0","TargetPort":5432,"PublishedPort":55001},{"URL":"::","TargetPort":5432,"PublishedPort":55001}]}
+{"Service":"worker","Publishers":[{"TargetPort":8080,"PublishedPort":0}]}
+`
+		got, err := parseComposePS([]byte(out))
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("json array", func(t *testing.T) {
+		out := `[{"Service":"my-db","Publishers":[{"TargetPort":5432,"PublishedPort":55001}]},
+{"Service":"redis","Publishers":[{"TargetPort":6379,"PublishedPort":55002},{"TargetPort":16379,"PublishedPort":55003}]}]`
+		got, err := parseComposePS([]byte(out))
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("no services", func(t *testing.T) {
+		got, err := parseComposePS(nil)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := parseComposePS([]byte("not json"))
+		require.Error(t, err)
+	})
+}
+
+func TestServices_Env(t *testing.T) {
+	s := &Services{Project: "p", endpoints: []serviceEndpoint{
+		{service: "my-db", target: 5432, published: 55001},
+		{service: "redis", target: 6379, published: 55002},
+		{service: "redis", target: 16379, published: 55003},
+	}}
+	assert.Equal(t, []string{
+		"MY_DB_HOST=127.0.0.1", "MY_DB_PORT=55001", "MY_DB_PORT_5432=55001",
+		"REDIS_HOST=127.0.0.1", "REDIS_PORT=55002", "REDIS_PORT_6379=55002", "REDIS_PORT_16379=55003",
+	}, s.Env(false))
+	assert.Equal(t, []string{
+		"MY_DB_HOST=my-db", "MY_DB_PORT=5432", "MY_DB_PORT_5432=5432",
+		"REDIS_HOST=redis", "REDIS_PORT=6379", "REDIS_PORT_6379=6379", "REDIS_PORT_16379=16379",
+	}, s.Env(true))
+	assert.Equal(t, "p_default", s.network())
+
+	assert.Empty(t, (&Services{}).Env(false), "nothing before Up")
+	assert.Empty(t, (&Services{}).network())
+}
+
+func TestServices_UpDown(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	argsLog := filepath.Join(dir, "args.log")
+	script := filepath.Join(dir, "docker")
+	body := `#!/bin/sh
+echo "$@" >> '` + argsLog + `'
+case "$6" in
+ps) echo '{"Service":"postgres","Publishers":[{"TargetPort":5432,"PublishedPort":55001}]}' ;;
+esac
+`
+	require.NoError(t, os.WriteFile(script, []byte(body), 0o700)) //nolint:gosec // test script must be executable
+
+	s := NewServices("compose.yml", dir, script)
+	assert.True(t, strings.HasPrefix(s.Project, "ralphex-verify-"))
+	env, err := s.Up(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"POSTGRES_HOST=127.0.0.1", "POSTGRES_PORT=55001", "POSTGRES_PORT_5432=55001"}, env)
+
+	// runner commands get service variables
+	r := &Runner{Dir: dir, Services: s, Commands: []string{`echo "$POSTGRES_HOST:$POSTGRES_PORT"`}}
+	report := r.Verify(t.Context())
+	require.True(t, report.Passed(), report.Feedback())
+	assert.Equal(t, "127.0.0.1:55001\n", report.Results[0].Output)
+
+	require.NoError(t, s.Down(t.Context()))
+	assert.Empty(t, s.Env(false))
+	prefix := "compose --file compose.yml --project-name " + s.Project + " "
+	assert.Equal(t, []string{prefix + "up --detach --wait", prefix + "ps --format json", prefix + "down --volumes --remove-orphans"},
+		readLog(t, argsLog))
+}
+
+func TestServices_UpFailure(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	script := filepath.Join(dir, "docker")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho 'no such image: postgres:99' >&2\nexit 1\n"), 0o700)) //nolint:gosec // test script
+
+	_, err := NewServices("compose.yml", dir, script).Up(t.Context())
+	require.ErrorContains(t, err, "start services:")
+	assert.ErrorContains(t, err, "no such image: postgres:99")
+}
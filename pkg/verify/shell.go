@@ -0,0 +1,85 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Shells lists shells verification commands can run with.
+var Shells = []string{"sh", "bash", "cmd", "powershell", "pwsh"}
+
+// ParseShell parses a verify_shell entry: a shell name, optionally prefixed with the OS it applies to,
+// e.g. "bash" or "windows:pwsh". goos is empty for entries applying to every OS.
+func ParseShell(entry string) (goos, shell string, err error) {
+	shell = strings.TrimSpace(entry)
+	if before, after, ok := strings.Cut(shell, ":"); ok {
+		goos, shell = strings.ToLower(strings.TrimSpace(before)), strings.TrimSpace(after)
+		if goos == "" {
+			return "", "", fmt.Errorf("empty os in %q", entry)
+		}
+	}
+	shell = strings.ToLower(shell)
+	if !slices.Contains(Shells, shell) {
+		return "", "", fmt.Errorf("unknown shell %q, use one of: %s", shell, strings.Join(Shells, ", "))
+	}
+	return goos, shell, nil
+}
+
+// SelectShell returns the shell for goos from verify_shell entries. an entry for goos wins over
+// one without an OS. empty means the platform default, sh on unix and cmd on windows.
+// entries are expected to be valid, see ParseShell.
+func SelectShell(entries []string, goos string) string {
+	var res string
+	for _, e := range entries {
+		entryOS, shell, err := ParseShell(e)
+		switch {
+		case err != nil:
+		case entryOS == goos:
+			return shell
+		case entryOS == "" && res == "":
+			res = shell
+		}
+	}
+	return res
+}
+
+// shellOrDefault returns shell, or the platform default if empty: sh on unix and cmd on windows.
+func shellOrDefault(shell string) string {
+	if shell != "" {
+		return shell
+	}
+	if runtime.GOOS == "windows" {
+		return "cmd"
+	}
+	return "sh"
+}
+
+// QuoteArg quotes s as a single argument of a command line run with shell, platform default if empty.
+func QuoteArg(shell, s string) string {
+	switch shellOrDefault(shell) {
+	case "cmd":
+		return `"` + s + `"`
+	case "powershell", "pwsh":
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	default:
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+}
+
+// shellCommand builds a command running a shell command line with shell, or the platform default
+// if empty: sh on unix and cmd.exe on windows.
+func shellCommand(ctx context.Context, shell, command string) *exec.Cmd {
+	switch shell = shellOrDefault(shell); shell {
+	case "cmd":
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	case "powershell", "pwsh":
+		// a failing last command makes powershell exit non-zero, native commands included
+		return exec.CommandContext(ctx, shell, "-NoProfile", "-NonInteractive", "-Command", command)
+	default:
+		return exec.CommandContext(ctx, shell, "-c", command)
+	}
+}
@@ -0,0 +1,61 @@
+package verify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShell(t *testing.T) {
+	tests := []struct {
+		entry   string
+		goos    string
+		shell   string
+		wantErr string
+	}{
+		{entry: "bash", shell: "bash"},
+		{entry: " Windows : PWSH ", goos: "windows", shell: "pwsh"},
+		{entry: "darwin:zsh", wantErr: `unknown shell "zsh", use one of: sh, bash, cmd, powershell, pwsh`},
+		{entry: ":sh", wantErr: `empty os in ":sh"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.entry, func(t *testing.T) {
+			goos, shell, err := ParseShell(tc.entry)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.goos, goos)
+			assert.Equal(t, tc.shell, shell)
+		})
+	}
+}
+
+func TestSelectShell(t *testing.T) {
+	entries := []string{"bash", "windows:pwsh"}
+	assert.Equal(t, "pwsh", SelectShell(entries, "windows"))
+	assert.Equal(t, "bash", SelectShell(entries, "linux"))
+	assert.Equal(t, "cmd", SelectShell([]string{"windows:cmd", "powershell"}, "windows"), "os entry wins regardless of order")
+	assert.Empty(t, SelectShell([]string{"windows:pwsh"}, "darwin"), "platform default")
+	assert.Empty(t, SelectShell(nil, "linux"))
+}
+
+func TestShellCommand(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{shell: "bash", want: []string{"bash", "-c", "go test ./..."}},
+		{shell: "cmd", want: []string{"cmd", "/C", "go test ./..."}},
+		{shell: "pwsh", want: []string{"pwsh", "-NoProfile", "-NonInteractive", "-Command", "go test ./..."}},
+		{shell: "powershell", want: []string{"powershell", "-NoProfile", "-NonInteractive", "-Command", "go test ./..."}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.shell, func(t *testing.T) {
+			assert.Equal(t, tc.want, shellCommand(context.Background(), tc.shell, "go test ./...").Args)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package verify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// streamLineLimit caps a single output line passed on by Stream, longer lines are cut
+const streamLineLimit = 64 * 1024
+
+// Stream runs a command line with shell, the platform default if empty, in dir, current directory if empty.
+// each line of the combined output is passed to onLine as it comes. canceling ctx kills the command
+// and its children, the context error is returned then.
+func Stream(ctx context.Context, shell, dir, command string, onLine func(line string)) error {
+	cmd := shellCommand(ctx, shell, command)
+	setupProcessGroup(cmd)
+	cmd.Dir = dir
+	pr, pw := io.Pipe()
+	cmd.Stdout, cmd.Stderr = pw, pw
+	cmd.WaitDelay = 5 * time.Second // don't hang on children holding output pipes after cancellation
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reader := bufio.NewReaderSize(pr, streamLineLimit)
+		for {
+			line, isPrefix, err := reader.ReadLine()
+			if len(line) > 0 || (err == nil && !isPrefix) {
+				onLine(string(line))
+			}
+			for isPrefix && err == nil { // drop the rest of an overlong line
+				_, isPrefix, err = reader.ReadLine()
+			}
+			if err != nil {
+				_, _ = io.Copy(io.Discard, pr)
+				return
+			}
+		}
+	}()
+
+	err := cmd.Run()
+	_ = pw.Close()
+	<-done
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("run %q: %w", command, err)
+	}
+	return nil
+}
+
+// Output runs a command line like Stream, feeding stdin to it, and returns its standard output.
+// the tail of standard error is added to the error of a failing command.
+func Output(ctx context.Context, shell, dir, command string, stdin io.Reader) ([]byte, error) {
+	cmd := shellCommand(ctx, shell, command)
+	setupProcessGroup(cmd)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	var stdout bytes.Buffer
+	stderr := &tailBuffer{limit: DefaultMaxOutput}
+	cmd.Stdout, cmd.Stderr = &stdout, stderr
+	cmd.WaitDelay = 5 * time.Second
+
+	err := cmd.Run()
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("run %q: %w: %s", command, err, msg)
+		}
+		return nil, fmt.Errorf("run %q: %w", command, err)
+	}
+	return stdout.Bytes(), nil
+}
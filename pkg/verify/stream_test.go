@@ -0,0 +1,56 @@
+//go:build !windows
+
+package verify
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	dir := t.TempDir()
+	var lines []string
+	err := Stream(context.Background(), "", dir, "echo one; echo two >&2; echo; pwd", func(line string) { lines = append(lines, line) })
+	require.NoError(t, err)
+	require.Len(t, lines, 4)
+	assert.Equal(t, []string{"one", "two", ""}, lines[:3])
+	assert.True(t, strings.HasSuffix(lines[3], dir[strings.LastIndex(dir, "/"):]), "runs in dir")
+
+	lines = nil
+	err = Stream(context.Background(), "sh", "", "echo failing; exit 3", func(line string) { lines = append(lines, line) })
+	require.ErrorContains(t, err, `run "echo failing; exit 3": exit status 3`)
+	assert.Equal(t, []string{"failing"}, lines)
+
+	long := strings.Repeat("x", streamLineLimit+10)
+	lines = nil
+	require.NoError(t, Stream(context.Background(), "", "", "printf '"+long+"\\nafter\\n'", func(line string) { lines = append(lines, line) }))
+	require.Len(t, lines, 2)
+	assert.Len(t, lines[0], streamLineLimit, "overlong line is cut")
+	assert.Equal(t, "after", lines[1])
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = Stream(ctx, "", "", "sleep 10", func(string) {})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestOutput(t *testing.T) {
+	out, err := Output(context.Background(), "", "", "echo noise >&2; cat", strings.NewReader("from stdin\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "from stdin\n", string(out), "stderr is not mixed in")
+
+	_, err = Output(context.Background(), "sh", "", "echo broken >&2; exit 2", nil)
+	require.ErrorContains(t, err, `run "echo broken >&2; exit 2": exit status 2: broken`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = Output(ctx, "", "", "sleep 10", nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
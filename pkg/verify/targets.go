@@ -0,0 +1,134 @@
+package verify
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultTargets are make/task targets run by target runner profiles when no targets are configured.
+// only targets present in the Makefile or Taskfile are used, in this order.
+var DefaultTargets = []string{"build", "lint", "test"}
+
+// makeRuleRe matches a rule line "target1 target2: deps" or a variable assignment like "X := y",
+// assignments are filtered out by the caller.
+var makeRuleRe = regexp.MustCompile(`^([^\s:#=][^:#=]*?)\s*:(.*)$`)
+
+// make and task failure messages naming the failed target
+var (
+	makeFailedRe     = regexp.MustCompile(`(?m)^g?make(?:\[\d+\])?: \*\*\* \[(?:[^\]]*?: )?([^\]:]+)\] Error`)
+	makeNoRuleRe     = regexp.MustCompile(`(?m)^g?make(?:\[\d+\])?: \*\*\* No rule to make target [` + "`" + `'"]([^'"]+)['"]`)
+	taskFailedRe     = regexp.MustCompile(`(?m)^task: Failed to run task "([^"]+)"`)
+	taskNotExistsRe  = regexp.MustCompile(`(?m)^task: Task "([^"]+)" does not exist`)
+	failedTargetRegs = []*regexp.Regexp{makeNoRuleRe, makeFailedRe, taskNotExistsRe, taskFailedRe}
+)
+
+// targetCommand builds the command running targets with tool. a single invocation is used,
+// both make and task stop at the first failed target and name it in the output.
+func targetCommand(tool string, targets []string) string {
+	return tool + " " + strings.Join(targets, " ")
+}
+
+// markerFile returns the first marker of p present in dir.
+func markerFile(dir string, p Profile) (string, bool) {
+	for _, marker := range p.Markers {
+		path := filepath.Join(dir, marker)
+		if st, err := os.Stat(path); err == nil && !st.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// listTargets returns targets defined in the marker file of a target runner profile.
+func listTargets(dir string, p Profile) []string {
+	path, ok := markerFile(dir, p)
+	if !ok {
+		return nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // marker file in the project directory
+	if err != nil {
+		return nil
+	}
+	if p.Tool == "task" {
+		return taskfileTargets(data)
+	}
+	return makefileTargets(data)
+}
+
+// makefileTargets returns explicit rule targets, skipping special (.PHONY), pattern and variable targets.
+// included makefiles are not followed.
+func makefileTargets(data []byte) []string {
+	var res []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\t") {
+			continue // recipe line
+		}
+		m := makeRuleRe.FindStringSubmatch(line)
+		if m == nil || strings.HasPrefix(strings.TrimPrefix(m[2], ":"), "=") {
+			continue
+		}
+		for name := range strings.FieldsSeq(m[1]) {
+			if strings.HasPrefix(name, ".") || strings.ContainsAny(name, "%$") || slices.Contains(res, name) {
+				continue
+			}
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// taskfileTargets returns task names from the top-level "tasks:" map of a Taskfile.
+// it is a line-based reader for the common layout, not a full yaml parser.
+func taskfileTargets(data []byte) []string {
+	var res []string
+	inTasks, indent := false, -1
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		lineIndent := len(line) - len(trimmed)
+		if lineIndent == 0 {
+			inTasks = trimmed == "tasks:"
+			indent = -1
+			continue
+		}
+		if !inTasks {
+			continue
+		}
+		if indent < 0 {
+			indent = lineIndent // first child sets the indentation of task names
+		}
+		if lineIndent != indent {
+			continue
+		}
+		name, _, found := strings.Cut(trimmed, ":")
+		if !found {
+			continue
+		}
+		if name = strings.Trim(name, `"'`); name != "" && !slices.Contains(res, name) {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// failedTarget extracts the failed make or task target from command output, empty if not found.
+// the last match is used, recursive make reports inner failures before the top-level target.
+func failedTarget(output string) string {
+	for _, re := range failedTargetRegs {
+		if ms := re.FindAllStringSubmatch(output, -1); len(ms) > 0 {
+			return strings.TrimSpace(ms[len(ms)-1][1])
+		}
+	}
+	return ""
+}
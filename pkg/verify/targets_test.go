@@ -0,0 +1,92 @@
+package verify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakefileTargets(t *testing.T) {
+	data := []byte(`# build helpers
+GO := go
+LDFLAGS ::= -s -w
+VERSION != git describe
+.PHONY: all build test lint
+
+all: build test
+
+build: $(wildcard *.go)
+	$(GO) build ./...
+
+test lint:
+	$(GO) test ./...
+	@echo "done: ok"
+
+%.o: %.c
+	cc -c $<
+
+test: EXTRA = -race
+build:
+	@echo again
+`)
+	assert.Equal(t, []string{"all", "build", "test", "lint"}, makefileTargets(data))
+}
+
+func TestTaskfileTargets(t *testing.T) {
+	data := []byte(`version: '3'
+
+vars:
+  name: app
+
+tasks:
+  build:
+    desc: build it
+    cmds:
+      - go build ./...
+  "lint":
+    cmds: [golangci-lint run]
+
+  # comment
+  test:
+    deps: [build]
+    cmds:
+      - go test ./...
+includes:
+  docs: ./docs
+`)
+	assert.Equal(t, []string{"build", "lint", "test"}, taskfileTargets(data))
+}
+
+func TestFailedTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "make with file and line", output: "go test ./...\nFAIL\nmake: *** [Makefile:15: test] Error 1\n", want: "test"},
+		{name: "old make", output: "make: *** [lint] Error 2\n", want: "lint"},
+		{name: "recursive make reports top-level target",
+			output: "make[1]: *** [sub.mk:3: unit] Error 1\nmake: *** [Makefile:9: test] Error 2\n", want: "test"},
+		{name: "make missing target", output: "make: *** No rule to make target 'lnt'.  Stop.\n", want: "lnt"},
+		{name: "gmake", output: "gmake: *** [GNUmakefile:2: build] Error 1\n", want: "build"},
+		{name: "task failure", output: "task: [test] go test ./...\ntask: Failed to run task \"test\": exit status 1\n", want: "test"},
+		{name: "task missing", output: "task: Task \"lnt\" does not exist\n", want: "lnt"},
+		{name: "plain command", output: "FAIL\texample.com/pkg\n", want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, failedTarget(tc.output))
+		})
+	}
+}
+
+func TestRunner_Verify_FailedTarget(t *testing.T) {
+	skipOnWindows(t)
+	r := &Runner{Commands: []string{"echo 'make: *** [Makefile:3: lint] Error 1' >&2; exit 2"}}
+	report := r.Verify(t.Context())
+	res, failed := report.Failure()
+	require.True(t, failed)
+	assert.Equal(t, "lint", res.Target)
+	assert.Contains(t, report.Feedback(), "\nFailed target: lint\nError: exit status 2\n")
+}
@@ -0,0 +1,207 @@
+// Package verify runs build/test verification commands (gates) between task iterations
+// and turns failures into feedback for the agent. built-in profiles cover common ecosystems.
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultMaxOutput is the default number of output bytes kept per command.
+// the tail is kept since build and test failures are reported at the end.
+const DefaultMaxOutput = 16 * 1024
+
+// Runner executes verification commands in a project directory.
+type Runner struct {
+	Dir       string        // working directory, current directory if empty
+	Commands  []string      // shell commands, run in order until the first failure
+	Timeout   time.Duration // per command, 0 means no timeout
+	Env       []string      // extra environment variables (KEY=VALUE) added to the current environment
+	MaxOutput int           // output bytes kept per command, DefaultMaxOutput if 0
+	Shell     string        // shell running commands on the host, one of Shells, platform default if empty
+
+	// Image runs commands inside this container image with Dir mounted, host toolchain is not used if set.
+	// Env is passed into the container.
+	Image            string
+	ContainerCommand string // container CLI, DefaultContainerCommand if empty
+
+	Services *Services // running services whose connection variables are added to Env, optional
+}
+
+// Result is the outcome of a single verification command.
+type Result struct {
+	Command  string
+	Err      error  // nil if the command passed
+	Output   string // combined stdout and stderr, tail only if longer than MaxOutput
+	Target   string // failed make or task target parsed from the output, if any
+	Label    string // matrix entry the command ran with, e.g. "go 1.23"
+	Duration time.Duration
+}
+
+// Passed returns true if the command succeeded.
+func (r Result) Passed() bool { return r.Err == nil }
+
+// Report holds results of a verification run. commands after the first failure are not run.
+type Report struct {
+	Results []Result
+}
+
+// Passed returns true if all commands that ran succeeded.
+func (r Report) Passed() bool {
+	_, failed := r.Failure()
+	return !failed
+}
+
+// Failure returns the failed command result, if any.
+func (r Report) Failure() (Result, bool) {
+	for _, res := range r.Results {
+		if !res.Passed() {
+			return res, true
+		}
+	}
+	return Result{}, false
+}
+
+// Feedback formats the failure for the agent, empty if verification passed.
+func (r Report) Feedback() string {
+	res, failed := r.Failure()
+	if !failed {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Command: %s\n", res.Command)
+	if res.Label != "" {
+		fmt.Fprintf(&b, "Failed with: %s (other versions may pass, keep the fix compatible with all)\n", res.Label)
+	}
+	if res.Target != "" {
+		fmt.Fprintf(&b, "Failed target: %s\n", res.Target)
+	}
+	fmt.Fprintf(&b, "Error: %v\nOutput:\n%s", res.Err, res.Output)
+	return b.String()
+}
+
+// Verify runs commands in order and stops at the first failure.
+// a canceled context stops the current command, its result carries the context error.
+func (r *Runner) Verify(ctx context.Context) Report {
+	var report Report
+	for _, command := range r.Commands {
+		res := r.run(ctx, command)
+		report.Results = append(report.Results, res)
+		if !res.Passed() {
+			break
+		}
+	}
+	return report
+}
+
+// run executes a single command through Shell, or sh in the container if Image is set.
+func (r *Runner) run(ctx context.Context, command string) Result {
+	return r.runCmd(ctx, command, func(ctx context.Context) *exec.Cmd {
+		if r.Image != "" {
+			return exec.CommandContext(ctx, "sh", "-c", command)
+		}
+		return shellCommand(ctx, r.Shell, command)
+	})
+}
+
+// runCmd executes the command made by build, reporting it as command. build gets the context
+// with the timeout applied. output is kept in the result unless build sets its own stdout.
+// with Image set, the built command runs inside the container.
+func (r *Runner) runCmd(ctx context.Context, command string, build func(ctx context.Context) *exec.Cmd) Result {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	cmd := build(ctx)
+	if r.Image != "" {
+		name := containerName()
+		containerized, err := r.containerCmd(ctx, cmd, name)
+		if err != nil {
+			return Result{Command: command, Err: err}
+		}
+		cmd = containerized
+		defer func() {
+			if ctx.Err() != nil { // killing the client leaves the container running
+				r.removeContainer(name)
+			}
+		}()
+	}
+	setupProcessGroup(cmd)
+	cmd.Dir = r.Dir
+	if env := r.env(); len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	maxOutput := r.MaxOutput
+	if maxOutput <= 0 {
+		maxOutput = DefaultMaxOutput
+	}
+	out := &tailBuffer{limit: maxOutput}
+	if cmd.Stdout == nil { // build may capture stdout separately
+		cmd.Stdout = out
+	}
+	cmd.Stderr = out
+	cmd.WaitDelay = 5 * time.Second // don't hang on children holding output pipes after a timeout
+
+	start := time.Now()
+	err := cmd.Run()
+	res := Result{Command: command, Output: out.String(), Duration: time.Since(start)}
+	switch {
+	case err == nil:
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		res.Err = fmt.Errorf("timed out after %s", r.Timeout)
+	case ctx.Err() != nil:
+		res.Err = ctx.Err()
+	default:
+		res.Err = err
+		res.Target = failedTarget(res.Output)
+	}
+	return res
+}
+
+// env returns extra environment variables for commands, including service connection variables.
+func (r *Runner) env() []string {
+	if r.Services == nil {
+		return r.Env
+	}
+	return append(slices.Clone(r.Env), r.Services.Env(r.Image != "")...)
+}
+
+// tailBuffer keeps the last limit bytes written to it.
+type tailBuffer struct {
+	limit   int
+	buf     []byte
+	dropped int
+}
+
+// Write appends p, dropping the oldest bytes beyond the limit. the buffer may grow to twice
+// the limit before compacting, keeping writes amortized O(len(p)).
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > 2*b.limit {
+		n := len(b.buf) - b.limit
+		b.dropped += n
+		b.buf = append(b.buf[:0], b.buf[n:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the kept output, noting how much was dropped.
+func (b *tailBuffer) String() string {
+	if n := len(b.buf) - b.limit; n > 0 {
+		b.dropped += n
+		b.buf = append(b.buf[:0], b.buf[n:]...)
+	}
+	out := strings.ToValidUTF8(string(b.buf), "")
+	if b.dropped > 0 {
+		return fmt.Sprintf("[... %d bytes of earlier output truncated ...]\n%s", b.dropped, out)
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func skipOnWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh commands")
+	}
+}
+
+func TestRunner_Verify(t *testing.T) {
+	skipOnWindows(t)
+
+	t.Run("all commands pass", func(t *testing.T) {
+		r := &Runner{Commands: []string{"echo one", "echo two"}}
+		report := r.Verify(t.Context())
+		assert.True(t, report.Passed())
+		require.Len(t, report.Results, 2)
+		assert.Equal(t, "one\n", report.Results[0].Output)
+		assert.Empty(t, report.Feedback())
+	})
+
+	t.Run("stops at first failure", func(t *testing.T) {
+		r := &Runner{Commands: []string{"echo ok", "echo broken >&2; exit 3", "echo never"}}
+		report := r.Verify(t.Context())
+		assert.False(t, report.Passed())
+		require.Len(t, report.Results, 2)
+
+		failed, ok := report.Failure()
+		require.True(t, ok)
+		assert.Equal(t, "echo broken >&2; exit 3", failed.Command)
+		assert.Equal(t, "broken\n", failed.Output)
+		assert.Equal(t, "Command: echo broken >&2; exit 3\nError: exit status 3\nOutput:\nbroken\n", report.Feedback())
+	})
+
+	t.Run("runs in dir with extra env", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "marker"), []byte("x"), 0o600))
+		r := &Runner{Dir: dir, Env: []string{"VERIFY_TEST_VAR=hello"}, Commands: []string{"ls; echo $VERIFY_TEST_VAR"}}
+		report := r.Verify(t.Context())
+		require.True(t, report.Passed(), report.Feedback())
+		assert.Equal(t, "marker\nhello\n", report.Results[0].Output)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		r := &Runner{Timeout: 50 * time.Millisecond, Commands: []string{"sleep 5"}}
+		start := time.Now()
+		report := r.Verify(t.Context())
+		assert.Less(t, time.Since(start), 4*time.Second)
+		require.False(t, report.Passed())
+		assert.EqualError(t, report.Results[0].Err, "timed out after 50ms")
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		report := (&Runner{Commands: []string{"echo hi"}}).Verify(ctx)
+		require.False(t, report.Passed())
+		assert.ErrorIs(t, report.Results[0].Err, context.Canceled)
+	})
+
+	t.Run("keeps output tail", func(t *testing.T) {
+		r := &Runner{MaxOutput: 10, Commands: []string{"printf '0123456789abcdefghij'; exit 1"}}
+		report := r.Verify(t.Context())
+		assert.Equal(t, "[... 10 bytes of earlier output truncated ...]\nabcdefghij", report.Results[0].Output)
+	})
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{limit: 8}
+	for range 100 {
+		_, err := b.Write([]byte("abcd"))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(b.buf), 16, "buffer stays bounded")
+	}
+	out := b.String()
+	assert.True(t, strings.HasSuffix(out, "\nabcdabcd"), out)
+	assert.Contains(t, out, "392 bytes of earlier output truncated")
+}
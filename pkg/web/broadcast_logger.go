@@ -16,6 +16,7 @@ type Logger interface {
 	PrintRaw(format string, args ...any)
 	PrintSection(section status.Section)
 	PrintAligned(text string)
+	PrintDiff(diff string)
 	LogQuestion(question string, options []string)
 	LogAnswer(answer string)
 	LogDraftReview(action string, feedback string)
@@ -111,6 +112,12 @@ func (b *BroadcastLogger) PrintAligned(text string) {
 	}
 }
 
+// PrintDiff writes a diff with timestamp on each line and broadcasts it.
+func (b *BroadcastLogger) PrintDiff(diff string) {
+	b.inner.PrintDiff(diff)
+	b.broadcast(NewOutputEvent(b.holder.Get(), diff))
+}
+
 // LogQuestion logs a question and its options for plan creation mode.
 func (b *BroadcastLogger) LogQuestion(question string, options []string) {
 	b.inner.LogQuestion(question, options)
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -123,6 +124,22 @@ func TestBroadcastLogger_PrintAligned(t *testing.T) {
 	assert.Equal(t, "aligned text", mockLogger.PrintAlignedCalls()[0].Text)
 }
 
+func TestBroadcastLogger_PrintDiff(t *testing.T) {
+	mockLogger := &mocks.LoggerMock{
+		PrintDiffFunc: func(string) {},
+	}
+	session := NewSession("test", "/tmp/test.txt")
+	defer session.Close()
+
+	holder := &status.PhaseHolder{}
+	bl := NewBroadcastLogger(mockLogger, session, holder)
+
+	bl.PrintDiff("+added")
+
+	require.Len(t, mockLogger.PrintDiffCalls(), 1)
+	assert.Equal(t, "+added", mockLogger.PrintDiffCalls()[0].Diff)
+}
+
 func TestBroadcastLogger_Path(t *testing.T) {
 	mockLogger := &mocks.LoggerMock{
 		PathFunc: func() string { return "/test/progress.txt" },
@@ -309,3 +326,18 @@ func TestExtractTerminalSignal(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkBroadcastLogger_PrintAligned(b *testing.B) {
+	mockLogger := &mocks.LoggerMock{PrintAlignedFunc: func(string) {}}
+	session := NewSession("bench", "/tmp/bench.txt")
+	b.Cleanup(session.Close)
+	holder := &status.PhaseHolder{}
+	holder.Set(status.PhaseTask)
+	bl := NewBroadcastLogger(mockLogger, session, holder)
+	text := strings.Repeat("editing pkg/foo/bar.go to wrap errors with context\n", 20)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		bl.PrintAligned(text)
+	}
+}
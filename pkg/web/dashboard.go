@@ -23,6 +23,7 @@ type DashboardConfig struct {
 	Branch          string           // current git branch
 	WatchDirs       []string         // CLI watch directories
 	ConfigWatchDirs []string         // config file watch directories
+	InboxFile       string           // file of user notes for the running agent, empty disables POST /api/inbox
 	Colors          *progress.Colors // colors for output
 }
 
@@ -34,6 +35,7 @@ type Dashboard struct {
 	baseLog         Logger
 	watchDirs       []string
 	configWatchDirs []string
+	inboxFile       string
 	colors          *progress.Colors
 	holder          *status.PhaseHolder
 }
@@ -47,6 +49,7 @@ func NewDashboard(cfg DashboardConfig, holder *status.PhaseHolder) *Dashboard {
 		baseLog:         cfg.BaseLog,
 		watchDirs:       cfg.WatchDirs,
 		configWatchDirs: cfg.ConfigWatchDirs,
+		inboxFile:       cfg.InboxFile,
 		colors:          cfg.Colors,
 		holder:          holder,
 	}
@@ -67,10 +70,11 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 	}
 
 	cfg := ServerConfig{
-		Port:     d.port,
-		PlanName: planName,
-		Branch:   d.branch,
-		PlanFile: d.planFile,
+		Port:      d.port,
+		PlanName:  planName,
+		Branch:    d.branch,
+		PlanFile:  d.planFile,
+		InboxFile: d.inboxFile,
 	}
 
 	// determine if we should use multi-session mode
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -241,3 +243,23 @@ func TestTaskStatus_Constants(t *testing.T) {
 	assert.Equal(t, TaskStatusDone, TaskStatus("done"))
 	assert.Equal(t, TaskStatusFailed, TaskStatus("failed"))
 }
+
+func BenchmarkParsePlan(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("# Large Plan\n\n## Overview\nbenchmark plan with many tasks\n\n")
+	for i := range 500 {
+		sb.WriteString("### Task " + strconv.Itoa(i+1) + ": implement step\n\n")
+		for range 8 {
+			sb.WriteString("- [ ] do something reasonably specific in pkg/foo/bar.go\n")
+		}
+		sb.WriteString("\n")
+	}
+	content := sb.String()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := ParsePlan(content); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
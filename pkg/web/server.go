@@ -9,9 +9,12 @@ import (
 	"html/template"
 	"io/fs"
 	"log"
+	"mime"
 	"net/http"
+	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -21,10 +24,11 @@ var embeddedFS embed.FS
 
 // ServerConfig holds configuration for the web server.
 type ServerConfig struct {
-	Port     int    // port to listen on
-	PlanName string // plan name to display in dashboard
-	Branch   string // git branch name
-	PlanFile string // path to plan file for /api/plan endpoint
+	Port      int    // port to listen on
+	PlanName  string // plan name to display in dashboard
+	Branch    string // git branch name
+	PlanFile  string // path to plan file for /api/plan endpoint
+	InboxFile string // file of user notes for the running agent, empty disables /api/inbox
 }
 
 // Server provides HTTP server for the real-time dashboard.
@@ -80,6 +84,7 @@ func (s *Server) Start(ctx context.Context) error {
 	mux.HandleFunc("/events", s.handleEvents)
 	mux.HandleFunc("/api/plan", s.handlePlan)
 	mux.HandleFunc("/api/sessions", s.handleSessions)
+	mux.HandleFunc("/api/inbox", s.handleInbox)
 
 	// static files
 	staticFS, err := fs.Sub(embeddedFS, "static")
@@ -389,6 +394,65 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(data)
 }
 
+// inboxNoteLimit caps the size of a note posted to /api/inbox
+const inboxNoteLimit = 16 * 1024
+
+// handleInbox adds a note for the running agent to the inbox file, injected into the prompt of its next iteration.
+// only JSON bodies like {"note": "..."} are accepted: a browser can't send them from another site without
+// a CORS preflight the server doesn't answer, so no web page can steer the agent.
+func (s *Server) handleInbox(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.cfg.InboxFile == "" {
+		http.Error(w, "no running agent to send notes to", http.StatusNotFound)
+		return
+	}
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+		http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	var req struct {
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, inboxNoteLimit)).Decode(&req); err != nil {
+		http.Error(w, "invalid note", http.StatusBadRequest)
+		return
+	}
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		http.Error(w, "empty note", http.StatusBadRequest)
+		return
+	}
+	if err := appendLine(s.cfg.InboxFile, note); err != nil {
+		log.Printf("[WARN] failed to write inbox file %s: %v", s.cfg.InboxFile, err)
+		http.Error(w, "unable to save note", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// appendLine appends text and a newline to the file, creating it and its directory if needed
+func appendLine(path, text string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // path is the configured inbox
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	if _, err = f.WriteString(text + "\n"); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+	return nil
+}
+
 // extractProjectDir extracts project directory name from session path.
 // handles edge cases where path has no meaningful parent directory.
 func extractProjectDir(path string) string {
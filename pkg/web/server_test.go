@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -347,6 +348,39 @@ func TestServer_HandlePlan(t *testing.T) {
 	})
 }
 
+func TestServer_HandleInbox(t *testing.T) {
+	inbox := filepath.Join(t.TempDir(), ".ralphex", "inbox")
+	srv, err := NewServer(ServerConfig{Port: 8080, InboxFile: inbox}, NewSession("test", "/tmp/test.txt"))
+	require.NoError(t, err)
+
+	post := func(srv *Server, contentType, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/inbox", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		srv.handleInbox(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusAccepted, post(srv, "application/json", `{"note": "please also rename Foo to Bar"}`))
+	assert.Equal(t, http.StatusAccepted, post(srv, "application/json; charset=utf-8", `{"note": " skip task 4 "}`))
+	data, err := os.ReadFile(inbox) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Equal(t, "please also rename Foo to Bar\nskip task 4\n", string(data))
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, post(srv, "text/plain", `{"note": "from a form"}`))
+	assert.Equal(t, http.StatusBadRequest, post(srv, "application/json", `{"note": "  "}`))
+	assert.Equal(t, http.StatusBadRequest, post(srv, "application/json", `not json`))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/inbox", http.NoBody)
+	w := httptest.NewRecorder()
+	srv.handleInbox(w, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	noInbox, err := NewServer(ServerConfig{Port: 8080}, NewSession("test", "/tmp/test.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, post(noInbox, "application/json", `{"note": "hi"}`))
+}
+
 func TestNewServerWithSessions(t *testing.T) {
 	sm := NewSessionManager()
 	defer sm.Close()